@@ -103,6 +103,9 @@ type CoreConfig struct {
 
 	TheOneHost string `long:"onehost" description:"Only connect with this server."`
 
+	WatchtowerURL string `long:"watchtower" description:"URL of a swap watchtower (e.g. http://127.0.0.1:7242). Signed refund transactions for new swaps, and pre-signed redemptions where the wallet supports it, are submitted to the watchtower so that they can be broadcast while the client is offline."`
+	WatchtowerKey string `long:"watchtowerkey" description:"API key for the swap watchtower."`
+
 	NoAutoWalletLock   bool `long:"no-wallet-lock" description:"Disable locking of wallets on shutdown or logout. Use this if you want your external wallets to stay unlocked after closing the DEX app."`
 	NoAutoDBBackup     bool `long:"no-db-backup" description:"Disable creation of a database backup on shutdown."`
 	UnlockCoinsOnLogin bool `long:"release-wallet-coins" description:"On login or wallet creation, instruct the wallet to release any coins that it may have locked."`
@@ -221,6 +224,8 @@ func (cfg *Config) Core(log dex.Logger) *core.Config {
 		NoAutoDBBackup:     cfg.NoAutoDBBackup,
 		ExtensionModeFile:  cfg.ExtensionModeFile,
 		TheOneHost:         cfg.TheOneHost,
+		WatchtowerURL:      cfg.WatchtowerURL,
+		WatchtowerKey:      cfg.WatchtowerKey,
 	}
 }
 
@@ -28,7 +28,6 @@ import (
 	"decred.org/dcrdex/dex/calc"
 	"decred.org/dcrdex/dex/config"
 	"decred.org/dcrdex/dex/dexnet"
-	"decred.org/dcrdex/dex/encode"
 	dexbtc "decred.org/dcrdex/dex/networks/btc"
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
@@ -77,6 +76,7 @@ const (
 	walletTypeRPC      = "bitcoindRPC"
 	walletTypeSPV      = "SPV"
 	walletTypeElectrum = "electrumRPC"
+	walletTypePSBT     = "watchOnlyPSBT"
 
 	swapFeeBumpKey      = "swapfeebump"
 	splitKey            = "swapsplit"
@@ -207,6 +207,15 @@ var (
 		MultiFundingOpts: MultiFundingOpts,
 	}
 
+	psbtWalletDefinition = &asset.WalletDefinition{
+		Type:              walletTypePSBT,
+		Tab:               "Watch-only (external)",
+		Description:       "Connect to a bitcoind watch-only wallet, and sign with an external signer",
+		DefaultConfigPath: dexbtc.SystemConfigPath("bitcoin"),
+		ConfigOpts:        append(append(RPCConfigOpts("Bitcoin", "8332"), psbtConfigOpts...), CommonConfigOpts("BTC", false)...),
+		MultiFundingOpts:  MultiFundingOpts,
+	}
+
 	// WalletInfo defines some general information about a Bitcoin wallet.
 	WalletInfo = &asset.WalletInfo{
 		Name:              "Bitcoin",
@@ -216,6 +225,7 @@ var (
 			spvWalletDefinition,
 			rpcWalletDefinition,
 			electrumWalletDefinition,
+			psbtWalletDefinition,
 		},
 		LegacyWalletIndex: 1,
 	}
@@ -1138,6 +1148,8 @@ func NewWallet(cfg *asset.WalletConfig, logger dex.Logger, net dex.Network) (ass
 		}
 		cloneCFG.MinElectrumVersion = *ver
 		return ElectrumWallet(cloneCFG)
+	case walletTypePSBT:
+		return openPSBTWallet(cloneCFG)
 	default:
 		makeCustomWallet, ok := customWalletConstructors[cfg.Type]
 		if !ok {
@@ -3865,20 +3877,35 @@ func (btc *baseWallet) Swap(swaps *asset.Swaps) ([]asset.Receipt, asset.Coin, ui
 	// the individual swap refund txs are prepared and signed.
 	msgTx, change, fees, err := btc.signTxAndAddChange(baseTx, changeAddr, totalIn, totalOut, feeRate)
 	if err != nil {
+		if extSigner, is := btc.node.(externalSigner); is && errors.Is(err, asset.ErrAwaitingSignature) {
+			// Request the backup refunds along with the swap so that the
+			// signer can sign them all at once. The refunds spend the outputs
+			// of the swap tx that the signer was asked to sign.
+			swapTx, reqErr := extSigner.requestTx(baseTx)
+			if reqErr == nil {
+				_, reqErr = btc.swapRefundTxs(btc.hashTx(swapTx), swaps, contracts, refundAddrs)
+			}
+			if errors.Is(reqErr, asset.ErrAwaitingSignature) {
+				err = errors.Join(err, reqErr)
+			} else if reqErr != nil {
+				btc.log.Errorf("Error requesting backup refund signatures: %v", reqErr)
+			}
+		}
 		return nil, nil, 0, err
 	}
 	txHash := btc.hashTx(msgTx)
 
+	// The swap is not broadcasted until all of its refunds are signed.
+	refundTxs, err := btc.swapRefundTxs(txHash, swaps, contracts, refundAddrs)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
 	// Prepare the receipts.
 	receipts := make([]asset.Receipt, 0, swapCount)
 	for i, contract := range swaps.Contracts {
 		output := NewOutput(txHash, uint32(i), contract.Value)
-		refundAddr := refundAddrs[i]
-		signedRefundTx, err := btc.refundTx(output.txHash(), output.vout(), contracts[i],
-			contract.Value, refundAddr, swaps.FeeRate)
-		if err != nil {
-			return nil, nil, 0, fmt.Errorf("error creating refund tx: %w", err)
-		}
+		signedRefundTx := refundTxs[i]
 		refundBuff := new(bytes.Buffer)
 		err = signedRefundTx.Serialize(refundBuff)
 		if err != nil {
@@ -3943,6 +3970,32 @@ func (btc *baseWallet) Swap(swaps *asset.Swaps) ([]asset.Receipt, asset.Coin, ui
 	return receipts, changeCoin, fees, nil
 }
 
+// swapRefundTxs creates the signed backup refund txs for the contracts of the
+// swap tx with hash txHash. With an external signer, every refund is requested
+// before asset.ErrAwaitingSignature is returned, so that the signer can sign
+// them together.
+func (btc *baseWallet) swapRefundTxs(txHash *chainhash.Hash, swaps *asset.Swaps, contracts [][]byte,
+	refundAddrs []btcutil.Address) ([]*wire.MsgTx, error) {
+
+	refundTxs := make([]*wire.MsgTx, 0, len(swaps.Contracts))
+	var awaitingErrs []error
+	for i, contract := range swaps.Contracts {
+		refundTx, err := btc.refundTx(txHash, uint32(i), contracts[i], contract.Value, refundAddrs[i], swaps.FeeRate)
+		if errors.Is(err, asset.ErrAwaitingSignature) {
+			awaitingErrs = append(awaitingErrs, fmt.Errorf("refund of swap %s:%d: %w", txHash, i, err))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error creating refund tx: %w", err)
+		}
+		refundTxs = append(refundTxs, refundTx)
+	}
+	if len(awaitingErrs) > 0 {
+		return nil, errors.Join(awaitingErrs...)
+	}
+	return refundTxs, nil
+}
+
 // privateSwapPubKey encodes a public key and nonce to be used for a private
 // swap. The public key and nonce must be sent to the counterparty in order
 // for them to calculate the combined nonce used in musig2.
@@ -3952,33 +4005,12 @@ type privateSwapPubKey struct {
 }
 
 func (ps *privateSwapPubKey) encode() ([]byte, error) {
-	return encode.BuildyBytes{0}.
-		AddData(ps.pubKey.SerializeCompressed()).
-		AddData(ps.pubNonce[:]), nil
+	return dexbtc.EncodePrivateSwapPubKey(ps.pubKey, ps.pubNonce), nil
 }
 
-func (ps *privateSwapPubKey) decode(data []byte) error {
-	ver, pushes, err := encode.DecodeBlob(data)
-	if err != nil {
-		return fmt.Errorf("error decoding blob: %w", err)
-	}
-	if ver != 0 {
-		return fmt.Errorf("invalid version")
-	}
-	if len(pushes) != 2 {
-		return fmt.Errorf("expected 2 pushes")
-	}
-
-	ps.pubKey, err = btcec.ParsePubKey(pushes[0])
-	if err != nil {
-		return fmt.Errorf("error parsing public key: %w", err)
-	}
-
-	if len(pushes[1]) != 66 {
-		return fmt.Errorf("expected 66 byte public nonce")
-	}
-	copy(ps.pubNonce[:], pushes[1])
-	return nil
+func (ps *privateSwapPubKey) decode(data []byte) (err error) {
+	ps.pubKey, ps.pubNonce, err = dexbtc.DecodePrivateSwapPubKey(data)
+	return err
 }
 
 // PrivateSwapPubKey returns the public key and public nonce to be used in a
@@ -4089,35 +4121,17 @@ func privateSwapOutputDataFromContract(contract *asset.PrivateContract) (*privat
 		return nil, fmt.Errorf("error decoding refund public key: %w", err)
 	}
 
-	// Create the combined key which is used to redeem the contract.
-	combinedKey, _, _, err := musig2.AggregateKeys([]*btcec.PublicKey{redeemPubKey.pubKey, refundPubKey.pubKey}, true)
-	if err != nil {
-		return nil, fmt.Errorf("error aggregating keys: %w", err)
-	}
-
-	// Create the refund script and the taproot script tree, which only
-	// contains one leaf.
-	refundScript, err := dexbtc.PrivateSwapRefundScript(refundPubKey.pubKey, int64(contract.LockTime))
-	if err != nil {
-		return nil, fmt.Errorf("error creating refund script: %w", err)
-	}
-
-	refundLeaf := txscript.NewBaseTapLeaf(refundScript)
-	tapScriptTree := txscript.AssembleTaprootScriptTree(refundLeaf)
-	tapScriptRootHash := tapScriptTree.RootNode.TapHash()
-	controlBlock := tapScriptTree.LeafMerkleProofs[0].ToControlBlock(combinedKey.FinalKey)
-	outputKey := txscript.ComputeTaprootOutputKey(combinedKey.FinalKey, tapScriptRootHash[:])
-	pkScript, err := dexbtc.PayToTaprootScript(outputKey)
+	tree, err := dexbtc.NewPrivateSwapTree(redeemPubKey.pubKey, refundPubKey.pubKey, int64(contract.LockTime))
 	if err != nil {
-		return nil, fmt.Errorf("error creating pay-to-taproot script: %w", err)
+		return nil, err
 	}
 
 	return &privateSwapOutputData{
-		refundScript:      refundScript,
-		leaf:              refundLeaf,
-		controlBlock:      &controlBlock,
-		pkScript:          pkScript,
-		tapscriptRootHash: tapScriptRootHash[:],
+		refundScript:      tree.RefundScript,
+		leaf:              tree.Leaf,
+		controlBlock:      tree.ControlBlock,
+		pkScript:          tree.PkScript,
+		tapscriptRootHash: tree.RootHash,
 		redeemPubKey:      redeemPubKey,
 		refundPubKey:      refundPubKey,
 	}, nil
@@ -5007,6 +5021,46 @@ func (btc *baseWallet) MarkPrivateSwapComplete(contract *asset.PrivateContract,
 
 // Redeem sends the redemption transaction, completing the atomic swap.
 func (btc *baseWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Coin, uint64, error) {
+	msgTx, totalIn, fee, err := btc.redemptionTx(form)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	txOut := msgTx.TxOut[0]
+
+	// Send the transaction.
+	txHash, err := btc.broadcastTx(msgTx)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	btc.addTxToHistory(&asset.WalletTransaction{
+		Type:   asset.Redeem,
+		ID:     txHash.String(),
+		Amount: totalIn,
+		Fees:   fee,
+	}, txHash, true)
+
+	// Log the change output.
+	coinIDs := make([]dex.Bytes, 0, len(form.Redemptions))
+	for i := range form.Redemptions {
+		coinIDs = append(coinIDs, ToCoinID(txHash, uint32(i)))
+	}
+	return coinIDs, NewOutput(txHash, 0, uint64(txOut.Value)), fee, nil
+}
+
+// SignRedemption creates and signs a transaction that redeems the form's
+// swaps, but does not broadcast it. This satisfies asset.RedemptionSigner.
+func (btc *baseWallet) SignRedemption(form *asset.RedeemForm) ([]byte, error) {
+	msgTx, _, _, err := btc.redemptionTx(form)
+	if err != nil {
+		return nil, err
+	}
+	return serializeMsgTx(msgTx)
+}
+
+// redemptionTx creates and signs a transaction that redeems the form's swaps,
+// returning the transaction, the total contract value redeemed, and the fee.
+func (btc *baseWallet) redemptionTx(form *asset.RedeemForm) (*wire.MsgTx, uint64, uint64, error) {
 	// Create a transaction that spends the referenced contract.
 	msgTx := wire.NewMsgTx(btc.txVersion())
 	var totalIn uint64
@@ -5016,12 +5070,12 @@ func (btc *baseWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Coin,
 	values := make([]int64, 0, len(form.Redemptions))
 	for _, r := range form.Redemptions {
 		if r.Spends == nil {
-			return nil, nil, 0, fmt.Errorf("no audit info")
+			return nil, 0, 0, fmt.Errorf("no audit info")
 		}
 
 		cinfo, err := ConvertAuditInfo(r.Spends, btc.decodeAddr, btc.chainParams)
 		if err != nil {
-			return nil, nil, 0, err
+			return nil, 0, 0, err
 		}
 
 		// Extract the swap contract recipient and secret hash and check the secret
@@ -5029,15 +5083,15 @@ func (btc *baseWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Coin,
 		contract := cinfo.contract
 		_, receiver, _, secretHash, err := dexbtc.ExtractSwapDetails(contract, btc.segwit, btc.chainParams)
 		if err != nil {
-			return nil, nil, 0, fmt.Errorf("error extracting swap addresses: %w", err)
+			return nil, 0, 0, fmt.Errorf("error extracting swap addresses: %w", err)
 		}
 		checkSecretHash := sha256.Sum256(r.Secret)
 		if !bytes.Equal(checkSecretHash[:], secretHash) {
-			return nil, nil, 0, fmt.Errorf("secret hash mismatch")
+			return nil, 0, 0, fmt.Errorf("secret hash mismatch")
 		}
 		pkScript, err := btc.scriptHashScript(contract)
 		if err != nil {
-			return nil, nil, 0, fmt.Errorf("error constructs p2sh script: %v", err)
+			return nil, 0, 0, fmt.Errorf("error constructs p2sh script: %v", err)
 		}
 		prevScripts = append(prevScripts, pkScript)
 		addresses = append(addresses, receiver)
@@ -5061,7 +5115,7 @@ func (btc *baseWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Coin,
 	customCfg := new(redeemOptions)
 	err := config.Unmapify(form.Options, customCfg)
 	if err != nil {
-		return nil, nil, 0, fmt.Errorf("error parsing selected swap options: %w", err)
+		return nil, 0, 0, fmt.Errorf("error parsing selected swap options: %w", err)
 	}
 
 	rawFeeRate := btc.targetFeeRateWithFallback(btc.redeemConfTarget(), form.FeeSuggestion)
@@ -5075,7 +5129,7 @@ func (btc *baseWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Coin,
 		feeRate = rawFeeRate
 		fee = feeRate * size
 		if fee > totalIn {
-			return nil, nil, 0, fmt.Errorf("redeem tx not worth the fees")
+			return nil, 0, 0, fmt.Errorf("redeem tx not worth the fees")
 		}
 		btc.log.Warnf("Ignoring fee bump (%s) resulting in fees > redemption", float64PtrStr(customCfg.FeeBump))
 	}
@@ -5083,20 +5137,40 @@ func (btc *baseWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Coin,
 	// Send the funds back to the exchange wallet.
 	redeemAddr, err := btc.node.ExternalAddress()
 	if err != nil {
-		return nil, nil, 0, fmt.Errorf("error getting new address from the wallet: %w", err)
+		return nil, 0, 0, fmt.Errorf("error getting new address from the wallet: %w", err)
 	}
 	pkScript, err := txscript.PayToAddrScript(redeemAddr)
 	if err != nil {
-		return nil, nil, 0, fmt.Errorf("error creating change script: %w", err)
+		return nil, 0, 0, fmt.Errorf("error creating change script: %w", err)
 	}
 	txOut := wire.NewTxOut(int64(totalIn-fee), pkScript)
 	// One last check for dust.
 	if btc.IsDust(txOut, feeRate) {
-		return nil, nil, 0, fmt.Errorf("swap redeem output is dust")
+		return nil, 0, 0, fmt.Errorf("swap redeem output is dust")
 	}
 	msgTx.AddTxOut(txOut)
 
-	if btc.segwit {
+	if extSigner, is := btc.node.(externalSigner); is && btc.segwit {
+		contractInputs := make(map[int]*contractInput, len(form.Redemptions))
+		for i, r := range form.Redemptions {
+			contract, secret := contracts[i], r.Secret
+			contractInputs[i] = &contractInput{
+				value:    values[i],
+				pkScript: prevScripts[i],
+				contract: contract,
+				addr:     addresses[i],
+				witness: func(sig, pubKey []byte) wire.TxWitness {
+					return dexbtc.RedeemP2WSHContract(contract, sig, pubKey, secret)
+				},
+			}
+		}
+		if msgTx, err = extSigner.signContractInputs(msgTx, contractInputs); err != nil {
+			return nil, 0, 0, fmt.Errorf("error signing redemption: %w", err)
+		}
+		// The signed tx may be an earlier version with a different fee.
+		txOut = msgTx.TxOut[0]
+		fee = totalIn - uint64(txOut.Value)
+	} else if btc.segwit {
 		// NewTxSigHashes uses the PrevOutFetcher only for detecting a taproot
 		// output, so we can provide a dummy that always returns a wire.TxOut
 		// with a nil pkScript that so IsPayToTaproot returns false.
@@ -5105,7 +5179,7 @@ func (btc *baseWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Coin,
 			contract := contracts[i]
 			redeemSig, redeemPubKey, err := btc.createWitnessSig(msgTx, i, contract, addresses[i], values[i], sigHashes)
 			if err != nil {
-				return nil, nil, 0, err
+				return nil, 0, 0, err
 			}
 			msgTx.TxIn[i].Witness = dexbtc.RedeemP2WSHContract(contract, redeemSig, redeemPubKey, r.Secret)
 		}
@@ -5114,34 +5188,16 @@ func (btc *baseWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Coin,
 			contract := contracts[i]
 			redeemSig, redeemPubKey, err := btc.createSig(msgTx, i, contract, addresses[i], values, prevScripts)
 			if err != nil {
-				return nil, nil, 0, err
+				return nil, 0, 0, err
 			}
 			msgTx.TxIn[i].SignatureScript, err = dexbtc.RedeemP2SHContract(contract, redeemSig, redeemPubKey, r.Secret)
 			if err != nil {
-				return nil, nil, 0, err
+				return nil, 0, 0, err
 			}
 		}
 	}
 
-	// Send the transaction.
-	txHash, err := btc.broadcastTx(msgTx)
-	if err != nil {
-		return nil, nil, 0, err
-	}
-
-	btc.addTxToHistory(&asset.WalletTransaction{
-		Type:   asset.Redeem,
-		ID:     txHash.String(),
-		Amount: totalIn,
-		Fees:   fee,
-	}, txHash, true)
-
-	// Log the change output.
-	coinIDs := make([]dex.Bytes, 0, len(form.Redemptions))
-	for i := range form.Redemptions {
-		coinIDs = append(coinIDs, ToCoinID(txHash, uint32(i)))
-	}
-	return coinIDs, NewOutput(txHash, 0, uint64(txOut.Value)), fee, nil
+	return msgTx, totalIn, fee, nil
 }
 
 // ConvertAuditInfo converts from the common *asset.AuditInfo type to our
@@ -5183,6 +5239,13 @@ func (btc *baseWallet) SignMessage(coin asset.Coin, msg dex.Bytes) (pubkeys, sig
 	if utxo == nil {
 		return nil, nil, fmt.Errorf("no utxo found for %s", op)
 	}
+	if extSigner, is := btc.node.(externalSigner); is {
+		pubKey, sig, err := extSigner.signHash(utxo.Address, chainhash.HashB(msg))
+		if err != nil {
+			return nil, nil, err
+		}
+		return []dex.Bytes{pubKey}, []dex.Bytes{sig}, nil
+	}
 	privKey, err := btc.node.PrivKeyForAddress(utxo.Address)
 	if err != nil {
 		return nil, nil, err
@@ -5453,6 +5516,22 @@ func (btc *baseWallet) refundTx(txHash *chainhash.Hash, vout uint32, contract de
 	}
 	msgTx.AddTxOut(txOut)
 
+	if extSigner, is := btc.node.(externalSigner); is && btc.segwit {
+		pkScript, err := btc.scriptHashScript(contract)
+		if err != nil {
+			return nil, fmt.Errorf("error constructing p2wsh script: %w", err)
+		}
+		return extSigner.signContractInputs(msgTx, map[int]*contractInput{0: {
+			value:    int64(val),
+			pkScript: pkScript,
+			contract: contract,
+			addr:     sender,
+			witness: func(sig, pubKey []byte) wire.TxWitness {
+				return dexbtc.RefundP2WSHContract(contract, sig, pubKey)
+			},
+		}})
+	}
+
 	if btc.segwit {
 		sigHashes := txscript.NewTxSigHashes(msgTx, new(txscript.CannedPrevOutputFetcher))
 		refundSig, refundPubKey, err := btc.createWitnessSig(msgTx, 0, contract, sender, int64(val), sigHashes)
@@ -5489,7 +5568,9 @@ func (btc *baseWallet) DepositAddress() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	if btc.node.Locked() {
+	// A watch-only wallet has no private keys to check. Its addresses are
+	// derived from the signer's extended public key.
+	if _, external := btc.node.(externalSigner); external || btc.node.Locked() {
 		return addrStr, nil
 	}
 
@@ -5713,6 +5794,20 @@ func (btc *baseWallet) SwapConfirmations(_ context.Context, id dex.Bytes, contra
 	return btc.node.SwapConfirmations(txHash, vout, pkScript, startTime)
 }
 
+// PrivateSwapConfirmations gets the number of confirmations and the spend
+// status for a private swap output.
+func (btc *baseWallet) PrivateSwapConfirmations(_ context.Context, id dex.Bytes, contract *asset.PrivateContract, startTime time.Time) (uint32, bool, error) {
+	txHash, vout, err := decodeCoinID(id)
+	if err != nil {
+		return 0, false, err
+	}
+	outputData, err := privateSwapOutputDataFromContract(contract)
+	if err != nil {
+		return 0, false, fmt.Errorf("error getting private swap output data: %w", err)
+	}
+	return btc.node.SwapConfirmations(txHash, vout, outputData.pkScript, startTime)
+}
+
 // RegFeeConfirmations gets the number of confirmations for the specified output
 // by first checking for a unspent output, and if not found, searching indexed
 // wallet transactions.
@@ -5901,10 +5996,20 @@ func (btc *baseWallet) signTxAndAddChange(baseTx *wire.MsgTx, addr btcutil.Addre
 		return nil, nil, 0, fmt.Errorf(s, a...)
 	}
 
+	// An external signer only signs the final tx. The fees are converged on
+	// with placeholder signatures.
+	signTx := btc.node.SignTx
+	extSigner, external := btc.node.(externalSigner)
+	if external {
+		signTx = func(tx *wire.MsgTx) (*wire.MsgTx, error) {
+			return extSigner.stubSignTx(tx), nil
+		}
+	}
+
 	// Sign the transaction to get an initial size estimate and calculate whether
 	// a change output would be dust.
 	sigCycles := 1
-	msgTx, err := btc.node.SignTx(baseTx)
+	msgTx, err := signTx(baseTx)
 	if err != nil {
 		return makeErr("signing error: %v, raw tx: %x", err, btc.wireBytes(baseTx))
 	}
@@ -5949,7 +6054,7 @@ func (btc *baseWallet) signTxAndAddChange(baseTx *wire.MsgTx, addr btcutil.Addre
 		for {
 			// Sign the transaction with the change output and compute new size.
 			sigCycles++
-			msgTx, err = btc.node.SignTx(baseTx)
+			msgTx, err = signTx(baseTx)
 			if err != nil {
 				return makeErr("signing error: %v, raw tx: %x", err, btc.wireBytes(baseTx))
 			}
@@ -5990,6 +6095,22 @@ func (btc *baseWallet) signTxAndAddChange(baseTx *wire.MsgTx, addr btcutil.Addre
 			changeOutput.Value, btc.hashTx(msgTx))
 	}
 
+	if external {
+		if msgTx, err = btc.node.SignTx(baseTx); err != nil {
+			return nil, nil, 0, fmt.Errorf("signing error: %w", err)
+		}
+		// The signed tx may be an earlier version with the same inputs and a
+		// different change amount.
+		totalOut = 0
+		for _, txOut := range msgTx.TxOut {
+			totalOut += uint64(txOut.Value)
+		}
+		if changeAdded = len(msgTx.TxOut) > changeIdx; changeAdded {
+			changeOutput = msgTx.TxOut[changeIdx]
+		}
+		vSize = btc.calcTxSize(msgTx)
+	}
+
 	txHash := btc.hashTx(msgTx)
 
 	fee := totalIn - totalOut
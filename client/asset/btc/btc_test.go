@@ -3452,6 +3452,66 @@ func testRedeem(t *testing.T, segwit bool, walletType string) {
 	node.badSendHash = nil
 }
 
+func TestSignRedemption(t *testing.T) {
+	runRubric(t, testSignRedemption)
+}
+
+func testSignRedemption(t *testing.T, segwit bool, walletType string) {
+	wallet, node, shutdown := tNewWallet(segwit, walletType)
+	defer shutdown()
+	swapVal := toSatoshi(5)
+
+	secret, _, _, contract, addr, _, lockTime := makeSwapContract(segwit, time.Hour*12)
+	redemption := &asset.Redemption{
+		Spends: &asset.AuditInfo{
+			Coin:       NewOutput(tTxHash, 0, swapVal),
+			Contract:   contract,
+			Recipient:  addr.String(),
+			Expiration: lockTime,
+		},
+		Secret: secret,
+	}
+
+	privBytes, _ := hex.DecodeString("b07209eec1a8fb6cfe5cb6ace36567406971a75c330db7101fb21bc679bc5330")
+	privKey, _ := btcec.PrivKeyFromBytes(privBytes)
+	wif, err := btcutil.NewWIF(privKey, &chaincfg.MainNetParams, true)
+	if err != nil {
+		t.Fatalf("error encoding wif: %v", err)
+	}
+	addrStr := tP2PKHAddr
+	if segwit {
+		addrStr = tP2WPKHAddr
+	}
+	node.changeAddr = addrStr
+	node.newAddress = addrStr
+	node.privKeyForAddr = wif
+
+	form := &asset.RedeemForm{Redemptions: []*asset.Redemption{redemption}}
+	rawTx, err := wallet.SignRedemption(form)
+	if err != nil {
+		t.Fatalf("SignRedemption error: %v", err)
+	}
+	if node.sentRawTx != nil {
+		t.Fatalf("signed redemption was broadcast")
+	}
+	msgTx, err := msgTxFromBytes(rawTx)
+	if err != nil {
+		t.Fatalf("error decoding signed redemption: %v", err)
+	}
+	if len(msgTx.TxIn) != 1 || msgTx.TxIn[0].PreviousOutPoint.Hash != *tTxHash {
+		t.Fatalf("signed redemption does not spend the contract")
+	}
+	if len(msgTx.TxIn[0].Witness) == 0 && len(msgTx.TxIn[0].SignatureScript) == 0 {
+		t.Fatalf("redemption not signed")
+	}
+
+	// Wrong secret
+	redemption.Secret = randBytes(32)
+	if _, err = wallet.SignRedemption(form); err == nil {
+		t.Fatalf("no error for wrong secret")
+	}
+}
+
 func TestSignMessage(t *testing.T) {
 	runRubric(t, testSignMessage)
 }
@@ -0,0 +1,823 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package btc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/config"
+	dexbtc "decred.org/dcrdex/dex/networks/btc"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	watchDescriptorKey = "watchdescriptor"
+	signerKey          = "signer"
+	psbtDirKey         = "psbtdir"
+
+	fileSignerName = "file"
+
+	methodWalletProcessPSBT = "walletprocesspsbt"
+	methodGetDescriptorInfo = "getdescriptorinfo"
+	methodImportDescriptors = "importdescriptors"
+
+	// signedTxExpiration is how long signed transactions are kept in case the
+	// caller needs them again, e.g. after a failed broadcast.
+	signedTxExpiration = 24 * time.Hour
+)
+
+var (
+	errWatchOnly = errors.New("watch-only wallet has no private keys")
+
+	// psbtMagic are the leading bytes of a binary PSBT.
+	psbtMagic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+	psbtConfigOpts = []*asset.ConfigOption{
+		{
+			Key:         watchDescriptorKey,
+			DisplayName: "Account xpub or descriptor",
+			Description: "The account extended public key, or a wpkh output descriptor for " +
+				"the receiving addresses, e.g. wpkh([d34db33f/84'/0'/0']xpub.../0/*). Include " +
+				"the key origin for hardware signers. Change addresses use the /1/* branch.",
+			Required: true,
+		},
+		{
+			Key:          signerKey,
+			DisplayName:  "Signer",
+			Description:  "The external signer. The file signer writes PSBTs to the PSBT directory for signing elsewhere.",
+			DefaultValue: fileSignerName,
+		},
+		{
+			Key:         psbtDirKey,
+			DisplayName: "PSBT directory",
+			Description: "The directory for the file signer's signing requests and signed responses. " +
+				"The default is the psbt directory in the wallet's data directory.",
+		},
+	}
+)
+
+// PSBTSigner signs for a watch-only wallet. Signing may require a person or an
+// offline device, so rather than blocking, a signer returns
+// asset.ErrAwaitingSignature until the signature is available. The same
+// request is made again later.
+type PSBTSigner interface {
+	// SignPSBT adds partial signatures to the packet's inputs.
+	SignPSBT(ctx context.Context, packet *psbt.Packet) (*psbt.Packet, error)
+	// SignHash signs the 32-byte hash with the derived key, returning a
+	// DER-encoded signature.
+	SignHash(ctx context.Context, key *psbt.Bip32Derivation, hash []byte) ([]byte, error)
+}
+
+// PSBTSignerConstructor creates a PSBTSigner from the wallet settings. dataDir
+// is the wallet's data directory.
+type PSBTSignerConstructor func(settings map[string]string, dataDir string, log dex.Logger) (PSBTSigner, error)
+
+// psbtSigners are the constructors of the signers available to the
+// watch-only wallet type.
+var psbtSigners = map[string]PSBTSignerConstructor{
+	fileSignerName: newFileSigner,
+}
+
+// RegisterPSBTSigner registers a PSBTSigner for the watch-only wallet type,
+// selected with the signer wallet setting. It'll panic if callers try to
+// register a signer twice.
+func RegisterPSBTSigner(name string, constructor PSBTSignerConstructor) {
+	if _, found := psbtSigners[name]; found {
+		panic(fmt.Sprintf("signer %q is already registered", name))
+	}
+	psbtSigners[name] = constructor
+}
+
+// psbtWalletConfig is the configuration of the watch-only wallet type, in
+// addition to the RPC wallet configuration.
+type psbtWalletConfig struct {
+	Descriptor string `ini:"watchdescriptor"`
+	Signer     string `ini:"signer"`
+	PSBTDir    string `ini:"psbtdir"`
+}
+
+// watchDescriptors parses the receiving and change descriptors from an account
+// extended public key or a wpkh descriptor for the receiving branch.
+func watchDescriptors(s string) (receive, change string, err error) {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "#") // checksum is added by getdescriptorinfo
+	if s == "" {
+		return "", "", errors.New("no xpub or descriptor")
+	}
+	if !strings.Contains(s, "(") {
+		if _, err := hdkeychain.NewKeyFromString(s); err != nil {
+			return "", "", fmt.Errorf("invalid extended public key: %w", err)
+		}
+		return fmt.Sprintf("wpkh(%s/0/*)", s), fmt.Sprintf("wpkh(%s/1/*)", s), nil
+	}
+	desc, err := dexbtc.ParseDescriptor(s)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid descriptor: %w", err)
+	}
+	// Transaction sizes are estimated for P2WPKH inputs.
+	if desc.Function != "wpkh" || desc.KeyFmt != dexbtc.KeyExtended {
+		return "", "", errors.New("only wpkh descriptors of an extended public key are supported")
+	}
+	if !strings.HasSuffix(s, "/0/*)") {
+		return "", "", errors.New("descriptor is not for the /0/* receiving branch")
+	}
+	return s, strings.TrimSuffix(s, "/0/*)") + "/1/*)", nil
+}
+
+// openPSBTWallet opens a bitcoind watch-only wallet whose transactions are
+// signed by a PSBTSigner.
+func openPSBTWallet(cfg *BTCCloneCFG) (*ExchangeWalletNoAuth, error) {
+	if !cfg.Segwit {
+		return nil, errors.New("watch-only wallets require segwit")
+	}
+	var psbtCfg psbtWalletConfig
+	if err := config.Unmapify(cfg.WalletCFG.Settings, &psbtCfg); err != nil {
+		return nil, fmt.Errorf("error parsing watch-only wallet config: %w", err)
+	}
+	receiveDesc, changeDesc, err := watchDescriptors(psbtCfg.Descriptor)
+	if err != nil {
+		return nil, err
+	}
+	signerName := psbtCfg.Signer
+	if signerName == "" {
+		signerName = fileSignerName
+	}
+	newSigner, found := psbtSigners[signerName]
+	if !found {
+		return nil, fmt.Errorf("unknown signer %q", signerName)
+	}
+	signer, err := newSigner(cfg.WalletCFG.Settings, filepath.Join(cfg.WalletCFG.DataDir, cfg.ChainParams.Name), cfg.Logger)
+	if err != nil {
+		return nil, fmt.Errorf("error creating %s signer: %w", signerName, err)
+	}
+
+	iw, err := btcCloneWallet(cfg)
+	if err != nil {
+		return nil, err
+	}
+	iw.setNode(&psbtWallet{
+		rpcClient:  iw.node.(*rpcClient),
+		signer:     signer,
+		psbtCfg:    &psbtCfg,
+		watchDescs: [2]string{receiveDesc, changeDesc},
+		pending:    make(map[string]*pendingPSBT),
+		signed:     make(map[chainhash.Hash]*signedTx),
+	})
+	return &ExchangeWalletNoAuth{iw}, nil
+}
+
+// contractInput is a swap contract input for an external signer.
+type contractInput struct {
+	value    int64
+	pkScript []byte
+	contract []byte
+	// addr is the address of the key that signs for the contract.
+	addr btcutil.Address
+	// witness creates the input's witness from the signature.
+	witness func(sig, pubKey []byte) wire.TxWitness
+	// pubKey is set when the signing request is created.
+	pubKey []byte
+}
+
+// pendingPSBT is a signing request that the signer has not signed yet.
+type pendingPSBT struct {
+	packet    *psbt.Packet
+	contracts map[int]*contractInput
+}
+
+// signedTx is a transaction that has been signed by the signer.
+type signedTx struct {
+	tx    *wire.MsgTx
+	stamp time.Time
+}
+
+// psbtWallet is a bitcoind watch-only descriptor wallet. The wallet tracks
+// balances and builds transactions, and every transaction is signed by a
+// PSBTSigner.
+//
+// A signing request can take a long time, so retries of an operation must
+// produce the same request. A retry spending the same inputs as a pending
+// request keeps waiting for that request, as long as the two transactions only
+// differ in their outputs to the wallet, e.g. the change amount at a new fee
+// rate.
+type psbtWallet struct {
+	*rpcClient
+	signer     PSBTSigner
+	psbtCfg    *psbtWalletConfig
+	watchDescs [2]string // receiving, change
+
+	mtx        sync.Mutex
+	changeAddr btcutil.Address
+	// pending are the signing requests awaiting signatures, keyed by the
+	// inputs spent.
+	pending map[string]*pendingPSBT
+	signed  map[chainhash.Hash]*signedTx
+}
+
+var _ Wallet = (*psbtWallet)(nil)
+var _ externalSigner = (*psbtWallet)(nil)
+
+// Connect connects to the wallet, which must be a descriptor wallet without
+// private keys, and imports the watch-only descriptors if needed.
+func (w *psbtWallet) Connect(ctx context.Context, wg *sync.WaitGroup) error {
+	if err := w.rpcClient.Connect(ctx, wg); err != nil {
+		return err
+	}
+	if !w.descriptors {
+		return errors.New("watch-only wallets must be descriptor wallets")
+	}
+	walletInfo, err := w.GetWalletInfo()
+	if err != nil {
+		return fmt.Errorf("getwalletinfo failure: %w", err)
+	}
+	if walletInfo.PriveyKeysEnabled {
+		return fmt.Errorf("wallet %q has private keys. Create the wallet with disable_private_keys", walletInfo.WalletName)
+	}
+	return w.importDescriptors()
+}
+
+// importDescriptors imports the receiving and change descriptors if the
+// wallet does not have them.
+func (w *psbtWallet) importDescriptors() error {
+	have, err := w.listDescriptors(false)
+	if err != nil {
+		return fmt.Errorf("listdescriptors error: %w", err)
+	}
+	type importRequest struct {
+		Desc      string `json:"desc"`
+		Active    bool   `json:"active"`
+		Internal  bool   `json:"internal"`
+		Timestamp string `json:"timestamp"`
+	}
+	var reqs []*importRequest
+	for i, desc := range w.watchDescs {
+		var info struct {
+			Descriptor string `json:"descriptor"`
+		}
+		if err := w.call(methodGetDescriptorInfo, anylist{desc}, &info); err != nil {
+			return fmt.Errorf("invalid descriptor %q: %w", desc, err)
+		}
+		var found bool
+		for _, d := range have.Descriptors {
+			if d.Descriptor == info.Descriptor {
+				found = true
+				break
+			}
+		}
+		if !found {
+			reqs = append(reqs, &importRequest{
+				Desc:      info.Descriptor,
+				Active:    true,
+				Internal:  i == 1,
+				Timestamp: "now",
+			})
+		}
+	}
+	if len(reqs) == 0 {
+		return nil
+	}
+	var res []struct {
+		Success bool `json:"success"`
+		Error   *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := w.call(methodImportDescriptors, anylist{reqs}, &res); err != nil {
+		return fmt.Errorf("importdescriptors error: %w", err)
+	}
+	for i, r := range res {
+		if !r.Success {
+			var msg string
+			if r.Error != nil {
+				msg = r.Error.Message
+			}
+			return fmt.Errorf("error importing descriptor %q: %s", reqs[i].Desc, msg)
+		}
+	}
+	w.log.Infof("Imported %d watch-only descriptors. Funds received before now require a rescan.", len(reqs))
+	return nil
+}
+
+// Reconfigure requires a restart if the watch-only settings change.
+func (w *psbtWallet) Reconfigure(cfg *asset.WalletConfig, currentAddress string) (restartRequired bool, err error) {
+	var newCfg psbtWalletConfig
+	if err := config.Unmapify(cfg.Settings, &newCfg); err != nil {
+		return false, err
+	}
+	if newCfg != *w.psbtCfg {
+		return true, nil
+	}
+	return w.rpcClient.Reconfigure(cfg, currentAddress)
+}
+
+// PrivKeyForAddress always errors. The wallet has no private keys.
+func (w *psbtWallet) PrivKeyForAddress(string) (*btcec.PrivateKey, error) {
+	return nil, errWatchOnly
+}
+
+// ChangeAddress returns the same change address until it is used, so that
+// retries of a transaction awaiting signatures create the same transaction.
+func (w *psbtWallet) ChangeAddress() (btcutil.Address, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.changeAddr != nil {
+		used, err := w.AddressUsed(w.changeAddr.String())
+		if err != nil {
+			return nil, err
+		}
+		if !used {
+			return w.changeAddr, nil
+		}
+	}
+	addr, err := w.rpcClient.ChangeAddress()
+	if err != nil {
+		return nil, err
+	}
+	w.changeAddr = addr
+	return addr, nil
+}
+
+// SignTx has the signer sign the tx.
+func (w *psbtWallet) SignTx(tx *wire.MsgTx) (*wire.MsgTx, error) {
+	return w.signContractInputs(tx, nil)
+}
+
+// stubSignTx adds placeholder P2WPKH witnesses of the maximum size.
+func (w *psbtWallet) stubSignTx(tx *wire.MsgTx) *wire.MsgTx {
+	stubTx := tx.Copy()
+	for _, txIn := range stubTx.TxIn {
+		txIn.Witness = wire.TxWitness{make([]byte, 73), make([]byte, 33)}
+	}
+	return stubTx
+}
+
+// signContractInputs has the signer sign the tx, which may spend swap
+// contracts. The returned tx is the tx of the signed or pending request for the
+// same inputs if there is one, so the caller must use the returned tx.
+func (w *psbtWallet) signContractInputs(tx *wire.MsgTx, contracts map[int]*contractInput) (*wire.MsgTx, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	for txHash, s := range w.signed {
+		if time.Since(s.stamp) > signedTxExpiration {
+			delete(w.signed, txHash)
+		}
+	}
+	signed, err := w.signedVersion(tx)
+	if err != nil {
+		return nil, err
+	}
+	if signed != nil {
+		return signed.Copy(), nil
+	}
+	txHash := tx.TxHash()
+
+	key := inputsKey(tx)
+	if p := w.pending[key]; p != nil {
+		if p.packet.UnsignedTx.TxHash() == txHash {
+			return w.requestSignatures(key, p)
+		}
+		same, err := w.sameExternalOutputs(p.packet.UnsignedTx, tx)
+		if err != nil {
+			return nil, err
+		}
+		if same {
+			return w.requestSignatures(key, p)
+		}
+		w.log.Infof("Replacing signing request for tx %s with tx %s", p.packet.UnsignedTx.TxHash(), txHash)
+		delete(w.pending, key)
+	}
+
+	packet, err := w.newPacket(tx, contracts)
+	if err != nil {
+		return nil, err
+	}
+	return w.requestSignatures(key, &pendingPSBT{packet: packet, contracts: contracts})
+}
+
+// requestTx returns the signed tx or the tx of the pending request that a
+// signing request for tx would return, or tx if there is neither.
+func (w *psbtWallet) requestTx(tx *wire.MsgTx) (*wire.MsgTx, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	signed, err := w.signedVersion(tx)
+	if err != nil {
+		return nil, err
+	}
+	if signed != nil {
+		return signed.Copy(), nil
+	}
+	p := w.pending[inputsKey(tx)]
+	if p == nil || p.packet.UnsignedTx.TxHash() == tx.TxHash() {
+		return tx, nil
+	}
+	same, err := w.sameExternalOutputs(p.packet.UnsignedTx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if same {
+		return p.packet.UnsignedTx.Copy(), nil
+	}
+	return tx, nil
+}
+
+// signedVersion returns the signed tx for the same inputs and external outputs
+// as tx, if there is one. A retry may differ in its outputs to the wallet, e.g.
+// when an operation awaiting other signatures is tried again after the tx was
+// signed. The mtx must be held.
+func (w *psbtWallet) signedVersion(tx *wire.MsgTx) (*wire.MsgTx, error) {
+	if s, found := w.signed[tx.TxHash()]; found {
+		return s.tx, nil
+	}
+	key := inputsKey(tx)
+	for _, s := range w.signed {
+		if inputsKey(s.tx) != key {
+			continue
+		}
+		same, err := w.sameExternalOutputs(s.tx, tx)
+		if err != nil {
+			return nil, err
+		}
+		if same {
+			return s.tx, nil
+		}
+	}
+	return nil, nil
+}
+
+// inputsKey identifies the inputs spent by a tx.
+func inputsKey(tx *wire.MsgTx) string {
+	ops := make([]string, 0, len(tx.TxIn))
+	for _, txIn := range tx.TxIn {
+		ops = append(ops, txIn.PreviousOutPoint.String())
+	}
+	return strings.Join(ops, ",")
+}
+
+// sameExternalOutputs checks whether the txs have the same outputs, ignoring
+// outputs to the wallet.
+func (w *psbtWallet) sameExternalOutputs(a, b *wire.MsgTx) (bool, error) {
+	externalOutputs := func(tx *wire.MsgTx) ([]*wire.TxOut, error) {
+		outs := make([]*wire.TxOut, 0, len(tx.TxOut))
+		for _, txOut := range tx.TxOut {
+			_, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.PkScript, w.chainParams)
+			if err == nil && len(addrs) == 1 {
+				owns, err := w.OwnsAddress(addrs[0])
+				if err != nil {
+					return nil, err
+				}
+				if owns {
+					continue
+				}
+			}
+			outs = append(outs, txOut)
+		}
+		return outs, nil
+	}
+	aOuts, err := externalOutputs(a)
+	if err != nil {
+		return false, err
+	}
+	bOuts, err := externalOutputs(b)
+	if err != nil {
+		return false, err
+	}
+	if len(aOuts) != len(bOuts) {
+		return false, nil
+	}
+	for i := range aOuts {
+		if aOuts[i].Value != bOuts[i].Value || !bytes.Equal(aOuts[i].PkScript, bOuts[i].PkScript) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// newPacket creates the PSBT for the tx. The wallet adds the input data for
+// its own inputs, and the contract inputs are described here.
+func (w *psbtWallet) newPacket(tx *wire.MsgTx, contracts map[int]*contractInput) (*psbt.Packet, error) {
+	unsignedTx := tx.Copy()
+	for _, txIn := range unsignedTx.TxIn {
+		txIn.SignatureScript, txIn.Witness = nil, nil
+	}
+	packet, err := psbt.NewFromUnsignedTx(unsignedTx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating PSBT: %w", err)
+	}
+	b64, err := packet.B64Encode()
+	if err != nil {
+		return nil, fmt.Errorf("error encoding PSBT: %w", err)
+	}
+	var res struct {
+		PSBT string `json:"psbt"`
+	}
+	// args: psbt sign sighashtype bip32derivs
+	if err := w.call(methodWalletProcessPSBT, anylist{b64, false, "ALL", true}, &res); err != nil {
+		return nil, fmt.Errorf("walletprocesspsbt error: %w", err)
+	}
+	packet, err = psbt.NewFromRawBytes(strings.NewReader(res.PSBT), true)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding processed PSBT: %w", err)
+	}
+	for i, ci := range contracts {
+		deriv, err := w.keyDerivation(ci.addr)
+		if err != nil {
+			return nil, err
+		}
+		ci.pubKey = deriv.PubKey
+		in := &packet.Inputs[i]
+		in.WitnessUtxo = wire.NewTxOut(ci.value, ci.pkScript)
+		in.WitnessScript = ci.contract
+		in.SighashType = txscript.SigHashAll
+		in.Bip32Derivation = []*psbt.Bip32Derivation{deriv}
+	}
+	return packet, nil
+}
+
+// keyDerivation gets the key derivation of a wallet address.
+func (w *psbtWallet) keyDerivation(addr btcutil.Address) (*psbt.Bip32Derivation, error) {
+	ai, err := w.getAddressInfo(addr, methodGetAddressInfo)
+	if err != nil {
+		return nil, fmt.Errorf("getaddressinfo error: %w", err)
+	}
+	desc, err := dexbtc.ParseDescriptor(ai.Descriptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor %q: %w", ai.Descriptor, err)
+	}
+	if desc.KeyOrigin == nil || desc.KeyFmt != dexbtc.KeyHexPub {
+		return nil, fmt.Errorf("address %s descriptor %q has no key origin", addr, ai.Descriptor)
+	}
+	pubKey, err := hex.DecodeString(desc.Key)
+	if err != nil {
+		return nil, fmt.Errorf("address pubkey not hexadecimal: %w", err)
+	}
+	fp, err := hex.DecodeString(desc.KeyOrigin.Fingerprint)
+	if err != nil || len(fp) != 4 {
+		return nil, fmt.Errorf("invalid key fingerprint %q", desc.KeyOrigin.Fingerprint)
+	}
+	return &psbt.Bip32Derivation{
+		PubKey:               pubKey,
+		MasterKeyFingerprint: binary.LittleEndian.Uint32(fp),
+		Bip32Path:            desc.KeyOrigin.Steps,
+	}, nil
+}
+
+// requestSignatures has the signer sign the pending request. If the signer
+// has not signed it yet, the request is kept for the next attempt.
+func (w *psbtWallet) requestSignatures(key string, p *pendingPSBT) (*wire.MsgTx, error) {
+	signedPacket, err := w.signer.SignPSBT(w.ctx, p.packet)
+	if err != nil {
+		if errors.Is(err, asset.ErrAwaitingSignature) {
+			w.pending[key] = p
+		}
+		return nil, err
+	}
+	if signedPacket.UnsignedTx.TxHash() != p.packet.UnsignedTx.TxHash() {
+		return nil, errors.New("signer signed a different transaction")
+	}
+	tx, err := finalizePacket(signedPacket, p.contracts)
+	if err != nil {
+		return nil, err
+	}
+	delete(w.pending, key)
+	w.signed[tx.TxHash()] = &signedTx{tx: tx, stamp: time.Now()}
+	return tx.Copy(), nil
+}
+
+// finalizePacket creates the witnesses from the signer's signatures, and
+// checks the signed tx.
+func finalizePacket(packet *psbt.Packet, contracts map[int]*contractInput) (*wire.MsgTx, error) {
+	for i := range packet.Inputs {
+		ci, isContract := contracts[i]
+		if !isContract {
+			in := &packet.Inputs[i]
+			if len(in.FinalScriptWitness) > 0 || len(in.FinalScriptSig) > 0 { // some signers finalize
+				continue
+			}
+			if err := psbt.Finalize(packet, i); err != nil {
+				return nil, fmt.Errorf("error finalizing input %d: %w", i, err)
+			}
+			continue
+		}
+		in := &packet.Inputs[i]
+		var partialSig *psbt.PartialSig
+		for _, ps := range in.PartialSigs {
+			if bytes.Equal(ps.PubKey, ci.pubKey) {
+				partialSig = ps
+				break
+			}
+		}
+		if partialSig == nil {
+			return nil, fmt.Errorf("no signature for contract input %d", i)
+		}
+		witness, err := serializeWitness(ci.witness(partialSig.Signature, partialSig.PubKey))
+		if err != nil {
+			return nil, err
+		}
+		in.FinalScriptWitness = witness
+	}
+	tx, err := psbt.Extract(packet)
+	if err != nil {
+		return nil, fmt.Errorf("error extracting signed tx: %w", err)
+	}
+
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+	for i, txIn := range tx.TxIn {
+		in := &packet.Inputs[i]
+		prevOut := in.WitnessUtxo
+		if prevOut == nil && in.NonWitnessUtxo != nil && int(txIn.PreviousOutPoint.Index) < len(in.NonWitnessUtxo.TxOut) {
+			prevOut = in.NonWitnessUtxo.TxOut[txIn.PreviousOutPoint.Index]
+		}
+		if prevOut == nil {
+			return nil, fmt.Errorf("no previous output for input %d", i)
+		}
+		prevOuts.AddPrevOut(txIn.PreviousOutPoint, prevOut)
+	}
+	sigHashes := txscript.NewTxSigHashes(tx, prevOuts)
+	for i, txIn := range tx.TxIn {
+		prevOut := prevOuts.FetchPrevOutput(txIn.PreviousOutPoint)
+		vm, err := txscript.NewEngine(prevOut.PkScript, tx, i, txscript.StandardVerifyFlags,
+			nil, sigHashes, prevOut.Value, prevOuts)
+		if err == nil {
+			err = vm.Execute()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature for input %d: %w", i, err)
+		}
+	}
+	return tx, nil
+}
+
+// serializeWitness serializes a witness for a PSBT's final script witness.
+func serializeWitness(witness wire.TxWitness) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := wire.WriteVarInt(&buf, 0, uint64(len(witness))); err != nil {
+		return nil, err
+	}
+	for _, item := range witness {
+		if err := wire.WriteVarBytes(&buf, 0, item); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// signHash has the signer sign the hash with the key of the address.
+func (w *psbtWallet) signHash(addrStr string, hash []byte) (pubKey, sig []byte, err error) {
+	addr, err := w.decodeAddr(addrStr, w.chainParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	deriv, err := w.keyDerivation(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, err = w.signer.SignHash(w.ctx, deriv, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	pk, err := btcec.ParsePubKey(deriv.PubKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid pubkey: %w", err)
+	}
+	signature, err := ecdsa.ParseDERSignature(sig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	if !signature.Verify(hash, pk) {
+		return nil, nil, fmt.Errorf("signer signed with the wrong key for address %s", addrStr)
+	}
+	return deriv.PubKey, sig, nil
+}
+
+// fileSigner is a PSBTSigner for air-gapped devices. For a transaction, the
+// signing request is written to <txid>.psbt in base64, and the signed PSBT is
+// expected in <txid>-signed.psbt, in binary or base64. For a message, the
+// request is written to <hash>.sighash.json, and the hex-encoded DER signature
+// is expected in <hash>.sig. The txid is of the unsigned transaction.
+type fileSigner struct {
+	dir string
+	log dex.Logger
+}
+
+var _ PSBTSigner = (*fileSigner)(nil)
+
+func newFileSigner(settings map[string]string, dataDir string, log dex.Logger) (PSBTSigner, error) {
+	dir := settings[psbtDirKey]
+	if dir == "" {
+		dir = filepath.Join(dataDir, "psbt")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("error creating PSBT directory: %w", err)
+	}
+	return &fileSigner{dir: dir, log: log}, nil
+}
+
+// respond reads the response to a request. If there is no response yet, the
+// request is written if it does not exist, and asset.ErrAwaitingSignature is
+// returned.
+func (s *fileSigner) respond(reqName, respName string, req func() ([]byte, error)) ([]byte, error) {
+	reqPath, respPath := filepath.Join(s.dir, reqName), filepath.Join(s.dir, respName)
+	resp, err := os.ReadFile(respPath)
+	if err == nil {
+		for _, path := range []string{reqPath, respPath} {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				s.log.Errorf("Error removing %s: %v", path, err)
+			}
+		}
+		return bytes.TrimSpace(resp), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error reading %s: %w", respPath, err)
+	}
+	if _, err := os.Stat(reqPath); errors.Is(err, os.ErrNotExist) {
+		b, err := req()
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(reqPath, b, 0600); err != nil {
+			return nil, fmt.Errorf("error writing signing request: %w", err)
+		}
+		s.log.Infof("Signing request written to %s", reqPath)
+	}
+	return nil, fmt.Errorf("%w: sign %s and save the result to %s", asset.ErrAwaitingSignature, reqPath, respPath)
+}
+
+// SignPSBT reads the signed PSBT, or writes the signing request.
+func (s *fileSigner) SignPSBT(_ context.Context, packet *psbt.Packet) (*psbt.Packet, error) {
+	txid := packet.UnsignedTx.TxHash().String()
+	resp, err := s.respond(txid+".psbt", txid+"-signed.psbt", func() ([]byte, error) {
+		b64, err := packet.B64Encode()
+		return []byte(b64), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	signed, err := psbt.NewFromRawBytes(bytes.NewReader(resp), !bytes.HasPrefix(resp, psbtMagic))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding signed PSBT for %s: %w", txid, err)
+	}
+	return signed, nil
+}
+
+// SignHash reads the signature, or writes the signing request.
+func (s *fileSigner) SignHash(_ context.Context, key *psbt.Bip32Derivation, hash []byte) ([]byte, error) {
+	id := hex.EncodeToString(hash)
+	resp, err := s.respond(id+".sighash.json", id+".sig", func() ([]byte, error) {
+		var fp [4]byte
+		binary.LittleEndian.PutUint32(fp[:], key.MasterKeyFingerprint)
+		return json.MarshalIndent(&struct {
+			Fingerprint string `json:"fingerprint"`
+			Path        string `json:"path"`
+			PubKey      string `json:"pubkey"`
+			Hash        string `json:"hash"`
+		}{
+			Fingerprint: hex.EncodeToString(fp[:]),
+			Path:        formatPath(key.Bip32Path),
+			PubKey:      hex.EncodeToString(key.PubKey),
+			Hash:        id,
+		}, "", "    ")
+	})
+	if err != nil {
+		return nil, err
+	}
+	sig, err := hex.DecodeString(string(resp))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding signature for %s: %w", id, err)
+	}
+	return sig, nil
+}
+
+// formatPath formats a derivation path, e.g. m/84'/0'/0'/0/1.
+func formatPath(path []uint32) string {
+	var sb strings.Builder
+	sb.WriteString("m")
+	for _, step := range path {
+		if step >= hdkeychain.HardenedKeyStart {
+			fmt.Fprintf(&sb, "/%d'", step-hdkeychain.HardenedKeyStart)
+		} else {
+			fmt.Fprintf(&sb, "/%d", step)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,383 @@
+//go:build !spvlive && !harness
+
+package btc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex/encode"
+	dexbtc "decred.org/dcrdex/dex/networks/btc"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestWatchDescriptors(t *testing.T) {
+	master, err := hdkeychain.NewMaster(encode.RandomBytes(32), &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acct, err := master.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	xpub := acct.String()
+
+	tests := []struct {
+		name            string
+		in              string
+		receive, change string
+		wantErr         bool
+	}{{
+		name:    "xpub",
+		in:      " " + xpub + "\n",
+		receive: "wpkh(" + xpub + "/0/*)",
+		change:  "wpkh(" + xpub + "/1/*)",
+	}, {
+		name:    "descriptor with origin and checksum",
+		in:      "wpkh([d34db33f/84'/0'/0']" + xpub + "/0/*)#abcdefgh",
+		receive: "wpkh([d34db33f/84'/0'/0']" + xpub + "/0/*)",
+		change:  "wpkh([d34db33f/84'/0'/0']" + xpub + "/1/*)",
+	}, {
+		name:    "empty",
+		wantErr: true,
+	}, {
+		name:    "bad xpub",
+		in:      "xpubnope",
+		wantErr: true,
+	}, {
+		name:    "change branch",
+		in:      "wpkh(" + xpub + "/1/*)",
+		wantErr: true,
+	}, {
+		name:    "not wpkh",
+		in:      "pkh(" + xpub + "/0/*)",
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		receive, change, err := watchDescriptors(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("%s: no error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if receive != tt.receive || change != tt.change {
+			t.Fatalf("%s: wrong descriptors %q, %q", tt.name, receive, change)
+		}
+	}
+}
+
+func TestFormatPath(t *testing.T) {
+	h := uint32(hdkeychain.HardenedKeyStart)
+	if s := formatPath([]uint32{84 + h, h, h, 1, 5}); s != "m/84'/0'/0'/1/5" {
+		t.Fatalf("wrong path %s", s)
+	}
+	if s := formatPath(nil); s != "m" {
+		t.Fatalf("wrong empty path %s", s)
+	}
+}
+
+// tPSBTSpend creates a tx spending a P2WPKH output and a P2WSH swap contract
+// refund, both belonging to priv.
+func tPSBTSpend(t *testing.T, priv *btcec.PrivateKey) (*wire.MsgTx, map[int]*contractInput, []*wire.TxOut) {
+	t.Helper()
+	pubKey := priv.PubKey().SerializeCompressed()
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(pubKey), &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2wpkh, _ := txscript.PayToAddrScript(addr)
+
+	const lockTime = 100
+	counterParty, _ := btcutil.NewAddressWitnessPubKeyHash(encode.RandomBytes(20), &chaincfg.MainNetParams)
+	contract, err := dexbtc.MakeContract(counterParty, addr, encode.RandomBytes(32), lockTime, true, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scriptHash := chainhash.HashB(contract)
+	p2wsh, _ := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(scriptHash).Script()
+
+	prevOuts := []*wire.TxOut{wire.NewTxOut(1e6, p2wpkh), wire.NewTxOut(2e6, p2wsh)}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.LockTime = lockTime
+	for i := range prevOuts {
+		var h chainhash.Hash
+		copy(h[:], encode.RandomBytes(32))
+		txIn := wire.NewTxIn(wire.NewOutPoint(&h, uint32(i)), nil, nil)
+		txIn.Sequence = wire.MaxTxInSequenceNum - 1
+		tx.AddTxIn(txIn)
+	}
+	tx.AddTxOut(wire.NewTxOut(29e5, p2wpkh))
+
+	contracts := map[int]*contractInput{1: {
+		value:    prevOuts[1].Value,
+		pkScript: p2wsh,
+		contract: contract,
+		addr:     addr,
+		pubKey:   pubKey,
+		witness: func(sig, pubKey []byte) wire.TxWitness {
+			return dexbtc.RefundP2WSHContract(contract, sig, pubKey)
+		},
+	}}
+	return tx, contracts, prevOuts
+}
+
+func TestFileSigner(t *testing.T) {
+	dir := t.TempDir()
+	signer, err := newFileSigner(map[string]string{psbtDirKey: dir}, "", tLogger)
+	if err != nil {
+		t.Fatalf("newFileSigner error: %v", err)
+	}
+	priv, _ := btcec.NewPrivateKey()
+	pubKey := priv.PubKey().SerializeCompressed()
+
+	tx, contracts, prevOuts := tPSBTSpend(t, priv)
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, prevOut := range prevOuts {
+		packet.Inputs[i].WitnessUtxo = prevOut
+		packet.Inputs[i].SighashType = txscript.SigHashAll
+	}
+	packet.Inputs[1].WitnessScript = contracts[1].contract
+
+	txid := tx.TxHash().String()
+	reqPath := filepath.Join(dir, txid+".psbt")
+	respPath := filepath.Join(dir, txid+"-signed.psbt")
+
+	// The first attempt writes the request.
+	if _, err := signer.SignPSBT(context.Background(), packet); !errors.Is(err, asset.ErrAwaitingSignature) {
+		t.Fatalf("expected ErrAwaitingSignature, got %v", err)
+	}
+	b64, err := os.ReadFile(reqPath)
+	if err != nil {
+		t.Fatalf("signing request not written: %v", err)
+	}
+	// And so does a retry before it's signed.
+	if _, err := signer.SignPSBT(context.Background(), packet); !errors.Is(err, asset.ErrAwaitingSignature) {
+		t.Fatalf("expected ErrAwaitingSignature on retry, got %v", err)
+	}
+
+	// Sign it like an air-gapped device would.
+	req, err := psbt.NewFromRawBytes(bytes.NewReader(b64), true)
+	if err != nil {
+		t.Fatalf("error decoding request: %v", err)
+	}
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, txIn := range req.UnsignedTx.TxIn {
+		fetcher.AddPrevOut(txIn.PreviousOutPoint, req.Inputs[i].WitnessUtxo)
+	}
+	sigHashes := txscript.NewTxSigHashes(req.UnsignedTx, fetcher)
+	var p2wpkhSig []byte
+	for i := range req.Inputs {
+		in := &req.Inputs[i]
+		script := in.WitnessScript
+		if script == nil { // p2wpkh
+			script = in.WitnessUtxo.PkScript
+		}
+		sig, err := txscript.RawTxInWitnessSignature(req.UnsignedTx, sigHashes, i,
+			in.WitnessUtxo.Value, script, txscript.SigHashAll, priv)
+		if err != nil {
+			t.Fatalf("error signing input %d: %v", i, err)
+		}
+		in.PartialSigs = []*psbt.PartialSig{{PubKey: pubKey, Signature: sig}}
+		if i == 0 {
+			p2wpkhSig = sig
+		}
+	}
+	var buf bytes.Buffer
+	if err := req.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(respPath, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := signer.SignPSBT(context.Background(), packet)
+	if err != nil {
+		t.Fatalf("error reading signed PSBT: %v", err)
+	}
+	for _, path := range []string{reqPath, respPath} {
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("%s not removed", path)
+		}
+	}
+	signedTx, err := finalizePacket(signed, contracts)
+	if err != nil {
+		t.Fatalf("finalizePacket error: %v", err)
+	}
+	if signedTx.TxHash() != tx.TxHash() {
+		t.Fatalf("signed tx has the wrong txid")
+	}
+
+	// A bad signature fails verification.
+	signed.Inputs[1].FinalScriptWitness = nil
+	signed.Inputs[1].PartialSigs[0].Signature = p2wpkhSig
+	if _, err := finalizePacket(signed, contracts); err == nil || !strings.Contains(err.Error(), "input 1") {
+		t.Fatalf("wrong error for invalid contract signature: %v", err)
+	}
+
+	// Messages.
+	hash := chainhash.HashB([]byte("message"))
+	deriv := &psbt.Bip32Derivation{PubKey: pubKey, MasterKeyFingerprint: 0xd34db33f}
+	if _, err := signer.SignHash(context.Background(), deriv, hash); !errors.Is(err, asset.ErrAwaitingSignature) {
+		t.Fatalf("expected ErrAwaitingSignature for message, got %v", err)
+	}
+	id := hex.EncodeToString(hash)
+	if _, err := os.Stat(filepath.Join(dir, id+".sighash.json")); err != nil {
+		t.Fatalf("message signing request not written: %v", err)
+	}
+	sig := ecdsa.Sign(priv, hash).Serialize()
+	if err := os.WriteFile(filepath.Join(dir, id+".sig"), []byte(hex.EncodeToString(sig)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	gotSig, err := signer.SignHash(context.Background(), deriv, hash)
+	if err != nil {
+		t.Fatalf("error reading signature: %v", err)
+	}
+	if !bytes.Equal(gotSig, sig) {
+		t.Fatalf("wrong signature")
+	}
+}
+
+// tExternalSigner is an RPC wallet whose transactions are signed by an
+// external signer. Requests are recorded, and are not signed while awaiting is
+// set.
+type tExternalSigner struct {
+	*rpcClient
+	awaiting bool
+	requests []*wire.MsgTx
+}
+
+var _ externalSigner = (*tExternalSigner)(nil)
+
+func (w *tExternalSigner) PrivKeyForAddress(string) (*btcec.PrivateKey, error) {
+	return nil, errWatchOnly
+}
+
+func (w *tExternalSigner) SignTx(tx *wire.MsgTx) (*wire.MsgTx, error) {
+	return w.signContractInputs(tx, nil)
+}
+
+func (w *tExternalSigner) stubSignTx(tx *wire.MsgTx) *wire.MsgTx {
+	stubTx := tx.Copy()
+	for _, txIn := range stubTx.TxIn {
+		txIn.Witness = wire.TxWitness{make([]byte, 73), make([]byte, 33)}
+	}
+	return stubTx
+}
+
+func (w *tExternalSigner) signContractInputs(tx *wire.MsgTx, _ map[int]*contractInput) (*wire.MsgTx, error) {
+	w.requests = append(w.requests, tx.Copy())
+	if w.awaiting {
+		return nil, asset.ErrAwaitingSignature
+	}
+	return w.stubSignTx(tx), nil
+}
+
+func (w *tExternalSigner) requestTx(tx *wire.MsgTx) (*wire.MsgTx, error) {
+	return tx, nil
+}
+
+func (w *tExternalSigner) signHash(string, []byte) (pubKey, sig []byte, err error) {
+	return nil, nil, errWatchOnly
+}
+
+func TestExternalSignerDepositAddress(t *testing.T) {
+	wallet, node, shutdown := tNewWallet(true, walletTypeRPC)
+	defer shutdown()
+	wallet.setNode(&tExternalSigner{rpcClient: wallet.node.(*rpcClient)})
+	node.newAddress = tP2WPKHAddr
+
+	// The wallet is not locked, but it has no private keys to check.
+	addr, err := wallet.DepositAddress()
+	if err != nil {
+		t.Fatalf("DepositAddress error: %v", err)
+	}
+	if addr != tP2WPKHAddr {
+		t.Fatalf("wrong deposit address %s", addr)
+	}
+}
+
+func TestExternalSignerSwapRefunds(t *testing.T) {
+	wallet, node, shutdown := tNewWallet(true, walletTypeRPC)
+	defer shutdown()
+	signer := &tExternalSigner{rpcClient: wallet.node.(*rpcClient), awaiting: true}
+	wallet.setNode(signer)
+	node.newAddress = tP2WPKHAddr
+	node.changeAddr = tP2WPKHAddr
+
+	secretHash := encode.RandomBytes(32)
+	swaps := &asset.Swaps{
+		Inputs: asset.Coins{NewOutput(tTxHash, 0, toSatoshi(10))},
+		Contracts: []*asset.Contract{{
+			Address:    tP2WPKHAddr,
+			Value:      toSatoshi(2),
+			SecretHash: secretHash,
+			LockTime:   uint64(time.Now().Unix()),
+		}, {
+			Address:    tP2WPKHAddr,
+			Value:      toSatoshi(3),
+			SecretHash: secretHash,
+			LockTime:   uint64(time.Now().Unix()),
+		}},
+		FeeRate: tBTC.MaxFeeRate,
+	}
+
+	// The backup refunds are requested with the swap.
+	if _, _, _, err := wallet.Swap(swaps); !errors.Is(err, asset.ErrAwaitingSignature) {
+		t.Fatalf("expected ErrAwaitingSignature, got %v", err)
+	}
+	if len(signer.requests) != 3 {
+		t.Fatalf("expected 3 signing requests, got %d", len(signer.requests))
+	}
+	swapHash := signer.requests[0].TxHash()
+	for i, refundTx := range signer.requests[1:] {
+		if op := refundTx.TxIn[0].PreviousOutPoint; op.Hash != swapHash || op.Index != uint32(i) {
+			t.Fatalf("refund %d spends %s, not swap output %s:%d", i, op, swapHash, i)
+		}
+	}
+	if node.sentRawTx != nil {
+		t.Fatalf("swap broadcasted before it was signed")
+	}
+
+	// Once signed, the receipts include the backup refunds.
+	signer.awaiting = false
+	receipts, _, _, err := wallet.Swap(swaps)
+	if err != nil {
+		t.Fatalf("swap error: %v", err)
+	}
+	if node.sentRawTx == nil || node.sentRawTx.TxHash() != swapHash {
+		t.Fatalf("signed swap not broadcasted")
+	}
+	for i, receipt := range receipts {
+		refundTx, err := msgTxFromBytes(receipt.SignedRefund())
+		if err != nil {
+			t.Fatalf("receipt %d has no valid refund tx: %v", i, err)
+		}
+		if op := refundTx.TxIn[0].PreviousOutPoint; op.Hash != swapHash || op.Index != uint32(i) {
+			t.Fatalf("receipt %d refund spends %s", i, op)
+		}
+	}
+}
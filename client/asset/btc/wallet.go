@@ -61,6 +61,24 @@ type TxFeeEstimator interface {
 	EstimateSendTxFee(tx *wire.MsgTx, feeRate uint64, subtract bool) (fee uint64, err error)
 }
 
+// externalSigner is implemented by a Wallet that has no private keys. Its
+// transactions are signed by an external signer, which returns
+// asset.ErrAwaitingSignature until it has signed.
+type externalSigner interface {
+	// stubSignTx adds placeholder signatures to the tx so that its size can
+	// be estimated without a signing request.
+	stubSignTx(tx *wire.MsgTx) *wire.MsgTx
+	// signContractInputs signs a tx that spends swap contracts. The returned
+	// tx may be an earlier version of the tx, so it must be used in place of
+	// the tx passed in.
+	signContractInputs(tx *wire.MsgTx, contracts map[int]*contractInput) (*wire.MsgTx, error)
+	// requestTx returns the tx that signing tx would return, which is the tx
+	// of a pending signing request for the same inputs, if there is one.
+	requestTx(tx *wire.MsgTx) (*wire.MsgTx, error)
+	// signHash signs the hash with the key of the address.
+	signHash(addr string, hash []byte) (pubKey, sig []byte, err error)
+}
+
 // walletTxChecker provide a fast wallet tx query when block info not needed.
 // This should be treated as an optimization method, where getWalletTransaction
 // may always be used in its place if it does not exists.
@@ -3469,18 +3469,7 @@ func (dcr *ExchangeWallet) ValidateAdaptorSecret(adaptorSecret *btcec.ModNScalar
 }
 
 func privateContractPkScript(contract *asset.PrivateContract, params stdaddr.AddressParamsV0) (contractScript, pkScript []byte, err error) {
-	redeemPKH := stdaddr.Hash160(contract.RedeemPublicKey)
-	refundPKH := stdaddr.Hash160(contract.RefundPublicKey)
-	contractScript, err = dexdcr.MakePrivateContract(redeemPKH, refundPKH, int64(contract.LockTime))
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create pubkey script: %w", err)
-	}
-	addr, err := stdaddr.NewAddressScriptHashV0(contractScript, params)
-	if err != nil {
-		return nil, nil, fmt.Errorf("error encoding script address: %w", err)
-	}
-	_, pkScript = addr.PaymentScript()
-	return contractScript, pkScript, nil
+	return dexdcr.PrivateContractPkScript(contract.RedeemPublicKey, contract.RefundPublicKey, int64(contract.LockTime), params)
 }
 
 // GeneratePrivateKeyTweakedAdaptor generates an adaptor signature for the provided
@@ -5413,6 +5402,29 @@ func (dcr *ExchangeWallet) ValidateSecret(secret, secretHash []byte) bool {
 //
 // If the coin is located, but recognized as spent, no error is returned.
 func (dcr *ExchangeWallet) SwapConfirmations(ctx context.Context, coinID, contract dex.Bytes, matchTime time.Time) (confs uint32, spent bool, err error) {
+	// Prepare the pkScript to find the contract output using block filters.
+	scriptAddr, err := stdaddr.NewAddressScriptHashV0(contract, dcr.chainParams)
+	if err != nil {
+		return 0, false, fmt.Errorf("error encoding script address: %w", err)
+	}
+	_, p2shScript := scriptAddr.PaymentScript()
+	return dcr.swapConfirmations(ctx, coinID, p2shScript, matchTime)
+}
+
+// PrivateSwapConfirmations gets the number of confirmations and the spend
+// status for a private swap output.
+func (dcr *ExchangeWallet) PrivateSwapConfirmations(ctx context.Context, coinID dex.Bytes, contract *asset.PrivateContract, matchTime time.Time) (confs uint32, spent bool, err error) {
+	_, pkScript, err := privateContractPkScript(contract, dcr.chainParams)
+	if err != nil {
+		return 0, false, err
+	}
+	return dcr.swapConfirmations(ctx, coinID, pkScript, matchTime)
+}
+
+// swapConfirmations gets the number of confirmations and the spend status of
+// the swap output with the pkScript. Block filters are scanned from the match
+// time if the output is not found by the wallet.
+func (dcr *ExchangeWallet) swapConfirmations(ctx context.Context, coinID, pkScript dex.Bytes, matchTime time.Time) (confs uint32, spent bool, err error) {
 	txHash, vout, err := decodeCoinID(coinID)
 	if err != nil {
 		return 0, false, err
@@ -5432,19 +5444,12 @@ func (dcr *ExchangeWallet) SwapConfirmations(ctx context.Context, coinID, contra
 		return 0, false, err
 	}
 
-	// Prepare the pkScript to find the contract output using block filters.
-	scriptAddr, err := stdaddr.NewAddressScriptHashV0(contract, dcr.chainParams)
-	if err != nil {
-		return 0, false, fmt.Errorf("error encoding script address: %w", err)
-	}
-	_, p2shScript := scriptAddr.PaymentScript()
-
 	// Find the contract and its spend status using block filters.
-	confs, spent, err = dcr.lookupTxOutWithBlockFilters(ctx, newOutPoint(txHash, vout), p2shScript, matchTime)
+	confs, spent, err = dcr.lookupTxOutWithBlockFilters(ctx, newOutPoint(txHash, vout), pkScript, matchTime)
 	// Don't trouble the caller if we're using an SPV wallet and the transaction
 	// cannot be located.
 	if errors.Is(err, asset.CoinNotFoundError) && dcr.wallet.SpvMode() {
-		dcr.log.Debugf("SwapConfirmations - cfilters scan did not find %v:%d. "+
+		dcr.log.Debugf("swapConfirmations - cfilters scan did not find %v:%d. "+
 			"Assuming in mempool.", txHash, vout)
 		err = nil
 	}
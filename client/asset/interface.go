@@ -258,6 +258,10 @@ const (
 	// to redeem a swap, but configuring a bundler to redeem would fix the
 	// issue.
 	ErrInsufficientRedeemFunds = dex.ErrorKind("insufficient redeem funds")
+	// ErrAwaitingSignature is returned when a transaction or message must be
+	// signed by an external signer that has not signed it yet. The caller
+	// should retry the same operation later.
+	ErrAwaitingSignature = dex.ErrorKind("awaiting external signature")
 
 	// ErrBundlerRedemptionLotSizeTooSmall is returned when the lot size is
 	// too small to cover the gas fees when using a bundler.
@@ -607,6 +611,10 @@ type PrivateSwapper interface {
 	// This ensures the counterparty has locked funds into the agreed-upon
 	// script and amount.
 	AuditPrivateContract(coinID, txData []byte, contract *PrivateContract, rebroadcast bool) error
+	// PrivateSwapConfirmations gets the number of confirmations and the spend
+	// status for a private swap output. The matchTime is the earliest time
+	// the swap could have been broadcast, and may be used to limit a search.
+	PrivateSwapConfirmations(ctx context.Context, coinID dex.Bytes, contract *PrivateContract, matchTime time.Time) (confs uint32, spent bool, err error)
 	// GenerateUnsignedRedeemTx creates the unsigned transaction that will be
 	// used to redeem the swap funds. This transaction is sent to the
 	// counterparty, who will create an adaptor signature for it.
@@ -1062,6 +1070,14 @@ type GaslessRedeemer interface {
 	GaslessRedeem(redeems *RedeemForm) (ins []dex.Bytes, out Coin, feesPaid uint64, submitted bool, err error)
 }
 
+// RedemptionSigner is implemented by wallets that can sign a redemption
+// without broadcasting it, e.g. to hand it to a watchtower.
+type RedemptionSigner interface {
+	// SignRedemption creates and signs, but does not broadcast, a transaction
+	// that redeems the form's swaps, returning the serialized transaction.
+	SignRedemption(form *RedeemForm) ([]byte, error)
+}
+
 // LiveReconfigurer is a wallet that can possibly handle a reconfiguration
 // without the need for re-initialization.
 type LiveReconfigurer interface {
@@ -1301,6 +1317,10 @@ const (
 	Mix
 	InitiateBridge
 	CompleteBridge
+	// Shield and Deshield move funds between a wallet's transparent and
+	// shielded balances.
+	Shield
+	Deshield
 )
 
 // IncomingTxType returns true if the wallet's balance increases due to a
@@ -0,0 +1,172 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package zec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"decred.org/dcrdex/client/asset"
+	dexbtc "decred.org/dcrdex/dex/networks/btc"
+	dexzec "decred.org/dcrdex/dex/networks/zec"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	autoShieldKey      = "autoshield"
+	shieldThresholdKey = "shieldthreshold"
+	// defaultShieldThreshold is the default spendable transparent balance, in
+	// ZEC, at which funds are auto-shielded.
+	defaultShieldThreshold = 1.0
+
+	// anyTAddr is the z_sendmany fromaddress that selects from all of the
+	// wallet's unlocked transparent outputs.
+	anyTAddr = "ANY_TADDR"
+	// nActionsOrchardShield is the number of orchard actions in a shielding
+	// tx. One orchard output is padded to two actions.
+	nActionsOrchardShield = 2
+
+	actionTypeShieldFailed = "shieldFailed"
+	// shieldActionID is the unique ID of the shieldFailed ActionRequired
+	// notification. There is only ever one shielding request outstanding.
+	shieldActionID = "zecAutoShield"
+)
+
+var _ asset.ActionTaker = (*zecWallet)(nil)
+
+// shieldFailedNote is the payload of the shieldFailed ActionRequired
+// notification.
+type shieldFailedNote struct {
+	Amount uint64 `json:"amount"`
+	Error  string `json:"error"`
+}
+
+// shieldFailedAction is the user's response to a shieldFailed ActionRequired
+// notification.
+type shieldFailedAction struct {
+	Retry bool `json:"retry"`
+}
+
+// shieldableFunds sums the wallet's confirmed, unlocked transparent outputs,
+// which are the outputs z_sendmany will select from with ANY_TADDR. pending is
+// the number of outputs that are not confirmed yet.
+func (w *zecWallet) shieldableFunds() (sum, fees uint64, n, pending int, err error) {
+	utxos, _, _, err := w.cm.SpendableUTXOs(0)
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("error listing utxos: %w", err)
+	}
+	for _, u := range utxos {
+		if u.Confs < minOrchardConfs {
+			pending++
+			continue
+		}
+		sum += u.Amount
+		n++
+	}
+	inputsSize := uint64(n)*dexbtc.RedeemP2PKHInputSize + uint64(wire.VarIntSerializeSize(uint64(n)))
+	fees = dexzec.TxFeesZIP317(inputsSize, 0, 0, 0, 0, nActionsOrchardShield)
+	return sum, fees, n, pending, nil
+}
+
+// shieldTransparent moves all of the wallet's confirmed, unlocked transparent
+// funds into the Orchard pool. Outputs locked for order funding are not spent.
+func (w *zecWallet) shieldTransparent(ctx context.Context) (*chainhash.Hash, uint64, error) {
+	sum, fees, n, _, err := w.shieldableFunds()
+	if err != nil {
+		return nil, 0, err
+	}
+	if n == 0 || sum <= fees {
+		return nil, 0, fmt.Errorf("not enough transparent funds to shield: %s in %d outputs",
+			btcutil.Amount(sum), n)
+	}
+	toAddr, err := w.lastShieldedAddress()
+	if err != nil {
+		return nil, 0, fmt.Errorf("lastShieldedAddress error: %w", err)
+	}
+	amt := sum - fees
+	txHash, err := w.sendOne(ctx, anyTAddr, toAddr, amt, AllowRevealedSenders)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error sending shielding tx: %w", err)
+	}
+	w.log.Infof("Shielded %s from %d transparent outputs in tx %s", btcutil.Amount(amt), n, txHash)
+	w.addTxToHistory(&asset.WalletTransaction{
+		Type:   asset.Shield,
+		ID:     txHash.String(),
+		Amount: amt,
+		Fees:   fees,
+	}, txHash, true)
+	return txHash, amt, nil
+}
+
+// checkAutoShield applies the auto-shield policy after a redemption. Once the
+// redeemed outputs are confirmed, the transparent balance is shielded if it is
+// at or above the configured threshold. If shielding fails, the user is asked
+// whether to try again.
+func (w *zecWallet) checkAutoShield(ctx context.Context) {
+	if !w.shielding.CompareAndSwap(false, true) {
+		return
+	}
+	defer w.shielding.Store(false)
+
+	cfg := w.walletCfg.Load().(*WalletConfig)
+	if !cfg.AutoShield {
+		w.shieldCheck.Store(false)
+		return
+	}
+	sum, fees, _, pending, err := w.shieldableFunds()
+	if err != nil {
+		w.log.Errorf("Error checking transparent funds for auto-shield: %v", err)
+		return
+	}
+	if sum < toZats(cfg.ShieldThreshold) || sum <= fees {
+		// Keep checking while redeemed outputs are still confirming.
+		if pending == 0 {
+			w.shieldCheck.Store(false)
+		}
+		return
+	}
+	w.shieldCheck.Store(false)
+	if _, _, err := w.shieldTransparent(ctx); err != nil {
+		w.log.Errorf("Auto-shield failed: %v", err)
+		w.emit.ActionRequired(shieldActionID, actionTypeShieldFailed, &shieldFailedNote{
+			Amount: sum - fees,
+			Error:  err.Error(),
+		})
+	}
+}
+
+// TakeAction satisfies asset.ActionTaker. This handles the user's response to
+// a failed auto-shield.
+func (w *zecWallet) TakeAction(actionID string, actionB []byte) error {
+	switch actionID {
+	case actionTypeShieldFailed:
+		var action shieldFailedAction
+		if err := json.Unmarshal(actionB, &action); err != nil {
+			return fmt.Errorf("error unmarshaling shield action: %w", err)
+		}
+		if action.Retry {
+			if _, _, err := w.shieldTransparent(w.ctx); err != nil {
+				return err
+			}
+		}
+		w.emit.ActionResolved(shieldActionID)
+		return nil
+	default:
+		return fmt.Errorf("unknown action %q", actionID)
+	}
+}
+
+// recordDeshield adds a tx that moved shielded funds to transparent outputs
+// for order funding to the tx history.
+func (w *zecWallet) recordDeshield(txHash *chainhash.Hash, tx *zTx, amt uint64) {
+	w.addTxToHistory(&asset.WalletTransaction{
+		Type:   asset.Deshield,
+		ID:     txHash.String(),
+		Amount: amt,
+		Fees:   tx.RequiredTxFeesZIP317(),
+	}, txHash, true)
+}
@@ -126,6 +126,21 @@ var (
 				"Used only for standing-type orders, e.g. limit orders without immediate time-in-force.",
 			IsBoolean: true,
 		},
+		{
+			Key:         autoShieldKey,
+			DisplayName: "Auto-shield trade proceeds",
+			Description: "Move redeemed funds from transparent addresses into the Orchard shielded pool once the " +
+				"spendable transparent balance reaches the shielding threshold. Shielded funds are moved back to a " +
+				"transparent address automatically when an order needs them.",
+			IsBoolean: true,
+		},
+		{
+			Key:          shieldThresholdKey,
+			DisplayName:  "Shielding threshold",
+			Description:  "The spendable transparent balance at which redeemed funds are shielded. Units: ZEC",
+			DefaultValue: strconv.FormatFloat(defaultShieldThreshold, 'f', -1, 64),
+			DependsOn:    autoShieldKey,
+		},
 	}
 	// WalletInfo defines some general information about a Zcash wallet.
 	WalletInfo = &asset.WalletInfo{
@@ -186,9 +201,11 @@ func (d *Driver) MinLotSize(maxFeeRate uint64) uint64 {
 
 // WalletConfig are wallet-level configuration settings.
 type WalletConfig struct {
-	UseSplitTx       bool   `ini:"txsplit"`
-	RedeemConfTarget uint64 `ini:"redeemconftarget"`
-	ActivelyUsed     bool   `ini:"special_activelyUsed"` // injected by core
+	UseSplitTx       bool    `ini:"txsplit"`
+	RedeemConfTarget uint64  `ini:"redeemconftarget"`
+	AutoShield       bool    `ini:"autoshield"`
+	ShieldThreshold  float64 `ini:"shieldthreshold"`      // ZEC
+	ActivelyUsed     bool    `ini:"special_activelyUsed"` // injected by core
 }
 
 func newRPCConnection(cfg *dexbtc.RPCConfig) (*rpcclient.Client, error) {
@@ -230,11 +247,14 @@ func NewWallet(cfg *asset.WalletConfig, logger dex.Logger, net dex.Network) (ass
 		Simnet:  "18232",
 	}
 
-	var walletCfg WalletConfig
+	walletCfg := WalletConfig{ShieldThreshold: defaultShieldThreshold}
 	err := config.Unmapify(cfg.Settings, &walletCfg)
 	if err != nil {
 		return nil, err
 	}
+	if walletCfg.ShieldThreshold < 0 {
+		return nil, fmt.Errorf("negative shielding threshold %f", walletCfg.ShieldThreshold)
+	}
 
 	if err := os.MkdirAll(cfg.DataDir, 0700); err != nil {
 		return nil, fmt.Errorf("error creating data directory at %q: %w", cfg.DataDir, err)
@@ -332,6 +352,11 @@ type zecWallet struct {
 
 	txHistoryDB      atomic.Value // *btc.BadgerTxDB
 	syncingTxHistory atomic.Bool
+
+	// shieldCheck is set when a redemption is sent, and cleared once the
+	// auto-shield policy has been applied to the redeemed funds.
+	shieldCheck atomic.Bool
+	shielding   atomic.Bool
 }
 
 var _ asset.FeeRater = (*zecWallet)(nil)
@@ -680,6 +705,10 @@ func (w *zecWallet) reportNewTip(ctx context.Context, newTip *btc.BlockVector) {
 	w.rf.ReportNewTip(ctx, prevTip, newTip)
 
 	w.syncTxHistory(uint64(newTip.Height))
+
+	if w.shieldCheck.Load() {
+		go w.checkAutoShield(ctx)
+	}
 }
 
 type swapOptions struct {
@@ -761,6 +790,7 @@ func (w *zecWallet) FundOrder(ord *asset.Order) (asset.Coins, []dex.Bytes, uint6
 		if err != nil {
 			return nil, nil, 0, fmt.Errorf("error retreiving split transaction %s: %w", txHash, err)
 		}
+		w.recordDeshield(txHash, tx, shieldedSplitNeeded)
 
 		var splitOutput *wire.TxOut
 		var splitOutputIndex int
@@ -967,6 +997,7 @@ func (w *zecWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Coin, uin
 		Amount: totalIn,
 		Fees:   fee,
 	}, txHash, true)
+	w.shieldCheck.Store(true)
 
 	// Log the change output.
 	coinIDs := make([]dex.Bytes, 0, len(form.Redemptions))
@@ -1769,6 +1800,12 @@ func (w *zecWallet) FundMultiOrder(mo *asset.MultiOrder, maxLock uint64) (coins
 
 	fundingFees = tx.RequiredTxFeesZIP317()
 
+	var deshielded uint64
+	for _, req := range orderReqs {
+		deshielded += req
+	}
+	w.recordDeshield(txHash, tx, deshielded)
+
 	txOuts := make(map[uint32]*wire.TxOut, len(mo.Values))
 	for vout, txOut := range tx.TxOut {
 		txOuts[uint32(vout)] = txOut
@@ -1141,6 +1141,121 @@ func TestSend(t *testing.T) {
 	}
 }
 
+func TestAutoShield(t *testing.T) {
+	w, cl, shutdown := tNewWallet()
+	defer shutdown()
+	defer cl.checkEmptiness(t)
+
+	emitChan := make(chan asset.WalletNotification, 16)
+	w.emit = asset.NewWalletEmitter(emitChan, BipID, tLogger)
+	w.ctx = tCtx
+	w.lastAddress.Store(tUnifiedAddr)
+	cfg := &WalletConfig{AutoShield: true, ShieldThreshold: 1}
+	w.walletCfg.Store(cfg)
+
+	utxo := &btc.ListUnspentResult{
+		TxID:          tTxID,
+		Address:       "tmH2m5fi5yY3Qg2GpGwcCrnnoD4wp944RMJ",
+		Amount:        0.5,
+		Confirmations: 1,
+		ScriptPubKey:  tP2PKH,
+		Spendable:     true,
+		Solvable:      true,
+		SafePtr:       boolPtr(true),
+	}
+	unspents := []*btc.ListUnspentResult{utxo}
+	successOp := []*operationStatus{{
+		Status: "success",
+		Result: &opResult{TxID: tTxID},
+	}}
+	checkShield := func(expSend bool) {
+		t.Helper()
+		w.shieldCheck.Store(true)
+		cl.queueResponse("listunspent", unspents)
+		if expSend {
+			cl.queueResponse("listunspent", unspents)
+			cl.queueResponse(methodZSendMany, "opid-123456")
+			cl.queueResponse(methodZGetOperationResult, successOp)
+		}
+		w.checkAutoShield(tCtx)
+	}
+
+	// Below the threshold.
+	checkShield(false)
+	if w.shieldCheck.Load() {
+		t.Fatalf("shieldCheck not cleared below threshold")
+	}
+
+	// Below the threshold, but still confirming.
+	utxo.Confirmations = 0
+	checkShield(false)
+	if !w.shieldCheck.Load() {
+		t.Fatalf("shieldCheck cleared with pending outputs")
+	}
+
+	// At the threshold.
+	utxo.Confirmations = 1
+	utxo.Amount = 1
+	checkShield(true)
+	if w.shieldCheck.Load() {
+		t.Fatalf("shieldCheck not cleared after shielding")
+	}
+
+	// Disabled.
+	cfg.AutoShield = false
+	w.shieldCheck.Store(true)
+	w.checkAutoShield(tCtx)
+	if w.shieldCheck.Load() {
+		t.Fatalf("shieldCheck not cleared with auto-shield disabled")
+	}
+	cfg.AutoShield = true
+
+	// Shielding fails and the user is asked what to do.
+	w.shieldCheck.Store(true)
+	cl.queueResponse("listunspent", unspents)
+	cl.queueResponse("listunspent", unspents)
+	cl.queueResponse(methodZSendMany, tErr)
+	w.checkAutoShield(tCtx)
+	var actionNote *asset.ActionRequiredNote
+	for len(emitChan) > 0 {
+		if n, ok := (<-emitChan).(*asset.ActionRequiredNote); ok {
+			actionNote = n
+		}
+	}
+	if actionNote == nil {
+		t.Fatalf("no ActionRequired note for failed shielding")
+	}
+	if actionNote.ActionID != actionTypeShieldFailed || actionNote.UniqueID != shieldActionID {
+		t.Fatalf("wrong action note %s / %s", actionNote.ActionID, actionNote.UniqueID)
+	}
+
+	// Retry.
+	cl.queueResponse("listunspent", unspents)
+	cl.queueResponse(methodZSendMany, "opid-123456")
+	cl.queueResponse(methodZGetOperationResult, successOp)
+	actionB, _ := json.Marshal(&shieldFailedAction{Retry: true})
+	if err := w.TakeAction(actionTypeShieldFailed, actionB); err != nil {
+		t.Fatalf("TakeAction retry error: %v", err)
+	}
+
+	// Retry fails.
+	cl.queueResponse("listunspent", unspents)
+	cl.queueResponse(methodZSendMany, tErr)
+	if err := w.TakeAction(actionTypeShieldFailed, actionB); err == nil {
+		t.Fatalf("no error for failed retry")
+	}
+
+	// Dismiss.
+	actionB, _ = json.Marshal(&shieldFailedAction{})
+	if err := w.TakeAction(actionTypeShieldFailed, actionB); err != nil {
+		t.Fatalf("TakeAction dismiss error: %v", err)
+	}
+
+	if err := w.TakeAction("nope", nil); err == nil {
+		t.Fatalf("no error for unknown action")
+	}
+}
+
 func TestSwapConfirmations(t *testing.T) {
 	w, cl, shutdown := tNewWallet()
 	defer shutdown()
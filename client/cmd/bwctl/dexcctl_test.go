@@ -6,6 +6,7 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -160,3 +161,37 @@ func TestReadTextFile(t *testing.T) {
 		}
 	}
 }
+
+func TestAbsOutputPath(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("error getting working directory: %v", err)
+	}
+	tests := []struct {
+		name, cmd  string
+		args, want []string
+	}{{
+		name: "relative path",
+		cmd:  "mmexportruns",
+		args: []string{"./runs.csv", "csv", "0"},
+		want: []string{filepath.Join(wd, "runs.csv"), "csv", "0"},
+	}, {
+		name: "absolute path",
+		cmd:  "mmexportrun",
+		args: []string{"/tmp/run.json", "json"},
+		want: []string{"/tmp/run.json", "json"},
+	}, {
+		name: "not an output file command",
+		cmd:  "getdexconfig",
+		args: []string{"1.2.3.4:3000", "./cert"},
+		want: []string{"1.2.3.4:3000", "./cert"},
+	}}
+	for _, test := range tests {
+		if err := absOutputPath(test.cmd, test.args); err != nil {
+			t.Fatalf("unexpected error for %s: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(test.want, test.args) {
+			t.Fatalf("wanted %v but got %v for test %s", test.want, test.args, test.name)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"decred.org/dcrdex/client/rpcserver"
@@ -77,6 +78,15 @@ var optionalTextFiles = map[string]int{
 	"newwallet":    2,
 }
 
+// outputFiles is a map of routes to arg index for routes that write a file at
+// the path found in the route's cmd args at the specified index. bisonw writes
+// the file, so relative paths are made absolute using the caller's working
+// directory.
+var outputFiles = map[string]int{
+	"mmexportrun":  0,
+	"mmexportruns": 0,
+}
+
 // promptPWs prompts for passwords on stdin and returns an error if prompting
 // fails or a password is empty. Returns passwords as a slice of []byte. If
 // cmdPWs is provided, the passwords will be drawn from cmdPWs instead of stdin
@@ -130,6 +140,21 @@ func readTextFile(cmd string, args []string) error {
 	return nil
 }
 
+// absOutputPath converts the output file path at args' index as expected for
+// cmd to an absolute path. The passed args are modified.
+func absOutputPath(cmd string, args []string) error {
+	fileArgIndx, writeFile := outputFiles[cmd]
+	if !writeFile || len(args) < fileArgIndx+1 || args[fileArgIndx] == "" {
+		return nil
+	}
+	path, err := filepath.Abs(dex.CleanAndExpandPath(args[fileArgIndx]))
+	if err != nil {
+		return fmt.Errorf("error resolving %s: %v", args[fileArgIndx], err)
+	}
+	args[fileArgIndx] = path
+	return nil
+}
+
 func run(ctx context.Context) error {
 	cfg, args, stop, err := configure()
 	if err != nil {
@@ -179,6 +204,11 @@ func run(ctx context.Context) error {
 		return err
 	}
 
+	err = absOutputPath(args[0], params)
+	if err != nil {
+		return err
+	}
+
 	payload := &rpcserver.RawParams{
 		PWArgs: pws,
 		Args:   params,
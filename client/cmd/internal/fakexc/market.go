@@ -0,0 +1,111 @@
+package fakexc
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+
+	"decred.org/dcrdex/dex"
+)
+
+// RateQty is an order book level.
+type RateQty struct {
+	Rate float64
+	Qty  float64
+}
+
+// Market is a simulated market with a mid-gap rate that randomly walks
+// around a basis rate derived from the assets' fiat rates.
+type Market struct {
+	Symbol       string
+	BaseFiatRate float64
+	BasisRate    float64
+
+	minRate, maxRate float64
+	walkingSpeed     float64
+	gapRange         float64
+	log              dex.Logger
+
+	mtx   sync.RWMutex
+	rate  float64
+	buys  []*RateQty
+	sells []*RateQty
+}
+
+// NewMarket is the constructor for a Market. walkingSpeed is the maximum
+// amount the mid-gap can shift during a shuffle, as a ratio of the basis
+// rate. gapRange is the ratio by which the gap can vary.
+func NewMarket(symbol string, baseFiatRate, quoteFiatRate, walkingSpeed, gapRange float64, log dex.Logger) *Market {
+	const maxVariation = 0.1
+	basisRate := baseFiatRate / quoteFiatRate
+	m := &Market{
+		Symbol:       symbol,
+		BaseFiatRate: baseFiatRate,
+		BasisRate:    basisRate,
+		minRate:      basisRate * (1 / (1 + maxVariation)),
+		maxRate:      basisRate * (1 + maxVariation),
+		walkingSpeed: walkingSpeed,
+		gapRange:     gapRange,
+		log:          log,
+		rate:         basisRate,
+	}
+	log.Tracef("Market %s intitialized with base fiat rate = %.4f, quote fiat rate = %.4f "+
+		"basis rate = %.8f. Mid-gap rate will randomly walk between %.8f and %.8f",
+		symbol, baseFiatRate, quoteFiatRate, basisRate, m.minRate, m.maxRate)
+	m.Shuffle()
+	return m
+}
+
+// Shuffle shifts the mid-gap rate and randomizes the order book.
+func (m *Market) Shuffle() {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	maxShift := m.BasisRate * m.walkingSpeed
+	if rand.Float64() < 0.5 {
+		maxShift *= -1
+	}
+	newRate := math.Max(m.minRate, math.Min(m.maxRate, m.rate+maxShift*rand.Float64()))
+	m.rate = newRate
+
+	const minHalfGap = 0.002 // 0.2%
+	halfGapFactor := minHalfGap + rand.Float64()*m.gapRange/2
+	bestBuy, bestSell := newRate/(1+halfGapFactor), newRate*(1+halfGapFactor)
+
+	const minLevelSpacing, levelSpacingRange = 0.002, 0.01
+	levelSpacing := (minLevelSpacing + rand.Float64()*levelSpacingRange) * newRate
+
+	makeOrders := func(bestRate, direction float64) []*RateQty {
+		nLevels := rand.Intn(20) + 5
+		ords := make([]*RateQty, nLevels)
+		for i := 0; i < nLevels; i++ {
+			// Each level has between 1 and 10,001 USD equivalent.
+			const minQtyUSD, qtyUSDRange = 1, 10_000
+			qtyUSD := minQtyUSD + qtyUSDRange*rand.Float64()
+			ords[i] = &RateQty{
+				Rate: bestRate + levelSpacing*direction*float64(i),
+				Qty:  qtyUSD / m.BaseFiatRate,
+			}
+		}
+		return ords
+	}
+	m.buys = makeOrders(bestBuy, -1)
+	m.sells = makeOrders(bestSell, 1)
+
+	m.log.Tracef("%s: Shuffle resulted in a mid-gap of %.8f with %d buy orders and %d sell orders",
+		m.Symbol, newRate, len(m.buys), len(m.sells))
+}
+
+// Book returns the current order book, best rates first.
+func (m *Market) Book() (buys, sells []*RateQty) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	return append([]*RateQty(nil), m.buys...), append([]*RateQty(nil), m.sells...)
+}
+
+// BestRates returns the best buy and sell rates.
+func (m *Market) BestRates() (bestBuy, bestSell float64) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	return m.buys[0].Rate, m.sells[0].Rate
+}
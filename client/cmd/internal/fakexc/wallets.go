@@ -1,4 +1,6 @@
-package main
+// Package fakexc provides utilities shared by the fake exchange servers used
+// for simnet testing of the mm/libxc CEX implementations.
+package fakexc
 
 import (
 	"context"
@@ -12,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"decred.org/dcrdex/dex"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	ethrpc "github.com/ethereum/go-ethereum/rpc"
@@ -21,6 +24,8 @@ var (
 	dextestDir = filepath.Join(os.Getenv("HOME"), "dextest")
 )
 
+// Wallet is a simnet harness wallet that the fake exchanges use to confirm
+// deposits and send withdrawals.
 type Wallet interface {
 	DepositAddress() string
 	Confirmations(ctx context.Context, txID string) (uint32, error)
@@ -31,9 +36,12 @@ type utxoWallet struct {
 	symbol string
 	dir    string
 	addr   string
+	log    dex.Logger
 }
 
-func newUtxoWallet(ctx context.Context, symbol string) (*utxoWallet, error) {
+// NewUtxoWallet creates a Wallet backed by the alpha node of a UTXO-based
+// asset's simnet harness.
+func NewUtxoWallet(ctx context.Context, symbol string, log dex.Logger) (Wallet, error) {
 	symbol = strings.ToLower(symbol)
 	dir := filepath.Join(dextestDir, symbol, "harness-ctl")
 	var addr string
@@ -56,6 +64,7 @@ func newUtxoWallet(ctx context.Context, symbol string) (*utxoWallet, error) {
 		symbol: symbol,
 		dir:    dir,
 		addr:   strings.TrimSpace(addr),
+		log:    log,
 	}, nil
 }
 
@@ -66,7 +75,7 @@ func (w *utxoWallet) DepositAddress() string {
 func (w *utxoWallet) Confirmations(ctx context.Context, txID string) (uint32, error) {
 	cmd := exec.CommandContext(ctx, "./alpha", "gettransaction", txID)
 	cmd.Dir = w.dir
-	log.Tracef("Running utxoWallet.Confirmations command %q from directory %q", cmd, w.dir)
+	w.log.Tracef("Running utxoWallet.Confirmations command %q from directory %q", cmd, w.dir)
 	b, err := cmd.CombinedOutput()
 	if err != nil {
 		return 0, fmt.Errorf("gettransaction error with output = %q, err = %v", string(b), err)
@@ -89,7 +98,7 @@ func (w *utxoWallet) unlock(ctx context.Context) {
 	cmd.Dir = w.dir
 	errText, err := cmd.CombinedOutput()
 	if err != nil {
-		log.Errorf("walletpassphrase error with output = %q, err = %v", string(errText), err)
+		w.log.Errorf("walletpassphrase error with output = %q, err = %v", string(errText), err)
 	}
 }
 
@@ -97,7 +106,7 @@ func (w *utxoWallet) Send(ctx context.Context, addr, _ string, amt float64) (str
 	w.unlock(ctx)
 	cmd := exec.CommandContext(ctx, "./alpha", "sendtoaddress", addr, strconv.FormatFloat(amt, 'f', 8, 64))
 	cmd.Dir = w.dir
-	log.Tracef("Running utxoWallet.Send command %q from directory %q", cmd, w.dir)
+	w.log.Tracef("Running utxoWallet.Send command %q from directory %q", cmd, w.dir)
 	txID, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("sendtoaddress error with output = %q, err = %v", string(txID), err)
@@ -109,9 +118,11 @@ type evmWallet struct {
 	dir  string
 	addr string
 	ec   *ethclient.Client
+	log  dex.Logger
 }
 
-func newEvmWallet(ctx context.Context, symbol string) (*evmWallet, error) {
+// NewEvmWallet creates a Wallet backed by an EVM simnet harness.
+func NewEvmWallet(ctx context.Context, symbol string, log dex.Logger) (Wallet, error) {
 	symbol = strings.ToLower(symbol)
 	rpcAddr := "http://localhost:38556"
 	switch symbol {
@@ -132,6 +143,7 @@ func newEvmWallet(ctx context.Context, symbol string) (*evmWallet, error) {
 		dir:  filepath.Join(dextestDir, symbol, "harness-ctl"),
 		addr: "0x18d65fb8d60c1199bb1ad381be47aa692b482605",
 		ec:   ec,
+		log:  log,
 	}, nil
 }
 
@@ -168,7 +180,7 @@ func (w *evmWallet) Send(ctx context.Context, addr, coin string, amt float64) (s
 	}
 	cmd := exec.CommandContext(ctx, script, addr, strconv.FormatFloat(amt, 'f', 9, 64))
 	cmd.Dir = w.dir
-	log.Tracef("Running evmWallet.Send command %q from directory %q", cmd, w.dir)
+	w.log.Tracef("Running evmWallet.Send command %q from directory %q", cmd, w.dir)
 	b, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("sendtoaddress error with output = %q, err = %v", string(b), err)
@@ -186,12 +198,13 @@ func (w *evmWallet) Send(ctx context.Context, addr, coin string, amt float64) (s
 	return txID, nil
 }
 
-func newWallet(ctx context.Context, symbol string) (w Wallet, err error) {
+// NewWallet creates a Wallet for the asset or network symbol.
+func NewWallet(ctx context.Context, symbol string, log dex.Logger) (Wallet, error) {
 	switch strings.ToLower(symbol) {
 	case "btc", "dcr", "zec":
-		w, err = newUtxoWallet(ctx, symbol)
+		return NewUtxoWallet(ctx, symbol, log)
 	case "eth", "matic", "polygon":
-		w, err = newEvmWallet(ctx, symbol)
+		return NewEvmWallet(ctx, symbol, log)
 	}
-	return w, err
+	return nil, fmt.Errorf("no wallet available for %s", symbol)
 }
@@ -14,6 +14,7 @@ import (
 	"testing"
 	"time"
 
+	"decred.org/dcrdex/client/cmd/internal/fakexc"
 	"decred.org/dcrdex/client/comms"
 	"decred.org/dcrdex/client/mm/libxc/bntypes"
 	"decred.org/dcrdex/dex"
@@ -29,7 +30,7 @@ func TestMain(m *testing.M) {
 func TestUtxoWallet(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	w, err := newUtxoWallet(ctx, "btc")
+	w, err := fakexc.NewUtxoWallet(ctx, "btc", log)
 	if err != nil {
 		t.Fatalf("newUtxoWallet error: %v", err)
 	}
@@ -39,17 +40,17 @@ func TestUtxoWallet(t *testing.T) {
 func TestEvmWallet(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	w, err := newEvmWallet(ctx, "eth")
+	w, err := fakexc.NewEvmWallet(ctx, "eth", log)
 	if err != nil {
 		t.Fatalf("newUtxoWallet error: %v", err)
 	}
 	testWallet(t, ctx, w)
 }
 
-func testWallet(t *testing.T, ctx context.Context, w Wallet) {
+func testWallet(t *testing.T, ctx context.Context, w fakexc.Wallet) {
 	addr := w.DepositAddress()
 	fmt.Println("##### Deposit address:", addr)
-	txID, err := w.Send(ctx, addr, 0.1)
+	txID, err := w.Send(ctx, addr, "", 0.1)
 	if err != nil {
 		t.Fatalf("Send error: %v", err)
 	}
@@ -207,11 +208,11 @@ func TestAccountFeed(t *testing.T) {
 
 	printThing("Deposit address", addrResp)
 
-	w, err := newUtxoWallet(ctx, "btc")
+	w, err := fakexc.NewUtxoWallet(ctx, "btc", log)
 	if err != nil {
 		t.Fatalf("Error constructing btc wallet: %v", err)
 	}
-	txID, err := w.Send(ctx, addrResp.Address, 0.1)
+	txID, err := w.Send(ctx, addrResp.Address, "", 0.1)
 	if err != nil {
 		t.Fatalf("Send error: %v", err)
 	}
@@ -24,6 +24,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"decred.org/dcrdex/client/cmd/internal/fakexc"
 	"decred.org/dcrdex/client/mm/libxc/bntypes"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/encode"
@@ -304,7 +305,7 @@ type fakeBinance struct {
 	marketSubscribers map[string]*marketSubscriber
 
 	walletMtx sync.RWMutex
-	wallets   map[string]Wallet
+	wallets   map[string]fakexc.Wallet
 
 	bookedOrdersMtx sync.RWMutex
 	bookedOrders    map[string]*userOrder
@@ -336,7 +337,7 @@ func newFakeBinanceServer(ctx context.Context) (*fakeBinance, error) {
 		withdrawalHistory:  make(map[string]*withdrawal, 0),
 		balances:           balances,
 		accountSubscribers: make(map[string]*ws.WSLink),
-		wallets:            make(map[string]Wallet),
+		wallets:            make(map[string]fakexc.Wallet),
 		fiatRates:          fiatRates,
 		markets:            make(map[string]*market),
 		marketSubscribers:  make(map[string]*marketSubscriber),
@@ -819,7 +820,7 @@ func (f *fakeBinance) handleConfirmDeposit(w http.ResponseWriter, r *http.Reques
 	writeJSONWithStatus(w, resp, http.StatusOK)
 }
 
-func (f *fakeBinance) getWallet(network string) (Wallet, error) {
+func (f *fakeBinance) getWallet(network string) (fakexc.Wallet, error) {
 	symbol := strings.ToLower(network)
 	f.walletMtx.Lock()
 	defer f.walletMtx.Unlock()
@@ -827,7 +828,7 @@ func (f *fakeBinance) getWallet(network string) (Wallet, error) {
 	if exists {
 		return wallet, nil
 	}
-	wallet, err := newWallet(f.ctx, symbol)
+	wallet, err := fakexc.NewWallet(f.ctx, symbol, log)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,1181 @@
+package main
+
+/*
+ * Starts an http server that responds to some of the kraken api's endpoints,
+ * for testing the libxc kraken client on simnet. Private endpoints are not
+ * authenticated. The websocket v2 public and private channels are both
+ * served on the same endpoint.
+ */
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/client/cmd/internal/fakexc"
+	"decred.org/dcrdex/client/mm/libxc/krtypes"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/fiatrates"
+	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/dex/ws"
+	"decred.org/dcrdex/server/comms"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	pongWait     = 60 * time.Second
+	pingPeriod   = (pongWait * 9) / 10
+	depositConfs = 3
+	// takerFee is charged in the quote asset for every fill.
+	takerFee = 0.0026
+	// checksumLevels is the number of levels of each side of the book that
+	// are included in the checksum.
+	checksumLevels = 10
+)
+
+var (
+	log dex.Logger
+
+	walkingSpeedAdj float64
+	gapRange        float64
+)
+
+type krAsset struct {
+	key      string
+	altname  string
+	decimals int
+	// methods maps deposit and withdrawal methods to the simnet harness
+	// network.
+	methods     map[string]string
+	withdrawFee float64
+}
+
+var (
+	assets = []*krAsset{
+		{key: "XXBT", altname: "XBT", decimals: 10, methods: map[string]string{"Bitcoin": "btc"}, withdrawFee: 0.00005},
+		{key: "DCR", altname: "DCR", decimals: 10, methods: map[string]string{"Decred": "dcr"}, withdrawFee: 0.005},
+		{key: "XETH", altname: "ETH", decimals: 10, methods: map[string]string{"Ether": "eth"}, withdrawFee: 0.0025},
+		{key: "USDC", altname: "USDC", decimals: 8, methods: map[string]string{"USDC (ERC20)": "eth", "USDC (Polygon)": "polygon"}, withdrawFee: 1},
+		{key: "XZEC", altname: "ZEC", decimals: 10, methods: map[string]string{"Zcash (Transparent)": "zec"}, withdrawFee: 0.0005},
+	}
+
+	pairs = map[string]*krtypes.AssetPair{
+		"DCRXBT":   makePair("DCR", "XXBT", "DCR/XBT", 7, 8, 0.1),
+		"XETHXXBT": makePair("XETH", "XXBT", "ETH/XBT", 5, 8, 0.002),
+		"DCRUSDC":  makePair("DCR", "USDC", "DCR/USDC", 2, 8, 0.1),
+		"XBTUSDC":  makePair("XXBT", "USDC", "XBT/USDC", 2, 8, 0.00005),
+		"XZECXXBT": makePair("XZEC", "XXBT", "ZEC/XBT", 6, 8, 0.05),
+	}
+
+	coinpapAssets = []*fiatrates.CoinpaprikaAsset{
+		makeCoinpapAsset(0, "btc", "Bitcoin"),
+		makeCoinpapAsset(42, "dcr", "Decred"),
+		makeCoinpapAsset(60, "eth", "Ethereum"),
+		makeCoinpapAsset(966001, "usdc.polygon", "USDC"),
+		makeCoinpapAsset(133, "zec", "Zcash"),
+	}
+
+	initialBalances = map[string]float64{
+		"XXBT": 1.5,
+		"DCR":  10000,
+		"XETH": 50,
+		"USDC": 1152,
+		"XZEC": 10000,
+	}
+
+	// krakenAssetIDs maps the kraken asset keys to the asset IDs used for
+	// the fiat rates.
+	krakenAssetIDs = map[string]uint32{
+		"XXBT": 0,
+		"DCR":  42,
+		"XETH": 60,
+		"USDC": 966001,
+		"XZEC": 133,
+	}
+)
+
+func makePair(base, quote, wsName string, pairDecimals, lotDecimals int, orderMin float64) *krtypes.AssetPair {
+	return &krtypes.AssetPair{
+		Altname:      strings.ReplaceAll(wsName, "/", ""),
+		WSName:       wsName,
+		Base:         base,
+		Quote:        quote,
+		PairDecimals: pairDecimals,
+		CostDecimals: pairDecimals,
+		LotDecimals:  lotDecimals,
+		OrderMin:     orderMin,
+		TickSize:     math.Pow10(-pairDecimals),
+		Status:       "online",
+	}
+}
+
+func makeCoinpapAsset(assetID uint32, symbol, name string) *fiatrates.CoinpaprikaAsset {
+	return &fiatrates.CoinpaprikaAsset{
+		AssetID: assetID,
+		Symbol:  symbol,
+		Name:    name,
+	}
+}
+
+func assetByKey(key string) *krAsset {
+	for _, a := range assets {
+		if a.key == key {
+			return a
+		}
+	}
+	return nil
+}
+
+// wsTicker translates a kraken asset key to the websocket v2 ticker.
+func wsTicker(key string) string {
+	switch a := assetByKey(key); a.altname {
+	case "XBT":
+		return "BTC"
+	default:
+		return a.altname
+	}
+}
+
+func main() {
+	var logDebug, logTrace bool
+	flag.Float64Var(&walkingSpeedAdj, "walkspeed", 1.0, "scale the maximum walking speed. default scale of 1.0 is about 3%")
+	flag.Float64Var(&gapRange, "gaprange", 0.04, "a ratio of how much the gap can vary. default is 0.04 => 4%")
+	flag.BoolVar(&logDebug, "debug", false, "use debug logging")
+	flag.BoolVar(&logTrace, "trace", false, "use trace logging")
+	flag.Parse()
+
+	switch {
+	case logTrace:
+		log = dex.StdOutLogger("TK", dex.LevelTrace)
+		comms.UseLogger(dex.StdOutLogger("C", dex.LevelTrace))
+	case logDebug:
+		log = dex.StdOutLogger("TK", dex.LevelDebug)
+		comms.UseLogger(dex.StdOutLogger("C", dex.LevelDebug))
+	default:
+		log = dex.StdOutLogger("TK", dex.LevelInfo)
+		comms.UseLogger(dex.StdOutLogger("C", dex.LevelInfo))
+	}
+
+	if err := mainErr(); err != nil {
+		fmt.Fprint(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func mainErr() error {
+	if walkingSpeedAdj > 10 {
+		return fmt.Errorf("invalid walkspeed must be in < 10")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	killChan := make(chan os.Signal, 1)
+	signal.Notify(killChan, os.Interrupt)
+	go func() {
+		<-killChan
+		log.Info("Shutting down...")
+		cancel()
+	}()
+
+	k, err := newFakeKraken(ctx)
+	if err != nil {
+		return err
+	}
+
+	k.run(ctx)
+
+	return nil
+}
+
+type deposit struct {
+	amt      float64
+	credited bool
+}
+
+type withdrawal struct {
+	refID   string
+	asset   string
+	network string
+	amt     float64
+	fee     float64
+	address string
+	txID    string
+}
+
+type userOrder struct {
+	id        string
+	pair      string
+	sell      bool
+	orderType string
+	price     float64
+	vol       float64
+	volExec   float64
+	cost      float64
+	fee       float64
+	status    string
+	apiKey    string
+	stamp     time.Time
+}
+
+type wsClient struct {
+	*ws.WSLink
+	apiKey string
+	// books is the set of subscribed ws symbols.
+	books map[string]bool
+	// executions and balances are true if the client is subscribed to the
+	// private channels.
+	executions bool
+	balances   bool
+}
+
+type fakeKraken struct {
+	ctx       context.Context
+	srv       *comms.Server
+	fiatRates map[uint32]float64
+
+	balancesMtx sync.RWMutex
+	balances    map[string]*krtypes.Balance
+
+	marketsMtx sync.RWMutex
+	markets    map[string]*fakexc.Market // pair -> market
+
+	clientsMtx sync.RWMutex
+	clients    map[string]*wsClient
+
+	walletMtx sync.Mutex
+	wallets   map[string]fakexc.Wallet
+
+	depositsMtx sync.Mutex
+	deposits    map[string]*deposit
+
+	withdrawalsMtx sync.RWMutex
+	withdrawals    []*withdrawal
+
+	ordersMtx sync.RWMutex
+	orders    map[string]*userOrder
+}
+
+func newFakeKraken(ctx context.Context) (*fakeKraken, error) {
+	log.Trace("Fetching coinpaprika prices")
+	fiatRates := fiatrates.FetchCoinpaprikaRates(ctx, coinpapAssets, dex.StdOutLogger("CP", dex.LevelDebug))
+	if len(fiatRates) < len(coinpapAssets) {
+		return nil, fmt.Errorf("not enough coinpap assets. wanted %d, got %d", len(coinpapAssets), len(fiatRates))
+	}
+
+	srv, err := comms.NewServer(&comms.RPCConfig{
+		ListenAddrs: []string{":37347"},
+		NoTLS:       true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error creating server: %w", err)
+	}
+
+	balances := make(map[string]*krtypes.Balance, len(initialBalances))
+	for key, bal := range initialBalances {
+		balances[key] = &krtypes.Balance{Balance: bal}
+	}
+
+	k := &fakeKraken{
+		ctx:       ctx,
+		srv:       srv,
+		fiatRates: fiatRates,
+		balances:  balances,
+		markets:   make(map[string]*fakexc.Market),
+		clients:   make(map[string]*wsClient),
+		wallets:   make(map[string]fakexc.Wallet),
+		deposits:  make(map[string]*deposit),
+		orders:    make(map[string]*userOrder),
+	}
+
+	for pair, p := range pairs {
+		k.markets[pair] = fakexc.NewMarket(p.WSName, fiatRates[krakenAssetIDs[p.Base]], fiatRates[krakenAssetIDs[p.Quote]],
+			0.03*walkingSpeedAdj, gapRange, log)
+	}
+
+	mux := srv.Mux()
+	mux.Route("/0/public", func(r chi.Router) {
+		r.Get("/Assets", k.handleAssets)
+		r.Get("/AssetPairs", k.handleAssetPairs)
+		r.Get("/Ticker", k.handleTicker)
+	})
+	mux.Route("/0/private", func(r chi.Router) {
+		r.Post("/BalanceEx", k.handleBalance)
+		r.Post("/GetWebSocketsToken", k.handleWebsocketToken)
+		r.Post("/AddOrder", k.handleAddOrder)
+		r.Post("/CancelOrder", k.handleCancelOrder)
+		r.Post("/QueryOrders", k.handleQueryOrders)
+		r.Post("/DepositMethods", k.handleDepositMethods)
+		r.Post("/DepositAddresses", k.handleDepositAddresses)
+		r.Post("/DepositStatus", k.handleDepositStatus)
+		r.Post("/WithdrawAddresses", k.handleWithdrawAddresses)
+		r.Post("/WithdrawInfo", k.handleWithdrawInfo)
+		r.Post("/Withdraw", k.handleWithdraw)
+		r.Post("/WithdrawStatus", k.handleWithdrawStatus)
+	})
+	mux.Get("/v2", k.handleWebsocket)
+
+	return k, nil
+}
+
+func (k *fakeKraken) run(ctx context.Context) {
+	// Shuffle the books periodically.
+	go func() {
+		const marketMinTick, marketTickRange = time.Second * 30, time.Second * 60
+		for {
+			delay := marketMinTick + time.Duration(rand.Float64()*float64(marketTickRange))
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+			k.marketsMtx.RLock()
+			for pair, mkt := range k.markets {
+				mkt.Shuffle()
+				k.sendBookSnapshot(pair, mkt, nil)
+			}
+			k.marketsMtx.RUnlock()
+		}
+	}()
+
+	// 50% chance of filling all booked orders every 5 to 30 seconds.
+	go func() {
+		const minFillTick, fillTickRange = 5 * time.Second, 25 * time.Second
+		for {
+			select {
+			case <-time.After(minFillTick + time.Duration(rand.Float64()*float64(fillTickRange))):
+			case <-ctx.Done():
+				return
+			}
+			if rand.Float32() < 0.5 {
+				continue
+			}
+			k.ordersMtx.Lock()
+			for id, ord := range k.orders {
+				if ord.status != "open" {
+					if time.Since(ord.stamp) > time.Hour {
+						delete(k.orders, id)
+					}
+					continue
+				}
+				k.fillOrder(ord, ord.price, true)
+			}
+			k.ordersMtx.Unlock()
+		}
+	}()
+
+	// Send withdrawals.
+	go func() {
+		for {
+			select {
+			case <-time.After(time.Second * 10):
+			case <-ctx.Done():
+				return
+			}
+			k.withdrawalsMtx.Lock()
+			for _, w := range k.withdrawals {
+				if w.txID != "" {
+					continue
+				}
+				wallet, err := k.getWallet(w.network)
+				if err != nil {
+					log.Errorf("No wallet for withdrawal network %s: %v", w.network, err)
+					continue
+				}
+				txID, err := wallet.Send(ctx, w.address, wsTicker(w.asset), w.amt)
+				if err != nil {
+					log.Errorf("Error sending %s withdrawal: %v", w.asset, err)
+					continue
+				}
+				log.Debugf("Sent withdrawal of %.8f %s, txid = %s", w.amt, w.asset, txID)
+				w.txID = txID
+			}
+			k.withdrawalsMtx.Unlock()
+		}
+	}()
+
+	k.srv.Run(ctx)
+}
+
+func (k *fakeKraken) getWallet(network string) (fakexc.Wallet, error) {
+	k.walletMtx.Lock()
+	defer k.walletMtx.Unlock()
+	if w, found := k.wallets[network]; found {
+		return w, nil
+	}
+	w, err := fakexc.NewWallet(k.ctx, network, log)
+	if err != nil {
+		return nil, err
+	}
+	k.wallets[network] = w
+	return w, nil
+}
+
+func checksumString(v float64, decimals int) string {
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	return strings.TrimLeft(strings.Replace(s, ".", "", 1), "0")
+}
+
+func roundTo(v float64, decimals int) float64 {
+	f := math.Pow10(decimals)
+	return math.Round(v*f) / f
+}
+
+// bookSnapshot generates a book snapshot message for the market.
+func bookSnapshot(pair string, mkt *fakexc.Market) []byte {
+	p := pairs[pair]
+	buys, sells := mkt.Book()
+	convert := func(levels []*fakexc.RateQty) []*krtypes.BookLevel {
+		book := make([]*krtypes.BookLevel, 0, len(levels))
+		for _, l := range levels {
+			book = append(book, &krtypes.BookLevel{
+				Price: roundTo(l.Rate, p.PairDecimals),
+				Qty:   roundTo(l.Qty, p.LotDecimals),
+			})
+		}
+		return book
+	}
+	bids, asks := convert(buys), convert(sells)
+
+	var sb strings.Builder
+	for _, side := range [][]*krtypes.BookLevel{asks, bids} {
+		for i, l := range side {
+			if i == checksumLevels {
+				break
+			}
+			sb.WriteString(checksumString(l.Price, p.PairDecimals))
+			sb.WriteString(checksumString(l.Qty, p.LotDecimals))
+		}
+	}
+
+	symbol := wsTicker(p.Base) + "/" + wsTicker(p.Quote)
+	dataB, _ := json.Marshal([]*krtypes.BookData{{
+		Symbol:   symbol,
+		Bids:     bids,
+		Asks:     asks,
+		Checksum: crc32.ChecksumIEEE([]byte(sb.String())),
+	}})
+	msgB, _ := json.Marshal(&krtypes.WSMessage{
+		Channel: "book",
+		Type:    "snapshot",
+		Data:    dataB,
+	})
+	return msgB
+}
+
+// sendBookSnapshot sends the book to every client subscribed to the market.
+// If cl is non-nil, the book is only sent to that client. Updates are always
+// sent as snapshots, which the client handles by replacing its book.
+func (k *fakeKraken) sendBookSnapshot(pair string, mkt *fakexc.Market, cl *wsClient) {
+	msgB := bookSnapshot(pair, mkt)
+	if cl != nil {
+		cl.SendRaw(msgB)
+		return
+	}
+	k.clientsMtx.RLock()
+	defer k.clientsMtx.RUnlock()
+	for _, cl := range k.clients {
+		if cl.books[mkt.Symbol] {
+			cl.SendRaw(msgB)
+		}
+	}
+}
+
+func (k *fakeKraken) pairForSymbol(symbol string) (string, *fakexc.Market) {
+	for pair, p := range pairs {
+		if wsTicker(p.Base)+"/"+wsTicker(p.Quote) == symbol {
+			return pair, k.markets[pair]
+		}
+	}
+	return "", nil
+}
+
+type wsParams struct {
+	Channel string   `json:"channel"`
+	Symbol  []string `json:"symbol"`
+	Token   string   `json:"token"`
+}
+
+func (k *fakeKraken) handleWebsocket(w http.ResponseWriter, r *http.Request) {
+	wsConn, err := ws.NewConnection(w, r, pongWait)
+	if err != nil {
+		log.Errorf("ws.NewConnection error: %v", err)
+		http.Error(w, "error initializing connection", http.StatusInternalServerError)
+		return
+	}
+
+	ip := dex.NewIPKey(r.RemoteAddr)
+	conn := ws.NewWSLink(ip.String(), wsConn, pingPeriod, func(msg *msgjson.Message) *msgjson.Error {
+		return nil
+	}, dex.StdOutLogger(fmt.Sprintf("CL[%s]", ip), dex.LevelDebug))
+
+	cl := &wsClient{
+		WSLink: conn,
+		books:  make(map[string]bool),
+	}
+
+	respond := func(method string, err error) {
+		resp := &krtypes.WSMessage{Method: method, Success: err == nil}
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		b, _ := json.Marshal(resp)
+		conn.SendRaw(b)
+	}
+
+	conn.RawHandler = func(b []byte) {
+		var req struct {
+			Method string    `json:"method"`
+			Params *wsParams `json:"params"`
+		}
+		if err := json.Unmarshal(b, &req); err != nil || req.Params == nil {
+			log.Errorf("Error unmarshaling websocket request %s: %v", string(b), err)
+			return
+		}
+		sub := req.Method == "subscribe"
+		switch req.Params.Channel {
+		case "book":
+			for _, symbol := range req.Params.Symbol {
+				pair, mkt := k.pairForSymbol(symbol)
+				if mkt == nil {
+					respond(req.Method, fmt.Errorf("Currency pair not supported %s", symbol))
+					return
+				}
+				k.clientsMtx.Lock()
+				if sub {
+					cl.books[symbol] = true
+				} else {
+					delete(cl.books, symbol)
+				}
+				k.clientsMtx.Unlock()
+				respond(req.Method, nil)
+				if sub {
+					k.sendBookSnapshot(pair, mkt, cl)
+				}
+			}
+		case "executions", "balances":
+			k.clientsMtx.Lock()
+			cl.apiKey = req.Params.Token
+			if req.Params.Channel == "executions" {
+				cl.executions = sub
+			} else {
+				cl.balances = sub
+			}
+			k.clientsMtx.Unlock()
+			respond(req.Method, nil)
+		default:
+			respond(req.Method, fmt.Errorf("Unsupported channel %s", req.Params.Channel))
+		}
+	}
+
+	cm := dex.NewConnectionMaster(conn)
+	if err = cm.ConnectOnce(k.ctx); err != nil {
+		log.Errorf("Error connecting websocket client: %v", err)
+		return
+	}
+
+	addr := conn.Addr()
+	k.clientsMtx.Lock()
+	k.clients[addr] = cl
+	k.clientsMtx.Unlock()
+
+	go func() {
+		cm.Wait()
+		k.clientsMtx.Lock()
+		delete(k.clients, addr)
+		k.clientsMtx.Unlock()
+		log.Tracef("Websocket client %s disconnected", addr)
+	}()
+}
+
+// sendUserUpdate sends a message on a private channel to the user's
+// subscribed clients.
+func (k *fakeKraken) sendUserUpdate(apiKey, channel string, data any) {
+	dataB, _ := json.Marshal(data)
+	msgB, _ := json.Marshal(&krtypes.WSMessage{
+		Channel: channel,
+		Type:    "update",
+		Data:    dataB,
+	})
+	k.clientsMtx.RLock()
+	defer k.clientsMtx.RUnlock()
+	for _, cl := range k.clients {
+		if cl.apiKey != apiKey {
+			continue
+		}
+		if (channel == "executions" && cl.executions) || (channel == "balances" && cl.balances) {
+			cl.SendRaw(msgB)
+		}
+	}
+}
+
+// updateBalances applies the changes to the available and held balances of
+// the assets, and notifies the user.
+func (k *fakeKraken) updateBalances(apiKey string, changes map[string][2]float64) {
+	k.balancesMtx.Lock()
+	updates := make([]*krtypes.BalanceUpdate, 0, len(changes))
+	for key, change := range changes {
+		bal, found := k.balances[key]
+		if !found {
+			bal = &krtypes.Balance{}
+			k.balances[key] = bal
+		}
+		bal.Balance += change[0]
+		bal.HoldTrade += change[1]
+		updates = append(updates, &krtypes.BalanceUpdate{
+			Asset:   wsTicker(key),
+			Balance: bal.Balance,
+		})
+	}
+	k.balancesMtx.Unlock()
+	k.sendUserUpdate(apiKey, "balances", updates)
+}
+
+// fillOrder fills the order at the rate, and sends an execution to the
+// user. ordersMtx must be held.
+func (k *fakeKraken) fillOrder(ord *userOrder, rate float64, booked bool) {
+	p := pairs[ord.pair]
+	qty := ord.vol
+	cost := qty * rate
+	fee := cost * takerFee
+	ord.volExec, ord.cost, ord.fee = qty, cost, fee
+	ord.status = "closed"
+
+	changes := make(map[string][2]float64, 2)
+	if ord.sell {
+		hold := 0.0
+		if booked {
+			hold = -qty
+		}
+		changes[p.Base] = [2]float64{-qty, hold}
+		changes[p.Quote] = [2]float64{cost - fee, 0}
+	} else {
+		hold := 0.0
+		if booked {
+			hold = -(ord.vol * ord.price * (1 + takerFee))
+		}
+		changes[p.Base] = [2]float64{qty, 0}
+		changes[p.Quote] = [2]float64{-(cost + fee), hold}
+	}
+	k.updateBalances(ord.apiKey, changes)
+
+	side := "buy"
+	if ord.sell {
+		side = "sell"
+	}
+	k.sendUserUpdate(ord.apiKey, "executions", []*krtypes.Execution{{
+		ExecType:    "trade",
+		OrderID:     ord.id,
+		OrderStatus: "filled",
+		Symbol:      wsTicker(p.Base) + "/" + wsTicker(p.Quote),
+		Side:        side,
+		CumQty:      qty,
+		CumCost:     cost,
+		Fees:        []*krtypes.ExecutionFee{{Asset: wsTicker(p.Quote), Qty: fee}},
+	}})
+}
+
+func (k *fakeKraken) handleAssets(w http.ResponseWriter, r *http.Request) {
+	resp := make(map[string]*krtypes.Asset, len(assets))
+	for _, a := range assets {
+		resp[a.key] = &krtypes.Asset{
+			AssetClass:      "currency",
+			Altname:         a.altname,
+			Decimals:        a.decimals,
+			DisplayDecimals: 5,
+			Status:          "enabled",
+		}
+	}
+	writeResult(w, resp)
+}
+
+func (k *fakeKraken) handleAssetPairs(w http.ResponseWriter, r *http.Request) {
+	writeResult(w, pairs)
+}
+
+func (k *fakeKraken) handleTicker(w http.ResponseWriter, r *http.Request) {
+	f := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	resp := make(map[string]*krtypes.Ticker, len(pairs))
+	k.marketsMtx.RLock()
+	for pair, mkt := range k.markets {
+		bestBuy, bestSell := mkt.BestRates()
+		vol24USD := math.Pow(10, float64(rand.Intn(4)+2))
+		vol := vol24USD / mkt.BaseFiatRate
+		last := mkt.BasisRate
+		high := last * (1 + rand.Float64()*0.15)
+		low := last / (1 + rand.Float64()*0.15)
+		open := low + ((high - low) * rand.Float64())
+		resp[pair] = &krtypes.Ticker{
+			Ask:       []string{f(bestSell), "1", "1.000"},
+			Bid:       []string{f(bestBuy), "1", "1.000"},
+			LastTrade: []string{f(last), "1.0"},
+			Volume:    [2]string{f(vol), f(vol)},
+			VWAP:      [2]string{f((open + last + high + low) / 4), f((open + last + high + low) / 4)},
+			Low:       [2]string{f(low), f(low)},
+			High:      [2]string{f(high), f(high)},
+			Open:      f(open),
+		}
+	}
+	k.marketsMtx.RUnlock()
+	writeResult(w, resp)
+}
+
+func (k *fakeKraken) handleBalance(w http.ResponseWriter, r *http.Request) {
+	k.balancesMtx.RLock()
+	defer k.balancesMtx.RUnlock()
+	writeResult(w, k.balances)
+}
+
+func (k *fakeKraken) handleWebsocketToken(w http.ResponseWriter, r *http.Request) {
+	// The API key is used as the token so that the private channels can be
+	// matched to the user.
+	writeResult(w, &krtypes.WebsocketToken{
+		Token:   extractAPIKey(r),
+		Expires: 900,
+	})
+}
+
+func (k *fakeKraken) handleAddOrder(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, "EGeneral:Invalid arguments")
+		return
+	}
+	apiKey := extractAPIKey(r)
+	pair := r.Form.Get("pair")
+	p, found := pairs[pair]
+	if !found {
+		writeError(w, "EQuery:Unknown asset pair")
+		return
+	}
+	vol, err := strconv.ParseFloat(r.Form.Get("volume"), 64)
+	if err != nil || vol < p.OrderMin {
+		writeError(w, "EOrder:Invalid volume")
+		return
+	}
+	orderType := r.Form.Get("ordertype")
+	sell := r.Form.Get("type") == "sell"
+	var price float64
+	switch orderType {
+	case "limit":
+		if price, err = strconv.ParseFloat(r.Form.Get("price"), 64); err != nil || price <= 0 {
+			writeError(w, "EOrder:Invalid price")
+			return
+		}
+	case "market":
+		bestBuy, bestSell := k.markets[pair].BestRates()
+		price = bestSell
+		if sell {
+			price = bestBuy
+		}
+	default:
+		writeError(w, "EGeneral:Invalid arguments:ordertype")
+		return
+	}
+
+	// Check the balance.
+	fromAsset, fromQty := p.Quote, vol*price*(1+takerFee)
+	if sell {
+		fromAsset, fromQty = p.Base, vol
+	}
+	k.balancesMtx.RLock()
+	bal := k.balances[fromAsset]
+	insufficient := bal == nil || bal.Balance-bal.HoldTrade < fromQty
+	k.balancesMtx.RUnlock()
+	if insufficient {
+		writeError(w, "EOrder:Insufficient funds")
+		return
+	}
+
+	ord := &userOrder{
+		id:        strings.ToUpper(hex.EncodeToString(encode.RandomBytes(9))),
+		pair:      pair,
+		sell:      sell,
+		orderType: orderType,
+		price:     price,
+		vol:       vol,
+		status:    "open",
+		apiKey:    apiKey,
+		stamp:     time.Now(),
+	}
+
+	k.ordersMtx.Lock()
+	k.orders[ord.id] = ord
+	k.ordersMtx.Unlock()
+
+	side := "buy"
+	if sell {
+		side = "sell"
+	}
+	var resp krtypes.AddOrderResult
+	resp.Description.Order = fmt.Sprintf("%s %s %s @ %s %s", side, floatString(vol), pair, orderType, floatString(price))
+	resp.TxIDs = []string{ord.id}
+	writeResult(w, &resp)
+
+	bookIt := orderType == "limit" && r.Form.Get("timeinforce") != "IOC" && rand.Float32() < 0.2
+	go func() {
+		k.ordersMtx.Lock()
+		defer k.ordersMtx.Unlock()
+		if !bookIt {
+			log.Tracef("Filling %s order %s on %s for %.8f for user %s", side, ord.id, pair, vol, apiKey)
+			k.fillOrder(ord, price, false)
+			return
+		}
+		log.Tracef("Booking %s order %s on %s for %.8f for user %s", side, ord.id, pair, vol, apiKey)
+		k.updateBalances(apiKey, map[string][2]float64{fromAsset: {0, fromQty}})
+		k.sendUserUpdate(apiKey, "executions", []*krtypes.Execution{{
+			ExecType:    "new",
+			OrderID:     ord.id,
+			OrderStatus: "new",
+			Symbol:      wsTicker(p.Base) + "/" + wsTicker(p.Quote),
+			Side:        side,
+		}})
+	}()
+}
+
+func (k *fakeKraken) handleCancelOrder(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, "EGeneral:Invalid arguments")
+		return
+	}
+	id := r.Form.Get("txid")
+	k.ordersMtx.Lock()
+	defer k.ordersMtx.Unlock()
+	ord, found := k.orders[id]
+	if !found {
+		writeError(w, "EOrder:Unknown order")
+		return
+	}
+	if ord.status != "open" {
+		writeResult(w, &krtypes.CancelOrderResult{Count: 0})
+		return
+	}
+	ord.status = "canceled"
+	p := pairs[ord.pair]
+	fromAsset, fromQty := p.Quote, ord.vol*ord.price*(1+takerFee)
+	if ord.sell {
+		fromAsset, fromQty = p.Base, ord.vol
+	}
+	k.updateBalances(ord.apiKey, map[string][2]float64{fromAsset: {0, -fromQty}})
+	writeResult(w, &krtypes.CancelOrderResult{Count: 1})
+
+	side := "buy"
+	if ord.sell {
+		side = "sell"
+	}
+	k.sendUserUpdate(ord.apiKey, "executions", []*krtypes.Execution{{
+		ExecType:    "canceled",
+		OrderID:     ord.id,
+		OrderStatus: "canceled",
+		Symbol:      wsTicker(p.Base) + "/" + wsTicker(p.Quote),
+		Side:        side,
+	}})
+}
+
+func (k *fakeKraken) handleQueryOrders(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, "EGeneral:Invalid arguments")
+		return
+	}
+	resp := make(map[string]*krtypes.Order)
+	k.ordersMtx.RLock()
+	for _, id := range strings.Split(r.Form.Get("txid"), ",") {
+		ord, found := k.orders[id]
+		if !found {
+			continue
+		}
+		side := "buy"
+		if ord.sell {
+			side = "sell"
+		}
+		resp[id] = &krtypes.Order{
+			Status: ord.status,
+			Description: krtypes.OrderDescription{
+				Pair:      ord.pair,
+				Type:      side,
+				OrderType: ord.orderType,
+				Price:     ord.price,
+			},
+			Volume:     ord.vol,
+			VolumeExec: ord.volExec,
+			Cost:       ord.cost,
+			Fee:        ord.fee,
+			Price:      ord.price,
+			OrderFlags: "fciq",
+		}
+	}
+	k.ordersMtx.RUnlock()
+	writeResult(w, resp)
+}
+
+func (k *fakeKraken) handleDepositMethods(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, "EGeneral:Invalid arguments")
+		return
+	}
+	a := assetByKey(r.Form.Get("asset"))
+	if a == nil {
+		writeError(w, "EFunding:Unknown asset")
+		return
+	}
+	resp := make([]*krtypes.DepositMethod, 0, len(a.methods))
+	for method := range a.methods {
+		resp = append(resp, &krtypes.DepositMethod{Method: method, GenAddress: true})
+	}
+	writeResult(w, resp)
+}
+
+// walletForMethod returns the harness wallet for the asset's method.
+func (k *fakeKraken) walletForMethod(assetKey, method string) (fakexc.Wallet, string, error) {
+	a := assetByKey(assetKey)
+	if a == nil {
+		return nil, "", fmt.Errorf("unknown asset %s", assetKey)
+	}
+	network, found := a.methods[method]
+	if !found {
+		return nil, "", fmt.Errorf("unknown method %q for %s", method, assetKey)
+	}
+	wallet, err := k.getWallet(network)
+	return wallet, network, err
+}
+
+func (k *fakeKraken) handleDepositAddresses(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, "EGeneral:Invalid arguments")
+		return
+	}
+	wallet, _, err := k.walletForMethod(r.Form.Get("asset"), r.Form.Get("method"))
+	if err != nil {
+		log.Errorf("Error getting deposit wallet: %v", err)
+		writeError(w, "EFunding:Invalid method")
+		return
+	}
+	writeResult(w, []*krtypes.DepositAddress{{Address: wallet.DepositAddress()}})
+}
+
+func (k *fakeKraken) handleDepositStatus(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, "EGeneral:Invalid arguments")
+		return
+	}
+	assetKey, method, txID := r.Form.Get("asset"), r.Form.Get("method"), r.Form.Get("txid")
+	amt, err := strconv.ParseFloat(r.Form.Get("amt"), 64)
+	if err != nil {
+		writeError(w, "EGeneral:Invalid arguments:amt")
+		return
+	}
+	wallet, _, err := k.walletForMethod(assetKey, method)
+	if err != nil {
+		log.Errorf("Error getting deposit wallet: %v", err)
+		writeError(w, "EFunding:Invalid method")
+		return
+	}
+	confs, err := wallet.Confirmations(k.ctx, txID)
+	if err != nil {
+		log.Errorf("Error getting deposit confirmations for %s: %v", txID, err)
+		writeError(w, "EService:Unavailable")
+		return
+	}
+
+	status := "Pending"
+	if confs >= depositConfs {
+		status = krtypes.TransferStatusSuccess
+		k.depositsMtx.Lock()
+		d, found := k.deposits[txID]
+		if !found {
+			d = &deposit{amt: amt}
+			k.deposits[txID] = d
+		}
+		credit := !d.credited
+		d.credited = true
+		k.depositsMtx.Unlock()
+		if credit {
+			log.Debugf("Confirmed deposit of %.8f %s", amt, assetKey)
+			k.updateBalances(extractAPIKey(r), map[string][2]float64{assetKey: {amt, 0}})
+		}
+	}
+
+	writeResult(w, []*krtypes.Transfer{{
+		Method: method,
+		Asset:  assetKey,
+		TxID:   txID,
+		Amount: amt,
+		Status: status,
+	}})
+}
+
+func (k *fakeKraken) handleWithdrawAddresses(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, "EGeneral:Invalid arguments")
+		return
+	}
+	assetKey, address := r.Form.Get("asset"), r.Form.Get("address")
+	a := assetByKey(assetKey)
+	if a == nil {
+		writeError(w, "EFunding:Unknown asset")
+		return
+	}
+	// Every address is considered verified for every method.
+	resp := make([]*krtypes.WithdrawAddress, 0, len(a.methods))
+	for method := range a.methods {
+		resp = append(resp, &krtypes.WithdrawAddress{
+			Address:  address,
+			Asset:    assetKey,
+			Method:   method,
+			Key:      method + "|" + address,
+			Verified: true,
+		})
+	}
+	writeResult(w, resp)
+}
+
+func (k *fakeKraken) handleWithdrawInfo(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, "EGeneral:Invalid arguments")
+		return
+	}
+	a := assetByKey(r.Form.Get("asset"))
+	if a == nil {
+		writeError(w, "EFunding:Unknown asset")
+		return
+	}
+	amt, _ := strconv.ParseFloat(r.Form.Get("amount"), 64)
+	writeResult(w, &krtypes.WithdrawInfo{
+		Method: strings.Split(r.Form.Get("key"), "|")[0],
+		Limit:  math.MaxInt32,
+		Amount: amt,
+		Fee:    a.withdrawFee,
+	})
+}
+
+func (k *fakeKraken) handleWithdraw(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, "EGeneral:Invalid arguments")
+		return
+	}
+	assetKey := r.Form.Get("asset")
+	a := assetByKey(assetKey)
+	if a == nil {
+		writeError(w, "EFunding:Unknown asset")
+		return
+	}
+	keyParts := strings.SplitN(r.Form.Get("key"), "|", 2)
+	if len(keyParts) != 2 {
+		writeError(w, "EFunding:Unknown withdraw key")
+		return
+	}
+	method, address := keyParts[0], keyParts[1]
+	network, found := a.methods[method]
+	if !found {
+		writeError(w, "EFunding:Unknown withdraw key")
+		return
+	}
+	amt, err := strconv.ParseFloat(r.Form.Get("amount"), 64)
+	if err != nil || amt <= 0 {
+		writeError(w, "EFunding:Invalid amount")
+		return
+	}
+
+	k.balancesMtx.RLock()
+	bal := k.balances[assetKey]
+	insufficient := bal == nil || bal.Balance-bal.HoldTrade < amt+a.withdrawFee
+	k.balancesMtx.RUnlock()
+	if insufficient {
+		writeError(w, "EFunding:Insufficient funds")
+		return
+	}
+
+	wd := &withdrawal{
+		refID:   strings.ToUpper(hex.EncodeToString(encode.RandomBytes(9))),
+		asset:   assetKey,
+		network: network,
+		amt:     amt,
+		fee:     a.withdrawFee,
+		address: address,
+	}
+	k.withdrawalsMtx.Lock()
+	k.withdrawals = append(k.withdrawals, wd)
+	k.withdrawalsMtx.Unlock()
+
+	k.updateBalances(extractAPIKey(r), map[string][2]float64{assetKey: {-(amt + a.withdrawFee), 0}})
+
+	writeResult(w, &krtypes.WithdrawResult{RefID: wd.refID})
+}
+
+func (k *fakeKraken) handleWithdrawStatus(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, "EGeneral:Invalid arguments")
+		return
+	}
+	assetKey := r.Form.Get("asset")
+	resp := make([]*krtypes.Transfer, 0)
+	k.withdrawalsMtx.RLock()
+	for _, wd := range k.withdrawals {
+		if wd.asset != assetKey {
+			continue
+		}
+		status := "Pending"
+		if wd.txID != "" {
+			status = krtypes.TransferStatusSuccess
+		}
+		resp = append(resp, &krtypes.Transfer{
+			Asset:  assetKey,
+			RefID:  wd.refID,
+			TxID:   wd.txID,
+			Amount: wd.amt,
+			Fee:    wd.fee,
+			Status: status,
+		})
+	}
+	k.withdrawalsMtx.RUnlock()
+	writeResult(w, resp)
+}
+
+// writeResult writes a successful kraken response with the result.
+func writeResult(w http.ResponseWriter, result any) {
+	resultB, err := json.Marshal(result)
+	if err != nil {
+		log.Errorf("JSON encode error: %v", err)
+		writeError(w, "EGeneral:Internal error")
+		return
+	}
+	writeJSON(w, &krtypes.Response{Error: []string{}, Result: resultB})
+}
+
+// writeError writes a kraken error response. Kraken reports errors with a
+// 200 status code.
+func writeError(w http.ResponseWriter, errMsg string) {
+	writeJSON(w, &krtypes.Response{Error: []string{errMsg}})
+}
+
+func writeJSON(w http.ResponseWriter, resp *krtypes.Response) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	b, _ := json.Marshal(resp)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(append(b, byte('\n'))); err != nil {
+		log.Errorf("Write error: %v", err)
+	}
+}
+
+func extractAPIKey(r *http.Request) string {
+	return r.Header.Get("API-Key")
+}
+
+func floatString(v float64) string {
+	return strconv.FormatFloat(v, 'f', 8, 64)
+}
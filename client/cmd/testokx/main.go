@@ -0,0 +1,1086 @@
+package main
+
+/*
+ * Starts an http server that responds to some of the okx v5 api's endpoints,
+ * for testing the libxc okx client on simnet. Private endpoints and the
+ * websocket login are not authenticated. Deposits are credited to the
+ * funding account, and withdrawals are made from the funding account, just
+ * like the real thing.
+ */
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/client/cmd/internal/fakexc"
+	"decred.org/dcrdex/client/mm/libxc/okxtypes"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/fiatrates"
+	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/dex/ws"
+	"decred.org/dcrdex/server/comms"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	pongWait     = 60 * time.Second
+	pingPeriod   = (pongWait * 9) / 10
+	depositConfs = 3
+	// tradingFee is charged in the received asset for every fill.
+	tradingFee = 0.001
+)
+
+var (
+	log dex.Logger
+
+	walkingSpeedAdj float64
+	gapRange        float64
+)
+
+type okxCurrency struct {
+	ccy string
+	// chains maps the okx chain names to the simnet harness network.
+	chains  map[string]string
+	mainNet string
+	minWd   float64
+	minFee  float64
+}
+
+var (
+	currencies = []*okxCurrency{
+		{ccy: "BTC", chains: map[string]string{"BTC-Bitcoin": "btc"}, mainNet: "BTC-Bitcoin", minWd: 0.0005, minFee: 0.0001},
+		{ccy: "DCR", chains: map[string]string{"DCR-Decred": "dcr"}, mainNet: "DCR-Decred", minWd: 0.1, minFee: 0.01},
+		{ccy: "ETH", chains: map[string]string{"ETH-ERC20": "eth"}, mainNet: "ETH-ERC20", minWd: 0.001, minFee: 0.0005},
+		{ccy: "USDC", chains: map[string]string{"USDC-ERC20": "eth", "USDC-Polygon": "polygon"}, mainNet: "USDC-ERC20", minWd: 2, minFee: 1},
+		{ccy: "ZEC", chains: map[string]string{"ZEC-Zcash": "zec"}, mainNet: "ZEC-Zcash", minWd: 0.01, minFee: 0.001},
+	}
+
+	instruments = []*okxtypes.Instrument{
+		makeInstrument("DCR", "BTC", 1e-7, 1e-6, 0.1),
+		makeInstrument("ETH", "BTC", 1e-5, 1e-6, 0.001),
+		makeInstrument("DCR", "USDC", 0.01, 1e-6, 0.1),
+		makeInstrument("BTC", "USDC", 0.1, 1e-8, 0.00001),
+		makeInstrument("ZEC", "BTC", 1e-6, 1e-6, 0.01),
+	}
+
+	coinpapAssets = []*fiatrates.CoinpaprikaAsset{
+		makeCoinpapAsset(0, "btc", "Bitcoin"),
+		makeCoinpapAsset(42, "dcr", "Decred"),
+		makeCoinpapAsset(60, "eth", "Ethereum"),
+		makeCoinpapAsset(60001, "usdc.eth", "USDC"),
+		makeCoinpapAsset(133, "zec", "Zcash"),
+	}
+
+	initialBalances = map[string]float64{
+		"BTC":  1.5,
+		"DCR":  10000,
+		"ETH":  50,
+		"USDC": 1152,
+		"ZEC":  10000,
+	}
+
+	// ccyAssetIDs maps the okx currencies to the asset IDs used for the fiat
+	// rates.
+	ccyAssetIDs = map[string]uint32{
+		"BTC":  0,
+		"DCR":  42,
+		"ETH":  60,
+		"USDC": 60001,
+		"ZEC":  133,
+	}
+)
+
+func makeInstrument(base, quote string, tickSz, lotSz, minSz float64) *okxtypes.Instrument {
+	return &okxtypes.Instrument{
+		InstID:   base + "-" + quote,
+		BaseCcy:  base,
+		QuoteCcy: quote,
+		TickSz:   tickSz,
+		LotSz:    lotSz,
+		MinSz:    minSz,
+		MaxLmtSz: 1e9,
+		MaxMktSz: 1e6,
+		State:    "live",
+	}
+}
+
+func makeCoinpapAsset(assetID uint32, symbol, name string) *fiatrates.CoinpaprikaAsset {
+	return &fiatrates.CoinpaprikaAsset{
+		AssetID: assetID,
+		Symbol:  symbol,
+		Name:    name,
+	}
+}
+
+func currency(ccy string) *okxCurrency {
+	for _, c := range currencies {
+		if c.ccy == ccy {
+			return c
+		}
+	}
+	return nil
+}
+
+func instrument(instID string) *okxtypes.Instrument {
+	for _, inst := range instruments {
+		if inst.InstID == instID {
+			return inst
+		}
+	}
+	return nil
+}
+
+func main() {
+	var logDebug, logTrace bool
+	flag.Float64Var(&walkingSpeedAdj, "walkspeed", 1.0, "scale the maximum walking speed. default scale of 1.0 is about 3%")
+	flag.Float64Var(&gapRange, "gaprange", 0.04, "a ratio of how much the gap can vary. default is 0.04 => 4%")
+	flag.BoolVar(&logDebug, "debug", false, "use debug logging")
+	flag.BoolVar(&logTrace, "trace", false, "use trace logging")
+	flag.Parse()
+
+	switch {
+	case logTrace:
+		log = dex.StdOutLogger("TO", dex.LevelTrace)
+		comms.UseLogger(dex.StdOutLogger("C", dex.LevelTrace))
+	case logDebug:
+		log = dex.StdOutLogger("TO", dex.LevelDebug)
+		comms.UseLogger(dex.StdOutLogger("C", dex.LevelDebug))
+	default:
+		log = dex.StdOutLogger("TO", dex.LevelInfo)
+		comms.UseLogger(dex.StdOutLogger("C", dex.LevelInfo))
+	}
+
+	if err := mainErr(); err != nil {
+		fmt.Fprint(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func mainErr() error {
+	if walkingSpeedAdj > 10 {
+		return fmt.Errorf("invalid walkspeed must be in < 10")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	killChan := make(chan os.Signal, 1)
+	signal.Notify(killChan, os.Interrupt)
+	go func() {
+		<-killChan
+		log.Info("Shutting down...")
+		cancel()
+	}()
+
+	o, err := newFakeOKX(ctx)
+	if err != nil {
+		return err
+	}
+
+	o.run(ctx)
+
+	return nil
+}
+
+type balance struct {
+	avail  float64
+	frozen float64
+}
+
+type withdrawal struct {
+	id      string
+	ccy     string
+	chain   string
+	network string
+	amt     float64
+	fee     float64
+	address string
+	txID    string
+}
+
+type userOrder struct {
+	*okxtypes.Order
+	price  float64
+	apiKey string
+	stamp  time.Time
+}
+
+type wsClient struct {
+	*ws.WSLink
+	apiKey string
+	// books is the set of subscribed instrument IDs.
+	books map[string]bool
+	// orders and account are true if the client is subscribed to the
+	// private channels.
+	orders  bool
+	account bool
+}
+
+type fakeOKX struct {
+	ctx       context.Context
+	srv       *comms.Server
+	fiatRates map[uint32]float64
+
+	balancesMtx sync.RWMutex
+	trading     map[string]*balance
+	funding     map[string]*balance
+
+	marketsMtx sync.RWMutex
+	markets    map[string]*fakexc.Market // instID -> market
+
+	clientsMtx sync.RWMutex
+	clients    map[string]*wsClient
+
+	walletMtx sync.Mutex
+	wallets   map[string]fakexc.Wallet
+
+	depositsMtx sync.Mutex
+	deposits    map[string]bool // credited txids
+
+	withdrawalsMtx sync.RWMutex
+	withdrawals    []*withdrawal
+
+	ordersMtx sync.RWMutex
+	orders    map[string]*userOrder
+}
+
+func newFakeOKX(ctx context.Context) (*fakeOKX, error) {
+	log.Trace("Fetching coinpaprika prices")
+	fiatRates := fiatrates.FetchCoinpaprikaRates(ctx, coinpapAssets, dex.StdOutLogger("CP", dex.LevelDebug))
+	if len(fiatRates) < len(coinpapAssets) {
+		return nil, fmt.Errorf("not enough coinpap assets. wanted %d, got %d", len(coinpapAssets), len(fiatRates))
+	}
+
+	srv, err := comms.NewServer(&comms.RPCConfig{
+		ListenAddrs: []string{":37348"},
+		NoTLS:       true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error creating server: %w", err)
+	}
+
+	o := &fakeOKX{
+		ctx:       ctx,
+		srv:       srv,
+		fiatRates: fiatRates,
+		trading:   make(map[string]*balance, len(initialBalances)),
+		funding:   make(map[string]*balance, len(initialBalances)),
+		markets:   make(map[string]*fakexc.Market),
+		clients:   make(map[string]*wsClient),
+		wallets:   make(map[string]fakexc.Wallet),
+		deposits:  make(map[string]bool),
+		orders:    make(map[string]*userOrder),
+	}
+	for ccy, bal := range initialBalances {
+		o.trading[ccy] = &balance{avail: bal}
+		o.funding[ccy] = &balance{}
+	}
+
+	for _, inst := range instruments {
+		o.markets[inst.InstID] = fakexc.NewMarket(inst.InstID, fiatRates[ccyAssetIDs[inst.BaseCcy]], fiatRates[ccyAssetIDs[inst.QuoteCcy]],
+			0.03*walkingSpeedAdj, gapRange, log)
+	}
+
+	mux := srv.Mux()
+	mux.Route("/api/v5", func(r chi.Router) {
+		r.Get("/public/instruments", o.handleInstruments)
+		r.Get("/market/tickers", o.handleTickers)
+		r.Get("/account/balance", o.handleBalance)
+		r.Get("/trade/order", o.handleGetOrder)
+		r.Post("/trade/order", o.handlePlaceOrder)
+		r.Post("/trade/cancel-order", o.handleCancelOrder)
+		r.Get("/asset/currencies", o.handleCurrencies)
+		r.Get("/asset/deposit-address", o.handleDepositAddress)
+		r.Get("/asset/deposit-history", o.handleDepositHistory)
+		r.Post("/asset/transfer", o.handleTransfer)
+		r.Post("/asset/withdrawal", o.handleWithdrawal)
+		r.Get("/asset/withdrawal-history", o.handleWithdrawalHistory)
+	})
+	mux.Get("/ws/v5/public", o.handleWebsocket)
+	mux.Get("/ws/v5/private", o.handleWebsocket)
+
+	return o, nil
+}
+
+func (o *fakeOKX) run(ctx context.Context) {
+	// Shuffle the books periodically.
+	go func() {
+		const marketMinTick, marketTickRange = time.Second * 30, time.Second * 60
+		for {
+			delay := marketMinTick + time.Duration(rand.Float64()*float64(marketTickRange))
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+			o.marketsMtx.RLock()
+			for instID, mkt := range o.markets {
+				mkt.Shuffle()
+				o.sendBookSnapshot(instID, mkt, nil)
+			}
+			o.marketsMtx.RUnlock()
+		}
+	}()
+
+	// 50% chance of filling all booked orders every 5 to 30 seconds.
+	go func() {
+		const minFillTick, fillTickRange = 5 * time.Second, 25 * time.Second
+		for {
+			select {
+			case <-time.After(minFillTick + time.Duration(rand.Float64()*float64(fillTickRange))):
+			case <-ctx.Done():
+				return
+			}
+			if rand.Float32() < 0.5 {
+				continue
+			}
+			o.ordersMtx.Lock()
+			for id, ord := range o.orders {
+				if ord.State != "live" {
+					if time.Since(ord.stamp) > time.Hour {
+						delete(o.orders, id)
+					}
+					continue
+				}
+				o.fillOrder(ord, ord.price, true)
+			}
+			o.ordersMtx.Unlock()
+		}
+	}()
+
+	// Send withdrawals.
+	go func() {
+		for {
+			select {
+			case <-time.After(time.Second * 10):
+			case <-ctx.Done():
+				return
+			}
+			o.withdrawalsMtx.Lock()
+			for _, w := range o.withdrawals {
+				if w.txID != "" {
+					continue
+				}
+				wallet, err := o.getWallet(w.network)
+				if err != nil {
+					log.Errorf("No wallet for withdrawal network %s: %v", w.network, err)
+					continue
+				}
+				txID, err := wallet.Send(ctx, w.address, w.ccy, w.amt)
+				if err != nil {
+					log.Errorf("Error sending %s withdrawal: %v", w.ccy, err)
+					continue
+				}
+				log.Debugf("Sent withdrawal of %.8f %s, txid = %s", w.amt, w.ccy, txID)
+				w.txID = txID
+			}
+			o.withdrawalsMtx.Unlock()
+		}
+	}()
+
+	o.srv.Run(ctx)
+}
+
+func (o *fakeOKX) getWallet(network string) (fakexc.Wallet, error) {
+	o.walletMtx.Lock()
+	defer o.walletMtx.Unlock()
+	if w, found := o.wallets[network]; found {
+		return w, nil
+	}
+	w, err := fakexc.NewWallet(o.ctx, network, log)
+	if err != nil {
+		return nil, err
+	}
+	o.wallets[network] = w
+	return w, nil
+}
+
+func formatLevels(levels []*fakexc.RateQty) [][]json.Number {
+	book := make([][]json.Number, 0, len(levels))
+	for _, l := range levels {
+		book = append(book, []json.Number{
+			json.Number(strconv.FormatFloat(l.Rate, 'f', -1, 64)),
+			json.Number(strconv.FormatFloat(l.Qty, 'f', -1, 64)),
+			"0",
+			"1",
+		})
+	}
+	return book
+}
+
+// sendBookSnapshot sends the book to every client subscribed to the market.
+// If cl is non-nil, the book is only sent to that client. Updates are always
+// sent as snapshots, which the client handles by replacing its book.
+func (o *fakeOKX) sendBookSnapshot(instID string, mkt *fakexc.Market, cl *wsClient) {
+	buys, sells := mkt.Book()
+	dataB, _ := json.Marshal([]*okxtypes.BookData{{
+		Bids:      formatLevels(buys),
+		Asks:      formatLevels(sells),
+		SeqID:     time.Now().UnixMilli(),
+		PrevSeqID: -1,
+	}})
+	msgB, _ := json.Marshal(&okxtypes.WSMessage{
+		Arg:    &okxtypes.WSArg{Channel: "books", InstID: instID},
+		Action: "snapshot",
+		Data:   dataB,
+	})
+	if cl != nil {
+		cl.SendRaw(msgB)
+		return
+	}
+	o.clientsMtx.RLock()
+	defer o.clientsMtx.RUnlock()
+	for _, cl := range o.clients {
+		if cl.books[instID] {
+			cl.SendRaw(msgB)
+		}
+	}
+}
+
+func (o *fakeOKX) handleWebsocket(w http.ResponseWriter, r *http.Request) {
+	wsConn, err := ws.NewConnection(w, r, pongWait)
+	if err != nil {
+		log.Errorf("ws.NewConnection error: %v", err)
+		http.Error(w, "error initializing connection", http.StatusInternalServerError)
+		return
+	}
+
+	ip := dex.NewIPKey(r.RemoteAddr)
+	conn := ws.NewWSLink(ip.String(), wsConn, pingPeriod, func(msg *msgjson.Message) *msgjson.Error {
+		return nil
+	}, dex.StdOutLogger(fmt.Sprintf("CL[%s]", ip), dex.LevelDebug))
+
+	cl := &wsClient{
+		WSLink: conn,
+		books:  make(map[string]bool),
+	}
+
+	respond := func(event string, arg *okxtypes.WSArg, errMsg string) {
+		resp := &okxtypes.WSMessage{Event: event, Arg: arg, Code: "0"}
+		if errMsg != "" {
+			resp.Event, resp.Code, resp.Msg = "error", "60018", errMsg
+		}
+		b, _ := json.Marshal(resp)
+		conn.SendRaw(b)
+	}
+
+	conn.RawHandler = func(b []byte) {
+		if string(b) == "ping" {
+			conn.SendRaw([]byte("pong"))
+			return
+		}
+		var req struct {
+			Op   string            `json:"op"`
+			Args []json.RawMessage `json:"args"`
+		}
+		if err := json.Unmarshal(b, &req); err != nil {
+			log.Errorf("Error unmarshaling websocket request %s: %v", string(b), err)
+			return
+		}
+		switch req.Op {
+		case "login":
+			var login okxtypes.LoginArg
+			if len(req.Args) != 1 || json.Unmarshal(req.Args[0], &login) != nil {
+				respond("", nil, "Invalid login request")
+				return
+			}
+			o.clientsMtx.Lock()
+			cl.apiKey = login.APIKey
+			o.clientsMtx.Unlock()
+			respond("login", nil, "")
+		case "subscribe", "unsubscribe":
+			sub := req.Op == "subscribe"
+			for _, argB := range req.Args {
+				var arg okxtypes.WSArg
+				if err := json.Unmarshal(argB, &arg); err != nil {
+					respond("", nil, "Invalid subscription")
+					return
+				}
+				switch arg.Channel {
+				case "books":
+					mkt := o.markets[arg.InstID]
+					if mkt == nil {
+						respond("", nil, fmt.Sprintf("Wrong URL or channel:%s,instId:%s doesn't exist", arg.Channel, arg.InstID))
+						return
+					}
+					o.clientsMtx.Lock()
+					if sub {
+						cl.books[arg.InstID] = true
+					} else {
+						delete(cl.books, arg.InstID)
+					}
+					o.clientsMtx.Unlock()
+					respond(req.Op, &arg, "")
+					if sub {
+						o.sendBookSnapshot(arg.InstID, mkt, cl)
+					}
+				case "orders", "account":
+					o.clientsMtx.Lock()
+					if cl.apiKey == "" {
+						o.clientsMtx.Unlock()
+						respond("", nil, "Please log in")
+						return
+					}
+					if arg.Channel == "orders" {
+						cl.orders = sub
+					} else {
+						cl.account = sub
+					}
+					o.clientsMtx.Unlock()
+					respond(req.Op, &arg, "")
+				default:
+					respond("", nil, fmt.Sprintf("Unsupported channel %s", arg.Channel))
+				}
+			}
+		default:
+			respond("", nil, fmt.Sprintf("Unsupported operation %s", req.Op))
+		}
+	}
+
+	cm := dex.NewConnectionMaster(conn)
+	if err = cm.ConnectOnce(o.ctx); err != nil {
+		log.Errorf("Error connecting websocket client: %v", err)
+		return
+	}
+
+	addr := conn.Addr()
+	o.clientsMtx.Lock()
+	o.clients[addr] = cl
+	o.clientsMtx.Unlock()
+
+	go func() {
+		cm.Wait()
+		o.clientsMtx.Lock()
+		delete(o.clients, addr)
+		o.clientsMtx.Unlock()
+		log.Tracef("Websocket client %s disconnected", addr)
+	}()
+}
+
+// sendUserUpdate sends a message on a private channel to the user's
+// subscribed clients.
+func (o *fakeOKX) sendUserUpdate(apiKey string, arg *okxtypes.WSArg, data any) {
+	dataB, _ := json.Marshal(data)
+	msgB, _ := json.Marshal(&okxtypes.WSMessage{
+		Arg:  arg,
+		Data: dataB,
+	})
+	o.clientsMtx.RLock()
+	defer o.clientsMtx.RUnlock()
+	for _, cl := range o.clients {
+		if cl.apiKey != apiKey {
+			continue
+		}
+		if (arg.Channel == "orders" && cl.orders) || (arg.Channel == "account" && cl.account) {
+			cl.SendRaw(msgB)
+		}
+	}
+}
+
+func balanceDetail(ccy string, bal *balance) *okxtypes.BalanceDetail {
+	return &okxtypes.BalanceDetail{
+		Ccy:       ccy,
+		AvailBal:  bal.avail,
+		FrozenBal: bal.frozen,
+		CashBal:   bal.avail + bal.frozen,
+	}
+}
+
+// updateTradingBalances applies the changes to the available and frozen
+// trading account balances, and notifies the user.
+func (o *fakeOKX) updateTradingBalances(apiKey string, changes map[string][2]float64) {
+	o.balancesMtx.Lock()
+	details := make([]*okxtypes.BalanceDetail, 0, len(changes))
+	for ccy, change := range changes {
+		bal, found := o.trading[ccy]
+		if !found {
+			bal = &balance{}
+			o.trading[ccy] = bal
+		}
+		bal.avail += change[0]
+		bal.frozen += change[1]
+		details = append(details, balanceDetail(ccy, bal))
+	}
+	o.balancesMtx.Unlock()
+	o.sendUserUpdate(apiKey, &okxtypes.WSArg{Channel: "account"}, []*okxtypes.AccountBalance{{Details: details}})
+}
+
+// fillOrder fills the order at the rate, and sends an order update to the
+// user. ordersMtx must be held.
+func (o *fakeOKX) fillOrder(ord *userOrder, rate float64, booked bool) {
+	inst := instrument(ord.InstID)
+	baseQty := ord.Sz
+	if ord.TgtCcy == "quote_ccy" {
+		baseQty = ord.Sz / rate
+	}
+	quoteQty := baseQty * rate
+
+	changes := make(map[string][2]float64, 2)
+	if ord.Side == "sell" {
+		fee := quoteQty * tradingFee
+		ord.Fee, ord.FeeCcy = -fee, inst.QuoteCcy
+		hold, avail := 0.0, -baseQty
+		if booked {
+			hold, avail = -baseQty, 0
+		}
+		changes[inst.BaseCcy] = [2]float64{avail, hold}
+		changes[inst.QuoteCcy] = [2]float64{quoteQty - fee, 0}
+	} else {
+		fee := baseQty * tradingFee
+		ord.Fee, ord.FeeCcy = -fee, inst.BaseCcy
+		hold, avail := 0.0, -quoteQty
+		if booked {
+			hold, avail = -ord.Sz*ord.price, 0
+		}
+		changes[inst.BaseCcy] = [2]float64{baseQty - fee, 0}
+		changes[inst.QuoteCcy] = [2]float64{avail, hold}
+	}
+	ord.AccFillSz = baseQty
+	ord.AvgPx = strconv.FormatFloat(rate, 'f', -1, 64)
+	ord.State = "filled"
+
+	o.updateTradingBalances(ord.apiKey, changes)
+	o.sendUserUpdate(ord.apiKey, &okxtypes.WSArg{Channel: "orders", InstType: "SPOT"}, []*okxtypes.Order{ord.Order})
+}
+
+func (o *fakeOKX) handleInstruments(w http.ResponseWriter, r *http.Request) {
+	writeData(w, instruments)
+}
+
+func (o *fakeOKX) handleTickers(w http.ResponseWriter, r *http.Request) {
+	resp := make([]*okxtypes.Ticker, 0, len(instruments))
+	o.marketsMtx.RLock()
+	for instID, mkt := range o.markets {
+		vol24USD := math.Pow(10, float64(rand.Intn(4)+2))
+		last := mkt.BasisRate
+		high := last * (1 + rand.Float64()*0.15)
+		low := last / (1 + rand.Float64()*0.15)
+		vol := vol24USD / mkt.BaseFiatRate
+		resp = append(resp, &okxtypes.Ticker{
+			InstID:    instID,
+			Last:      last,
+			Open24h:   low + ((high - low) * rand.Float64()),
+			High24h:   high,
+			Low24h:    low,
+			Vol24h:    vol,
+			VolCcy24h: vol * last,
+		})
+	}
+	o.marketsMtx.RUnlock()
+	writeData(w, resp)
+}
+
+func (o *fakeOKX) handleBalance(w http.ResponseWriter, r *http.Request) {
+	o.balancesMtx.RLock()
+	details := make([]*okxtypes.BalanceDetail, 0, len(o.trading))
+	for ccy, bal := range o.trading {
+		details = append(details, balanceDetail(ccy, bal))
+	}
+	o.balancesMtx.RUnlock()
+	writeData(w, []*okxtypes.AccountBalance{{Details: details}})
+}
+
+func (o *fakeOKX) handleCurrencies(w http.ResponseWriter, r *http.Request) {
+	resp := make([]*okxtypes.Currency, 0, len(currencies))
+	for _, c := range currencies {
+		for chain := range c.chains {
+			resp = append(resp, &okxtypes.Currency{
+				Ccy:      c.ccy,
+				Chain:    chain,
+				CanDep:   true,
+				CanWd:    true,
+				MinWd:    c.minWd,
+				MinFee:   c.minFee,
+				WdTickSz: 8,
+				MainNet:  chain == c.mainNet,
+			})
+		}
+	}
+	writeData(w, resp)
+}
+
+func (o *fakeOKX) handlePlaceOrder(w http.ResponseWriter, r *http.Request) {
+	var req okxtypes.OrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "50014", "Invalid request body")
+		return
+	}
+	apiKey := extractAPIKey(r)
+	inst := instrument(req.InstID)
+	if inst == nil {
+		writeOrderError(w, "51001", "Instrument ID does not exist")
+		return
+	}
+	sz, err := strconv.ParseFloat(req.Sz, 64)
+	if err != nil || sz <= 0 {
+		writeOrderError(w, "51000", "Parameter sz error")
+		return
+	}
+
+	var price float64
+	switch req.OrdType {
+	case "limit", "ioc":
+		if price, err = strconv.ParseFloat(req.Px, 64); err != nil || price <= 0 {
+			writeOrderError(w, "51000", "Parameter px error")
+			return
+		}
+	case "market":
+		bestBuy, bestSell := o.markets[req.InstID].BestRates()
+		price = bestSell
+		if req.Side == "sell" {
+			price = bestBuy
+		}
+	default:
+		writeOrderError(w, "51000", "Parameter ordType error")
+		return
+	}
+
+	baseQty := sz
+	if req.TgtCcy == "quote_ccy" {
+		baseQty = sz / price
+	}
+	if baseQty < inst.MinSz {
+		writeOrderError(w, "51020", "Your order should meet or exceed the minimum order amount.")
+		return
+	}
+
+	// Check the balance.
+	fromCcy, fromQty := inst.QuoteCcy, baseQty*price
+	if req.Side == "sell" {
+		fromCcy, fromQty = inst.BaseCcy, baseQty
+	}
+	o.balancesMtx.RLock()
+	bal := o.trading[fromCcy]
+	insufficient := bal == nil || bal.avail < fromQty
+	o.balancesMtx.RUnlock()
+	if insufficient {
+		writeOrderError(w, "51008", "Order failed. Insufficient balance.")
+		return
+	}
+
+	ord := &userOrder{
+		Order: &okxtypes.Order{
+			InstID:  req.InstID,
+			OrdID:   strconv.FormatUint(rand.Uint64()>>1, 10),
+			ClOrdID: req.ClOrdID,
+			Side:    req.Side,
+			OrdType: req.OrdType,
+			Px:      req.Px,
+			Sz:      sz,
+			TgtCcy:  req.TgtCcy,
+			State:   "live",
+		},
+		price:  price,
+		apiKey: apiKey,
+		stamp:  time.Now(),
+	}
+
+	o.ordersMtx.Lock()
+	o.orders[ord.OrdID] = ord
+	o.ordersMtx.Unlock()
+
+	writeData(w, []*okxtypes.OrderResult{{
+		OrdID:   ord.OrdID,
+		ClOrdID: ord.ClOrdID,
+		SCode:   "0",
+	}})
+
+	bookIt := req.OrdType == "limit" && rand.Float32() < 0.2
+	go func() {
+		o.ordersMtx.Lock()
+		defer o.ordersMtx.Unlock()
+		if !bookIt {
+			log.Tracef("Filling %s order %s on %s for %.8f for user %s", req.Side, ord.OrdID, req.InstID, baseQty, apiKey)
+			o.fillOrder(ord, price, false)
+			return
+		}
+		log.Tracef("Booking %s order %s on %s for %.8f for user %s", req.Side, ord.OrdID, req.InstID, baseQty, apiKey)
+		o.updateTradingBalances(apiKey, map[string][2]float64{fromCcy: {-fromQty, fromQty}})
+		o.sendUserUpdate(apiKey, &okxtypes.WSArg{Channel: "orders", InstType: "SPOT"}, []*okxtypes.Order{ord.Order})
+	}()
+}
+
+func (o *fakeOKX) handleCancelOrder(w http.ResponseWriter, r *http.Request) {
+	var req okxtypes.CancelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "50014", "Invalid request body")
+		return
+	}
+	o.ordersMtx.Lock()
+	defer o.ordersMtx.Unlock()
+	ord, found := o.orders[req.OrdID]
+	if !found || ord.InstID != req.InstID {
+		writeOrderError(w, "51400", "Order cancellation failed as the order has been filled, canceled or does not exist.")
+		return
+	}
+	if ord.State != "live" {
+		writeOrderError(w, "51400", "Order cancellation failed as the order has been filled, canceled or does not exist.")
+		return
+	}
+	ord.State = "canceled"
+	inst := instrument(ord.InstID)
+	fromCcy, fromQty := inst.QuoteCcy, ord.Sz*ord.price
+	if ord.Side == "sell" {
+		fromCcy, fromQty = inst.BaseCcy, ord.Sz
+	}
+	o.updateTradingBalances(ord.apiKey, map[string][2]float64{fromCcy: {fromQty, -fromQty}})
+	writeData(w, []*okxtypes.OrderResult{{
+		OrdID:   ord.OrdID,
+		ClOrdID: ord.ClOrdID,
+		SCode:   "0",
+	}})
+	o.sendUserUpdate(ord.apiKey, &okxtypes.WSArg{Channel: "orders", InstType: "SPOT"}, []*okxtypes.Order{ord.Order})
+}
+
+func (o *fakeOKX) handleGetOrder(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	o.ordersMtx.RLock()
+	defer o.ordersMtx.RUnlock()
+	ord, found := o.orders[q.Get("ordId")]
+	if !found || ord.InstID != q.Get("instId") {
+		writeError(w, http.StatusOK, "51603", "Order does not exist")
+		return
+	}
+	writeData(w, []*okxtypes.Order{ord.Order})
+}
+
+func (o *fakeOKX) handleDepositAddress(w http.ResponseWriter, r *http.Request) {
+	c := currency(r.URL.Query().Get("ccy"))
+	if c == nil {
+		writeError(w, http.StatusOK, "51001", "Currency does not exist")
+		return
+	}
+	resp := make([]*okxtypes.DepositAddress, 0, len(c.chains))
+	for chain, network := range c.chains {
+		wallet, err := o.getWallet(network)
+		if err != nil {
+			log.Errorf("Error getting %s wallet: %v", network, err)
+			continue
+		}
+		resp = append(resp, &okxtypes.DepositAddress{
+			Addr:     wallet.DepositAddress(),
+			Ccy:      c.ccy,
+			Chain:    chain,
+			Selected: chain == c.mainNet,
+		})
+	}
+	writeData(w, resp)
+}
+
+func (o *fakeOKX) handleDepositHistory(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	c := currency(q.Get("ccy"))
+	if c == nil {
+		writeError(w, http.StatusOK, "51001", "Currency does not exist")
+		return
+	}
+	txID := q.Get("txId")
+	amt, err := strconv.ParseFloat(q.Get("amt"), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "50014", "Parameter amt can not be empty")
+		return
+	}
+
+	// Look for the transaction on each of the currency's chains.
+	for chain, network := range c.chains {
+		wallet, err := o.getWallet(network)
+		if err != nil {
+			log.Errorf("Error getting %s wallet: %v", network, err)
+			continue
+		}
+		confs, err := wallet.Confirmations(o.ctx, txID)
+		if err != nil {
+			continue
+		}
+		state := "0" // waiting for confirmation
+		if confs >= depositConfs {
+			state = okxtypes.DepositStateSuccessful
+			o.depositsMtx.Lock()
+			credit := !o.deposits[txID]
+			o.deposits[txID] = true
+			o.depositsMtx.Unlock()
+			if credit {
+				log.Debugf("Confirmed deposit of %.8f %s", amt, c.ccy)
+				o.balancesMtx.Lock()
+				o.funding[c.ccy].avail += amt
+				o.balancesMtx.Unlock()
+			}
+		}
+		writeData(w, []*okxtypes.Deposit{{
+			Ccy:   c.ccy,
+			Chain: chain,
+			Amt:   amt,
+			TxID:  txID,
+			State: state,
+		}})
+		return
+	}
+
+	writeData(w, []*okxtypes.Deposit{})
+}
+
+func (o *fakeOKX) handleTransfer(w http.ResponseWriter, r *http.Request) {
+	var req okxtypes.TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "50014", "Invalid request body")
+		return
+	}
+	amt, err := strconv.ParseFloat(req.Amt, 64)
+	if err != nil || amt <= 0 {
+		writeError(w, http.StatusOK, "51000", "Parameter amt error")
+		return
+	}
+	if currency(req.Ccy) == nil {
+		writeError(w, http.StatusOK, "51001", "Currency does not exist")
+		return
+	}
+
+	o.balancesMtx.Lock()
+	var from, to *balance
+	switch {
+	case req.From == okxtypes.AccountFunding && req.To == okxtypes.AccountTrading:
+		from, to = o.funding[req.Ccy], o.trading[req.Ccy]
+	case req.From == okxtypes.AccountTrading && req.To == okxtypes.AccountFunding:
+		from, to = o.trading[req.Ccy], o.funding[req.Ccy]
+	default:
+		o.balancesMtx.Unlock()
+		writeError(w, http.StatusOK, "58123", "Parameter from cannot equal to parameter to.")
+		return
+	}
+	// Allow for some float imprecision.
+	if from.avail < amt*(1-1e-9) {
+		o.balancesMtx.Unlock()
+		writeError(w, http.StatusOK, "58350", "Insufficient balance")
+		return
+	}
+	from.avail = math.Max(from.avail-amt, 0)
+	to.avail += amt
+	o.balancesMtx.Unlock()
+
+	// Send the new trading balances to the user.
+	o.updateTradingBalances(extractAPIKey(r), map[string][2]float64{req.Ccy: {0, 0}})
+
+	writeData(w, []*okxtypes.TransferResult{{TransID: hex.EncodeToString(encode.RandomBytes(8))}})
+}
+
+func (o *fakeOKX) handleWithdrawal(w http.ResponseWriter, r *http.Request) {
+	var req okxtypes.WithdrawalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "50014", "Invalid request body")
+		return
+	}
+	c := currency(req.Ccy)
+	if c == nil {
+		writeError(w, http.StatusOK, "51001", "Currency does not exist")
+		return
+	}
+	network, found := c.chains[req.Chain]
+	if !found {
+		writeError(w, http.StatusOK, "58207", "Withdrawal chain is not supported")
+		return
+	}
+	amt, err := strconv.ParseFloat(req.Amt, 64)
+	if err != nil || amt < c.minWd {
+		writeError(w, http.StatusOK, "58201", "Withdrawal amount is lower than the minimum")
+		return
+	}
+
+	o.balancesMtx.Lock()
+	bal := o.funding[c.ccy]
+	if bal.avail < (amt+c.minFee)*(1-1e-9) {
+		o.balancesMtx.Unlock()
+		writeError(w, http.StatusOK, "58350", "Insufficient balance")
+		return
+	}
+	bal.avail = math.Max(bal.avail-amt-c.minFee, 0)
+	o.balancesMtx.Unlock()
+
+	wd := &withdrawal{
+		id:      strconv.FormatUint(rand.Uint64()>>1, 10),
+		ccy:     c.ccy,
+		chain:   req.Chain,
+		network: network,
+		amt:     amt,
+		fee:     c.minFee,
+		address: req.ToAddr,
+	}
+	o.withdrawalsMtx.Lock()
+	o.withdrawals = append(o.withdrawals, wd)
+	o.withdrawalsMtx.Unlock()
+
+	writeData(w, []*okxtypes.WithdrawalResult{{WdID: wd.id}})
+}
+
+func (o *fakeOKX) handleWithdrawalHistory(w http.ResponseWriter, r *http.Request) {
+	wdID := r.URL.Query().Get("wdId")
+	resp := make([]*okxtypes.Withdrawal, 0, 1)
+	o.withdrawalsMtx.RLock()
+	for _, wd := range o.withdrawals {
+		if wdID != "" && wd.id != wdID {
+			continue
+		}
+		state := "1" // broadcasting
+		if wd.txID != "" {
+			state = "2" // success
+		}
+		resp = append(resp, &okxtypes.Withdrawal{
+			WdID:  wd.id,
+			TxID:  wd.txID,
+			Ccy:   wd.ccy,
+			Chain: wd.chain,
+			Amt:   wd.amt,
+			Fee:   wd.fee,
+			State: state,
+		})
+	}
+	o.withdrawalsMtx.RUnlock()
+	writeData(w, resp)
+}
+
+// writeData writes a successful okx response with the data.
+func writeData(w http.ResponseWriter, data any) {
+	dataB, err := json.Marshal(data)
+	if err != nil {
+		log.Errorf("JSON encode error: %v", err)
+		writeError(w, http.StatusInternalServerError, "50000", "Internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, &okxtypes.Response{Code: "0", Data: dataB})
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code, msg string) {
+	writeJSON(w, statusCode, &okxtypes.Response{Code: code, Msg: msg, Data: json.RawMessage("[]")})
+}
+
+// writeOrderError writes the error response for the trade endpoints, which
+// report the reason in the data.
+func writeOrderError(w http.ResponseWriter, sCode, sMsg string) {
+	dataB, _ := json.Marshal([]*okxtypes.OrderResult{{SCode: sCode, SMsg: sMsg}})
+	writeJSON(w, http.StatusOK, &okxtypes.Response{Code: "1", Msg: "Operation failed.", Data: dataB})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, resp *okxtypes.Response) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	b, _ := json.Marshal(resp)
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(append(b, byte('\n'))); err != nil {
+		log.Errorf("Write error: %v", err)
+	}
+}
+
+func extractAPIKey(r *http.Request) string {
+	return r.Header.Get("OK-ACCESS-KEY")
+}
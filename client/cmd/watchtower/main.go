@@ -0,0 +1,170 @@
+package main
+
+/*
+ * watchtower is a standalone swap watchtower. Clients hand it their swap
+ * contracts and pre-signed refund transactions, and the watchtower broadcasts
+ * the refunds once the contracts' locktimes have passed, even if the client
+ * is offline. Chains are monitored through the same asset backends that the
+ * DEX server uses, so each asset requires a full node configured as it would
+ * be for the server, e.g.
+ *
+ *   watchtower --asset 42:~/.dcrd/dcrd.conf --asset 0:~/.bitcoin/bitcoin.conf
+ *
+ * Only UTXO-based assets are supported.
+ */
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"decred.org/dcrdex/client/watchtower/tower"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/asset"
+	_ "decred.org/dcrdex/server/asset/importall"
+	"github.com/decred/dcrd/dcrutil/v4"
+)
+
+var log = dex.StdOutLogger("TOWER", dex.LevelInfo)
+
+type assetFlags []string
+
+func (f *assetFlags) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *assetFlags) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+func main() {
+	if err := mainErr(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func mainErr() error {
+	var assets assetFlags
+	var netStr, listen, dbPath, apiKey string
+	var debug bool
+	var maxJobs int
+	flag.Var(&assets, "asset", "asset to watch, as assetID:configpath. Can be specified multiple times")
+	flag.StringVar(&netStr, "net", "mainnet", "network: mainnet, testnet or simnet")
+	flag.StringVar(&listen, "listen", "127.0.0.1:7242", "HTTP listen address")
+	flag.StringVar(&dbPath, "db", filepath.Join(dcrutil.AppDataDir("dexwatchtower", false), "tower.db"), "database file path")
+	flag.StringVar(&apiKey, "apikey", "", "API key that clients must provide. Strongly recommended if not listening on localhost")
+	flag.IntVar(&maxJobs, "maxjobs", tower.DefaultMaxJobs, "maximum number of unfinished jobs")
+	flag.BoolVar(&debug, "debug", false, "log at debug level")
+	flag.Parse()
+
+	if debug {
+		log.SetLevel(dex.LevelDebug)
+	}
+
+	net, err := dex.NetFromString(netStr)
+	if err != nil {
+		return err
+	}
+	if len(assets) == 0 {
+		return errors.New("no assets specified")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	killChan := make(chan os.Signal, 1)
+	signal.Notify(killChan, os.Interrupt)
+	go func() {
+		<-killChan
+		log.Infof("Shutting down...")
+		cancel()
+	}()
+
+	backends := make(map[uint32]tower.Backend, len(assets))
+	var cms []*dex.ConnectionMaster
+	defer func() {
+		for _, cm := range cms {
+			cm.Disconnect()
+		}
+	}()
+	for _, a := range assets {
+		assetIDStr, configPath, found := strings.Cut(a, ":")
+		if !found {
+			return fmt.Errorf("invalid asset %q. expected assetID:configpath", a)
+		}
+		v, err := strconv.ParseUint(assetIDStr, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid asset ID %q", assetIDStr)
+		}
+		assetID := uint32(v)
+		symbol := dex.BipIDSymbol(assetID)
+		be, err := asset.Setup(&asset.BackendConfig{
+			AssetID:    assetID,
+			ConfigPath: configPath,
+			Logger:     log.SubLogger(strings.ToUpper(symbol)),
+			Net:        net,
+		})
+		if err != nil {
+			return fmt.Errorf("error setting up %s backend: %w", symbol, err)
+		}
+		tbe, ok := be.(tower.Backend)
+		if !ok {
+			return fmt.Errorf("%s backend cannot broadcast transactions", symbol)
+		}
+		if _, is := be.(asset.AccountBalancer); is {
+			return fmt.Errorf("%s is not a UTXO-based asset", symbol)
+		}
+		cm := dex.NewConnectionMaster(be)
+		if err := cm.ConnectOnce(ctx); err != nil {
+			return fmt.Errorf("error connecting %s backend: %w", symbol, err)
+		}
+		cms = append(cms, cm)
+		backends[assetID] = tbe
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0700); err != nil {
+		return fmt.Errorf("error creating database directory: %w", err)
+	}
+	t, err := tower.New(&tower.Config{
+		Backends: backends,
+		DBPath:   dbPath,
+		MaxJobs:  maxJobs,
+		Logger:   log,
+	})
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              listen,
+		Handler:           t.Handler(apiKey),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	go func() {
+		log.Infof("Listening on %s", listen)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("HTTP server error: %v", err)
+			cancel()
+		}
+	}()
+
+	t.Run(ctx)
+	return nil
+}
@@ -803,12 +803,16 @@ func handleTradeResumptionMsg(c *Core, dc *dexConnection, msg *msgjson.Message)
 	dc.epoch[rs.MarketID] = rs.StartEpoch
 	dc.epochMtx.Unlock()
 
-	// TODO: Server config change without restart is not implemented on the
-	// server, but it would involve either getting the config response or adding
-	// the entire market config to the TradeResumption payload.
-	//
-	// Fetch the updated DEX configuration.
-	// dc.refreshServerConfig()
+	// Fetch the updated DEX configuration if the market's lot size or rate
+	// step changed while it was suspended.
+	if rs.ConfigChange {
+		if _, err := dc.refreshServerConfig(); err != nil {
+			c.log.Errorf("Failed to refresh %s config after %s market config change: %v",
+				dc.acct.host, rs.MarketID, err)
+		} else {
+			c.notify(newServerConfigUpdateNote(dc.acct.host))
+		}
+	}
 
 	subject, detail := c.formatDetails(TopicMarketResumed, rs.MarketID, dc.acct.host, rs.StartEpoch)
 	c.notify(newServerNotifyNote(TopicMarketResumed, subject, detail, db.Success))
@@ -818,6 +822,20 @@ func handleTradeResumptionMsg(c *Core, dc *dexConnection, msg *msgjson.Message)
 	return nil
 }
 
+// handleConfigChangeMsg is called when a config_change notification is
+// received, such as when the server adds or retires a market.
+func handleConfigChangeMsg(c *Core, dc *dexConnection, msg *msgjson.Message) error {
+	cfg := new(msgjson.ConfigResult)
+	if err := msg.Unmarshal(cfg); err != nil {
+		return fmt.Errorf("config change unmarshal error: %w", err)
+	}
+	if err := dc.applyServerConfig(cfg); err != nil {
+		return fmt.Errorf("unable to apply new configuration for DEX at %s: %w", dc.acct.host, err)
+	}
+	c.notify(newServerConfigUpdateNote(dc.acct.host))
+	return nil
+}
+
 // refreshServerConfig fetches and replaces server configuration data. It also
 // initially checks that a server's API version is one of serverAPIVers.
 func (dc *dexConnection) refreshServerConfig() (*msgjson.ConfigResult, error) {
@@ -827,7 +845,15 @@ func (dc *dexConnection) refreshServerConfig() (*msgjson.ConfigResult, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to fetch server config: %w", err)
 	}
+	if err := dc.applyServerConfig(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
 
+// applyServerConfig checks that the server's API version is one of
+// serverAPIVers, and replaces the server configuration data.
+func (dc *dexConnection) applyServerConfig(cfg *msgjson.ConfigResult) error {
 	apiVer := int32(cfg.APIVersion)
 	dc.log.Infof("Server %v supports API version %v.", dc.acct.host, cfg.APIVersion)
 	atomic.StoreInt32(&dc.apiVer, apiVer)
@@ -844,7 +870,7 @@ func (dc *dexConnection) refreshServerConfig() (*msgjson.ConfigResult, error) {
 		if apiVer > supportedAPIVers[len(supportedAPIVers)-1] {
 			err = fmt.Errorf("%v: %w", err, outdatedClientErr)
 		}
-		return nil, err
+		return err
 	}
 
 	bTimeout := time.Millisecond * time.Duration(cfg.BroadcastTimeout)
@@ -862,7 +888,7 @@ func (dc *dexConnection) refreshServerConfig() (*msgjson.ConfigResult, error) {
 
 	assets, epochs, err := generateDEXMaps(dc.acct.host, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("inconsistent 'config' response: %w", err)
+		return fmt.Errorf("inconsistent 'config' response: %w", err)
 	}
 
 	// Update dc.{epoch,assets}
@@ -878,7 +904,7 @@ func (dc *dexConnection) refreshServerConfig() (*msgjson.ConfigResult, error) {
 	if dc.acct.dexPubKey == nil && len(cfg.DEXPubKey) > 0 {
 		dc.acct.dexPubKey, err = secp256k1.ParsePubKey(cfg.DEXPubKey)
 		if err != nil {
-			return nil, fmt.Errorf("error decoding secp256k1 PublicKey from bytes: %w", err)
+			return fmt.Errorf("error decoding secp256k1 PublicKey from bytes: %w", err)
 		}
 	}
 
@@ -887,7 +913,7 @@ func (dc *dexConnection) refreshServerConfig() (*msgjson.ConfigResult, error) {
 	dc.resolvedEpoch = utils.CopyMap(epochs)
 	dc.epochMtx.Unlock()
 
-	return cfg, nil
+	return nil
 }
 
 // subPriceFeed subscribes to the price_feed notification feed and primes the
@@ -0,0 +1,453 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/client/orderbook"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
+)
+
+// conditionalCheckInterval is how often the conditional order supervisor
+// checks the fiat rate triggers and makes sure the books needed for the
+// mid-gap triggers are synced.
+const conditionalCheckInterval = 10 * time.Second
+
+// ConditionalOrderForm is the information necessary to place a conditional
+// order.
+type ConditionalOrderForm struct {
+	Trade *TradeForm `json:"trade"`
+	// Type is either "stoploss" or "takeprofit". A stop-loss sell is
+	// triggered when the price falls to the trigger price, while a stop-loss
+	// buy is triggered when the price rises to the trigger price. Take-profit
+	// orders trigger on price movements in the opposite direction.
+	Type string `json:"type"`
+	// Trigger is either "midgap", to trigger on the mid-gap rate of the
+	// market's order book, or "fiat", to trigger on the fiat rate of the base
+	// asset.
+	Trigger string `json:"trigger"`
+	// TriggerRate is the mid-gap message rate for "midgap" triggers.
+	TriggerRate uint64 `json:"triggerRate"`
+	// TriggerFiatRate is the base asset fiat rate for "fiat" triggers.
+	TriggerFiatRate float64 `json:"triggerFiatRate"`
+}
+
+// ConditionalOrder is an order that is held by the client until its trigger
+// price is reached, at which point it is placed with Trade.
+type ConditionalOrder struct {
+	ID              dex.Bytes  `json:"id"`
+	Trade           *TradeForm `json:"trade"`
+	Type            string     `json:"type"`
+	Trigger         string     `json:"trigger"`
+	TriggerRate     uint64     `json:"triggerRate"`
+	TriggerFiatRate float64    `json:"triggerFiatRate"`
+	Stamp           uint64     `json:"stamp"`
+	Status          string     `json:"status"`
+	// OrderID is the ID of the order placed when the conditional order was
+	// triggered.
+	OrderID dex.Bytes `json:"orderID,omitempty"`
+	// Error is the reason a triggered order could not be placed.
+	Error string `json:"error,omitempty"`
+}
+
+func conditionalOrderFromDB(o *db.ConditionalOrder) *ConditionalOrder {
+	return &ConditionalOrder{
+		ID:              o.ID,
+		Trade:           conditionalTradeForm(o),
+		Type:            o.Type.String(),
+		Trigger:         o.Trigger.String(),
+		TriggerRate:     o.TriggerRate,
+		TriggerFiatRate: o.TriggerFiatRate,
+		Stamp:           o.Stamp,
+		Status:          o.Status.String(),
+		OrderID:         o.OrderID,
+		Error:           o.Error,
+	}
+}
+
+func conditionalTradeForm(o *db.ConditionalOrder) *TradeForm {
+	return &TradeForm{
+		Host:    o.Host,
+		IsLimit: o.IsLimit,
+		Sell:    o.Sell,
+		Base:    o.Base,
+		Quote:   o.Quote,
+		Qty:     o.Qty,
+		Rate:    o.Rate,
+		TifNow:  o.TifNow,
+		Options: o.Options,
+	}
+}
+
+// conditionMet checks whether the price has reached the conditional order's
+// trigger price.
+func conditionMet(o *db.ConditionalOrder, price float64) bool {
+	trigger := o.TriggerFiatRate
+	if o.Trigger == db.TriggerMidGap {
+		trigger = float64(o.TriggerRate)
+	}
+	if (o.Type == db.TakeProfit) == o.Sell {
+		return price >= trigger
+	}
+	return price <= trigger
+}
+
+// conditionalBookFeed is a book subscription used to monitor the mid-gap rate
+// of a market with active mid-gap triggered conditional orders.
+type conditionalBookFeed struct {
+	book   *orderbook.OrderBook
+	feed   BookFeed
+	cancel context.CancelFunc
+}
+
+// conditionalMarketKey is the key for a market in the conditional order
+// supervisor.
+func conditionalMarketKey(host string, base, quote uint32) string {
+	return host + "|" + marketName(base, quote)
+}
+
+// PlaceConditionalOrder stores a stop-loss or take-profit order that will be
+// placed with Trade when its trigger price is reached. The wallets must be
+// unlocked and the user logged in at that time for the order to be placed.
+func (c *Core) PlaceConditionalOrder(form *ConditionalOrderForm) (*ConditionalOrder, error) {
+	tf := form.Trade
+	if tf == nil {
+		return nil, errors.New("no trade specified")
+	}
+	if tf.FillOrKill || tf.PostOnly {
+		return nil, errors.New("fill-or-kill and post-only conditional orders are not supported")
+	}
+
+	o := &db.ConditionalOrder{
+		Host:            tf.Host,
+		Base:            tf.Base,
+		Quote:           tf.Quote,
+		Sell:            tf.Sell,
+		IsLimit:         tf.IsLimit,
+		TifNow:          tf.TifNow,
+		Qty:             tf.Qty,
+		Rate:            tf.Rate,
+		Options:         tf.Options,
+		TriggerRate:     form.TriggerRate,
+		TriggerFiatRate: form.TriggerFiatRate,
+	}
+	switch form.Type {
+	case db.StopLoss.String():
+		o.Type = db.StopLoss
+	case db.TakeProfit.String():
+		o.Type = db.TakeProfit
+	default:
+		return nil, fmt.Errorf("unknown conditional order type %q", form.Type)
+	}
+	switch form.Trigger {
+	case db.TriggerMidGap.String():
+		o.Trigger = db.TriggerMidGap
+		if o.TriggerRate == 0 {
+			return nil, errors.New("zero trigger rate")
+		}
+	case db.TriggerFiatRate.String():
+		o.Trigger = db.TriggerFiatRate
+		if o.TriggerFiatRate <= 0 {
+			return nil, errors.New("invalid trigger fiat rate")
+		}
+	default:
+		return nil, fmt.Errorf("unknown conditional order trigger %q", form.Trigger)
+	}
+
+	dc, err := c.registeredDEX(tf.Host)
+	if err != nil {
+		return nil, err
+	}
+	mktID := marketName(tf.Base, tf.Quote)
+	mkt := dc.marketConfig(mktID)
+	if mkt == nil {
+		return nil, newError(marketErr, "unknown market %q", mktID)
+	}
+	if tf.Qty == 0 {
+		return nil, newError(orderParamsErr, "zero quantity not allowed")
+	}
+	if tf.IsLimit {
+		if tf.Rate == 0 {
+			return nil, newError(orderParamsErr, "zero rate is invalid")
+		}
+		if tf.Rate%mkt.RateStep != 0 {
+			return nil, newError(orderParamsErr, "order rate %d not a multiple of rate step %d", tf.Rate, mkt.RateStep)
+		}
+	}
+	// Market buy quantities are in units of the quote asset.
+	if (tf.IsLimit || tf.Sell) && tf.Qty%mkt.LotSize != 0 {
+		return nil, newError(orderParamsErr, "order quantity %d not a multiple of lot size %d", tf.Qty, mkt.LotSize)
+	}
+
+	// Don't accept an order that would trigger immediately.
+	if price, ok := c.conditionalPrice(o); ok && conditionMet(o, price) {
+		return nil, newError(orderParamsErr, "%s trigger price has already been reached", o.Trigger)
+	}
+
+	o.ID = encode.RandomBytes(8)
+	o.Stamp = uint64(time.Now().UnixMilli())
+	if err := c.db.UpdateConditionalOrder(o); err != nil {
+		return nil, fmt.Errorf("error storing conditional order: %w", err)
+	}
+
+	c.condMtx.Lock()
+	c.condOrders[dex.Bytes(o.ID).String()] = o
+	c.condMtx.Unlock()
+
+	if o.Trigger == db.TriggerMidGap {
+		c.syncConditionalFeeds()
+	}
+
+	return conditionalOrderFromDB(o), nil
+}
+
+// CancelConditionalOrder cancels an active conditional order.
+func (c *Core) CancelConditionalOrder(id dex.Bytes) error {
+	c.condMtx.Lock()
+	o, found := c.condOrders[id.String()]
+	if !found {
+		c.condMtx.Unlock()
+		return fmt.Errorf("no active conditional order %s", id)
+	}
+	delete(c.condOrders, id.String())
+	c.condMtx.Unlock()
+
+	o.Status = db.ConditionalOrderCanceled
+	if err := c.db.UpdateConditionalOrder(o); err != nil {
+		return fmt.Errorf("error updating conditional order: %w", err)
+	}
+	c.syncConditionalFeeds()
+	return nil
+}
+
+// ConditionalOrders returns all stored conditional orders, newest first. If
+// activeOnly is true, only orders that have not been triggered or canceled are
+// returned.
+func (c *Core) ConditionalOrders(activeOnly bool) ([]*ConditionalOrder, error) {
+	dbOrds, err := c.db.ConditionalOrders(activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	ords := make([]*ConditionalOrder, 0, len(dbOrds))
+	for _, o := range dbOrds {
+		ords = append(ords, conditionalOrderFromDB(o))
+	}
+	return ords, nil
+}
+
+// conditionalPrice gets the current price used for the conditional order's
+// trigger.
+func (c *Core) conditionalPrice(o *db.ConditionalOrder) (float64, bool) {
+	switch o.Trigger {
+	case db.TriggerMidGap:
+		c.condMtx.RLock()
+		f, found := c.condFeeds[conditionalMarketKey(o.Host, o.Base, o.Quote)]
+		c.condMtx.RUnlock()
+		if found {
+			midGap, err := f.book.MidGap()
+			return float64(midGap), err == nil
+		}
+		book, err := c.Book(o.Host, o.Base, o.Quote)
+		if err != nil || len(book.Buys) == 0 || len(book.Sells) == 0 {
+			return 0, false
+		}
+		return float64(book.Buys[0].MsgRate+book.Sells[0].MsgRate) / 2, true
+	case db.TriggerFiatRate:
+		rate, found := c.fiatConversions()[o.Base]
+		return rate, found && rate > 0
+	}
+	return 0, false
+}
+
+// watchConditionalOrders loads the active conditional orders and monitors
+// their trigger prices until the context is canceled.
+func (c *Core) watchConditionalOrders(ctx context.Context) {
+	ords, err := c.db.ConditionalOrders(true)
+	if err != nil {
+		c.log.Errorf("Error loading conditional orders: %v", err)
+	}
+	c.condMtx.Lock()
+	for _, o := range ords {
+		c.condOrders[dex.Bytes(o.ID).String()] = o
+	}
+	c.condMtx.Unlock()
+
+	defer func() {
+		c.condMtx.Lock()
+		defer c.condMtx.Unlock()
+		for key, f := range c.condFeeds {
+			f.cancel()
+			f.feed.Close()
+			delete(c.condFeeds, key)
+		}
+	}()
+
+	ticker := time.NewTicker(conditionalCheckInterval)
+	defer ticker.Stop()
+	for {
+		c.syncConditionalFeeds()
+		c.checkConditionalOrders(func(o *db.ConditionalOrder) bool { return true })
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// syncConditionalFeeds makes sure that there is a book subscription for every
+// market with active mid-gap triggered orders, and closes subscriptions that
+// are no longer needed. condMtx is not held while subscribing, since SyncBook
+// requires a round trip to the server.
+func (c *Core) syncConditionalFeeds() {
+	type market struct {
+		host        string
+		base, quote uint32
+	}
+	// neededMarkets must be called with the condMtx locked.
+	neededMarkets := func() map[string]*market {
+		needed := make(map[string]*market)
+		for _, o := range c.condOrders {
+			if o.Trigger == db.TriggerMidGap {
+				needed[conditionalMarketKey(o.Host, o.Base, o.Quote)] = &market{o.Host, o.Base, o.Quote}
+			}
+		}
+		return needed
+	}
+
+	c.condMtx.Lock()
+	if c.ctx == nil || c.ctx.Err() != nil {
+		c.condMtx.Unlock()
+		return
+	}
+	needed := neededMarkets()
+	for key, f := range c.condFeeds {
+		if needed[key] == nil {
+			f.cancel()
+			f.feed.Close()
+			delete(c.condFeeds, key)
+		}
+	}
+	missing := make(map[string]*market)
+	for key, mkt := range needed {
+		if c.condFeeds[key] == nil {
+			missing[key] = mkt
+		}
+	}
+	c.condMtx.Unlock()
+
+	for key, mkt := range missing {
+		book, feed, err := c.SyncBook(mkt.host, mkt.base, mkt.quote)
+		if err != nil {
+			c.log.Debugf("Unable to sync %s book for conditional orders: %v", key, err)
+			continue
+		}
+		c.condMtx.Lock()
+		// The orders may have been triggered or canceled, or another sync may
+		// have subscribed, while we were waiting on the server.
+		if c.ctx.Err() != nil || c.condFeeds[key] != nil || neededMarkets()[key] == nil {
+			c.condMtx.Unlock()
+			feed.Close()
+			continue
+		}
+		ctx, cancel := context.WithCancel(c.ctx)
+		c.condFeeds[key] = &conditionalBookFeed{
+			book:   book,
+			feed:   feed,
+			cancel: cancel,
+		}
+		c.condMtx.Unlock()
+		c.wg.Add(1)
+		go func(key string) {
+			defer c.wg.Done()
+			for {
+				select {
+				case <-feed.Next():
+					c.checkConditionalOrders(func(o *db.ConditionalOrder) bool {
+						return o.Trigger == db.TriggerMidGap && conditionalMarketKey(o.Host, o.Base, o.Quote) == key
+					})
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(key)
+	}
+}
+
+// checkConditionalOrders triggers the active conditional orders that pass the
+// filter and whose trigger price has been reached. Nothing is triggered until
+// the user is logged in.
+func (c *Core) checkConditionalOrders(filter func(*db.ConditionalOrder) bool) {
+	c.loginMtx.Lock()
+	loggedIn := c.loggedIn
+	c.loginMtx.Unlock()
+	if !loggedIn {
+		return
+	}
+
+	c.condMtx.RLock()
+	candidates := make([]*db.ConditionalOrder, 0, len(c.condOrders))
+	for _, o := range c.condOrders {
+		if filter(o) {
+			candidates = append(candidates, o)
+		}
+	}
+	c.condMtx.RUnlock()
+
+	for _, o := range candidates {
+		price, ok := c.conditionalPrice(o)
+		if !ok || !conditionMet(o, price) {
+			continue
+		}
+		// Remove the order from the active orders first, so that it is only
+		// triggered once.
+		id := dex.Bytes(o.ID).String()
+		c.condMtx.Lock()
+		_, found := c.condOrders[id]
+		delete(c.condOrders, id)
+		c.condMtx.Unlock()
+		if !found {
+			continue
+		}
+		c.log.Infof("Conditional %s order %s on %s triggered at %s price %f",
+			o.Type, id, conditionalMarketKey(o.Host, o.Base, o.Quote), o.Trigger, price)
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.placeTriggeredOrder(o)
+		}()
+	}
+}
+
+// placeTriggeredOrder places the order for a triggered conditional order
+// through the regular Trade path. The wallets must already be unlocked.
+func (c *Core) placeTriggeredOrder(o *db.ConditionalOrder) {
+	corder, err := c.Trade(nil, conditionalTradeForm(o))
+	if err != nil {
+		o.Status = db.ConditionalOrderFailed
+		o.Error = err.Error()
+	} else {
+		o.Status = db.ConditionalOrderTriggered
+		o.OrderID = corder.ID
+	}
+	if err := c.db.UpdateConditionalOrder(o); err != nil {
+		c.log.Errorf("Error updating conditional order %s: %v", dex.Bytes(o.ID), err)
+	}
+	c.syncConditionalFeeds()
+
+	mktID := marketName(o.Base, o.Quote)
+	if err != nil {
+		subject, details := c.formatDetails(TopicConditionalOrderFailed, o.Type, dex.Bytes(o.ID), mktID, err)
+		c.notify(newConditionalOrderNote(TopicConditionalOrderFailed, subject, details, db.ErrorLevel, conditionalOrderFromDB(o)))
+		return
+	}
+	subject, details := c.formatDetails(TopicConditionalOrderTriggered, o.Type, dex.Bytes(o.ID), mktID, corder.ID.String())
+	c.notify(newConditionalOrderNote(TopicConditionalOrderTriggered, subject, details, db.Success, conditionalOrderFromDB(o)))
+}
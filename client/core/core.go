@@ -33,6 +33,7 @@ import (
 	"decred.org/dcrdex/client/db/bolt"
 	"decred.org/dcrdex/client/mnemonic"
 	"decred.org/dcrdex/client/orderbook"
+	"decred.org/dcrdex/client/watchtower"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/calc"
 	"decred.org/dcrdex/dex/config"
@@ -685,7 +686,7 @@ func (c *Core) tryCancel(dc *dexConnection, oid order.OrderID) (found bool, err
 // tryCancelTrade attempts to cancel the order.
 func (c *Core) tryCancelTrade(dc *dexConnection, tracker *trackedTrade) error {
 	oid := tracker.ID()
-	if lo, ok := tracker.Order.(*order.LimitOrder); !ok || lo.Force != order.StandingTiF {
+	if lo, ok := tracker.Order.(*order.LimitOrder); !ok || !lo.Force.Standing() {
 		return fmt.Errorf("cannot cancel %s order %s that is not a standing limit order", tracker.Type(), oid)
 	}
 
@@ -1186,7 +1187,7 @@ func (dc *dexConnection) reconcileTrades(srvOrderStatuses []*msgjson.OrderStatus
 		} else if ourStatus == order.OrderStatusEpoch && serverStatus == order.OrderStatusBooked {
 			// Only standing orders can move from Epoch to Booked. This must have
 			// happened in the client's absence (maybe a missed nomatch message).
-			if lo, ok := trade.Order.(*order.LimitOrder); ok && lo.Force == order.StandingTiF {
+			if lo, ok := trade.Order.(*order.LimitOrder); ok && lo.Force.Standing() {
 				reconciledOrdersCount++
 				dc.updateOrderStatus(trade, serverStatus)
 			} else {
@@ -1465,6 +1466,16 @@ type Config struct {
 	ExtensionModeFile string
 
 	TheOneHost string
+
+	// WatchtowerURL is the address of a swap watchtower. If set, the signed
+	// refund transactions of new swap contracts, and the pre-signed
+	// redemptions of audited counterparty contracts where the wallet supports
+	// it, are submitted to the watchtower so that swaps can be completed
+	// while the client is offline.
+	// See the client/watchtower package.
+	WatchtowerURL string
+	// WatchtowerKey is the API key for the watchtower, if required.
+	WatchtowerKey string
 }
 
 // locale is data associated with the currently selected language.
@@ -1490,6 +1501,11 @@ type Core struct {
 	intl          atomic.Value // *locale
 
 	extensionModeConfig *ExtensionModeConfig
+	watchtower          towerClient
+
+	towerMtx  sync.Mutex
+	towerJobs map[string]*watchtower.Job // awaiting submission
+	towerKick chan struct{}
 
 	// construction or init sets credentials
 	credMtx     sync.RWMutex
@@ -1538,6 +1554,17 @@ type Core struct {
 
 	requestedActionMtx sync.RWMutex
 	requestedActions   map[string]*asset.ActionRequiredNote
+
+	// condMtx guards the active conditional orders, keyed by the hex ID, and
+	// the book feeds used to monitor their mid-gap triggers.
+	condMtx    sync.RWMutex
+	condOrders map[string]*db.ConditionalOrder
+	condFeeds  map[string]*conditionalBookFeed
+
+	// execMtx guards the active and paused execution orders, keyed by the
+	// hex ID. It is not held while child orders are placed or canceled.
+	execMtx    sync.Mutex
+	execOrders map[string]*db.ExecutionOrder
 }
 
 // New is the constructor for a new Core.
@@ -1645,6 +1672,11 @@ func New(cfg *Config) (*Core, error) {
 		}
 	}
 
+	var tower towerClient
+	if cfg.WatchtowerURL != "" {
+		tower = watchtower.NewClient(cfg.WatchtowerURL, cfg.WatchtowerKey)
+	}
+
 	c := &Core{
 		cfg:           cfg,
 		credentials:   creds,
@@ -1669,6 +1701,9 @@ func New(cfg *Config) (*Core, error) {
 
 		extensionModeConfig: xCfg,
 		seedGenerationTime:  seedGenerationTime,
+		watchtower:          tower,
+		towerJobs:           make(map[string]*watchtower.Job),
+		towerKick:           make(chan struct{}, 1),
 
 		fiatRateSources: make(map[string]*commonRateSource),
 		reFiat:          make(chan struct{}, 1),
@@ -1676,6 +1711,9 @@ func New(cfg *Config) (*Core, error) {
 
 		notes:            make(chan asset.WalletNotification, 128),
 		requestedActions: make(map[string]*asset.ActionRequiredNote),
+		condOrders:       make(map[string]*db.ConditionalOrder),
+		condFeeds:        make(map[string]*conditionalBookFeed),
+		execOrders:       make(map[string]*db.ExecutionOrder),
 	}
 
 	c.intl.Store(&locale{
@@ -1762,6 +1800,29 @@ fetchers:
 		c.watchBonds(ctx)
 	}()
 
+	// Start conditional order supervisor.
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.watchConditionalOrders(ctx)
+	}()
+
+	// Start execution order supervisor.
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.watchExecutionOrders(ctx)
+	}()
+
+	// Start watchtower job submitter.
+	if c.watchtower != nil {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.watchWatchtowerJobs(ctx)
+		}()
+	}
+
 	// Handle wallet notifications.
 	c.wg.Add(1)
 	go func() {
@@ -5276,6 +5337,10 @@ func (c *Core) resolveActiveTrades(crypter encrypt.Crypter) {
 	// resumeTrades will be a no-op if there are no trades in any
 	// dexConnection's trades map that is not ready to tick.
 	c.resumeTrades(crypter)
+
+	// Resubmit the refunds and redemptions that the watchtower had not
+	// accepted before shutdown.
+	c.loadWatchtowerJobs()
 }
 
 func (c *Core) wait(coinID []byte, assetID uint32, trigger func() (bool, error), action func(error)) {
@@ -5892,7 +5957,7 @@ func (c *Core) PreOrder(form *TradeForm) (*OrderEstimate, error) {
 		LotSize:         swapLotSize,
 		Lots:            lots,
 		MaxFeeRate:      assetConfigs.fromAsset.MaxFeeRate,
-		Immediate:       (form.IsLimit && form.TifNow) || !form.IsLimit,
+		Immediate:       !form.IsLimit || form.TifNow || form.FillOrKill,
 		FeeSuggestion:   swapFeeSuggestion,
 		SelectedOptions: form.Options,
 		RedeemVersion:   assetConfigs.toAsset.Version,
@@ -6113,6 +6178,15 @@ func (c *Core) prepareForTradeRequestPrep(pw []byte, base, quote uint32, host st
 
 	fromWallet, toWallet := wallets.fromWallet, wallets.toWallet
 
+	if mktConf.PrivateSwaps {
+		for _, w := range []*xcWallet{fromWallet, toWallet} {
+			if _, ok := w.Wallet.(asset.PrivateSwapper); !ok {
+				return fail(newError(walletErr, "%s wallet does not support the private swaps required by market %s",
+					unbip(w.AssetID), mktID))
+			}
+		}
+	}
+
 	prepareWallet := func(w *xcWallet) error {
 		// NOTE: If the wallet is already internally unlocked (the decrypted
 		// password cached in xcWallet.pw), this could be done without the
@@ -6181,9 +6255,9 @@ func (c *Core) createTradeRequest(wallets *walletSet, coins asset.Coins, redeemS
 	var ord order.Order
 	if form.IsLimit {
 		prefix.OrderType = order.LimitOrderType
-		tif := order.StandingTiF
-		if form.TifNow {
-			tif = order.ImmediateTiF
+		tif, err := form.timeInForce() // already validated by the caller
+		if err != nil {
+			return nil, newError(orderParamsErr, "%v", err)
 		}
 		ord = &order.LimitOrder{
 			P: *prefix,
@@ -6348,8 +6422,36 @@ func (c *Core) createTradeRequest(wallets *walletSet, coins asset.Coins, redeemS
 	}, nil
 }
 
+// timeInForce is the time in force of a limit order placed with the form. An
+// error is returned if more than one time in force is selected, or if
+// fill-or-kill or post-only is selected for a market order.
+func (form *TradeForm) timeInForce() (order.TimeInForce, error) {
+	tif, n := order.StandingTiF, 0
+	if form.TifNow {
+		tif, n = order.ImmediateTiF, n+1
+	}
+	if form.FillOrKill {
+		tif, n = order.FillOrKillTiF, n+1
+	}
+	if form.PostOnly {
+		tif, n = order.PostOnlyTiF, n+1
+	}
+	if n > 1 {
+		return 0, errors.New("only one of immediate, fill-or-kill, and post-only may be selected")
+	}
+	if !form.IsLimit && (form.FillOrKill || form.PostOnly) {
+		return 0, fmt.Errorf("%s is only valid for limit orders", tif)
+	}
+	return tif, nil
+}
+
 // prepareTradeRequest prepares a trade request.
 func (c *Core) prepareTradeRequest(pw []byte, form *TradeForm) (*tradeRequest, error) {
+	tif, err := form.timeInForce()
+	if err != nil {
+		return nil, newError(orderParamsErr, "%v", err)
+	}
+
 	wallets, assetConfigs, dc, mktConf, err := c.prepareForTradeRequestPrep(pw, form.Base, form.Quote, form.Host, form.Sell)
 	if err != nil {
 		return nil, err
@@ -6384,7 +6486,7 @@ func (c *Core) prepareTradeRequest(pw []byte, form *TradeForm) (*tradeRequest, e
 	}
 	redemptionRefundLots := lots
 
-	isImmediate := (!form.IsLimit || form.TifNow)
+	isImmediate := !form.IsLimit || !tif.Standing()
 
 	// Market buy order
 	if !form.IsLimit && !form.Sell {
@@ -7264,7 +7366,7 @@ func (c *Core) authDEX(dc *dexConnection) error {
 	var brokenTrades []*trackedTrade
 	dc.tradeMtx.RLock()
 	for _, trade := range dc.trades {
-		if lo, ok := trade.Order.(*order.LimitOrder); !ok || lo.Force != order.StandingTiF {
+		if lo, ok := trade.Order.(*order.LimitOrder); !ok || !lo.Force.Standing() {
 			continue // only standing limit orders need to be canceled
 		}
 		trade.mtx.RLock()
@@ -7807,6 +7909,11 @@ func (c *Core) resumeTrade(tracker *trackedTrade, crypter encrypt.Crypter, faile
 			}
 			c.log.Tracef("Trade %v match %v needs coins = %v, needs audit info = %v",
 				tracker.ID(), match.MatchID, len(matchesNeedingCoins) > 0, needsAuditInfo)
+			if match.MetaData.Proof.Private != nil {
+				// Private swaps have no HTLC audit info. The counterparty's
+				// swap was audited with the keys in the PrivateSwapProof.
+				continue
+			}
 			if needsAuditInfo {
 				// Check for unresolvable states.
 				if len(counterSwap) == 0 {
@@ -8944,6 +9051,11 @@ var reqHandlers = map[string]routeHandler{
 	msgjson.MatchRoute:      handleMatchRoute,
 	msgjson.AuditRoute:      handleAuditRoute,
 	msgjson.RedemptionRoute: handleRedemptionRoute, // TODO: to ntfn
+
+	msgjson.CounterpartyKeysRoute:        handleCounterpartyKeysRoute,
+	msgjson.PrivateAuditRoute:            handlePrivateAuditRoute,
+	msgjson.CounterpartyAdaptorSigsRoute: handleCounterpartyAdaptorSigsRoute,
+	msgjson.PrivateRedemptionRoute:       handlePrivateRedemptionRoute,
 }
 
 var noteHandlers = map[string]routeHandler{
@@ -8964,6 +9076,7 @@ var noteHandlers = map[string]routeHandler{
 	msgjson.TierChangeRoute:      handleTierChangeMsg,
 	msgjson.ScoreChangeRoute:     handleScoreChangeMsg,
 	msgjson.BondExpiredRoute:     handleBondExpiredMsg,
+	msgjson.ConfigChangeRoute:    handleConfigChangeMsg,
 }
 
 // listen monitors the DEX websocket connection for server requests and
@@ -9365,7 +9478,7 @@ func handleNoMatchRoute(c *Core, dc *dexConnection, msg *msgjson.Message) error
 		return newError(unknownOrderErr, "nomatch request received for unknown order %v from %s", oid, dc.acct.host)
 	}
 
-	updatedAssets, err := tracker.nomatch(oid)
+	updatedAssets, err := tracker.nomatch(oid, nomatchMsg.Rejected)
 	if len(updatedAssets) > 0 {
 		c.updateBalances(updatedAssets)
 	}
@@ -9890,9 +10003,16 @@ func messageOrder(ord order.Order, coins []*msgjson.Coin) (string, msgjson.Stamp
 	prefix, trade := ord.Prefix(), ord.Trade()
 	switch o := ord.(type) {
 	case *order.LimitOrder:
-		tifFlag := uint8(msgjson.StandingOrderNum)
-		if o.Force == order.ImmediateTiF {
+		var tifFlag uint8
+		switch o.Force {
+		case order.ImmediateTiF:
 			tifFlag = msgjson.ImmediateOrderNum
+		case order.FillOrKillTiF:
+			tifFlag = msgjson.FillOrKillOrderNum
+		case order.PostOnlyTiF:
+			tifFlag = msgjson.PostOnlyOrderNum
+		default:
+			tifFlag = msgjson.StandingOrderNum
 		}
 		msgOrd := &msgjson.LimitOrder{
 			Prefix: *messagePrefix(prefix),
@@ -10633,14 +10753,7 @@ func (c *Core) fiatConversions() map[uint32]float64 {
 		var rateSum float64
 		var sources int
 		for _, source := range c.fiatSources() {
-			// Adjust a couple custom bip ids to get the eth equivalent.
-			getAssetID := assetID
-			switch assetID {
-			case 8453:
-				// base -> eth
-				getAssetID = 60
-			}
-			rateInfo := source.assetRate(getAssetID)
+			rateInfo := source.assetRate(fiatRateAssetID(assetID))
 			if rateInfo != nil && time.Since(rateInfo.lastUpdate) < fiatRateDataExpiry && rateInfo.rate > 0 {
 				sources++
 				rateSum += rateInfo.rate
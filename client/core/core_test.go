@@ -26,6 +26,7 @@ import (
 	"decred.org/dcrdex/client/comms"
 	"decred.org/dcrdex/client/db"
 	dbtest "decred.org/dcrdex/client/db/test"
+	"decred.org/dcrdex/client/watchtower"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/calc"
 	"decred.org/dcrdex/dex/encode"
@@ -36,6 +37,7 @@ import (
 	"decred.org/dcrdex/dex/wait"
 	"decred.org/dcrdex/server/account"
 	serverdex "decred.org/dcrdex/server/dex"
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/decred/dcrd/crypto/blake256"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"golang.org/x/text/language"
@@ -351,42 +353,48 @@ func (conn *TWebsocket) Connect(context.Context) (*sync.WaitGroup, error) {
 func (conn *TWebsocket) UpdateURL(string) {}
 
 type TDB struct {
-	updateWalletErr          error
-	acct                     *db.AccountInfo
-	acctErr                  error
-	createAccountErr         error
-	addBondErr               error
-	updateOrderErr           error
-	activeDEXOrders          []*db.MetaOrder
-	matchesForOID            []*db.MetaMatch
-	matchesForOIDErr         error
-	updateMatchChan          chan order.MatchStatus
-	activeMatchOIDs          []order.OrderID
-	activeMatchOIDSErr       error
-	lastStatusID             order.OrderID
-	lastStatus               order.OrderStatus
-	wallet                   *db.Wallet
-	walletErr                error
-	setWalletPwErr           error
-	orderOrders              map[order.OrderID]*db.MetaOrder
-	orderErr                 error
-	linkedFromID             order.OrderID
-	linkedToID               order.OrderID
-	existValues              map[string]bool
-	accountProofErr          error
-	verifyCreateAccount      bool
-	verifyUpdateAccountInfo  bool
-	disabledHost             *string
-	disableAccountErr        error
-	creds                    *db.PrimaryCredentials
-	setCredsErr              error
-	legacyKeyErr             error
-	recryptErr               error
-	deleteInactiveOrdersErr  error
-	archivedOrders           int
-	deleteInactiveMatchesErr error
-	archivedMatches          int
-	updateAccountInfoErr     error
+	updateWalletErr           error
+	acct                      *db.AccountInfo
+	acctErr                   error
+	createAccountErr          error
+	addBondErr                error
+	updateOrderErr            error
+	activeDEXOrders           []*db.MetaOrder
+	matchesForOID             []*db.MetaMatch
+	matchesForOIDErr          error
+	updateMatchChan           chan order.MatchStatus
+	activeMatchOIDs           []order.OrderID
+	activeMatchOIDSErr        error
+	lastStatusID              order.OrderID
+	lastStatus                order.OrderStatus
+	wallet                    *db.Wallet
+	walletErr                 error
+	setWalletPwErr            error
+	orderOrders               map[order.OrderID]*db.MetaOrder
+	orderErr                  error
+	linkedFromID              order.OrderID
+	linkedToID                order.OrderID
+	existValues               map[string]bool
+	accountProofErr           error
+	verifyCreateAccount       bool
+	verifyUpdateAccountInfo   bool
+	disabledHost              *string
+	disableAccountErr         error
+	creds                     *db.PrimaryCredentials
+	setCredsErr               error
+	legacyKeyErr              error
+	recryptErr                error
+	deleteInactiveOrdersErr   error
+	archivedOrders            int
+	deleteInactiveMatchesErr  error
+	archivedMatches           int
+	updateAccountInfoErr      error
+	conditionalOrders         map[string]*db.ConditionalOrder
+	updateConditionalOrderErr error
+	executionOrders           map[string]*db.ExecutionOrder
+	historicalRates           map[string]map[uint64]float64
+	towerJobsMtx              sync.Mutex
+	towerJobs                 map[string][]byte
 }
 
 func (tdb *TDB) Run(context.Context) {}
@@ -590,6 +598,98 @@ func (tdb *TDB) Language() (string, error) {
 	return "en-US", nil
 }
 
+func (tdb *TDB) UpdateConditionalOrder(o *db.ConditionalOrder) error {
+	if tdb.updateConditionalOrderErr != nil {
+		return tdb.updateConditionalOrderErr
+	}
+	if tdb.conditionalOrders == nil {
+		tdb.conditionalOrders = make(map[string]*db.ConditionalOrder)
+	}
+	oCopy := *o
+	tdb.conditionalOrders[dex.Bytes(o.ID).String()] = &oCopy
+	return nil
+}
+
+func (tdb *TDB) ConditionalOrders(activeOnly bool) ([]*db.ConditionalOrder, error) {
+	ords := make([]*db.ConditionalOrder, 0, len(tdb.conditionalOrders))
+	for _, o := range tdb.conditionalOrders {
+		if !activeOnly || o.Status == db.ConditionalOrderActive {
+			ords = append(ords, o)
+		}
+	}
+	return ords, nil
+}
+
+func (tdb *TDB) UpdateExecutionOrder(o *db.ExecutionOrder) error {
+	if tdb.executionOrders == nil {
+		tdb.executionOrders = make(map[string]*db.ExecutionOrder)
+	}
+	oCopy := *o
+	tdb.executionOrders[dex.Bytes(o.ID).String()] = &oCopy
+	return nil
+}
+
+func (tdb *TDB) ExecutionOrders(activeOnly bool) ([]*db.ExecutionOrder, error) {
+	ords := make([]*db.ExecutionOrder, 0, len(tdb.executionOrders))
+	for _, o := range tdb.executionOrders {
+		if !activeOnly || o.Status == db.ExecutionActive || o.Status == db.ExecutionPaused {
+			ords = append(ords, o)
+		}
+	}
+	return ords, nil
+}
+
+func (tdb *TDB) StoreHistoricalRates(source string, assetID uint32, rates map[uint64]float64) error {
+	if tdb.historicalRates == nil {
+		tdb.historicalRates = make(map[string]map[uint64]float64)
+	}
+	k := fmt.Sprintf("%s:%d", source, assetID)
+	if tdb.historicalRates[k] == nil {
+		tdb.historicalRates[k] = make(map[uint64]float64)
+	}
+	for day, rate := range rates {
+		tdb.historicalRates[k][day] = rate
+	}
+	return nil
+}
+
+func (tdb *TDB) HistoricalRates(source string, assetID uint32, fromDay, toDay uint64) (map[uint64]float64, error) {
+	rates := make(map[uint64]float64)
+	for day, rate := range tdb.historicalRates[fmt.Sprintf("%s:%d", source, assetID)] {
+		if day >= fromDay && day <= toDay {
+			rates[day] = rate
+		}
+	}
+	return rates, nil
+}
+
+func (tdb *TDB) StoreWatchtowerJob(id string, job []byte) error {
+	tdb.towerJobsMtx.Lock()
+	defer tdb.towerJobsMtx.Unlock()
+	if tdb.towerJobs == nil {
+		tdb.towerJobs = make(map[string][]byte)
+	}
+	tdb.towerJobs[id] = job
+	return nil
+}
+
+func (tdb *TDB) DeleteWatchtowerJob(id string) error {
+	tdb.towerJobsMtx.Lock()
+	defer tdb.towerJobsMtx.Unlock()
+	delete(tdb.towerJobs, id)
+	return nil
+}
+
+func (tdb *TDB) WatchtowerJobs() ([][]byte, error) {
+	tdb.towerJobsMtx.Lock()
+	defer tdb.towerJobsMtx.Unlock()
+	jobs := make([][]byte, 0, len(tdb.towerJobs))
+	for _, job := range tdb.towerJobs {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
 type tCoin struct {
 	id []byte
 
@@ -613,9 +713,10 @@ func (c *tCoin) Value() uint64 {
 }
 
 type tReceipt struct {
-	coin       *tCoin
-	contract   []byte
-	expiration time.Time
+	coin         *tCoin
+	contract     []byte
+	expiration   time.Time
+	signedRefund []byte
 }
 
 func (r *tReceipt) Coin() asset.Coin {
@@ -635,7 +736,7 @@ func (r *tReceipt) String() string {
 }
 
 func (r *tReceipt) SignedRefund() dex.Bytes {
-	return nil
+	return r.signedRefund
 }
 
 type TXCWallet struct {
@@ -1361,6 +1462,11 @@ func newTestRig() *testRig {
 			notes:            make(chan asset.WalletNotification, 128),
 			pokesCache:       newPokesCache(pokesCapacity),
 			requestedActions: make(map[string]*asset.ActionRequiredNote),
+			condOrders:       make(map[string]*db.ConditionalOrder),
+			execOrders:       make(map[string]*db.ExecutionOrder),
+			condFeeds:        make(map[string]*conditionalBookFeed),
+			towerJobs:        make(map[string]*watchtower.Job),
+			towerKick:        make(chan struct{}, 1),
 		},
 		db:      tdb,
 		queue:   queue,
@@ -3067,6 +3173,16 @@ func trade(t *testing.T, async bool) {
 	ensureErr("zero rate limit")
 	form.Rate = rate
 
+	// More than one time in force
+	form.TifNow, form.PostOnly = true, true
+	ensureErr("immediate and post-only")
+	form.TifNow, form.PostOnly = false, false
+
+	// Fill-or-kill market order
+	form.IsLimit, form.FillOrKill = false, true
+	ensureErr("fill-or-kill market order")
+	form.IsLimit, form.FillOrKill = true, false
+
 	// No from wallet
 	tCore.walletMtx.Lock()
 	delete(tCore.wallets, tUTXOAssetA.ID)
@@ -3435,7 +3551,7 @@ func TestRefundReserves(t *testing.T) {
 	dc.trades = map[order.OrderID]*trackedTrade{moid: tracker}
 
 	test("nomatch", reserves, func() {
-		tracker.nomatch(moid)
+		tracker.nomatch(moid, false)
 	})
 
 	test("partial market sell match", reserves/3, func() {
@@ -3642,7 +3758,7 @@ func TestRedemptionReserves(t *testing.T) {
 	dc.trades = map[order.OrderID]*trackedTrade{moid: tracker}
 
 	test("nomatch", reserves, func() {
-		tracker.nomatch(moid)
+		tracker.nomatch(moid, false)
 	})
 
 	test("partial market sell match", reserves/3, func() {
@@ -5590,6 +5706,327 @@ func TestRefunds(t *testing.T) {
 	tEthWallet.contractLockTime = time.Now().Add(time.Minute)
 }
 
+// tPrivateSwapper is a TXCWallet with canned asset.PrivateSwapper results.
+type tPrivateSwapper struct {
+	*TXCWallet
+	pubKey         []byte
+	swapCoin       *tCoin
+	swapTx         []byte
+	auditErr       error
+	privConfs      uint32
+	unsignedRedeem []byte
+	adaptorSig     []byte
+	validSig       bool
+	recoveredKey   *btcec.ModNScalar
+	redeemCoin     *tCoin
+	redeemSecret   *btcec.ModNScalar
+	refundCoin     dex.Bytes
+	refundErr      error
+	completed      int
+}
+
+var _ asset.PrivateSwapper = (*tPrivateSwapper)(nil)
+
+func newTPrivateSwapper(assetID uint32) (*xcWallet, *tPrivateSwapper) {
+	xcWallet, tWallet := newTWallet(assetID)
+	w := &tPrivateSwapper{
+		TXCWallet:      tWallet,
+		pubKey:         encode.RandomBytes(33),
+		swapCoin:       &tCoin{id: encode.RandomBytes(36)},
+		swapTx:         encode.RandomBytes(100),
+		unsignedRedeem: encode.RandomBytes(100),
+		adaptorSig:     encode.RandomBytes(64),
+		validSig:       true,
+		redeemCoin:     &tCoin{id: encode.RandomBytes(36)},
+		refundCoin:     encode.RandomBytes(36),
+	}
+	xcWallet.Wallet = w
+	return xcWallet, w
+}
+
+func (w *tPrivateSwapper) PrivateSwapPubKey() ([]byte, error) {
+	return w.pubKey, nil
+}
+
+func (w *tPrivateSwapper) SwapPrivate(swaps *asset.PrivateSwaps) ([]asset.Receipt, asset.Coin, []byte, uint64, error) {
+	if w.swapErr != nil {
+		return nil, nil, nil, 0, w.swapErr
+	}
+	return []asset.Receipt{&tReceipt{coin: w.swapCoin}}, nil, w.swapTx, 1, nil
+}
+
+func (w *tPrivateSwapper) AuditPrivateContract(coinID, txData []byte, contract *asset.PrivateContract, rebroadcast bool) error {
+	return w.auditErr
+}
+
+func (w *tPrivateSwapper) PrivateSwapConfirmations(ctx context.Context, coinID dex.Bytes, contract *asset.PrivateContract, matchTime time.Time) (uint32, bool, error) {
+	return w.privConfs, false, nil
+}
+
+func (w *tPrivateSwapper) GenerateUnsignedRedeemTx(coinID []byte, contract *asset.PrivateContract, feeRate uint64) ([]byte, error) {
+	return w.unsignedRedeem, nil
+}
+
+func (w *tPrivateSwapper) ValidateAdaptorSecret(*btcec.ModNScalar, []byte, *asset.PrivateContract) (bool, error) {
+	return true, nil
+}
+
+func (w *tPrivateSwapper) GeneratePrivateKeyTweakedAdaptor([]byte, *asset.PrivateContract, *btcec.ModNScalar, bool) ([]byte, error) {
+	return w.adaptorSig, nil
+}
+
+func (w *tPrivateSwapper) ValidateAdaptorSig([]byte, []byte, *btcec.JacobianPoint, *asset.PrivateContract, bool) (bool, error) {
+	return w.validSig, nil
+}
+
+func (w *tPrivateSwapper) GeneratePublicKeyTweakedAdaptor([]byte, *asset.PrivateContract, *btcec.JacobianPoint) ([]byte, error) {
+	return w.adaptorSig, nil
+}
+
+func (w *tPrivateSwapper) RecoverAdaptorSecret([]byte, []byte, []byte, *btcec.JacobianPoint, *asset.PrivateContract) (*btcec.ModNScalar, error) {
+	return w.recoveredKey, nil
+}
+
+func (w *tPrivateSwapper) RedeemPrivate(contract *asset.PrivateContract, unsignedRedeemB, adaptorSigB []byte, secret *btcec.ModNScalar) (asset.Coin, uint64, []byte, error) {
+	w.redeemSecret = secret
+	return w.redeemCoin, 1, encode.RandomBytes(100), nil
+}
+
+func (w *tPrivateSwapper) RefundPrivate(coinID dex.Bytes, contract *asset.PrivateContract, feeRate uint64) (dex.Bytes, error) {
+	return w.refundCoin, w.refundErr
+}
+
+func (w *tPrivateSwapper) MarkPrivateSwapComplete(*asset.PrivateContract, bool) {
+	w.completed++
+}
+
+func TestPrivateSwap(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	rig.dc.cfg.Markets[0].PrivateSwaps = true
+	defer func() { rig.dc.cfg.Markets[0].PrivateSwaps = false }()
+
+	dcrWallet, tDcrWallet := newTPrivateSwapper(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.Unlock(rig.crypter)
+	btcWallet, tBtcWallet := newTPrivateSwapper(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.Unlock(rig.crypter)
+
+	// privateAcker acks any private swap request.
+	privateAcker := func(signable msgjson.Signable) func(*msgjson.Message, msgFunc) error {
+		return makeAcker(func(msg *msgjson.Message) msgjson.Signable {
+			msg.Unmarshal(signable)
+			return signable
+		})
+	}
+	waitFor := func(tag string, tracker *trackedTrade, f func() bool) {
+		t.Helper()
+		for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+			tracker.mtx.RLock()
+			ok := f()
+			tracker.mtx.RUnlock()
+			if ok {
+				return
+			}
+		}
+		t.Fatalf("%s: timed out", tag)
+	}
+	relay := func(route string, signable msgjson.Signable, handler routeHandler, tracker *trackedTrade) {
+		t.Helper()
+		sign(tDexPriv, signable)
+		msg, _ := msgjson.NewRequest(rig.ws.NextID(), route, signable)
+		if err := handler(tCore, rig.dc, msg); err != nil {
+			t.Fatalf("%s error: %v", route, err)
+		}
+	}
+
+	newMatch := func(side order.MatchSide) (*trackedTrade, *matchTracker) {
+		t.Helper()
+		matchSize := 4 * dcrBtcLotSize
+		lo, dbOrder, preImg, _ := makeLimitOrder(rig.dc, true, matchSize, dcrBtcRateStep)
+		oid, mid := lo.ID(), ordertest.RandomMatchID()
+		walletSet, _, _, err := tCore.walletSet(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+		if err != nil {
+			t.Fatalf("walletSet error: %v", err)
+		}
+		tracker := newTrackedTrade(dbOrder, preImg, rig.dc, tCore.lockTimeTaker, tCore.lockTimeMaker,
+			rig.db, rig.queue, walletSet, asset.Coins{&tCoin{id: encode.RandomBytes(36)}},
+			tCore.notify, tCore.formatDetails)
+		rig.dc.tradeMtx.Lock()
+		rig.dc.trades[oid] = tracker
+		rig.dc.tradeMtx.Unlock()
+		msgMatch := &msgjson.Match{
+			OrderID:      oid[:],
+			MatchID:      mid[:],
+			Quantity:     matchSize,
+			Rate:         dcrBtcRateStep,
+			Address:      "counterparty-address",
+			Side:         uint8(side),
+			ServerTime:   uint64(time.Now().UnixMilli()),
+			FeeRateBase:  tMaxFeeRate,
+			FeeRateQuote: tMaxFeeRate,
+		}
+		sign(tDexPriv, msgMatch)
+		rig.ws.queueResponse(msgjson.PrivateKeysRoute, privateAcker(new(msgjson.PrivateKeys)))
+		msg, _ := msgjson.NewRequest(rig.ws.NextID(), msgjson.MatchRoute, []*msgjson.Match{msgMatch})
+		if err := handleMatchRoute(tCore, rig.dc, msg); err != nil {
+			t.Fatalf("match messages error: %v", err)
+		}
+		match := tracker.matches[mid]
+		if match == nil || match.MetaData.Proof.Private == nil {
+			t.Fatalf("private match not found")
+		}
+		waitFor("keys sent", tracker, func() bool { return match.privateKeysSent })
+		p := match.MetaData.Proof.Private
+		if !bytes.Equal(p.RedeemKey, tBtcWallet.pubKey) || !bytes.Equal(p.RefundKey, tDcrWallet.pubKey) {
+			t.Fatalf("wrong keys")
+		}
+		return tracker, match
+	}
+
+	sendKeys := func(tracker *trackedTrade, match *matchTracker) {
+		t.Helper()
+		relay(msgjson.CounterpartyKeysRoute, &msgjson.PrivateKeys{
+			OrderID:   tracker.ID().Bytes(),
+			MatchID:   match.MatchID[:],
+			RedeemKey: encode.RandomBytes(33),
+			RefundKey: encode.RandomBytes(33),
+		}, handleCounterpartyKeysRoute, tracker)
+	}
+
+	// The maker swaps once the keys are exchanged.
+	tBtcWallet.privConfs = tUTXOAssetB.SwapConf
+	rig.ws.queueResponse(msgjson.PrivateInitRoute, privateAcker(new(msgjson.PrivateInit)))
+	tracker, match := newMatch(order.Maker)
+	sendKeys(tracker, match)
+	proof := &match.MetaData.Proof
+	p := proof.Private
+	waitFor("maker swap", tracker, func() bool { return len(proof.Auth.InitSig) > 0 })
+	if match.Status != order.MakerSwapCast || !bytes.Equal(proof.MakerSwap, tDcrWallet.swapCoin.id) {
+		t.Fatalf("maker swap not recorded")
+	}
+
+	// The taker's audit is missing their unsigned redeem.
+	audit := &msgjson.PrivateAudit{
+		PrivateInit: msgjson.PrivateInit{
+			OrderID: tracker.ID().Bytes(),
+			MatchID: match.MatchID[:],
+			CoinID:  encode.RandomBytes(36),
+			TxData:  encode.RandomBytes(100),
+		},
+		Time: uint64(time.Now().UnixMilli()),
+	}
+	sign(tDexPriv, audit)
+	msg, _ := msgjson.NewRequest(rig.ws.NextID(), msgjson.PrivateAuditRoute, audit)
+	if err := handlePrivateAuditRoute(tCore, rig.dc, msg); err == nil {
+		t.Fatalf("no error for audit without unsigned redeem")
+	}
+
+	// After the audit, the maker sends their adaptor signatures.
+	rig.ws.queueResponse(msgjson.AdaptorSigsRoute, privateAcker(new(msgjson.AdaptorSigs)))
+	audit.UnsignedRedeem = encode.RandomBytes(100)
+	relay(msgjson.PrivateAuditRoute, audit, handlePrivateAuditRoute, tracker)
+	waitFor("maker sigs", tracker, func() bool { return match.privateSigsSent })
+	if match.Status != order.TakerSwapCast || !bytes.Equal(p.CounterUnsignedRedeem, audit.UnsignedRedeem) {
+		t.Fatalf("taker swap not recorded")
+	}
+	if len(p.AdaptorPub) != 33 || len(p.AdaptorSecret) != 32 || len(p.MakerRefundSig) == 0 {
+		t.Fatalf("maker adaptor signatures not generated")
+	}
+
+	// The taker's adaptor signature allows the maker to redeem.
+	rig.ws.queueResponse(msgjson.PrivateRedeemRoute, privateAcker(new(msgjson.PrivateRedeem)))
+	relay(msgjson.CounterpartyAdaptorSigsRoute, &msgjson.AdaptorSigs{
+		OrderID:   tracker.ID().Bytes(),
+		MatchID:   match.MatchID[:],
+		RefundSig: encode.RandomBytes(64),
+	}, handleCounterpartyAdaptorSigsRoute, tracker)
+	waitFor("maker redeem", tracker, func() bool { return match.Status == order.MatchConfirmed })
+	if !bytes.Equal(proof.MakerRedeem, tBtcWallet.redeemCoin.id) {
+		t.Fatalf("maker redeem not recorded")
+	}
+	if secretB := tBtcWallet.redeemSecret.Bytes(); !bytes.Equal(secretB[:], p.AdaptorSecret) {
+		t.Fatalf("wrong adaptor secret used to redeem")
+	}
+
+	// The taker swaps after the maker's swap is confirmed.
+	rig.ws.queueResponse(msgjson.PrivateInitRoute, privateAcker(new(msgjson.PrivateInit)))
+	tracker, match = newMatch(order.Taker)
+	sendKeys(tracker, match)
+	proof = &match.MetaData.Proof
+	p = proof.Private
+	audit.OrderID, audit.MatchID, audit.UnsignedRedeem = tracker.ID().Bytes(), match.MatchID[:], nil
+	relay(msgjson.PrivateAuditRoute, audit, handlePrivateAuditRoute, tracker)
+	waitFor("taker swap", tracker, func() bool { return len(proof.Auth.InitSig) > 0 })
+	if match.Status != order.TakerSwapCast || !bytes.Equal(p.UnsignedRedeem, tBtcWallet.unsignedRedeem) {
+		t.Fatalf("taker swap not recorded")
+	}
+
+	// Invalid maker adaptor signatures are rejected.
+	var secret btcec.ModNScalar
+	secret.SetInt(7)
+	var pt btcec.JacobianPoint
+	btcec.ScalarBaseMultNonConst(&secret, &pt)
+	pt.ToAffine()
+	sigs := &msgjson.AdaptorSigs{
+		OrderID:        tracker.ID().Bytes(),
+		MatchID:        match.MatchID[:],
+		AdaptorPub:     btcec.NewPublicKey(&pt.X, &pt.Y).SerializeCompressed(),
+		RedeemSig:      encode.RandomBytes(64),
+		RefundSig:      encode.RandomBytes(64),
+		UnsignedRedeem: encode.RandomBytes(100),
+	}
+	sign(tDexPriv, sigs)
+	tBtcWallet.validSig = false
+	msg, _ = msgjson.NewRequest(rig.ws.NextID(), msgjson.CounterpartyAdaptorSigsRoute, sigs)
+	if err := handleCounterpartyAdaptorSigsRoute(tCore, rig.dc, msg); err == nil {
+		t.Fatalf("no error for invalid adaptor signature")
+	}
+	tBtcWallet.validSig = true
+
+	// The taker responds to valid signatures.
+	rig.ws.queueResponse(msgjson.AdaptorSigsRoute, privateAcker(new(msgjson.AdaptorSigs)))
+	relay(msgjson.CounterpartyAdaptorSigsRoute, sigs, handleCounterpartyAdaptorSigsRoute, tracker)
+	waitFor("taker sigs", tracker, func() bool { return match.privateSigsSent })
+	if !bytes.Equal(p.TakerRefundSig, tDcrWallet.adaptorSig) {
+		t.Fatalf("taker adaptor signature not recorded")
+	}
+
+	// The taker redeems with the secret recovered from the maker's redeem.
+	tDcrWallet.recoveredKey = &secret
+	rig.ws.queueResponse(msgjson.PrivateRedeemRoute, privateAcker(new(msgjson.PrivateRedeem)))
+	relay(msgjson.PrivateRedemptionRoute, &msgjson.PrivateRedemption{
+		PrivateRedeem: msgjson.PrivateRedeem{
+			OrderID: tracker.ID().Bytes(),
+			MatchID: match.MatchID[:],
+			CoinID:  encode.RandomBytes(36),
+			TxData:  encode.RandomBytes(100),
+		},
+		Time: uint64(time.Now().UnixMilli()),
+	}, handlePrivateRedemptionRoute, tracker)
+	waitFor("taker redeem", tracker, func() bool { return match.Status == order.MatchConfirmed })
+	if tBtcWallet.redeemSecret != &secret || !bytes.Equal(proof.TakerRedeem, tBtcWallet.redeemCoin.id) {
+		t.Fatalf("taker redeem not recorded")
+	}
+
+	// A taker refunds after the lock time.
+	tracker, match = newMatch(order.Taker)
+	proof = &match.MetaData.Proof
+	tracker.mtx.Lock()
+	match.Status = order.TakerSwapCast
+	proof.TakerSwap = encode.RandomBytes(36)
+	proof.Auth.MatchStamp = uint64(time.Now().Add(-tCore.lockTimeTaker).UnixMilli())
+	tracker.mtx.Unlock()
+	if _, err := tCore.tick(tracker); err != nil {
+		t.Fatalf("tick error: %v", err)
+	}
+	if !bytes.Equal(proof.RefundCoin, tDcrWallet.refundCoin) || !proof.IsRevoked() {
+		t.Fatalf("taker swap not refunded")
+	}
+}
+
 func TestNotifications(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
@@ -6236,8 +6673,13 @@ func TestCompareServerMatches(t *testing.T) {
 
 func convertMsgLimitOrder(msgOrder *msgjson.LimitOrder) *order.LimitOrder {
 	tif := order.ImmediateTiF
-	if msgOrder.TiF == msgjson.StandingOrderNum {
+	switch msgOrder.TiF {
+	case msgjson.StandingOrderNum:
 		tif = order.StandingTiF
+	case msgjson.FillOrKillOrderNum:
+		tif = order.FillOrKillTiF
+	case msgjson.PostOnlyOrderNum:
+		tif = order.PostOnlyTiF
 	}
 	return &order.LimitOrder{
 		P:     convertMsgPrefix(&msgOrder.Prefix, order.LimitOrderType),
@@ -7050,6 +7492,34 @@ func TestHandleTradeResumptionMsg(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected trade error %v", err)
 	}
+
+	// A resume with a config change refreshes the server config.
+	rig.ws.queueResponse(msgjson.ConfigRoute, func(msg *msgjson.Message, f msgFunc) error {
+		cfg := *rig.dc.cfg
+		mkts := make([]*msgjson.Market, 0, len(cfg.Markets))
+		for _, mkt := range cfg.Markets {
+			mkt := *mkt
+			if mkt.Name == tDcrBtcMktName {
+				mkt.LotSize *= 2
+			}
+			mkts = append(mkts, &mkt)
+		}
+		cfg.Markets = mkts
+		resp, _ := msgjson.NewResponse(msg.ID, cfg, nil)
+		f(resp)
+		return nil
+	})
+	payload = newPayload()
+	payload.ResumeTime = 0
+	payload.ConfigChange = true
+	req, _ = msgjson.NewRequest(rig.dc.NextID(), msgjson.ResumptionRoute, payload)
+	err = handleTradeResumptionMsg(rig.core, rig.dc, req)
+	if err != nil {
+		t.Fatalf("[handleTradeResumptionMsg] unexpected error: %v", err)
+	}
+	if lotSize := rig.dc.marketConfig(tDcrBtcMktName).LotSize; lotSize != dcrBtcLotSize*2 {
+		t.Fatalf("expected lot size %d after config change, got %d", dcrBtcLotSize*2, lotSize)
+	}
 }
 
 func TestHandleNomatch(t *testing.T) {
@@ -7112,18 +7582,32 @@ func TestHandleNomatch(t *testing.T) {
 		rig.db, rig.queue, walletSet, fundingCoins, rig.core.notify, rig.core.formatDetails)
 	dc.trades[marketOID] = marketTracker
 
-	runNomatch := func(tag string, oid order.OrderID) {
+	// 5. Post-only limit orders, one booked and one rejected.
+	newPostOnly := func() order.OrderID {
+		lo, dbOrder, preImgL, _ := makeLimitOrder(dc, true, dcrBtcLotSize*100, dcrBtcRateStep)
+		lo.Force = order.PostOnlyTiF
+		oid := lo.ID()
+		dc.trades[oid] = newTrackedTrade(dbOrder, preImgL, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+			rig.db, rig.queue, walletSet, fundingCoins, rig.core.notify, rig.core.formatDetails)
+		return oid
+	}
+	postOnlyBookedOID, postOnlyRejectedOID := newPostOnly(), newPostOnly()
+
+	runNomatchMsg := func(tag string, oid order.OrderID, rejected bool) {
 		tracker, _ := dc.findOrder(oid)
 		if tracker == nil {
 			t.Fatalf("%s: order ID not found", tag)
 		}
-		payload := &msgjson.NoMatch{OrderID: oid[:]}
+		payload := &msgjson.NoMatch{OrderID: oid[:], Rejected: rejected}
 		req, _ := msgjson.NewRequest(dc.NextID(), msgjson.NoMatchRoute, payload)
 		err := handleNoMatchRoute(tCore, dc, req)
 		if err != nil {
 			t.Fatalf("handleNoMatchRoute error: %v", err)
 		}
 	}
+	runNomatch := func(tag string, oid order.OrderID) {
+		runNomatchMsg(tag, oid, false)
+	}
 
 	checkTradeStatus := func(tag string, oid order.OrderID, expStatus order.OrderStatus) {
 		tracker, _ := dc.findOrder(oid)
@@ -7161,6 +7645,12 @@ func TestHandleNomatch(t *testing.T) {
 	runNomatch("market", marketOID)
 	checkTradeStatus("market", marketOID, order.OrderStatusExecuted)
 
+	runNomatch("post-only booked", postOnlyBookedOID)
+	checkTradeStatus("post-only booked", postOnlyBookedOID, order.OrderStatusBooked)
+
+	runNomatchMsg("post-only rejected", postOnlyRejectedOID, true)
+	checkTradeStatus("post-only rejected", postOnlyRejectedOID, order.OrderStatusExecuted)
+
 	// Unknown order should error.
 	oid := ordertest.RandomOrderID()
 	payload := &msgjson.NoMatch{OrderID: oid[:]}
@@ -9483,6 +9973,108 @@ func TestSuspectTrades(t *testing.T) {
 	}
 }
 
+func TestAwaitingSignatureSwap(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+	tCore := rig.core
+
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	walletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+
+	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, 0, 0)
+	oid := lo.ID()
+	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
+	dc.trades[oid] = tracker
+
+	// A maker match that was made long ago, so that a regular swap error
+	// would give up on it.
+	matchStamp := uint64(time.Now().Add(-time.Hour).UnixMilli())
+	match := &matchTracker{
+		prefix: lo.Prefix(),
+		trade:  lo.Trade(),
+		MetaMatch: db.MetaMatch{
+			MetaData: &db.MatchMetaData{
+				Proof: db.MatchProof{
+					Auth: db.MatchAuth{
+						MatchStamp: matchStamp,
+					},
+				},
+			},
+			UserMatch: &order.UserMatch{
+				MatchID:     ordertest.RandomMatchID(),
+				Side:        order.Maker,
+				Address:     ordertest.RandomAddress(),
+				Status:      order.NewlyMatched,
+				FeeRateSwap: tMaxFeeRate,
+			},
+		},
+	}
+	tracker.matches = map[order.MatchID]*matchTracker{match.MatchID: match}
+
+	feed := tCore.NotificationFeed()
+	defer feed.ReturnFeed()
+	countNotes := func() (n int) {
+		for {
+			select {
+			case note := <-feed.C:
+				if note.Topic() == TopicAwaitingSignature {
+					n++
+				}
+			default:
+				return
+			}
+		}
+	}
+
+	tDcrWallet.swapErr = fmt.Errorf("%w: sign it", asset.ErrAwaitingSignature)
+	for i := 0; i < 3; i++ {
+		if _, err := tCore.tick(tracker); err != nil {
+			t.Fatalf("tick %d error: %v", i, err)
+		}
+		tracker.mtx.Lock()
+		if match.suspectSwap || match.swapErrCount != 0 || match.swapErr != nil {
+			t.Fatalf("tick %d: match treated as failed while awaiting signature", i)
+		}
+		if !match.awaitingSignature {
+			t.Fatalf("tick %d: match not flagged as awaiting signature", i)
+		}
+		if match.tickGovernor == nil {
+			t.Fatalf("tick %d: no tick governor set", i)
+		}
+		match.tickGovernor = nil
+		tracker.mtx.Unlock()
+		expNotes := 0
+		if i == 0 {
+			expNotes = 1
+		}
+		if n := countNotes(); n != expNotes {
+			t.Fatalf("tick %d: expected %d awaiting signature notes, got %d", i, expNotes, n)
+		}
+	}
+	if tDcrWallet.swapCounter != 3 {
+		t.Fatalf("expected 3 swap attempts, got %d", tDcrWallet.swapCounter)
+	}
+
+	// Once signed, the swap goes out and the flag is cleared.
+	tDcrWallet.swapErr = nil
+	if _, err := tCore.tick(tracker); err != nil {
+		t.Fatalf("tick error after signing: %v", err)
+	}
+	tracker.mtx.Lock()
+	defer tracker.mtx.Unlock()
+	if match.awaitingSignature {
+		t.Fatalf("awaiting signature flag not cleared after swap")
+	}
+	if tDcrWallet.swapCounter != 4 {
+		t.Fatalf("swap not sent after signing")
+	}
+}
+
 func TestWalletSyncing(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
@@ -9827,6 +10419,42 @@ func TestRefreshServerConfig(t *testing.T) {
 	}
 }
 
+func TestHandleConfigChangeMsg(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+
+	cfg := *rig.dc.cfg
+	mkt := *cfg.Markets[0]
+	mkt.Base, mkt.Quote = mkt.Quote, mkt.Base
+	mkt.Name, _ = dex.MarketName(mkt.Base, mkt.Quote)
+	cfg.Markets = append([]*msgjson.Market{&mkt}, cfg.Markets...)
+
+	note, _ := msgjson.NewNotification(msgjson.ConfigChangeRoute, &cfg)
+	if err := handleConfigChangeMsg(rig.core, rig.dc, note); err != nil {
+		t.Fatalf("handleConfigChangeMsg error: %v", err)
+	}
+	if rig.dc.marketConfig(mkt.Name) == nil {
+		t.Fatalf("added market %s not found", mkt.Name)
+	}
+
+	// The market is retired.
+	cfg.Markets = cfg.Markets[1:]
+	note, _ = msgjson.NewNotification(msgjson.ConfigChangeRoute, &cfg)
+	if err := handleConfigChangeMsg(rig.core, rig.dc, note); err != nil {
+		t.Fatalf("handleConfigChangeMsg error: %v", err)
+	}
+	if rig.dc.marketConfig(mkt.Name) != nil {
+		t.Fatalf("retired market %s still found", mkt.Name)
+	}
+
+	// Unsupported API version.
+	cfg.APIVersion = ^uint16(0)
+	note, _ = msgjson.NewNotification(msgjson.ConfigChangeRoute, &cfg)
+	if err := handleConfigChangeMsg(rig.core, rig.dc, note); err == nil {
+		t.Fatalf("no error for unsupported API version")
+	}
+}
+
 func TestCredentialHandling(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
@@ -11291,3 +11919,747 @@ func TestTakeAction(t *testing.T) {
 	}
 
 }
+
+func TestConditionMet(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     db.ConditionalOrderType
+		sell    bool
+		price   float64
+		trigger float64
+		met     bool
+	}{
+		{"stop-loss sell above", db.StopLoss, true, 101, 100, false},
+		{"stop-loss sell at", db.StopLoss, true, 100, 100, true},
+		{"stop-loss sell below", db.StopLoss, true, 99, 100, true},
+		{"stop-loss buy below", db.StopLoss, false, 99, 100, false},
+		{"stop-loss buy above", db.StopLoss, false, 101, 100, true},
+		{"take-profit sell below", db.TakeProfit, true, 99, 100, false},
+		{"take-profit sell above", db.TakeProfit, true, 101, 100, true},
+		{"take-profit buy above", db.TakeProfit, false, 101, 100, false},
+		{"take-profit buy below", db.TakeProfit, false, 99, 100, true},
+	}
+	for _, tt := range tests {
+		for _, trigger := range []db.ConditionalTrigger{db.TriggerMidGap, db.TriggerFiatRate} {
+			o := &db.ConditionalOrder{
+				Type:            tt.typ,
+				Sell:            tt.sell,
+				Trigger:         trigger,
+				TriggerRate:     uint64(tt.trigger),
+				TriggerFiatRate: tt.trigger,
+			}
+			if met := conditionMet(o, tt.price); met != tt.met {
+				t.Fatalf("%s, %s trigger: expected met = %t, got %t", tt.name, trigger, tt.met, met)
+			}
+		}
+	}
+}
+
+func TestPlaceConditionalOrder(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	for token := range fiatRateFetchers {
+		tCore.fiatRateSources[token] = newCommonRateSource(tFetcher)
+	}
+	tCore.refreshFiatRates(tCtx)
+
+	newForm := func() *ConditionalOrderForm {
+		return &ConditionalOrderForm{
+			Trade: &TradeForm{
+				Host:    tDexHost,
+				IsLimit: true,
+				Sell:    true,
+				Base:    tUTXOAssetA.ID,
+				Quote:   tUTXOAssetB.ID,
+				Qty:     dcrBtcLotSize * 5,
+				Rate:    dcrBtcRateStep * 1000,
+			},
+			Type:            "stoploss",
+			Trigger:         "fiat",
+			TriggerFiatRate: 40, // base fiat rate is 45
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*ConditionalOrderForm)
+		wantErr bool
+	}{
+		{
+			name:   "ok",
+			modify: func(*ConditionalOrderForm) {},
+		},
+		{
+			name:    "no trade",
+			modify:  func(f *ConditionalOrderForm) { f.Trade = nil },
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			modify:  func(f *ConditionalOrderForm) { f.Type = "stopgain" },
+			wantErr: true,
+		},
+		{
+			name:    "unknown trigger",
+			modify:  func(f *ConditionalOrderForm) { f.Trigger = "vwap" },
+			wantErr: true,
+		},
+		{
+			name:    "zero mid-gap trigger",
+			modify:  func(f *ConditionalOrderForm) { f.Trigger = "midgap" },
+			wantErr: true,
+		},
+		{
+			name:    "unknown market",
+			modify:  func(f *ConditionalOrderForm) { f.Trade.Quote = 12345 },
+			wantErr: true,
+		},
+		{
+			name:    "bad lot size",
+			modify:  func(f *ConditionalOrderForm) { f.Trade.Qty = dcrBtcLotSize + 1 },
+			wantErr: true,
+		},
+		{
+			name:    "bad rate step",
+			modify:  func(f *ConditionalOrderForm) { f.Trade.Rate = dcrBtcRateStep + 1 },
+			wantErr: true,
+		},
+		{
+			name:    "stop-loss already triggered",
+			modify:  func(f *ConditionalOrderForm) { f.TriggerFiatRate = 50 },
+			wantErr: true,
+		},
+		{
+			name: "take-profit",
+			modify: func(f *ConditionalOrderForm) {
+				f.Type = "takeprofit"
+				f.TriggerFiatRate = 50
+			},
+		},
+		{
+			name: "take-profit already triggered",
+			modify: func(f *ConditionalOrderForm) {
+				f.Type = "takeprofit"
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		form := newForm()
+		tt.modify(form)
+		co, err := tCore.PlaceConditionalOrder(form)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("%s: no error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if co.Status != "active" || co.Type != form.Type || co.Trigger != "fiat" {
+			t.Fatalf("%s: wrong conditional order %+v", tt.name, co)
+		}
+		if rig.db.conditionalOrders[co.ID.String()] == nil {
+			t.Fatalf("%s: conditional order not stored", tt.name)
+		}
+
+		if err := tCore.CancelConditionalOrder(co.ID); err != nil {
+			t.Fatalf("%s: CancelConditionalOrder error: %v", tt.name, err)
+		}
+		if status := rig.db.conditionalOrders[co.ID.String()].Status; status != db.ConditionalOrderCanceled {
+			t.Fatalf("%s: wrong status after cancel: %s", tt.name, status)
+		}
+		if err := tCore.CancelConditionalOrder(co.ID); err == nil {
+			t.Fatalf("%s: no error canceling twice", tt.name)
+		}
+	}
+
+	ords, err := tCore.ConditionalOrders(true)
+	if err != nil {
+		t.Fatalf("ConditionalOrders error: %v", err)
+	}
+	if len(ords) != 0 {
+		t.Fatalf("expected no active conditional orders, got %d", len(ords))
+	}
+}
+
+func TestPlaceExecutionOrder(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	newForm := func() *ExecutionOrderForm {
+		return &ExecutionOrderForm{
+			Host:       tDexHost,
+			Base:       tUTXOAssetA.ID,
+			Quote:      tUTXOAssetB.ID,
+			Sell:       true,
+			Qty:        dcrBtcLotSize * 10,
+			Rate:       dcrBtcRateStep * 1000,
+			Algo:       "twap",
+			Slices:     5,
+			Interval:   3600,
+			VisibleQty: dcrBtcLotSize * 2,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*ExecutionOrderForm)
+		wantErr bool
+	}{
+		{
+			name:   "twap",
+			modify: func(*ExecutionOrderForm) {},
+		},
+		{
+			name:   "iceberg",
+			modify: func(f *ExecutionOrderForm) { f.Algo = "iceberg" },
+		},
+		{
+			name:    "unknown algo",
+			modify:  func(f *ExecutionOrderForm) { f.Algo = "vwap" },
+			wantErr: true,
+		},
+		{
+			name:    "unknown market",
+			modify:  func(f *ExecutionOrderForm) { f.Quote = 12345 },
+			wantErr: true,
+		},
+		{
+			name:    "bad lot size",
+			modify:  func(f *ExecutionOrderForm) { f.Qty = dcrBtcLotSize*10 + 1 },
+			wantErr: true,
+		},
+		{
+			name:    "bad rate step",
+			modify:  func(f *ExecutionOrderForm) { f.Rate = dcrBtcRateStep + 1 },
+			wantErr: true,
+		},
+		{
+			name:    "more slices than lots",
+			modify:  func(f *ExecutionOrderForm) { f.Slices = 11 },
+			wantErr: true,
+		},
+		{
+			name:    "interval shorter than epoch",
+			modify:  func(f *ExecutionOrderForm) { f.Interval = 0 },
+			wantErr: true,
+		},
+		{
+			name: "visible quantity not a multiple of lot size",
+			modify: func(f *ExecutionOrderForm) {
+				f.Algo = "iceberg"
+				f.VisibleQty = dcrBtcLotSize / 2
+			},
+			wantErr: true,
+		},
+		{
+			name: "visible quantity exceeds quantity",
+			modify: func(f *ExecutionOrderForm) {
+				f.Algo = "iceberg"
+				f.VisibleQty = dcrBtcLotSize * 11
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		form := newForm()
+		tt.modify(form)
+		eo, err := tCore.PlaceExecutionOrder(form)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("%s: no error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if eo.Status != "active" || eo.Algo != form.Algo {
+			t.Fatalf("%s: wrong execution order %+v", tt.name, eo)
+		}
+		if rig.db.executionOrders[eo.ID.String()] == nil {
+			t.Fatalf("%s: execution order not stored", tt.name)
+		}
+
+		if err := tCore.ResumeExecutionOrder(eo.ID); err == nil {
+			t.Fatalf("%s: no error resuming an active order", tt.name)
+		}
+		if err := tCore.PauseExecutionOrder(eo.ID); err != nil {
+			t.Fatalf("%s: PauseExecutionOrder error: %v", tt.name, err)
+		}
+		if status := rig.db.executionOrders[eo.ID.String()].Status; status != db.ExecutionPaused {
+			t.Fatalf("%s: wrong status after pause: %s", tt.name, status)
+		}
+		if err := tCore.ResumeExecutionOrder(eo.ID); err != nil {
+			t.Fatalf("%s: ResumeExecutionOrder error: %v", tt.name, err)
+		}
+		if err := tCore.CancelExecutionOrder(eo.ID); err != nil {
+			t.Fatalf("%s: CancelExecutionOrder error: %v", tt.name, err)
+		}
+		if status := rig.db.executionOrders[eo.ID.String()].Status; status != db.ExecutionCanceled {
+			t.Fatalf("%s: wrong status after cancel: %s", tt.name, status)
+		}
+		if err := tCore.PauseExecutionOrder(eo.ID); err == nil {
+			t.Fatalf("%s: no error pausing a canceled order", tt.name)
+		}
+	}
+}
+
+func TestCheckExecutionOrder(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+	tCore := rig.core
+
+	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	if err != nil {
+		t.Fatalf("walletSet error: %v", err)
+	}
+
+	qty := dcrBtcLotSize * 4
+	rate := dcrBtcRateStep * 1000
+
+	// addChild adds a tracked child order with the specified status and fill.
+	addChild := func(status order.OrderStatus, filled uint64) order.OrderID {
+		lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, qty/2, rate)
+		lo.Force = order.StandingTiF
+		lo.FillAmt = filled
+		dbOrder.MetaData.Status = status
+		tracker := newTrackedTrade(dbOrder, preImg, dc, tCore.lockTimeTaker, tCore.lockTimeMaker,
+			rig.db, rig.queue, walletSet, nil, tCore.notify, tCore.formatDetails)
+		dc.tradeMtx.Lock()
+		dc.trades[tracker.ID()] = tracker
+		dc.tradeMtx.Unlock()
+		return lo.ID()
+	}
+
+	eo := &db.ExecutionOrder{
+		ID:         encode.RandomBytes(8),
+		Host:       tDexHost,
+		Base:       tUTXOAssetA.ID,
+		Quote:      tUTXOAssetB.ID,
+		Sell:       true,
+		Qty:        qty,
+		Rate:       rate,
+		Algo:       db.ExecutionIceberg,
+		VisibleQty: qty / 2,
+		ChildIDs: []order.OrderID{
+			addChild(order.OrderStatusExecuted, qty/2),
+			addChild(order.OrderStatusBooked, qty/4),
+		},
+	}
+	eoID := dex.Bytes(eo.ID).String()
+
+	// check checks a copy of the active execution order, and returns true if
+	// the order is no longer active.
+	check := func() (done bool) {
+		t.Helper()
+		tCore.execMtx.Lock()
+		tCore.execOrders[eoID] = eo
+		tCore.execMtx.Unlock()
+		tCore.checkExecutionOrder(copyExecutionOrder(eo))
+		tCore.execMtx.Lock()
+		defer tCore.execMtx.Unlock()
+		_, found := tCore.execOrders[eoID]
+		return !found
+	}
+
+	notes := tCore.NotificationFeed()
+	defer notes.ReturnFeed()
+	ensurePaused := func(tag string) {
+		t.Helper()
+		if eo.Status != db.ExecutionPaused || eo.Error == "" {
+			t.Fatalf("%s: execution order not paused with an error: status = %s, error = %q", tag, eo.Status, eo.Error)
+		}
+		if stored := rig.db.executionOrders[eoID]; stored == nil || stored.Status != db.ExecutionPaused {
+			t.Fatalf("%s: paused status not stored", tag)
+		}
+		for {
+			select {
+			case note := <-notes.C:
+				if note.Topic() == TopicExecutionOrderPaused {
+					return
+				}
+			default:
+				t.Fatalf("%s: no pause notification", tag)
+			}
+		}
+	}
+
+	// The second child is still booked.
+	if check() {
+		t.Fatalf("iceberg order complete with a booked child order")
+	}
+	if eo.Filled != qty*3/4 {
+		t.Fatalf("wrong filled quantity %d", eo.Filled)
+	}
+	if stored := rig.db.executionOrders[eoID]; stored == nil || stored.Filled != eo.Filled {
+		t.Fatalf("filled quantity not stored")
+	}
+
+	// A child order that can't be retrieved pauses the execution order.
+	rig.db.orderErr = tErr
+	eo.ChildIDs = append(eo.ChildIDs, order.OrderID{0x01})
+	if check() {
+		t.Fatalf("execution order complete with an unknown child order")
+	}
+	ensurePaused("unknown child order")
+	rig.db.orderErr = nil
+	eo.ChildIDs = eo.ChildIDs[:2]
+	eo.Status, eo.Error = db.ExecutionActive, ""
+
+	// If the lot size grew, the remaining quantity can be less than a lot,
+	// and the execution order is paused rather than placing nothing.
+	eo.ChildIDs[1] = addChild(order.OrderStatusCanceled, 0)
+	mkt := dc.marketConfig(tDcrBtcMktName)
+	lotSize := mkt.LotSize
+	mkt.LotSize = qty
+	if check() {
+		t.Fatalf("iceberg order complete with less than a lot left")
+	}
+	mkt.LotSize = lotSize
+	ensurePaused("less than a lot")
+	eo.Status, eo.Error = db.ExecutionActive, ""
+
+	// Both children are filled.
+	eo.ChildIDs[1] = addChild(order.OrderStatusExecuted, qty/2)
+	if !check() {
+		t.Fatalf("filled iceberg order not complete")
+	}
+	if eo.Status != db.ExecutionComplete {
+		t.Fatalf("wrong status %s", eo.Status)
+	}
+
+	// A TWAP order with all slices placed is complete once the child orders
+	// are no longer booked, even if they were not filled.
+	eo.Algo = db.ExecutionTWAP
+	eo.Status = db.ExecutionActive
+	eo.Slices = 2
+	eo.ChildIDs[1] = addChild(order.OrderStatusBooked, 0)
+	if check() {
+		t.Fatalf("TWAP order complete with a booked child order")
+	}
+	eo.ChildIDs[1] = addChild(order.OrderStatusCanceled, 0)
+	if !check() {
+		t.Fatalf("TWAP order not complete")
+	}
+	if eo.Filled != qty/2 {
+		t.Fatalf("wrong filled quantity %d", eo.Filled)
+	}
+}
+
+func TestRealizedGains(t *testing.T) {
+	const day = msPerDay
+	rates := map[uint32]map[uint64]float64{
+		0:  {1: 50000, 2: 100000, 3: 40000},
+		42: {1: 50, 2: 100, 3: 80},
+	}
+	rate := func(assetID uint32, stamp uint64) float64 {
+		return rates[assetID][stamp/day]
+	}
+
+	trades := []*taxTrade{{
+		// Buy 2 DCR for 0.002 BTC worth $100.
+		stamp:    day + 1,
+		base:     42,
+		quote:    0,
+		qty:      2e8,
+		quoteQty: 2e5,
+	}, {
+		// Buy 2 DCR for 0.002 BTC worth $200.
+		stamp:    2*day + 1,
+		base:     42,
+		quote:    0,
+		qty:      2e8,
+		quoteQty: 2e5,
+	}, {
+		// Sell 3 DCR worth $240 for 0.006 BTC, paying 0.01 DCR in fees.
+		stamp:    3*day + 1,
+		base:     42,
+		quote:    0,
+		sell:     true,
+		qty:      3e8,
+		quoteQty: 6e5,
+		fees:     map[uint32]uint64{42: 1e6},
+	}}
+
+	tests := []struct {
+		method  CostBasisMethod
+		dcrCost float64
+	}{
+		{CostBasisFIFO, 100 + 100},
+		{CostBasisLIFO, 200 + 50},
+		{CostBasisAverage, 300 * 3 / 4},
+	}
+
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	for _, tt := range tests {
+		// Disposals before from are not reported.
+		gains := realizedGains(trades, tt.method, 2*day, 4*day, rate)
+		if len(gains) != 2 {
+			t.Fatalf("%s: expected 2 gains, got %d", tt.method, len(gains))
+		}
+
+		// The BTC spent on day 2 has an unknown cost basis.
+		btc := gains[0]
+		if btc.AssetID != 0 || btc.Qty != 2e5 || btc.UnknownBasisQty != 2e5 ||
+			!near(btc.Proceeds, 200) || btc.CostBasis != 0 || !near(btc.Gain, 200) {
+			t.Fatalf("%s: wrong BTC gain %+v", tt.method, btc)
+		}
+
+		dcr := gains[1]
+		if dcr.AssetID != 42 || dcr.Qty != 3e8 || dcr.UnknownBasisQty != 0 || dcr.MarketID != "dcr_btc" {
+			t.Fatalf("%s: wrong DCR disposal %+v", tt.method, dcr)
+		}
+		if !near(dcr.Proceeds, 240) || !near(dcr.CostBasis, tt.dcrCost) || !near(dcr.Fees, 0.8) ||
+			!near(dcr.Gain, 240-tt.dcrCost-0.8) {
+			t.Fatalf("%s: wrong DCR gain %+v", tt.method, dcr)
+		}
+	}
+
+	// Trades after to are not processed.
+	if gains := realizedGains(trades, CostBasisFIFO, 0, 2*day, rate); len(gains) != 1 {
+		t.Fatalf("expected 1 gain, got %d", len(gains))
+	}
+}
+
+func TestHistoricalFiatRates(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	type fetch struct{ from, to uint64 }
+	var fetches []fetch
+	historicalRateFetchers["test"] = func(ctx context.Context, a *SupportedAsset, fromDay, toDay uint64) (map[uint64]float64, error) {
+		fetches = append(fetches, fetch{fromDay, toDay})
+		rates := make(map[uint64]float64)
+		for day := fromDay; day <= toDay; day++ {
+			rates[day] = float64(day)
+		}
+		return rates, nil
+	}
+	defer delete(historicalRateFetchers, "test")
+
+	today := uint64(time.Now().UnixMilli()) / msPerDay
+	rig.db.StoreHistoricalRates("test", 42, map[uint64]float64{today - 5: 1})
+
+	sa := &SupportedAsset{ID: 42, Symbol: "dcr", Name: "Decred"}
+	days := map[uint64]bool{today - 5: true, today - 3: true, today - 2: true, today: true}
+	rates, err := tCore.historicalFiatRates(tCtx, "test", sa, days)
+	if err != nil {
+		t.Fatalf("historicalFiatRates error: %v", err)
+	}
+	// No fiat rate sources are enabled, so there is no rate for today.
+	exp := map[uint64]float64{today - 5: 1, today - 3: float64(today - 3), today - 2: float64(today - 2)}
+	if !reflect.DeepEqual(rates, exp) {
+		t.Fatalf("wrong rates. expected %v, got %v", exp, rates)
+	}
+	if len(fetches) != 1 || fetches[0] != (fetch{today - 3, today - 2}) {
+		t.Fatalf("wrong fetches %v", fetches)
+	}
+
+	// The fetched rates are cached, except for today's.
+	fetches = nil
+	rates, err = tCore.historicalFiatRates(tCtx, "test", sa, days)
+	if err != nil {
+		t.Fatalf("historicalFiatRates error: %v", err)
+	}
+	if !reflect.DeepEqual(rates, exp) {
+		t.Fatalf("wrong cached rates. expected %v, got %v", exp, rates)
+	}
+	if len(fetches) != 0 {
+		t.Fatalf("unexpected fetches %v", fetches)
+	}
+
+	if _, err := tCore.historicalFiatRates(tCtx, "unknown", sa, days); err == nil {
+		t.Fatalf("no error for unknown rate source")
+	}
+
+	if _, err := tCore.TaxReport(&TaxReportForm{Method: "hifo"}); err == nil {
+		t.Fatalf("no error for unknown cost basis method")
+	}
+	if _, err := tCore.TaxReport(&TaxReportForm{RateSource: dcrdataDotOrg}); err == nil {
+		t.Fatalf("no error for rate source without historical rates")
+	}
+	report, err := tCore.TaxReport(&TaxReportForm{})
+	if err != nil {
+		t.Fatalf("TaxReport error: %v", err)
+	}
+	if report.Method != CostBasisFIFO || report.RateSource != coinpaprika || len(report.Gains) != 0 {
+		t.Fatalf("wrong empty report %+v", report)
+	}
+}
+
+type TRedemptionSigner struct {
+	*TXCWallet
+	signedTx []byte
+	signErr  error
+	forms    []*asset.RedeemForm
+}
+
+func (w *TRedemptionSigner) SignRedemption(form *asset.RedeemForm) ([]byte, error) {
+	w.forms = append(w.forms, form)
+	return w.signedTx, w.signErr
+}
+
+type tTower struct {
+	watchErr error
+	jobs     []*watchtower.Job
+}
+
+func (t *tTower) Watch(_ context.Context, job *watchtower.Job) (string, error) {
+	if t.watchErr != nil {
+		return "", t.watchErr
+	}
+	t.jobs = append(t.jobs, job)
+	return job.ID(), nil
+}
+
+func TestWatchtowerRedeems(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	tower := new(tTower)
+	tCore.watchtower = tower
+
+	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	signer := &TRedemptionSigner{TXCWallet: tBtcWallet, signedTx: []byte{0x01}}
+	btcWallet.Wallet = signer
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+
+	walletSet, _, _, err := tCore.walletSet(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	if err != nil {
+		t.Fatalf("walletSet error: %v", err)
+	}
+	tracker := makeTradeTracker(rig, walletSet, order.StandingTiF, order.OrderStatusBooked)
+	tracker.metaData.ToSwapConf = 2
+
+	secret := encode.RandomBytes(32)
+	secretHash := sha256.Sum256(secret)
+	_, auditInfo := tMsgAudit(tracker.ID(), ordertest.RandomMatchID(), "", 1, secretHash[:])
+	newMatch := func(side order.MatchSide, status order.MatchStatus) *matchTracker {
+		return &matchTracker{
+			MetaMatch: db.MetaMatch{
+				UserMatch: &order.UserMatch{
+					MatchID: ordertest.RandomMatchID(),
+					Side:    side,
+					Status:  status,
+				},
+				MetaData: &db.MatchMetaData{
+					Proof: db.MatchProof{Secret: secret},
+				},
+			},
+			counterSwap: auditInfo,
+		}
+	}
+
+	// Only the maker can pre-sign a redemption, after the audit.
+	if tCore.needsWatchtowerRedeem(tracker, newMatch(order.Taker, order.MakerRedeemed)) {
+		t.Fatalf("taker match needs watchtower redeem")
+	}
+	if tCore.needsWatchtowerRedeem(tracker, newMatch(order.Maker, order.MakerSwapCast)) {
+		t.Fatalf("unaudited match needs watchtower redeem")
+	}
+	match := newMatch(order.Maker, order.TakerSwapCast)
+	if !tCore.needsWatchtowerRedeem(tracker, match) {
+		t.Fatalf("audited maker match doesn't need watchtower redeem")
+	}
+
+	// A failed signing is not retried.
+	signer.signErr = tErr
+	tCore.sendRedeemsToWatchtower(tracker, []*matchTracker{match})
+	if len(tCore.towerJobs) != 0 {
+		t.Fatalf("job queued for failed signing")
+	}
+	if tCore.needsWatchtowerRedeem(tracker, match) {
+		t.Fatalf("watchtower redeem retried after signing error")
+	}
+	signer.signErr = nil
+
+	match = newMatch(order.Maker, order.TakerSwapCast)
+	tCore.sendRedeemsToWatchtower(tracker, []*matchTracker{match})
+	if len(tCore.towerJobs) != 1 {
+		t.Fatalf("expected 1 queued job, got %d", len(tCore.towerJobs))
+	}
+	form := signer.forms[len(signer.forms)-1]
+	if len(form.Redemptions) != 1 || !bytes.Equal(form.Redemptions[0].Secret, secret) ||
+		form.Redemptions[0].Spends != auditInfo {
+		t.Fatalf("wrong redemption signed")
+	}
+	for _, job := range tCore.towerJobs {
+		if job.Action != watchtower.ActionRedeem || job.AssetID != tUTXOAssetB.ID ||
+			!bytes.Equal(job.CoinID, auditInfo.Coin.ID()) || !bytes.Equal(job.Tx, signer.signedTx) {
+			t.Fatalf("wrong redeem job %+v", job)
+		}
+		if job.Confs != 2+watchtowerRedeemConfBuffer {
+			t.Fatalf("wrong redeem job confs %d", job.Confs)
+		}
+	}
+	if tCore.needsWatchtowerRedeem(tracker, match) {
+		t.Fatalf("watchtower redeem needed after it was sent")
+	}
+
+	// No watchtower redeems for wallets that can't pre-sign.
+	btcWallet.Wallet = tBtcWallet
+	if tCore.needsWatchtowerRedeem(tracker, newMatch(order.Maker, order.TakerSwapCast)) {
+		t.Fatalf("watchtower redeem needed for wallet that can't sign")
+	}
+}
+
+func TestWatchtowerJobRetry(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	tower := &tTower{watchErr: tErr}
+	tCore.watchtower = tower
+
+	tCore.sendRefundsToWatchtower(tUTXOAssetA.ID, []asset.Receipt{
+		&tReceipt{coin: &tCoin{id: encode.RandomBytes(36)}, contract: encode.RandomBytes(36), signedRefund: []byte{0x01}},
+		&tReceipt{coin: &tCoin{id: encode.RandomBytes(36)}, contract: encode.RandomBytes(36)}, // no refund
+	})
+	if len(tCore.towerJobs) != 1 {
+		t.Fatalf("expected 1 queued job, got %d", len(tCore.towerJobs))
+	}
+
+	// The watchtower is unreachable. The job stays queued.
+	tCore.submitWatchtowerJobs(tCtx)
+	if len(tCore.towerJobs) != 1 {
+		t.Fatalf("failed job was dropped")
+	}
+
+	// The queued job is saved, and is queued again after a restart.
+	if len(rig.db.towerJobs) != 1 {
+		t.Fatalf("expected 1 saved job, got %d", len(rig.db.towerJobs))
+	}
+	tCore.towerJobs = make(map[string]*watchtower.Job)
+	tCore.resolveActiveTrades(rig.crypter)
+	if len(tCore.towerJobs) != 1 {
+		t.Fatalf("saved job not queued after restart")
+	}
+
+	tower.watchErr = nil
+	tCore.submitWatchtowerJobs(tCtx)
+	if len(tCore.towerJobs) != 0 {
+		t.Fatalf("submitted job still queued")
+	}
+	if len(rig.db.towerJobs) != 0 {
+		t.Fatalf("submitted job still saved")
+	}
+	if len(tower.jobs) != 1 || tower.jobs[0].Action != watchtower.ActionRefund {
+		t.Fatalf("refund job not submitted")
+	}
+}
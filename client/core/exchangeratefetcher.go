@@ -43,8 +43,20 @@ var (
 	messariURL  = "https://data.messari.io/api/v1/assets/%s/metrics/market-data"
 	btcBipID, _ = dex.BipSymbolID("btc")
 	dcrBipID, _ = dex.BipSymbolID("dcr")
+	ethBipID, _ = dex.BipSymbolID("eth")
+	// baseBipID is the Base network's ETH.
+	baseBipID, _ = dex.BipSymbolID("base")
 )
 
+// fiatRateAssetID is the ID of the asset that the fiat rate sources price
+// the asset as. Base's ETH is priced as ETH.
+func fiatRateAssetID(assetID uint32) uint32 {
+	if assetID == baseBipID {
+		return ethBipID
+	}
+	return assetID
+}
+
 // fiatRateFetchers is the list of all supported fiat rate fetchers.
 var fiatRateFetchers = map[string]rateFetcher{
 	coinpaprika:   FetchCoinpaprikaRates,
@@ -0,0 +1,483 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/order"
+)
+
+// executionCheckInterval is how often the execution order supervisor checks
+// the child orders and places new ones.
+const executionCheckInterval = 5 * time.Second
+
+// ExecutionOrderForm is the information necessary to place an execution
+// order. Child orders are standing limit orders at the parent order's rate.
+type ExecutionOrderForm struct {
+	Host    string            `json:"host"`
+	Base    uint32            `json:"base"`
+	Quote   uint32            `json:"quote"`
+	Sell    bool              `json:"sell"`
+	Qty     uint64            `json:"qty"`
+	Rate    uint64            `json:"rate"`
+	Options map[string]string `json:"options"`
+	// Algo is either "twap", to split the order into Slices equal child
+	// orders placed every Interval seconds, or "iceberg", to keep a single
+	// child order of VisibleQty booked until the order is filled.
+	Algo       string `json:"algo"`
+	Slices     uint32 `json:"slices"`
+	Interval   uint64 `json:"interval"`
+	VisibleQty uint64 `json:"visibleQty"`
+}
+
+// ExecutionOrder is a large limit order that is executed as a series of
+// smaller child orders.
+type ExecutionOrder struct {
+	ID         dex.Bytes         `json:"id"`
+	Host       string            `json:"host"`
+	Base       uint32            `json:"base"`
+	Quote      uint32            `json:"quote"`
+	Sell       bool              `json:"sell"`
+	Qty        uint64            `json:"qty"`
+	Rate       uint64            `json:"rate"`
+	Options    map[string]string `json:"options,omitempty"`
+	Algo       string            `json:"algo"`
+	Slices     uint32            `json:"slices,omitempty"`
+	Interval   uint64            `json:"interval,omitempty"`
+	VisibleQty uint64            `json:"visibleQty,omitempty"`
+	Stamp      uint64            `json:"stamp"`
+	Status     string            `json:"status"`
+	// NextSlice is when the next TWAP child order is due, in milliseconds.
+	NextSlice     uint64      `json:"nextSlice,omitempty"`
+	Filled        uint64      `json:"filled"`
+	ChildOrderIDs []dex.Bytes `json:"childOrderIDs"`
+	// Error is the reason the last child order could not be placed.
+	Error string `json:"error,omitempty"`
+}
+
+func executionOrderFromDB(o *db.ExecutionOrder) *ExecutionOrder {
+	childIDs := make([]dex.Bytes, 0, len(o.ChildIDs))
+	for _, oid := range o.ChildIDs {
+		childIDs = append(childIDs, oid.Bytes())
+	}
+	return &ExecutionOrder{
+		ID:            o.ID,
+		Host:          o.Host,
+		Base:          o.Base,
+		Quote:         o.Quote,
+		Sell:          o.Sell,
+		Qty:           o.Qty,
+		Rate:          o.Rate,
+		Options:       o.Options,
+		Algo:          o.Algo.String(),
+		Slices:        o.Slices,
+		Interval:      o.Interval,
+		VisibleQty:    o.VisibleQty,
+		Stamp:         o.Stamp,
+		Status:        o.Status.String(),
+		NextSlice:     o.NextSlice,
+		Filled:        o.Filled,
+		ChildOrderIDs: childIDs,
+		Error:         o.Error,
+	}
+}
+
+// PlaceExecutionOrder stores an order that will be executed as a series of
+// child orders, placed with Trade. The wallets must be unlocked and the user
+// logged in for child orders to be placed.
+func (c *Core) PlaceExecutionOrder(form *ExecutionOrderForm) (*ExecutionOrder, error) {
+	dc, err := c.registeredDEX(form.Host)
+	if err != nil {
+		return nil, err
+	}
+	mktID := marketName(form.Base, form.Quote)
+	mkt := dc.marketConfig(mktID)
+	if mkt == nil {
+		return nil, newError(marketErr, "unknown market %q", mktID)
+	}
+	if form.Qty == 0 {
+		return nil, newError(orderParamsErr, "zero quantity not allowed")
+	}
+	if form.Qty%mkt.LotSize != 0 {
+		return nil, newError(orderParamsErr, "order quantity %d not a multiple of lot size %d", form.Qty, mkt.LotSize)
+	}
+	if form.Rate == 0 {
+		return nil, newError(orderParamsErr, "zero rate is invalid")
+	}
+	if form.Rate%mkt.RateStep != 0 {
+		return nil, newError(orderParamsErr, "order rate %d not a multiple of rate step %d", form.Rate, mkt.RateStep)
+	}
+
+	now := uint64(time.Now().UnixMilli())
+	o := &db.ExecutionOrder{
+		ID:        encode.RandomBytes(8),
+		Host:      form.Host,
+		Base:      form.Base,
+		Quote:     form.Quote,
+		Sell:      form.Sell,
+		Qty:       form.Qty,
+		Rate:      form.Rate,
+		Options:   form.Options,
+		Stamp:     now,
+		NextSlice: now,
+	}
+	switch form.Algo {
+	case db.ExecutionTWAP.String():
+		o.Algo = db.ExecutionTWAP
+		if form.Slices == 0 || uint64(form.Slices) > form.Qty/mkt.LotSize {
+			return nil, newError(orderParamsErr, "number of slices must be between 1 and the number of lots")
+		}
+		if form.Interval*1000 < mkt.EpochLen {
+			return nil, newError(orderParamsErr, "interval must be at least one epoch (%d ms)", mkt.EpochLen)
+		}
+		o.Slices = form.Slices
+		o.Interval = form.Interval
+	case db.ExecutionIceberg.String():
+		o.Algo = db.ExecutionIceberg
+		if form.VisibleQty == 0 || form.VisibleQty > form.Qty {
+			return nil, newError(orderParamsErr, "visible quantity must be between 1 lot and the order quantity")
+		}
+		if form.VisibleQty%mkt.LotSize != 0 {
+			return nil, newError(orderParamsErr, "visible quantity %d not a multiple of lot size %d", form.VisibleQty, mkt.LotSize)
+		}
+		o.VisibleQty = form.VisibleQty
+	default:
+		return nil, fmt.Errorf("unknown execution algorithm %q", form.Algo)
+	}
+
+	if err := c.db.UpdateExecutionOrder(o); err != nil {
+		return nil, fmt.Errorf("error storing execution order: %w", err)
+	}
+
+	c.execMtx.Lock()
+	c.execOrders[dex.Bytes(o.ID).String()] = o
+	c.execMtx.Unlock()
+
+	return executionOrderFromDB(o), nil
+}
+
+// PauseExecutionOrder stops an active execution order from placing new child
+// orders. Child orders that are already booked are not canceled.
+func (c *Core) PauseExecutionOrder(id dex.Bytes) error {
+	c.execMtx.Lock()
+	defer c.execMtx.Unlock()
+	o, found := c.execOrders[id.String()]
+	if !found {
+		return fmt.Errorf("no active execution order %s", id)
+	}
+	if o.Status == db.ExecutionPaused {
+		return fmt.Errorf("execution order %s is already paused", id)
+	}
+	o.Status = db.ExecutionPaused
+	if err := c.db.UpdateExecutionOrder(o); err != nil {
+		return fmt.Errorf("error updating execution order: %w", err)
+	}
+	return nil
+}
+
+// ResumeExecutionOrder resumes a paused execution order. A TWAP order places
+// its next child order immediately.
+func (c *Core) ResumeExecutionOrder(id dex.Bytes) error {
+	c.execMtx.Lock()
+	defer c.execMtx.Unlock()
+	o, found := c.execOrders[id.String()]
+	if !found {
+		return fmt.Errorf("no active execution order %s", id)
+	}
+	if o.Status != db.ExecutionPaused {
+		return fmt.Errorf("execution order %s is not paused", id)
+	}
+	o.Status = db.ExecutionActive
+	o.NextSlice = uint64(time.Now().UnixMilli())
+	o.Error = ""
+	if err := c.db.UpdateExecutionOrder(o); err != nil {
+		return fmt.Errorf("error updating execution order: %w", err)
+	}
+	return nil
+}
+
+// CancelExecutionOrder cancels an active or paused execution order, and
+// cancels any of its child orders that are still booked.
+func (c *Core) CancelExecutionOrder(id dex.Bytes) error {
+	c.execMtx.Lock()
+	o, found := c.execOrders[id.String()]
+	if !found {
+		c.execMtx.Unlock()
+		return fmt.Errorf("no active execution order %s", id)
+	}
+	delete(c.execOrders, id.String())
+	c.execMtx.Unlock()
+
+	o.Status = db.ExecutionCanceled
+	if err := c.db.UpdateExecutionOrder(o); err != nil {
+		return fmt.Errorf("error updating execution order: %w", err)
+	}
+	for _, oid := range o.ChildIDs {
+		ord, err := c.Order(oid.Bytes())
+		if err != nil {
+			c.log.Errorf("Error retrieving child order %s of execution order %s: %v", oid, id, err)
+			continue
+		}
+		if ord.Status > order.OrderStatusBooked || ord.Cancelling {
+			continue
+		}
+		if err := c.Cancel(oid.Bytes()); err != nil {
+			c.log.Errorf("Error canceling child order %s of execution order %s: %v", oid, id, err)
+		}
+	}
+	return nil
+}
+
+// ExecutionOrders returns all stored execution orders, newest first. If
+// activeOnly is true, only active and paused orders are returned.
+func (c *Core) ExecutionOrders(activeOnly bool) ([]*ExecutionOrder, error) {
+	dbOrds, err := c.db.ExecutionOrders(activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	ords := make([]*ExecutionOrder, 0, len(dbOrds))
+	for _, o := range dbOrds {
+		ords = append(ords, executionOrderFromDB(o))
+	}
+	return ords, nil
+}
+
+// watchExecutionOrders loads the active and paused execution orders and
+// manages their child orders until the context is canceled.
+func (c *Core) watchExecutionOrders(ctx context.Context) {
+	ords, err := c.db.ExecutionOrders(true)
+	if err != nil {
+		c.log.Errorf("Error loading execution orders: %v", err)
+	}
+	c.execMtx.Lock()
+	for _, o := range ords {
+		c.execOrders[dex.Bytes(o.ID).String()] = o
+	}
+	c.execMtx.Unlock()
+
+	ticker := time.NewTicker(executionCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.checkExecutionOrders()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkExecutionOrders updates the filled quantity of the execution orders,
+// places any child orders that are due, and completes the orders that are
+// done. Nothing is done until the user is logged in. The execMtx is not held
+// while child orders are placed, since Trade requires a round trip to the
+// server.
+func (c *Core) checkExecutionOrders() {
+	c.loginMtx.Lock()
+	loggedIn := c.loggedIn
+	c.loginMtx.Unlock()
+	if !loggedIn {
+		return
+	}
+
+	c.execMtx.Lock()
+	ords := make([]*db.ExecutionOrder, 0, len(c.execOrders))
+	for _, o := range c.execOrders {
+		ords = append(ords, copyExecutionOrder(o))
+	}
+	c.execMtx.Unlock()
+
+	for _, o := range ords {
+		c.checkExecutionOrder(o)
+	}
+}
+
+// copyExecutionOrder copies the execution order, so that it can be read
+// without the execMtx.
+func copyExecutionOrder(o *db.ExecutionOrder) *db.ExecutionOrder {
+	oCopy := *o
+	oCopy.ChildIDs = append([]order.OrderID(nil), o.ChildIDs...)
+	return &oCopy
+}
+
+// updateExecutionOrder applies the update to the active execution order and
+// stores it. The order is no longer active if update returns true. nil is
+// returned if the order is no longer active, e.g. it was canceled.
+func (c *Core) updateExecutionOrder(id dex.Bytes, update func(o *db.ExecutionOrder) (done bool)) *ExecutionOrder {
+	c.execMtx.Lock()
+	defer c.execMtx.Unlock()
+	o, found := c.execOrders[id.String()]
+	if !found {
+		return nil
+	}
+	if update(o) {
+		delete(c.execOrders, id.String())
+	}
+	if err := c.db.UpdateExecutionOrder(o); err != nil {
+		c.log.Errorf("Error updating execution order %s: %v", id, err)
+	}
+	return executionOrderFromDB(o)
+}
+
+// pauseExecutionOrder pauses an active execution order that cannot place its
+// next child order, and notifies the user.
+func (c *Core) pauseExecutionOrder(o *db.ExecutionOrder, filled uint64, err error) {
+	id := dex.Bytes(o.ID)
+	eo := c.updateExecutionOrder(id, func(o *db.ExecutionOrder) bool {
+		o.Filled = filled
+		o.Status = db.ExecutionPaused
+		o.Error = err.Error()
+		return false
+	})
+	if eo == nil {
+		return
+	}
+	subject, details := c.formatDetails(TopicExecutionOrderPaused, o.Algo, id, marketName(o.Base, o.Quote), err)
+	c.notify(newExecutionOrderNote(TopicExecutionOrderPaused, subject, details, db.ErrorLevel, eo))
+}
+
+// checkExecutionOrder processes a copy of a single execution order. Changes
+// are applied to the active order, which is removed from the active orders
+// once it is complete. The execMtx must not be held.
+func (c *Core) checkExecutionOrder(o *db.ExecutionOrder) {
+	id := dex.Bytes(o.ID)
+	var filled, placed uint64
+	var open int
+	for _, oid := range o.ChildIDs {
+		ord, err := c.Order(oid.Bytes())
+		if err != nil {
+			c.log.Errorf("Error retrieving child order %s of execution order %s: %v", oid, id, err)
+			// Without the child order, the filled and open quantities are
+			// unknown, so stop placing child orders until the user resumes or
+			// cancels the order.
+			if o.Status == db.ExecutionActive {
+				c.pauseExecutionOrder(o, o.Filled, fmt.Errorf("error retrieving child order %s: %w", oid, err))
+			}
+			return
+		}
+		filled += ord.Filled
+		placed += ord.Qty
+		if ord.Status <= order.OrderStatusBooked {
+			open++
+		}
+	}
+
+	// updateFilled stores the filled quantity if it changed.
+	updateFilled := func() {
+		if filled == o.Filled {
+			return
+		}
+		c.updateExecutionOrder(id, func(o *db.ExecutionOrder) bool {
+			o.Filled = filled
+			return false
+		})
+	}
+
+	var qty uint64
+	var done bool
+	switch o.Algo {
+	case db.ExecutionTWAP:
+		slicesLeft := uint64(o.Slices) - uint64(len(o.ChildIDs))
+		if slicesLeft == 0 {
+			done = open == 0
+			break
+		}
+		if o.Status != db.ExecutionActive || uint64(time.Now().UnixMilli()) < o.NextSlice {
+			updateFilled()
+			return
+		}
+		qty = (o.Qty - placed) / slicesLeft
+	case db.ExecutionIceberg:
+		if open > 0 {
+			updateFilled()
+			return
+		}
+		if filled >= o.Qty {
+			done = true
+			break
+		}
+		if o.Status != db.ExecutionActive {
+			updateFilled()
+			return
+		}
+		qty = min(o.VisibleQty, o.Qty-filled)
+	}
+
+	mktID := marketName(o.Base, o.Quote)
+	if done {
+		eo := c.updateExecutionOrder(id, func(o *db.ExecutionOrder) bool {
+			o.Filled = filled
+			o.Status = db.ExecutionComplete
+			return true
+		})
+		if eo == nil {
+			return
+		}
+		subject, details := c.formatDetails(TopicExecutionOrderComplete, o.Algo, id, mktID,
+			executionAmtString(o.Base, filled), executionAmtString(o.Base, o.Qty))
+		c.notify(newExecutionOrderNote(TopicExecutionOrderComplete, subject, details, db.Success, eo))
+		return
+	}
+
+	if dc, err := c.registeredDEX(o.Host); err == nil {
+		if mkt := dc.marketConfig(mktID); mkt != nil {
+			qty -= qty % mkt.LotSize
+		}
+	}
+	if qty == 0 {
+		// The lot size may have changed since the order was placed, leaving
+		// less than a lot for the child order.
+		c.pauseExecutionOrder(o, filled, errors.New("child order quantity is less than one lot"))
+		return
+	}
+
+	corder, err := c.Trade(nil, &TradeForm{
+		Host:    o.Host,
+		IsLimit: true,
+		Sell:    o.Sell,
+		Base:    o.Base,
+		Quote:   o.Quote,
+		Qty:     qty,
+		Rate:    o.Rate,
+		Options: o.Options,
+	})
+	if err != nil {
+		c.pauseExecutionOrder(o, filled, err)
+		return
+	}
+	var oid order.OrderID
+	copy(oid[:], corder.ID)
+	if c.updateExecutionOrder(id, func(o *db.ExecutionOrder) bool {
+		o.Filled = filled
+		o.ChildIDs = append(o.ChildIDs, oid)
+		o.NextSlice = uint64(time.Now().UnixMilli()) + o.Interval*1000
+		return false
+	}) == nil {
+		// The execution order was canceled while the child order was placed.
+		c.log.Infof("Canceling child order %s of canceled %s execution order %s on %s", oid, o.Algo, id, mktID)
+		if err := c.Cancel(corder.ID); err != nil {
+			c.log.Errorf("Error canceling child order %s of execution order %s: %v", oid, id, err)
+		}
+		return
+	}
+	c.log.Infof("Placed child order %s of %s execution order %s on %s", oid, o.Algo, id, mktID)
+}
+
+// executionAmtString formats an amount of the asset in conventional units.
+func executionAmtString(assetID uint32, amt uint64) string {
+	ui, err := asset.UnitInfo(assetID)
+	if err != nil {
+		return strconv.FormatUint(amt, 10)
+	}
+	return ui.ConventionalString(amt) + " " + ui.Conventional.Unit
+}
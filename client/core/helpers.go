@@ -104,7 +104,7 @@ func (ord *OrderReader) ToID() uint32 {
 // Cancelable will be true for standing limit orders in status epoch or booked.
 func (ord *OrderReader) Cancelable() bool {
 	return ord.Type == order.LimitOrderType &&
-		ord.TimeInForce == order.StandingTiF &&
+		ord.TimeInForce.Standing() &&
 		ord.Status <= order.OrderStatusBooked
 }
 
@@ -113,8 +113,13 @@ func (ord *OrderReader) TypeString() string {
 	s := "market"
 	if ord.Type == order.LimitOrderType {
 		s = "limit"
-		if ord.TimeInForce == order.ImmediateTiF {
+		switch ord.TimeInForce {
+		case order.ImmediateTiF:
 			s += " (i)"
+		case order.FillOrKillTiF:
+			s += " (fok)"
+		case order.PostOnlyTiF:
+			s += " (post)"
 		}
 	}
 	if ord.Sell {
@@ -177,6 +177,10 @@ var originLocale = map[Topic]*translation{
 		subject:  intl.Translation{T: "Matches Refunded"},
 		template: intl.Translation{T: "Refunded %s %s on order %s", Notes: "args: [qty, ticker, token]"},
 	},
+	TopicAwaitingSignature: {
+		subject:  intl.Translation{T: "Awaiting signature"},
+		template: intl.Translation{T: "The %s wallet is waiting for an external signature on order %s: %v", Notes: "args: [ticker, token, error]"},
+	},
 	TopicMatchRevoked: {
 		subject:  intl.Translation{T: "Match revoked"},
 		template: intl.Translation{T: "Match %s has been revoked", Notes: "args: [match ID token]"},
@@ -363,6 +367,22 @@ var originLocale = map[Topic]*translation{
 		subject:  intl.Translation{T: "DEX server status"},
 		template: intl.Translation{T: "DEX server %s has been enabled.", Notes: "args: [host]"},
 	},
+	TopicConditionalOrderTriggered: {
+		subject:  intl.Translation{T: "Conditional order triggered"},
+		template: intl.Translation{T: "%s order %s was triggered on %s. Order ID = %s", Notes: "args: [order type, conditional order ID, market, order ID]"},
+	},
+	TopicConditionalOrderFailed: {
+		subject:  intl.Translation{T: "Conditional order failed"},
+		template: intl.Translation{T: "%s order %s was triggered on %s, but the order could not be placed: %v", Notes: "args: [order type, conditional order ID, market, error]"},
+	},
+	TopicExecutionOrderComplete: {
+		subject:  intl.Translation{T: "Execution order complete"},
+		template: intl.Translation{T: "%s order %s on %s is complete. Filled %s of %s", Notes: "args: [algorithm, execution order ID, market, filled quantity, quantity]"},
+	},
+	TopicExecutionOrderPaused: {
+		subject:  intl.Translation{T: "Execution order paused"},
+		template: intl.Translation{T: "%s order %s on %s was paused because a child order could not be placed: %v", Notes: "args: [algorithm, execution order ID, market, error]"},
+	},
 }
 
 var ptBR = map[Topic]*translation{
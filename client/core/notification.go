@@ -43,6 +43,8 @@ const (
 	NoteTypeWalletNote     = "walletnote"
 	NoteTypeReputation     = "reputation"
 	NoteTypeActionRequired = "actionrequired"
+	NoteTypeConditional    = "conditionalorder"
+	NoteTypeExecution      = "executionorder"
 )
 
 var noteChanCounter uint64
@@ -391,6 +393,7 @@ const (
 	TopicMatchComplete        Topic = "MatchComplete"
 	TopicRefundFailure        Topic = "RefundFailure"
 	TopicMatchesRefunded      Topic = "MatchesRefunded"
+	TopicAwaitingSignature    Topic = "AwaitingSignature"
 	TopicMatchRevoked         Topic = "MatchRevoked"
 	TopicOrderRevoked         Topic = "OrderRevoked"
 	TopicOrderAutoRevoked     Topic = "OrderAutoRevoked"
@@ -817,3 +820,39 @@ func newRejectedRedemptionNote(assetID uint32, oid order.OrderID, coinID []byte)
 	}
 	return actionNote, coreNote
 }
+
+// ConditionalOrderNote is a notification about a conditional order.
+type ConditionalOrderNote struct {
+	db.Notification
+	ConditionalOrder *ConditionalOrder `json:"conditionalOrder"`
+}
+
+const (
+	TopicConditionalOrderTriggered Topic = "ConditionalOrderTriggered"
+	TopicConditionalOrderFailed    Topic = "ConditionalOrderFailed"
+)
+
+func newConditionalOrderNote(topic Topic, subject, details string, severity db.Severity, o *ConditionalOrder) *ConditionalOrderNote {
+	return &ConditionalOrderNote{
+		Notification:     db.NewNotification(NoteTypeConditional, topic, subject, details, severity),
+		ConditionalOrder: o,
+	}
+}
+
+// ExecutionOrderNote is a notification about an execution order.
+type ExecutionOrderNote struct {
+	db.Notification
+	ExecutionOrder *ExecutionOrder `json:"executionOrder"`
+}
+
+const (
+	TopicExecutionOrderComplete Topic = "ExecutionOrderComplete"
+	TopicExecutionOrderPaused   Topic = "ExecutionOrderPaused"
+)
+
+func newExecutionOrderNote(topic Topic, subject, details string, severity db.Severity, o *ExecutionOrder) *ExecutionOrderNote {
+	return &ExecutionOrderNote{
+		Notification:   db.NewNotification(NoteTypeExecution, topic, subject, details, severity),
+		ExecutionOrder: o,
+	}
+}
@@ -0,0 +1,1058 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/dex/order"
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// Private swaps settle a match with adaptor signatures instead of a hashed
+// timelock contract, so no secret hash is visible on either chain. The steps
+// are driven by the trade ticker like HTLC swaps, but the swap, redeem and
+// refund of each private match is handled individually.
+//
+//  1. NewlyMatched: Both parties send their public keys. The redeem key is
+//     from the toWallet and the refund key is from the fromWallet. The server
+//     relays the keys once it has both.
+//  2. The maker broadcasts their swap, which the taker audits.
+//  3. After the maker's swap has the required confirmations, the taker
+//     broadcasts their swap along with their unsigned redemption of the
+//     maker's swap.
+//  4. After the taker's swap has the required confirmations, the maker
+//     generates the adaptor secret and sends adaptor signatures for both
+//     redemptions. The taker validates them and responds with a public key
+//     tweaked adaptor signature for the maker's redemption.
+//  5. The maker redeems, which reveals the adaptor secret to the taker.
+//  6. The taker recovers the secret from the maker's redemption and redeems.
+//
+// If the counterparty stops responding, each party refunds their own swap
+// after its lock time. Redemption confirmations are not monitored for private
+// swaps, since the wallets' redemption confirmation methods require HTLC audit
+// info. A private match is confirmed once the server accepts the redeem.
+
+// privateStep is the next action to take for a private match.
+type privateStep uint8
+
+const (
+	privateSendKeys privateStep = iota + 1
+	privateSwap
+	privateSendSigs
+	privateRedeem
+	privateRefund
+	privateConfirm
+)
+
+// String returns a description of the step.
+func (s privateStep) String() string {
+	switch s {
+	case privateSendKeys:
+		return "send keys"
+	case privateSwap:
+		return "swap"
+	case privateSendSigs:
+		return "send adaptor signatures"
+	case privateRedeem:
+		return "redeem"
+	case privateRefund:
+		return "refund"
+	case privateConfirm:
+		return "confirm"
+	}
+	return "unknown"
+}
+
+// privateMatchStep pairs a match with its next private swap action.
+type privateMatchStep struct {
+	match *matchTracker
+	step  privateStep
+}
+
+// privateSwappers returns the trade's wallets as asset.PrivateSwappers.
+func (t *trackedTrade) privateSwappers() (from, to asset.PrivateSwapper, err error) {
+	from, ok := t.wallets.fromWallet.Wallet.(asset.PrivateSwapper)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s wallet does not support private swaps", t.wallets.fromWallet.Symbol)
+	}
+	to, ok = t.wallets.toWallet.Wallet.(asset.PrivateSwapper)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s wallet does not support private swaps", t.wallets.toWallet.Symbol)
+	}
+	return from, to, nil
+}
+
+// privateContracts returns our swap contract and the counterparty's swap
+// contract for the private match. The counterparty's keys are empty until
+// they are received.
+//
+// This method accesses match fields and MUST be called with the trackedTrade
+// mutex lock held for reads.
+func (t *trackedTrade) privateContracts(match *matchTracker) (ours, theirs *asset.PrivateContract) {
+	p := match.MetaData.Proof.Private
+	matchTime := match.matchTime()
+	ourLockTime, theirLockTime := t.lockTimeTaker, t.lockTimeMaker
+	if match.Side == order.Maker {
+		ourLockTime, theirLockTime = t.lockTimeMaker, t.lockTimeTaker
+	}
+	ourValue, theirValue := match.Quantity, calc.BaseToQuote(match.Rate, match.Quantity)
+	if !match.trade.Sell {
+		ourValue, theirValue = theirValue, ourValue
+	}
+	ours = &asset.PrivateContract{
+		LockTime:        uint64(matchTime.Add(ourLockTime).UTC().Unix()),
+		Value:           ourValue,
+		RedeemPublicKey: p.CounterRedeemKey,
+		RefundPublicKey: p.RefundKey,
+	}
+	theirs = &asset.PrivateContract{
+		LockTime:        uint64(matchTime.Add(theirLockTime).UTC().Unix()),
+		Value:           theirValue,
+		RedeemPublicKey: p.RedeemKey,
+		RefundPublicKey: p.CounterRefundKey,
+	}
+	return ours, theirs
+}
+
+// parseAdaptorPub parses the compressed public key of the adaptor secret.
+func parseAdaptorPub(b []byte) (*btcec.JacobianPoint, error) {
+	pub, err := btcec.ParsePubKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid adaptor public key: %w", err)
+	}
+	var pt btcec.JacobianPoint
+	pub.AsJacobian(&pt)
+	return &pt, nil
+}
+
+// parseAdaptorSecret parses the adaptor secret scalar.
+func parseAdaptorSecret(b []byte) (*btcec.ModNScalar, error) {
+	if len(b) != 32 {
+		return nil, fmt.Errorf("invalid adaptor secret length %d", len(b))
+	}
+	var secret btcec.ModNScalar
+	if overflow := secret.SetByteSlice(b); overflow {
+		return nil, errors.New("adaptor secret overflows the curve order")
+	}
+	return &secret, nil
+}
+
+// privateStep determines the next action for a private match. A zero
+// privateStep is returned if there is nothing to do. The ctx is used for
+// confirmation requests.
+//
+// This method accesses match fields and MUST be called with the trackedTrade
+// mutex lock held for reads.
+func (t *trackedTrade) privateStep(ctx context.Context, match *matchTracker, revoked bool) privateStep {
+	proof := &match.MetaData.Proof
+	p := proof.Private
+	if ticksGoverned, _ := match.exceptions(); ticksGoverned {
+		return 0
+	}
+	maker := match.Side == order.Maker
+
+	ourSwap, ourRedeem := proof.TakerSwap, proof.TakerRedeem
+	if maker {
+		ourSwap, ourRedeem = proof.MakerSwap, proof.MakerRedeem
+	}
+
+	// Once our redeem is broadcast, the match is done when the server
+	// accepts it, or right away if the server can't be told.
+	if len(ourRedeem) > 0 {
+		if revoked || t.isSelfGoverned() {
+			return privateConfirm
+		}
+		return 0
+	}
+
+	ours, theirs := t.privateContracts(match)
+
+	// The taker's swap is spent if the maker has redeemed, so a taker in
+	// MakerRedeemed redeems instead.
+	if len(ourSwap) > 0 && len(proof.RefundCoin) == 0 && match.refundErr == nil &&
+		(maker || match.Status < order.MakerRedeemed) &&
+		time.Now().Unix() >= int64(ours.LockTime) {
+		return privateRefund
+	}
+
+	// counterConfirmed checks that the counterparty's swap has the required
+	// confirmations.
+	counterConfirmed := func(coinID order.CoinID) bool {
+		_, to, err := t.privateSwappers()
+		if err != nil {
+			t.dc.log.Errorf("Private match %s: %v", match, err)
+			return false
+		}
+		confs, spent, err := to.PrivateSwapConfirmations(ctx, dex.Bytes(coinID), theirs, match.matchTime())
+		if err != nil {
+			t.dc.log.Errorf("Error getting confirmations for private swap %s (%s) for match %s: %v",
+				coinIDString(t.wallets.toWallet.AssetID, coinID), t.wallets.toWallet.Symbol, match, err)
+			return false
+		}
+		if spent {
+			t.dc.log.Errorf("Counterparty's private swap %s (%s) for match %s is already spent",
+				coinIDString(t.wallets.toWallet.AssetID, coinID), t.wallets.toWallet.Symbol, match)
+			return false
+		}
+		if was := match.setCounterConfirms(int64(confs)); was != int64(confs) {
+			t.dc.log.Debugf("Counterparty's private swap for match %s has %d of %d required confirmations",
+				match, confs, t.metaData.ToSwapConf)
+		}
+		return confs >= t.metaData.ToSwapConf
+	}
+
+	switch match.Status {
+	case order.NewlyMatched:
+		if revoked || match.swapErr != nil {
+			return 0
+		}
+		if !match.privateKeysSent {
+			return privateSendKeys
+		}
+		if maker && len(p.CounterRedeemKey) > 0 {
+			return privateSwap
+		}
+	case order.MakerSwapCast:
+		if maker || revoked || match.swapErr != nil || len(proof.MakerSwap) == 0 {
+			return 0
+		}
+		if _, checkServerRevoke := match.exceptions(); checkServerRevoke {
+			return 0
+		}
+		if counterConfirmed(proof.MakerSwap) {
+			return privateSwap
+		}
+	case order.TakerSwapCast:
+		if revoked {
+			return 0
+		}
+		if !maker {
+			if len(p.MakerRefundSig) > 0 && (len(p.TakerRefundSig) == 0 || !match.privateSigsSent) {
+				return privateSendSigs
+			}
+			return 0
+		}
+		switch {
+		case len(p.TakerRefundSig) > 0:
+			return privateRedeem
+		case len(p.MakerRedeemSig) > 0:
+			if !match.privateSigsSent {
+				return privateSendSigs
+			}
+		case len(p.CounterUnsignedRedeem) > 0 && len(proof.TakerSwap) > 0:
+			if counterConfirmed(proof.TakerSwap) {
+				return privateSendSigs
+			}
+		}
+	case order.MakerRedeemed:
+		if !maker && len(p.CounterRedeemTx) > 0 {
+			return privateRedeem
+		}
+	}
+	return 0
+}
+
+// privateMatchSteps takes the private swap actions for the matches.
+//
+// This method modifies match fields and MUST be called with the trackedTrade
+// mutex lock held for writes.
+func (c *Core) privateMatchSteps(t *trackedTrade, steps []*privateMatchStep, errs *errorSet) {
+	for _, w := range []*xcWallet{t.wallets.fromWallet, t.wallets.toWallet} {
+		didUnlock, err := w.refreshUnlock()
+		if err != nil { // Just log it and try anyway.
+			c.log.Errorf("refreshUnlock error for %s private swap: %v", w.Symbol, err)
+		}
+		if didUnlock {
+			c.log.Infof("Unexpected unlock needed for the %s wallet for a private swap", w.Symbol)
+		}
+	}
+	for _, s := range steps {
+		c.log.Debugf("Private match %s for order %v (%v): %s", s.match, t.ID(), s.match.Side, s.step)
+		var err error
+		switch s.step {
+		case privateSendKeys:
+			err = c.privateSendKeys(t, s.match)
+		case privateSwap:
+			err = c.privateSwap(t, s.match)
+		case privateSendSigs:
+			err = c.privateSendSigs(t, s.match)
+		case privateRedeem:
+			err = c.privateRedeem(t, s.match)
+		case privateRefund:
+			err = c.privateRefund(t, s.match)
+		case privateConfirm:
+			s.match.Status = order.MatchConfirmed
+			err = t.db.UpdateMatch(&s.match.MetaMatch)
+		}
+		if err != nil {
+			errs.add("private match %s %s error: %v", s.match, s.step, err)
+		}
+	}
+}
+
+// privateSendKeys generates our keys for the private match if necessary and
+// sends them to the server.
+//
+// This method modifies match fields and MUST be called with the trackedTrade
+// mutex lock held for writes.
+func (c *Core) privateSendKeys(t *trackedTrade, match *matchTracker) error {
+	p := match.MetaData.Proof.Private
+	if len(p.RedeemKey) == 0 {
+		from, to, err := t.privateSwappers()
+		if err != nil {
+			return err
+		}
+		redeemKey, err := to.PrivateSwapPubKey()
+		if err != nil {
+			return fmt.Errorf("error getting %s redeem key: %w", t.wallets.toWallet.Symbol, err)
+		}
+		refundKey, err := from.PrivateSwapPubKey()
+		if err != nil {
+			return fmt.Errorf("error getting %s refund key: %w", t.wallets.fromWallet.Symbol, err)
+		}
+		p.RedeemKey, p.RefundKey = redeemKey, refundKey
+		if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+			return fmt.Errorf("error storing keys: %w", err)
+		}
+	}
+	keys := &msgjson.PrivateKeys{
+		OrderID:   t.ID().Bytes(),
+		MatchID:   match.MatchID[:],
+		RedeemKey: p.RedeemKey,
+		RefundKey: p.RefundKey,
+	}
+	c.sendPrivateAsync(t, match, msgjson.PrivateKeysRoute, keys, &match.sendingPrivateAsync, "", func([]byte) {
+		match.privateKeysSent = true
+	})
+	return nil
+}
+
+// privateSwap broadcasts our swap for the private match. The taker also
+// generates their unsigned redemption of the maker's swap.
+//
+// This method modifies match fields and MUST be called with the trackedTrade
+// mutex lock held for writes.
+func (c *Core) privateSwap(t *trackedTrade, match *matchTracker) error {
+	from, to, err := t.privateSwappers()
+	if err != nil {
+		return err
+	}
+	proof := &match.MetaData.Proof
+	p := proof.Private
+	ours, theirs := t.privateContracts(match)
+	if match.Side == order.Taker && len(p.UnsignedRedeem) == 0 {
+		p.UnsignedRedeem, err = to.GenerateUnsignedRedeemTx(proof.MakerSwap, theirs, t.redeemFee())
+		if err != nil {
+			return fmt.Errorf("error generating unsigned redeem: %w", err)
+		}
+		if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+			return fmt.Errorf("error storing unsigned redeem: %w", err)
+		}
+	}
+
+	feeRate := t.bestSwapGroupFeeRate([]*matchTracker{match})
+	if feeRate == 0 {
+		return errors.New("swap cannot proceed with a zero fee rate")
+	}
+	inputs, err := t.swapInputs()
+	if err != nil {
+		return err
+	}
+	if t.dc.IsDown() {
+		return fmt.Errorf("not broadcasting swap while DEX %s connection is down (could be revoked)", t.dc.acct.host)
+	}
+	lockChange := t.lockSwapChange(1)
+
+	fromWallet := t.wallets.fromWallet
+	ui := fromWallet.Info().UnitInfo
+	receipts, change, txData, fees, err := from.SwapPrivate(&asset.PrivateSwaps{
+		Version:    t.metaData.FromVersion,
+		Inputs:     inputs,
+		Contracts:  []*asset.PrivateContract{ours},
+		FeeRate:    feeRate,
+		LockChange: lockChange,
+		Options:    t.options,
+	})
+	if err != nil {
+		// The server will revoke the match if the swap is not broadcast in
+		// time, so just wait for the next attempt.
+		match.suspectSwap = true
+		match.swapErrCount++
+		match.delayTicks(t.dc.ticker.Dur() * 3 / 4)
+		subject, details := c.formatDetails(TopicSwapSendError, ui.ConventionalString(ours.Value),
+			ui.Conventional.Unit, makeOrderToken(t.token()))
+		t.notify(newOrderNote(TopicSwapSendError, subject, details, db.ErrorLevel, t.coreOrderInternal()))
+		return fmt.Errorf("error sending %s private swap transaction: %w", fromWallet.Symbol, err)
+	}
+	if len(receipts) != 1 {
+		return fmt.Errorf("expected 1 private swap receipt, got %d", len(receipts))
+	}
+	coin := receipts[0].Coin()
+	c.log.Infof("Broadcasted private swap %v (%s), value = %d, refundable at %v, for order %v, match %v",
+		coin, fromWallet.Symbol, coin.Value(), receipts[0].Expiration(), t.ID(), match)
+
+	t.recordSwapChange(change, fees, lockChange)
+
+	p.SwapTx = txData
+	if match.Side == order.Taker {
+		proof.TakerSwap = []byte(coin.ID())
+		match.Status = order.TakerSwapCast
+	} else {
+		proof.MakerSwap = []byte(coin.ID())
+		match.Status = order.MakerSwapCast
+	}
+	if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+		c.log.Errorf("Error storing private swap details in database for match %s, coin %s: %v",
+			match, coin, err)
+	}
+	c.sendPrivateInit(t, match)
+
+	subject, details := c.formatDetails(TopicSwapsInitiated, ui.ConventionalString(ours.Value),
+		ui.Conventional.Unit, makeOrderToken(t.token()))
+	t.notify(newOrderNote(TopicSwapsInitiated, subject, details, db.Poke, t.coreOrderInternal()))
+	return nil
+}
+
+// sendPrivateInit sends the 'private_init' request for our swap.
+//
+// This method accesses match fields and MUST be called with the trackedTrade
+// mutex lock held for reads.
+func (c *Core) sendPrivateInit(t *trackedTrade, match *matchTracker) {
+	proof := &match.MetaData.Proof
+	init := &msgjson.PrivateInit{
+		OrderID: t.ID().Bytes(),
+		MatchID: match.MatchID[:],
+		CoinID:  []byte(proof.MakerSwap),
+		TxData:  proof.Private.SwapTx,
+	}
+	if match.Side == order.Taker {
+		init.CoinID = []byte(proof.TakerSwap)
+		init.UnsignedRedeem = proof.Private.UnsignedRedeem
+	}
+	c.sendPrivateAsync(t, match, msgjson.PrivateInitRoute, init, &match.sendingInitAsync, TopicInitError, func(sig []byte) {
+		auth := &match.MetaData.Proof.Auth
+		auth.InitSig = sig
+		auth.InitStamp = uint64(time.Now().UnixMilli())
+	})
+}
+
+// privateAdaptorSecret generates an adaptor secret that is valid for both
+// redemptions.
+func privateAdaptorSecret(from, to asset.PrivateSwapper, unsignedRedeem, counterUnsignedRedeem []byte,
+	ours, theirs *asset.PrivateContract) (*btcec.ModNScalar, error) {
+
+	var secret btcec.ModNScalar
+	for i := 0; i < 100; i++ {
+		secret.SetByteSlice(encode.RandomBytes(32))
+		if ok, err := to.ValidateAdaptorSecret(&secret, unsignedRedeem, theirs); err != nil {
+			return nil, fmt.Errorf("error validating adaptor secret: %w", err)
+		} else if !ok {
+			continue
+		}
+		if ok, err := from.ValidateAdaptorSecret(&secret, counterUnsignedRedeem, ours); err != nil {
+			return nil, fmt.Errorf("error validating adaptor secret: %w", err)
+		} else if !ok {
+			continue
+		}
+		return &secret, nil
+	}
+	return nil, errors.New("no valid adaptor secret found")
+}
+
+// privateSendSigs generates our adaptor signatures for the private match if
+// necessary and sends them to the server. The maker generates the adaptor
+// secret and signs both redemptions. The taker signs the maker's redemption
+// with a public key tweaked adaptor.
+//
+// This method modifies match fields and MUST be called with the trackedTrade
+// mutex lock held for writes.
+func (c *Core) privateSendSigs(t *trackedTrade, match *matchTracker) error {
+	from, to, err := t.privateSwappers()
+	if err != nil {
+		return err
+	}
+	proof := &match.MetaData.Proof
+	p := proof.Private
+	ours, theirs := t.privateContracts(match)
+
+	sigs := &msgjson.AdaptorSigs{
+		OrderID: t.ID().Bytes(),
+		MatchID: match.MatchID[:],
+	}
+	if match.Side == order.Maker {
+		if len(p.MakerRedeemSig) == 0 {
+			unsignedRedeem, err := to.GenerateUnsignedRedeemTx(proof.TakerSwap, theirs, t.redeemFee())
+			if err != nil {
+				return fmt.Errorf("error generating unsigned redeem: %w", err)
+			}
+			secret, err := privateAdaptorSecret(from, to, unsignedRedeem, p.CounterUnsignedRedeem, ours, theirs)
+			if err != nil {
+				return err
+			}
+			redeemSig, err := to.GeneratePrivateKeyTweakedAdaptor(unsignedRedeem, theirs, secret, true)
+			if err != nil {
+				return fmt.Errorf("error generating redeem adaptor: %w", err)
+			}
+			refundSig, err := from.GeneratePrivateKeyTweakedAdaptor(p.CounterUnsignedRedeem, ours, secret, false)
+			if err != nil {
+				return fmt.Errorf("error generating refund adaptor: %w", err)
+			}
+			var pt btcec.JacobianPoint
+			btcec.ScalarBaseMultNonConst(secret, &pt)
+			pt.ToAffine()
+			secretB := secret.Bytes()
+			p.UnsignedRedeem = unsignedRedeem
+			p.AdaptorSecret = secretB[:]
+			p.AdaptorPub = btcec.NewPublicKey(&pt.X, &pt.Y).SerializeCompressed()
+			p.MakerRedeemSig, p.MakerRefundSig = redeemSig, refundSig
+			if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+				return fmt.Errorf("error storing adaptor signatures: %w", err)
+			}
+		}
+		sigs.AdaptorPub = p.AdaptorPub
+		sigs.RedeemSig = p.MakerRedeemSig
+		sigs.RefundSig = p.MakerRefundSig
+		sigs.UnsignedRedeem = p.UnsignedRedeem
+	} else {
+		if len(p.TakerRefundSig) == 0 {
+			adaptorPub, err := parseAdaptorPub(p.AdaptorPub)
+			if err != nil {
+				return err
+			}
+			p.TakerRefundSig, err = from.GeneratePublicKeyTweakedAdaptor(p.CounterUnsignedRedeem, ours, adaptorPub)
+			if err != nil {
+				return fmt.Errorf("error generating adaptor signature: %w", err)
+			}
+			if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+				return fmt.Errorf("error storing adaptor signature: %w", err)
+			}
+		}
+		sigs.RefundSig = p.TakerRefundSig
+	}
+	c.sendPrivateAsync(t, match, msgjson.AdaptorSigsRoute, sigs, &match.sendingPrivateAsync, "", func([]byte) {
+		match.privateSigsSent = true
+	})
+	return nil
+}
+
+// privateRedeem redeems the counterparty's swap. The maker uses the adaptor
+// secret they generated. The taker recovers the secret from the maker's
+// redemption.
+//
+// This method modifies match fields and MUST be called with the trackedTrade
+// mutex lock held for writes.
+func (c *Core) privateRedeem(t *trackedTrade, match *matchTracker) error {
+	from, to, err := t.privateSwappers()
+	if err != nil {
+		return err
+	}
+	redeemWallet := t.wallets.toWallet
+	if !redeemWallet.connected() {
+		return errWalletNotConnected
+	}
+	proof := &match.MetaData.Proof
+	p := proof.Private
+	ours, theirs := t.privateContracts(match)
+
+	var secret *btcec.ModNScalar
+	adaptorSig := p.TakerRefundSig
+	if match.Side == order.Maker {
+		secret, err = parseAdaptorSecret(p.AdaptorSecret)
+	} else {
+		adaptorSig = p.MakerRefundSig
+		var adaptorPub *btcec.JacobianPoint
+		adaptorPub, err = parseAdaptorPub(p.AdaptorPub)
+		if err != nil {
+			return err
+		}
+		secret, err = from.RecoverAdaptorSecret(p.CounterRedeemTx, p.TakerRefundSig, p.MakerRedeemSig, adaptorPub, ours)
+		if err == nil {
+			secretB := secret.Bytes()
+			p.AdaptorSecret = secretB[:]
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error getting adaptor secret: %w", err)
+	}
+
+	ui := redeemWallet.Info().UnitInfo
+	outCoin, fees, txData, err := to.RedeemPrivate(theirs, p.UnsignedRedeem, adaptorSig, secret)
+	if err != nil {
+		match.suspectRedeem = true
+		match.redeemErrCount++
+		tickInterval := t.dc.ticker.Dur()
+		if tickInterval == 0 {
+			tickInterval = defaultTickInterval
+		}
+		match.delayTicks(tickInterval * 3 / 4)
+		subject, details := c.formatDetails(TopicRedemptionError, ui.ConventionalString(theirs.Value),
+			ui.Conventional.Unit, makeOrderToken(t.token()))
+		t.notify(newOrderNote(TopicRedemptionError, subject, details, db.ErrorLevel, t.coreOrderInternal()))
+		return fmt.Errorf("error sending private redeem transaction: %w", err)
+	}
+
+	c.log.Infof("Broadcasted private redeem transaction for order %v, match %v, paying to %s (%s)",
+		t.ID(), match, outCoin, redeemWallet.Symbol)
+
+	if _, dynamic := redeemWallet.Wallet.(asset.DynamicSwapper); !dynamic {
+		t.metaData.RedemptionFeesPaid += fees
+	}
+	if err := t.db.UpdateOrderMetaData(t.ID(), t.metaData); err != nil {
+		c.log.Errorf("Error updating order metadata for order %s: %v", t.ID(), err)
+	}
+
+	p.RedeemTx = txData
+	if match.Side == order.Taker {
+		proof.TakerRedeem = []byte(outCoin.ID())
+		match.Status = order.MatchComplete
+	} else {
+		proof.MakerRedeem = []byte(outCoin.ID())
+		match.Status = order.MakerRedeemed
+	}
+	to.MarkPrivateSwapComplete(theirs, true)
+	from.MarkPrivateSwapComplete(ours, false)
+	if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+		c.log.Errorf("Error storing private redeem details in database for match %s, coin %s: %v",
+			match, outCoin, err)
+	}
+	c.sendPrivateRedeem(t, match)
+
+	subject, details := c.formatDetails(TopicMatchComplete, ui.ConventionalString(theirs.Value),
+		ui.Conventional.Unit, makeOrderToken(t.token()))
+	t.notify(newOrderNote(TopicMatchComplete, subject, details, db.Poke, t.coreOrderInternal()))
+	return nil
+}
+
+// sendPrivateRedeem sends the 'private_redeem' request for our redemption.
+// The match is confirmed when the server accepts it.
+//
+// This method accesses match fields and MUST be called with the trackedTrade
+// mutex lock held for reads.
+func (c *Core) sendPrivateRedeem(t *trackedTrade, match *matchTracker) {
+	proof := &match.MetaData.Proof
+	redeem := &msgjson.PrivateRedeem{
+		OrderID: t.ID().Bytes(),
+		MatchID: match.MatchID[:],
+		CoinID:  []byte(proof.MakerRedeem),
+		TxData:  proof.Private.RedeemTx,
+	}
+	if match.Side == order.Taker {
+		redeem.CoinID = []byte(proof.TakerRedeem)
+	}
+	c.sendPrivateAsync(t, match, msgjson.PrivateRedeemRoute, redeem, &match.sendingRedeemAsync, TopicReportRedeemError, func(sig []byte) {
+		auth := &match.MetaData.Proof.Auth
+		auth.RedeemSig = sig
+		auth.RedeemStamp = uint64(time.Now().UnixMilli())
+		match.matchCompleteSent = true
+		match.Status = order.MatchConfirmed
+		subject, details := t.formatDetails(TopicRedemptionConfirmed, match.token(), makeOrderToken(t.token()))
+		t.notify(newMatchNote(TopicRedemptionConfirmed, subject, details, db.Success, t, match))
+	})
+}
+
+// privateRefund refunds our swap after its lock time.
+//
+// This method modifies match fields and MUST be called with the trackedTrade
+// mutex lock held for writes.
+func (c *Core) privateRefund(t *trackedTrade, match *matchTracker) error {
+	from, _, err := t.privateSwappers()
+	if err != nil {
+		return err
+	}
+	proof := &match.MetaData.Proof
+	ours, _ := t.privateContracts(match)
+	refundWallet := t.wallets.fromWallet
+	swapCoinID := dex.Bytes(proof.TakerSwap)
+	if match.Side == order.Maker {
+		swapCoinID = dex.Bytes(proof.MakerSwap)
+	}
+	swapCoinString := coinIDString(refundWallet.AssetID, swapCoinID)
+	c.log.Infof("Refunding %s private swap %s for match %s", refundWallet.Symbol, swapCoinString, match)
+
+	ui := refundWallet.Info().UnitInfo
+	refundCoin, err := from.RefundPrivate(swapCoinID, ours, c.feeSuggestionAny(refundWallet.AssetID))
+	if err != nil {
+		if errors.Is(err, asset.CoinNotFoundError) {
+			// There is no redemption search for private swaps. The adaptor
+			// secret can be recovered from the counterparty's redemption of
+			// our swap.
+			match.refundErr = err
+			return fmt.Errorf("private swap %s (%s) already spent: %w", swapCoinString, refundWallet.Symbol, err)
+		}
+		match.delayTicks(time.Minute * 5)
+		subject, details := c.formatDetails(TopicRefundFailure, ui.ConventionalString(ours.Value),
+			ui.Conventional.Unit, makeOrderToken(t.token()))
+		t.notify(newOrderNote(TopicRefundFailure, subject, details, db.ErrorLevel, t.coreOrderInternal()))
+		return fmt.Errorf("error sending refund tx for private swap %s: %w", swapCoinString, err)
+	}
+
+	from.MarkPrivateSwapComplete(ours, false)
+	proof.RefundCoin = []byte(refundCoin)
+	proof.SelfRevoked = true
+	if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+		c.log.Errorf("Error storing refund in database for match %s: %v", match, err)
+	}
+	subject, details := c.formatDetails(TopicMatchesRefunded, ui.ConventionalString(ours.Value),
+		ui.Conventional.Unit, makeOrderToken(t.token()))
+	t.notify(newOrderNote(TopicMatchesRefunded, subject, details, db.WarningLevel, t.coreOrderInternal()))
+	return nil
+}
+
+// sendPrivateAsync starts a goroutine to send a private swap request for the
+// match. The acked function is called with the trackedTrade mutex locked when
+// the server accepts the request. A DuplicateRequestError is considered an
+// acknowledgement, with no sig, for the keys and adaptor signatures. Sends a
+// notification with the errTopic if it is not empty and an error occurs,
+// otherwise the error is logged. The sending flag prevents concurrent
+// requests.
+func (c *Core) sendPrivateAsync(t *trackedTrade, match *matchTracker, route string, signable msgjson.Signable,
+	sending *uint32, errTopic Topic, acked func(sig []byte)) {
+
+	if !atomic.CompareAndSwapUint32(sending, 0, 1) {
+		return
+	}
+
+	c.log.Debugf("Sending '%s' request to DEX %s for private match %s", route, t.dc.acct.host, match)
+
+	c.wg.Add(1) // So Core does not shut down until we're done with this request.
+	go func() {
+		defer c.wg.Done() // bottom of the stack
+		var err error
+		defer func() {
+			atomic.StoreUint32(sending, 0)
+			if err == nil {
+				return
+			}
+			if errTopic == "" {
+				c.log.Errorf("Private match %s: %v", match, err)
+				return
+			}
+			subject, details := c.formatDetails(errTopic, match, err)
+			t.notify(newOrderNote(errTopic, subject, details, db.ErrorLevel, t.coreOrder()))
+		}()
+
+		ack := new(msgjson.Acknowledgement)
+		timeout := max(t.broadcastTimeout()/4, time.Minute)
+		err = t.dc.signAndRequest(signable, route, ack, timeout)
+		if err != nil {
+			var msgErr *msgjson.Error
+			if errors.As(err, &msgErr) {
+				switch {
+				case msgErr.Code == msgjson.DuplicateRequestError &&
+					(route == msgjson.PrivateKeysRoute || route == msgjson.AdaptorSigsRoute):
+					c.log.Debugf("DEX %s already has our '%s' request for match %s", t.dc.acct.host, route, match)
+					t.mtx.Lock()
+					acked(nil)
+					t.mtx.Unlock()
+					err = nil
+					return
+				case msgErr.Code == msgjson.RPCUnknownMatch:
+					t.mtx.Lock()
+					c.log.Warnf("DEX %s did not report active private match %s on order %s - assuming revoked, status %v.",
+						t.dc.acct.host, match, t.ID(), match.Status)
+					match.MetaData.Proof.SelfRevoked = true
+					if err := c.db.UpdateMatch(&match.MetaMatch); err != nil {
+						c.log.Errorf("Failed to update missing/revoked match: %v", err)
+					}
+					t.mtx.Unlock()
+				}
+			}
+			err = fmt.Errorf("error sending '%s' message: %w", route, err)
+			return
+		}
+
+		err = t.dc.acct.checkSig(signable.Serialize(), ack.Sig)
+		if err != nil {
+			err = fmt.Errorf("'%s' ack signature error: %v", route, err)
+			return
+		}
+
+		c.log.Debugf("Received valid ack for '%s' request for match %s", route, match)
+
+		t.mtx.Lock()
+		acked(ack.Sig)
+		err = t.db.UpdateMatch(&match.MetaMatch)
+		if err != nil {
+			err = fmt.Errorf("error storing '%s' ack sig in database: %v", route, err)
+		}
+		t.mtx.Unlock()
+	}()
+}
+
+// privateMatch finds the private match for a DEX-originating private swap
+// request and checks the server's signature.
+//
+// This method MUST be called with the trackedTrade mutex lock held for reads.
+func (t *trackedTrade) privateMatch(midB []byte, signable msgjson.Signable, sig []byte) (*matchTracker, error) {
+	var mid order.MatchID
+	copy(mid[:], midB)
+	match, found := t.matches[mid]
+	if !found {
+		return nil, fmt.Errorf("match %v not found for order %s", mid, t.ID())
+	}
+	if match.MetaData.Proof.Private == nil {
+		return nil, fmt.Errorf("match %v for order %s is not a private swap", mid, t.ID())
+	}
+	if err := t.dc.acct.checkSig(signable.Serialize(), sig); err != nil {
+		// Log, but don't quit.
+		t.dc.log.Warnf("Server signature error for private match %s: %v", match, err)
+	}
+	return match, nil
+}
+
+// processCounterpartyKeys stores the counterparty's keys for a private match.
+func (t *trackedTrade) processCounterpartyKeys(msgID uint64, keys *msgjson.PrivateKeys) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	match, err := t.privateMatch(keys.MatchID, keys, keys.Sig)
+	if err != nil {
+		return err
+	}
+	if len(keys.RedeemKey) == 0 || len(keys.RefundKey) == 0 {
+		return fmt.Errorf("missing counterparty keys for match %s", match)
+	}
+	p := match.MetaData.Proof.Private
+	if len(p.CounterRedeemKey) == 0 {
+		if match.Status != order.NewlyMatched {
+			return fmt.Errorf("counterparty keys received for match %s in status %v", match, match.Status)
+		}
+		p.CounterRedeemKey, p.CounterRefundKey = keys.RedeemKey, keys.RefundKey
+		if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+			t.dc.log.Errorf("Error updating database for match %s: %v", match, err)
+		}
+	}
+	return t.dc.ack(msgID, match.MatchID, keys)
+}
+
+// processPrivateAudit audits the counterparty's private swap. The maker also
+// receives the taker's unsigned redemption of the maker's swap.
+func (t *trackedTrade) processPrivateAudit(msgID uint64, audit *msgjson.PrivateAudit) error {
+	t.mtx.RLock()
+	match, err := t.privateMatch(audit.MatchID, audit, audit.Sig)
+	if err != nil {
+		t.mtx.RUnlock()
+		return err
+	}
+	p := match.MetaData.Proof.Private
+	maker := match.Side == order.Maker
+	wantStatus := order.NewlyMatched
+	if maker {
+		wantStatus = order.MakerSwapCast
+	}
+	status := match.Status
+	_, theirs := t.privateContracts(match)
+	haveKeys := len(p.CounterRefundKey) > 0
+	t.mtx.RUnlock()
+
+	switch {
+	case status > wantStatus:
+		// Already audited. Just acknowledge.
+		return t.dc.ack(msgID, match.MatchID, audit)
+	case status < wantStatus:
+		return fmt.Errorf("private audit received for match %s in status %v", match, status)
+	case !haveKeys:
+		return fmt.Errorf("private audit received before counterparty keys for match %s", match)
+	case maker && len(audit.UnsignedRedeem) == 0:
+		return fmt.Errorf("private audit for match %s is missing the taker's unsigned redeem", match)
+	}
+
+	_, to, err := t.privateSwappers()
+	if err != nil {
+		return err
+	}
+	contractID := coinIDString(t.wallets.toWallet.AssetID, audit.CoinID)
+	if err := to.AuditPrivateContract(audit.CoinID, audit.TxData, theirs, true); err != nil {
+		return fmt.Errorf("failed to audit private swap %v (%s) for match %s: %w",
+			contractID, t.wallets.toWallet.Symbol, match, err)
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if match.Status != wantStatus {
+		return fmt.Errorf("private match %s status changed to %v during audit", match, match.Status)
+	}
+	proof := &match.MetaData.Proof
+	if maker {
+		p.CounterUnsignedRedeem = audit.UnsignedRedeem
+		proof.TakerSwap = order.CoinID(audit.CoinID)
+		match.Status = order.TakerSwapCast
+	} else {
+		proof.MakerSwap = order.CoinID(audit.CoinID)
+		match.Status = order.MakerSwapCast
+	}
+	proof.CounterTxData = audit.TxData
+	proof.Auth.AuditStamp, proof.Auth.AuditSig = audit.Time, audit.Sig
+	t.notify(newMatchNote(TopicAudit, "", "", db.Data, t, match))
+	if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+		t.dc.log.Errorf("Error updating database for match %s: %v", match, err)
+	}
+
+	t.dc.log.Infof("Audited private swap (%s: %v) for order %s, match %s",
+		t.wallets.toWallet.Symbol, contractID, t.ID(), match)
+
+	return t.dc.ack(msgID, match.MatchID, audit)
+}
+
+// processCounterpartyAdaptorSigs validates and stores the counterparty's
+// adaptor signatures.
+func (t *trackedTrade) processCounterpartyAdaptorSigs(msgID uint64, sigs *msgjson.AdaptorSigs) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	match, err := t.privateMatch(sigs.MatchID, sigs, sigs.Sig)
+	if err != nil {
+		return err
+	}
+	if match.Status != order.TakerSwapCast {
+		return fmt.Errorf("adaptor signatures received for match %s in status %v", match, match.Status)
+	}
+	p := match.MetaData.Proof.Private
+	if match.Side == order.Maker {
+		if len(p.MakerRedeemSig) == 0 {
+			return fmt.Errorf("taker's adaptor signature received before ours for match %s", match)
+		}
+		if len(p.TakerRefundSig) == 0 {
+			// The signature is validated when it is used to redeem.
+			p.TakerRefundSig = sigs.RefundSig
+		}
+	} else if len(p.MakerRefundSig) == 0 {
+		from, to, err := t.privateSwappers()
+		if err != nil {
+			return err
+		}
+		adaptorPub, err := parseAdaptorPub(sigs.AdaptorPub)
+		if err != nil {
+			return err
+		}
+		ours, theirs := t.privateContracts(match)
+		if ok, err := to.ValidateAdaptorSig(p.UnsignedRedeem, sigs.RefundSig, adaptorPub, theirs, false); err != nil {
+			return fmt.Errorf("error validating maker's refund adaptor signature: %w", err)
+		} else if !ok {
+			return fmt.Errorf("invalid maker's refund adaptor signature for match %s", match)
+		}
+		if ok, err := from.ValidateAdaptorSig(sigs.UnsignedRedeem, sigs.RedeemSig, adaptorPub, ours, true); err != nil {
+			return fmt.Errorf("error validating maker's redeem adaptor signature: %w", err)
+		} else if !ok {
+			return fmt.Errorf("invalid maker's redeem adaptor signature for match %s", match)
+		}
+		p.AdaptorPub = sigs.AdaptorPub
+		p.MakerRedeemSig, p.MakerRefundSig = sigs.RedeemSig, sigs.RefundSig
+		p.CounterUnsignedRedeem = sigs.UnsignedRedeem
+	}
+	if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+		t.dc.log.Errorf("Error updating database for match %s: %v", match, err)
+	}
+	return t.dc.ack(msgID, match.MatchID, sigs)
+}
+
+// processPrivateRedemption stores the counterparty's redemption. The taker
+// recovers the adaptor secret from the maker's redemption transaction.
+func (t *trackedTrade) processPrivateRedemption(msgID uint64, redemption *msgjson.PrivateRedemption) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	match, err := t.privateMatch(redemption.MatchID, redemption, redemption.Sig)
+	if err != nil {
+		return err
+	}
+	proof := &match.MetaData.Proof
+	if match.Side == order.Taker {
+		switch {
+		case match.Status == order.TakerSwapCast:
+			if len(redemption.TxData) == 0 {
+				return fmt.Errorf("maker's redemption for match %s is missing the transaction", match)
+			}
+			proof.Private.CounterRedeemTx = redemption.TxData
+			proof.MakerRedeem = order.CoinID(redemption.CoinID)
+			match.Status = order.MakerRedeemed
+		case match.Status < order.TakerSwapCast:
+			return fmt.Errorf("maker redemption received at incorrect step %v", match.Status)
+		}
+	} else {
+		if len(proof.MakerRedeem) == 0 {
+			return fmt.Errorf("redemption request received as maker for match %s before redeeming", match)
+		}
+		proof.TakerRedeem = order.CoinID(redemption.CoinID)
+	}
+	proof.Auth.RedemptionSig = redemption.Sig
+	proof.Auth.RedemptionStamp = redemption.Time
+	if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+		t.dc.log.Errorf("Error updating database for match %s: %v", match, err)
+	}
+	return t.dc.ack(msgID, match.MatchID, redemption)
+}
+
+// handleCounterpartyKeysRoute handles the DEX-originating counterparty_keys
+// request, which relays the counterparty's keys for a private swap.
+func handleCounterpartyKeysRoute(c *Core, dc *dexConnection, msg *msgjson.Message) error {
+	keys := new(msgjson.PrivateKeys)
+	if err := msg.Unmarshal(keys); err != nil {
+		return fmt.Errorf("counterparty_keys request parsing error: %w", err)
+	}
+	return handlePrivateRequest(c, dc, keys.OrderID, msg, func(t *trackedTrade) error {
+		return t.processCounterpartyKeys(msg.ID, keys)
+	})
+}
+
+// handlePrivateAuditRoute handles the DEX-originating private_audit request,
+// which relays the counterparty's private swap.
+func handlePrivateAuditRoute(c *Core, dc *dexConnection, msg *msgjson.Message) error {
+	audit := new(msgjson.PrivateAudit)
+	if err := msg.Unmarshal(audit); err != nil {
+		return fmt.Errorf("private_audit request parsing error: %w", err)
+	}
+	return handlePrivateRequest(c, dc, audit.OrderID, msg, func(t *trackedTrade) error {
+		return t.processPrivateAudit(msg.ID, audit)
+	})
+}
+
+// handleCounterpartyAdaptorSigsRoute handles the DEX-originating
+// counterparty_adaptor_sigs request, which relays the counterparty's adaptor
+// signatures.
+func handleCounterpartyAdaptorSigsRoute(c *Core, dc *dexConnection, msg *msgjson.Message) error {
+	sigs := new(msgjson.AdaptorSigs)
+	if err := msg.Unmarshal(sigs); err != nil {
+		return fmt.Errorf("counterparty_adaptor_sigs request parsing error: %w", err)
+	}
+	return handlePrivateRequest(c, dc, sigs.OrderID, msg, func(t *trackedTrade) error {
+		return t.processCounterpartyAdaptorSigs(msg.ID, sigs)
+	})
+}
+
+// handlePrivateRedemptionRoute handles the DEX-originating private_redemption
+// request, which relays the counterparty's private swap redemption.
+func handlePrivateRedemptionRoute(c *Core, dc *dexConnection, msg *msgjson.Message) error {
+	redemption := new(msgjson.PrivateRedemption)
+	if err := msg.Unmarshal(redemption); err != nil {
+		return fmt.Errorf("private_redemption request parsing error: %w", err)
+	}
+	return handlePrivateRequest(c, dc, redemption.OrderID, msg, func(t *trackedTrade) error {
+		return t.processPrivateRedemption(msg.ID, redemption)
+	})
+}
+
+// handlePrivateRequest finds the trade for a DEX-originating private swap
+// request, processes the request, and schedules a tick for the trade.
+func handlePrivateRequest(c *Core, dc *dexConnection, oidB []byte, msg *msgjson.Message, process func(*trackedTrade) error) error {
+	var oid order.OrderID
+	copy(oid[:], oidB)
+	tracker, isCancel := dc.findOrder(oid)
+	if tracker == nil || isCancel {
+		return fmt.Errorf("%s request received for unknown order: %s", msg.Route, string(msg.Payload))
+	}
+	if err := process(tracker); err != nil {
+		return err
+	}
+	c.schedTradeTick(tracker)
+	return nil
+}
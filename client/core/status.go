@@ -140,6 +140,22 @@ func (c *Core) resolveConflictWithServerData(dc *dexConnection, trade *trackedTr
 
 	logID := statusResolutionID(dc, trade, match)
 
+	if match.MetaData.Proof.Private != nil {
+		// The match_status data has no private swap details. If the server
+		// missed our last request, it will be resent. Otherwise, we missed a
+		// step, and our swap, if any, will be refunded after the lock time.
+		if match.Status > srvStatus {
+			c.log.Infof("Server is behind our private match status (%s -> %s). %s", match.Status, srvStatus, logID)
+			return
+		}
+		c.log.Errorf("Private match status conflict (%s -> %s). Self-revoking. %s", match.Status, srvStatus, logID)
+		match.MetaData.Proof.SelfRevoked = true
+		if err := c.db.UpdateMatch(&match.MetaMatch); err != nil {
+			c.log.Errorf("error updating database after self revocation for %s: %v", logID, err)
+		}
+		return
+	}
+
 	if resolver := conflictResolver(match.Status, srvStatus); resolver != nil {
 		resolver(dc, trade, match, srvData)
 	} else {
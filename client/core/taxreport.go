@@ -0,0 +1,644 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/fiatrates"
+	"decred.org/dcrdex/dex/order"
+)
+
+// CostBasisMethod is the method used to choose the acquisitions that are
+// matched with a disposal when computing realized gains.
+type CostBasisMethod string
+
+const (
+	// CostBasisFIFO matches disposals with the oldest acquisitions first.
+	CostBasisFIFO CostBasisMethod = "fifo"
+	// CostBasisLIFO matches disposals with the newest acquisitions first.
+	CostBasisLIFO CostBasisMethod = "lifo"
+	// CostBasisAverage uses the average cost of all holdings of the asset.
+	CostBasisAverage CostBasisMethod = "average"
+)
+
+const (
+	msPerDay = 24 * 60 * 60 * 1000
+
+	coinpaprikaHistoricalURL = "https://api.coinpaprika.com/v1/tickers/%s/historical?start=%s&end=%s&interval=1d"
+	messariHistoricalURL     = "https://data.messari.io/api/v1/assets/%s/metrics/price/time-series?start=%s&end=%s&interval=1d"
+)
+
+// historicalRateFetcher fetches the daily fiat rates of an asset for the days
+// in the range [fromDay, toDay]. Days are counted from the unix epoch.
+type historicalRateFetcher func(ctx context.Context, a *SupportedAsset, fromDay, toDay uint64) (map[uint64]float64, error)
+
+// historicalRateFetchers are the fiat rate sources that can provide the
+// historical rates used for tax reports.
+var historicalRateFetchers = map[string]historicalRateFetcher{
+	coinpaprika: fetchCoinpaprikaHistoricalRates,
+	messari:     fetchMessariHistoricalRates,
+}
+
+// HistoricalRateSources returns the fiat rate sources that can be used for
+// tax reports, sorted by name.
+func HistoricalRateSources() []string {
+	sources := make([]string, 0, len(historicalRateFetchers))
+	for source := range historicalRateFetchers {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// TaxReportForm is the information necessary to generate a tax report.
+type TaxReportForm struct {
+	// From and To are the bounds of the reporting period, in milliseconds. A
+	// zero To is the current time. Trades before From are still used to
+	// determine the cost basis of disposals within the period.
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+	// Method is the cost basis method. The default is CostBasisFIFO.
+	Method CostBasisMethod `json:"method"`
+	// RateSource is the historical fiat rate source. The default is
+	// Coinpaprika.
+	RateSource string `json:"rateSource"`
+	// AssetIDs limits the report to disposals of the specified assets. All
+	// assets are reported if AssetIDs is empty.
+	AssetIDs []uint32 `json:"assetIDs,omitempty"`
+}
+
+// RealizedGain is the realized gain or loss from a disposal of an asset in a
+// single match. All fiat values are in the report's currency.
+type RealizedGain struct {
+	AssetID  uint32    `json:"assetID"`
+	Symbol   string    `json:"symbol"`
+	Stamp    uint64    `json:"stamp"`
+	Host     string    `json:"host"`
+	MarketID string    `json:"marketID"`
+	OrderID  dex.Bytes `json:"orderID"`
+	MatchID  dex.Bytes `json:"matchID"`
+	// Qty is the amount of the asset disposed of, in atoms.
+	Qty       uint64  `json:"qty"`
+	Proceeds  float64 `json:"proceeds"`
+	CostBasis float64 `json:"costBasis"`
+	// Fees is the fiat value of the network fees paid for the match.
+	Fees float64 `json:"fees"`
+	// Gain is Proceeds minus CostBasis and Fees.
+	Gain float64 `json:"gain"`
+	// UnknownBasisQty is the part of Qty that could not be matched with an
+	// acquisition in the trade history, such as funds that were deposited to
+	// the wallet. It is given a cost basis of zero.
+	UnknownBasisQty uint64 `json:"unknownBasisQty"`
+}
+
+// AssetGains is the sum of the realized gains for an asset.
+type AssetGains struct {
+	AssetID         uint32  `json:"assetID"`
+	Symbol          string  `json:"symbol"`
+	Disposed        uint64  `json:"disposed"`
+	Proceeds        float64 `json:"proceeds"`
+	CostBasis       float64 `json:"costBasis"`
+	Fees            float64 `json:"fees"`
+	Gain            float64 `json:"gain"`
+	UnknownBasisQty uint64  `json:"unknownBasisQty"`
+}
+
+// TaxReport is the realized gains from trades over a period.
+type TaxReport struct {
+	From       uint64          `json:"from"`
+	To         uint64          `json:"to"`
+	Method     CostBasisMethod `json:"method"`
+	RateSource string          `json:"rateSource"`
+	Currency   string          `json:"currency"`
+	Gains      []*RealizedGain `json:"gains"`
+	Assets     []*AssetGains   `json:"assets"`
+	TotalGain  float64         `json:"totalGain"`
+	// MissingRates lists the days, as YYYY-MM-DD, that no fiat rate could be
+	// found for, keyed by asset symbol. Fiat values that depend on a missing
+	// rate may be zero.
+	MissingRates map[string][]string `json:"missingRates,omitempty"`
+}
+
+// taxTrade is a settled match, from the perspective of the user.
+type taxTrade struct {
+	stamp    uint64
+	host     string
+	base     uint32
+	quote    uint32
+	sell     bool
+	qty      uint64
+	quoteQty uint64
+	// fees are the network fees attributed to the match, by asset.
+	fees    map[uint32]uint64
+	orderID order.OrderID
+	matchID order.MatchID
+}
+
+// taxLot is an acquisition of an asset that has not been fully disposed of.
+type taxLot struct {
+	qty  uint64
+	cost float64
+}
+
+// lotBook tracks the holdings of an asset.
+type lotBook struct {
+	method CostBasisMethod
+	lots   []*taxLot
+}
+
+// acquire adds an acquisition. With CostBasisAverage, all acquisitions are
+// pooled into a single lot.
+func (b *lotBook) acquire(qty uint64, cost float64) {
+	if qty == 0 {
+		return
+	}
+	if b.method == CostBasisAverage && len(b.lots) > 0 {
+		b.lots[0].qty += qty
+		b.lots[0].cost += cost
+		return
+	}
+	b.lots = append(b.lots, &taxLot{qty: qty, cost: cost})
+}
+
+// dispose removes qty from the holdings, returning the cost basis of the
+// amount removed and any amount that exceeded the holdings.
+func (b *lotBook) dispose(qty uint64) (basis float64, unknownQty uint64) {
+	for qty > 0 && len(b.lots) > 0 {
+		i := 0
+		if b.method == CostBasisLIFO {
+			i = len(b.lots) - 1
+		}
+		lot := b.lots[i]
+		if qty < lot.qty {
+			cost := lot.cost * float64(qty) / float64(lot.qty)
+			lot.cost -= cost
+			lot.qty -= qty
+			return basis + cost, 0
+		}
+		basis += lot.cost
+		qty -= lot.qty
+		b.lots = append(b.lots[:i], b.lots[i+1:]...)
+	}
+	return basis, qty
+}
+
+// conventional converts an amount in atoms to conventional units.
+func conventional(assetID uint32, v uint64) float64 {
+	ui, err := asset.UnitInfo(assetID)
+	if err != nil {
+		return 0
+	}
+	return float64(v) / float64(ui.Conventional.ConversionFactor)
+}
+
+// feeAssetID is the asset that network fees for an asset are paid in.
+func feeAssetID(assetID uint32) uint32 {
+	if tkn := asset.TokenInfo(assetID); tkn != nil {
+		return tkn.ParentID
+	}
+	return assetID
+}
+
+// realizedGains processes the trades, which must be sorted by time, and
+// returns the realized gains from disposals between from and to. Each trade
+// disposes of the asset sent and acquires the asset received, both at the
+// fiat value of the asset sent. If there is no rate for the asset sent, the
+// fiat value of the asset received is used. rate returns zero if there is no
+// fiat rate for an asset at the time.
+func realizedGains(trades []*taxTrade, method CostBasisMethod, from, to uint64, rate func(assetID uint32, stamp uint64) float64) []*RealizedGain {
+	books := make(map[uint32]*lotBook)
+	book := func(assetID uint32) *lotBook {
+		b, found := books[assetID]
+		if !found {
+			b = &lotBook{method: method}
+			books[assetID] = b
+		}
+		return b
+	}
+
+	var gains []*RealizedGain
+	for _, t := range trades {
+		if t.stamp > to {
+			break
+		}
+		fromAsset, toAsset, fromQty, toQty := t.quote, t.base, t.quoteQty, t.qty
+		if t.sell {
+			fromAsset, toAsset, fromQty, toQty = t.base, t.quote, t.qty, t.quoteQty
+		}
+
+		value := conventional(fromAsset, fromQty) * rate(fromAsset, t.stamp)
+		if value == 0 {
+			value = conventional(toAsset, toQty) * rate(toAsset, t.stamp)
+		}
+
+		basis, unknownQty := book(fromAsset).dispose(fromQty)
+		book(toAsset).acquire(toQty, value)
+
+		if t.stamp < from {
+			continue
+		}
+
+		var fees float64
+		for feeAsset, amt := range t.fees {
+			fees += conventional(feeAsset, amt) * rate(feeAsset, t.stamp)
+		}
+
+		gains = append(gains, &RealizedGain{
+			AssetID:         fromAsset,
+			Symbol:          unbip(fromAsset),
+			Stamp:           t.stamp,
+			Host:            t.host,
+			MarketID:        marketName(t.base, t.quote),
+			OrderID:         t.orderID[:],
+			MatchID:         t.matchID[:],
+			Qty:             fromQty,
+			Proceeds:        value,
+			CostBasis:       basis,
+			Fees:            fees,
+			Gain:            value - basis - fees,
+			UnknownBasisQty: unknownQty,
+		})
+	}
+	return gains
+}
+
+// matchSettled is true if the user's side of a trade match has been redeemed.
+func matchSettled(m *db.MetaMatch) bool {
+	if len(m.MetaData.Proof.RefundCoin) > 0 {
+		return false
+	}
+	if m.Side == order.Maker {
+		return m.Status >= order.MakerRedeemed
+	}
+	return m.Status >= order.MatchComplete
+}
+
+// taxTrades returns the settled matches of all trades in the database, sorted
+// by match time. The network fees paid for an order are divided between its
+// settled matches in proportion to their quantities.
+func (c *Core) taxTrades() ([]*taxTrade, error) {
+	ords, err := c.db.Orders(&db.OrderFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving orders: %w", err)
+	}
+
+	var trades []*taxTrade
+	for _, mo := range ords {
+		trade, ok := mo.Order.(*order.LimitOrder)
+		var sell bool
+		if ok {
+			sell = trade.Sell
+		} else if mkt, ok := mo.Order.(*order.MarketOrder); ok {
+			sell = mkt.Sell
+		} else {
+			continue
+		}
+
+		matches, err := c.db.MatchesForOrder(mo.Order.ID(), true)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving matches for order %s: %w", mo.Order.ID(), err)
+		}
+
+		var settledQty uint64
+		settled := make([]*db.MetaMatch, 0, len(matches))
+		for _, m := range matches {
+			if matchSettled(m) {
+				settled = append(settled, m)
+				settledQty += m.Quantity
+			}
+		}
+		if settledQty == 0 {
+			continue
+		}
+
+		fromAsset, toAsset := mo.Order.Quote(), mo.Order.Base()
+		if sell {
+			fromAsset, toAsset = toAsset, fromAsset
+		}
+		md := mo.MetaData
+		orderFees := map[uint32]uint64{
+			feeAssetID(fromAsset): md.SwapFeesPaid + md.FundingFeesPaid,
+		}
+		orderFees[feeAssetID(toAsset)] += md.RedemptionFeesPaid
+
+		for _, m := range settled {
+			fees := make(map[uint32]uint64, len(orderFees))
+			for assetID, fee := range orderFees {
+				if fee > 0 {
+					fees[assetID] = uint64(float64(fee) * float64(m.Quantity) / float64(settledQty))
+				}
+			}
+			trades = append(trades, &taxTrade{
+				stamp:    m.MetaData.Stamp,
+				host:     m.MetaData.DEX,
+				base:     m.MetaData.Base,
+				quote:    m.MetaData.Quote,
+				sell:     sell,
+				qty:      m.Quantity,
+				quoteQty: calc.BaseToQuote(m.Rate, m.Quantity),
+				fees:     fees,
+				orderID:  m.OrderID,
+				matchID:  m.MatchID,
+			})
+		}
+	}
+
+	sort.Slice(trades, func(i, j int) bool { return trades[i].stamp < trades[j].stamp })
+	return trades, nil
+}
+
+// historicalFiatRates returns the daily fiat rates of an asset for the
+// specified days. Rates are loaded from the database cache, and any missing
+// days are fetched from the rate source and cached. The rate for the current
+// day is not cached, and is the current rate from the enabled fiat rate
+// sources.
+func (c *Core) historicalFiatRates(ctx context.Context, source string, sa *SupportedAsset, days map[uint64]bool) (map[uint64]float64, error) {
+	fetcher, found := historicalRateFetchers[source]
+	if !found {
+		return nil, fmt.Errorf("unknown historical fiat rate source %q", source)
+	}
+
+	today := uint64(time.Now().UnixMilli()) / msPerDay
+	fromDay, toDay := ^uint64(0), uint64(0)
+	for day := range days {
+		fromDay, toDay = min(fromDay, day), max(toDay, day)
+	}
+	if toDay < fromDay {
+		return map[uint64]float64{}, nil
+	}
+
+	rates, err := c.db.HistoricalRates(source, sa.ID, fromDay, toDay)
+	if err != nil {
+		return nil, fmt.Errorf("error loading cached %s rates: %w", sa.Symbol, err)
+	}
+
+	missingFrom, missingTo := ^uint64(0), uint64(0)
+	for day := range days {
+		if _, found := rates[day]; !found && day < today {
+			missingFrom, missingTo = min(missingFrom, day), max(missingTo, day)
+		}
+	}
+	if missingTo >= missingFrom {
+		fetched, err := fetcher(ctx, sa, missingFrom, missingTo)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching %s rates from %s: %w", sa.Symbol, source, err)
+		}
+		for day := range fetched {
+			if day >= today {
+				delete(fetched, day)
+			}
+		}
+		if err := c.db.StoreHistoricalRates(source, sa.ID, fetched); err != nil {
+			c.log.Errorf("Error caching %s rates: %v", sa.Symbol, err)
+		}
+		for day, rate := range fetched {
+			rates[day] = rate
+		}
+	}
+
+	if days[today] {
+		if rate := c.fiatConversions()[sa.ID]; rate > 0 {
+			rates[today] = rate
+		}
+	}
+
+	return rates, nil
+}
+
+// TaxReport computes the realized gains from the settled trades in the trade
+// history over a period, using the specified cost basis method and historical
+// fiat rate source. The entire trade history is processed, so that the cost
+// basis of assets acquired before the period is known. The network fees paid
+// for a trade are deducted from its gain, but do not reduce the holdings of
+// the asset they were paid in.
+func (c *Core) TaxReport(form *TaxReportForm) (*TaxReport, error) {
+	method := form.Method
+	switch method {
+	case "":
+		method = CostBasisFIFO
+	case CostBasisFIFO, CostBasisLIFO, CostBasisAverage:
+	default:
+		return nil, fmt.Errorf("unknown cost basis method %q", method)
+	}
+	source := form.RateSource
+	if source == "" {
+		source = coinpaprika
+	}
+	if _, found := historicalRateFetchers[source]; !found {
+		return nil, fmt.Errorf("fiat rate source %q does not provide historical rates", source)
+	}
+	to := form.To
+	if to == 0 {
+		to = uint64(time.Now().UnixMilli())
+	}
+	if to < form.From {
+		return nil, fmt.Errorf("report period ends before it starts")
+	}
+
+	trades, err := c.taxTrades()
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the days that rates are needed for.
+	rateDays := make(map[uint32]map[uint64]bool)
+	addDay := func(assetID uint32, stamp uint64) {
+		if rateDays[assetID] == nil {
+			rateDays[assetID] = make(map[uint64]bool)
+		}
+		rateDays[assetID][stamp/msPerDay] = true
+	}
+	for _, t := range trades {
+		if t.stamp > to {
+			break
+		}
+		addDay(t.base, t.stamp)
+		addDay(t.quote, t.stamp)
+		for assetID := range t.fees {
+			addDay(assetID, t.stamp)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, time.Minute)
+	defer cancel()
+	supportedAssets := c.SupportedAssets()
+	rates := make(map[uint32]map[uint64]float64, len(rateDays))
+	for assetID, days := range rateDays {
+		sa := supportedAssets[fiatRateAssetID(assetID)]
+		if sa == nil {
+			c.log.Warnf("No supported asset for %s. Fiat values will be zero.", unbip(assetID))
+			continue
+		}
+		rates[assetID], err = c.historicalFiatRates(ctx, source, sa, days)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	missing := make(map[string]map[string]bool)
+	rate := func(assetID uint32, stamp uint64) float64 {
+		day := stamp / msPerDay
+		if r := rates[assetID][day]; r > 0 {
+			return r
+		}
+		symbol := unbip(assetID)
+		if missing[symbol] == nil {
+			missing[symbol] = make(map[string]bool)
+		}
+		missing[symbol][dayDate(day)] = true
+		return 0
+	}
+
+	report := &TaxReport{
+		From:       form.From,
+		To:         to,
+		Method:     method,
+		RateSource: source,
+		Currency:   DefaultFiatCurrency,
+		Gains:      []*RealizedGain{},
+		Assets:     []*AssetGains{},
+	}
+
+	reportAssets := make(map[uint32]bool, len(form.AssetIDs))
+	for _, assetID := range form.AssetIDs {
+		reportAssets[assetID] = true
+	}
+	assetGains := make(map[uint32]*AssetGains)
+	for _, g := range realizedGains(trades, method, form.From, to, rate) {
+		if len(reportAssets) > 0 && !reportAssets[g.AssetID] {
+			continue
+		}
+		report.Gains = append(report.Gains, g)
+		ag, found := assetGains[g.AssetID]
+		if !found {
+			ag = &AssetGains{AssetID: g.AssetID, Symbol: g.Symbol}
+			assetGains[g.AssetID] = ag
+			report.Assets = append(report.Assets, ag)
+		}
+		ag.Disposed += g.Qty
+		ag.Proceeds += g.Proceeds
+		ag.CostBasis += g.CostBasis
+		ag.Fees += g.Fees
+		ag.Gain += g.Gain
+		ag.UnknownBasisQty += g.UnknownBasisQty
+		report.TotalGain += g.Gain
+	}
+	sort.Slice(report.Assets, func(i, j int) bool { return report.Assets[i].AssetID < report.Assets[j].AssetID })
+
+	if len(missing) > 0 {
+		report.MissingRates = make(map[string][]string, len(missing))
+		for symbol, days := range missing {
+			for day := range days {
+				report.MissingRates[symbol] = append(report.MissingRates[symbol], day)
+			}
+			sort.Strings(report.MissingRates[symbol])
+		}
+	}
+
+	return report, nil
+}
+
+// WriteCSV writes the report's realized gains as CSV, one row per disposal.
+// Quantities are in conventional units.
+func (r *TaxReport) WriteCSV(w io.Writer) error {
+	csvWriter := csv.NewWriter(w)
+	csvWriter.UseCRLF = runtime.GOOS == "windows"
+	fiat := func(field string) string { return fmt.Sprintf("%s (%s)", field, r.Currency) }
+	if err := csvWriter.Write([]string{
+		"Time", "Asset", "Quantity", fiat("Proceeds"), fiat("Cost Basis"),
+		fiat("Fees"), fiat("Gain"), "Unknown Basis Quantity", "Host", "Market",
+		"Order ID", "Match ID",
+	}); err != nil {
+		return err
+	}
+	f64 := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	qty := func(assetID uint32, v uint64) string {
+		return strconv.FormatFloat(conventional(assetID, v), 'f', -1, 64)
+	}
+	for _, g := range r.Gains {
+		if err := csvWriter.Write([]string{
+			time.UnixMilli(int64(g.Stamp)).UTC().Format(time.RFC3339),
+			g.Symbol,
+			qty(g.AssetID, g.Qty),
+			f64(g.Proceeds),
+			f64(g.CostBasis),
+			f64(g.Fees),
+			f64(g.Gain),
+			qty(g.AssetID, g.UnknownBasisQty),
+			g.Host,
+			g.MarketID,
+			g.OrderID.String(),
+			g.MatchID.String(),
+		}); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// coinpaprikaSlug is the Coinpaprika coin ID for an asset.
+func coinpaprikaSlug(a *SupportedAsset) string {
+	return fiatrates.CoinpapSlug(a.Name, a.Symbol)
+}
+
+// dayDate formats a day counted from the unix epoch as YYYY-MM-DD.
+func dayDate(day uint64) string {
+	return time.UnixMilli(int64(day * msPerDay)).UTC().Format(time.DateOnly)
+}
+
+// fetchCoinpaprikaHistoricalRates fetches daily rates from the Coinpaprika
+// API. See https://api.coinpaprika.com/#operation/getTickersHistoricalById.
+func fetchCoinpaprikaHistoricalRates(ctx context.Context, a *SupportedAsset, fromDay, toDay uint64) (map[uint64]float64, error) {
+	var res []*struct {
+		Timestamp time.Time `json:"timestamp"`
+		Price     float64   `json:"price"`
+	}
+	uri := fmt.Sprintf(coinpaprikaHistoricalURL, coinpaprikaSlug(a), dayDate(fromDay), dayDate(toDay))
+	if err := getRates(ctx, uri, &res); err != nil {
+		return nil, err
+	}
+	rates := make(map[uint64]float64, len(res))
+	for _, r := range res {
+		if r.Price > 0 {
+			rates[uint64(r.Timestamp.UnixMilli())/msPerDay] = r.Price
+		}
+	}
+	return rates, nil
+}
+
+// fetchMessariHistoricalRates fetches daily closing prices from the Messari
+// API. See https://messari.io/api/docs#operation/Get%20Asset%20timeseries.
+func fetchMessariHistoricalRates(ctx context.Context, a *SupportedAsset, fromDay, toDay uint64) (map[uint64]float64, error) {
+	res := new(struct {
+		Data struct {
+			// Values are [timestamp (ms), open, high, low, close, volume].
+			Values [][]float64 `json:"values"`
+		} `json:"data"`
+	})
+	uri := fmt.Sprintf(messariHistoricalURL, dex.TokenSymbol(a.Symbol), dayDate(fromDay), dayDate(toDay))
+	if err := getRates(ctx, uri, res); err != nil {
+		return nil, err
+	}
+	rates := make(map[uint64]float64, len(res.Data.Values))
+	for _, v := range res.Data.Values {
+		if len(v) < 5 || v[4] <= 0 {
+			continue
+		}
+		rates[uint64(v[0])/msPerDay] = v[4]
+	}
+	return rates, nil
+}
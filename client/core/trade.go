@@ -55,6 +55,9 @@ type matchTracker struct {
 	// to the server and awaiting a response. No attempts will be made to send
 	// another redeem request for this match while one is already active.
 	sendingRedeemAsync uint32 // atomic
+	// sendingPrivateAsync indicates if this private match's keys or adaptor
+	// signatures are being sent to the server.
+	sendingPrivateAsync uint32 // atomic
 
 	// The first group of fields below should be accessed with the parent
 	// trackedTrade's mutex locked, excluding the atomic fields.
@@ -78,6 +81,13 @@ type matchTracker struct {
 	// trying to redeem this match. If suspectRedeem is true, the match will not
 	// be grouped when attempting future redemptions.
 	suspectRedeem bool
+	// awaitingSignature is set when the last swap, redeem or refund attempt
+	// for this match is waiting on an external signer. It is not treated as a
+	// failure, and is used to notify the user only once per signing request.
+	awaitingSignature bool
+	// watchtowerRedeem is set once a pre-signed redemption of the
+	// counterparty's contract has been prepared for the watchtower.
+	watchtowerRedeem bool
 	// refundErr will be set to true if we attempt a refund and get a
 	// CoinNotFoundError, indicating there is nothing to refund and the
 	// counterparty redemption search should be attempted. Prevents retries.
@@ -107,6 +117,10 @@ type matchTracker struct {
 	// request. Additional requests will just error and they don't really care
 	// if we redeem as taker anyway.
 	matchCompleteSent bool
+	// privateKeysSent and privateSigsSent are set when the server has our
+	// keys and adaptor signatures for a private match.
+	privateKeysSent bool
+	privateSigsSent bool
 
 	// The fields below need to be modified without the parent trackedTrade's
 	// mutex being write locked, so they have dedicated mutexes.
@@ -642,6 +656,56 @@ func (t *trackedTrade) broadcastTimeout() time.Duration {
 	return time.Millisecond * time.Duration(t.dc.cfg.BroadcastTimeout)
 }
 
+// allAwaitingSignature checks whether every match is already waiting on an
+// external signer.
+func allAwaitingSignature(matches []*matchTracker) bool {
+	for _, match := range matches {
+		if !match.awaitingSignature {
+			return false
+		}
+	}
+	return len(matches) > 0
+}
+
+// onlyAwaitingSignature checks whether err is an asset.ErrAwaitingSignature or
+// an errorSet made up entirely of them.
+func onlyAwaitingSignature(err error) bool {
+	set, is := err.(*errorSet)
+	if !is {
+		return errors.Is(err, asset.ErrAwaitingSignature)
+	}
+	for _, err := range set.errs {
+		if !onlyAwaitingSignature(err) {
+			return false
+		}
+	}
+	return len(set.errs) > 0
+}
+
+// notifyAwaitingSignature notifies the user that a wallet is waiting on an
+// external signer for the trade. A wallet may be waiting on a signer for many
+// ticks, so the user is only notified when a new request is made. The caller
+// must hold the trackedTrade mutex.
+func (c *Core) notifyAwaitingSignature(t *trackedTrade, w *xcWallet, wasAwaiting bool, err error) {
+	if wasAwaiting {
+		c.log.Debugf("Order %s is still waiting for an external %s signature", t.ID(), w.Symbol)
+		return
+	}
+	c.log.Infof("Order %s is waiting for an external %s signature: %v", t.ID(), w.Symbol, err)
+	subject, details := c.formatDetails(TopicAwaitingSignature, unbip(w.AssetID), makeOrderToken(t.token()), err)
+	t.notify(newOrderNote(TopicAwaitingSignature, subject, details, db.WarningLevel, t.coreOrderInternal()))
+}
+
+// signatureRetryInterval is how long to wait before checking back with a
+// wallet whose external signer has not yet signed a transaction. This is about
+// the next auto-tick.
+func (t *trackedTrade) signatureRetryInterval() time.Duration {
+	if tickInterval := t.dc.ticker.Dur(); tickInterval > 0 {
+		return tickInterval * 3 / 4
+	}
+	return defaultTickInterval * 3 / 4
+}
+
 // coreOrder constructs a *core.Order for the tracked order.Order. If the trade
 // has a cancel order associated with it, the cancel order will be returned,
 // otherwise the second returned *Order will be nil.
@@ -771,8 +835,10 @@ func (t *trackedTrade) cancelTrade(co *order.CancelOrder, preImg order.Preimage,
 	return nil
 }
 
-// nomatch sets the appropriate order status and returns funding coins.
-func (t *trackedTrade) nomatch(oid order.OrderID) (assetMap, error) {
+// nomatch sets the appropriate order status and returns funding coins. If
+// rejected is true, the order is a post-only order that would have matched, and
+// was not booked.
+func (t *trackedTrade) nomatch(oid order.OrderID, rejected bool) (assetMap, error) {
 	assets := make(assetMap)
 	// Check if this is the cancel order.
 	t.mtx.Lock()
@@ -803,7 +869,7 @@ func (t *trackedTrade) nomatch(oid order.OrderID) (assetMap, error) {
 	if t.metaData.Status != order.OrderStatusEpoch {
 		return assets, fmt.Errorf("nomatch sent for non-epoch order %s", oid)
 	}
-	if lo, ok := t.Order.(*order.LimitOrder); ok && lo.Force == order.StandingTiF {
+	if lo, ok := t.Order.(*order.LimitOrder); ok && lo.Force.Standing() && !rejected {
 		t.dc.log.Infof("Standing order %s did not match and is now booked.", t.token())
 		t.metaData.Status = order.OrderStatusBooked
 		t.notify(newOrderNote(TopicOrderBooked, "", "", db.Data, t.coreOrderInternal()))
@@ -812,7 +878,11 @@ func (t *trackedTrade) nomatch(oid order.OrderID) (assetMap, error) {
 		t.unlockRedemptionFraction(1, 1)
 		t.unlockRefundFraction(1, 1)
 		assets.count(t.wallets.fromWallet.AssetID)
-		t.dc.log.Infof("Non-standing order %s did not match.", t.token())
+		if rejected {
+			t.dc.log.Infof("Post-only order %s would have matched and was not booked.", t.token())
+		} else {
+			t.dc.log.Infof("Non-standing order %s did not match.", t.token())
+		}
 		t.metaData.Status = order.OrderStatusExecuted
 		t.notify(newOrderNote(TopicNoMatch, "", "", db.Data, t.coreOrderInternal()))
 	}
@@ -870,6 +940,9 @@ func (t *trackedTrade) negotiate(msgMatches []*msgjson.Match) error {
 			lastExpireDur:   365 * 24 * time.Hour,
 		}
 		match.Status = order.NewlyMatched // these must be new matches
+		if mkt := t.dc.marketConfig(t.mktID); mkt != nil && mkt.PrivateSwaps {
+			match.MetaData.Proof.Private = &db.PrivateSwapProof{}
+		}
 		newTrackers = append(newTrackers, match)
 	}
 
@@ -973,7 +1046,7 @@ func (t *trackedTrade) negotiate(msgMatches []*msgjson.Match) error {
 	completedMarketSell = trade.Sell && t.Type() == order.MarketOrderType && t.metaData.Status < order.OrderStatusExecuted
 	lo, ok := t.Order.(*order.LimitOrder)
 	if ok {
-		completedImmediateTiF = lo.Force == order.ImmediateTiF && t.metaData.Status < order.OrderStatusExecuted
+		completedImmediateTiF = !lo.Force.Standing() && t.metaData.Status < order.OrderStatusExecuted
 	}
 	if remain := trade.Quantity - preCancelFilled; remain > 0 && (completedMarketSell || completedImmediateTiF || cancelMatch != nil) {
 		t.unlockRedemptionFraction(remain, trade.Quantity)
@@ -982,7 +1055,7 @@ func (t *trackedTrade) negotiate(msgMatches []*msgjson.Match) error {
 
 	// Set the order as executed depending on type and fill.
 	if t.metaData.Status != order.OrderStatusCanceled && t.metaData.Status != order.OrderStatusRevoked {
-		if lo, ok := t.Order.(*order.LimitOrder); ok && lo.Force == order.StandingTiF && filled < trade.Quantity {
+		if lo, ok := t.Order.(*order.LimitOrder); ok && lo.Force.Standing() && filled < trade.Quantity {
 			t.metaData.Status = order.OrderStatusBooked
 		} else {
 			t.metaData.Status = order.OrderStatusExecuted
@@ -1927,7 +2000,8 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 	tLock = time.Since(tStart)
 
 	var swaps, redeems, refunds, revokes, searches, redemptionConfirms,
-		dynamicSwapFeeConfirms, dynamicRedemptionFeeConfirms []*matchTracker
+		dynamicSwapFeeConfirms, dynamicRedemptionFeeConfirms, towerRedeems []*matchTracker
+	var privates []*privateMatchStep
 	var sent, quoteSent, received, quoteReceived uint64
 
 	checkMatch := func(match *matchTracker) error { // only errors on context.DeadlineExceeded or context.Canceled
@@ -1952,6 +2026,21 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 		ctx, cancel := context.WithTimeout(c.ctx, 40*time.Second)
 		defer cancel()
 
+		// Private swaps have their own steps.
+		if match.MetaData.Proof.Private != nil {
+			if step := t.privateStep(ctx, match, revoked); step != 0 {
+				privates = append(privates, &privateMatchStep{match, step})
+				return nil
+			}
+			// See the self-governed check below.
+			if !revoked && t.isSelfGoverned() && time.Since(match.matchTime()) > t.lockTimeTaker {
+				c.log.Warnf("Revoking old self-governed private match %v for market %v, host %v.",
+					match, t.mktID, t.dc.acct.host)
+				revokes = append(revokes, match)
+			}
+			return ctx.Err()
+		}
+
 		ok, revoke := t.isSwappable(ctx, match) // rejects revoked matches
 		if ok {
 			c.log.Debugf("Swappable match %s for order %v (%v)", match, t.ID(), side)
@@ -1992,6 +2081,10 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 			dynamicRedemptionFeeConfirms = append(dynamicRedemptionFeeConfirms, match)
 		}
 
+		if c.needsWatchtowerRedeem(t, match) {
+			towerRedeems = append(towerRedeems, match)
+		}
+
 		// Check refundability before checking if to start finding redemption.
 		// Ensures that redemption search is not started if locktime has expired.
 		// If we've already started redemption search for this match, the search
@@ -2082,14 +2175,15 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 	if len(swaps) > 0 || len(refunds) > 0 {
 		assets.count(t.wallets.fromWallet.AssetID)
 	}
-	if len(redeems) > 0 {
+	if len(redeems) > 0 || len(privates) > 0 {
 		assets.count(t.wallets.toWallet.AssetID)
 		assets.count(t.wallets.fromWallet.AssetID) // update ContractLocked balance
 	}
 
 	if !rmCancel && len(swaps) == 0 && len(refunds) == 0 && len(redeems) == 0 &&
 		len(revokes) == 0 && len(searches) == 0 && len(redemptionConfirms) == 0 &&
-		len(dynamicSwapFeeConfirms) == 0 && len(dynamicRedemptionFeeConfirms) == 0 {
+		len(dynamicSwapFeeConfirms) == 0 && len(dynamicRedemptionFeeConfirms) == 0 &&
+		len(privates) == 0 && len(towerRedeems) == 0 {
 		return assets, nil // nothing to do, don't acquire the write-lock
 	}
 
@@ -2128,10 +2222,13 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 		if !t.Trade().Sell {
 			qty = quoteSent
 		}
+		wasAwaiting := allAwaitingSignature(swaps)
 		err = c.swapMatches(t, swaps)
 		corder := t.coreOrderInternal() // after swapMatches modifies matches
 		ui := t.wallets.fromWallet.Info().UnitInfo
-		if err != nil {
+		if onlyAwaitingSignature(err) {
+			c.notifyAwaitingSignature(t, t.wallets.fromWallet, wasAwaiting, err)
+		} else if err != nil {
 			errs.addErr(err)
 			subject, details := c.formatDetails(TopicSwapSendError, ui.ConventionalString(qty), ui.Conventional.Unit, makeOrderToken(t.token()))
 			t.notify(newOrderNote(TopicSwapSendError, subject, details, db.ErrorLevel, corder))
@@ -2153,10 +2250,13 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 		if t.Trade().Sell {
 			qty = quoteReceived
 		}
+		wasAwaiting := allAwaitingSignature(redeems)
 		err = c.redeemMatches(t, redeems)
 		corder := t.coreOrderInternal()
 		ui := t.wallets.toWallet.Info().UnitInfo
-		if err != nil {
+		if onlyAwaitingSignature(err) {
+			c.notifyAwaitingSignature(t, t.wallets.toWallet, wasAwaiting, err)
+		} else if err != nil {
 			errs.addErr(err)
 			subject, details := c.formatDetails(TopicRedemptionError,
 				ui.ConventionalString(qty), ui.Conventional.Unit, makeOrderToken(t.token()))
@@ -2176,10 +2276,13 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 		if didUnlock {
 			c.log.Infof("Unexpected unlock needed for the %s wallet while sending a refund", t.wallets.fromWallet.Symbol)
 		}
+		wasAwaiting := allAwaitingSignature(refunds)
 		refunded, err := c.refundMatches(t, refunds)
 		corder := t.coreOrderInternal()
 		ui := t.wallets.fromWallet.Info().UnitInfo
-		if err != nil {
+		if onlyAwaitingSignature(err) {
+			c.notifyAwaitingSignature(t, t.wallets.fromWallet, wasAwaiting, err)
+		} else if err != nil {
 			errs.addErr(err)
 			subject, details := c.formatDetails(TopicRefundFailure,
 				ui.ConventionalString(refunded), ui.Conventional.Unit, makeOrderToken(t.token()))
@@ -2191,6 +2294,14 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 		}
 	}
 
+	if len(privates) > 0 {
+		c.privateMatchSteps(t, privates, errs)
+	}
+
+	if len(towerRedeems) > 0 {
+		c.sendRedeemsToWatchtower(t, towerRedeems)
+	}
+
 	if len(searches) > 0 {
 		for _, match := range searches {
 			t.findMakersRedemption(c.ctx, match) // async search, just set cancelRedemptionSearch
@@ -2242,7 +2353,14 @@ func (c *Core) resendPendingRequests(t *trackedTrade) {
 		case side == order.Taker && status >= order.MatchComplete:
 			redeemCoinID = proof.TakerRedeem
 		}
-		if len(swapCoinID) != 0 && len(auth.InitSig) == 0 { // resend pending `init` request
+		if proof.Private != nil {
+			// Keys and adaptor signatures are resent by privateStep.
+			if len(swapCoinID) != 0 && len(auth.InitSig) == 0 {
+				c.sendPrivateInit(t, match)
+			} else if len(redeemCoinID) != 0 && len(auth.RedeemSig) == 0 {
+				c.sendPrivateRedeem(t, match)
+			}
+		} else if len(swapCoinID) != 0 && len(auth.InitSig) == 0 { // resend pending `init` request
 			c.sendInitAsync(t, match, swapCoinID, proof.ContractData)
 		} else if len(redeemCoinID) != 0 && len(auth.RedeemSig) == 0 && !match.redemptionPendingSubmission { // resend pending `redeem` request
 			c.sendRedeemAsync(t, match, redeemCoinID, proof.Secret)
@@ -2409,6 +2527,87 @@ func (t *trackedTrade) bestSwapGroupFeeRate(matches []*matchTracker) uint64 {
 	return highestFeeRate
 }
 
+// lockSwapChange checks if the change from a swap transaction for numSwaps
+// matches should be locked. If the order is executed, canceled or revoked, and
+// these are the last swaps, then we don't need to lock the change coin.
+//
+// This method accesses match fields and MUST be called with the trackedTrade
+// mutex lock held for reads.
+func (t *trackedTrade) lockSwapChange(numSwaps int) bool {
+	if t.metaData.Status <= order.OrderStatusBooked {
+		return true
+	}
+	var matchesRequiringSwaps int
+	for _, match := range t.matches {
+		if match.MetaData.Proof.IsRevoked() {
+			// Revoked matches don't require swaps.
+			continue
+		}
+		if (match.Side == order.Maker && match.Status < order.MakerSwapCast) ||
+			(match.Side == order.Taker && match.Status < order.TakerSwapCast) {
+			matchesRequiringSwaps++
+		}
+	}
+	return numSwaps != matchesRequiringSwaps // not the last swaps
+}
+
+// swapInputs are the coins that fund the next swap transaction. If this isn't
+// the first swap, the change coin from the previous swaps is used.
+//
+// This method MUST be called with the trackedTrade mutex lock held for reads.
+func (t *trackedTrade) swapInputs() ([]asset.Coin, error) {
+	fromWallet := t.wallets.fromWallet
+	coinIDs := t.Trade().Coins
+	if len(t.metaData.ChangeCoin) > 0 {
+		coinIDs = []order.CoinID{t.metaData.ChangeCoin}
+		t.dc.log.Debugf("Using stored change coin %v (%v) for order %v matches",
+			coinIDString(fromWallet.AssetID, coinIDs[0]), fromWallet.Symbol, t.ID())
+	}
+
+	inputs := make([]asset.Coin, len(coinIDs))
+	for i, coinID := range coinIDs {
+		coin, found := t.coins[hex.EncodeToString(coinID)]
+		if !found {
+			return nil, fmt.Errorf("%s coin %s not found", fromWallet.Symbol, coinIDString(fromWallet.AssetID, coinID))
+		}
+		inputs[i] = coin
+	}
+	return inputs, nil
+}
+
+// recordSwapChange updates and stores the order's change coin and swap fees
+// after a swap transaction is broadcast.
+//
+// This method MUST be called with the trackedTrade mutex lock held for writes.
+func (t *trackedTrade) recordSwapChange(change asset.Coin, fees uint64, lockChange bool) {
+	fromWallet := t.wallets.fromWallet
+	// If this is the first swap (and even if not), the funding coins
+	// would have been spent and unlocked.
+	t.coinsLocked = false
+	t.changeLocked = lockChange
+	if _, dynamic := fromWallet.Wallet.(asset.DynamicSwapper); !dynamic {
+		t.metaData.SwapFeesPaid += fees // dynamic tx wallets don't know the fees paid until mining
+	}
+
+	if change == nil {
+		t.metaData.ChangeCoin = nil
+	} else {
+		cid := change.ID()
+		if rc, is := change.(asset.RecoveryCoin); is {
+			cid = rc.RecoveryID()
+		}
+		t.coins[cid.String()] = change
+		t.metaData.ChangeCoin = []byte(cid)
+		t.dc.log.Debugf("Saving change coin %v (%v) to DB for order %v",
+			coinIDString(fromWallet.AssetID, t.metaData.ChangeCoin), fromWallet.Symbol, t.ID())
+	}
+	t.change = change
+	err := t.db.UpdateOrderMetaData(t.ID(), t.metaData)
+	if err != nil {
+		t.dc.log.Errorf("Error updating order metadata for order %s: %v", t.ID(), err)
+	}
+}
+
 // swapMatchGroup will send a transaction with swap outputs for the specified
 // matches.
 //
@@ -2447,44 +2646,15 @@ func (c *Core) swapMatchGroup(t *trackedTrade, matches []*matchTracker, highestF
 		}
 	}
 
-	lockChange := true
-	// If the order is executed, canceled or revoked, and these are the last
-	// swaps, then we don't need to lock the change coin.
-	if t.metaData.Status > order.OrderStatusBooked {
-		var matchesRequiringSwaps int
-		for _, match := range t.matches {
-			if match.MetaData.Proof.IsRevoked() {
-				// Revoked matches don't require swaps.
-				continue
-			}
-			if (match.Side == order.Maker && match.Status < order.MakerSwapCast) ||
-				(match.Side == order.Taker && match.Status < order.TakerSwapCast) {
-				matchesRequiringSwaps++
-			}
-		}
-		if len(matches) == matchesRequiringSwaps { // last swaps
-			lockChange = false
-		}
-	}
+	lockChange := t.lockSwapChange(len(matches))
 
 	// Fund the swap. If this isn't the first swap, use the change coin from the
 	// previous swaps.
 	fromWallet := t.wallets.fromWallet
-	coinIDs := t.Trade().Coins
-	if len(t.metaData.ChangeCoin) > 0 {
-		coinIDs = []order.CoinID{t.metaData.ChangeCoin}
-		c.log.Debugf("Using stored change coin %v (%v) for order %v matches",
-			coinIDString(fromWallet.AssetID, coinIDs[0]), fromWallet.Symbol, t.ID())
-	}
-
-	inputs := make([]asset.Coin, len(coinIDs))
-	for i, coinID := range coinIDs {
-		coin, found := t.coins[hex.EncodeToString(coinID)]
-		if !found {
-			errs.add("%s coin %s not found", fromWallet.Symbol, coinIDString(fromWallet.AssetID, coinID))
-			return
-		}
-		inputs[i] = coin
+	inputs, err := t.swapInputs()
+	if err != nil {
+		errs.addErr(err)
+		return
 	}
 
 	if t.dc.IsDown() {
@@ -2506,6 +2676,18 @@ func (c *Core) swapMatchGroup(t *trackedTrade, matches []*matchTracker, highestF
 		Options:      t.options,
 	}
 	receipts, change, fees, err := fromWallet.Swap(swaps)
+	if errors.Is(err, asset.ErrAwaitingSignature) {
+		// The wallet's external signer has not signed the transaction yet.
+		// This is not a swap failure, so don't mark the matches suspect or
+		// count it towards the retry limit. Keep checking back until the
+		// signature shows up or the server revokes the match.
+		for _, match := range matches {
+			match.awaitingSignature = true
+			match.delayTicks(t.signatureRetryInterval())
+		}
+		errs.add("%s swap transaction: %w", fromWallet.Symbol, err)
+		return
+	}
 	if err != nil {
 		bTimeout, tickInterval := t.broadcastTimeout(), t.dc.ticker.Dur() // bTimeout / tickCheckInterval
 		for _, match := range matches {
@@ -2536,6 +2718,9 @@ func (c *Core) swapMatchGroup(t *trackedTrade, matches []*matchTracker, highestF
 		errs.add("error sending %s swap transaction: %v", fromWallet.Symbol, err)
 		return
 	}
+	for _, match := range matches {
+		match.awaitingSignature = false
+	}
 
 	refundTxs := ""
 	for i, r := range receipts {
@@ -2563,32 +2748,11 @@ func (c *Core) swapMatchGroup(t *trackedTrade, matches []*matchTracker, highestF
 			"NOT be used if Bison Wallet is operable. The wallet will refund failed "+
 			"contracts automatically.\nRefund Txs: {%s}", refundTxs)
 	}
-
-	// If this is the first swap (and even if not), the funding coins
-	// would have been spent and unlocked.
-	t.coinsLocked = false
-	t.changeLocked = lockChange
-	if _, dynamic := fromWallet.Wallet.(asset.DynamicSwapper); !dynamic {
-		t.metaData.SwapFeesPaid += fees // dynamic tx wallets don't know the fees paid until mining
+	if c.watchtower != nil && refundTxs != "" {
+		c.sendRefundsToWatchtower(fromWallet.AssetID, receipts)
 	}
 
-	if change == nil {
-		t.metaData.ChangeCoin = nil
-	} else {
-		cid := change.ID()
-		if rc, is := change.(asset.RecoveryCoin); is {
-			cid = rc.RecoveryID()
-		}
-		t.coins[cid.String()] = change
-		t.metaData.ChangeCoin = []byte(cid)
-		c.log.Debugf("Saving change coin %v (%v) to DB for order %v",
-			coinIDString(fromWallet.AssetID, t.metaData.ChangeCoin), fromWallet.Symbol, t.ID())
-	}
-	t.change = change
-	err = t.db.UpdateOrderMetaData(t.ID(), t.metaData)
-	if err != nil {
-		c.log.Errorf("Error updating order metadata for order %s: %v", t.ID(), err)
-	}
+	t.recordSwapChange(change, fees, lockChange)
 
 	// Process the swap for each match by updating the match with swap
 	// details and sending the `init` request to the DEX.
@@ -2828,6 +2992,16 @@ func (c *Core) redeemMatchGroup(t *trackedTrade, matches []*matchTracker, errs *
 			Options:       t.options,
 		})
 
+	if errors.Is(err, asset.ErrAwaitingSignature) {
+		// Not a redeem failure. See swapMatchGroup.
+		for _, match := range matches {
+			match.awaitingSignature = true
+			match.delayTicks(t.signatureRetryInterval())
+		}
+		errs.add("%s redeem transaction: %w", redeemWallet.Symbol, err)
+		return
+	}
+
 	// If an error was encountered, fail all of the matches. A failed match will
 	// not run again on during ticks.
 	if err != nil {
@@ -2900,6 +3074,7 @@ func (c *Core) redeemMatchGroup(t *trackedTrade, matches []*matchTracker, errs *
 	for i, match := range matches {
 		proof := &match.MetaData.Proof
 		coinID := []byte(coinIDs[i])
+		match.awaitingSignature = false
 		match.redemptionPendingSubmission = !submitted
 		if match.Side == order.Taker {
 			// The match won't be retired before the redeem request succeeds
@@ -3386,6 +3561,11 @@ func (c *Core) refundMatches(t *trackedTrade, matches []*matchTracker) (uint64,
 				c.log.Debugf("Failed to refund %s contract %s, already redeemed. Beginning find redemption.",
 					symbol, swapCoinString)
 				t.findMakersRedemption(c.ctx, match)
+			} else if errors.Is(err, asset.ErrAwaitingSignature) {
+				match.awaitingSignature = true
+				match.delayTicks(t.signatureRetryInterval())
+				errs.add("refund tx for match %s, swap coin %s: %w",
+					match, swapCoinString, err)
 			} else {
 				match.delayTicks(time.Minute * 5)
 				errs.add("error sending refund tx for match %s, swap coin %s: %v",
@@ -3399,6 +3579,7 @@ func (c *Core) refundMatches(t *trackedTrade, matches []*matchTracker) (uint64,
 			}
 			continue
 		}
+		match.awaitingSignature = false
 
 		if t.isMarketBuy() {
 			t.unlockRedemptionFraction(1, uint64(len(t.matches)))
@@ -3925,8 +4106,11 @@ func (t *trackedTrade) likelyTaker(midGap uint64) bool {
 		return true
 	}
 	lo := t.Order.(*order.LimitOrder)
-	if lo.Force == order.ImmediateTiF {
+	switch lo.Force {
+	case order.ImmediateTiF, order.FillOrKillTiF:
 		return true
+	case order.PostOnlyTiF:
+		return false
 	}
 
 	if midGap == 0 {
@@ -70,6 +70,12 @@ func (set *errorSet) ifAny() error {
 	return nil
 }
 
+// Unwrap returns the errors in the set so that errors.Is and errors.As can
+// inspect them.
+func (set *errorSet) Unwrap() []error {
+	return set.errs
+}
+
 // Error satisfies the error interface. Error strings are concatenated using a
 // ", " and prepended with the prefix.
 func (set *errorSet) Error() string {
@@ -1035,17 +1041,20 @@ func (a *dexAccount) checkSig(msg []byte, sig []byte) error {
 	return checkSigS256(msg, a.dexPubKey.SerializeCompressed(), sig)
 }
 
-// TradeForm is used to place a market or limit order
+// TradeForm is used to place a market or limit order. A limit order is
+// standing unless one of TifNow, FillOrKill, or PostOnly is set.
 type TradeForm struct {
-	Host    string            `json:"host"`
-	IsLimit bool              `json:"isLimit"`
-	Sell    bool              `json:"sell"`
-	Base    uint32            `json:"base"`
-	Quote   uint32            `json:"quote"`
-	Qty     uint64            `json:"qty"`
-	Rate    uint64            `json:"rate"`
-	TifNow  bool              `json:"tifnow"`
-	Options map[string]string `json:"options"`
+	Host       string            `json:"host"`
+	IsLimit    bool              `json:"isLimit"`
+	Sell       bool              `json:"sell"`
+	Base       uint32            `json:"base"`
+	Quote      uint32            `json:"quote"`
+	Qty        uint64            `json:"qty"`
+	Rate       uint64            `json:"rate"`
+	TifNow     bool              `json:"tifnow"`
+	FillOrKill bool              `json:"fillorkill,omitempty"`
+	PostOnly   bool              `json:"postonly,omitempty"`
+	Options    map[string]string `json:"options"`
 }
 
 // QtyRate specifies the quantity and rate of an order placement.
@@ -0,0 +1,218 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/watchtower"
+	"decred.org/dcrdex/dex/order"
+)
+
+const (
+	// watchtowerRetryInterval is how often jobs that could not be submitted
+	// to the watchtower are retried.
+	watchtowerRetryInterval = time.Minute
+	// watchtowerRedeemConfBuffer is the number of confirmations beyond the
+	// swap confirmation requirement that the counterparty's contract must
+	// have before the watchtower broadcasts our pre-signed redemption. This
+	// gives an online client the chance to redeem first.
+	watchtowerRedeemConfBuffer = 3
+)
+
+// towerClient is the part of the *watchtower.Client that Core uses.
+type towerClient interface {
+	Watch(ctx context.Context, job *watchtower.Job) (string, error)
+}
+
+// queueWatchtowerJobs queues jobs for submission to the watchtower. Jobs that
+// cannot be submitted are retried until they are accepted. Queued jobs are
+// saved to the DB so that they are resubmitted after a restart.
+func (c *Core) queueWatchtowerJobs(jobs ...*watchtower.Job) {
+	c.towerMtx.Lock()
+	for _, job := range jobs {
+		c.towerJobs[job.ID()] = job
+		b, err := json.Marshal(job)
+		if err != nil {
+			c.log.Errorf("Error encoding watchtower job for contract %s: %v", job.CoinID, err)
+			continue
+		}
+		if err := c.db.StoreWatchtowerJob(job.ID(), b); err != nil {
+			c.log.Errorf("Error saving watchtower job for contract %s: %v", job.CoinID, err)
+		}
+	}
+	c.towerMtx.Unlock()
+	select {
+	case c.towerKick <- struct{}{}:
+	default:
+	}
+}
+
+// watchWatchtowerJobs submits queued watchtower jobs until the context is
+// canceled.
+func (c *Core) watchWatchtowerJobs(ctx context.Context) {
+	ticker := time.NewTicker(watchtowerRetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.towerKick:
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		c.submitWatchtowerJobs(ctx)
+	}
+}
+
+// submitWatchtowerJobs submits the queued watchtower jobs, removing those that
+// are accepted from the queue.
+func (c *Core) submitWatchtowerJobs(ctx context.Context) {
+	c.towerMtx.Lock()
+	jobs := make([]*watchtower.Job, 0, len(c.towerJobs))
+	for _, job := range c.towerJobs {
+		jobs = append(jobs, job)
+	}
+	c.towerMtx.Unlock()
+
+	for _, job := range jobs {
+		reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		_, err := c.watchtower.Watch(reqCtx, job)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.log.Errorf("Error submitting %s %s for contract %s to the watchtower (will retry): %v",
+				unbip(job.AssetID), job.Action, job.CoinID, err)
+			continue
+		}
+		c.towerMtx.Lock()
+		// The job may have been replaced while we were submitting it.
+		if c.towerJobs[job.ID()] == job {
+			delete(c.towerJobs, job.ID())
+			if err := c.db.DeleteWatchtowerJob(job.ID()); err != nil {
+				c.log.Errorf("Error deleting watchtower job for contract %s: %v", job.CoinID, err)
+			}
+		}
+		c.towerMtx.Unlock()
+		c.log.Infof("Submitted %s %s for contract %s to the watchtower",
+			unbip(job.AssetID), job.Action, job.CoinID)
+	}
+}
+
+// loadWatchtowerJobs queues the jobs that were saved to the DB but not accepted
+// by the watchtower before the last shutdown.
+func (c *Core) loadWatchtowerJobs() {
+	if c.watchtower == nil {
+		return
+	}
+	encJobs, err := c.db.WatchtowerJobs()
+	if err != nil {
+		c.log.Errorf("Error loading watchtower jobs: %v", err)
+		return
+	}
+	jobs := make([]*watchtower.Job, 0, len(encJobs))
+	for _, b := range encJobs {
+		job := new(watchtower.Job)
+		if err := json.Unmarshal(b, job); err != nil {
+			c.log.Errorf("Error decoding watchtower job: %v", err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	if len(jobs) > 0 {
+		c.log.Infof("Resubmitting %d saved jobs to the watchtower", len(jobs))
+		c.queueWatchtowerJobs(jobs...)
+	}
+}
+
+// sendRefundsToWatchtower queues the signed refund transactions of the swap
+// receipts for the watchtower, which will broadcast them if the contracts are
+// not redeemed before they expire.
+func (c *Core) sendRefundsToWatchtower(assetID uint32, receipts []asset.Receipt) {
+	jobs := make([]*watchtower.Job, 0, len(receipts))
+	for _, r := range receipts {
+		rawRefund := r.SignedRefund()
+		if len(rawRefund) == 0 {
+			continue
+		}
+		jobs = append(jobs, &watchtower.Job{
+			AssetID:  assetID,
+			CoinID:   r.Coin().ID(),
+			Contract: r.Contract(),
+			Action:   watchtower.ActionRefund,
+			Tx:       rawRefund,
+		})
+	}
+	if len(jobs) > 0 {
+		c.queueWatchtowerJobs(jobs...)
+	}
+}
+
+// needsWatchtowerRedeem checks whether we should hand the watchtower a
+// pre-signed redemption of the counterparty's contract. Only the maker knows
+// the secret before redeeming, so only the maker can sign the redemption
+// ahead of time, once the taker's contract has been audited.
+//
+// This method accesses match fields and MUST be called with the trackedTrade
+// mutex lock held for reads.
+func (c *Core) needsWatchtowerRedeem(t *trackedTrade, match *matchTracker) bool {
+	if c.watchtower == nil || match.watchtowerRedeem || match.Side != order.Maker ||
+		match.Status != order.TakerSwapCast || match.counterSwap == nil {
+		return false
+	}
+	_, is := t.wallets.toWallet.Wallet.(asset.RedemptionSigner)
+	return is
+}
+
+// sendRedeemsToWatchtower signs a redemption of the counterparty's contract
+// for each match and queues it for the watchtower. The watchtower broadcasts
+// a redemption once the contract has enough confirmations, in case we are
+// offline when it is time to redeem.
+//
+// This method modifies match fields and MUST be called with the trackedTrade
+// mutex lock held for writes.
+func (c *Core) sendRedeemsToWatchtower(t *trackedTrade, matches []*matchTracker) {
+	redeemWallet := t.wallets.toWallet
+	signer, is := redeemWallet.Wallet.(asset.RedemptionSigner)
+	if !is || !redeemWallet.connected() {
+		return
+	}
+	if _, err := redeemWallet.refreshUnlock(); err != nil {
+		c.log.Errorf("refreshUnlock error signing %s redemptions for the watchtower: %v", redeemWallet.Symbol, err)
+		return
+	}
+	jobs := make([]*watchtower.Job, 0, len(matches))
+	for _, match := range matches {
+		// Only try once. If signing fails, we still redeem ourselves.
+		match.watchtowerRedeem = true
+		rawRedeem, err := signer.SignRedemption(&asset.RedeemForm{
+			Redemptions: []*asset.Redemption{{
+				Spends: match.counterSwap,
+				Secret: match.MetaData.Proof.Secret,
+			}},
+			FeeSuggestion: t.redeemFee(),
+			Options:       t.options,
+		})
+		if err != nil {
+			c.log.Errorf("Error signing %s redemption of contract %s for the watchtower: %v",
+				redeemWallet.Symbol, match.counterSwap.Coin, err)
+			continue
+		}
+		jobs = append(jobs, &watchtower.Job{
+			AssetID:  redeemWallet.AssetID,
+			CoinID:   match.counterSwap.Coin.ID(),
+			Contract: match.counterSwap.Contract,
+			Action:   watchtower.ActionRedeem,
+			Tx:       rawRedeem,
+			Confs:    t.metaData.ToSwapConf + watchtowerRedeemConfBuffer,
+		})
+	}
+	if len(jobs) > 0 {
+		c.queueWatchtowerJobs(jobs...)
+	}
+}
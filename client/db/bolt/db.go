@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"math"
 	"os"
 	"path/filepath"
 	"slices"
@@ -69,6 +70,10 @@ var (
 	notesBucket           = []byte("notes")
 	pokesBucket           = []byte("pokes")
 	credentialsBucket     = []byte("credentials")
+	conditionalBucket     = []byte("conditionalOrders")
+	executionBucket       = []byte("executionOrders")
+	historicalRatesBucket = []byte("historicalRates")
+	watchtowerJobsBucket  = []byte("watchtowerJobs")
 
 	// value keys
 	versionKey = []byte("version")
@@ -181,7 +186,8 @@ func NewDB(dbPath string, logger dex.Logger, opts ...Opts) (dexdb.DB, error) {
 		activeOrdersBucket, archivedOrdersBucket,
 		activeMatchesBucket, archivedMatchesBucket,
 		walletsBucket, notesBucket, credentialsBucket,
-		botProgramsBucket, pokesBucket,
+		botProgramsBucket, pokesBucket, conditionalBucket, executionBucket,
+		historicalRatesBucket, watchtowerJobsBucket,
 	}); err != nil {
 		return nil, err
 	}
@@ -1888,6 +1894,32 @@ func (db *BoltDB) LoadPokes() (pokes []*dexdb.Notification, _ error) {
 	})
 }
 
+// StoreWatchtowerJob saves an encoded watchtower job that has not yet been
+// accepted by the watchtower. Any existing job with the same ID will be
+// overwritten.
+func (db *BoltDB) StoreWatchtowerJob(id string, job []byte) error {
+	return db.withBucket(watchtowerJobsBucket, db.Update, func(bkt *bbolt.Bucket) error {
+		return bkt.Put([]byte(id), job)
+	})
+}
+
+// DeleteWatchtowerJob deletes the job saved with StoreWatchtowerJob.
+func (db *BoltDB) DeleteWatchtowerJob(id string) error {
+	return db.withBucket(watchtowerJobsBucket, db.Update, func(bkt *bbolt.Bucket) error {
+		return bkt.Delete([]byte(id))
+	})
+}
+
+// WatchtowerJobs retrieves the encoded jobs saved with StoreWatchtowerJob.
+func (db *BoltDB) WatchtowerJobs() (jobs [][]byte, _ error) {
+	return jobs, db.withBucket(watchtowerJobsBucket, db.View, func(bkt *bbolt.Bucket) error {
+		return bkt.ForEach(func(_, v []byte) error {
+			jobs = append(jobs, append([]byte(nil), v...))
+			return nil
+		})
+	})
+}
+
 // newest buckets gets the nested buckets with the highest timestamp from the
 // specified master buckets. The nested bucket should have an encoded uint64 at
 // the timeKey. An optional filter function can be used to reject buckets.
@@ -2446,6 +2478,131 @@ func (db *BoltDB) Language() (lang string, _ error) {
 	})
 }
 
+// UpdateConditionalOrder saves the conditional order. Any existing entry with
+// the same ID will be overwritten.
+func (db *BoltDB) UpdateConditionalOrder(o *dexdb.ConditionalOrder) error {
+	if len(o.ID) == 0 {
+		return fmt.Errorf("conditional order has no ID")
+	}
+	return db.withBucket(conditionalBucket, db.Update, func(bkt *bbolt.Bucket) error {
+		return bkt.Put(o.ID, o.Encode())
+	})
+}
+
+// ConditionalOrders retrieves the stored conditional orders, sorted by
+// descending time. If activeOnly is true, only orders that have not been
+// triggered or canceled are returned.
+func (db *BoltDB) ConditionalOrders(activeOnly bool) (ords []*dexdb.ConditionalOrder, _ error) {
+	err := db.withBucket(conditionalBucket, db.View, func(bkt *bbolt.Bucket) error {
+		return bkt.ForEach(func(k, v []byte) error {
+			o, err := dexdb.DecodeConditionalOrder(encode.CopySlice(v))
+			if err != nil {
+				return fmt.Errorf("error decoding conditional order %x: %w", k, err)
+			}
+			if activeOnly && o.Status != dexdb.ConditionalOrderActive {
+				return nil
+			}
+			ords = append(ords, o)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ords, func(i, j int) bool { return ords[i].Stamp > ords[j].Stamp })
+	return ords, nil
+}
+
+// UpdateExecutionOrder saves the execution order. Any existing entry with the
+// same ID will be overwritten.
+func (db *BoltDB) UpdateExecutionOrder(o *dexdb.ExecutionOrder) error {
+	if len(o.ID) == 0 {
+		return fmt.Errorf("execution order has no ID")
+	}
+	return db.withBucket(executionBucket, db.Update, func(bkt *bbolt.Bucket) error {
+		return bkt.Put(o.ID, o.Encode())
+	})
+}
+
+// ExecutionOrders retrieves the stored execution orders, sorted by descending
+// time. If activeOnly is true, only orders that are active or paused are
+// returned.
+func (db *BoltDB) ExecutionOrders(activeOnly bool) (ords []*dexdb.ExecutionOrder, _ error) {
+	err := db.withBucket(executionBucket, db.View, func(bkt *bbolt.Bucket) error {
+		return bkt.ForEach(func(k, v []byte) error {
+			o, err := dexdb.DecodeExecutionOrder(encode.CopySlice(v))
+			if err != nil {
+				return fmt.Errorf("error decoding execution order %x: %w", k, err)
+			}
+			if activeOnly && o.Status != dexdb.ExecutionActive && o.Status != dexdb.ExecutionPaused {
+				return nil
+			}
+			ords = append(ords, o)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ords, func(i, j int) bool { return ords[i].Stamp > ords[j].Stamp })
+	return ords, nil
+}
+
+// StoreHistoricalRates saves daily fiat rates for an asset from the specified
+// fiat rate source. Rates are stored in a sub-bucket for the source, with a
+// nested sub-bucket for the asset, keyed by the big-endian day so that a range
+// of days can be read with a cursor.
+func (db *BoltDB) StoreHistoricalRates(source string, assetID uint32, rates map[uint64]float64) error {
+	if source == "" {
+		return fmt.Errorf("no fiat rate source")
+	}
+	return db.withBucket(historicalRatesBucket, db.Update, func(bkt *bbolt.Bucket) error {
+		srcBkt, err := bkt.CreateBucketIfNotExists([]byte(source))
+		if err != nil {
+			return fmt.Errorf("error creating %s bucket: %w", source, err)
+		}
+		assetBkt, err := srcBkt.CreateBucketIfNotExists(encode.Uint32Bytes(assetID))
+		if err != nil {
+			return fmt.Errorf("error creating asset %d bucket: %w", assetID, err)
+		}
+		for day, rate := range rates {
+			if err := assetBkt.Put(encode.Uint64Bytes(day), encode.Uint64Bytes(math.Float64bits(rate))); err != nil {
+				return fmt.Errorf("error storing rate for day %d: %w", day, err)
+			}
+		}
+		return nil
+	})
+}
+
+// HistoricalRates retrieves the daily fiat rates saved with
+// StoreHistoricalRates for the days in the range [fromDay, toDay]. Days
+// without a stored rate are not included in the returned map.
+func (db *BoltDB) HistoricalRates(source string, assetID uint32, fromDay, toDay uint64) (map[uint64]float64, error) {
+	rates := make(map[uint64]float64)
+	return rates, db.withBucket(historicalRatesBucket, db.View, func(bkt *bbolt.Bucket) error {
+		srcBkt := bkt.Bucket([]byte(source))
+		if srcBkt == nil {
+			return nil
+		}
+		assetBkt := srcBkt.Bucket(encode.Uint32Bytes(assetID))
+		if assetBkt == nil {
+			return nil
+		}
+		cursor := assetBkt.Cursor()
+		for k, v := cursor.Seek(encode.Uint64Bytes(fromDay)); k != nil; k, v = cursor.Next() {
+			day := intCoder.Uint64(k)
+			if day > toDay {
+				break
+			}
+			if len(v) != 8 {
+				return fmt.Errorf("invalid rate encoding for day %d", day)
+			}
+			rates[day] = math.Float64frombits(intCoder.Uint64(v))
+		}
+		return nil
+	})
+}
+
 // timeNow is the current unix timestamp in milliseconds.
 func timeNow() uint64 {
 	return uint64(time.Now().UnixMilli())
@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"decred.org/dcrdex/client/db"
 	dbtest "decred.org/dcrdex/client/db/test"
 	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
 	"decred.org/dcrdex/dex/order"
 	ordertest "decred.org/dcrdex/dex/order/test"
 	"go.etcd.io/bbolt"
@@ -1613,3 +1615,213 @@ func TestPokes(t *testing.T) {
 		t.Fatal("Result from second LoadPokes wasn't empty")
 	}
 }
+
+func TestConditionalOrders(t *testing.T) {
+	boltdb, shutdown := newTestDB(t)
+	defer shutdown()
+
+	newOrder := func(stamp uint64, trigger db.ConditionalTrigger) *db.ConditionalOrder {
+		return &db.ConditionalOrder{
+			ID:              encode.RandomBytes(8),
+			Host:            "somedex.com",
+			Base:            42,
+			Quote:           0,
+			Sell:            true,
+			IsLimit:         true,
+			Qty:             5e8,
+			Rate:            1e6,
+			Options:         map[string]string{"swapfeebump": "1.2"},
+			Type:            db.StopLoss,
+			Trigger:         trigger,
+			TriggerRate:     9e5,
+			TriggerFiatRate: 19.75,
+			Stamp:           stamp,
+		}
+	}
+	ord1 := newOrder(1, db.TriggerMidGap)
+	ord2 := newOrder(2, db.TriggerFiatRate)
+	ord3 := newOrder(3, db.TriggerMidGap)
+	for _, o := range []*db.ConditionalOrder{ord1, ord2, ord3} {
+		if err := boltdb.UpdateConditionalOrder(o); err != nil {
+			t.Fatalf("UpdateConditionalOrder error: %v", err)
+		}
+	}
+
+	ord2.Status = db.ConditionalOrderTriggered
+	ord2.OrderID = encode.RandomBytes(32)
+	if err := boltdb.UpdateConditionalOrder(ord2); err != nil {
+		t.Fatalf("UpdateConditionalOrder error: %v", err)
+	}
+
+	ords, err := boltdb.ConditionalOrders(false)
+	if err != nil {
+		t.Fatalf("ConditionalOrders error: %v", err)
+	}
+	exp := []*db.ConditionalOrder{ord3, ord2, ord1}
+	if !reflect.DeepEqual(ords, exp) {
+		t.Fatalf("wrong conditional orders. expected %+v, got %+v", exp, ords)
+	}
+
+	ords, err = boltdb.ConditionalOrders(true)
+	if err != nil {
+		t.Fatalf("ConditionalOrders error: %v", err)
+	}
+	exp = []*db.ConditionalOrder{ord3, ord1}
+	if !reflect.DeepEqual(ords, exp) {
+		t.Fatalf("wrong active conditional orders. expected %+v, got %+v", exp, ords)
+	}
+}
+
+func TestExecutionOrders(t *testing.T) {
+	boltdb, shutdown := newTestDB(t)
+	defer shutdown()
+
+	newOrder := func(stamp uint64, algo db.ExecutionAlgo) *db.ExecutionOrder {
+		return &db.ExecutionOrder{
+			ID:         encode.RandomBytes(8),
+			Host:       "somedex.com",
+			Base:       42,
+			Quote:      0,
+			Sell:       true,
+			Qty:        50e8,
+			Rate:       1e6,
+			Options:    map[string]string{"swapfeebump": "1.2"},
+			Algo:       algo,
+			Slices:     10,
+			Interval:   600,
+			VisibleQty: 5e8,
+			Stamp:      stamp,
+			NextSlice:  stamp,
+			ChildIDs:   []order.OrderID{},
+		}
+	}
+	ord1 := newOrder(1, db.ExecutionTWAP)
+	ord2 := newOrder(2, db.ExecutionIceberg)
+	ord3 := newOrder(3, db.ExecutionTWAP)
+	for _, o := range []*db.ExecutionOrder{ord1, ord2, ord3} {
+		if err := boltdb.UpdateExecutionOrder(o); err != nil {
+			t.Fatalf("UpdateExecutionOrder error: %v", err)
+		}
+	}
+
+	ord1.Status = db.ExecutionPaused
+	ord1.ChildIDs = []order.OrderID{ordertest.RandomOrderID(), ordertest.RandomOrderID()}
+	ord1.Filled = 5e8
+	ord1.Error = "wallet locked"
+	ord2.Status = db.ExecutionComplete
+	for _, o := range []*db.ExecutionOrder{ord1, ord2} {
+		if err := boltdb.UpdateExecutionOrder(o); err != nil {
+			t.Fatalf("UpdateExecutionOrder error: %v", err)
+		}
+	}
+
+	ords, err := boltdb.ExecutionOrders(false)
+	if err != nil {
+		t.Fatalf("ExecutionOrders error: %v", err)
+	}
+	exp := []*db.ExecutionOrder{ord3, ord2, ord1}
+	if !reflect.DeepEqual(ords, exp) {
+		t.Fatalf("wrong execution orders. expected %+v, got %+v", exp, ords)
+	}
+
+	ords, err = boltdb.ExecutionOrders(true)
+	if err != nil {
+		t.Fatalf("ExecutionOrders error: %v", err)
+	}
+	exp = []*db.ExecutionOrder{ord3, ord1}
+	if !reflect.DeepEqual(ords, exp) {
+		t.Fatalf("wrong active execution orders. expected %+v, got %+v", exp, ords)
+	}
+}
+
+func TestHistoricalRates(t *testing.T) {
+	boltdb, shutdown := newTestDB(t)
+	defer shutdown()
+
+	rates, err := boltdb.HistoricalRates("coinpaprika", 42, 0, 100)
+	if err != nil {
+		t.Fatalf("HistoricalRates error: %v", err)
+	}
+	if len(rates) != 0 {
+		t.Fatalf("expected no rates, got %v", rates)
+	}
+
+	if err := boltdb.StoreHistoricalRates("coinpaprika", 42, map[uint64]float64{10: 20.5, 11: 21, 13: 19.25}); err != nil {
+		t.Fatalf("StoreHistoricalRates error: %v", err)
+	}
+	if err := boltdb.StoreHistoricalRates("coinpaprika", 0, map[uint64]float64{11: 60000}); err != nil {
+		t.Fatalf("StoreHistoricalRates error: %v", err)
+	}
+	if err := boltdb.StoreHistoricalRates("messari", 42, map[uint64]float64{11: 22}); err != nil {
+		t.Fatalf("StoreHistoricalRates error: %v", err)
+	}
+	// Overwrite one of the rates.
+	if err := boltdb.StoreHistoricalRates("coinpaprika", 42, map[uint64]float64{13: 19.5}); err != nil {
+		t.Fatalf("StoreHistoricalRates error: %v", err)
+	}
+	if err := boltdb.StoreHistoricalRates("", 42, map[uint64]float64{13: 19.5}); err == nil {
+		t.Fatalf("no error for empty source")
+	}
+
+	rates, err = boltdb.HistoricalRates("coinpaprika", 42, 11, 13)
+	if err != nil {
+		t.Fatalf("HistoricalRates error: %v", err)
+	}
+	exp := map[uint64]float64{11: 21, 13: 19.5}
+	if !reflect.DeepEqual(rates, exp) {
+		t.Fatalf("wrong rates. expected %v, got %v", exp, rates)
+	}
+
+	rates, err = boltdb.HistoricalRates("messari", 42, 0, 100)
+	if err != nil {
+		t.Fatalf("HistoricalRates error: %v", err)
+	}
+	exp = map[uint64]float64{11: 22}
+	if !reflect.DeepEqual(rates, exp) {
+		t.Fatalf("wrong rates. expected %v, got %v", exp, rates)
+	}
+}
+
+func TestWatchtowerJobs(t *testing.T) {
+	boltdb, shutdown := newTestDB(t)
+	defer shutdown()
+
+	jobs, err := boltdb.WatchtowerJobs()
+	if err != nil {
+		t.Fatalf("WatchtowerJobs error: %v", err)
+	}
+	if len(jobs) != 0 {
+		t.Fatalf("expected no jobs, got %d", len(jobs))
+	}
+
+	if err := boltdb.StoreWatchtowerJob("a", []byte{1}); err != nil {
+		t.Fatalf("StoreWatchtowerJob error: %v", err)
+	}
+	if err := boltdb.StoreWatchtowerJob("b", []byte{2}); err != nil {
+		t.Fatalf("StoreWatchtowerJob error: %v", err)
+	}
+	// Overwrite one of the jobs.
+	if err := boltdb.StoreWatchtowerJob("a", []byte{3}); err != nil {
+		t.Fatalf("StoreWatchtowerJob error: %v", err)
+	}
+	jobs, err = boltdb.WatchtowerJobs()
+	if err != nil {
+		t.Fatalf("WatchtowerJobs error: %v", err)
+	}
+	exp := [][]byte{{3}, {2}} // sorted by ID
+	if !reflect.DeepEqual(jobs, exp) {
+		t.Fatalf("wrong jobs. expected %v, got %v", exp, jobs)
+	}
+
+	if err := boltdb.DeleteWatchtowerJob("a"); err != nil {
+		t.Fatalf("DeleteWatchtowerJob error: %v", err)
+	}
+	jobs, err = boltdb.WatchtowerJobs()
+	if err != nil {
+		t.Fatalf("WatchtowerJobs error: %v", err)
+	}
+	exp = [][]byte{{2}}
+	if !reflect.DeepEqual(jobs, exp) {
+		t.Fatalf("wrong jobs. expected %v, got %v", exp, jobs)
+	}
+}
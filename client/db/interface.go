@@ -155,4 +155,33 @@ type DB interface {
 	SetLanguage(lang string) error
 	// Language gets the language stored with SetLanguage.
 	Language() (string, error)
+	// UpdateConditionalOrder saves the conditional order. Any existing entry
+	// with the same ID will be overwritten.
+	UpdateConditionalOrder(*ConditionalOrder) error
+	// ConditionalOrders retrieves the stored conditional orders, sorted by
+	// descending time. If activeOnly is true, only orders that have not been
+	// triggered or canceled are returned.
+	ConditionalOrders(activeOnly bool) ([]*ConditionalOrder, error)
+	// UpdateExecutionOrder saves the execution order. Any existing entry with
+	// the same ID will be overwritten.
+	UpdateExecutionOrder(*ExecutionOrder) error
+	// ExecutionOrders retrieves the stored execution orders, sorted by
+	// descending time. If activeOnly is true, only orders that are active or
+	// paused are returned.
+	ExecutionOrders(activeOnly bool) ([]*ExecutionOrder, error)
+	// StoreHistoricalRates saves daily fiat rates for an asset from the
+	// specified fiat rate source. The rates are keyed by the number of days
+	// since the unix epoch. Existing rates for the same days are overwritten.
+	StoreHistoricalRates(source string, assetID uint32, rates map[uint64]float64) error
+	// HistoricalRates retrieves the daily fiat rates saved with
+	// StoreHistoricalRates for the days in the range [fromDay, toDay].
+	HistoricalRates(source string, assetID uint32, fromDay, toDay uint64) (map[uint64]float64, error)
+	// StoreWatchtowerJob saves an encoded watchtower job that has not yet been
+	// accepted by the watchtower. Any existing job with the same ID will be
+	// overwritten.
+	StoreWatchtowerJob(id string, job []byte) error
+	// DeleteWatchtowerJob deletes the job saved with StoreWatchtowerJob.
+	DeleteWatchtowerJob(id string) error
+	// WatchtowerJobs retrieves the encoded jobs saved with StoreWatchtowerJob.
+	WatchtowerJobs() ([][]byte, error)
 }
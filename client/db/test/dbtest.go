@@ -151,9 +151,40 @@ func RandomMatchProof(sparsity float64) *db.MatchProof {
 	if !doZero() {
 		proof.Auth.RedemptionStamp = rand.Uint64()
 	}
+	if !doZero() {
+		proof.Private = RandomPrivateSwapProof(sparsity)
+	}
 	return proof
 }
 
+// RandomPrivateSwapProof creates a private swap proof with random values.
+// Half of the fields are left empty on average if sparsity is 0.5.
+func RandomPrivateSwapProof(sparsity float64) *db.PrivateSwapProof {
+	doZero := func() bool { return rand.IntN(1000) < int(sparsity*1000) }
+	randField := func(n int) []byte {
+		if doZero() {
+			return nil
+		}
+		return randBytes(n)
+	}
+	return &db.PrivateSwapProof{
+		RedeemKey:             randField(99),
+		RefundKey:             randField(99),
+		CounterRedeemKey:      randField(99),
+		CounterRefundKey:      randField(99),
+		UnsignedRedeem:        randField(150),
+		CounterUnsignedRedeem: randField(150),
+		AdaptorPub:            randField(33),
+		AdaptorSecret:         randField(32),
+		MakerRedeemSig:        randField(97),
+		MakerRefundSig:        randField(97),
+		TakerRefundSig:        randField(97),
+		CounterRedeemTx:       randField(200),
+		SwapTx:                randField(200),
+		RedeemTx:              randField(200),
+	}
+}
+
 func RandomNotification(maxTime uint64) *db.Notification {
 	return &db.Notification{
 		NoteType:    ordertest.RandomAddress(),
@@ -251,6 +282,41 @@ func MustCompareMatchProof(t testKiller, m1, m2 *db.MatchProof) {
 		t.Fatalf("TakerRedeem mismatch. %x != %x", m1.TakerRedeem, m2.TakerRedeem)
 	}
 	MustCompareMatchAuth(t, &m1.Auth, &m2.Auth)
+	if (m1.Private == nil) != (m2.Private == nil) {
+		t.Fatalf("Private mismatch. %v != %v", m1.Private, m2.Private)
+	}
+	if m1.Private != nil {
+		MustComparePrivateSwapProof(t, m1.Private, m2.Private)
+	}
+}
+
+// MustComparePrivateSwapProof ensures the two PrivateSwapProof are identical,
+// calling the Fatalf method of the testKiller if not.
+func MustComparePrivateSwapProof(t testKiller, p1, p2 *db.PrivateSwapProof) {
+	t.Helper()
+	for _, f := range []struct {
+		name   string
+		b1, b2 []byte
+	}{
+		{"RedeemKey", p1.RedeemKey, p2.RedeemKey},
+		{"RefundKey", p1.RefundKey, p2.RefundKey},
+		{"CounterRedeemKey", p1.CounterRedeemKey, p2.CounterRedeemKey},
+		{"CounterRefundKey", p1.CounterRefundKey, p2.CounterRefundKey},
+		{"UnsignedRedeem", p1.UnsignedRedeem, p2.UnsignedRedeem},
+		{"CounterUnsignedRedeem", p1.CounterUnsignedRedeem, p2.CounterUnsignedRedeem},
+		{"AdaptorPub", p1.AdaptorPub, p2.AdaptorPub},
+		{"AdaptorSecret", p1.AdaptorSecret, p2.AdaptorSecret},
+		{"MakerRedeemSig", p1.MakerRedeemSig, p2.MakerRedeemSig},
+		{"MakerRefundSig", p1.MakerRefundSig, p2.MakerRefundSig},
+		{"TakerRefundSig", p1.TakerRefundSig, p2.TakerRefundSig},
+		{"CounterRedeemTx", p1.CounterRedeemTx, p2.CounterRedeemTx},
+		{"SwapTx", p1.SwapTx, p2.SwapTx},
+		{"RedeemTx", p1.RedeemTx, p2.RedeemTx},
+	} {
+		if !bytes.Equal(f.b1, f.b2) {
+			t.Fatalf("%s mismatch. %x != %x", f.name, f.b1, f.b2)
+		}
+	}
 }
 
 // MustCompareAccountInfo ensures the two AccountInfo are identical, calling the
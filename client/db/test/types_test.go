@@ -52,6 +52,26 @@ func TestMatchProof(t *testing.T) {
 	t.Logf("encoded, decoded, and compared %d MatchProof in %d ms", spins, time.Since(tStart)/time.Millisecond)
 }
 
+func TestMatchProofV3(t *testing.T) {
+	// A v3 MatchProof has no private swap data.
+	proof := RandomMatchProof(0)
+	proof.Private = nil
+	proofB := proof.Encode()
+	proofB[0] = 3
+	proofB = proofB[:len(proofB)-1] // drop the empty push
+	reProof, ver, err := db.DecodeMatchProof(proofB)
+	if err != nil {
+		t.Fatalf("match decode error: %v", err)
+	}
+	if ver != 3 {
+		t.Fatalf("wanted match proof ver 3, got %d", ver)
+	}
+	if reProof.Private != nil {
+		t.Fatalf("unexpected private swap data")
+	}
+	MustCompareMatchProof(t, proof, reProof)
+}
+
 func TestOrderProof(t *testing.T) {
 	spins := 10000
 	if testing.Short() {
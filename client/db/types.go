@@ -624,6 +624,103 @@ type MatchProof struct {
 	// RedemptionFeeConfirmed indicate the fees for this match have been
 	// confirmed and the value added to the trade.
 	RedemptionFeeConfirmed bool
+	// Private is the adaptor signature data for a private swap. Private is
+	// nil for an HTLC swap.
+	Private *PrivateSwapProof
+}
+
+// PrivateSwapProof is the data needed to settle a private swap, which uses
+// adaptor signatures instead of a hashed timelock contract. The adaptor
+// signatures are named for the party that created them.
+type PrivateSwapProof struct {
+	// RedeemKey is our redeem wallet's public key, used in the counterparty's
+	// swap. RefundKey is our swap wallet's public key, used in our swap.
+	RedeemKey []byte
+	RefundKey []byte
+	// CounterRedeemKey and CounterRefundKey are the counterparty's keys.
+	CounterRedeemKey []byte
+	CounterRefundKey []byte
+	// UnsignedRedeem is our unsigned redemption of the counterparty's swap.
+	UnsignedRedeem []byte
+	// CounterUnsignedRedeem is the counterparty's unsigned redemption of our
+	// swap.
+	CounterUnsignedRedeem []byte
+	// AdaptorPub is the public point of the adaptor secret.
+	AdaptorPub []byte
+	// AdaptorSecret is generated by the maker, and recovered by the taker
+	// from the maker's redemption.
+	AdaptorSecret []byte
+	// MakerRedeemSig is the maker's adaptor signature for the maker's redeem.
+	MakerRedeemSig []byte
+	// MakerRefundSig is the maker's adaptor signature for the taker's
+	// redeem.
+	MakerRefundSig []byte
+	// TakerRefundSig is the taker's adaptor signature for the maker's redeem.
+	TakerRefundSig []byte
+	// CounterRedeemTx is the maker's redemption transaction, which the taker
+	// uses to recover the adaptor secret.
+	CounterRedeemTx []byte
+	// SwapTx and RedeemTx are our swap and redeem transactions, which are
+	// sent to the server for the counterparty.
+	SwapTx   []byte
+	RedeemTx []byte
+}
+
+const privateSwapProofPushes = 14
+
+// Encode encodes the PrivateSwapProof to a versioned blob.
+func (p *PrivateSwapProof) Encode() []byte {
+	return versionedBytes(0).
+		AddData(p.RedeemKey).
+		AddData(p.RefundKey).
+		AddData(p.CounterRedeemKey).
+		AddData(p.CounterRefundKey).
+		AddData(p.UnsignedRedeem).
+		AddData(p.CounterUnsignedRedeem).
+		AddData(p.AdaptorPub).
+		AddData(p.AdaptorSecret).
+		AddData(p.MakerRedeemSig).
+		AddData(p.MakerRefundSig).
+		AddData(p.TakerRefundSig).
+		AddData(p.CounterRedeemTx).
+		AddData(p.SwapTx).
+		AddData(p.RedeemTx)
+}
+
+// DecodePrivateSwapProof decodes the versioned blob to a *PrivateSwapProof.
+func DecodePrivateSwapProof(b []byte) (*PrivateSwapProof, error) {
+	ver, pushes, err := encode.DecodeBlob(b, privateSwapProofPushes)
+	if err != nil {
+		return nil, err
+	}
+	switch ver {
+	case 0:
+		return decodePrivateSwapProof_v0(pushes)
+	}
+	return nil, fmt.Errorf("unknown PrivateSwapProof version %d", ver)
+}
+
+func decodePrivateSwapProof_v0(pushes [][]byte) (*PrivateSwapProof, error) {
+	if len(pushes) != privateSwapProofPushes {
+		return nil, fmt.Errorf("decodePrivateSwapProof: expected %d pushes, got %d",
+			privateSwapProofPushes, len(pushes))
+	}
+	return &PrivateSwapProof{
+		RedeemKey:             pushes[0],
+		RefundKey:             pushes[1],
+		CounterRedeemKey:      pushes[2],
+		CounterRefundKey:      pushes[3],
+		UnsignedRedeem:        pushes[4],
+		CounterUnsignedRedeem: pushes[5],
+		AdaptorPub:            pushes[6],
+		AdaptorSecret:         pushes[7],
+		MakerRedeemSig:        pushes[8],
+		MakerRefundSig:        pushes[9],
+		TakerRefundSig:        pushes[10],
+		CounterRedeemTx:       pushes[11],
+		SwapTx:                pushes[12],
+		RedeemTx:              pushes[13],
+	}, nil
 }
 
 func boolByte(b bool) []byte {
@@ -635,13 +732,17 @@ func boolByte(b bool) []byte {
 
 // MatchProofVer is the current serialization version of a MatchProof.
 const (
-	MatchProofVer    = 3
-	matchProofPushes = 24
+	MatchProofVer    = 4
+	matchProofPushes = 25
 )
 
 // Encode encodes the MatchProof to a versioned blob.
 func (p *MatchProof) Encode() []byte {
 	auth := p.Auth
+	var privateBytes []byte
+	if p.Private != nil {
+		privateBytes = p.Private.Encode()
+	}
 	return versionedBytes(MatchProofVer).
 		AddData(p.ContractData).
 		AddData(p.CounterContract).
@@ -666,7 +767,8 @@ func (p *MatchProof) Encode() []byte {
 		AddData(boolByte(p.SelfRevoked)).
 		AddData(p.CounterTxData).
 		AddData(boolByte(p.SwapFeeConfirmed)).
-		AddData(boolByte(p.RedemptionFeeConfirmed))
+		AddData(boolByte(p.RedemptionFeeConfirmed)).
+		AddData(privateBytes)
 }
 
 // DecodeMatchProof decodes the versioned blob to a *MatchProof.
@@ -676,7 +778,10 @@ func DecodeMatchProof(b []byte) (*MatchProof, uint8, error) {
 		return nil, 0, err
 	}
 	switch ver {
-	case 3: // MatchProofVer
+	case 4: // MatchProofVer
+		proof, err := decodeMatchProof_v4(pushes)
+		return proof, ver, err
+	case 3:
 		proof, err := decodeMatchProof_v3(pushes)
 		return proof, ver, err
 	case 2:
@@ -710,10 +815,24 @@ func decodeMatchProof_v2(pushes [][]byte) (*MatchProof, error) {
 }
 
 func decodeMatchProof_v3(pushes [][]byte) (*MatchProof, error) {
+	// Add the empty private swap data.
+	pushes = append(pushes, nil)
+	return decodeMatchProof_v4(pushes)
+}
+
+func decodeMatchProof_v4(pushes [][]byte) (*MatchProof, error) {
 	if len(pushes) != matchProofPushes {
 		return nil, fmt.Errorf("DecodeMatchProof: expected %d pushes, got %d",
 			matchProofPushes, len(pushes))
 	}
+	var private *PrivateSwapProof
+	if len(pushes[24]) > 0 {
+		var err error
+		private, err = DecodePrivateSwapProof(pushes[24])
+		if err != nil {
+			return nil, fmt.Errorf("error decoding private swap data: %w", err)
+		}
+	}
 	return &MatchProof{
 		ContractData:    pushes[0],
 		CounterContract: pushes[1],
@@ -741,6 +860,7 @@ func decodeMatchProof_v3(pushes [][]byte) (*MatchProof, error) {
 		SelfRevoked:            bytes.Equal(pushes[20], encode.ByteTrue),
 		SwapFeeConfirmed:       bytes.Equal(pushes[21], encode.ByteTrue),
 		RedemptionFeeConfirmed: bytes.Equal(pushes[22], encode.ByteTrue),
+		Private:                private,
 	}, nil
 }
 
@@ -1234,3 +1354,338 @@ func hashKey(b []byte) []byte {
 	h := blake2s.Sum256(b)
 	return h[:]
 }
+
+// ConditionalOrderType is the type of a conditional order.
+type ConditionalOrderType uint8
+
+const (
+	// StopLoss orders are triggered when the price moves against the
+	// direction of the order, i.e. down for a sell, or up for a buy.
+	StopLoss ConditionalOrderType = iota + 1
+	// TakeProfit orders are triggered when the price moves in the direction
+	// of the order, i.e. up for a sell, or down for a buy.
+	TakeProfit
+)
+
+// String satisfies fmt.Stringer for ConditionalOrderType.
+func (t ConditionalOrderType) String() string {
+	switch t {
+	case StopLoss:
+		return "stoploss"
+	case TakeProfit:
+		return "takeprofit"
+	}
+	return "unknown"
+}
+
+// ConditionalTrigger is the price that a conditional order is triggered by.
+type ConditionalTrigger uint8
+
+const (
+	// TriggerMidGap orders are triggered by the mid-gap rate of the synced
+	// order book.
+	TriggerMidGap ConditionalTrigger = iota + 1
+	// TriggerFiatRate orders are triggered by the fiat rate of the base asset.
+	TriggerFiatRate
+)
+
+// String satisfies fmt.Stringer for ConditionalTrigger.
+func (t ConditionalTrigger) String() string {
+	switch t {
+	case TriggerMidGap:
+		return "midgap"
+	case TriggerFiatRate:
+		return "fiat"
+	}
+	return "unknown"
+}
+
+// ConditionalOrderStatus is the status of a conditional order.
+type ConditionalOrderStatus uint8
+
+const (
+	ConditionalOrderActive ConditionalOrderStatus = iota
+	ConditionalOrderTriggered
+	ConditionalOrderCanceled
+	ConditionalOrderFailed
+)
+
+// String satisfies fmt.Stringer for ConditionalOrderStatus.
+func (s ConditionalOrderStatus) String() string {
+	switch s {
+	case ConditionalOrderActive:
+		return "active"
+	case ConditionalOrderTriggered:
+		return "triggered"
+	case ConditionalOrderCanceled:
+		return "canceled"
+	case ConditionalOrderFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// ConditionalOrder is an order that is held by the client until the trigger
+// price is reached, at which point it is submitted as a regular trade.
+type ConditionalOrder struct {
+	ID      []byte
+	Host    string
+	Base    uint32
+	Quote   uint32
+	Sell    bool
+	IsLimit bool
+	TifNow  bool
+	Qty     uint64
+	Rate    uint64
+	Options map[string]string
+	Type    ConditionalOrderType
+	Trigger ConditionalTrigger
+	// TriggerRate is the mid-gap message rate for TriggerMidGap orders.
+	TriggerRate uint64
+	// TriggerFiatRate is the fiat rate of the base asset for TriggerFiatRate
+	// orders.
+	TriggerFiatRate float64
+	// Stamp is the time the conditional order was created, in milliseconds.
+	Stamp  uint64
+	Status ConditionalOrderStatus
+	// OrderID is the ID of the order that was placed when the conditional
+	// order was triggered.
+	OrderID []byte
+	// Error is the reason a triggered order could not be placed.
+	Error string
+}
+
+// Encode encodes the ConditionalOrder to a versioned blob.
+func (o *ConditionalOrder) Encode() []byte {
+	return versionedBytes(0).
+		AddData(o.ID).
+		AddData([]byte(o.Host)).
+		AddData(uint32Bytes(o.Base)).
+		AddData(uint32Bytes(o.Quote)).
+		AddData(boolByte(o.Sell)).
+		AddData(boolByte(o.IsLimit)).
+		AddData(boolByte(o.TifNow)).
+		AddData(uint64Bytes(o.Qty)).
+		AddData(uint64Bytes(o.Rate)).
+		AddData(config.Data(o.Options)).
+		AddData([]byte{byte(o.Type)}).
+		AddData([]byte{byte(o.Trigger)}).
+		AddData(uint64Bytes(o.TriggerRate)).
+		AddData(uint64Bytes(math.Float64bits(o.TriggerFiatRate))).
+		AddData(uint64Bytes(o.Stamp)).
+		AddData([]byte{byte(o.Status)}).
+		AddData(o.OrderID).
+		AddData([]byte(o.Error))
+}
+
+// DecodeConditionalOrder decodes the versioned blob to a *ConditionalOrder.
+func DecodeConditionalOrder(b []byte) (*ConditionalOrder, error) {
+	ver, pushes, err := encode.DecodeBlob(b, 18)
+	if err != nil {
+		return nil, err
+	}
+	switch ver {
+	case 0:
+		return decodeConditionalOrder_v0(pushes)
+	}
+	return nil, fmt.Errorf("unknown DecodeConditionalOrder version %d", ver)
+}
+
+func decodeConditionalOrder_v0(pushes [][]byte) (*ConditionalOrder, error) {
+	if len(pushes) != 18 {
+		return nil, fmt.Errorf("decodeConditionalOrder_v0: expected 18 pushes, got %d", len(pushes))
+	}
+	for _, i := range []int{10, 11, 15} {
+		if len(pushes[i]) != 1 {
+			return nil, fmt.Errorf("decodeConditionalOrder_v0: push %d is supposed to be length 1. got %d", i, len(pushes[i]))
+		}
+	}
+	options, err := config.Parse(pushes[9])
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode conditional order options: %w", err)
+	}
+	return &ConditionalOrder{
+		ID:              pushes[0],
+		Host:            string(pushes[1]),
+		Base:            intCoder.Uint32(pushes[2]),
+		Quote:           intCoder.Uint32(pushes[3]),
+		Sell:            bytes.Equal(pushes[4], encode.ByteTrue),
+		IsLimit:         bytes.Equal(pushes[5], encode.ByteTrue),
+		TifNow:          bytes.Equal(pushes[6], encode.ByteTrue),
+		Qty:             intCoder.Uint64(pushes[7]),
+		Rate:            intCoder.Uint64(pushes[8]),
+		Options:         options,
+		Type:            ConditionalOrderType(pushes[10][0]),
+		Trigger:         ConditionalTrigger(pushes[11][0]),
+		TriggerRate:     intCoder.Uint64(pushes[12]),
+		TriggerFiatRate: math.Float64frombits(intCoder.Uint64(pushes[13])),
+		Stamp:           intCoder.Uint64(pushes[14]),
+		Status:          ConditionalOrderStatus(pushes[15][0]),
+		OrderID:         pushes[16],
+		Error:           string(pushes[17]),
+	}, nil
+}
+
+// ExecutionAlgo is the algorithm used to execute an ExecutionOrder.
+type ExecutionAlgo uint8
+
+const (
+	// ExecutionTWAP splits the parent order into equal child orders that are
+	// placed at regular intervals.
+	ExecutionTWAP ExecutionAlgo = iota + 1
+	// ExecutionIceberg keeps a single child order of the visible quantity
+	// booked, and places a new one whenever it is filled.
+	ExecutionIceberg
+)
+
+// String satisfies fmt.Stringer for ExecutionAlgo.
+func (a ExecutionAlgo) String() string {
+	switch a {
+	case ExecutionTWAP:
+		return "twap"
+	case ExecutionIceberg:
+		return "iceberg"
+	}
+	return "unknown"
+}
+
+// ExecutionStatus is the status of an ExecutionOrder.
+type ExecutionStatus uint8
+
+const (
+	ExecutionActive ExecutionStatus = iota
+	ExecutionPaused
+	ExecutionComplete
+	ExecutionCanceled
+)
+
+// String satisfies fmt.Stringer for ExecutionStatus.
+func (s ExecutionStatus) String() string {
+	switch s {
+	case ExecutionActive:
+		return "active"
+	case ExecutionPaused:
+		return "paused"
+	case ExecutionComplete:
+		return "complete"
+	case ExecutionCanceled:
+		return "canceled"
+	}
+	return "unknown"
+}
+
+// ExecutionOrder is a large limit order that the client executes as a series
+// of smaller child orders.
+type ExecutionOrder struct {
+	ID      []byte
+	Host    string
+	Base    uint32
+	Quote   uint32
+	Sell    bool
+	Qty     uint64
+	Rate    uint64
+	Options map[string]string
+	Algo    ExecutionAlgo
+	// Slices is the number of child orders for ExecutionTWAP orders.
+	Slices uint32
+	// Interval is the time between ExecutionTWAP child orders, in seconds.
+	Interval uint64
+	// VisibleQty is the quantity of the ExecutionIceberg child orders.
+	VisibleQty uint64
+	// Stamp is the time the execution order was created, in milliseconds.
+	Stamp  uint64
+	Status ExecutionStatus
+	// NextSlice is the time that the next ExecutionTWAP child order is due,
+	// in milliseconds.
+	NextSlice uint64
+	// Filled is the quantity filled by the child orders.
+	Filled uint64
+	// ChildIDs are the IDs of the child orders placed so far.
+	ChildIDs []order.OrderID
+	// Error is the reason the last child order could not be placed.
+	Error string
+}
+
+// Encode encodes the ExecutionOrder to a versioned blob.
+func (o *ExecutionOrder) Encode() []byte {
+	childIDs := make([]byte, 0, len(o.ChildIDs)*order.OrderIDSize)
+	for _, oid := range o.ChildIDs {
+		childIDs = append(childIDs, oid[:]...)
+	}
+	return versionedBytes(0).
+		AddData(o.ID).
+		AddData([]byte(o.Host)).
+		AddData(uint32Bytes(o.Base)).
+		AddData(uint32Bytes(o.Quote)).
+		AddData(boolByte(o.Sell)).
+		AddData(uint64Bytes(o.Qty)).
+		AddData(uint64Bytes(o.Rate)).
+		AddData(config.Data(o.Options)).
+		AddData([]byte{byte(o.Algo)}).
+		AddData(uint32Bytes(o.Slices)).
+		AddData(uint64Bytes(o.Interval)).
+		AddData(uint64Bytes(o.VisibleQty)).
+		AddData(uint64Bytes(o.Stamp)).
+		AddData([]byte{byte(o.Status)}).
+		AddData(uint64Bytes(o.NextSlice)).
+		AddData(uint64Bytes(o.Filled)).
+		AddData(childIDs).
+		AddData([]byte(o.Error))
+}
+
+// DecodeExecutionOrder decodes the versioned blob to a *ExecutionOrder.
+func DecodeExecutionOrder(b []byte) (*ExecutionOrder, error) {
+	ver, pushes, err := encode.DecodeBlob(b, 18)
+	if err != nil {
+		return nil, err
+	}
+	switch ver {
+	case 0:
+		return decodeExecutionOrder_v0(pushes)
+	}
+	return nil, fmt.Errorf("unknown DecodeExecutionOrder version %d", ver)
+}
+
+func decodeExecutionOrder_v0(pushes [][]byte) (*ExecutionOrder, error) {
+	if len(pushes) != 18 {
+		return nil, fmt.Errorf("decodeExecutionOrder_v0: expected 18 pushes, got %d", len(pushes))
+	}
+	for _, i := range []int{8, 13} {
+		if len(pushes[i]) != 1 {
+			return nil, fmt.Errorf("decodeExecutionOrder_v0: push %d is supposed to be length 1. got %d", i, len(pushes[i]))
+		}
+	}
+	options, err := config.Parse(pushes[7])
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode execution order options: %w", err)
+	}
+	childIDsB := pushes[16]
+	if len(childIDsB)%order.OrderIDSize != 0 {
+		return nil, fmt.Errorf("decodeExecutionOrder_v0: child order IDs length %d not a multiple of %d",
+			len(childIDsB), order.OrderIDSize)
+	}
+	childIDs := make([]order.OrderID, len(childIDsB)/order.OrderIDSize)
+	for i := range childIDs {
+		copy(childIDs[i][:], childIDsB[i*order.OrderIDSize:])
+	}
+	return &ExecutionOrder{
+		ID:         pushes[0],
+		Host:       string(pushes[1]),
+		Base:       intCoder.Uint32(pushes[2]),
+		Quote:      intCoder.Uint32(pushes[3]),
+		Sell:       bytes.Equal(pushes[4], encode.ByteTrue),
+		Qty:        intCoder.Uint64(pushes[5]),
+		Rate:       intCoder.Uint64(pushes[6]),
+		Options:    options,
+		Algo:       ExecutionAlgo(pushes[8][0]),
+		Slices:     intCoder.Uint32(pushes[9]),
+		Interval:   intCoder.Uint64(pushes[10]),
+		VisibleQty: intCoder.Uint64(pushes[11]),
+		Stamp:      intCoder.Uint64(pushes[12]),
+		Status:     ExecutionStatus(pushes[13][0]),
+		NextSlice:  intCoder.Uint64(pushes[14]),
+		Filled:     intCoder.Uint64(pushes[15]),
+		ChildIDs:   childIDs,
+		Error:      string(pushes[17]),
+	}, nil
+}
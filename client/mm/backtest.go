@@ -0,0 +1,640 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package mm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex"
+)
+
+// ReplayEvent is a recorded market update. Only one of the update fields will
+// be populated.
+type ReplayEvent struct {
+	// Stamp is the time the update was recorded, in milliseconds.
+	Stamp int64 `json:"stamp"`
+
+	// DEXBook is a snapshot of the DEX order book.
+	DEXBook *core.OrderBook `json:"dexBook,omitempty"`
+	// BookOrder is an order added to the DEX order book.
+	BookOrder *core.MiniOrder `json:"bookOrder,omitempty"`
+	// UnbookOrder is an order removed from the DEX order book.
+	UnbookOrder *core.MiniOrder `json:"unbookOrder,omitempty"`
+	// UpdateRemaining is a change to the remaining quantity of an order on
+	// the DEX order book.
+	UpdateRemaining *core.RemainderUpdate `json:"updateRemaining,omitempty"`
+	// EpochReport is the summary of the matches made in a DEX epoch.
+	EpochReport *core.EpochMatchSummaryPayload `json:"epochReport,omitempty"`
+	// CEXBook is a snapshot of a CEX order book.
+	CEXBook *CEXBookSnapshot `json:"cexBook,omitempty"`
+}
+
+// CEXBookSnapshot is a snapshot of a CEX order book. Buys and sells are
+// ordered best rate first.
+type CEXBookSnapshot struct {
+	BaseID  uint32            `json:"baseID"`
+	QuoteID uint32            `json:"quoteID"`
+	Buys    []*core.MiniOrder `json:"buys"`
+	Sells   []*core.MiniOrder `json:"sells"`
+}
+
+// ReplaySource provides recorded market updates to a backtest in the order
+// they were recorded. Next returns io.EOF when there are no more updates.
+type ReplaySource interface {
+	Next() (*ReplayEvent, error)
+}
+
+// BacktestConfig is the configuration for a backtest.
+type BacktestConfig struct {
+	// Bot is the configuration of the bot being tested.
+	Bot *BotConfig `json:"bot"`
+	// DEXBalances and CEXBalances are the bot's starting balances.
+	DEXBalances map[uint32]uint64 `json:"dexBalances"`
+	CEXBalances map[uint32]uint64 `json:"cexBalances"`
+	// LotSize, RateStep, EpochLen and ParcelSize are the parameters of the
+	// DEX market that was recorded.
+	LotSize    uint64 `json:"lotSize"`
+	RateStep   uint64 `json:"rateStep"`
+	EpochLen   uint64 `json:"epochLen"`
+	ParcelSize uint32 `json:"parcelSize"`
+	// BaseFees are the single lot fees paid in the base asset's fee asset,
+	// and QuoteFees are the single lot fees paid in the quote asset's fee
+	// asset. A swap fee and a redeem fee is charged for each match.
+	BaseFees  *LotFees `json:"baseFees"`
+	QuoteFees *LotFees `json:"quoteFees"`
+	// CEXFeeRate is the CEX trading fee as a ratio of the amount received.
+	CEXFeeRate float64 `json:"cexFeeRate"`
+	// FiatRates are the starting fiat rates of the assets. During the
+	// backtest, the base asset's fiat rate is updated based on the market
+	// rate, while the quote asset's rate is kept constant.
+	FiatRates map[uint32]float64 `json:"fiatRates"`
+}
+
+func (c *BacktestConfig) validate() error {
+	if c.Bot == nil {
+		return errors.New("no bot config")
+	}
+	if err := c.Bot.validate(); err != nil {
+		return fmt.Errorf("invalid bot config: %w", err)
+	}
+	if c.Bot.requiresCEX() && c.Bot.CEXName == "" {
+		return errors.New("bot requires a CEX, but no CEX name was specified")
+	}
+	if c.LotSize == 0 {
+		return errors.New("zero lot size")
+	}
+	if c.EpochLen == 0 {
+		return errors.New("zero epoch length")
+	}
+	if c.BaseFees == nil {
+		c.BaseFees = &LotFees{}
+	}
+	if c.QuoteFees == nil {
+		c.QuoteFees = &LotFees{}
+	}
+	if c.CEXFeeRate < 0 || c.CEXFeeRate >= 1 {
+		return fmt.Errorf("invalid CEX fee rate %f", c.CEXFeeRate)
+	}
+	if c.FiatRates[c.Bot.QuoteID] == 0 {
+		return fmt.Errorf("no fiat rate for quote asset %d", c.Bot.QuoteID)
+	}
+	return nil
+}
+
+// BacktestResult is the outcome of a backtest.
+type BacktestResult struct {
+	// Overview is the summary of the run in the same format as runs stored
+	// in the event log.
+	Overview *MarketMakingRunOverview `json:"overview"`
+	// Fees are the total DEX and CEX fees paid in each asset.
+	Fees map[uint32]*Amount `json:"fees"`
+	// InventoryDrift is the change in the holdings of each asset due to
+	// trading, not including fees.
+	InventoryDrift map[uint32]*Amount `json:"inventoryDrift"`
+	// Epochs is the number of DEX epochs that were replayed.
+	Epochs uint64 `json:"epochs"`
+	// DEXMatches is the number of matches the bot's DEX orders received.
+	DEXMatches uint32 `json:"dexMatches"`
+	// CEXTrades is the number of trades the bot made on the CEX.
+	CEXTrades uint32 `json:"cexTrades"`
+	// Events are the events that were logged during the run, ordered by ID.
+	Events []*MarketMakingEvent `json:"events"`
+}
+
+var errBotStopped = errors.New("bot stopped")
+
+// backtester replays recorded market data through a bot.
+type backtester struct {
+	cfg      *BacktestConfig
+	log      dex.Logger
+	core     *backtestCore
+	cex      *backtestCEX
+	eventLog *backtestEventLog
+	adaptor  *unifiedExchangeAdaptor
+	// async is true if the bot handles DEX order updates and CEX trade
+	// updates outside of the notification feed and book feed goroutines.
+	// The driver must then wait for the bot to go idle before moving on.
+	async bool
+	done  <-chan struct{}
+
+	haveBook              bool
+	epochs                uint64
+	firstStamp, lastStamp int64
+}
+
+// RunBacktest replays recorded market data through a bot using a simulated
+// DEX and CEX, and reports the bot's performance. Matches settle
+// immediately, and deposits and withdrawals are not supported.
+func RunBacktest(ctx context.Context, cfg *BacktestConfig, src ReplaySource, log dex.Logger) (*BacktestResult, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	c, err := newBacktestCore(cfg, log.SubLogger("CORE"))
+	if err != nil {
+		return nil, err
+	}
+	bt := &backtester{
+		cfg:      cfg,
+		log:      log,
+		core:     c,
+		cex:      newBacktestCEX(cfg.CEXFeeRate, log.SubLogger("CEX")),
+		eventLog: newBacktestEventLog(),
+		async:    cfg.Bot.requiresCEX(),
+	}
+
+	// Apply updates until the first epoch report, so that the bot starts
+	// with synced books.
+	var first *ReplayEvent
+	for first == nil {
+		e, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("no epoch reports in replay data")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading replay data: %w", err)
+		}
+		bt.stamp(e)
+		if e.EpochReport != nil {
+			first = e
+			continue
+		}
+		if err := bt.apply(e); err != nil {
+			return nil, err
+		}
+	}
+	if !bt.haveBook {
+		return nil, errors.New("no DEX order book snapshot before the first epoch report")
+	}
+	bt.core.updateFiatRates(bt.marketRate())
+
+	b, err := bt.newBot()
+	if err != nil {
+		return nil, err
+	}
+	botCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cm := dex.NewConnectionMaster(b)
+	if err := cm.ConnectOnce(botCtx); err != nil {
+		return nil, fmt.Errorf("error starting bot: %w", err)
+	}
+	bt.done = bt.adaptor.ctx.Done()
+	bt.core.done.Store(bt.done)
+
+	err = bt.resolveEpoch(first)
+	for err == nil {
+		var e *ReplayEvent
+		e, err = src.Next()
+		if errors.Is(err, io.EOF) {
+			err = nil
+			break
+		}
+		if err != nil {
+			err = fmt.Errorf("error reading replay data: %w", err)
+			break
+		}
+		if ctx.Err() != nil {
+			err = ctx.Err()
+			break
+		}
+		bt.stamp(e)
+		if e.EpochReport != nil {
+			err = bt.resolveEpoch(e)
+		} else {
+			err = bt.apply(e)
+		}
+	}
+	if errors.Is(err, errBotStopped) {
+		err = errors.New("bot stopped unexpectedly")
+	}
+
+	if stopErr := bt.shutdown(cancel, cm); stopErr != nil && err == nil {
+		err = stopErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return bt.result(), nil
+}
+
+func (bt *backtester) stamp(e *ReplayEvent) {
+	if bt.firstStamp == 0 {
+		bt.firstStamp = e.Stamp
+	}
+	bt.lastStamp = e.Stamp
+}
+
+// newBot creates the bot specified by the config.
+func (bt *backtester) newBot() (bot, error) {
+	cfg := bt.cfg.Bot
+	mktID := dexMarketID(cfg.Host, cfg.BaseID, cfg.QuoteID)
+	adaptorCfg := &exchangeAdaptorCfg{
+		botID:           mktID,
+		mwh:             &MarketWithHost{Host: cfg.Host, BaseID: cfg.BaseID, QuoteID: cfg.QuoteID},
+		baseDexBalances: bt.cfg.DEXBalances,
+		baseCexBalances: bt.cfg.CEXBalances,
+		core:            bt.core,
+		log:             bt.log.SubLogger(mktID),
+		eventLogDB:      bt.eventLog,
+		botCfg:          cfg,
+	}
+	if cfg.CEXName != "" {
+		adaptorCfg.cex = bt.cex
+	}
+
+	switch {
+	case cfg.ArbMarketMakerConfig != nil:
+		b, err := newArbMarketMaker(cfg, adaptorCfg, bt.log.SubLogger(fmt.Sprintf("AMM-%s", mktID)))
+		if err != nil {
+			return nil, err
+		}
+		bt.adaptor = b.unifiedExchangeAdaptor
+		return b, nil
+	case cfg.BasicMMConfig != nil:
+		b, err := newBasicMarketMaker(cfg, adaptorCfg, &backtestOracle{bt}, bt.log.SubLogger(fmt.Sprintf("MM-%s", mktID)))
+		if err != nil {
+			return nil, err
+		}
+		bt.adaptor = b.unifiedExchangeAdaptor
+		return b, nil
+	case cfg.SimpleArbConfig != nil:
+		b, err := newSimpleArbMarketMaker(cfg, adaptorCfg, bt.log.SubLogger(fmt.Sprintf("ARB-%s", mktID)))
+		if err != nil {
+			return nil, err
+		}
+		bt.adaptor = b.unifiedExchangeAdaptor
+		return b, nil
+	default:
+		return nil, fmt.Errorf("no bot config found")
+	}
+}
+
+// apply applies a recorded book update.
+func (bt *backtester) apply(e *ReplayEvent) error {
+	if e.CEXBook != nil {
+		bt.cex.updateBook(e.CEXBook)
+		return bt.flushCEXUpdates()
+	}
+	if e.DEXBook != nil {
+		bt.haveBook = true
+	}
+	if err := bt.core.applyBookEvent(e); err != nil {
+		return fmt.Errorf("error applying DEX book update: %w", err)
+	}
+	return nil
+}
+
+// marketRate is the mid-gap rate of the market on the CEX, or on the DEX if
+// no CEX book was recorded.
+func (bt *backtester) marketRate() uint64 {
+	baseID, quoteID := bt.cfg.Bot.BaseID, bt.cfg.Bot.QuoteID
+	if r := bt.cex.midGap(baseID, quoteID); r > 0 {
+		return r
+	}
+	r, _ := bt.core.book.MidGap()
+	return r
+}
+
+// resolveEpoch matches the bot's orders at the end of a recorded epoch, sends
+// the resulting notifications to the bot, and then starts the next epoch.
+func (bt *backtester) resolveEpoch(e *ReplayEvent) error {
+	report := e.EpochReport
+	for _, n := range bt.core.resolveEpoch(report, uint64(e.Stamp)) {
+		if !bt.core.notify(n) {
+			return errBotStopped
+		}
+	}
+	// The fiat rates note also ensures that the previous notes have been
+	// handled, since the notification feed is unbuffered.
+	if !bt.core.notify(&core.FiatRatesNote{
+		Notification: db.NewNotification(core.NoteTypeFiatRates, core.TopicFiatRatesUpdate, "", "", db.Data),
+		FiatRates:    bt.core.updateFiatRates(bt.marketRate()),
+	}) {
+		return errBotStopped
+	}
+	bt.settle()
+	if err := bt.flushCEXUpdates(); err != nil {
+		return err
+	}
+
+	bt.epochs++
+	bt.sendEpoch(report.Epoch, nil)
+	bt.settle()
+	return nil
+}
+
+// sendEpoch sends a ResolvedEpoch update to all open book feeds. Each update
+// is followed by another update that the bots ignore, which ensures that the
+// bot has finished handling the epoch before sendEpoch returns. If abort is
+// closed, sendEpoch gives up on feeds that are not being read.
+func (bt *backtester) sendEpoch(epoch uint64, abort <-chan struct{}) {
+	mktID := bt.core.mktName
+	for _, f := range bt.core.openFeeds() {
+		for _, u := range []*core.BookUpdate{{
+			Action:   core.EpochResolved,
+			Host:     bt.cfg.Bot.Host,
+			MarketID: mktID,
+			Payload:  &core.ResolvedEpoch{Current: epoch + 1, Resolved: epoch},
+		}, {
+			Action:   core.EpochMatchSummary,
+			Host:     bt.cfg.Bot.Host,
+			MarketID: mktID,
+			Payload:  &core.EpochMatchSummaryPayload{Epoch: epoch},
+		}} {
+			select {
+			case f.c <- u:
+			case <-f.closed:
+			case <-abort:
+			}
+		}
+	}
+}
+
+// flushCEXUpdates sends queued CEX trade updates to the bot.
+func (bt *backtester) flushCEXUpdates() error {
+	updates := bt.cex.flushUpdates()
+	if len(updates) == 0 {
+		return nil
+	}
+	for _, u := range updates {
+		select {
+		case bt.cex.updates <- u:
+		case <-bt.done:
+			return errBotStopped
+		}
+	}
+	bt.settle()
+	return nil
+}
+
+// settle waits for a bot that handles updates asynchronously to stop making
+// calls to the simulated core and CEX.
+func (bt *backtester) settle() {
+	if !bt.async {
+		return
+	}
+	const idleChecks = 3
+	var lastCalls uint64
+	for idle := 0; idle < idleChecks; {
+		time.Sleep(time.Millisecond)
+		calls := bt.core.calls.Load() + bt.cex.calls.Load()
+		drained := true
+		if ch, ok := bt.adaptor.orderUpdates.Load().(chan *core.Order); ok && len(ch) > 0 {
+			drained = false
+		}
+		if calls == lastCalls && drained {
+			idle++
+		} else {
+			idle = 0
+		}
+		lastCalls = calls
+	}
+}
+
+// shutdown stops the bot. The bot cancels its orders on shutdown, which
+// requires the driver to keep resolving epochs until the bot has stopped.
+func (bt *backtester) shutdown(cancel context.CancelFunc, cm *dex.ConnectionMaster) error {
+	cancel()
+	stopped := make(chan struct{})
+	go func() {
+		cm.Wait()
+		close(stopped)
+	}()
+
+	epoch := bt.core.currentEpoch()
+	const maxShutdownEpochs = 10
+	for i := 0; i < maxShutdownEpochs; i++ {
+		select {
+		case <-stopped:
+			return nil
+		case <-time.After(10 * time.Millisecond):
+		}
+		bt.core.resolveEpoch(&core.EpochMatchSummaryPayload{Epoch: epoch}, uint64(bt.lastStamp))
+		bt.sendEpoch(epoch, stopped)
+		epoch++
+	}
+
+	select {
+	case <-stopped:
+		return nil
+	case <-time.After(time.Duration(3*bt.cfg.EpochLen)*time.Millisecond + 5*time.Second):
+		return errors.New("timed out waiting for bot to stop")
+	}
+}
+
+func (bt *backtester) result() *BacktestResult {
+	stats := bt.adaptor.stats()
+	finalState := bt.adaptor.balanceState()
+
+	finalBals := make(map[uint32]uint64, len(finalState.Balances))
+	for assetID, bal := range finalState.Balances {
+		finalBals[assetID] = bal.Available + bal.Pending + bal.Locked + bal.Reserved
+	}
+
+	fees := make(map[uint32]uint64)
+	bt.core.mtx.Lock()
+	for assetID, v := range bt.core.fees {
+		fees[assetID] += v
+	}
+	dexMatches := bt.core.numMatches
+	bt.core.mtx.Unlock()
+	bt.cex.mtx.Lock()
+	for assetID, v := range bt.cex.fees {
+		fees[assetID] += v
+	}
+	cexTrades := bt.cex.numTrades
+	bt.cex.mtx.Unlock()
+
+	res := &BacktestResult{
+		Fees:           make(map[uint32]*Amount, len(fees)),
+		InventoryDrift: make(map[uint32]*Amount),
+		Epochs:         bt.epochs,
+		DEXMatches:     dexMatches,
+		CEXTrades:      cexTrades,
+		Events:         bt.eventLog.allEvents(),
+	}
+	for assetID, v := range fees {
+		res.Fees[assetID] = NewAmount(assetID, int64(v), finalState.FiatRates[assetID])
+	}
+
+	assets := make(map[uint32]bool)
+	for assetID := range stats.InitialBalances {
+		assets[assetID] = true
+	}
+	for assetID := range finalBals {
+		assets[assetID] = true
+	}
+	for assetID := range assets {
+		drift := int64(finalBals[assetID]) - int64(stats.InitialBalances[assetID]) -
+			finalState.InventoryMods[assetID] + int64(fees[assetID])
+		res.InventoryDrift[assetID] = NewAmount(assetID, drift, finalState.FiatRates[assetID])
+	}
+
+	endTime := bt.lastStamp / 1000
+	res.Overview = &MarketMakingRunOverview{
+		EndTime:         &endTime,
+		Cfgs:            []*CfgUpdate{{Timestamp: bt.firstStamp / 1000, Cfg: bt.cfg.Bot}},
+		InitialBalances: stats.InitialBalances,
+		ProfitLoss:      newProfitLoss(stats.InitialBalances, finalBals, finalState.InventoryMods, finalState.FiatRates),
+		FinalState:      finalState,
+	}
+
+	return res
+}
+
+// backtestOracle provides market prices to a basic market maker from the
+// recorded order books.
+type backtestOracle struct {
+	bt *backtester
+}
+
+var _ oracle = (*backtestOracle)(nil)
+
+func (o *backtestOracle) getMarketPrice(baseID, quoteID uint32) float64 {
+	if baseID != o.bt.cfg.Bot.BaseID || quoteID != o.bt.cfg.Bot.QuoteID {
+		return 0
+	}
+	return conventionalRate(baseID, quoteID, o.bt.marketRate())
+}
+
+// backtestEventLog is an in-memory eventLogDB for a single backtest run.
+type backtestEventLog struct {
+	mtx          sync.Mutex
+	startTime    int64
+	mkt          *MarketWithHost
+	cfg          *BotConfig
+	initialState *BalanceState
+	finalState   *BalanceState
+	endTime      *int64
+	events       map[uint64]*MarketMakingEvent
+}
+
+var _ eventLogDB = (*backtestEventLog)(nil)
+
+func newBacktestEventLog() *backtestEventLog {
+	return &backtestEventLog{
+		events: make(map[uint64]*MarketMakingEvent),
+	}
+}
+
+func (db *backtestEventLog) storeNewRun(startTime int64, mkt *MarketWithHost, cfg *BotConfig, initialState *BalanceState) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	db.startTime, db.mkt, db.cfg = startTime, mkt, cfg
+	db.initialState, db.finalState = initialState, initialState
+	return nil
+}
+
+func (db *backtestEventLog) storeEvent(startTime int64, mkt *MarketWithHost, e *MarketMakingEvent, fs *BalanceState) {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	db.events[e.ID] = e
+	if fs != nil {
+		db.finalState = fs
+	}
+}
+
+func (db *backtestEventLog) endRun(startTime int64, mkt *MarketWithHost) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	endTime := time.Now().Unix()
+	db.endTime = &endTime
+	return nil
+}
+
+func (db *backtestEventLog) runs(n uint64, refStartTime *uint64, refMkt *MarketWithHost) ([]*MarketMakingRun, error) {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	if db.mkt == nil {
+		return nil, nil
+	}
+	return []*MarketMakingRun{{StartTime: db.startTime, Market: db.mkt}}, nil
+}
+
+func (db *backtestEventLog) runOverview(startTime int64, mkt *MarketWithHost) (*MarketMakingRunOverview, error) {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	if db.mkt == nil || startTime != db.startTime {
+		return nil, fmt.Errorf("run %d not found", startTime)
+	}
+	initialBals := make(map[uint32]uint64, len(db.initialState.Balances))
+	for assetID, bal := range db.initialState.Balances {
+		initialBals[assetID] = bal.Available + bal.Pending + bal.Locked + bal.Reserved
+	}
+	finalBals := make(map[uint32]uint64, len(db.finalState.Balances))
+	for assetID, bal := range db.finalState.Balances {
+		finalBals[assetID] = bal.Available + bal.Pending + bal.Locked + bal.Reserved
+	}
+	return &MarketMakingRunOverview{
+		EndTime:         db.endTime,
+		Cfgs:            []*CfgUpdate{{Timestamp: db.startTime, Cfg: db.cfg}},
+		InitialBalances: initialBals,
+		ProfitLoss:      newProfitLoss(initialBals, finalBals, db.finalState.InventoryMods, db.finalState.FiatRates),
+		FinalState:      db.finalState,
+	}, nil
+}
+
+func (db *backtestEventLog) runEvents(startTime int64, mkt *MarketWithHost, n uint64, refID *uint64, pendingOnly bool, filters *RunLogFilters) ([]*MarketMakingEvent, error) {
+	all := db.allEvents()
+	events := make([]*MarketMakingEvent, 0, len(all))
+	// Newest first, as in the bolt event log.
+	for i := len(all) - 1; i >= 0; i-- {
+		e := all[i]
+		if refID != nil && e.ID > *refID {
+			continue
+		}
+		if pendingOnly && !e.Pending {
+			continue
+		}
+		if filters != nil && !filters.filter(e) {
+			continue
+		}
+		events = append(events, e)
+		if n > 0 && uint64(len(events)) >= n {
+			break
+		}
+	}
+	return events, nil
+}
+
+// allEvents returns all of the events, ordered by ID.
+func (db *backtestEventLog) allEvents() []*MarketMakingEvent {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	events := make([]*MarketMakingEvent, 0, len(db.events))
+	for _, e := range db.events {
+		events = append(events, e)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events
+}
@@ -0,0 +1,1086 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package mm
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/client/mm/libxc"
+	"decred.org/dcrdex/client/orderbook"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/dex/order"
+)
+
+// errBacktestUnsupported is returned from the simulated core and CEX methods
+// that have no meaning during a backtest, such as deposits and withdrawals.
+var errBacktestUnsupported = errors.New("not supported during backtest")
+
+// backtestBookFeed is a core.BookFeed that is fed by the backtest driver.
+type backtestBookFeed struct {
+	c         chan *core.BookUpdate
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+var _ core.BookFeed = (*backtestBookFeed)(nil)
+
+func (f *backtestBookFeed) Next() <-chan *core.BookUpdate { return f.c }
+func (f *backtestBookFeed) Candles(dur string) error      { return nil }
+func (f *backtestBookFeed) Close() {
+	f.closeOnce.Do(func() { close(f.closed) })
+}
+
+// backtestCore is a clientCore that simulates a DEX market using recorded
+// order book data. Orders placed by the bot are matched against the recorded
+// book when they are placed, and against the recorded taker flow in
+// subsequent epochs. All swaps complete immediately.
+type backtestCore struct {
+	cfg     *BacktestConfig
+	mktName string
+	log     dex.Logger
+	// calls is incremented on every call made by the bot, and is used by the
+	// driver to determine when the bot has gone idle.
+	calls atomic.Uint64
+
+	book  *orderbook.OrderBook
+	seq   uint64
+	notes chan core.Notification
+	// done is closed when the bot is shut down, so that notifications are
+	// not sent to a feed that is no longer being read.
+	done atomic.Value // <-chan struct{}
+
+	mtx        sync.Mutex
+	epoch      uint64
+	fiatRates  map[uint32]float64
+	feeds      []*backtestBookFeed
+	orders     map[order.OrderID]*core.Order
+	orderList  []*core.Order // in placement order
+	cancels    map[order.OrderID]bool
+	txs        map[string]*asset.WalletTransaction
+	fees       map[uint32]uint64
+	numMatches uint32
+}
+
+var _ clientCore = (*backtestCore)(nil)
+
+func newBacktestCore(cfg *BacktestConfig, log dex.Logger) (*backtestCore, error) {
+	mktName, err := dex.MarketName(cfg.Bot.BaseID, cfg.Bot.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+	fiatRates := make(map[uint32]float64, len(cfg.FiatRates))
+	for assetID, r := range cfg.FiatRates {
+		fiatRates[assetID] = r
+	}
+	return &backtestCore{
+		cfg:       cfg,
+		mktName:   mktName,
+		log:       log,
+		book:      orderbook.NewOrderBook(log.SubLogger("BOOK")),
+		notes:     make(chan core.Notification),
+		fiatRates: fiatRates,
+		orders:    make(map[order.OrderID]*core.Order),
+		cancels:   make(map[order.OrderID]bool),
+		txs:       make(map[string]*asset.WalletTransaction),
+		fees:      make(map[uint32]uint64),
+	}, nil
+}
+
+// bookOrderID generates an order ID for a recorded order from its token.
+func bookOrderID(token string) (oid order.OrderID) {
+	copy(oid[:], token)
+	return
+}
+
+func bookOrderNote(o *core.MiniOrder) *msgjson.BookOrderNote {
+	side := uint8(msgjson.BuyOrderNum)
+	if o.Sell {
+		side = msgjson.SellOrderNum
+	}
+	oid := bookOrderID(o.Token)
+	return &msgjson.BookOrderNote{
+		OrderNote: msgjson.OrderNote{OrderID: oid[:]},
+		TradeNote: msgjson.TradeNote{
+			Side:     side,
+			Quantity: o.QtyAtomic,
+			Rate:     o.MsgRate,
+			TiF:      msgjson.StandingOrderNum,
+		},
+	}
+}
+
+// applyBookEvent applies a recorded DEX order book update.
+func (c *backtestCore) applyBookEvent(e *ReplayEvent) error {
+	switch {
+	case e.DEXBook != nil:
+		snap := &msgjson.OrderBook{
+			MarketID: c.mktName,
+			Orders:   make([]*msgjson.BookOrderNote, 0, len(e.DEXBook.Sells)+len(e.DEXBook.Buys)),
+		}
+		for _, o := range e.DEXBook.Sells {
+			snap.Orders = append(snap.Orders, bookOrderNote(o))
+		}
+		for _, o := range e.DEXBook.Buys {
+			snap.Orders = append(snap.Orders, bookOrderNote(o))
+		}
+		c.seq = 0
+		return c.book.Reset(snap)
+	case e.BookOrder != nil:
+		c.seq++
+		note := bookOrderNote(e.BookOrder)
+		note.Seq, note.MarketID = c.seq, c.mktName
+		return c.book.Book(note)
+	case e.UnbookOrder != nil:
+		c.seq++
+		oid := bookOrderID(e.UnbookOrder.Token)
+		return c.book.Unbook(&msgjson.UnbookOrderNote{Seq: c.seq, MarketID: c.mktName, OrderID: oid[:]})
+	case e.UpdateRemaining != nil:
+		c.seq++
+		oid := bookOrderID(e.UpdateRemaining.Token)
+		return c.book.UpdateRemaining(&msgjson.UpdateRemainingNote{
+			OrderNote: msgjson.OrderNote{Seq: c.seq, MarketID: c.mktName, OrderID: oid[:]},
+			Remaining: e.UpdateRemaining.QtyAtomic,
+		})
+	}
+	return nil
+}
+
+func (c *backtestCore) currentEpoch() uint64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.epoch
+}
+
+// notify sends a notification to the bot, returning false if the bot has
+// been shut down.
+func (c *backtestCore) notify(n core.Notification) bool {
+	select {
+	case c.notes <- n:
+		return true
+	case <-c.done.Load().(<-chan struct{}):
+		return false
+	}
+}
+
+// openFeeds returns the book feeds that have not been closed.
+func (c *backtestCore) openFeeds() []*backtestBookFeed {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	feeds := make([]*backtestBookFeed, 0, len(c.feeds))
+	for _, f := range c.feeds {
+		select {
+		case <-f.closed:
+		default:
+			feeds = append(feeds, f)
+		}
+	}
+	c.feeds = feeds
+	return feeds
+}
+
+// copyOrder copies an order so that the bot does not share state with the
+// simulation.
+func copyOrder(o *core.Order) *core.Order {
+	cp := *o
+	cp.Matches = append([]*core.Match(nil), o.Matches...)
+	if o.FeesPaid != nil {
+		fp := *o.FeesPaid
+		cp.FeesPaid = &fp
+	}
+	return &cp
+}
+
+// updateLocked sets the amounts locked by an order based on its remaining
+// quantity. c.mtx must be locked.
+func (c *backtestCore) updateLocked(o *core.Order) {
+	o.LockedAmt, o.ParentAssetLockedAmt = 0, 0
+	if !o.Status.IsActive() {
+		return
+	}
+	lotSize := c.cfg.LotSize
+	remaining := o.Qty - o.Filled
+	lots := remaining / lotSize
+	fromAsset, fromFeeAsset, _, _ := orderAssets(o.BaseID, o.QuoteID, o.Sell)
+	swapFees := lots * c.swapFee(o.Sell)
+	if o.Sell {
+		o.LockedAmt = remaining
+	} else {
+		o.LockedAmt = calc.BaseToQuote(o.Rate, remaining)
+	}
+	if fromAsset == fromFeeAsset {
+		o.LockedAmt += swapFees
+	} else {
+		o.ParentAssetLockedAmt = swapFees
+	}
+}
+
+func (c *backtestCore) swapFee(sell bool) uint64 {
+	if sell {
+		return c.cfg.BaseFees.Swap
+	}
+	return c.cfg.QuoteFees.Swap
+}
+
+func (c *backtestCore) redeemFee(sell bool) uint64 {
+	if sell {
+		return c.cfg.QuoteFees.Redeem
+	}
+	return c.cfg.BaseFees.Redeem
+}
+
+// fill records a match for an order. The swap and redemption complete
+// immediately. c.mtx must be locked.
+func (c *backtestCore) fill(o *core.Order, rate, qty uint64, side order.MatchSide, stamp uint64) *core.Match {
+	fromAsset, fromFeeAsset, toAsset, toFeeAsset := orderAssets(o.BaseID, o.QuoteID, o.Sell)
+	swapAmt, redeemAmt := qty, calc.BaseToQuote(rate, qty)
+	if !o.Sell {
+		swapAmt, redeemAmt = redeemAmt, swapAmt
+	}
+	swapFee, redeemFee := c.swapFee(o.Sell), c.redeemFee(o.Sell)
+
+	newTx := func(txType asset.TransactionType, amt, fees uint64) []byte {
+		coinID := encode.RandomBytes(32)
+		id := hex.EncodeToString(coinID)
+		c.txs[id] = &asset.WalletTransaction{
+			Type:      txType,
+			ID:        id,
+			Amount:    amt,
+			Fees:      fees,
+			Timestamp: stamp / 1000,
+			Confirmed: true,
+		}
+		return coinID
+	}
+
+	match := &core.Match{
+		MatchID: encode.RandomBytes(32),
+		Status:  order.MatchConfirmed,
+		Rate:    rate,
+		Qty:     qty,
+		Side:    side,
+		Swap:    core.NewCoin(fromAsset, newTx(asset.Swap, swapAmt, swapFee)),
+		Redeem:  core.NewCoin(toAsset, newTx(asset.Redeem, redeemAmt, redeemFee)),
+		Stamp:   stamp,
+	}
+	o.Matches = append(o.Matches, match)
+	o.Filled += qty
+	o.FeesPaid.Swap += swapFee
+	o.FeesPaid.Redemption += redeemFee
+	c.fees[fromFeeAsset] += swapFee
+	c.fees[toFeeAsset] += redeemFee
+	c.numMatches++
+
+	if o.Qty-o.Filled < c.cfg.LotSize {
+		o.Status = order.OrderStatusExecuted
+	}
+	return match
+}
+
+// bookLevel is a price level on the recorded DEX book that may be consumed by
+// the bot's taker orders.
+type bookLevel struct {
+	rate, qty uint64
+}
+
+func (c *backtestCore) bookLevels(sell bool) []*bookLevel {
+	ords, _, err := c.book.BestNOrders(math.MaxInt32, sell)
+	if err != nil {
+		return nil
+	}
+	levels := make([]*bookLevel, 0, len(ords))
+	for _, o := range ords {
+		if n := len(levels); n > 0 && levels[n-1].rate == o.Rate {
+			levels[n-1].qty += o.Quantity
+			continue
+		}
+		levels = append(levels, &bookLevel{rate: o.Rate, qty: o.Quantity})
+	}
+	return levels
+}
+
+// resolveEpoch matches the bot's orders at the end of an epoch. Cancel
+// requests are processed first. Orders placed during the epoch are matched
+// as takers against the recorded book, and the remainder is booked. Booked
+// orders are then matched as makers against the recorded taker flow for the
+// epoch. Notifications for the updated orders are returned.
+func (c *backtestCore) resolveEpoch(report *core.EpochMatchSummaryPayload, stamp uint64) []core.Notification {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.epoch = report.Epoch + 1
+
+	updated := make(map[order.OrderID]bool)
+	notes := make([]core.Notification, 0)
+	matchNote := func(o *core.Order, m *core.Match) {
+		notes = append(notes, &core.MatchNote{
+			Notification: db.NewNotification(core.NoteTypeMatch, core.TopicRedemptionConfirmed, "", "", db.Data),
+			OrderID:      o.ID,
+			Match:        m,
+			Host:         o.Host,
+			MarketID:     o.MarketID,
+		})
+	}
+
+	for oid := range c.cancels {
+		o := c.orders[oid]
+		if o.Status == order.OrderStatusBooked {
+			o.Status = order.OrderStatusCanceled
+			o.Canceled = true
+			o.Cancelling = false
+			updated[oid] = true
+		}
+	}
+	c.cancels = make(map[order.OrderID]bool)
+
+	// Taker matching.
+	levels := map[bool][]*bookLevel{
+		true:  c.bookLevels(false),
+		false: c.bookLevels(true),
+	}
+	takers := make(map[order.OrderID]bool)
+	for _, o := range c.orderList {
+		if o.Status != order.OrderStatusEpoch || o.Epoch > report.Epoch {
+			continue
+		}
+		var oid order.OrderID
+		copy(oid[:], o.ID)
+		takers[oid] = true
+		updated[oid] = true
+		o.Status = order.OrderStatusBooked
+		for _, lvl := range levels[o.Sell] {
+			if (o.Sell && lvl.rate < o.Rate) || (!o.Sell && lvl.rate > o.Rate) {
+				break
+			}
+			qty := min(lvl.qty, o.Qty-o.Filled)
+			qty -= qty % c.cfg.LotSize
+			if qty == 0 {
+				continue
+			}
+			lvl.qty -= qty
+			matchNote(o, c.fill(o, lvl.rate, qty, order.Taker, stamp))
+			if o.Status == order.OrderStatusExecuted {
+				break
+			}
+		}
+	}
+
+	// Maker matching.
+	for _, s := range report.MatchSummaries {
+		// s.Sell indicates that the taker was a sell order.
+		makers := make([]*core.Order, 0)
+		for _, o := range c.orderList {
+			var oid order.OrderID
+			copy(oid[:], o.ID)
+			if o.Status != order.OrderStatusBooked || takers[oid] || o.Sell == s.Sell {
+				continue
+			}
+			if (o.Sell && o.Rate <= s.Rate) || (!o.Sell && o.Rate >= s.Rate) {
+				makers = append(makers, o)
+			}
+		}
+		sort.SliceStable(makers, func(i, j int) bool {
+			if makers[i].Sell {
+				return makers[i].Rate < makers[j].Rate
+			}
+			return makers[i].Rate > makers[j].Rate
+		})
+		remaining := s.Qty
+		for _, o := range makers {
+			qty := min(remaining, o.Qty-o.Filled)
+			qty -= qty % c.cfg.LotSize
+			if qty == 0 {
+				break
+			}
+			remaining -= qty
+			var oid order.OrderID
+			copy(oid[:], o.ID)
+			updated[oid] = true
+			matchNote(o, c.fill(o, o.Rate, qty, order.Maker, stamp))
+		}
+	}
+
+	matched := make(map[string]bool, len(notes))
+	for _, n := range notes {
+		matched[n.(*core.MatchNote).OrderID.String()] = true
+	}
+	for _, o := range c.orderList {
+		var oid order.OrderID
+		copy(oid[:], o.ID)
+		if !updated[oid] {
+			continue
+		}
+		c.updateLocked(o)
+		o.AllFeesConfirmed = !o.Status.IsActive()
+		if !matched[o.ID.String()] {
+			notes = append(notes, &core.OrderNote{
+				Notification: db.NewNotification(core.NoteTypeOrder, core.TopicOrderStatusUpdate, "", "", db.Data),
+				Order:        copyOrder(o),
+			})
+		}
+	}
+
+	// Completed orders are no longer needed for matching.
+	active := c.orderList[:0]
+	for _, o := range c.orderList {
+		if o.Status.IsActive() {
+			active = append(active, o)
+		}
+	}
+	c.orderList = active
+
+	return notes
+}
+
+// updateFiatRates updates the base asset's fiat rate using a market rate,
+// keeping the quote asset's fiat rate constant, and returns a copy of the
+// rates.
+func (c *backtestCore) updateFiatRates(msgRate uint64) map[uint32]float64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	baseID, quoteID := c.cfg.Bot.BaseID, c.cfg.Bot.QuoteID
+	if quoteRate := c.fiatRates[quoteID]; msgRate > 0 && quoteRate > 0 {
+		if convRate := conventionalRate(baseID, quoteID, msgRate); convRate > 0 {
+			c.fiatRates[baseID] = convRate * quoteRate
+		}
+	}
+	rates := make(map[uint32]float64, len(c.fiatRates))
+	for assetID, r := range c.fiatRates {
+		rates[assetID] = r
+	}
+	return rates
+}
+
+func conventionalRate(baseID, quoteID uint32, msgRate uint64) float64 {
+	bui, err := asset.UnitInfo(baseID)
+	if err != nil {
+		return 0
+	}
+	qui, err := asset.UnitInfo(quoteID)
+	if err != nil {
+		return 0
+	}
+	return calc.ConventionalRate(msgRate, bui, qui)
+}
+
+func (c *backtestCore) NotificationFeed() *core.NoteFeed {
+	c.calls.Add(1)
+	return &core.NoteFeed{C: c.notes}
+}
+
+func (c *backtestCore) ExchangeMarket(host string, baseID, quoteID uint32) (*core.Market, error) {
+	c.calls.Add(1)
+	if baseID != c.cfg.Bot.BaseID || quoteID != c.cfg.Bot.QuoteID {
+		return nil, fmt.Errorf("unknown market %d-%d", baseID, quoteID)
+	}
+	bui, _ := asset.UnitInfo(baseID)
+	qui, _ := asset.UnitInfo(quoteID)
+	return &core.Market{
+		Name:        c.mktName,
+		BaseID:      baseID,
+		BaseSymbol:  bui.Conventional.Unit,
+		QuoteID:     quoteID,
+		QuoteSymbol: qui.Conventional.Unit,
+		LotSize:     c.cfg.LotSize,
+		ParcelSize:  c.cfg.ParcelSize,
+		RateStep:    c.cfg.RateStep,
+		EpochLen:    c.cfg.EpochLen,
+		AtomToConv:  float64(qui.Conventional.ConversionFactor) / float64(bui.Conventional.ConversionFactor),
+	}, nil
+}
+
+func (c *backtestCore) SyncBook(host string, baseID, quoteID uint32) (*orderbook.OrderBook, core.BookFeed, error) {
+	c.calls.Add(1)
+	feed := &backtestBookFeed{
+		c:      make(chan *core.BookUpdate),
+		closed: make(chan struct{}),
+	}
+	c.mtx.Lock()
+	c.feeds = append(c.feeds, feed)
+	c.mtx.Unlock()
+	return c.book, feed, nil
+}
+
+func (c *backtestCore) SupportedAssets() map[uint32]*core.SupportedAsset {
+	c.calls.Add(1)
+	return nil
+}
+
+func (c *backtestCore) SingleLotFees(form *core.SingleLotFeesForm) (uint64, uint64, uint64, error) {
+	c.calls.Add(1)
+	if form.Sell {
+		return c.cfg.BaseFees.Swap, c.cfg.QuoteFees.Redeem, c.cfg.BaseFees.Refund, nil
+	}
+	return c.cfg.QuoteFees.Swap, c.cfg.BaseFees.Redeem, c.cfg.QuoteFees.Refund, nil
+}
+
+func (c *backtestCore) Cancel(oidB dex.Bytes) error {
+	c.calls.Add(1)
+	var oid order.OrderID
+	copy(oid[:], oidB)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	o, found := c.orders[oid]
+	if !found {
+		return fmt.Errorf("unknown order %s", oidB)
+	}
+	if o.Status != order.OrderStatusBooked {
+		return fmt.Errorf("cannot cancel %s order %s", o.Status, oidB)
+	}
+	o.Cancelling = true
+	c.cancels[oid] = true
+	return nil
+}
+
+func (c *backtestCore) AssetBalance(assetID uint32) (*core.WalletBalance, error) {
+	c.calls.Add(1)
+	return &core.WalletBalance{Balance: &db.Balance{Balance: asset.Balance{Available: c.cfg.DEXBalances[assetID]}}}, nil
+}
+
+func (c *backtestCore) WalletTraits(assetID uint32) (asset.WalletTrait, error) {
+	c.calls.Add(1)
+	return 0, nil
+}
+
+func (c *backtestCore) MultiTrade(pw []byte, form *core.MultiTradeForm) []*core.MultiTradeResult {
+	c.calls.Add(1)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	results := make([]*core.MultiTradeResult, 0, len(form.Placements))
+	for _, p := range form.Placements {
+		if p.Qty == 0 || p.Qty%c.cfg.LotSize != 0 {
+			results = append(results, &core.MultiTradeResult{
+				Error: fmt.Errorf("quantity %d is not a multiple of lot size %d", p.Qty, c.cfg.LotSize),
+			})
+			continue
+		}
+		if p.Rate == 0 || (c.cfg.RateStep > 0 && p.Rate%c.cfg.RateStep != 0) {
+			results = append(results, &core.MultiTradeResult{
+				Error: fmt.Errorf("rate %d is not a multiple of rate step %d", p.Rate, c.cfg.RateStep),
+			})
+			continue
+		}
+		var oid order.OrderID
+		copy(oid[:], encode.RandomBytes(32))
+		o := &core.Order{
+			Host:        form.Host,
+			BaseID:      form.Base,
+			QuoteID:     form.Quote,
+			MarketID:    c.mktName,
+			Type:        order.LimitOrderType,
+			ID:          oid[:],
+			Status:      order.OrderStatusEpoch,
+			Epoch:       c.epoch,
+			Qty:         p.Qty,
+			Sell:        form.Sell,
+			Rate:        p.Rate,
+			TimeInForce: order.StandingTiF,
+			FeesPaid:    &core.FeeBreakdown{},
+		}
+		c.updateLocked(o)
+		c.orders[oid] = o
+		c.orderList = append(c.orderList, o)
+		results = append(results, &core.MultiTradeResult{Order: copyOrder(o)})
+	}
+	return results
+}
+
+func (c *backtestCore) MaxFundingFees(fromAsset uint32, host string, numTrades uint32, fromSettings map[string]string) (uint64, error) {
+	c.calls.Add(1)
+	return 0, nil
+}
+
+func (c *backtestCore) Login(pw []byte) error {
+	c.calls.Add(1)
+	return nil
+}
+
+func (c *backtestCore) OpenWallet(assetID uint32, appPW []byte) error {
+	c.calls.Add(1)
+	return nil
+}
+
+func (c *backtestCore) Broadcast(core.Notification) {}
+
+func (c *backtestCore) FiatConversionRates() map[uint32]float64 {
+	c.calls.Add(1)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	rates := make(map[uint32]float64, len(c.fiatRates))
+	for assetID, r := range c.fiatRates {
+		rates[assetID] = r
+	}
+	return rates
+}
+
+func (c *backtestCore) Send(pw []byte, assetID uint32, value uint64, address string, subtract bool) (asset.Coin, error) {
+	c.calls.Add(1)
+	return nil, errBacktestUnsupported
+}
+
+func (c *backtestCore) EstimateSendTxFee(address string, assetID uint32, amount uint64, subtract, maxWithdraw bool) (uint64, bool, error) {
+	c.calls.Add(1)
+	return 0, false, errBacktestUnsupported
+}
+
+func (c *backtestCore) NewDepositAddress(assetID uint32) (string, error) {
+	c.calls.Add(1)
+	return "", errBacktestUnsupported
+}
+
+func (c *backtestCore) Network() dex.Network {
+	return dex.Mainnet
+}
+
+func (c *backtestCore) Order(oidB dex.Bytes) (*core.Order, error) {
+	c.calls.Add(1)
+	var oid order.OrderID
+	copy(oid[:], oidB)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	o, found := c.orders[oid]
+	if !found {
+		return nil, fmt.Errorf("order %s not found", oidB)
+	}
+	return copyOrder(o), nil
+}
+
+func (c *backtestCore) WalletTransaction(assetID uint32, txID string) (*asset.WalletTransaction, error) {
+	c.calls.Add(1)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	tx, found := c.txs[txID]
+	if !found {
+		return nil, asset.CoinNotFoundError
+	}
+	cp := *tx
+	return &cp, nil
+}
+
+func (c *backtestCore) TradingLimits(host string) (userParcels, parcelLimit uint32, err error) {
+	c.calls.Add(1)
+	return 0, math.MaxUint32, nil
+}
+
+func (c *backtestCore) WalletState(assetID uint32) *core.WalletState {
+	c.calls.Add(1)
+	return &core.WalletState{
+		AssetID:   assetID,
+		Open:      true,
+		Running:   true,
+		PeerCount: 1,
+		Synced:    true,
+	}
+}
+
+func (c *backtestCore) Exchange(host string) (*core.Exchange, error) {
+	c.calls.Add(1)
+	return &core.Exchange{
+		Host: host,
+		Auth: core.ExchangeAuth{EffectiveTier: 1},
+	}, nil
+}
+
+// cexLevel is a price level on a recorded CEX book. The quantity is reduced
+// as the bot's trades consume the level, until the next snapshot arrives.
+type cexLevel struct {
+	rate, qty uint64
+}
+
+type cexBook struct {
+	buys, sells []*cexLevel
+	snap        *CEXBookSnapshot
+}
+
+// backtestTrade is a trade on the simulated CEX. The filled quantities before
+// fees are tracked separately from the libxc.Trade, which reports the filled
+// amounts net of fees.
+type backtestTrade struct {
+	*libxc.Trade
+	grossBase, grossQuote uint64
+	fee                   uint64
+}
+
+// backtestCEX is a libxc.CEX that simulates a centralized exchange using
+// recorded order book snapshots. Trades are filled against the most recent
+// snapshot. Limit orders that do not cross the book rest until a later
+// snapshot crosses their rate. Trading fees are charged in the asset
+// received.
+type backtestCEX struct {
+	feeRate float64
+	log     dex.Logger
+	// calls is incremented on every call made by the bot, and is used by the
+	// driver to determine when the bot has gone idle.
+	calls   atomic.Uint64
+	updates chan *libxc.Trade
+
+	mtx       sync.Mutex
+	books     map[[2]uint32]*cexBook
+	trades    map[string]*backtestTrade
+	open      map[string]*backtestTrade
+	queued    []*libxc.Trade
+	tradeID   uint64
+	fees      map[uint32]uint64
+	numTrades uint32
+}
+
+var _ libxc.CEX = (*backtestCEX)(nil)
+
+func newBacktestCEX(feeRate float64, log dex.Logger) *backtestCEX {
+	return &backtestCEX{
+		feeRate: feeRate,
+		log:     log,
+		updates: make(chan *libxc.Trade),
+		books:   make(map[[2]uint32]*cexBook),
+		trades:  make(map[string]*backtestTrade),
+		open:    make(map[string]*backtestTrade),
+		fees:    make(map[uint32]uint64),
+	}
+}
+
+// updateBook stores a new order book snapshot and fills any resting trades
+// that cross it.
+func (c *backtestCEX) updateBook(snap *CEXBookSnapshot) {
+	levels := func(ords []*core.MiniOrder) []*cexLevel {
+		lvls := make([]*cexLevel, 0, len(ords))
+		for _, o := range ords {
+			lvls = append(lvls, &cexLevel{rate: o.MsgRate, qty: o.QtyAtomic})
+		}
+		return lvls
+	}
+	book := &cexBook{
+		buys:  levels(snap.Buys),
+		sells: levels(snap.Sells),
+		snap:  snap,
+	}
+	sort.Slice(book.buys, func(i, j int) bool { return book.buys[i].rate > book.buys[j].rate })
+	sort.Slice(book.sells, func(i, j int) bool { return book.sells[i].rate < book.sells[j].rate })
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.books[[2]uint32{snap.BaseID, snap.QuoteID}] = book
+
+	ids := make([]string, 0, len(c.open))
+	for id, t := range c.open {
+		if t.BaseID == snap.BaseID && t.QuoteID == snap.QuoteID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		t := c.open[id]
+		if !c.match(t, book, true) {
+			continue
+		}
+		if t.grossBase >= t.Qty {
+			t.Complete = true
+			delete(c.open, id)
+		}
+		cp := *t.Trade
+		c.queued = append(c.queued, &cp)
+	}
+}
+
+// match fills a trade against a book, returning true if anything was filled.
+// Resting orders fill at their own rate. c.mtx must be locked.
+func (c *backtestCEX) match(t *backtestTrade, book *cexBook, resting bool) bool {
+	levels := book.sells
+	if t.Sell {
+		levels = book.buys
+	}
+	var filled bool
+	for _, lvl := range levels {
+		if !t.Market && ((t.Sell && lvl.rate < t.Rate) || (!t.Sell && lvl.rate > t.Rate)) {
+			break
+		}
+		rate := lvl.rate
+		if resting {
+			rate = t.Rate
+		}
+		var qty uint64
+		if t.Market && !t.Sell {
+			qty = min(lvl.qty, calc.QuoteToBase(rate, t.Qty-t.grossQuote))
+		} else {
+			qty = min(lvl.qty, t.Qty-t.grossBase)
+		}
+		if qty == 0 {
+			break
+		}
+		lvl.qty -= qty
+		t.grossBase += qty
+		t.grossQuote += calc.BaseToQuote(rate, qty)
+		filled = true
+	}
+	if !filled {
+		return false
+	}
+
+	// Fees are charged in the asset received.
+	if t.Sell {
+		fee := uint64(math.Round(float64(t.grossQuote) * c.feeRate))
+		c.fees[t.QuoteID] += fee - t.fee
+		t.fee = fee
+		t.BaseFilled, t.QuoteFilled = t.grossBase, t.grossQuote-fee
+	} else {
+		fee := uint64(math.Round(float64(t.grossBase) * c.feeRate))
+		c.fees[t.BaseID] += fee - t.fee
+		t.fee = fee
+		t.BaseFilled, t.QuoteFilled = t.grossBase-fee, t.grossQuote
+	}
+	return true
+}
+
+// flushUpdates returns the trade updates that have been queued since the
+// last call.
+func (c *backtestCEX) flushUpdates() []*libxc.Trade {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	updates := c.queued
+	c.queued = nil
+	return updates
+}
+
+func (c *backtestCEX) midGap(baseID, quoteID uint32) uint64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	book := c.books[[2]uint32{baseID, quoteID}]
+	if book == nil || len(book.snap.Buys) == 0 || len(book.snap.Sells) == 0 {
+		return 0
+	}
+	return (book.snap.Buys[0].MsgRate + book.snap.Sells[0].MsgRate) / 2
+}
+
+func (c *backtestCEX) Connect(ctx context.Context) (*sync.WaitGroup, error) {
+	return &sync.WaitGroup{}, nil
+}
+
+func (c *backtestCEX) Balance(assetID uint32) (*libxc.ExchangeBalance, error) {
+	c.calls.Add(1)
+	return nil, errBacktestUnsupported
+}
+
+func (c *backtestCEX) Balances(ctx context.Context) (map[uint32]*libxc.ExchangeBalance, error) {
+	c.calls.Add(1)
+	return nil, errBacktestUnsupported
+}
+
+func (c *backtestCEX) CancelTrade(ctx context.Context, baseID, quoteID uint32, tradeID string) error {
+	c.calls.Add(1)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	t, found := c.open[tradeID]
+	if !found {
+		return nil
+	}
+	t.Complete = true
+	delete(c.open, tradeID)
+	cp := *t.Trade
+	c.queued = append(c.queued, &cp)
+	return nil
+}
+
+func (c *backtestCEX) Markets(ctx context.Context) (map[string]*libxc.Market, error) {
+	c.calls.Add(1)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	mkts := make(map[string]*libxc.Market, len(c.books))
+	for mkt := range c.books {
+		name, err := dex.MarketName(mkt[0], mkt[1])
+		if err != nil {
+			continue
+		}
+		mkts[name] = &libxc.Market{BaseID: mkt[0], QuoteID: mkt[1]}
+	}
+	return mkts, nil
+}
+
+func (c *backtestCEX) SubscribeMarket(ctx context.Context, baseID, quoteID uint32) error {
+	c.calls.Add(1)
+	return nil
+}
+
+func (c *backtestCEX) SubscribeTradeUpdates() (updates <-chan *libxc.Trade, unsubscribe func(), subscriptionID int) {
+	c.calls.Add(1)
+	return c.updates, func() {}, 0
+}
+
+func (c *backtestCEX) Trade(ctx context.Context, baseID, quoteID uint32, sell bool, rate, qty, quoteQty uint64, orderType libxc.OrderType, subscriptionID int) (*libxc.Trade, error) {
+	c.calls.Add(1)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	book := c.books[[2]uint32{baseID, quoteID}]
+	if book == nil {
+		return nil, fmt.Errorf("no order book for market %d-%d", baseID, quoteID)
+	}
+
+	market := orderType == libxc.OrderTypeMarket
+	if qty > 0 && quoteQty > 0 {
+		return nil, errors.New("only one of qty or quoteQty may be specified")
+	}
+	if quoteQty > 0 {
+		if sell {
+			return nil, errors.New("quote quantity cannot be used for sell orders")
+		}
+		if !market {
+			qty = calc.QuoteToBase(rate, quoteQty)
+		}
+	} else if market && !sell {
+		return nil, errors.New("market buy orders require a quote quantity")
+	}
+
+	c.tradeID++
+	t := &backtestTrade{
+		Trade: &libxc.Trade{
+			ID:      strconv.FormatUint(c.tradeID, 10),
+			Sell:    sell,
+			Qty:     qty,
+			Market:  market,
+			Rate:    rate,
+			BaseID:  baseID,
+			QuoteID: quoteID,
+		},
+	}
+	if market && !sell {
+		t.Qty = quoteQty
+	}
+	if t.Qty == 0 {
+		return nil, errors.New("zero quantity")
+	}
+
+	c.numTrades++
+	c.trades[t.ID] = t
+	c.match(t, book, false)
+	if market || orderType == libxc.OrderTypeLimitIOC || t.grossBase >= t.Qty {
+		t.Complete = true
+	} else {
+		c.open[t.ID] = t
+	}
+
+	cp := *t.Trade
+	return &cp, nil
+}
+
+func (c *backtestCEX) ValidateTrade(baseID, quoteID uint32, sell bool, rate, qty, quoteQty uint64, orderType libxc.OrderType) error {
+	c.calls.Add(1)
+	return nil
+}
+
+func (c *backtestCEX) UnsubscribeMarket(baseID, quoteID uint32) error {
+	c.calls.Add(1)
+	return nil
+}
+
+// vwap computes the volume weighted average rate for a quantity on one side
+// of a book. If quote is true, qty is in units of the quote asset.
+func (c *backtestCEX) vwap(baseID, quoteID uint32, sell bool, qty uint64, quote bool) (vwap, extrema uint64, filled bool, err error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	book := c.books[[2]uint32{baseID, quoteID}]
+	if book == nil {
+		return 0, 0, false, fmt.Errorf("no order book for market %d-%d", baseID, quoteID)
+	}
+	if qty == 0 {
+		return 0, 0, false, nil
+	}
+	levels := book.buys
+	if sell {
+		levels = book.sells
+	}
+	var baseTotal, quoteTotal uint64
+	for _, lvl := range levels {
+		extrema = lvl.rate
+		lvlQuote := calc.BaseToQuote(lvl.rate, lvl.qty)
+		if quote && lvlQuote >= qty-quoteTotal {
+			baseTotal += calc.QuoteToBase(lvl.rate, qty-quoteTotal)
+			quoteTotal = qty
+			filled = true
+			break
+		}
+		if !quote && lvl.qty >= qty-baseTotal {
+			quoteTotal += calc.BaseToQuote(lvl.rate, qty-baseTotal)
+			baseTotal = qty
+			filled = true
+			break
+		}
+		baseTotal += lvl.qty
+		quoteTotal += lvlQuote
+	}
+	if !filled || baseTotal == 0 {
+		return 0, 0, false, nil
+	}
+	return calc.BaseQuoteToRate(baseTotal, quoteTotal), extrema, true, nil
+}
+
+func (c *backtestCEX) VWAP(baseID, quoteID uint32, sell bool, qty uint64) (vwap, extrema uint64, filled bool, err error) {
+	c.calls.Add(1)
+	return c.vwap(baseID, quoteID, sell, qty, false)
+}
+
+func (c *backtestCEX) InvVWAP(baseID, quoteID uint32, sell bool, qty uint64) (vwap, extrema uint64, filled bool, err error) {
+	c.calls.Add(1)
+	return c.vwap(baseID, quoteID, sell, qty, true)
+}
+
+func (c *backtestCEX) MidGap(baseID, quoteID uint32) uint64 {
+	c.calls.Add(1)
+	return c.midGap(baseID, quoteID)
+}
+
+func (c *backtestCEX) GetDepositAddress(ctx context.Context, assetID uint32) (string, error) {
+	c.calls.Add(1)
+	return "", errBacktestUnsupported
+}
+
+func (c *backtestCEX) ConfirmDeposit(ctx context.Context, deposit *libxc.DepositData) (bool, uint64) {
+	c.calls.Add(1)
+	return false, 0
+}
+
+func (c *backtestCEX) Withdraw(ctx context.Context, assetID uint32, amt uint64, address string) (string, uint64, error) {
+	c.calls.Add(1)
+	return "", 0, errBacktestUnsupported
+}
+
+func (c *backtestCEX) ConfirmWithdrawal(ctx context.Context, withdrawalID string, assetID uint32) (uint64, string, error) {
+	c.calls.Add(1)
+	return 0, "", errBacktestUnsupported
+}
+
+func (c *backtestCEX) TradeStatus(ctx context.Context, id string, baseID, quoteID uint32) (*libxc.Trade, error) {
+	c.calls.Add(1)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	t, found := c.trades[id]
+	if !found {
+		return nil, fmt.Errorf("trade %s not found", id)
+	}
+	cp := *t.Trade
+	return &cp, nil
+}
+
+func (c *backtestCEX) Book(baseID, quoteID uint32) (buys, sells []*core.MiniOrder, _ error) {
+	c.calls.Add(1)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	book := c.books[[2]uint32{baseID, quoteID}]
+	if book == nil {
+		return nil, nil, fmt.Errorf("no order book for market %d-%d", baseID, quoteID)
+	}
+	return book.snap.Buys, book.snap.Sells, nil
+}
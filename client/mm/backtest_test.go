@@ -0,0 +1,210 @@
+package mm
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/mm/libxc"
+	"decred.org/dcrdex/client/orderbook"
+)
+
+type tReplaySource struct {
+	events []*ReplayEvent
+}
+
+func (s *tReplaySource) Next() (*ReplayEvent, error) {
+	if len(s.events) == 0 {
+		return nil, io.EOF
+	}
+	e := s.events[0]
+	s.events = s.events[1:]
+	return e, nil
+}
+
+func TestBacktestBasicMM(t *testing.T) {
+	const lotSize = 1e8
+	const dcrID, btcID = 42, 0
+
+	miniOrder := func(token string, sell bool, rate uint64) *core.MiniOrder {
+		return &core.MiniOrder{Token: token, Sell: sell, MsgRate: rate, QtyAtomic: 5 * lotSize}
+	}
+
+	var stamp int64 = 1e12
+	event := func(e *ReplayEvent) *ReplayEvent {
+		stamp += 10_000
+		e.Stamp = stamp
+		return e
+	}
+	epochReport := func(epoch uint64, summaries ...*orderbook.MatchSummary) *ReplayEvent {
+		return event(&ReplayEvent{EpochReport: &core.EpochMatchSummaryPayload{Epoch: epoch, MatchSummaries: summaries}})
+	}
+
+	events := []*ReplayEvent{
+		event(&ReplayEvent{DEXBook: &core.OrderBook{
+			Sells: []*core.MiniOrder{miniOrder("aaaaaaaa", true, 40500)},
+			Buys:  []*core.MiniOrder{miniOrder("bbbbbbbb", false, 39500)},
+		}}),
+		event(&ReplayEvent{CEXBook: &CEXBookSnapshot{
+			BaseID:  dcrID,
+			QuoteID: btcID,
+			Buys:    []*core.MiniOrder{miniOrder("", false, 39990)},
+			Sells:   []*core.MiniOrder{miniOrder("", true, 40010)},
+		}}),
+		// The bot places its orders in epoch 101.
+		epochReport(100),
+		// The bot's orders are booked at the end of epoch 101.
+		epochReport(101),
+		event(&ReplayEvent{BookOrder: miniOrder("cccccccc", true, 40600)}),
+		event(&ReplayEvent{UnbookOrder: &core.MiniOrder{Token: "cccccccc"}}),
+		// A taker buy and a taker sell fill the bot's orders.
+		epochReport(102,
+			&orderbook.MatchSummary{Rate: 40300, Qty: lotSize, Sell: false},
+			&orderbook.MatchSummary{Rate: 39700, Qty: lotSize, Sell: true},
+		),
+		epochReport(103),
+		// These do not reach the bot's orders.
+		epochReport(104,
+			&orderbook.MatchSummary{Rate: 40100, Qty: lotSize, Sell: false},
+			&orderbook.MatchSummary{Rate: 39900, Qty: lotSize, Sell: true},
+		),
+	}
+
+	cfg := &BacktestConfig{
+		Bot: &BotConfig{
+			Host:    "dex.com",
+			BaseID:  dcrID,
+			QuoteID: btcID,
+			BasicMMConfig: &BasicMarketMakingConfig{
+				GapStrategy:    GapStrategyPercent,
+				SellPlacements: []*OrderPlacement{{Lots: 1, GapFactor: 0.005}},
+				BuyPlacements:  []*OrderPlacement{{Lots: 1, GapFactor: 0.005}},
+			},
+		},
+		DEXBalances: map[uint32]uint64{
+			dcrID: 10 * lotSize,
+			btcID: 1e7,
+		},
+		LotSize:   lotSize,
+		RateStep:  10,
+		EpochLen:  10_000,
+		BaseFees:  &LotFees{Swap: 2000, Redeem: 1500, Refund: 1500},
+		QuoteFees: &LotFees{Swap: 300, Redeem: 200, Refund: 200},
+		FiatRates: map[uint32]float64{
+			dcrID: 20,
+			btcID: 50_000,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	res, err := RunBacktest(ctx, cfg, &tReplaySource{events: events}, tLogger)
+	if err != nil {
+		t.Fatalf("RunBacktest error: %v", err)
+	}
+
+	if res.Epochs != 5 {
+		t.Fatalf("expected 5 epochs, got %d", res.Epochs)
+	}
+	if res.DEXMatches != 2 {
+		t.Fatalf("expected 2 matches, got %d", res.DEXMatches)
+	}
+
+	// The sell at 40200 and the buy at 39800 net 400 sats.
+	expDrift := map[uint32]int64{dcrID: 0, btcID: 400}
+	expFees := map[uint32]int64{dcrID: 2000 + 1500, btcID: 300 + 200}
+	for assetID, exp := range expDrift {
+		if drift := res.InventoryDrift[assetID]; drift == nil || drift.Atoms != exp {
+			t.Fatalf("wrong inventory drift for asset %d. expected %d, got %+v", assetID, exp, drift)
+		}
+	}
+	for assetID, exp := range expFees {
+		if fees := res.Fees[assetID]; fees == nil || fees.Atoms != exp {
+			t.Fatalf("wrong fees for asset %d. expected %d, got %+v", assetID, exp, fees)
+		}
+	}
+
+	pl := res.Overview.ProfitLoss
+	for assetID, exp := range expDrift {
+		expDiff := exp - expFees[assetID]
+		if diff := pl.Diffs[assetID]; diff == nil || diff.Atoms != expDiff {
+			t.Fatalf("wrong diff for asset %d. expected %d, got %+v", assetID, expDiff, diff)
+		}
+	}
+	if *res.Overview.EndTime != stamp/1000 {
+		t.Fatalf("wrong end time %d", *res.Overview.EndTime)
+	}
+
+	var dexOrderEvents int
+	for _, e := range res.Events {
+		if e.DEXOrderEvent != nil {
+			dexOrderEvents++
+		}
+	}
+	if dexOrderEvents < 2 {
+		t.Fatalf("expected at least 2 DEX order events, got %d", dexOrderEvents)
+	}
+}
+
+func TestBacktestCEXTrade(t *testing.T) {
+	const baseID, quoteID = 42, 0
+	const feeRate = 0.001
+	cex := newBacktestCEX(feeRate, tLogger)
+	cex.updateBook(&CEXBookSnapshot{
+		BaseID:  baseID,
+		QuoteID: quoteID,
+		Buys: []*core.MiniOrder{
+			{MsgRate: 40000, QtyAtomic: 1e8},
+			{MsgRate: 39000, QtyAtomic: 1e8},
+		},
+		Sells: []*core.MiniOrder{
+			{MsgRate: 41000, QtyAtomic: 1e8},
+		},
+	})
+
+	ctx := context.Background()
+
+	// A limit sell that crosses both bid levels fills at the book's rates.
+	trade, err := cex.Trade(ctx, baseID, quoteID, true, 39000, 2e8, 0, libxc.OrderTypeLimit, 0)
+	if err != nil {
+		t.Fatalf("Trade error: %v", err)
+	}
+	if !trade.Complete || trade.BaseFilled != 2e8 || trade.QuoteFilled != 79000-79 {
+		t.Fatalf("unexpected trade %+v", trade)
+	}
+
+	// A limit buy below the ask rests on the book.
+	trade, err = cex.Trade(ctx, baseID, quoteID, false, 40500, 1e8, 0, libxc.OrderTypeLimit, 0)
+	if err != nil {
+		t.Fatalf("Trade error: %v", err)
+	}
+	if trade.Complete || trade.BaseFilled != 0 {
+		t.Fatalf("unexpected trade %+v", trade)
+	}
+	if updates := cex.flushUpdates(); len(updates) != 0 {
+		t.Fatalf("unexpected updates %+v", updates)
+	}
+
+	// A new snapshot that crosses the resting order fills it at its rate.
+	cex.updateBook(&CEXBookSnapshot{
+		BaseID:  baseID,
+		QuoteID: quoteID,
+		Buys:    []*core.MiniOrder{{MsgRate: 40000, QtyAtomic: 1e8}},
+		Sells:   []*core.MiniOrder{{MsgRate: 40400, QtyAtomic: 5e8}},
+	})
+	updates := cex.flushUpdates()
+	if len(updates) != 1 {
+		t.Fatalf("expected 1 update, got %d", len(updates))
+	}
+	u := updates[0]
+	if u.ID != trade.ID || !u.Complete || u.BaseFilled != 1e8-1e5 || u.QuoteFilled != 40500 {
+		t.Fatalf("unexpected update %+v", u)
+	}
+
+	if cex.fees[quoteID] != 79 || cex.fees[baseID] != 1e5 {
+		t.Fatalf("unexpected fees %+v", cex.fees)
+	}
+}
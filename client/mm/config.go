@@ -32,6 +32,9 @@ type CEXConfig struct {
 	APIKey string `json:"apiKey"`
 	// APISecret is the API secret for the CEX.
 	APISecret string `json:"apiSecret"`
+	// APIPassphrase is the passphrase that was set when creating the API
+	// key. Only required by some CEXes.
+	APIPassphrase string `json:"apiPassphrase,omitempty"`
 }
 
 // AutoRebalanceConfig configures deposits and withdrawals by setting minimum
@@ -63,6 +66,70 @@ func (a *AutoRebalanceConfig) copy() *AutoRebalanceConfig {
 	}
 }
 
+// RiskLimitAction is the action a bot takes when one of its RiskLimits is
+// crossed.
+type RiskLimitAction string
+
+const (
+	// RiskLimitPause cancels the bot's orders and pauses its trading loop.
+	// The bot resumes when its configuration is updated.
+	RiskLimitPause RiskLimitAction = "pause"
+	// RiskLimitStop cancels the bot's orders and stops the bot.
+	RiskLimitStop RiskLimitAction = "stop"
+)
+
+// RiskLimits are guardrails that pause or stop a running bot. A zero value
+// disables the corresponding limit.
+type RiskLimits struct {
+	// MaxDrawdownUSD is the maximum decline, in USD, of the bot's profit from
+	// its peak since the bot was started or last resumed.
+	MaxDrawdownUSD float64 `json:"maxDrawdownUSD"`
+	// MaxInventorySkew is the maximum change, per asset, in the fraction of
+	// the bot's total fiat value held in the asset, relative to the asset's
+	// share of the bot's allocation. A limit of 0.25 on an asset that makes up
+	// half of the allocation allows its share to range from 25% to 75%.
+	MaxInventorySkew map[uint32]float64 `json:"maxInventorySkew,omitempty"`
+	// MaxConsecutiveCEXFailures is the maximum number of CEX trades in a row
+	// that the CEX fails to accept.
+	MaxConsecutiveCEXFailures uint32 `json:"maxConsecutiveCEXFailures"`
+	// MaxDEXMatchFailuresPerHour is the maximum number of the bot's DEX
+	// matches that may be revoked in any one hour period.
+	MaxDEXMatchFailuresPerHour uint32 `json:"maxDEXMatchFailuresPerHour"`
+	// Action is the action taken when a limit is crossed. The default is
+	// RiskLimitPause.
+	Action RiskLimitAction `json:"action,omitempty"`
+}
+
+func (r *RiskLimits) copy() *RiskLimits {
+	c := *r
+	c.MaxInventorySkew = utils.CopyMap(r.MaxInventorySkew)
+	return &c
+}
+
+func (r *RiskLimits) validate() error {
+	if r.MaxDrawdownUSD < 0 {
+		return fmt.Errorf("negative max drawdown %f", r.MaxDrawdownUSD)
+	}
+	for assetID, skew := range r.MaxInventorySkew {
+		if skew < 0 || skew > 1 {
+			return fmt.Errorf("max inventory skew %f for asset %d is not between 0 and 1", skew, assetID)
+		}
+	}
+	switch r.Action {
+	case "", RiskLimitPause, RiskLimitStop:
+	default:
+		return fmt.Errorf("unknown risk limit action %q", r.Action)
+	}
+	return nil
+}
+
+func (r *RiskLimits) action() RiskLimitAction {
+	if r.Action == "" {
+		return RiskLimitPause
+	}
+	return r.Action
+}
+
 // BotBalanceAllocation is the initial allocation of funds for a bot.
 type BotBalanceAllocation struct {
 	DEX map[uint32]uint64 `json:"dex"`
@@ -155,10 +222,15 @@ type BotConfig struct {
 	// when they are starting the bot.
 	LotSize uint64 `json:"lotSize"`
 
+	// RiskLimits are optional guardrails that pause or stop the bot.
+	RiskLimits *RiskLimits `json:"riskLimits,omitempty"`
+
 	// Only one of the following configs should be set
 	BasicMMConfig        *BasicMarketMakingConfig `json:"basicMarketMakingConfig,omitempty"`
 	SimpleArbConfig      *SimpleArbConfig         `json:"simpleArbConfig,omitempty"`
 	ArbMarketMakerConfig *ArbMarketMakerConfig    `json:"arbMarketMakingConfig,omitempty"`
+	TriangularArbConfig  *TriangularArbConfig     `json:"triangularArbConfig,omitempty"`
+	CrossHostArbConfig   *CrossHostArbConfig      `json:"crossHostArbConfig,omitempty"`
 }
 
 func (c *BotConfig) copy() *BotConfig {
@@ -174,6 +246,9 @@ func (c *BotConfig) copy() *BotConfig {
 	if c.RPCConfig != nil {
 		b.RPCConfig = c.RPCConfig.copy()
 	}
+	if c.RiskLimits != nil {
+		b.RiskLimits = c.RiskLimits.copy()
+	}
 	if c.BasicMMConfig != nil {
 		b.BasicMMConfig = c.BasicMMConfig.copy()
 	}
@@ -183,6 +258,12 @@ func (c *BotConfig) copy() *BotConfig {
 	if c.ArbMarketMakerConfig != nil {
 		b.ArbMarketMakerConfig = c.ArbMarketMakerConfig.copy()
 	}
+	if c.TriangularArbConfig != nil {
+		b.TriangularArbConfig = c.TriangularArbConfig.copy()
+	}
+	if c.CrossHostArbConfig != nil {
+		b.CrossHostArbConfig = c.CrossHostArbConfig.copy()
+	}
 
 	return &b
 }
@@ -198,12 +279,22 @@ func (c *BotConfig) updateLotSize(oldLotSize, newLotSize uint64) {
 }
 
 func (c *BotConfig) validate() error {
+	if c.RiskLimits != nil {
+		if err := c.RiskLimits.validate(); err != nil {
+			return fmt.Errorf("invalid risk limits: %w", err)
+		}
+	}
+
 	if c.BasicMMConfig != nil {
 		return c.BasicMMConfig.validate()
 	} else if c.SimpleArbConfig != nil {
 		return c.SimpleArbConfig.validate()
 	} else if c.ArbMarketMakerConfig != nil {
 		return c.ArbMarketMakerConfig.validate(c.BaseID, c.QuoteID)
+	} else if c.TriangularArbConfig != nil {
+		return c.TriangularArbConfig.validate(c.BaseID, c.QuoteID)
+	} else if c.CrossHostArbConfig != nil {
+		return c.CrossHostArbConfig.validate(c.Host)
 	}
 
 	return fmt.Errorf("no bot config set")
@@ -212,11 +303,29 @@ func (c *BotConfig) validate() error {
 func validateConfigUpdate(old, new *BotConfig) error {
 	if (old.BasicMMConfig == nil) != (new.BasicMMConfig == nil) ||
 		(old.SimpleArbConfig == nil) != (new.SimpleArbConfig == nil) ||
-		(old.ArbMarketMakerConfig == nil) != (new.ArbMarketMakerConfig == nil) {
+		(old.ArbMarketMakerConfig == nil) != (new.ArbMarketMakerConfig == nil) ||
+		(old.TriangularArbConfig == nil) != (new.TriangularArbConfig == nil) ||
+		(old.CrossHostArbConfig == nil) != (new.CrossHostArbConfig == nil) {
 		return fmt.Errorf("cannot change bot type")
 	}
 
-	return new.validate()
+	if err := new.validate(); err != nil {
+		return err
+	}
+
+	// The candle subscription used by the Avellaneda-Stoikov strategy is set
+	// up when the bot starts.
+	if old.BasicMMConfig != nil {
+		oldAS, newAS := old.BasicMMConfig.AvellanedaStoikov, new.BasicMMConfig.AvellanedaStoikov
+		if (oldAS == nil) != (newAS == nil) {
+			return fmt.Errorf("cannot change to or from the %s gap strategy", GapStrategyAvellanedaStoikov)
+		}
+		if oldAS != nil && (oldAS.VolatilityBinSize != newAS.VolatilityBinSize || oldAS.VolatilityPeriods != newAS.VolatilityPeriods) {
+			return fmt.Errorf("cannot change the volatility bin size or periods")
+		}
+	}
+
+	return nil
 }
 
 func (c *BotConfig) requiresPriceOracle() bool {
@@ -224,7 +333,20 @@ func (c *BotConfig) requiresPriceOracle() bool {
 }
 
 func (c *BotConfig) requiresCEX() bool {
-	return c.SimpleArbConfig != nil || c.ArbMarketMakerConfig != nil
+	return c.SimpleArbConfig != nil || c.ArbMarketMakerConfig != nil || c.TriangularArbConfig != nil
+}
+
+// extraAssets returns the assets used by the bot other than the market's base
+// and quote assets and their fee assets.
+func (c *BotConfig) extraAssets() []uint32 {
+	if c.TriangularArbConfig == nil {
+		return nil
+	}
+	assetID := c.TriangularArbConfig.DEXMarket[1]
+	if feeAssetID := feeAssetID(assetID); feeAssetID != assetID {
+		return []uint32{assetID, feeAssetID}
+	}
+	return []uint32{assetID}
 }
 
 // multiSplitBuffer returns the additional buffer to add to the order size
@@ -248,7 +370,7 @@ func (c *BotConfig) multiSplitBuffer() float64 {
 // either side of the market in an epoch.
 func (c *BotConfig) maxPlacements() (buy, sell uint32) {
 	switch {
-	case c.SimpleArbConfig != nil:
+	case c.SimpleArbConfig != nil, c.TriangularArbConfig != nil, c.CrossHostArbConfig != nil:
 		return 1, 1
 	case c.ArbMarketMakerConfig != nil:
 		return uint32(len(c.ArbMarketMakerConfig.BuyPlacements)), uint32(len(c.ArbMarketMakerConfig.SellPlacements))
@@ -55,6 +55,14 @@ type WithdrawalEvent struct {
 	CEXDebit    uint64                   `json:"cexDebit"`
 }
 
+// RiskLimitEvent represents a bot being paused or stopped because one of its
+// RiskLimits was crossed.
+type RiskLimitEvent struct {
+	Limit  RiskLimitType   `json:"limit"`
+	Reason string          `json:"reason"`
+	Action RiskLimitAction `json:"action"`
+}
+
 // MarketMakingEvent represents an action that a market making bot takes.
 type MarketMakingEvent struct {
 	ID             uint64          `json:"id"`
@@ -69,6 +77,11 @@ type MarketMakingEvent struct {
 	WithdrawalEvent *WithdrawalEvent  `json:"withdrawalEvent,omitempty"`
 	UpdateConfig    *BotConfig        `json:"updateConfig,omitempty"`
 	UpdateInventory *map[uint32]int64 `json:"updateInventory,omitempty"`
+	RiskLimitEvent  *RiskLimitEvent   `json:"riskLimitEvent,omitempty"`
+
+	// FiatRates are the fiat rates at the time the event was first stored.
+	// They are set by the event log database.
+	FiatRates map[uint32]float64 `json:"fiatRates,omitempty"`
 }
 
 // MarketMakingRun identifies a market making run.
@@ -303,7 +316,22 @@ func (db *boltEventLogDB) updateEvent(update *eventUpdate) {
 			return err
 		}
 		eventKey := encode.Uint64Bytes(update.e.ID)
-		eventJSON, err := json.Marshal(update.e)
+
+		// Keep the fiat rates from when the event was first stored. The
+		// event is copied because the caller may still be using it.
+		e := *update.e
+		if e.FiatRates == nil {
+			if oldEventB := eventsBkt.Get(eventKey); oldEventB != nil {
+				if oldEvent, err := decodeMarketMakingEvent(oldEventB); err == nil {
+					e.FiatRates = oldEvent.FiatRates
+				}
+			}
+		}
+		if e.FiatRates == nil && update.bs != nil {
+			e.FiatRates = update.bs.FiatRates
+		}
+
+		eventJSON, err := json.Marshal(&e)
 		if err != nil {
 			return err
 		}
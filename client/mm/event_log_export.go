@@ -0,0 +1,329 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package mm
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strconv"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex"
+)
+
+// Run export formats.
+const (
+	RunExportCSV  = "csv"
+	RunExportJSON = "json"
+)
+
+// Run ledger entry types.
+const (
+	LedgerDEXOrder        = "dexOrder"
+	LedgerCEXOrder        = "cexOrder"
+	LedgerDeposit         = "deposit"
+	LedgerWithdrawal      = "withdrawal"
+	LedgerInventoryUpdate = "inventoryUpdate"
+)
+
+// RunLedgerEntry is the effect of a market making event on the bot's balance
+// of one asset. An event that affects several assets, such as a filled order,
+// has an entry for each asset.
+type RunLedgerEntry struct {
+	RunStartTime int64  `json:"runStartTime"`
+	Host         string `json:"host"`
+	BaseID       uint32 `json:"baseID"`
+	QuoteID      uint32 `json:"quoteID"`
+	EventID      uint64 `json:"eventID"`
+	Timestamp    int64  `json:"timestamp"`
+	Pending      bool   `json:"pending"`
+	Type         string `json:"type"`
+	// ID is the order ID for orders, the transaction ID for deposits, and the
+	// CEX's withdrawal ID for withdrawals.
+	ID string `json:"id,omitempty"`
+	// Sell, Rate, Qty, BaseFilled and QuoteFilled are only set for orders.
+	Sell        bool   `json:"sell,omitempty"`
+	Rate        uint64 `json:"rate,omitempty"`
+	Qty         uint64 `json:"qty,omitempty"`
+	BaseFilled  uint64 `json:"baseFilled,omitempty"`
+	QuoteFilled uint64 `json:"quoteFilled,omitempty"`
+	AssetID     uint32 `json:"assetID"`
+	Symbol      string `json:"symbol"`
+	// Amount is the change in the bot's settled balance of the asset,
+	// including fees.
+	Amount int64 `json:"amount"`
+	// Fees are the fees paid in the asset.
+	Fees uint64 `json:"fees"`
+	// FiatRate is the fiat rate of the asset when the event was first
+	// recorded. Events recorded before fiat rates were stored with events use
+	// the run's final fiat rates.
+	FiatRate      float64 `json:"fiatRate"`
+	FiatValue     float64 `json:"fiatValue"`
+	FeesFiatValue float64 `json:"feesFiatValue"`
+}
+
+// RunExport is the exportable history of a market making run.
+type RunExport struct {
+	StartTime int64                    `json:"startTime"`
+	Market    *MarketWithHost          `json:"market"`
+	Overview  *MarketMakingRunOverview `json:"overview"`
+	Entries   []*RunLedgerEntry        `json:"entries"`
+}
+
+// dexOrderFills returns the amounts of the base and quote assets filled by a
+// DEX order, based on its swap, redeem and refund transactions.
+func dexOrderFills(e *DEXOrderEvent) (baseFilled, quoteFilled uint64) {
+	var swapped, redeemed, refunded uint64
+	for _, tx := range e.Transactions {
+		switch tx.Type {
+		case asset.Swap:
+			swapped += tx.Amount
+		case asset.Redeem:
+			redeemed += tx.Amount
+		case asset.Refund:
+			refunded += tx.Amount
+		}
+	}
+	if refunded > swapped {
+		refunded = swapped
+	}
+	if e.Sell {
+		return swapped - refunded, redeemed
+	}
+	return redeemed, swapped - refunded
+}
+
+// dexOrderFees returns the network fees paid for a DEX order's transactions
+// by asset.
+func dexOrderFees(mkt *MarketWithHost, e *DEXOrderEvent) map[uint32]uint64 {
+	fromAsset, toAsset := mkt.QuoteID, mkt.BaseID
+	if e.Sell {
+		fromAsset, toAsset = mkt.BaseID, mkt.QuoteID
+	}
+	fees := make(map[uint32]uint64)
+	for _, tx := range e.Transactions {
+		switch tx.Type {
+		case asset.Swap, asset.Refund:
+			fees[feeAssetID(fromAsset)] += tx.Fees
+		case asset.Redeem:
+			fees[feeAssetID(toAsset)] += tx.Fees
+		}
+	}
+	return fees
+}
+
+// runLedgerEntries converts a run's events to ledger entries. Events that do
+// not affect the bot's balances, such as config updates, are skipped.
+func runLedgerEntries(startTime int64, mkt *MarketWithHost, events []*MarketMakingEvent, fallbackRates map[uint32]float64) []*RunLedgerEntry {
+	entries := make([]*RunLedgerEntry, 0, len(events)*2)
+
+	for _, e := range events {
+		base := RunLedgerEntry{
+			RunStartTime: startTime,
+			Host:         mkt.Host,
+			BaseID:       mkt.BaseID,
+			QuoteID:      mkt.QuoteID,
+			EventID:      e.ID,
+			Timestamp:    e.TimeStamp,
+			Pending:      e.Pending,
+		}
+
+		fees := make(map[uint32]uint64)
+		switch {
+		case e.DEXOrderEvent != nil:
+			o := e.DEXOrderEvent
+			base.Type = LedgerDEXOrder
+			base.ID = o.ID
+			base.Sell = o.Sell
+			base.Rate = o.Rate
+			base.Qty = o.Qty
+			base.BaseFilled, base.QuoteFilled = dexOrderFills(o)
+			fees = dexOrderFees(mkt, o)
+		case e.CEXOrderEvent != nil:
+			o := e.CEXOrderEvent
+			base.Type = LedgerCEXOrder
+			base.ID = o.ID
+			base.Sell = o.Sell
+			base.Rate = o.Rate
+			base.Qty = o.Qty
+			base.BaseFilled = o.BaseFilled
+			base.QuoteFilled = o.QuoteFilled
+		case e.DepositEvent != nil:
+			d := e.DepositEvent
+			base.Type = LedgerDeposit
+			if d.Transaction != nil {
+				base.ID = d.Transaction.ID
+				fees[feeAssetID(d.AssetID)] += d.Transaction.Fees
+			}
+		case e.WithdrawalEvent != nil:
+			w := e.WithdrawalEvent
+			base.Type = LedgerWithdrawal
+			base.ID = w.ID
+			// The CEX deducts the withdrawal fee from the amount debited.
+			if w.Transaction != nil && w.CEXDebit > w.Transaction.Amount {
+				fees[w.AssetID] += w.CEXDebit - w.Transaction.Amount
+			}
+		case e.UpdateInventory != nil:
+			base.Type = LedgerInventoryUpdate
+		default:
+			continue
+		}
+
+		rates := e.FiatRates
+		if rates == nil {
+			rates = fallbackRates
+		}
+
+		assets := make(map[uint32]bool)
+		if e.BalanceEffects != nil {
+			for assetID := range e.BalanceEffects.Settled {
+				assets[assetID] = true
+			}
+		}
+		if e.UpdateInventory != nil {
+			for assetID := range *e.UpdateInventory {
+				assets[assetID] = true
+			}
+		}
+		for assetID, fee := range fees {
+			if fee > 0 {
+				assets[assetID] = true
+			}
+		}
+		assetIDs := make([]uint32, 0, len(assets))
+		for assetID := range assets {
+			assetIDs = append(assetIDs, assetID)
+		}
+		sort.Slice(assetIDs, func(i, j int) bool { return assetIDs[i] < assetIDs[j] })
+
+		for _, assetID := range assetIDs {
+			entry := base
+			entry.AssetID = assetID
+			entry.Symbol = dex.BipIDSymbol(assetID)
+			if e.BalanceEffects != nil {
+				entry.Amount = e.BalanceEffects.Settled[assetID]
+			} else if e.UpdateInventory != nil {
+				entry.Amount = (*e.UpdateInventory)[assetID]
+			}
+			entry.Fees = fees[assetID]
+			entry.FiatRate = rates[assetID]
+			if ui, err := asset.UnitInfo(assetID); err == nil {
+				conv := float64(ui.Conventional.ConversionFactor)
+				entry.FiatValue = float64(entry.Amount) / conv * entry.FiatRate
+				entry.FeesFiatValue = float64(entry.Fees) / conv * entry.FiatRate
+			}
+			entries = append(entries, &entry)
+		}
+	}
+
+	return entries
+}
+
+// ExportRun returns the history of a market making run, including the DEX and
+// CEX orders, deposits, withdrawals and inventory updates, along with their
+// fees and fiat values.
+func (m *MarketMaker) ExportRun(startTime int64, mkt *MarketWithHost) (*RunExport, error) {
+	overview, err := m.eventLogDB.runOverview(startTime, mkt)
+	if err != nil {
+		return nil, err
+	}
+	events, err := m.eventLogDB.runEvents(startTime, mkt, 0, nil, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Events are returned newest first.
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+
+	var fallbackRates map[uint32]float64
+	if overview.FinalState != nil {
+		fallbackRates = overview.FinalState.FiatRates
+	}
+
+	return &RunExport{
+		StartTime: startTime,
+		Market:    mkt,
+		Overview:  overview,
+		Entries:   runLedgerEntries(startTime, mkt, events, fallbackRates),
+	}, nil
+}
+
+// ExportRuns returns the history of all market making runs that started
+// between from and to, which are unix timestamps in seconds. If to is zero,
+// all runs that started after from are returned. Runs are ordered by start
+// time.
+func (m *MarketMaker) ExportRuns(from, to int64) ([]*RunExport, error) {
+	runs, err := m.eventLogDB.runs(0, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	exports := make([]*RunExport, 0, len(runs))
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		if run.StartTime < from || (to > 0 && run.StartTime > to) {
+			continue
+		}
+		export, err := m.ExportRun(run.StartTime, run.Market)
+		if err != nil {
+			return nil, fmt.Errorf("error exporting run %s started at %d: %w", run.Market, run.StartTime, err)
+		}
+		exports = append(exports, export)
+	}
+
+	return exports, nil
+}
+
+var runLedgerCSVHeader = []string{
+	"runStartTime", "host", "baseID", "quoteID", "eventID", "timestamp",
+	"pending", "type", "id", "sell", "rate", "qty", "baseFilled",
+	"quoteFilled", "assetID", "symbol", "amount", "fees", "fiatRate",
+	"fiatValue", "feesFiatValue",
+}
+
+func (e *RunLedgerEntry) csvRecord() []string {
+	i64 := func(v int64) string { return strconv.FormatInt(v, 10) }
+	u64 := func(v uint64) string { return strconv.FormatUint(v, 10) }
+	f64 := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return []string{
+		i64(e.RunStartTime), e.Host, u64(uint64(e.BaseID)), u64(uint64(e.QuoteID)),
+		u64(e.EventID), i64(e.Timestamp), strconv.FormatBool(e.Pending), e.Type,
+		e.ID, strconv.FormatBool(e.Sell), u64(e.Rate), u64(e.Qty),
+		u64(e.BaseFilled), u64(e.QuoteFilled), u64(uint64(e.AssetID)), e.Symbol,
+		i64(e.Amount), u64(e.Fees), f64(e.FiatRate), f64(e.FiatValue),
+		f64(e.FeesFiatValue),
+	}
+}
+
+// WriteRunExports writes run histories in the specified format. The JSON
+// format includes the run overviews. The CSV format only includes the
+// ledger entries, one per row.
+func WriteRunExports(w io.Writer, exports []*RunExport, format string) error {
+	switch format {
+	case RunExportJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(exports)
+	case RunExportCSV:
+		csvWriter := csv.NewWriter(w)
+		csvWriter.UseCRLF = runtime.GOOS == "windows"
+		if err := csvWriter.Write(runLedgerCSVHeader); err != nil {
+			return err
+		}
+		for _, export := range exports {
+			for _, entry := range export.Entries {
+				if err := csvWriter.Write(entry.csvRecord()); err != nil {
+					return err
+				}
+			}
+		}
+		csvWriter.Flush()
+		return csvWriter.Error()
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+}
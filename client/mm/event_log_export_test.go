@@ -0,0 +1,153 @@
+//go:build !harness && !botlive
+
+package mm
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"decred.org/dcrdex/client/asset"
+)
+
+func TestRunLedgerEntries(t *testing.T) {
+	mkt := &MarketWithHost{Host: "dex.com", BaseID: 42, QuoteID: 0}
+	const startTime = 1700000000
+
+	eventRates := map[uint32]float64{42: 20, 0: 60000}
+	finalRates := map[uint32]float64{42: 10, 0: 50000}
+	invMods := map[uint32]int64{42: 1e8}
+
+	events := []*MarketMakingEvent{{
+		ID:        1,
+		TimeStamp: startTime + 1,
+		FiatRates: eventRates,
+		BalanceEffects: &BalanceEffects{
+			Settled: map[uint32]int64{42: -2e8, 0: 6e5 - 300},
+		},
+		DEXOrderEvent: &DEXOrderEvent{
+			ID:   "order1",
+			Rate: 3e5,
+			Qty:  2e8,
+			Sell: true,
+			Transactions: []*asset.WalletTransaction{
+				{Type: asset.Swap, ID: "swap", Amount: 2e8, Fees: 200},
+				{Type: asset.Redeem, ID: "redeem", Amount: 6e5, Fees: 300},
+			},
+		},
+	}, {
+		// No fiat rates stored with the event. The final rates are used.
+		ID:        2,
+		TimeStamp: startTime + 2,
+		BalanceEffects: &BalanceEffects{
+			Settled: map[uint32]int64{0: -1000},
+		},
+		WithdrawalEvent: &WithdrawalEvent{
+			ID:          "withdrawal1",
+			AssetID:     0,
+			CEXDebit:    1e6,
+			Transaction: &asset.WalletTransaction{ID: "tx", Amount: 1e6 - 1000},
+		},
+	}, {
+		ID:              3,
+		TimeStamp:       startTime + 3,
+		FiatRates:       eventRates,
+		UpdateInventory: &invMods,
+	}, {
+		ID:           4,
+		TimeStamp:    startTime + 4,
+		UpdateConfig: &BotConfig{},
+	}}
+
+	entries := runLedgerEntries(startTime, mkt, events, finalRates)
+
+	base := func(eventID uint64, typ string) RunLedgerEntry {
+		return RunLedgerEntry{
+			RunStartTime: startTime,
+			Host:         "dex.com",
+			BaseID:       42,
+			QuoteID:      0,
+			EventID:      eventID,
+			Timestamp:    startTime + int64(eventID),
+			Type:         typ,
+		}
+	}
+	entry := func(e RunLedgerEntry, assetID uint32, symbol string, amt int64, fees uint64, rate float64, conv float64) *RunLedgerEntry {
+		e.AssetID = assetID
+		e.Symbol = symbol
+		e.Amount = amt
+		e.Fees = fees
+		e.FiatRate = rate
+		e.FiatValue = float64(amt) / conv * rate
+		e.FeesFiatValue = float64(fees) / conv * rate
+		return &e
+	}
+
+	dexOrder := base(1, LedgerDEXOrder)
+	dexOrder.ID = "order1"
+	dexOrder.Sell = true
+	dexOrder.Rate = 3e5
+	dexOrder.Qty = 2e8
+	dexOrder.BaseFilled = 2e8
+	dexOrder.QuoteFilled = 6e5
+
+	withdrawal := base(2, LedgerWithdrawal)
+	withdrawal.ID = "withdrawal1"
+
+	expEntries := []*RunLedgerEntry{
+		entry(dexOrder, 0, "btc", 6e5-300, 300, 60000, 1e8),
+		entry(dexOrder, 42, "dcr", -2e8, 200, 20, 1e8),
+		entry(withdrawal, 0, "btc", -1000, 1000, 50000, 1e8),
+		entry(base(3, LedgerInventoryUpdate), 42, "dcr", 1e8, 0, 20, 1e8),
+	}
+
+	if !reflect.DeepEqual(entries, expEntries) {
+		for i, e := range entries {
+			t.Logf("entry %d: %+v", i, e)
+		}
+		t.Fatalf("wrong ledger entries")
+	}
+
+	exports := []*RunExport{{
+		StartTime: startTime,
+		Market:    mkt,
+		Overview:  &MarketMakingRunOverview{},
+		Entries:   entries,
+	}}
+
+	var b bytes.Buffer
+	if err := WriteRunExports(&b, exports, RunExportCSV); err != nil {
+		t.Fatalf("error writing csv: %v", err)
+	}
+	records, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatalf("error reading csv: %v", err)
+	}
+	if len(records) != len(entries)+1 {
+		t.Fatalf("expected %d csv records, got %d", len(entries)+1, len(records))
+	}
+	if !reflect.DeepEqual(records[0], runLedgerCSVHeader) {
+		t.Fatalf("wrong csv header: %v", records[0])
+	}
+	if records[1][15] != "btc" || records[1][16] != "599700" {
+		t.Fatalf("wrong csv record: %v", records[1])
+	}
+
+	b.Reset()
+	if err := WriteRunExports(&b, exports, RunExportJSON); err != nil {
+		t.Fatalf("error writing json: %v", err)
+	}
+	var decoded []*RunExport
+	if err := json.Unmarshal(b.Bytes(), &decoded); err != nil {
+		t.Fatalf("error decoding json: %v", err)
+	}
+	if !reflect.DeepEqual(decoded[0].Entries, entries) {
+		t.Fatalf("wrong json entries")
+	}
+
+	if err := WriteRunExports(&b, exports, "xml"); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+}
@@ -89,6 +89,18 @@ func TestEventLogDB(t *testing.T) {
 
 	inventoryMods := map[uint32]int64{}
 
+	// The event log stores the fiat rates from when an event was first
+	// stored, even if the event is updated after the rates change.
+	eventFiatRates := map[uint32]float64{
+		42: 20,
+		60: 2500,
+	}
+	withRates := func(e *MarketMakingEvent) *MarketMakingEvent {
+		withRates := *e
+		withRates.FiatRates = eventFiatRates
+		return &withRates
+	}
+
 	currBalanceState := func() *BalanceState {
 		balances := make(map[uint32]*BotBalance, len(currBals))
 		for k, v := range currBals {
@@ -213,10 +225,10 @@ func TestEventLogDB(t *testing.T) {
 		if len(runEvents) != 2 {
 			return fmt.Errorf("expected 2 run event, got %d", len(runEvents))
 		}
-		if !reflect.DeepEqual(runEvents[0], event2) {
+		if !reflect.DeepEqual(runEvents[0], withRates(event2)) {
 			return fmt.Errorf("expected event:\n%v\n\ngot:\n%v", event2, runEvents[0])
 		}
-		if !reflect.DeepEqual(runEvents[1], event1) {
+		if !reflect.DeepEqual(runEvents[1], withRates(event1)) {
 			return fmt.Errorf("expected event:\n%v\n\ngot:\n%v", event1, runEvents[1])
 		}
 		return nil
@@ -231,7 +243,7 @@ func TestEventLogDB(t *testing.T) {
 	if len(runEvents) != 1 {
 		t.Fatalf("expected 1 run event, got %d", len(runEvents))
 	}
-	if !reflect.DeepEqual(runEvents[0], event2) {
+	if !reflect.DeepEqual(runEvents[0], withRates(event2)) {
 		t.Fatalf("expected event:\n%v\n\ngot:\n%v", event2, runEvents[0])
 	}
 
@@ -243,7 +255,7 @@ func TestEventLogDB(t *testing.T) {
 	if len(runEvents) != 1 {
 		t.Fatalf("expected 1 run event, got %d", len(runEvents))
 	}
-	if !reflect.DeepEqual(runEvents[0], event1) {
+	if !reflect.DeepEqual(runEvents[0], withRates(event1)) {
 		t.Fatalf("expected event:\n%v\n\ngot:\n%v", event1, runEvents[0])
 	}
 
@@ -279,10 +291,10 @@ func TestEventLogDB(t *testing.T) {
 		if len(runEvents) != 2 {
 			return fmt.Errorf("expected 2 run event, got %d", len(runEvents))
 		}
-		if !reflect.DeepEqual(runEvents[0], event2) {
+		if !reflect.DeepEqual(runEvents[0], withRates(event2)) {
 			return fmt.Errorf("expected event:\n%v\n\ngot:\n%v", event2, runEvents[0])
 		}
-		if !reflect.DeepEqual(runEvents[1], event1) {
+		if !reflect.DeepEqual(runEvents[1], withRates(event1)) {
 			return fmt.Errorf("expected event:\n%v\n\ngot:\n%v", event1, runEvents[1])
 		}
 		return nil
@@ -310,7 +322,7 @@ func TestEventLogDB(t *testing.T) {
 	if len(runEvents) != 1 {
 		t.Fatalf("expected 1 run events, got %d", len(runEvents))
 	}
-	if !reflect.DeepEqual(runEvents[0], event2) {
+	if !reflect.DeepEqual(runEvents[0], withRates(event2)) {
 		t.Fatalf("expected event:\n%v\n\ngot:\n%v", event2, runEvents[0])
 	}
 
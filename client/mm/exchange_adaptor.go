@@ -72,6 +72,7 @@ type botCoreAdaptor interface {
 	OrderFeesInUnits(sell, base bool, rate uint64) (uint64, error) // estimated fees, not max
 	SubscribeOrderUpdates() (updates <-chan *core.Order)
 	SufficientBalanceForDEXTrade(rate, qty uint64, sell bool) (bool, error)
+	DEXBalance(assetID uint32) *BotBalance
 }
 
 // botCexAdaptor is an interface used by bots to access CEX related
@@ -445,6 +446,9 @@ type unifiedExchangeAdaptor struct {
 	feesMtx  sync.RWMutex
 	buyFees  *OrderFees
 	sellFees *OrderFees
+	// otherMarketFees are the fees for markets other than the bot's market
+	// that the bot trades on, keyed by dexMarketID.
+	otherMarketFees map[string]*cachedOrderFees
 
 	startTime  atomic.Int64
 	eventLogID atomic.Uint64
@@ -462,6 +466,12 @@ type unifiedExchangeAdaptor struct {
 	pendingWithdrawals map[string]*pendingWithdrawal
 	pendingDeposits    map[string]*pendingDeposit
 	inventoryMods      map[uint32]int64
+	// allocatedDEX/allocatedCEX are the amounts allocated to the bot on the
+	// DEX and the CEX, including inventory updates made while the bot is
+	// running. Unlike baseDexBalances and baseCexBalances, they are not
+	// affected by trades, deposits, withdrawals or portfolio rebalancing.
+	allocatedDEX map[uint32]int64
+	allocatedCEX map[uint32]int64
 
 	// If pendingBaseRebalance/pendingQuoteRebalance are true, it means
 	// there is a pending deposit/withdrawal of the base/quote asset,
@@ -486,6 +496,8 @@ type unifiedExchangeAdaptor struct {
 
 	cexProblemsMtx sync.RWMutex
 	cexProblems    *CEXProblems
+
+	risk riskTracker
 }
 
 var _ botCoreAdaptor = (*unifiedExchangeAdaptor)(nil)
@@ -523,7 +535,13 @@ func (u *unifiedExchangeAdaptor) runBotLoop(ctx context.Context) error {
 	return u.botLoop.ConnectOnce(ctx)
 }
 
-// withPause runs a function with the bot loop paused.
+// errBotNotResumed is wrapped by the error returned from withPause when the bot
+// loop could not be restarted. The bot should be stopped.
+var errBotNotResumed = errors.New("bot loop not resumed")
+
+// withPause runs a function with the bot loop paused. The bot loop is resumed
+// even if f fails. A bot that was paused by a risk limit is left paused unless
+// f calls resetRiskLimits.
 func (u *unifiedExchangeAdaptor) withPause(f func() error) error {
 	if !u.paused.CompareAndSwap(false, true) {
 		return errors.New("already paused")
@@ -532,14 +550,24 @@ func (u *unifiedExchangeAdaptor) withPause(f func() error) error {
 
 	u.botLoop.Disconnect()
 
-	if err := f(); err != nil {
-		return err
-	}
+	fErr := f()
 	if u.ctx.Err() != nil { // Make sure we weren't shut down during pause.
 		return u.ctx.Err()
 	}
 
-	return u.botLoop.ConnectOnce(u.ctx)
+	if !u.risk.tripped.Load() {
+		if err := u.botLoop.ConnectOnce(u.ctx); err != nil {
+			return fmt.Errorf("%w: %v", errBotNotResumed, err)
+		}
+	}
+
+	return fErr
+}
+
+// resetRiskLimits clears the state of a bot that was paused by a risk limit,
+// so that it is resumed by withPause.
+func (u *unifiedExchangeAdaptor) resetRiskLimits() {
+	u.risk.reset()
 }
 
 // logBalanceAdjustments logs a trace log of balance adjustments and updated
@@ -637,7 +665,17 @@ func (u *unifiedExchangeAdaptor) logBalanceAdjustments(dexDiffs, cexDiffs map[ui
 // SufficientBalanceForDEXTrade returns whether the bot has sufficient balance
 // to place a DEX trade.
 func (u *unifiedExchangeAdaptor) SufficientBalanceForDEXTrade(rate, qty uint64, sell bool) (bool, error) {
-	fromAsset, fromFeeAsset, toAsset, toFeeAsset := orderAssets(u.baseID, u.quoteID, sell)
+	buyFees, sellFees, err := u.orderFees()
+	if err != nil {
+		return false, err
+	}
+	return u.sufficientBalanceForDEXTrade(u.baseID, u.quoteID, u.lotSize.Load(), buyFees, sellFees, rate, qty, sell), nil
+}
+
+// sufficientBalanceForDEXTrade returns whether the bot has sufficient balance
+// to place a trade on a DEX market with the specified lot size and fees.
+func (u *unifiedExchangeAdaptor) sufficientBalanceForDEXTrade(baseID, quoteID uint32, lotSize uint64, buyFees, sellFees *OrderFees, rate, qty uint64, sell bool) bool {
+	fromAsset, fromFeeAsset, toAsset, toFeeAsset := orderAssets(baseID, quoteID, sell)
 	balances := map[uint32]uint64{}
 	for _, assetID := range []uint32{fromAsset, fromFeeAsset, toAsset, toFeeAsset} {
 		if _, found := balances[assetID]; !found {
@@ -646,17 +684,13 @@ func (u *unifiedExchangeAdaptor) SufficientBalanceForDEXTrade(rate, qty uint64,
 		}
 	}
 
-	buyFees, sellFees, err := u.orderFees()
-	if err != nil {
-		return false, err
-	}
 	fees, fundingFees := buyFees.Max, buyFees.Funding
 	if sell {
 		fees, fundingFees = sellFees.Max, sellFees.Funding
 	}
 
 	if balances[fromFeeAsset] < fundingFees {
-		return false, nil
+		return false
 	}
 	balances[fromFeeAsset] -= fundingFees
 
@@ -665,31 +699,31 @@ func (u *unifiedExchangeAdaptor) SufficientBalanceForDEXTrade(rate, qty uint64,
 		fromQty = calc.BaseToQuote(rate, qty)
 	}
 	if balances[fromAsset] < fromQty {
-		return false, nil
+		return false
 	}
 	balances[fromAsset] -= fromQty
 
-	numLots := qty / u.lotSize.Load()
+	numLots := qty / lotSize
 	if balances[fromFeeAsset] < numLots*fees.Swap {
-		return false, nil
+		return false
 	}
 	balances[fromFeeAsset] -= numLots * fees.Swap
 
 	if u.isAccountLocker(fromAsset) {
 		if balances[fromFeeAsset] < numLots*fees.Refund {
-			return false, nil
+			return false
 		}
 		balances[fromFeeAsset] -= numLots * fees.Refund
 	}
 
 	if u.isAccountLocker(toAsset) {
 		if balances[toFeeAsset] < numLots*fees.Redeem {
-			return false, nil
+			return false
 		}
 		balances[toFeeAsset] -= numLots * fees.Redeem
 	}
 
-	return true, nil
+	return true
 }
 
 // SufficientBalanceOnCEXTrade returns whether the bot has sufficient balance
@@ -967,24 +1001,37 @@ func withinTolerance(rate, target uint64, driftTolerance float64) bool {
 }
 
 func (u *unifiedExchangeAdaptor) placeMultiTrade(placements []*dexOrderInfo, sell bool) []*core.MultiTradeResult {
+	return u.placeMultiTradeOnMarket(u.market, placements, sell)
+}
+
+// walletOptions returns the configured wallet options for an asset. Only the
+// assets of the bot's market have wallet options.
+func (u *unifiedExchangeAdaptor) walletOptions(assetID uint32) map[string]string {
+	botCfg := u.botCfg()
+	switch assetID {
+	case u.baseID:
+		return botCfg.BaseWalletOptions
+	case u.quoteID:
+		return botCfg.QuoteWalletOptions
+	}
+	return nil
+}
+
+// placeMultiTradeOnMarket places orders on a DEX market. The market does not
+// need to be the bot's market or on the bot's host, allowing bots to trade on
+// multiple markets with the same balance accounting.
+func (u *unifiedExchangeAdaptor) placeMultiTradeOnMarket(mkt *market, placements []*dexOrderInfo, sell bool) []*core.MultiTradeResult {
 	corePlacements := make([]*core.QtyRate, 0, len(placements))
 	for _, p := range placements {
 		corePlacements = append(corePlacements, p.placement)
 	}
 
-	botCfg := u.botCfg()
-	var walletOptions map[string]string
-	if sell {
-		walletOptions = botCfg.BaseWalletOptions
-	} else {
-		walletOptions = botCfg.QuoteWalletOptions
-	}
-
-	fromAsset, fromFeeAsset, toAsset, toFeeAsset := orderAssets(u.baseID, u.quoteID, sell)
+	fromAsset, fromFeeAsset, toAsset, toFeeAsset := orderAssets(mkt.baseID, mkt.quoteID, sell)
+	walletOptions := u.walletOptions(fromAsset)
 	multiTradeForm := &core.MultiTradeForm{
-		Host:       u.host,
-		Base:       u.baseID,
-		Quote:      u.quoteID,
+		Host:       mkt.host,
+		Base:       mkt.baseID,
+		Quote:      mkt.quoteID,
 		Sell:       sell,
 		Placements: corePlacements,
 		Options:    walletOptions,
@@ -1444,6 +1491,32 @@ func (u *unifiedExchangeAdaptor) DEXTrade(rate, qty uint64, sell bool) (*core.Or
 	return results[0].Order, nil
 }
 
+// dexMarketTrade places a single order on a DEX market other than the bot's
+// market, which may be on another host. The fees are the market's fees, as
+// returned by marketOrderFees.
+func (u *unifiedExchangeAdaptor) dexMarketTrade(mkt *market, buyFees, sellFees *OrderFees, rate, qty uint64, sell bool) (*core.Order, error) {
+	if !u.sufficientBalanceForDEXTrade(mkt.baseID, mkt.quoteID, mkt.lotSize.Load(), buyFees, sellFees, rate, qty, sell) {
+		return nil, fmt.Errorf("insufficient balance")
+	}
+
+	placements := []*dexOrderInfo{{
+		placement: &core.QtyRate{
+			Qty:  qty,
+			Rate: rate,
+		},
+	}}
+
+	results := u.placeMultiTradeOnMarket(mkt, placements, sell)
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no orders placed")
+	}
+	if results[0].Error != nil {
+		return nil, results[0].Error
+	}
+
+	return results[0].Order, nil
+}
+
 type BotBalances struct {
 	DEX *BotBalance `json:"dex"`
 	CEX *BotBalance `json:"cex"`
@@ -1537,7 +1610,7 @@ func (u *unifiedExchangeAdaptor) refreshAllPendingEvents(ctx context.Context) {
 	for _, pendingOrder := range pendingDEXOrders {
 		pendingOrder.txsMtx.Lock()
 		state := pendingOrder.currentState()
-		pendingOrder.updateState(state.order, u.clientCore.WalletTransaction, u.baseTraits, u.quoteTraits)
+		pendingOrder.updateState(state.order, u.clientCore.WalletTransaction, u.walletTraits(state.order.BaseID), u.walletTraits(state.order.QuoteID))
 		pendingOrder.txsMtx.Unlock()
 	}
 
@@ -2264,6 +2337,7 @@ func (u *unifiedExchangeAdaptor) CEXTrade(ctx context.Context, baseID, quoteID u
 	defer u.balancesMtx.Unlock()
 
 	trade, err := u.CEX.Trade(ctx, baseID, quoteID, sell, rate, qty, quoteQty, orderType, *subscriptionID)
+	u.recordCEXTradeResult(err)
 	if err != nil {
 		return nil, err
 	}
@@ -2345,6 +2419,43 @@ func (u *unifiedExchangeAdaptor) orderFees() (buyFees, sellFees *OrderFees, err
 	return buyFees, sellFees, nil
 }
 
+type cachedOrderFees struct {
+	buyFees   *OrderFees
+	sellFees  *OrderFees
+	refreshed time.Time
+}
+
+// otherMarketOrderFees returns the fees for a single order on a DEX market
+// other than the bot's market. The fees are refreshed every 10 minutes.
+func (u *unifiedExchangeAdaptor) otherMarketOrderFees(mkt *market) (buyFees, sellFees *OrderFees, err error) {
+	mktID := dexMarketID(mkt.host, mkt.baseID, mkt.quoteID)
+
+	u.feesMtx.RLock()
+	cached := u.otherMarketFees[mktID]
+	u.feesMtx.RUnlock()
+	if cached != nil && time.Since(cached.refreshed) < time.Minute*10 {
+		return cached.buyFees, cached.sellFees, nil
+	}
+
+	buyFees, sellFees, err = u.marketOrderFees(mkt, 1, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u.feesMtx.Lock()
+	if u.otherMarketFees == nil {
+		u.otherMarketFees = make(map[string]*cachedOrderFees)
+	}
+	u.otherMarketFees[mktID] = &cachedOrderFees{
+		buyFees:   buyFees,
+		sellFees:  sellFees,
+		refreshed: time.Now(),
+	}
+	u.feesMtx.Unlock()
+
+	return buyFees, sellFees, nil
+}
+
 // OrderFeesInUnits returns the estimated swap and redemption fees for either a
 // buy or sell order in units of either the base or quote asset. If either the
 // base or quote asset is a token, the fees are converted using fiat rates.
@@ -2354,7 +2465,13 @@ func (u *unifiedExchangeAdaptor) OrderFeesInUnits(sell, base bool, rate uint64)
 	if err != nil {
 		return 0, fmt.Errorf("error getting order fees: %v", err)
 	}
+	return u.marketOrderFeesInUnits(u.market, buyFeeRange, sellFeeRange, sell, base, rate)
+}
 
+// marketOrderFeesInUnits is OrderFeesInUnits for any DEX market, using the
+// market's fees, as returned by marketOrderFees.
+func (u *unifiedExchangeAdaptor) marketOrderFeesInUnits(mkt *market, buyFeeRange, sellFeeRange *OrderFees, sell, base bool, rate uint64) (uint64, error) {
+	var err error
 	buyFees, sellFees := buyFeeRange.Estimated, sellFeeRange.Estimated
 	baseFees, quoteFees := buyFees.Redeem, buyFees.Swap
 	if sell {
@@ -2370,14 +2487,14 @@ func (u *unifiedExchangeAdaptor) OrderFeesInUnits(sell, base bool, rate uint64)
 	}
 
 	var baseFeesInUnits, quoteFeesInUnits uint64
-	if tkn := asset.TokenInfo(u.baseID); tkn != nil {
-		baseFees, err = convertViaFiat(baseFees, tkn.ParentID, u.baseID)
+	if tkn := asset.TokenInfo(mkt.baseID); tkn != nil {
+		baseFees, err = convertViaFiat(baseFees, tkn.ParentID, mkt.baseID)
 		if err != nil {
 			return 0, err
 		}
 	}
-	if tkn := asset.TokenInfo(u.quoteID); tkn != nil {
-		quoteFees, err = convertViaFiat(quoteFees, tkn.ParentID, u.quoteID)
+	if tkn := asset.TokenInfo(mkt.quoteID); tkn != nil {
+		quoteFees, err = convertViaFiat(quoteFees, tkn.ParentID, mkt.quoteID)
 		if err != nil {
 			return 0, err
 		}
@@ -2530,28 +2647,35 @@ func (u *unifiedExchangeAdaptor) SubscribeOrderUpdates() <-chan *core.Order {
 	return orderUpdates
 }
 
+// walletTraits returns the traits of the asset's wallet. The traits of the
+// wallets for the bot's market are cached when the bot is created.
+func (u *unifiedExchangeAdaptor) walletTraits(assetID uint32) asset.WalletTrait {
+	switch assetID {
+	case u.baseID:
+		return u.baseTraits
+	case u.quoteID:
+		return u.quoteTraits
+	}
+	traits, err := u.clientCore.WalletTraits(assetID)
+	if err != nil {
+		u.log.Errorf("Error getting wallet traits for %s: %v", dex.BipIDSymbol(assetID), err)
+	}
+	return traits
+}
+
 // isAccountLocker returns if the asset's wallet is an asset.AccountLocker.
 func (u *unifiedExchangeAdaptor) isAccountLocker(assetID uint32) bool {
-	if assetID == u.baseID {
-		return u.baseTraits.IsAccountLocker()
-	}
-	return u.quoteTraits.IsAccountLocker()
+	return u.walletTraits(assetID).IsAccountLocker()
 }
 
 // isDynamicSwapper returns if the asset's wallet is an asset.DynamicSwapper.
 func (u *unifiedExchangeAdaptor) isDynamicSwapper(assetID uint32) bool {
-	if assetID == u.baseID {
-		return u.baseTraits.IsDynamicSwapper()
-	}
-	return u.quoteTraits.IsDynamicSwapper()
+	return u.walletTraits(assetID).IsDynamicSwapper()
 }
 
 // isWithdrawer returns if the asset's wallet is an asset.Withdrawer.
 func (u *unifiedExchangeAdaptor) isWithdrawer(assetID uint32) bool {
-	if assetID == u.baseID {
-		return u.baseTraits.IsWithdrawer()
-	}
-	return u.quoteTraits.IsWithdrawer()
+	return u.walletTraits(assetID).IsWithdrawer()
 }
 
 func orderAssets(baseID, quoteID uint32, sell bool) (fromAsset, fromFeeAsset, toAsset, toFeeAsset uint32) {
@@ -2779,8 +2903,10 @@ func (u *unifiedExchangeAdaptor) handleDEXOrderUpdate(o *core.Order) {
 		return
 	}
 
+	u.recordDEXMatchFailures(o)
+
 	pendingOrder.txsMtx.Lock()
-	pendingOrder.updateState(o, u.clientCore.WalletTransaction, u.baseTraits, u.quoteTraits)
+	pendingOrder.updateState(o, u.clientCore.WalletTransaction, u.walletTraits(o.BaseID), u.walletTraits(o.QuoteID))
 	dexEffects := pendingOrder.currentState().dexBalanceEffects
 	var havePending bool
 	for _, v := range dexEffects.Pending {
@@ -3468,20 +3594,25 @@ func (u *unifiedExchangeAdaptor) cexCounterRates(cexBuyLots, cexSellLots uint64,
 // bookingFees are the per-lot fees that have to be available before placing an
 // order.
 func (u *unifiedExchangeAdaptor) bookingFees(buyFees, sellFees *LotFees) (buyBookingFeesPerLot, sellBookingFeesPerLot uint64) {
+	return u.marketBookingFees(u.market, buyFees, sellFees)
+}
+
+// marketBookingFees is bookingFees for any DEX market.
+func (u *unifiedExchangeAdaptor) marketBookingFees(mkt *market, buyFees, sellFees *LotFees) (buyBookingFeesPerLot, sellBookingFeesPerLot uint64) {
 	buyBookingFeesPerLot = buyFees.Swap
 	// If we're redeeming on the same chain, add redemption fees.
-	if u.quoteFeeID == u.baseFeeID {
+	if mkt.quoteFeeID == mkt.baseFeeID {
 		buyBookingFeesPerLot += buyFees.Redeem
 	}
 	// EVM assets need to reserve refund gas.
-	if u.quoteTraits.IsAccountLocker() {
+	if u.isAccountLocker(mkt.quoteID) {
 		buyBookingFeesPerLot += buyFees.Refund
 	}
 	sellBookingFeesPerLot = sellFees.Swap
-	if u.baseFeeID == u.quoteFeeID {
+	if mkt.baseFeeID == mkt.quoteFeeID {
 		sellBookingFeesPerLot += sellFees.Redeem
 	}
-	if u.baseTraits.IsAccountLocker() {
+	if u.isAccountLocker(mkt.baseID) {
 		sellBookingFeesPerLot += sellFees.Refund
 	}
 	return
@@ -3502,25 +3633,37 @@ func (u *unifiedExchangeAdaptor) updateFeeRates() (buyFees, sellFees *OrderFees,
 		u.sellFees = nil
 	}()
 
-	maxBaseFees, maxQuoteFees, err := marketFees(u.clientCore, u.host, u.baseID, u.quoteID, true)
+	maxBuyPlacements, maxSellPlacements := u.botCfg().maxPlacements()
+	buyFees, sellFees, err = u.marketOrderFees(u.market, maxBuyPlacements, maxSellPlacements)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	estBaseFees, estQuoteFees, err := marketFees(u.clientCore, u.host, u.baseID, u.quoteID, false)
+	u.feesMtx.Lock()
+	defer u.feesMtx.Unlock()
+	u.buyFees, u.sellFees = buyFees, sellFees
+	return buyFees, sellFees, nil
+}
+
+// marketOrderFees calculates the fees for placing buy and sell orders on a DEX
+// market.
+func (u *unifiedExchangeAdaptor) marketOrderFees(mkt *market, maxBuyPlacements, maxSellPlacements uint32) (buyFees, sellFees *OrderFees, err error) {
+	maxBaseFees, maxQuoteFees, err := marketFees(u.clientCore, mkt.host, mkt.baseID, mkt.quoteID, true)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	botCfg := u.botCfg()
-	maxBuyPlacements, maxSellPlacements := botCfg.maxPlacements()
+	estBaseFees, estQuoteFees, err := marketFees(u.clientCore, mkt.host, mkt.baseID, mkt.quoteID, false)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	buyFundingFees, err := u.clientCore.MaxFundingFees(u.quoteID, u.host, maxBuyPlacements, botCfg.QuoteWalletOptions)
+	buyFundingFees, err := u.clientCore.MaxFundingFees(mkt.quoteID, mkt.host, maxBuyPlacements, u.walletOptions(mkt.quoteID))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get buy funding fees: %v", err)
 	}
 
-	sellFundingFees, err := u.clientCore.MaxFundingFees(u.baseID, u.host, maxSellPlacements, botCfg.BaseWalletOptions)
+	sellFundingFees, err := u.clientCore.MaxFundingFees(mkt.baseID, mkt.host, maxSellPlacements, u.walletOptions(mkt.baseID))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get sell funding fees: %v", err)
 	}
@@ -3536,12 +3679,9 @@ func (u *unifiedExchangeAdaptor) updateFeeRates() (buyFees, sellFees *OrderFees,
 		Refund: maxBaseFees.Refund,
 	}
 
-	buyBookingFeesPerLot, sellBookingFeesPerLot := u.bookingFees(maxBuyFees, maxSellFees)
+	buyBookingFeesPerLot, sellBookingFeesPerLot := u.marketBookingFees(mkt, maxBuyFees, maxSellFees)
 
-	u.feesMtx.Lock()
-	defer u.feesMtx.Unlock()
-
-	u.buyFees = &OrderFees{
+	buyFees = &OrderFees{
 		LotFeeRange: &LotFeeRange{
 			Max: maxBuyFees,
 			Estimated: &LotFees{
@@ -3554,7 +3694,7 @@ func (u *unifiedExchangeAdaptor) updateFeeRates() (buyFees, sellFees *OrderFees,
 		BookingFeesPerLot: buyBookingFeesPerLot,
 	}
 
-	u.sellFees = &OrderFees{
+	sellFees = &OrderFees{
 		LotFeeRange: &LotFeeRange{
 			Max: maxSellFees,
 			Estimated: &LotFees{
@@ -3567,7 +3707,7 @@ func (u *unifiedExchangeAdaptor) updateFeeRates() (buyFees, sellFees *OrderFees,
 		BookingFeesPerLot: sellBookingFeesPerLot,
 	}
 
-	return u.buyFees, u.sellFees, nil
+	return buyFees, sellFees, nil
 }
 
 func (u *unifiedExchangeAdaptor) Connect(ctx context.Context) (*sync.WaitGroup, error) {
@@ -3795,7 +3935,9 @@ func (u *unifiedExchangeAdaptor) stats() *RunStats {
 }
 
 func (u *unifiedExchangeAdaptor) sendStatsUpdate() {
-	u.clientCore.Broadcast(newRunStatsNote(u.host, u.baseID, u.quoteID, u.stats()))
+	stats := u.stats()
+	u.clientCore.Broadcast(newRunStatsNote(u.host, u.baseID, u.quoteID, stats))
+	u.checkRiskLimits(stats)
 }
 
 func (u *unifiedExchangeAdaptor) notifyEvent(e *MarketMakingEvent) {
@@ -3806,7 +3948,10 @@ func (u *unifiedExchangeAdaptor) registerFeeGap(feeGap *FeeGapStats) {
 	u.runStats.feeGapStats.Store(feeGap)
 }
 
-func (u *unifiedExchangeAdaptor) applyInventoryDiffs(balanceDiffs *BotInventoryDiffs) map[uint32]int64 {
+// applyInventoryDiffs updates the bot's balances. If updateAllocation is
+// false, the amounts allocated to the bot are not changed, so the diffs
+// cover surpluses or shortages relative to the allocation.
+func (u *unifiedExchangeAdaptor) applyInventoryDiffs(balanceDiffs *BotInventoryDiffs, updateAllocation bool) map[uint32]int64 {
 	u.balancesMtx.Lock()
 	defer u.balancesMtx.Unlock()
 
@@ -3821,6 +3966,9 @@ func (u *unifiedExchangeAdaptor) applyInventoryDiffs(balanceDiffs *BotInventoryD
 			}
 		}
 		u.baseDexBalances[assetID] += diff
+		if updateAllocation {
+			u.allocatedDEX[assetID] += diff
+		}
 		mods[assetID] = diff
 	}
 
@@ -3833,6 +3981,9 @@ func (u *unifiedExchangeAdaptor) applyInventoryDiffs(balanceDiffs *BotInventoryD
 			}
 		}
 		u.baseCexBalances[assetID] += diff
+		if updateAllocation {
+			u.allocatedCEX[assetID] += diff
+		}
 		mods[assetID] += diff
 	}
 
@@ -3858,10 +4009,53 @@ func (u *unifiedExchangeAdaptor) updateConfig(cfg *BotConfig, autoRebalanceCfg *
 }
 
 func (u *unifiedExchangeAdaptor) updateInventory(balanceDiffs *BotInventoryDiffs) {
-	u.updateInventoryEvent(u.applyInventoryDiffs(balanceDiffs))
+	u.updateInventoryEvent(u.applyInventoryDiffs(balanceDiffs, true))
+	u.sendStatsUpdate()
+}
+
+// moveInventory updates the bot's balances without changing the amounts
+// allocated to it. It is used by the portfolio rebalancer to cover a bot's
+// shortage with another bot's surplus.
+func (u *unifiedExchangeAdaptor) moveInventory(balanceDiffs *BotInventoryDiffs) {
+	u.updateInventoryEvent(u.applyInventoryDiffs(balanceDiffs, false))
 	u.sendStatsUpdate()
 }
 
+// allocatedBalances returns the amounts allocated to the bot on the DEX and
+// the CEX.
+func (u *unifiedExchangeAdaptor) allocatedBalances() (dexBals, cexBals map[uint32]uint64) {
+	u.balancesMtx.RLock()
+	defer u.balancesMtx.RUnlock()
+
+	toUint := func(allocated map[uint32]int64) map[uint32]uint64 {
+		bals := make(map[uint32]uint64, len(allocated))
+		for assetID, v := range allocated {
+			if v > 0 {
+				bals[assetID] = uint64(v)
+			}
+		}
+		return bals
+	}
+
+	return toUint(u.allocatedDEX), toUint(u.allocatedCEX)
+}
+
+// transferFunds deposits funds to the CEX if toCEX is true, or withdraws them
+// from the CEX otherwise.
+func (u *unifiedExchangeAdaptor) transferFunds(assetID uint32, amount uint64, toCEX bool) error {
+	if u.CEX == nil {
+		return errors.New("not a cex-connected bot")
+	}
+	if toCEX {
+		err := u.deposit(u.ctx, assetID, amount)
+		u.updateCEXProblems(cexDepositProblem, assetID, err)
+		return err
+	}
+	err := u.withdraw(u.ctx, assetID, amount)
+	u.updateCEXProblems(cexWithdrawProblem, assetID, err)
+	return err
+}
+
 func (u *unifiedExchangeAdaptor) Book() (buys, sells []*core.MiniOrder, _ error) {
 	if u.CEX == nil {
 		return nil, nil, errors.New("not a cex-connected bot")
@@ -4102,6 +4296,8 @@ func newUnifiedExchangeAdaptor(cfg *exchangeAdaptorCfg) (*unifiedExchangeAdaptor
 		pendingWithdrawals: make(map[string]*pendingWithdrawal),
 		mwh:                cfg.mwh,
 		inventoryMods:      make(map[uint32]int64),
+		allocatedDEX:       utils.CopyMap(baseDEXBalances),
+		allocatedCEX:       utils.CopyMap(baseCEXBalances),
 		cexProblems:        newCEXProblems(),
 	}
 
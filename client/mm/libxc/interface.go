@@ -163,19 +163,28 @@ const (
 	Binance   = "Binance"
 	BinanceUS = "BinanceUS"
 	Coinbase  = "Coinbase"
+	Kraken    = "Kraken"
+	OKX       = "OKX"
 )
 
 // IsValidCEXName returns whether or not a cex name is supported.
 func IsValidCexName(cexName string) bool {
-	return cexName == Binance || cexName == BinanceUS
+	switch cexName {
+	case Binance, BinanceUS, Coinbase, Kraken, OKX:
+		return true
+	}
+	return false
 }
 
 type CEXConfig struct {
 	Net       dex.Network
 	APIKey    string
 	SecretKey string
-	Logger    dex.Logger
-	Notify    func(interface{})
+	// APIPassphrase is required by some exchanges, e.g. OKX, in addition to
+	// the API key and secret.
+	APIPassphrase string
+	Logger        dex.Logger
+	Notify        func(interface{})
 }
 
 // NewCEX creates a new CEX.
@@ -187,6 +196,10 @@ func NewCEX(cexName string, cfg *CEXConfig) (CEX, error) {
 		return newBinance(cfg, true), nil
 	case Coinbase:
 		return newCoinbase(cfg)
+	case Kraken:
+		return newKraken(cfg)
+	case OKX:
+		return newOKX(cfg)
 	default:
 		return nil, fmt.Errorf("unrecognized CEX: %v", cexName)
 	}
@@ -0,0 +1,1708 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package libxc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/comms"
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/mm/libxc/krtypes"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/dexnet"
+	"decred.org/dcrdex/dex/utils"
+)
+
+// Kraken REST docs:         https://docs.kraken.com/api/docs/rest-api/add-order
+// Kraken websocket v2 docs: https://docs.kraken.com/api/docs/websocket-v2/book
+
+const (
+	krakenHttpURL      = "https://api.kraken.com"
+	krakenWsURL        = "wss://ws.kraken.com/v2"
+	krakenAuthWsURL    = "wss://ws-auth.kraken.com/v2"
+	fakeKrakenURL      = "http://localhost:37347"
+	fakeKrakenWsURL    = "ws://localhost:37347/v2"
+	krakenBookDepth    = 100
+	krakenChecksumSize = 10
+)
+
+// supportedKrakenTokens is the set of tokens that can be deposited to or
+// withdrawn from Kraken, mapped to the substrings that identify the
+// deposit and withdrawal methods for the token's network.
+var supportedKrakenTokens = map[uint32][]string{
+	60001:  {"ERC20", "Ethereum"}, // USDC on ETH
+	60002:  {"ERC20", "Ethereum"}, // USDT on ETH
+	966001: {"Polygon"},           // USDC on POLYGON
+}
+
+// krakenAltTickers translates Kraken's legacy REST asset names to the
+// tickers used by the websocket v2 API.
+var krakenAltTickers = map[string]string{
+	"XBT": "BTC",
+	"XDG": "DOGE",
+}
+
+func krakenTicker(altname string) string {
+	if t, found := krakenAltTickers[altname]; found {
+		return t
+	}
+	return altname
+}
+
+// krakenAsset is a Kraken asset that is supported by DEX.
+type krakenAsset struct {
+	// key is the REST asset name, e.g. XXBT.
+	key string
+	// ticker is the websocket v2 ticker, e.g. BTC.
+	ticker string
+}
+
+// krakenMarket is a Kraken asset pair with the trading limits converted to
+// DEX atomic units.
+type krakenMarket struct {
+	*krtypes.AssetPair
+	// pair is the REST pair name, e.g. XXBTZUSD.
+	pair string
+	// symbol is the websocket v2 symbol, e.g. BTC/USD.
+	symbol      string
+	baseTicker  string
+	quoteTicker string
+	rateStep    uint64
+	lotSize     uint64
+	minQty      uint64
+	minQuoteQty uint64
+}
+
+// krakenTradeInfo tracks the cumulative fills and fees of a Kraken order.
+// The executions channel reports fees per trade, so they must be summed.
+type krakenTradeInfo struct {
+	*tradeInfo
+	baseFilled  uint64
+	quoteFilled uint64
+	baseFees    uint64
+	quoteFees   uint64
+}
+
+// krBook manages an orderbook for a single Kraken market. As with Coinbase,
+// each book gets its own websocket connection. Kraken provides no sequence
+// numbers, so the book is verified with the checksum that accompanies every
+// update, and the book is re-requested if the checksum does not match.
+type krBook struct {
+	mtx            sync.RWMutex
+	numSubscribers uint32
+	cm             *dex.ConnectionMaster
+
+	wsURL  string
+	mkt    *krakenMarket
+	synced atomic.Bool
+	book   *orderbook
+	bui    *dex.UnitInfo
+	qui    *dex.UnitInfo
+	log    dex.Logger
+	wsConn comms.WsConn
+}
+
+func newKRBook(wsURL string, mkt *krakenMarket, bui, qui *dex.UnitInfo, log dex.Logger) *krBook {
+	return &krBook{
+		wsURL: wsURL,
+		mkt:   mkt,
+		book:  newOrderBook(),
+		bui:   bui,
+		qui:   qui,
+		log:   log.SubLogger("WS-book-" + mkt.symbol),
+	}
+}
+
+func (b *krBook) subUnsub(sub bool) error {
+	method := "subscribe"
+	if !sub {
+		method = "unsubscribe"
+	}
+	reqB, err := json.Marshal(&krtypes.WSRequest{
+		Method: method,
+		Params: &krtypes.BookSubscription{
+			Channel:  "book",
+			Symbol:   []string{b.mkt.symbol},
+			Depth:    krakenBookDepth,
+			Snapshot: sub,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return b.wsConn.SendRaw(reqB)
+}
+
+func (b *krBook) convertLevels(levels []*krtypes.BookLevel) []*obEntry {
+	entries := make([]*obEntry, 0, len(levels))
+	for _, l := range levels {
+		entries = append(entries, &obEntry{
+			qty:  toAtomic(l.Qty, b.bui),
+			rate: messageRate(l.Price, b.bui, b.qui),
+		})
+	}
+	return entries
+}
+
+func (b *krBook) handleWebsocketMessage(msgB []byte) {
+	var msg krtypes.WSMessage
+	if err := json.Unmarshal(msgB, &msg); err != nil {
+		b.log.Errorf("Error unmarshaling websocket message: %v", err)
+		b.log.Errorf("Raw Message: %s", string(msgB))
+		return
+	}
+
+	if msg.Method != "" {
+		if !msg.Success {
+			b.log.Errorf("Websocket %s error: %s", msg.Method, msg.Error)
+		}
+		return
+	}
+
+	switch msg.Channel {
+	case "book":
+	case "heartbeat", "status":
+		return
+	default:
+		b.log.Errorf("Websocket message for unknown channel %q", msg.Channel)
+		return
+	}
+
+	var data []*krtypes.BookData
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		b.log.Errorf("Error unmarshaling book data: %v", err)
+		return
+	}
+
+	for _, d := range data {
+		if d.Symbol != b.mkt.symbol {
+			continue
+		}
+		if msg.Type == "snapshot" {
+			b.book.clear()
+		} else if !b.synced.Load() {
+			// Waiting for a new snapshot.
+			continue
+		}
+
+		b.book.update(b.convertLevels(d.Bids), b.convertLevels(d.Asks))
+		// Levels that fall out of the subscribed depth are not removed by
+		// Kraken.
+		b.book.trim(krakenBookDepth)
+
+		bids, asks := b.book.snap()
+		if checksum := krakenBookChecksum(bids, asks, b.mkt, b.bui, b.qui); checksum != d.Checksum {
+			b.log.Errorf("Book checksum mismatch. Expected %d, calculated %d. Resubscribing.", d.Checksum, checksum)
+			b.synced.Store(false)
+			if err := b.subUnsub(false); err != nil {
+				b.log.Errorf("Error unsubscribing: %v", err)
+			}
+			if err := b.subUnsub(true); err != nil {
+				b.log.Errorf("Error resubscribing: %v", err)
+			}
+			return
+		}
+
+		if msg.Type == "snapshot" {
+			b.log.Infof("Book synced")
+			b.synced.Store(true)
+		}
+	}
+}
+
+// krakenChecksumString formats a price or quantity the way Kraken does when
+// calculating the book checksum, i.e. with the decimal point and any leading
+// zeros removed.
+func krakenChecksumString(v float64, decimals int) string {
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	return strings.TrimLeft(strings.Replace(s, ".", "", 1), "0")
+}
+
+// krakenBookChecksum calculates the CRC32 checksum of the top 10 levels of
+// each side of the book.
+func krakenBookChecksum(bids, asks []*obEntry, mkt *krakenMarket, bui, qui *dex.UnitInfo) uint32 {
+	bFactor, qFactor := bui.Conventional.ConversionFactor, qui.Conventional.ConversionFactor
+	var sb strings.Builder
+	addLevels := func(entries []*obEntry) {
+		for i, e := range entries {
+			if i == krakenChecksumSize {
+				break
+			}
+			sb.WriteString(krakenChecksumString(calc.ConventionalRateAlt(e.rate, bFactor, qFactor), mkt.PairDecimals))
+			sb.WriteString(krakenChecksumString(float64(e.qty)/float64(bFactor), mkt.LotDecimals))
+		}
+	}
+	addLevels(asks)
+	addLevels(bids)
+	return crc32.ChecksumIEEE([]byte(sb.String()))
+}
+
+func (b *krBook) Connect(ctx context.Context) (*sync.WaitGroup, error) {
+	initialConnect := true
+	conn, err := comms.NewWsConn(&comms.WsCfg{
+		URL: b.wsURL,
+		// Kraken sends a heartbeat every second while there is an active
+		// subscription.
+		PingWait:      time.Minute,
+		ReconnectSync: func() {},
+		ConnectEventFunc: func(cs comms.ConnectionStatus) {
+			if cs != comms.Connected && cs != comms.Disconnected {
+				return
+			}
+			if cs == comms.Connected && initialConnect {
+				initialConnect = false
+			} else if cs == comms.Connected {
+				if err := b.subUnsub(true); err != nil {
+					b.log.Errorf("Error resubscribing after reconnect: %v", err)
+				}
+			} else { // Disconnected
+				b.synced.Store(false)
+			}
+		},
+		Logger:     b.log,
+		RawHandler: b.handleWebsocketMessage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating WsConn: %w", err)
+	}
+
+	cm := dex.NewConnectionMaster(conn)
+	if err := cm.ConnectOnce(ctx); err != nil {
+		return nil, fmt.Errorf("error connecting to websocket feed: %w", err)
+	}
+
+	b.wsConn = conn
+
+	if err := b.subUnsub(true); err != nil {
+		cm.Disconnect()
+		return nil, fmt.Errorf("error subscribing to book: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		cm.Disconnect()
+	}()
+
+	return &wg, nil
+}
+
+func (b *krBook) sync(ctx context.Context) error {
+	cm := dex.NewConnectionMaster(b)
+	b.mtx.Lock()
+	b.cm = cm
+	b.numSubscribers++
+	b.mtx.Unlock()
+	return cm.ConnectOnce(ctx)
+}
+
+func (b *krBook) midGap() (uint64, error) {
+	if !b.synced.Load() {
+		return 0, ErrUnsyncedOrderbook
+	}
+	return b.book.midGap(), nil
+}
+
+func (b *krBook) vwap(bids bool, qty uint64) (vwap, extrema uint64, filled bool, err error) {
+	if !b.synced.Load() {
+		return 0, 0, false, ErrUnsyncedOrderbook
+	}
+	vwap, extrema, filled = b.book.vwap(bids, qty)
+	return
+}
+
+func (b *krBook) invVWAP(bids bool, qty uint64) (vwap, extrema uint64, filled bool, err error) {
+	if !b.synced.Load() {
+		return 0, 0, false, ErrUnsyncedOrderbook
+	}
+	vwap, extrema, filled = b.book.invVWAP(bids, qty)
+	return
+}
+
+type kraken struct {
+	log       dex.Logger
+	net       dex.Network
+	httpURL   string
+	wsURL     string
+	authWsURL string
+	apiKey    string
+	secretKey []byte
+	broadcast func(interface{})
+	ctx       context.Context
+
+	// tickerIDs maps a websocket v2 ticker to the DEX asset IDs that it
+	// represents. There may be more than one for tokens.
+	tickerIDs map[string][]uint32
+	idTicker  map[uint32]string
+
+	assets  atomic.Value // map[string]*krakenAsset, ticker -> asset
+	markets atomic.Value // map[string]*krakenMarket, symbol -> market
+
+	marketSnapshotMtx sync.RWMutex
+	marketSnapshot    struct {
+		stamp time.Time
+		m     map[string]*Market
+	}
+
+	balanceMtx sync.RWMutex
+	balances   map[uint32]*ExchangeBalance
+
+	// privateReqMtx serializes private requests. Kraken rejects a nonce that
+	// is not greater than the last one it has seen, so requests must arrive
+	// in the order the nonces were generated.
+	privateReqMtx sync.Mutex
+	lastNonce     uint64
+
+	// subMarketMtx must be held while subscribing or unsubscribing to a
+	// market.
+	subMarketMtx sync.Mutex
+
+	booksMtx sync.RWMutex
+	books    map[string]*krBook
+
+	tradeUpdaterMtx    sync.RWMutex
+	tradeInfo          map[string]*krakenTradeInfo
+	tradeUpdaters      map[int]chan *Trade
+	tradeUpdateCounter int
+
+	userStream        comms.WsConn
+	refreshingBalance atomic.Bool
+}
+
+var _ CEX = (*kraken)(nil)
+
+func newKraken(cfg *CEXConfig) (*kraken, error) {
+	var httpURL, wsURL, authWsURL string
+	switch cfg.Net {
+	case dex.Mainnet:
+		httpURL, wsURL, authWsURL = krakenHttpURL, krakenWsURL, krakenAuthWsURL
+	case dex.Simnet:
+		httpURL, wsURL, authWsURL = fakeKrakenURL, fakeKrakenWsURL, fakeKrakenWsURL
+	default:
+		return nil, fmt.Errorf("kraken is not supported on %s", cfg.Net)
+	}
+
+	secretKey, err := base64.StdEncoding.DecodeString(cfg.SecretKey)
+	if err != nil {
+		// The fake server does not check signatures.
+		if cfg.Net != dex.Simnet {
+			return nil, fmt.Errorf("error decoding API secret: %w", err)
+		}
+		secretKey = []byte(cfg.SecretKey)
+	}
+
+	tickerIDs := make(map[string][]uint32)
+	idTicker := make(map[uint32]string)
+	addTicker := func(assetID uint32, ticker string) {
+		tickerIDs[ticker] = append(tickerIDs[ticker], assetID)
+		idTicker[assetID] = ticker
+	}
+	for _, a := range asset.Assets() {
+		addTicker(a.ID, a.Info.UnitInfo.Conventional.Unit)
+		for tokenID, tkn := range a.Tokens {
+			if _, supported := supportedKrakenTokens[tokenID]; supported {
+				addTicker(tokenID, tkn.UnitInfo.Conventional.Unit)
+			}
+		}
+	}
+
+	k := &kraken{
+		log:           cfg.Logger,
+		net:           cfg.Net,
+		httpURL:       httpURL,
+		wsURL:         wsURL,
+		authWsURL:     authWsURL,
+		apiKey:        cfg.APIKey,
+		secretKey:     secretKey,
+		broadcast:     cfg.Notify,
+		tickerIDs:     tickerIDs,
+		idTicker:      idTicker,
+		balances:      make(map[uint32]*ExchangeBalance),
+		books:         make(map[string]*krBook),
+		tradeInfo:     make(map[string]*krakenTradeInfo),
+		tradeUpdaters: make(map[int]chan *Trade),
+	}
+	k.assets.Store(make(map[string]*krakenAsset))
+	k.markets.Store(make(map[string]*krakenMarket))
+
+	return k, nil
+}
+
+// krakenSignature generates the API-Sign header for a private request.
+func krakenSignature(urlPath, nonce, postData string, secret []byte) string {
+	sha := sha256.Sum256([]byte(nonce + postData))
+	mac := hmac.New(sha512.New, secret)
+	mac.Write([]byte(urlPath))
+	mac.Write(sha[:])
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (k *kraken) request(ctx context.Context, req *http.Request, res interface{}) error {
+	var errCode int
+	var resp krtypes.Response
+	if err := dexnet.Do(req, &resp, dexnet.WithStatusFunc(func(code int) { errCode = code })); err != nil {
+		return fmt.Errorf("request error (%d): %w", errCode, err)
+	}
+	if len(resp.Error) > 0 {
+		return fmt.Errorf("kraken error: %s", strings.Join(resp.Error, ", "))
+	}
+	if res == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, res)
+}
+
+func (k *kraken) publicRequest(ctx context.Context, endpoint string, query url.Values, res interface{}) error {
+	fullURL := k.httpURL + "/0/public/" + endpoint
+	if len(query) > 0 {
+		fullURL += "?" + query.Encode()
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return fmt.Errorf("error generating http request: %w", err)
+	}
+	return k.request(ctx, req, res)
+}
+
+func (k *kraken) privateRequest(ctx context.Context, endpoint string, form url.Values, res interface{}) error {
+	if form == nil {
+		form = make(url.Values)
+	}
+	urlPath := "/0/private/" + endpoint
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	k.privateReqMtx.Lock()
+	defer k.privateReqMtx.Unlock()
+
+	nonce := uint64(time.Now().UnixMicro())
+	if nonce <= k.lastNonce {
+		nonce = k.lastNonce + 1
+	}
+	k.lastNonce = nonce
+	nonceStr := strconv.FormatUint(nonce, 10)
+	form.Set("nonce", nonceStr)
+	postData := form.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.httpURL+urlPath, strings.NewReader(postData))
+	if err != nil {
+		return fmt.Errorf("error generating http request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("API-Key", k.apiKey)
+	req.Header.Set("API-Sign", krakenSignature(urlPath, nonceStr, postData, k.secretKey))
+
+	return k.request(ctx, req, res)
+}
+
+func (k *kraken) updateAssets(ctx context.Context) error {
+	var res map[string]*krtypes.Asset
+	if err := k.publicRequest(ctx, "Assets", nil, &res); err != nil {
+		return err
+	}
+	assets := make(map[string]*krakenAsset)
+	for key, a := range res {
+		ticker := krakenTicker(a.Altname)
+		if _, supported := k.tickerIDs[ticker]; !supported {
+			continue
+		}
+		assets[ticker] = &krakenAsset{key: key, ticker: ticker}
+	}
+	k.assets.Store(assets)
+	return nil
+}
+
+// asset returns the krakenAsset for the DEX asset ID.
+func (k *kraken) asset(assetID uint32) (*krakenAsset, error) {
+	ticker, found := k.idTicker[assetID]
+	if !found {
+		return nil, fmt.Errorf("no ticker found for asset ID %d", assetID)
+	}
+	a, found := k.assets.Load().(map[string]*krakenAsset)[ticker]
+	if !found {
+		return nil, fmt.Errorf("%s is not supported by kraken", ticker)
+	}
+	return a, nil
+}
+
+func (k *kraken) krakenMarketToDexMarkets(baseTicker, quoteTicker string) [][2]uint32 {
+	baseIDs := k.tickerIDs[baseTicker]
+	quoteIDs := k.tickerIDs[quoteTicker]
+	markets := make([][2]uint32, 0, len(baseIDs)*len(quoteIDs))
+	for _, baseID := range baseIDs {
+		for _, quoteID := range quoteIDs {
+			markets = append(markets, [2]uint32{baseID, quoteID})
+		}
+	}
+	return markets
+}
+
+// parseKrakenMarket converts the asset pair's trading limits to DEX atomic
+// units.
+func parseKrakenMarket(pair string, p *krtypes.AssetPair, bui, qui *dex.UnitInfo) (*krakenMarket, error) {
+	wsParts := strings.Split(p.WSName, "/")
+	if len(wsParts) != 2 {
+		return nil, fmt.Errorf("invalid wsname %q for pair %s", p.WSName, pair)
+	}
+	baseTicker, quoteTicker := krakenTicker(wsParts[0]), krakenTicker(wsParts[1])
+	bFactor, qFactor := float64(bui.Conventional.ConversionFactor), float64(qui.Conventional.ConversionFactor)
+	tickSize := p.TickSize
+	if tickSize == 0 {
+		tickSize = math.Pow10(-p.PairDecimals)
+	}
+	lotSize := uint64(math.Round(bFactor * math.Pow10(-p.LotDecimals)))
+	if lotSize == 0 {
+		lotSize = 1
+	}
+	return &krakenMarket{
+		AssetPair:   p,
+		pair:        pair,
+		symbol:      baseTicker + "/" + quoteTicker,
+		baseTicker:  baseTicker,
+		quoteTicker: quoteTicker,
+		rateStep:    messageRate(tickSize, bui, qui),
+		lotSize:     lotSize,
+		minQty:      toAtomic(p.OrderMin, bui),
+		minQuoteQty: uint64(math.Round(p.CostMin * qFactor)),
+	}, nil
+}
+
+func (k *kraken) updateMarkets(ctx context.Context) (map[string]*Market, error) {
+	var pairs map[string]*krtypes.AssetPair
+	if err := k.publicRequest(ctx, "AssetPairs", nil, &pairs); err != nil {
+		return nil, fmt.Errorf("error fetching asset pairs: %w", err)
+	}
+	var tickers map[string]*krtypes.Ticker
+	if err := k.publicRequest(ctx, "Ticker", nil, &tickers); err != nil {
+		return nil, fmt.Errorf("error fetching tickers: %w", err)
+	}
+
+	markets := make(map[string]*Market)
+	krMarkets := make(map[string]*krakenMarket)
+	for pair, p := range pairs {
+		if p.Status != "online" {
+			continue
+		}
+		wsParts := strings.Split(p.WSName, "/")
+		if len(wsParts) != 2 {
+			continue
+		}
+		dexMarkets := k.krakenMarketToDexMarkets(krakenTicker(wsParts[0]), krakenTicker(wsParts[1]))
+		if len(dexMarkets) == 0 {
+			continue
+		}
+		bui, err := asset.UnitInfo(dexMarkets[0][0])
+		if err != nil {
+			continue
+		}
+		qui, err := asset.UnitInfo(dexMarkets[0][1])
+		if err != nil {
+			continue
+		}
+		mkt, err := parseKrakenMarket(pair, p, &bui, &qui)
+		if err != nil {
+			k.log.Errorf("Error parsing market: %v", err)
+			continue
+		}
+		krMarkets[mkt.symbol] = mkt
+
+		var day *MarketDay
+		if t := tickers[pair]; t != nil && len(t.LastTrade) > 0 {
+			last, open := parseFloat(t.LastTrade[0]), parseFloat(t.Open)
+			vol := parseFloat(t.Volume[1])
+			avg := parseFloat(t.VWAP[1])
+			day = &MarketDay{
+				Vol:         vol,
+				QuoteVol:    vol * avg,
+				PriceChange: last - open,
+				AvgPrice:    avg,
+				LastPrice:   last,
+				OpenPrice:   open,
+				HighPrice:   parseFloat(t.High[1]),
+				LowPrice:    parseFloat(t.Low[1]),
+			}
+			if open > 0 {
+				day.PriceChangePct = (last - open) / open * 100
+			}
+		}
+
+		for _, m := range dexMarkets {
+			dexMarketSlug := dex.BipIDSymbol(m[0]) + "_" + dex.BipIDSymbol(m[1])
+			markets[dexMarketSlug] = &Market{
+				BaseID:  m[0],
+				QuoteID: m[1],
+				Day:     day,
+			}
+		}
+	}
+
+	k.markets.Store(krMarkets)
+
+	k.marketSnapshotMtx.Lock()
+	defer k.marketSnapshotMtx.Unlock()
+	k.marketSnapshot.m = markets
+	k.marketSnapshot.stamp = time.Now()
+	return markets, nil
+}
+
+func (k *kraken) market(baseID, quoteID uint32) (*krakenMarket, error) {
+	baseTicker, found := k.idTicker[baseID]
+	if !found {
+		return nil, fmt.Errorf("ticker not found for base asset ID %d", baseID)
+	}
+	quoteTicker, found := k.idTicker[quoteID]
+	if !found {
+		return nil, fmt.Errorf("ticker not found for quote asset ID %d", quoteID)
+	}
+	symbol := baseTicker + "/" + quoteTicker
+	mkt, found := k.markets.Load().(map[string]*krakenMarket)[symbol]
+	if !found {
+		return nil, fmt.Errorf("no market found for %s", symbol)
+	}
+	return mkt, nil
+}
+
+func (k *kraken) refreshBalances(ctx context.Context) error {
+	var res map[string]*krtypes.Balance
+	if err := k.privateRequest(ctx, "BalanceEx", nil, &res); err != nil {
+		return err
+	}
+
+	keyTickers := make(map[string]string)
+	for ticker, a := range k.assets.Load().(map[string]*krakenAsset) {
+		keyTickers[a.key] = ticker
+	}
+
+	k.balanceMtx.Lock()
+	defer k.balanceMtx.Unlock()
+
+	for key, bal := range res {
+		ticker, found := keyTickers[key]
+		if !found {
+			continue
+		}
+		for _, assetID := range k.tickerIDs[ticker] {
+			ui, err := asset.UnitInfo(assetID)
+			if err != nil {
+				k.log.Errorf("Error getting unit info for asset ID %d: %v", assetID, err)
+				continue
+			}
+			updated := &ExchangeBalance{
+				Available: toAtomic(bal.Balance-bal.HoldTrade, &ui),
+				Locked:    toAtomic(bal.HoldTrade, &ui),
+			}
+			old, found := k.balances[assetID]
+			k.balances[assetID] = updated
+			if found && *old != *updated && k.broadcast != nil {
+				k.broadcast(&BalanceUpdate{
+					AssetID: assetID,
+					Balance: updated,
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+func (k *kraken) handleExecution(e *krtypes.Execution) {
+	k.tradeUpdaterMtx.Lock()
+	defer k.tradeUpdaterMtx.Unlock()
+
+	info, found := k.tradeInfo[e.OrderID]
+	if !found {
+		// Not one of ours, or already completed.
+		k.log.Tracef("Execution report for unknown order %s", e.OrderID)
+		return
+	}
+
+	updater, found := k.tradeUpdaters[info.updaterID]
+	if !found {
+		k.log.Errorf("No trade updater found for order ID %s", e.OrderID)
+		return
+	}
+
+	bui, err := asset.UnitInfo(info.baseID)
+	if err != nil {
+		k.log.Errorf("Error getting unit info for asset ID %d: %v", info.baseID, err)
+		return
+	}
+	qui, err := asset.UnitInfo(info.quoteID)
+	if err != nil {
+		k.log.Errorf("Error getting unit info for asset ID %d: %v", info.quoteID, err)
+		return
+	}
+
+	// Status updates do not always repeat the cumulative fills, so never
+	// let them regress.
+	if filled := toAtomic(e.CumQty, &bui); filled > info.baseFilled {
+		info.baseFilled = filled
+	}
+	if filled := toAtomic(e.CumCost, &qui); filled > info.quoteFilled {
+		info.quoteFilled = filled
+	}
+	if e.ExecType == "trade" {
+		baseTicker, quoteTicker := k.idTicker[info.baseID], k.idTicker[info.quoteID]
+		for _, fee := range e.Fees {
+			switch fee.Asset {
+			case baseTicker:
+				info.baseFees += toAtomic(fee.Qty, &bui)
+			case quoteTicker:
+				info.quoteFees += toAtomic(fee.Qty, &qui)
+			default:
+				k.log.Errorf("Unknown fee asset %q for order %s", fee.Asset, e.OrderID)
+			}
+		}
+	}
+
+	complete := e.OrderStatus == "filled" || e.OrderStatus == "canceled" || e.OrderStatus == "expired"
+	baseFilled, quoteFilled := krakenFilled(info.sell, info.baseFilled, info.quoteFilled, info.baseFees, info.quoteFees)
+
+	updater <- &Trade{
+		ID:          e.OrderID,
+		Sell:        info.sell,
+		Qty:         info.qty,
+		Rate:        info.rate,
+		Market:      info.market,
+		BaseID:      info.baseID,
+		QuoteID:     info.quoteID,
+		BaseFilled:  baseFilled,
+		QuoteFilled: quoteFilled,
+		Complete:    complete,
+	}
+
+	if complete {
+		delete(k.tradeInfo, e.OrderID)
+	}
+}
+
+// krakenFilled calculates the net amounts that were taken from and added to
+// the user's balances by an order, given the gross filled quantities and the
+// fees paid in each asset.
+func krakenFilled(sell bool, baseQty, quoteQty, baseFees, quoteFees uint64) (baseFilled, quoteFilled uint64) {
+	if sell {
+		return baseQty + baseFees, utils.SafeSub(quoteQty, quoteFees)
+	}
+	return utils.SafeSub(baseQty, baseFees), quoteQty + quoteFees
+}
+
+func (k *kraken) handleUserMessage(b []byte) {
+	var msg krtypes.WSMessage
+	if err := json.Unmarshal(b, &msg); err != nil {
+		k.log.Errorf("Error unmarshaling user message: %v", err)
+		return
+	}
+
+	if msg.Method != "" {
+		if !msg.Success {
+			k.log.Errorf("User stream %s error: %s", msg.Method, msg.Error)
+		}
+		return
+	}
+
+	switch msg.Channel {
+	case "executions":
+		var execs []*krtypes.Execution
+		if err := json.Unmarshal(msg.Data, &execs); err != nil {
+			k.log.Errorf("Error unmarshaling executions: %v", err)
+			return
+		}
+		for _, e := range execs {
+			k.handleExecution(e)
+		}
+	case "balances":
+		if msg.Type != "update" {
+			return
+		}
+		// The balances channel reports the total balance, but not the amount
+		// on hold, so refresh from the REST API.
+		if !k.refreshingBalance.CompareAndSwap(false, true) {
+			return
+		}
+		go func() {
+			defer k.refreshingBalance.Store(false)
+			if err := k.refreshBalances(k.ctx); err != nil {
+				k.log.Errorf("Error refreshing balances: %v", err)
+			}
+		}()
+	case "heartbeat", "status":
+	default:
+		k.log.Debugf("User stream message for unknown channel %q", msg.Channel)
+	}
+}
+
+// subscribeUserStream gets a new websocket token and subscribes to the
+// executions and balances channels.
+func (k *kraken) subscribeUserStream(ctx context.Context) error {
+	var tok krtypes.WebsocketToken
+	if err := k.privateRequest(ctx, "GetWebSocketsToken", nil, &tok); err != nil {
+		return fmt.Errorf("error getting websocket token: %w", err)
+	}
+	no := false
+	for _, sub := range []*krtypes.UserSubscription{
+		{Channel: "executions", Token: tok.Token, SnapOrders: &no, SnapTrades: &no},
+		{Channel: "balances", Token: tok.Token, Snapshot: &no},
+	} {
+		reqB, err := json.Marshal(&krtypes.WSRequest{Method: "subscribe", Params: sub})
+		if err != nil {
+			return err
+		}
+		if err := k.userStream.SendRaw(reqB); err != nil {
+			return fmt.Errorf("error subscribing to %s: %w", sub.Channel, err)
+		}
+	}
+	return nil
+}
+
+func (k *kraken) connectUserStream(ctx context.Context) (*sync.WaitGroup, error) {
+	initialConnect := true
+	conn, err := comms.NewWsConn(&comms.WsCfg{
+		URL:           k.authWsURL,
+		PingWait:      time.Minute,
+		ReconnectSync: func() {},
+		ConnectEventFunc: func(cs comms.ConnectionStatus) {
+			if cs != comms.Connected {
+				return
+			}
+			if initialConnect {
+				initialConnect = false
+				return
+			}
+			// Subscriptions do not survive a reconnect, and the old
+			// token may have expired.
+			go func() {
+				if err := k.subscribeUserStream(ctx); err != nil {
+					k.log.Errorf("Error resubscribing to user stream: %v", err)
+				}
+			}()
+		},
+		Logger:     k.log.SubLogger("WS-user"),
+		RawHandler: k.handleUserMessage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating WsConn: %w", err)
+	}
+
+	cm := dex.NewConnectionMaster(conn)
+	if err := cm.ConnectOnce(ctx); err != nil {
+		return nil, fmt.Errorf("error connecting to user stream: %w", err)
+	}
+	k.userStream = conn
+
+	if err := k.subscribeUserStream(ctx); err != nil {
+		cm.Disconnect()
+		return nil, err
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		cm.Disconnect()
+	}()
+
+	return &wg, nil
+}
+
+func (k *kraken) Connect(ctx context.Context) (*sync.WaitGroup, error) {
+	k.ctx = ctx
+
+	if err := k.updateAssets(ctx); err != nil {
+		return nil, fmt.Errorf("error fetching assets: %w", err)
+	}
+
+	if _, err := k.updateMarkets(ctx); err != nil {
+		return nil, fmt.Errorf("error fetching markets: %w", err)
+	}
+
+	if err := k.refreshBalances(ctx); err != nil {
+		return nil, fmt.Errorf("error fetching balances: %w", err)
+	}
+
+	wg, err := k.connectUserStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The balances channel triggers a refresh whenever a balance changes,
+	// but do a periodic refresh in case a message is missed.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Minute * 5)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := k.refreshBalances(ctx); err != nil {
+					k.log.Errorf("Error refreshing balances: %v", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Update assets / markets every 10 minutes. These shouldn't change often.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Minute * 10)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := k.updateAssets(ctx); err != nil {
+					k.log.Errorf("Error fetching assets: %v", err)
+				}
+				if _, err := k.updateMarkets(ctx); err != nil {
+					k.log.Errorf("Error fetching markets: %v", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		k.booksMtx.RLock()
+		defer k.booksMtx.RUnlock()
+		for _, book := range k.books {
+			book.cm.Disconnect()
+		}
+	}()
+
+	return wg, nil
+}
+
+// Balance returns the balance of an asset at the CEX.
+func (k *kraken) Balance(assetID uint32) (*ExchangeBalance, error) {
+	k.balanceMtx.RLock()
+	defer k.balanceMtx.RUnlock()
+	bal, found := k.balances[assetID]
+	if !found {
+		return nil, fmt.Errorf("no %s balance found", dex.BipIDSymbol(assetID))
+	}
+	return bal, nil
+}
+
+// Balances returns the balances of known assets on the CEX.
+func (k *kraken) Balances(ctx context.Context) (map[uint32]*ExchangeBalance, error) {
+	k.balanceMtx.RLock()
+	if len(k.balances) > 0 {
+		defer k.balanceMtx.RUnlock()
+		return utils.CopyMap(k.balances), nil
+	}
+	k.balanceMtx.RUnlock()
+
+	if err := k.updateAssets(ctx); err != nil {
+		return nil, fmt.Errorf("error fetching assets: %w", err)
+	}
+	if err := k.refreshBalances(ctx); err != nil {
+		return nil, err
+	}
+
+	k.balanceMtx.RLock()
+	defer k.balanceMtx.RUnlock()
+	return utils.CopyMap(k.balances), nil
+}
+
+// Markets returns the list of markets at the CEX.
+func (k *kraken) Markets(ctx context.Context) (map[string]*Market, error) {
+	k.marketSnapshotMtx.RLock()
+	const snapshotTimeout = time.Minute * 30
+	if k.marketSnapshot.m != nil && time.Since(k.marketSnapshot.stamp) < snapshotTimeout {
+		defer k.marketSnapshotMtx.RUnlock()
+		return k.marketSnapshot.m, nil
+	}
+	k.marketSnapshotMtx.RUnlock()
+
+	if len(k.assets.Load().(map[string]*krakenAsset)) == 0 {
+		if err := k.updateAssets(ctx); err != nil {
+			return nil, fmt.Errorf("error fetching assets: %w", err)
+		}
+	}
+
+	return k.updateMarkets(ctx)
+}
+
+// SubscribeMarket subscribes to order book updates on a market. This must
+// be called before calling VWAP or MidGap.
+func (k *kraken) SubscribeMarket(ctx context.Context, baseID, quoteID uint32) error {
+	k.subMarketMtx.Lock()
+	defer k.subMarketMtx.Unlock()
+
+	mkt, err := k.market(baseID, quoteID)
+	if err != nil {
+		return err
+	}
+
+	k.booksMtx.RLock()
+	book, exists := k.books[mkt.symbol]
+	k.booksMtx.RUnlock()
+	if exists {
+		book.mtx.Lock()
+		book.numSubscribers++
+		book.mtx.Unlock()
+		return nil
+	}
+
+	bui, err := asset.UnitInfo(baseID)
+	if err != nil {
+		return fmt.Errorf("error getting unit info for base asset ID %d: %v", baseID, err)
+	}
+	qui, err := asset.UnitInfo(quoteID)
+	if err != nil {
+		return fmt.Errorf("error getting unit info for quote asset ID %d: %v", quoteID, err)
+	}
+
+	book = newKRBook(k.wsURL, mkt, &bui, &qui, k.log)
+	if err := book.sync(k.ctx); err != nil {
+		return fmt.Errorf("error syncing book: %v", err)
+	}
+
+	k.booksMtx.Lock()
+	k.books[mkt.symbol] = book
+	k.booksMtx.Unlock()
+
+	return nil
+}
+
+// UnsubscribeMarket unsubscribes from order book updates on a market.
+func (k *kraken) UnsubscribeMarket(baseID, quoteID uint32) error {
+	k.subMarketMtx.Lock()
+	defer k.subMarketMtx.Unlock()
+
+	book, err := k.book(baseID, quoteID)
+	if err != nil {
+		return err
+	}
+
+	book.mtx.Lock()
+	book.numSubscribers--
+	remaining := book.numSubscribers
+	book.mtx.Unlock()
+
+	if remaining == 0 {
+		k.booksMtx.Lock()
+		delete(k.books, book.mkt.symbol)
+		k.booksMtx.Unlock()
+		go book.cm.Disconnect()
+	}
+
+	return nil
+}
+
+// SubscribeTradeUpdates returns a channel that the caller can use to
+// listen for updates to a trade's status. When the subscription ID
+// returned from this function is passed as the updaterID argument to
+// Trade, then updates to the trade will be sent on the updated channel
+// returned from this function.
+func (k *kraken) SubscribeTradeUpdates() (<-chan *Trade, func(), int) {
+	k.tradeUpdaterMtx.Lock()
+	defer k.tradeUpdaterMtx.Unlock()
+
+	updaterID := k.tradeUpdateCounter
+	k.tradeUpdateCounter++
+	updater := make(chan *Trade, 256)
+	k.tradeUpdaters[updaterID] = updater
+
+	unsubscribe := func() {
+		k.tradeUpdaterMtx.Lock()
+		delete(k.tradeUpdaters, updaterID)
+		k.tradeUpdaterMtx.Unlock()
+	}
+
+	return updater, unsubscribe, updaterID
+}
+
+// buildKrakenOrderRequest builds the form for an AddOrder request. Kraken
+// does not accept a quote-asset quantity, so for market buys the caller must
+// convert quoteQty to a base quantity using the book, and pass it as
+// marketBuyQty. If marketBuyQty is zero for a market buy, only the quote
+// quantity is validated and no request is generated.
+func buildKrakenOrderRequest(mkt *krakenMarket, bui, qui *dex.UnitInfo, sell bool, orderType OrderType, rate, qty, quoteQty, marketBuyQty uint64) (url.Values, uint64, error) {
+	if qty > 0 && quoteQty > 0 {
+		return nil, 0, fmt.Errorf("cannot specify both quantity and quote quantity")
+	}
+	if sell && quoteQty > 0 {
+		return nil, 0, fmt.Errorf("quote quantity cannot be used for sell orders")
+	}
+	if !sell && orderType == OrderTypeMarket && qty > 0 {
+		return nil, 0, fmt.Errorf("quoteQty MUST be used for market buys")
+	}
+
+	bFactor, qFactor := bui.Conventional.ConversionFactor, qui.Conventional.ConversionFactor
+
+	qtyString := func(baseQty uint64) (string, error) {
+		if baseQty < mkt.minQty {
+			return "", fmt.Errorf("quantity %s is less than the minimum %s for market %s",
+				bui.FormatConventional(baseQty), bui.FormatConventional(mkt.minQty), mkt.symbol)
+		}
+		convQty := float64(steppedQty(baseQty, mkt.lotSize)) / float64(bFactor)
+		return strconv.FormatFloat(convQty, 'f', mkt.LotDecimals, 64), nil
+	}
+
+	form := make(url.Values)
+	form.Set("pair", mkt.pair)
+	form.Set("type", "buy")
+	if sell {
+		form.Set("type", "sell")
+	}
+	// Always take fees in the quote asset, so that the filled quantities
+	// are easier to reason about.
+	form.Set("oflags", "fciq")
+
+	var qtyToReturn uint64
+	switch orderType {
+	case OrderTypeLimit, OrderTypeLimitIOC:
+		if quoteQty > 0 {
+			qty = calc.QuoteToBase(rate, quoteQty)
+		}
+		if qty == 0 {
+			return nil, 0, fmt.Errorf("must specify quantity or quote quantity")
+		}
+		qtyStr, err := qtyString(qty)
+		if err != nil {
+			return nil, 0, err
+		}
+		convRate := calc.ConventionalRateAlt(steppedRate(rate, mkt.rateStep), bFactor, qFactor)
+		form.Set("ordertype", "limit")
+		form.Set("volume", qtyStr)
+		form.Set("price", strconv.FormatFloat(convRate, 'f', mkt.PairDecimals, 64))
+		form.Set("timeinforce", "GTC")
+		if orderType == OrderTypeLimitIOC {
+			form.Set("timeinforce", "IOC")
+		}
+		qtyToReturn = qty
+	case OrderTypeMarket:
+		form.Set("ordertype", "market")
+		if sell {
+			qtyStr, err := qtyString(qty)
+			if err != nil {
+				return nil, 0, err
+			}
+			form.Set("volume", qtyStr)
+			qtyToReturn = qty
+			break
+		}
+		if quoteQty < mkt.minQuoteQty {
+			return nil, 0, fmt.Errorf("quote quantity %s is less than the minimum %s for market %s",
+				qui.FormatConventional(quoteQty), qui.FormatConventional(mkt.minQuoteQty), mkt.symbol)
+		}
+		qtyToReturn = quoteQty
+		if marketBuyQty == 0 {
+			return nil, qtyToReturn, nil
+		}
+		qtyStr, err := qtyString(marketBuyQty)
+		if err != nil {
+			return nil, 0, err
+		}
+		form.Set("volume", qtyStr)
+	default:
+		return nil, 0, fmt.Errorf("unknown order type %d", orderType)
+	}
+
+	return form, qtyToReturn, nil
+}
+
+// Trade executes a trade on the CEX.
+//   - subscriptionID takes an ID returned from SubscribeTradeUpdates.
+//   - Rate is ignored for market orders.
+//   - Qty is in units of base asset, quoteQty is in units of quote asset.
+//     Only one of qty or quoteQty should be non-zero.
+//   - QuoteQty is only allowed for BUY orders, and it is required for market
+//     buy orders.
+func (k *kraken) Trade(ctx context.Context, baseID, quoteID uint32, sell bool, rate, qty, quoteQty uint64, orderType OrderType, subscriptionID int) (*Trade, error) {
+	mkt, err := k.market(baseID, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	bui, err := asset.UnitInfo(baseID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting unit info for base asset ID %d: %v", baseID, err)
+	}
+	qui, err := asset.UnitInfo(quoteID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting unit info for quote asset ID %d: %v", quoteID, err)
+	}
+
+	var marketBuyQty uint64
+	if orderType == OrderTypeMarket && !sell && quoteQty > 0 {
+		book, err := k.book(baseID, quoteID)
+		if err != nil {
+			return nil, fmt.Errorf("a synced book is required for market buys: %w", err)
+		}
+		vwap, _, filled, err := book.invVWAP(false, quoteQty)
+		if err != nil {
+			return nil, err
+		}
+		if !filled {
+			return nil, fmt.Errorf("not enough liquidity on the book for a market buy of %s", qui.FormatConventional(quoteQty))
+		}
+		marketBuyQty = calc.QuoteToBase(vwap, quoteQty)
+	}
+
+	form, qtyToReturn, err := buildKrakenOrderRequest(mkt, &bui, &qui, sell, orderType, rate, qty, quoteQty, marketBuyQty)
+	if err != nil {
+		return nil, fmt.Errorf("error building order request: %w", err)
+	}
+
+	// Hold the lock so that execution reports for this order are not
+	// processed until the order is registered.
+	k.tradeUpdaterMtx.Lock()
+	defer k.tradeUpdaterMtx.Unlock()
+
+	if _, found := k.tradeUpdaters[subscriptionID]; !found {
+		return nil, fmt.Errorf("no trade updater found for subscription ID %d", subscriptionID)
+	}
+
+	var res krtypes.AddOrderResult
+	if err := k.privateRequest(ctx, "AddOrder", form, &res); err != nil {
+		return nil, fmt.Errorf("error placing order: %w", err)
+	}
+	if len(res.TxIDs) != 1 {
+		return nil, fmt.Errorf("expected 1 order ID, got %d", len(res.TxIDs))
+	}
+	orderID := res.TxIDs[0]
+
+	market := orderType == OrderTypeMarket
+	k.tradeInfo[orderID] = &krakenTradeInfo{
+		tradeInfo: &tradeInfo{
+			updaterID: subscriptionID,
+			baseID:    baseID,
+			quoteID:   quoteID,
+			sell:      sell,
+			rate:      rate,
+			qty:       qtyToReturn,
+			market:    market,
+		},
+	}
+
+	return &Trade{
+		ID:      orderID,
+		Sell:    sell,
+		Rate:    rate,
+		Qty:     qtyToReturn,
+		BaseID:  baseID,
+		QuoteID: quoteID,
+		Market:  market,
+	}, nil
+}
+
+// ValidateTrade validates a trade before it is executed.
+func (k *kraken) ValidateTrade(baseID, quoteID uint32, sell bool, rate, qty, quoteQty uint64, orderType OrderType) error {
+	mkt, err := k.market(baseID, quoteID)
+	if err != nil {
+		return err
+	}
+	bui, err := asset.UnitInfo(baseID)
+	if err != nil {
+		return fmt.Errorf("error getting unit info for base asset ID %d: %v", baseID, err)
+	}
+	qui, err := asset.UnitInfo(quoteID)
+	if err != nil {
+		return fmt.Errorf("error getting unit info for quote asset ID %d: %v", quoteID, err)
+	}
+	_, _, err = buildKrakenOrderRequest(mkt, &bui, &qui, sell, orderType, rate, qty, quoteQty, 0)
+	return err
+}
+
+// CancelTrade cancels a trade on the CEX.
+func (k *kraken) CancelTrade(ctx context.Context, baseID, quoteID uint32, tradeID string) error {
+	form := url.Values{"txid": []string{tradeID}}
+	var res krtypes.CancelOrderResult
+	if err := k.privateRequest(ctx, "CancelOrder", form, &res); err != nil {
+		return fmt.Errorf("error cancelling order: %w", err)
+	}
+	return nil
+}
+
+// TradeStatus returns the current status of a trade.
+func (k *kraken) TradeStatus(ctx context.Context, id string, baseID, quoteID uint32) (*Trade, error) {
+	bui, err := asset.UnitInfo(baseID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting unit info for base asset ID %d: %v", baseID, err)
+	}
+	qui, err := asset.UnitInfo(quoteID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting unit info for quote asset ID %d: %v", quoteID, err)
+	}
+
+	form := url.Values{"txid": []string{id}}
+	var res map[string]*krtypes.Order
+	if err := k.privateRequest(ctx, "QueryOrders", form, &res); err != nil {
+		return nil, fmt.Errorf("error querying order: %w", err)
+	}
+	ord, found := res[id]
+	if !found {
+		return nil, fmt.Errorf("order %s not found", id)
+	}
+
+	sell := ord.Description.Type == "sell"
+	market := ord.Description.OrderType == "market"
+	// Fees are in the quote asset unless the order was placed with fcib.
+	var baseFees, quoteFees uint64
+	if strings.Contains(ord.OrderFlags, "fcib") {
+		baseFees = toAtomic(ord.Fee, &bui)
+	} else {
+		quoteFees = toAtomic(ord.Fee, &qui)
+	}
+	baseFilled, quoteFilled := krakenFilled(sell, toAtomic(ord.VolumeExec, &bui), toAtomic(ord.Cost, &qui), baseFees, quoteFees)
+
+	qty := toAtomic(ord.Volume, &bui)
+	if market && !sell {
+		// Market buys are tracked by quote quantity.
+		if info := k.trackedTrade(id); info != nil {
+			qty = info.qty
+		}
+	}
+
+	return &Trade{
+		ID:          id,
+		Sell:        sell,
+		Qty:         qty,
+		Rate:        messageRate(ord.Description.Price, &bui, &qui),
+		Market:      market,
+		BaseID:      baseID,
+		QuoteID:     quoteID,
+		BaseFilled:  baseFilled,
+		QuoteFilled: quoteFilled,
+		Complete:    ord.Status != "pending" && ord.Status != "open",
+	}, nil
+}
+
+func (k *kraken) trackedTrade(id string) *tradeInfo {
+	k.tradeUpdaterMtx.RLock()
+	defer k.tradeUpdaterMtx.RUnlock()
+	if info, found := k.tradeInfo[id]; found {
+		return info.tradeInfo
+	}
+	return nil
+}
+
+func (k *kraken) book(baseID, quoteID uint32) (*krBook, error) {
+	mkt, err := k.market(baseID, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	k.booksMtx.RLock()
+	book, found := k.books[mkt.symbol]
+	k.booksMtx.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("no book for market %s", mkt.symbol)
+	}
+	return book, nil
+}
+
+// Book generates the CEX's current view of a market's orderbook.
+func (k *kraken) Book(baseID, quoteID uint32) (buys, sells []*core.MiniOrder, _ error) {
+	book, err := k.book(baseID, quoteID)
+	if err != nil {
+		return nil, nil, err
+	}
+	bids, asks := book.book.snap()
+	baseFactor := book.bui.Conventional.ConversionFactor
+	quoteFactor := book.qui.Conventional.ConversionFactor
+	buys = convertSide(bids, false, baseFactor, quoteFactor)
+	sells = convertSide(asks, true, baseFactor, quoteFactor)
+	return
+}
+
+// VWAP returns the volume weighted average price for a certain quantity
+// of the base asset on a market.
+func (k *kraken) VWAP(baseID, quoteID uint32, sell bool, qty uint64) (vwap, extrema uint64, filled bool, err error) {
+	book, err := k.book(baseID, quoteID)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return book.vwap(!sell, qty)
+}
+
+// InvVWAP returns the inverse volume weighted average price for a certain
+// quantity of the quote asset on a market. SubscribeMarket must be called,
+// and the market must be synced before results can be expected.
+func (k *kraken) InvVWAP(baseID, quoteID uint32, sell bool, qty uint64) (vwap, extrema uint64, filled bool, err error) {
+	book, err := k.book(baseID, quoteID)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return book.invVWAP(!sell, qty)
+}
+
+// MidGap returns the mid-gap price for a market.
+func (k *kraken) MidGap(baseID, quoteID uint32) uint64 {
+	book, err := k.book(baseID, quoteID)
+	if err != nil {
+		k.log.Errorf("Error getting book: %v", err)
+		return 0
+	}
+	midGap, err := book.midGap()
+	if err != nil {
+		k.log.Errorf("Error getting mid gap: %v", err)
+		return 0
+	}
+	return midGap
+}
+
+// krakenTransferMethod finds the deposit or withdrawal method for the asset.
+// For tokens, the method is identified by the network name. For other
+// assets, the method for the native chain is preferred, which is the only one
+// without a parenthesized network.
+func krakenTransferMethod(assetID uint32, methods []string) (string, error) {
+	if len(methods) == 0 {
+		return "", fmt.Errorf("no methods available")
+	}
+	if networks, isToken := supportedKrakenTokens[assetID]; isToken {
+		for _, m := range methods {
+			for _, network := range networks {
+				if strings.Contains(strings.ToLower(m), strings.ToLower(network)) {
+					return m, nil
+				}
+			}
+		}
+		return "", fmt.Errorf("no method found for %s", dex.BipIDSymbol(assetID))
+	}
+	for _, m := range methods {
+		if !strings.Contains(m, "(") {
+			return m, nil
+		}
+	}
+	return methods[0], nil
+}
+
+func (k *kraken) depositMethod(ctx context.Context, assetID uint32, kAsset *krakenAsset) (string, error) {
+	var res []*krtypes.DepositMethod
+	if err := k.privateRequest(ctx, "DepositMethods", url.Values{"asset": []string{kAsset.key}}, &res); err != nil {
+		return "", fmt.Errorf("error fetching deposit methods: %w", err)
+	}
+	methods := make([]string, 0, len(res))
+	for _, m := range res {
+		methods = append(methods, m.Method)
+	}
+	return krakenTransferMethod(assetID, methods)
+}
+
+// GetDepositAddress returns a deposit address for an asset.
+func (k *kraken) GetDepositAddress(ctx context.Context, assetID uint32) (string, error) {
+	kAsset, err := k.asset(assetID)
+	if err != nil {
+		return "", err
+	}
+	method, err := k.depositMethod(ctx, assetID, kAsset)
+	if err != nil {
+		return "", err
+	}
+
+	getAddrs := func(newAddr bool) ([]*krtypes.DepositAddress, error) {
+		form := url.Values{
+			"asset":  []string{kAsset.key},
+			"method": []string{method},
+		}
+		if newAddr {
+			form.Set("new", "true")
+		}
+		var res []*krtypes.DepositAddress
+		if err := k.privateRequest(ctx, "DepositAddresses", form, &res); err != nil {
+			return nil, fmt.Errorf("error fetching deposit addresses: %w", err)
+		}
+		return res, nil
+	}
+
+	addrs, err := getAddrs(false)
+	if err != nil {
+		return "", err
+	}
+	if len(addrs) == 0 {
+		if addrs, err = getAddrs(true); err != nil {
+			return "", err
+		}
+	}
+	if len(addrs) == 0 {
+		return "", fmt.Errorf("no deposit address returned for %s", kAsset.ticker)
+	}
+	return addrs[0].Address, nil
+}
+
+// ConfirmDeposit checks whether a deposit has been credited, and returns the
+// amount credited.
+func (k *kraken) ConfirmDeposit(ctx context.Context, deposit *DepositData) (bool, uint64) {
+	kAsset, err := k.asset(deposit.AssetID)
+	if err != nil {
+		k.log.Errorf("Error getting asset for deposit: %v", err)
+		return false, 0
+	}
+	ui, err := asset.UnitInfo(deposit.AssetID)
+	if err != nil {
+		k.log.Errorf("Error getting unit info for asset ID %d: %v", deposit.AssetID, err)
+		return false, 0
+	}
+
+	form := url.Values{"asset": []string{kAsset.key}}
+	if k.httpURL == fakeKrakenURL {
+		// The fake server needs to know the method, amount and txid to
+		// check the harness wallet.
+		method, err := k.depositMethod(ctx, deposit.AssetID, kAsset)
+		if err != nil {
+			k.log.Errorf("Error getting deposit method: %v", err)
+			return false, 0
+		}
+		form.Set("method", method)
+		form.Set("txid", deposit.TxID)
+		form.Set("amt", strconv.FormatFloat(deposit.AmountConventional, 'f', 9, 64))
+	}
+
+	var res []*krtypes.Transfer
+	if err := k.privateRequest(ctx, "DepositStatus", form, &res); err != nil {
+		k.log.Errorf("Error fetching deposit status: %v", err)
+		return false, 0
+	}
+
+	for _, d := range res {
+		if d.TxID != deposit.TxID {
+			continue
+		}
+		switch d.Status {
+		case krtypes.TransferStatusSuccess:
+			return true, toAtomic(d.Amount-d.Fee, &ui)
+		case krtypes.TransferStatusFailure:
+			k.log.Errorf("Deposit %s to kraken failed: %s", deposit.TxID, d.Info)
+			return true, 0
+		default:
+			return false, 0
+		}
+	}
+
+	return false, 0
+}
+
+// withdrawalKey finds the name of the withdrawal address that was set up in
+// the Kraken account for the address. Kraken only allows withdrawals to
+// addresses that have been added to the account.
+func (k *kraken) withdrawalKey(ctx context.Context, assetID uint32, kAsset *krakenAsset, address string) (string, error) {
+	form := url.Values{"asset": []string{kAsset.key}}
+	if k.httpURL == fakeKrakenURL {
+		// There is no way to add withdrawal addresses to the fake server's
+		// account, so it generates an entry for the address.
+		form.Set("address", address)
+	}
+	var res []*krtypes.WithdrawAddress
+	if err := k.privateRequest(ctx, "WithdrawAddresses", form, &res); err != nil {
+		return "", fmt.Errorf("error fetching withdrawal addresses: %w", err)
+	}
+	methods := make([]string, 0, len(res))
+	for _, a := range res {
+		methods = append(methods, a.Method)
+	}
+	method, err := krakenTransferMethod(assetID, methods)
+	if err != nil {
+		return "", err
+	}
+	for _, a := range res {
+		if a.Method != method || !strings.EqualFold(a.Address, address) {
+			continue
+		}
+		if !a.Verified {
+			return "", fmt.Errorf("withdrawal address %s has not been verified", address)
+		}
+		return a.Key, nil
+	}
+	return "", fmt.Errorf("address %s is not a withdrawal address for %s in the kraken account", address, kAsset.ticker)
+}
+
+// Withdraw withdraws funds from the CEX to a certain address. Kraken adds the
+// fee on top of the amount, so more than the amount specified will be
+// deducted from the balance.
+func (k *kraken) Withdraw(ctx context.Context, assetID uint32, amt uint64, address string) (string, uint64, error) {
+	kAsset, err := k.asset(assetID)
+	if err != nil {
+		return "", 0, err
+	}
+	ui, err := asset.UnitInfo(assetID)
+	if err != nil {
+		return "", 0, fmt.Errorf("error getting unit info for asset ID %d: %v", assetID, err)
+	}
+	key, err := k.withdrawalKey(ctx, assetID, kAsset, address)
+	if err != nil {
+		return "", 0, err
+	}
+
+	form := url.Values{
+		"asset":  []string{kAsset.key},
+		"key":    []string{key},
+		"amount": []string{strconv.FormatFloat(toConv(amt, &ui), 'f', -1, 64)},
+	}
+
+	var info krtypes.WithdrawInfo
+	if err := k.privateRequest(ctx, "WithdrawInfo", form, &info); err != nil {
+		return "", 0, fmt.Errorf("error fetching withdrawal info: %w", err)
+	}
+
+	form.Set("address", address)
+	var res krtypes.WithdrawResult
+	if err := k.privateRequest(ctx, "Withdraw", form, &res); err != nil {
+		return "", 0, fmt.Errorf("error withdrawing: %w", err)
+	}
+
+	return res.RefID, amt + toAtomic(info.Fee, &ui), nil
+}
+
+// ConfirmWithdrawal checks whether a withdrawal has been completed. If the
+// withdrawal has not yet been sent, ErrWithdrawalPending is returned.
+func (k *kraken) ConfirmWithdrawal(ctx context.Context, withdrawalID string, assetID uint32) (uint64, string, error) {
+	kAsset, err := k.asset(assetID)
+	if err != nil {
+		return 0, "", err
+	}
+	ui, err := asset.UnitInfo(assetID)
+	if err != nil {
+		return 0, "", fmt.Errorf("error getting unit info for asset ID %d: %v", assetID, err)
+	}
+
+	var res []*krtypes.Transfer
+	if err := k.privateRequest(ctx, "WithdrawStatus", url.Values{"asset": []string{kAsset.key}}, &res); err != nil {
+		return 0, "", fmt.Errorf("error fetching withdrawal status: %w", err)
+	}
+
+	for _, w := range res {
+		if w.RefID != withdrawalID {
+			continue
+		}
+		if w.Status == krtypes.TransferStatusFailure {
+			return 0, "", fmt.Errorf("withdrawal %s failed: %s", withdrawalID, w.Info)
+		}
+		if w.TxID == "" {
+			return 0, "", ErrWithdrawalPending
+		}
+		return toAtomic(w.Amount, &ui), w.TxID, nil
+	}
+
+	return 0, "", fmt.Errorf("withdrawal status not found for %s", withdrawalID)
+}
@@ -0,0 +1,322 @@
+package libxc
+
+import (
+	"encoding/base64"
+	"math"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/mm/libxc/krtypes"
+	"decred.org/dcrdex/dex/calc"
+)
+
+func TestKrakenSignature(t *testing.T) {
+	// Example from https://docs.kraken.com/api/docs/guides/spot-rest-auth
+	secret, _ := base64.StdEncoding.DecodeString("kQH5HW/8p1uGOVjbgWA7FunAmGO8lsSUXNsu3eow76sz84Q18fWxnyRzBHCd3pd5nE9qa99HAZtuZuj6F1huXg==")
+	const (
+		nonce    = "1616492376594"
+		postData = "nonce=1616492376594&ordertype=limit&pair=XBTUSD&price=37500&type=buy&volume=1.25"
+		expSig   = "4/dpxb3iT4tp/ZCVEwSnEsLxx0bqyhLpdfOpc6fn7OR8+UClSV5n9E6aSS8MPtnRfp32bAb0nmbRn6H8ndwLUQ=="
+	)
+	if sig := krakenSignature("/0/private/AddOrder", nonce, postData, secret); sig != expSig {
+		t.Fatalf("wrong signature. expected %s, got %s", expSig, sig)
+	}
+}
+
+func TestKrakenBookChecksum(t *testing.T) {
+	btcUI, _ := asset.UnitInfo(0)
+	usdcUI, _ := asset.UnitInfo(966001)
+	mkt := &krakenMarket{AssetPair: &krtypes.AssetPair{PairDecimals: 1, LotDecimals: 8}}
+
+	entry := func(rate, qty float64) *obEntry {
+		return &obEntry{
+			rate: calc.MessageRate(rate, btcUI, usdcUI),
+			qty:  uint64(math.Round(qty * 1e8)),
+		}
+	}
+	asks := []*obEntry{entry(45285.2, 0.001), entry(45285.3, 2.5)}
+	bids := []*obEntry{entry(45283.5, 0.5)}
+
+	// crc32("452852" + "100000" + "452853" + "250000000" + "452835" + "50000000")
+	const expChecksum = 2933608812
+	if checksum := krakenBookChecksum(bids, asks, mkt, &btcUI, &usdcUI); checksum != expChecksum {
+		t.Fatalf("wrong checksum. expected %d, got %d", expChecksum, checksum)
+	}
+}
+
+func TestKrakenFilled(t *testing.T) {
+	tests := []struct {
+		name           string
+		sell           bool
+		baseQty        uint64
+		quoteQty       uint64
+		baseFees       uint64
+		quoteFees      uint64
+		expBaseFilled  uint64
+		expQuoteFilled uint64
+	}{
+		{
+			name:           "sell, quote fees",
+			sell:           true,
+			baseQty:        1e8,
+			quoteQty:       5e6,
+			quoteFees:      1e4,
+			expBaseFilled:  1e8,
+			expQuoteFilled: 5e6 - 1e4,
+		},
+		{
+			name:           "buy, quote fees",
+			baseQty:        1e8,
+			quoteQty:       5e6,
+			quoteFees:      1e4,
+			expBaseFilled:  1e8,
+			expQuoteFilled: 5e6 + 1e4,
+		},
+		{
+			name:           "sell, base fees",
+			sell:           true,
+			baseQty:        1e8,
+			quoteQty:       5e6,
+			baseFees:       1e5,
+			expBaseFilled:  1e8 + 1e5,
+			expQuoteFilled: 5e6,
+		},
+		{
+			name:           "buy, base fees",
+			baseQty:        1e8,
+			quoteQty:       5e6,
+			baseFees:       1e5,
+			expBaseFilled:  1e8 - 1e5,
+			expQuoteFilled: 5e6,
+		},
+	}
+
+	for _, tt := range tests {
+		baseFilled, quoteFilled := krakenFilled(tt.sell, tt.baseQty, tt.quoteQty, tt.baseFees, tt.quoteFees)
+		if baseFilled != tt.expBaseFilled || quoteFilled != tt.expQuoteFilled {
+			t.Fatalf("%s: expected base %d, quote %d, got base %d, quote %d", tt.name,
+				tt.expBaseFilled, tt.expQuoteFilled, baseFilled, quoteFilled)
+		}
+	}
+}
+
+func TestKrakenTransferMethod(t *testing.T) {
+	tests := []struct {
+		name      string
+		assetID   uint32
+		methods   []string
+		expMethod string
+		wantErr   bool
+	}{
+		{
+			name:      "native asset",
+			assetID:   60,
+			methods:   []string{"Ether (Hex)", "Ethereum (Arbitrum One)", "Ether"},
+			expMethod: "Ether",
+		},
+		{
+			name:      "native asset, only one method",
+			assetID:   0,
+			methods:   []string{"Bitcoin (Lightning)"},
+			expMethod: "Bitcoin (Lightning)",
+		},
+		{
+			name:      "token",
+			assetID:   966001,
+			methods:   []string{"USDC (ERC20)", "USDC (Polygon)", "USDC (Solana)"},
+			expMethod: "USDC (Polygon)",
+		},
+		{
+			name:    "token network not available",
+			assetID: 966001,
+			methods: []string{"USDC (ERC20)", "USDC (Solana)"},
+			wantErr: true,
+		},
+		{
+			name:    "no methods",
+			assetID: 0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		method, err := krakenTransferMethod(tt.assetID, tt.methods)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("%s: expected error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if method != tt.expMethod {
+			t.Fatalf("%s: expected method %s, got %s", tt.name, tt.expMethod, method)
+		}
+	}
+}
+
+func TestBuildKrakenOrderRequest(t *testing.T) {
+	btcUI, _ := asset.UnitInfo(0)
+	usdcUI, _ := asset.UnitInfo(966001)
+	btcAmt := func(amt float64) uint64 {
+		return uint64(math.Round(amt * float64(btcUI.Conventional.ConversionFactor)))
+	}
+	usdcAmt := func(amt float64) uint64 {
+		return uint64(math.Round(amt * float64(usdcUI.Conventional.ConversionFactor)))
+	}
+	msgRate := func(rate float64) uint64 {
+		return calc.MessageRate(rate, btcUI, usdcUI)
+	}
+
+	mkt, err := parseKrakenMarket("XBTUSDC", &krtypes.AssetPair{
+		WSName:       "XBT/USDC",
+		PairDecimals: 2,
+		LotDecimals:  8,
+		OrderMin:     0.0001,
+		CostMin:      0.5,
+		TickSize:     0.01,
+	}, &btcUI, &usdcUI)
+	if err != nil {
+		t.Fatalf("error parsing market: %v", err)
+	}
+	if mkt.symbol != "BTC/USDC" {
+		t.Fatalf("wrong symbol %s", mkt.symbol)
+	}
+
+	tests := []struct {
+		name         string
+		sell         bool
+		orderType    OrderType
+		rate         uint64
+		qty          uint64
+		quoteQty     uint64
+		marketBuyQty uint64
+		expForm      url.Values
+		wantQtyRet   uint64
+		wantErr      bool
+	}{
+		{
+			name:      "limit sell",
+			sell:      true,
+			orderType: OrderTypeLimit,
+			rate:      msgRate(50000.123),
+			qty:       btcAmt(0.5),
+			expForm: url.Values{
+				"pair":        []string{"XBTUSDC"},
+				"type":        []string{"sell"},
+				"oflags":      []string{"fciq"},
+				"ordertype":   []string{"limit"},
+				"volume":      []string{"0.50000000"},
+				"price":       []string{"50000.12"},
+				"timeinforce": []string{"GTC"},
+			},
+			wantQtyRet: btcAmt(0.5),
+		},
+		{
+			name:      "limit IOC buy with quote qty",
+			orderType: OrderTypeLimitIOC,
+			rate:      msgRate(50000),
+			quoteQty:  usdcAmt(5000),
+			expForm: url.Values{
+				"pair":        []string{"XBTUSDC"},
+				"type":        []string{"buy"},
+				"oflags":      []string{"fciq"},
+				"ordertype":   []string{"limit"},
+				"volume":      []string{"0.10000000"},
+				"price":       []string{"50000.00"},
+				"timeinforce": []string{"IOC"},
+			},
+			wantQtyRet: btcAmt(0.1),
+		},
+		{
+			name:      "market sell",
+			sell:      true,
+			orderType: OrderTypeMarket,
+			qty:       btcAmt(0.25),
+			expForm: url.Values{
+				"pair":      []string{"XBTUSDC"},
+				"type":      []string{"sell"},
+				"oflags":    []string{"fciq"},
+				"ordertype": []string{"market"},
+				"volume":    []string{"0.25000000"},
+			},
+			wantQtyRet: btcAmt(0.25),
+		},
+		{
+			name:         "market buy",
+			orderType:    OrderTypeMarket,
+			quoteQty:     usdcAmt(1000),
+			marketBuyQty: btcAmt(0.02),
+			expForm: url.Values{
+				"pair":      []string{"XBTUSDC"},
+				"type":      []string{"buy"},
+				"oflags":    []string{"fciq"},
+				"ordertype": []string{"market"},
+				"volume":    []string{"0.02000000"},
+			},
+			wantQtyRet: usdcAmt(1000),
+		},
+		{
+			name:       "market buy validation only",
+			orderType:  OrderTypeMarket,
+			quoteQty:   usdcAmt(1000),
+			wantQtyRet: usdcAmt(1000),
+		},
+		{
+			name:      "market buy with base qty",
+			orderType: OrderTypeMarket,
+			qty:       btcAmt(0.02),
+			wantErr:   true,
+		},
+		{
+			name:      "market buy below min cost",
+			orderType: OrderTypeMarket,
+			quoteQty:  usdcAmt(0.1),
+			wantErr:   true,
+		},
+		{
+			name:      "limit sell below min qty",
+			sell:      true,
+			orderType: OrderTypeLimit,
+			rate:      msgRate(50000),
+			qty:       btcAmt(0.00005),
+			wantErr:   true,
+		},
+		{
+			name:      "sell with quote qty",
+			sell:      true,
+			orderType: OrderTypeLimit,
+			rate:      msgRate(50000),
+			quoteQty:  usdcAmt(1000),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		form, qtyRet, err := buildKrakenOrderRequest(mkt, &btcUI, &usdcUI, tt.sell, tt.orderType, tt.rate, tt.qty, tt.quoteQty, tt.marketBuyQty)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("%s: expected error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if qtyRet != tt.wantQtyRet {
+			t.Fatalf("%s: expected qty %d, got %d", tt.name, tt.wantQtyRet, qtyRet)
+		}
+		if tt.expForm == nil {
+			if form != nil {
+				t.Fatalf("%s: expected no form, got %v", tt.name, form)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(form, tt.expForm) {
+			t.Fatalf("%s: expected form %v, got %v", tt.name, tt.expForm, form)
+		}
+	}
+}
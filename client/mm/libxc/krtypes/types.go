@@ -0,0 +1,202 @@
+package krtypes
+
+import "encoding/json"
+
+// Response is the envelope for every Kraken REST response. Errors are
+// reported as a list of strings, e.g. "EGeneral:Invalid arguments".
+type Response struct {
+	Error  []string        `json:"error"`
+	Result json.RawMessage `json:"result"`
+}
+
+type Asset struct {
+	AssetClass      string `json:"aclass"`
+	Altname         string `json:"altname"`
+	Decimals        int    `json:"decimals"`
+	DisplayDecimals int    `json:"display_decimals"`
+	Status          string `json:"status"`
+}
+
+type AssetPair struct {
+	Altname      string  `json:"altname"`
+	WSName       string  `json:"wsname"`
+	Base         string  `json:"base"`
+	Quote        string  `json:"quote"`
+	PairDecimals int     `json:"pair_decimals"`
+	CostDecimals int     `json:"cost_decimals"`
+	LotDecimals  int     `json:"lot_decimals"`
+	OrderMin     float64 `json:"ordermin,string"`
+	CostMin      float64 `json:"costmin,string"`
+	TickSize     float64 `json:"tick_size,string"`
+	Status       string  `json:"status"`
+}
+
+// Ticker is the 24-hour summary for a pair. The two-element arrays hold
+// the value for today in the first position and for the last 24 hours in
+// the second.
+type Ticker struct {
+	Ask       []string  `json:"a"`
+	Bid       []string  `json:"b"`
+	LastTrade []string  `json:"c"`
+	Volume    [2]string `json:"v"`
+	VWAP      [2]string `json:"p"`
+	Low       [2]string `json:"l"`
+	High      [2]string `json:"h"`
+	Open      string    `json:"o"`
+}
+
+type Balance struct {
+	Balance   float64 `json:"balance,string"`
+	HoldTrade float64 `json:"hold_trade,string"`
+}
+
+type AddOrderResult struct {
+	Description struct {
+		Order string `json:"order"`
+	} `json:"descr"`
+	TxIDs []string `json:"txid"`
+}
+
+type CancelOrderResult struct {
+	Count int `json:"count"`
+}
+
+type OrderDescription struct {
+	Pair      string  `json:"pair"`
+	Type      string  `json:"type"`      // "buy" or "sell"
+	OrderType string  `json:"ordertype"` // "limit", "market", ...
+	Price     float64 `json:"price,string"`
+}
+
+// Order is an order as returned by the QueryOrders endpoint.
+type Order struct {
+	ClientOrderID string           `json:"cl_ord_id"`
+	Status        string           `json:"status"` // pending, open, closed, canceled, expired
+	Description   OrderDescription `json:"descr"`
+	Volume        float64          `json:"vol,string"`
+	VolumeExec    float64          `json:"vol_exec,string"`
+	Cost          float64          `json:"cost,string"`
+	Fee           float64          `json:"fee,string"`
+	Price         float64          `json:"price,string"`
+	OrderFlags    string           `json:"oflags"`
+}
+
+type DepositMethod struct {
+	Method     string `json:"method"`
+	GenAddress bool   `json:"gen-address"`
+}
+
+type DepositAddress struct {
+	Address string `json:"address"`
+	New     bool   `json:"new"`
+}
+
+// Transfer is a deposit or withdrawal as returned by the DepositStatus and
+// WithdrawStatus endpoints.
+type Transfer struct {
+	Method string  `json:"method"`
+	Asset  string  `json:"asset"`
+	RefID  string  `json:"refid"`
+	TxID   string  `json:"txid"`
+	Info   string  `json:"info"`
+	Amount float64 `json:"amount,string"`
+	Fee    float64 `json:"fee,string"`
+	Time   int64   `json:"time"`
+	Status string  `json:"status"` // Initial, Pending, Settled, Success, Failure
+}
+
+const (
+	TransferStatusSuccess = "Success"
+	TransferStatusFailure = "Failure"
+)
+
+type WithdrawAddress struct {
+	Address  string `json:"address"`
+	Asset    string `json:"asset"`
+	Method   string `json:"method"`
+	Key      string `json:"key"`
+	Verified bool   `json:"verified"`
+}
+
+type WithdrawInfo struct {
+	Method string  `json:"method"`
+	Limit  float64 `json:"limit,string"`
+	Amount float64 `json:"amount,string"`
+	Fee    float64 `json:"fee,string"`
+}
+
+type WithdrawResult struct {
+	RefID string `json:"refid"`
+}
+
+type WebsocketToken struct {
+	Token   string `json:"token"`
+	Expires int64  `json:"expires"`
+}
+
+// WSRequest is a websocket v2 request.
+type WSRequest struct {
+	Method string `json:"method"`
+	Params any    `json:"params,omitempty"`
+	ReqID  uint64 `json:"req_id,omitempty"`
+}
+
+type BookSubscription struct {
+	Channel  string   `json:"channel"`
+	Symbol   []string `json:"symbol"`
+	Depth    int      `json:"depth"`
+	Snapshot bool     `json:"snapshot"`
+}
+
+type UserSubscription struct {
+	Channel    string `json:"channel"`
+	Token      string `json:"token"`
+	SnapOrders *bool  `json:"snap_orders,omitempty"`
+	SnapTrades *bool  `json:"snap_trades,omitempty"`
+	Snapshot   *bool  `json:"snapshot,omitempty"`
+}
+
+// WSMessage is the general form of a websocket v2 message. Method responses
+// have Method set, while channel data has Channel set.
+type WSMessage struct {
+	Method  string          `json:"method"`
+	Success bool            `json:"success"`
+	Error   string          `json:"error"`
+	Channel string          `json:"channel"`
+	Type    string          `json:"type"` // "snapshot" or "update"
+	Data    json.RawMessage `json:"data"`
+}
+
+type BookLevel struct {
+	Price float64 `json:"price"`
+	Qty   float64 `json:"qty"`
+}
+
+type BookData struct {
+	Symbol   string       `json:"symbol"`
+	Bids     []*BookLevel `json:"bids"`
+	Asks     []*BookLevel `json:"asks"`
+	Checksum uint32       `json:"checksum"`
+}
+
+type ExecutionFee struct {
+	Asset string  `json:"asset"`
+	Qty   float64 `json:"qty"`
+}
+
+type Execution struct {
+	ExecType      string          `json:"exec_type"`
+	OrderID       string          `json:"order_id"`
+	ClientOrderID string          `json:"cl_ord_id"`
+	OrderStatus   string          `json:"order_status"` // pending_new, new, partially_filled, filled, canceled, expired
+	Symbol        string          `json:"symbol"`
+	Side          string          `json:"side"`
+	CumQty        float64         `json:"cum_qty"`
+	CumCost       float64         `json:"cum_cost"`
+	Fees          []*ExecutionFee `json:"fees"`
+}
+
+type BalanceUpdate struct {
+	Asset   string  `json:"asset"`
+	Balance float64 `json:"balance"`
+}
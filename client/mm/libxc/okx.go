@@ -0,0 +1,1516 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package libxc
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/comms"
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/mm/libxc/okxtypes"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/dexnet"
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/utils"
+)
+
+// OKX API docs: https://www.okx.com/docs-v5/en/
+
+const (
+	okxHttpURL          = "https://www.okx.com"
+	okxPublicWsURL      = "wss://ws.okx.com:8443/ws/v5/public"
+	okxPrivateWsURL     = "wss://ws.okx.com:8443/ws/v5/private"
+	okxDemoPublicWsURL  = "wss://wspap.okx.com:8443/ws/v5/public"
+	okxDemoPrivateWsURL = "wss://wspap.okx.com:8443/ws/v5/private"
+	fakeOKXURL          = "http://localhost:37348"
+	fakeOKXPublicWsURL  = "ws://localhost:37348/ws/v5/public"
+	fakeOKXPrivateWsURL = "ws://localhost:37348/ws/v5/private"
+
+	// OKX closes connections that have been idle for 30 seconds.
+	okxPingInterval = time.Second * 20
+)
+
+// supportedOKXTokens is the set of tokens that can be deposited to or
+// withdrawn from OKX, mapped to the substring that identifies the token's
+// network in OKX's chain names, e.g. USDT-ERC20.
+var supportedOKXTokens = map[uint32]string{
+	60001:  "ERC20",   // USDC on ETH
+	60002:  "ERC20",   // USDT on ETH
+	966001: "Polygon", // USDC on POLYGON
+	966002: "Polygon", // USDT on POLYGON
+}
+
+// okxMarket is an OKX spot instrument with the trading limits converted to
+// DEX atomic units.
+type okxMarket struct {
+	*okxtypes.Instrument
+	rateStep     uint64
+	lotSize      uint64
+	minQty       uint64
+	ratePrec     int
+	qtyPrec      int
+	quoteQtyPrec int
+}
+
+// okxPrecision is the number of decimal places needed to represent a
+// multiple of the increment.
+func okxPrecision(increment float64) int {
+	if increment <= 0 || increment >= 1 {
+		return 0
+	}
+	return int(math.Round(-math.Log10(increment)))
+}
+
+func parseOKXMarket(inst *okxtypes.Instrument, bui, qui *dex.UnitInfo) *okxMarket {
+	lotSize := toAtomic(inst.LotSz, bui)
+	if lotSize == 0 {
+		lotSize = 1
+	}
+	return &okxMarket{
+		Instrument:   inst,
+		rateStep:     messageRate(inst.TickSz, bui, qui),
+		lotSize:      lotSize,
+		minQty:       toAtomic(inst.MinSz, bui),
+		ratePrec:     okxPrecision(inst.TickSz),
+		qtyPrec:      okxPrecision(inst.LotSz),
+		quoteQtyPrec: int(math.Round(math.Log10(float64(qui.Conventional.ConversionFactor)))),
+	}
+}
+
+// okxSign generates the signature for a REST request or websocket login.
+func okxSign(timestamp, method, requestPath, body, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + method + requestPath + body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// okxWSConn is a websocket connection to one of the OKX websocket endpoints.
+// OKX does not send heartbeats, so a "ping" is sent periodically to keep the
+// connection alive.
+type okxWSConn struct {
+	wsConn     comms.WsConn
+	url        string
+	log        dex.Logger
+	msgHandler func(*okxtypes.WSMessage)
+	// onConnect is called on the initial connection and every reconnection,
+	// and should (re)establish any subscriptions.
+	onConnect    func() error
+	onDisconnect func()
+}
+
+func (c *okxWSConn) send(op string, args ...any) error {
+	reqB, err := json.Marshal(&okxtypes.WSRequest{Op: op, Args: args})
+	if err != nil {
+		return err
+	}
+	return c.wsConn.SendRaw(reqB)
+}
+
+func (c *okxWSConn) handleWebsocketMessage(b []byte) {
+	if string(b) == "pong" {
+		return
+	}
+	var msg okxtypes.WSMessage
+	if err := json.Unmarshal(b, &msg); err != nil {
+		c.log.Errorf("Error unmarshaling websocket message: %v", err)
+		c.log.Errorf("Raw Message: %s", string(b))
+		return
+	}
+	if msg.Event == "error" {
+		c.log.Errorf("Websocket error %s: %s", msg.Code, msg.Msg)
+		return
+	}
+	c.msgHandler(&msg)
+}
+
+func (c *okxWSConn) Connect(ctx context.Context) (*sync.WaitGroup, error) {
+	initialConnect := true
+	conn, err := comms.NewWsConn(&comms.WsCfg{
+		URL:           c.url,
+		PingWait:      time.Minute,
+		ReconnectSync: func() {},
+		ConnectEventFunc: func(cs comms.ConnectionStatus) {
+			if cs != comms.Connected && cs != comms.Disconnected {
+				return
+			}
+			if cs == comms.Connected && initialConnect {
+				initialConnect = false
+			} else if cs == comms.Connected {
+				if err := c.onConnect(); err != nil {
+					c.log.Errorf("Error resubscribing after reconnect: %v", err)
+				}
+			} else if c.onDisconnect != nil {
+				c.onDisconnect()
+			}
+		},
+		Logger:     c.log,
+		RawHandler: c.handleWebsocketMessage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating WsConn: %w", err)
+	}
+
+	cm := dex.NewConnectionMaster(conn)
+	if err := cm.ConnectOnce(ctx); err != nil {
+		return nil, fmt.Errorf("error connecting to websocket feed: %w", err)
+	}
+	c.wsConn = conn
+
+	if err := c.onConnect(); err != nil {
+		cm.Disconnect()
+		return nil, err
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(okxPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if !conn.IsDown() {
+					if err := conn.SendRaw([]byte("ping")); err != nil {
+						c.log.Debugf("Error sending ping: %v", err)
+					}
+				}
+			case <-ctx.Done():
+				cm.Disconnect()
+				return
+			}
+		}
+	}()
+
+	return &wg, nil
+}
+
+// okxBook manages an orderbook for a single OKX market. Each update carries
+// the sequence ID of the previous update, and the book is re-requested if an
+// update is missed.
+type okxBook struct {
+	mtx            sync.RWMutex
+	numSubscribers uint32
+	cm             *dex.ConnectionMaster
+
+	conn   *okxWSConn
+	instID string
+	synced atomic.Bool
+	seq    atomic.Int64
+	book   *orderbook
+	bui    *dex.UnitInfo
+	qui    *dex.UnitInfo
+	log    dex.Logger
+}
+
+func newOKXBook(wsURL, instID string, bui, qui *dex.UnitInfo, log dex.Logger) *okxBook {
+	b := &okxBook{
+		instID: instID,
+		book:   newOrderBook(),
+		bui:    bui,
+		qui:    qui,
+		log:    log.SubLogger("WS-book-" + instID),
+	}
+	b.conn = &okxWSConn{
+		url:          wsURL,
+		log:          b.log,
+		msgHandler:   b.handleMessage,
+		onConnect:    func() error { return b.subUnsub(true) },
+		onDisconnect: func() { b.synced.Store(false) },
+	}
+	return b
+}
+
+func (b *okxBook) subUnsub(sub bool) error {
+	op := "subscribe"
+	if !sub {
+		op = "unsubscribe"
+	}
+	return b.conn.send(op, &okxtypes.WSArg{Channel: "books", InstID: b.instID})
+}
+
+func (b *okxBook) convertLevels(levels [][]json.Number) ([]*obEntry, error) {
+	entries := make([]*obEntry, 0, len(levels))
+	for _, l := range levels {
+		if len(l) < 2 {
+			return nil, fmt.Errorf("invalid book level %v", l)
+		}
+		price, err := l[0].Float64()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing price: %w", err)
+		}
+		qty, err := l[1].Float64()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing quantity: %w", err)
+		}
+		entries = append(entries, &obEntry{
+			qty:  toAtomic(qty, b.bui),
+			rate: messageRate(price, b.bui, b.qui),
+		})
+	}
+	return entries, nil
+}
+
+func (b *okxBook) resubscribe() {
+	b.synced.Store(false)
+	if err := b.subUnsub(false); err != nil {
+		b.log.Errorf("Error unsubscribing: %v", err)
+	}
+	if err := b.subUnsub(true); err != nil {
+		b.log.Errorf("Error resubscribing: %v", err)
+	}
+}
+
+func (b *okxBook) handleMessage(msg *okxtypes.WSMessage) {
+	if msg.Event != "" || msg.Arg == nil || msg.Arg.Channel != "books" || msg.Arg.InstID != b.instID {
+		return
+	}
+
+	var data []*okxtypes.BookData
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		b.log.Errorf("Error unmarshaling book data: %v", err)
+		return
+	}
+
+	for _, d := range data {
+		if msg.Action == "snapshot" {
+			b.book.clear()
+		} else {
+			if !b.synced.Load() {
+				continue
+			}
+			if lastSeq := b.seq.Load(); d.PrevSeqID != lastSeq {
+				b.log.Errorf("Book update out of sequence. %d -> %d. Resubscribing.", lastSeq, d.PrevSeqID)
+				b.resubscribe()
+				return
+			}
+		}
+		b.seq.Store(d.SeqID)
+
+		bids, err := b.convertLevels(d.Bids)
+		if err != nil {
+			b.log.Errorf("Error parsing bids: %v", err)
+			b.resubscribe()
+			return
+		}
+		asks, err := b.convertLevels(d.Asks)
+		if err != nil {
+			b.log.Errorf("Error parsing asks: %v", err)
+			b.resubscribe()
+			return
+		}
+		b.book.update(bids, asks)
+
+		if msg.Action == "snapshot" {
+			b.log.Infof("Book synced")
+			b.synced.Store(true)
+		}
+	}
+}
+
+func (b *okxBook) Connect(ctx context.Context) (*sync.WaitGroup, error) {
+	return b.conn.Connect(ctx)
+}
+
+func (b *okxBook) sync(ctx context.Context) error {
+	cm := dex.NewConnectionMaster(b)
+	b.mtx.Lock()
+	b.cm = cm
+	b.numSubscribers++
+	b.mtx.Unlock()
+	return cm.ConnectOnce(ctx)
+}
+
+func (b *okxBook) midGap() (uint64, error) {
+	if !b.synced.Load() {
+		return 0, ErrUnsyncedOrderbook
+	}
+	return b.book.midGap(), nil
+}
+
+func (b *okxBook) vwap(bids bool, qty uint64) (vwap, extrema uint64, filled bool, err error) {
+	if !b.synced.Load() {
+		return 0, 0, false, ErrUnsyncedOrderbook
+	}
+	vwap, extrema, filled = b.book.vwap(bids, qty)
+	return
+}
+
+func (b *okxBook) invVWAP(bids bool, qty uint64) (vwap, extrema uint64, filled bool, err error) {
+	if !b.synced.Load() {
+		return 0, 0, false, ErrUnsyncedOrderbook
+	}
+	vwap, extrema, filled = b.book.invVWAP(bids, qty)
+	return
+}
+
+// okx implements CEX for OKX. Funds are traded from the OKX trading account,
+// but deposits and withdrawals go through the funding account, so deposits
+// are transferred to the trading account when they are confirmed, and funds
+// are transferred to the funding account before they are withdrawn.
+type okx struct {
+	log          dex.Logger
+	net          dex.Network
+	httpURL      string
+	publicWsURL  string
+	privateWsURL string
+	apiKey       string
+	secretKey    string
+	passphrase   string
+	demo         bool
+	broadcast    func(interface{})
+	ctx          context.Context
+
+	tickerIDs map[string][]uint32
+	idTicker  map[uint32]string
+
+	markets    atomic.Value // map[string]*okxMarket, instID -> market
+	currencies atomic.Value // map[string][]*okxtypes.Currency, ccy -> chains
+
+	marketSnapshotMtx sync.RWMutex
+	marketSnapshot    struct {
+		stamp time.Time
+		m     map[string]*Market
+	}
+
+	balanceMtx sync.RWMutex
+	balances   map[uint32]*ExchangeBalance
+
+	// subMarketMtx must be held while subscribing or unsubscribing to a
+	// market.
+	subMarketMtx sync.Mutex
+
+	booksMtx sync.RWMutex
+	books    map[string]*okxBook
+
+	tradeUpdaterMtx    sync.RWMutex
+	tradeInfo          map[string]*tradeInfo
+	tradeUpdaters      map[int]chan *Trade
+	tradeUpdateCounter int
+
+	tradeIDNonce       atomic.Uint32
+	tradeIDNoncePrefix dex.Bytes
+
+	userConn *okxWSConn
+}
+
+var _ CEX = (*okx)(nil)
+
+func newOKX(cfg *CEXConfig) (*okx, error) {
+	o := &okx{
+		log:        cfg.Logger,
+		net:        cfg.Net,
+		apiKey:     cfg.APIKey,
+		secretKey:  cfg.SecretKey,
+		passphrase: cfg.APIPassphrase,
+		broadcast:  cfg.Notify,
+		tickerIDs:  make(map[string][]uint32),
+		idTicker:   make(map[uint32]string),
+		balances:   make(map[uint32]*ExchangeBalance),
+		books:      make(map[string]*okxBook),
+		tradeInfo:  make(map[string]*tradeInfo),
+		// Client order IDs are limited to 32 alphanumeric characters.
+		tradeIDNoncePrefix: encode.RandomBytes(12),
+		tradeUpdaters:      make(map[int]chan *Trade),
+	}
+
+	switch cfg.Net {
+	case dex.Mainnet:
+		o.httpURL, o.publicWsURL, o.privateWsURL = okxHttpURL, okxPublicWsURL, okxPrivateWsURL
+	case dex.Testnet:
+		// OKX's demo trading environment.
+		o.httpURL, o.publicWsURL, o.privateWsURL = okxHttpURL, okxDemoPublicWsURL, okxDemoPrivateWsURL
+		o.demo = true
+	default:
+		o.httpURL, o.publicWsURL, o.privateWsURL = fakeOKXURL, fakeOKXPublicWsURL, fakeOKXPrivateWsURL
+	}
+
+	addTicker := func(assetID uint32, ticker string) {
+		o.tickerIDs[ticker] = append(o.tickerIDs[ticker], assetID)
+		o.idTicker[assetID] = ticker
+	}
+	for _, a := range asset.Assets() {
+		addTicker(a.ID, a.Info.UnitInfo.Conventional.Unit)
+		for tokenID, tkn := range a.Tokens {
+			if _, supported := supportedOKXTokens[tokenID]; supported {
+				addTicker(tokenID, tkn.UnitInfo.Conventional.Unit)
+			}
+		}
+	}
+
+	o.markets.Store(make(map[string]*okxMarket))
+	o.currencies.Store(make(map[string][]*okxtypes.Currency))
+
+	o.userConn = &okxWSConn{
+		url:        o.privateWsURL,
+		log:        o.log.SubLogger("WS-user"),
+		msgHandler: o.handleUserMessage,
+		onConnect:  o.login,
+	}
+
+	return o, nil
+}
+
+func (o *okx) request(ctx context.Context, method, endpoint string, query url.Values, body, res any) error {
+	var bodyB []byte
+	if body != nil {
+		var err error
+		if bodyB, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("error marshaling request: %w", err)
+		}
+	}
+
+	requestPath := endpoint
+	if len(query) > 0 {
+		requestPath += "?" + query.Encode()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, o.httpURL+requestPath, bytes.NewReader(bodyB))
+	if err != nil {
+		return fmt.Errorf("error generating http request: %w", err)
+	}
+
+	timestamp := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("OK-ACCESS-KEY", o.apiKey)
+	req.Header.Set("OK-ACCESS-SIGN", okxSign(timestamp, method, requestPath, string(bodyB), o.secretKey))
+	req.Header.Set("OK-ACCESS-TIMESTAMP", timestamp)
+	req.Header.Set("OK-ACCESS-PASSPHRASE", o.passphrase)
+	if o.demo {
+		req.Header.Set("x-simulated-trading", "1")
+	}
+
+	var errCode int
+	var resp okxtypes.Response
+	var errResp okxtypes.Response
+	if err := dexnet.Do(req, &resp, dexnet.WithStatusFunc(func(code int) { errCode = code }), dexnet.WithErrorParsing(&errResp)); err != nil {
+		if errCode == http.StatusUnauthorized {
+			return fmt.Errorf("authentication failure: %s", errResp.Msg)
+		}
+		return fmt.Errorf("error response (%d): code = %s, msg = %q", errCode, errResp.Code, errResp.Msg)
+	}
+	if resp.Code != "0" {
+		// Order endpoints report the reason in the data.
+		var results []*okxtypes.OrderResult
+		if json.Unmarshal(resp.Data, &results) == nil && len(results) > 0 && results[0].SCode != "" && results[0].SCode != "0" {
+			return fmt.Errorf("okx error %s: %s", results[0].SCode, results[0].SMsg)
+		}
+		return fmt.Errorf("okx error %s: %s", resp.Code, resp.Msg)
+	}
+	if res == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Data, res)
+}
+
+func (o *okx) updateCurrencies(ctx context.Context) error {
+	var res []*okxtypes.Currency
+	if err := o.request(ctx, http.MethodGet, "/api/v5/asset/currencies", nil, nil, &res); err != nil {
+		return err
+	}
+	currencies := make(map[string][]*okxtypes.Currency)
+	for _, c := range res {
+		if _, supported := o.tickerIDs[c.Ccy]; supported {
+			currencies[c.Ccy] = append(currencies[c.Ccy], c)
+		}
+	}
+	o.currencies.Store(currencies)
+	return nil
+}
+
+// currency returns the OKX currency and chain for the DEX asset ID.
+func (o *okx) currency(assetID uint32) (*okxtypes.Currency, error) {
+	ticker, found := o.idTicker[assetID]
+	if !found {
+		return nil, fmt.Errorf("no ticker found for asset ID %d", assetID)
+	}
+	chains := o.currencies.Load().(map[string][]*okxtypes.Currency)[ticker]
+	if len(chains) == 0 {
+		return nil, fmt.Errorf("%s is not supported by okx", ticker)
+	}
+	if network, isToken := supportedOKXTokens[assetID]; isToken {
+		for _, c := range chains {
+			if strings.Contains(c.Chain, network) {
+				return c, nil
+			}
+		}
+		return nil, fmt.Errorf("no %s chain found for %s", network, ticker)
+	}
+	for _, c := range chains {
+		if c.MainNet {
+			return c, nil
+		}
+	}
+	return chains[0], nil
+}
+
+func (o *okx) okxMarketToDexMarkets(baseTicker, quoteTicker string) [][2]uint32 {
+	baseIDs := o.tickerIDs[baseTicker]
+	quoteIDs := o.tickerIDs[quoteTicker]
+	markets := make([][2]uint32, 0, len(baseIDs)*len(quoteIDs))
+	for _, baseID := range baseIDs {
+		for _, quoteID := range quoteIDs {
+			markets = append(markets, [2]uint32{baseID, quoteID})
+		}
+	}
+	return markets
+}
+
+func (o *okx) updateMarkets(ctx context.Context) (map[string]*Market, error) {
+	q := url.Values{"instType": []string{"SPOT"}}
+	var insts []*okxtypes.Instrument
+	if err := o.request(ctx, http.MethodGet, "/api/v5/public/instruments", q, nil, &insts); err != nil {
+		return nil, fmt.Errorf("error fetching instruments: %w", err)
+	}
+	var tickers []*okxtypes.Ticker
+	if err := o.request(ctx, http.MethodGet, "/api/v5/market/tickers", q, nil, &tickers); err != nil {
+		return nil, fmt.Errorf("error fetching tickers: %w", err)
+	}
+	tickerMap := make(map[string]*okxtypes.Ticker, len(tickers))
+	for _, t := range tickers {
+		tickerMap[t.InstID] = t
+	}
+
+	markets := make(map[string]*Market)
+	okxMarkets := make(map[string]*okxMarket)
+	for _, inst := range insts {
+		if inst.State != "live" {
+			continue
+		}
+		dexMarkets := o.okxMarketToDexMarkets(inst.BaseCcy, inst.QuoteCcy)
+		if len(dexMarkets) == 0 {
+			continue
+		}
+		bui, err := asset.UnitInfo(dexMarkets[0][0])
+		if err != nil {
+			continue
+		}
+		qui, err := asset.UnitInfo(dexMarkets[0][1])
+		if err != nil {
+			continue
+		}
+		okxMarkets[inst.InstID] = parseOKXMarket(inst, &bui, &qui)
+
+		var day *MarketDay
+		if t := tickerMap[inst.InstID]; t != nil {
+			day = &MarketDay{
+				Vol:         t.Vol24h,
+				QuoteVol:    t.VolCcy24h,
+				PriceChange: t.Last - t.Open24h,
+				LastPrice:   t.Last,
+				OpenPrice:   t.Open24h,
+				HighPrice:   t.High24h,
+				LowPrice:    t.Low24h,
+			}
+			if t.Vol24h > 0 {
+				day.AvgPrice = t.VolCcy24h / t.Vol24h
+			}
+			if t.Open24h > 0 {
+				day.PriceChangePct = (t.Last - t.Open24h) / t.Open24h * 100
+			}
+		}
+
+		for _, m := range dexMarkets {
+			dexMarketSlug := dex.BipIDSymbol(m[0]) + "_" + dex.BipIDSymbol(m[1])
+			mkt := &Market{
+				BaseID:  m[0],
+				QuoteID: m[1],
+				Day:     day,
+			}
+			if c, err := o.currency(m[0]); err == nil {
+				mkt.BaseMinWithdraw = toAtomic(c.MinWd, &bui)
+			}
+			if c, err := o.currency(m[1]); err == nil {
+				mkt.QuoteMinWithdraw = toAtomic(c.MinWd, &qui)
+			}
+			markets[dexMarketSlug] = mkt
+		}
+	}
+
+	o.markets.Store(okxMarkets)
+
+	o.marketSnapshotMtx.Lock()
+	defer o.marketSnapshotMtx.Unlock()
+	o.marketSnapshot.m = markets
+	o.marketSnapshot.stamp = time.Now()
+	return markets, nil
+}
+
+func (o *okx) instID(baseID, quoteID uint32) (string, error) {
+	baseTicker, found := o.idTicker[baseID]
+	if !found {
+		return "", fmt.Errorf("ticker not found for base asset ID %d", baseID)
+	}
+	quoteTicker, found := o.idTicker[quoteID]
+	if !found {
+		return "", fmt.Errorf("ticker not found for quote asset ID %d", quoteID)
+	}
+	return baseTicker + "-" + quoteTicker, nil
+}
+
+func (o *okx) market(baseID, quoteID uint32) (*okxMarket, error) {
+	instID, err := o.instID(baseID, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	mkt, found := o.markets.Load().(map[string]*okxMarket)[instID]
+	if !found {
+		return nil, fmt.Errorf("no market found for %s", instID)
+	}
+	return mkt, nil
+}
+
+// updateBalances updates the balances of the trading account, and
+// broadcasts any changes.
+func (o *okx) updateBalances(details []*okxtypes.BalanceDetail) {
+	o.balanceMtx.Lock()
+	defer o.balanceMtx.Unlock()
+	for _, d := range details {
+		for _, assetID := range o.tickerIDs[d.Ccy] {
+			ui, err := asset.UnitInfo(assetID)
+			if err != nil {
+				o.log.Errorf("Error getting unit info for asset ID %d: %v", assetID, err)
+				continue
+			}
+			updated := &ExchangeBalance{
+				Available: toAtomic(d.AvailBal, &ui),
+				Locked:    toAtomic(d.FrozenBal, &ui),
+			}
+			old, found := o.balances[assetID]
+			o.balances[assetID] = updated
+			if found && *old != *updated && o.broadcast != nil {
+				o.broadcast(&BalanceUpdate{
+					AssetID: assetID,
+					Balance: updated,
+				})
+			}
+		}
+	}
+}
+
+func (o *okx) refreshBalances(ctx context.Context) error {
+	var res []*okxtypes.AccountBalance
+	if err := o.request(ctx, http.MethodGet, "/api/v5/account/balance", nil, nil, &res); err != nil {
+		return err
+	}
+	for _, acct := range res {
+		o.updateBalances(acct.Details)
+	}
+	return nil
+}
+
+func (o *okx) login() error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	return o.userConn.send("login", &okxtypes.LoginArg{
+		APIKey:     o.apiKey,
+		Passphrase: o.passphrase,
+		Timestamp:  timestamp,
+		Sign:       okxSign(timestamp, http.MethodGet, "/users/self/verify", "", o.secretKey),
+	})
+}
+
+func (o *okx) handleUserMessage(msg *okxtypes.WSMessage) {
+	switch msg.Event {
+	case "login":
+		if msg.Code != "0" {
+			o.log.Errorf("Websocket login error %s: %s", msg.Code, msg.Msg)
+			return
+		}
+		// Subscriptions are only accepted after login.
+		err := o.userConn.send("subscribe",
+			&okxtypes.WSArg{Channel: "orders", InstType: "SPOT"},
+			&okxtypes.WSArg{Channel: "account"},
+		)
+		if err != nil {
+			o.log.Errorf("Error subscribing to user channels: %v", err)
+		}
+		return
+	case "":
+	default:
+		return
+	}
+
+	if msg.Arg == nil {
+		return
+	}
+
+	switch msg.Arg.Channel {
+	case "orders":
+		var ords []*okxtypes.Order
+		if err := json.Unmarshal(msg.Data, &ords); err != nil {
+			o.log.Errorf("Error unmarshaling orders: %v", err)
+			return
+		}
+		for _, ord := range ords {
+			o.handleOrderUpdate(ord)
+		}
+	case "account":
+		var accts []*okxtypes.AccountBalance
+		if err := json.Unmarshal(msg.Data, &accts); err != nil {
+			o.log.Errorf("Error unmarshaling account update: %v", err)
+			return
+		}
+		for _, acct := range accts {
+			o.updateBalances(acct.Details)
+		}
+	}
+}
+
+// okxFilled calculates the net amounts that were taken from and added to the
+// user's balances by an order. OKX reports fees as negative amounts.
+func okxFilled(ord *okxtypes.Order, baseTicker, quoteTicker string, bui, qui *dex.UnitInfo) (baseFilled, quoteFilled uint64) {
+	baseQty := toAtomic(ord.AccFillSz, bui)
+	quoteQty := toAtomic(ord.AccFillSz*parseFloat(ord.AvgPx), qui)
+	var baseFees, quoteFees uint64
+	if ord.Fee < 0 {
+		switch ord.FeeCcy {
+		case baseTicker:
+			baseFees = toAtomic(-ord.Fee, bui)
+		case quoteTicker:
+			quoteFees = toAtomic(-ord.Fee, qui)
+		}
+	}
+	if ord.Side == "sell" {
+		return baseQty + baseFees, utils.SafeSub(quoteQty, quoteFees)
+	}
+	return utils.SafeSub(baseQty, baseFees), quoteQty + quoteFees
+}
+
+func okxOrderComplete(state string) bool {
+	return state != "live" && state != "partially_filled"
+}
+
+func (o *okx) handleOrderUpdate(ord *okxtypes.Order) {
+	o.tradeUpdaterMtx.Lock()
+	defer o.tradeUpdaterMtx.Unlock()
+
+	info, found := o.tradeInfo[ord.OrdID]
+	if !found {
+		o.log.Tracef("Update for unknown order %s", ord.OrdID)
+		return
+	}
+
+	updater, found := o.tradeUpdaters[info.updaterID]
+	if !found {
+		o.log.Errorf("No trade updater found for order ID %s", ord.OrdID)
+		return
+	}
+
+	bui, err := asset.UnitInfo(info.baseID)
+	if err != nil {
+		o.log.Errorf("Error getting unit info for asset ID %d: %v", info.baseID, err)
+		return
+	}
+	qui, err := asset.UnitInfo(info.quoteID)
+	if err != nil {
+		o.log.Errorf("Error getting unit info for asset ID %d: %v", info.quoteID, err)
+		return
+	}
+
+	complete := okxOrderComplete(ord.State)
+	baseFilled, quoteFilled := okxFilled(ord, o.idTicker[info.baseID], o.idTicker[info.quoteID], &bui, &qui)
+
+	updater <- &Trade{
+		ID:          ord.OrdID,
+		Sell:        info.sell,
+		Qty:         info.qty,
+		Rate:        info.rate,
+		Market:      info.market,
+		BaseID:      info.baseID,
+		QuoteID:     info.quoteID,
+		BaseFilled:  baseFilled,
+		QuoteFilled: quoteFilled,
+		Complete:    complete,
+	}
+
+	if complete {
+		delete(o.tradeInfo, ord.OrdID)
+	}
+}
+
+func (o *okx) Connect(ctx context.Context) (*sync.WaitGroup, error) {
+	o.ctx = ctx
+
+	if err := o.updateCurrencies(ctx); err != nil {
+		return nil, fmt.Errorf("error fetching currencies: %w", err)
+	}
+
+	if _, err := o.updateMarkets(ctx); err != nil {
+		return nil, fmt.Errorf("error fetching markets: %w", err)
+	}
+
+	if err := o.refreshBalances(ctx); err != nil {
+		return nil, fmt.Errorf("error fetching balances: %w", err)
+	}
+
+	wg, err := o.userConn.Connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to user stream: %w", err)
+	}
+
+	// Update currencies / markets every 10 minutes. These shouldn't change
+	// often.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Minute * 10)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := o.updateCurrencies(ctx); err != nil {
+					o.log.Errorf("Error fetching currencies: %v", err)
+				}
+				if _, err := o.updateMarkets(ctx); err != nil {
+					o.log.Errorf("Error fetching markets: %v", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		o.booksMtx.RLock()
+		defer o.booksMtx.RUnlock()
+		for _, book := range o.books {
+			book.cm.Disconnect()
+		}
+	}()
+
+	return wg, nil
+}
+
+// Balance returns the balance of an asset at the CEX.
+func (o *okx) Balance(assetID uint32) (*ExchangeBalance, error) {
+	o.balanceMtx.RLock()
+	defer o.balanceMtx.RUnlock()
+	bal, found := o.balances[assetID]
+	if !found {
+		// OKX omits currencies with a zero balance.
+		if _, found := o.idTicker[assetID]; found {
+			return &ExchangeBalance{}, nil
+		}
+		return nil, fmt.Errorf("no %s balance found", dex.BipIDSymbol(assetID))
+	}
+	return bal, nil
+}
+
+// Balances returns the balances of known assets on the CEX.
+func (o *okx) Balances(ctx context.Context) (map[uint32]*ExchangeBalance, error) {
+	o.balanceMtx.RLock()
+	if len(o.balances) > 0 {
+		defer o.balanceMtx.RUnlock()
+		return utils.CopyMap(o.balances), nil
+	}
+	o.balanceMtx.RUnlock()
+
+	if err := o.refreshBalances(ctx); err != nil {
+		return nil, err
+	}
+
+	o.balanceMtx.RLock()
+	defer o.balanceMtx.RUnlock()
+	return utils.CopyMap(o.balances), nil
+}
+
+// Markets returns the list of markets at the CEX.
+func (o *okx) Markets(ctx context.Context) (map[string]*Market, error) {
+	o.marketSnapshotMtx.RLock()
+	const snapshotTimeout = time.Minute * 30
+	if o.marketSnapshot.m != nil && time.Since(o.marketSnapshot.stamp) < snapshotTimeout {
+		defer o.marketSnapshotMtx.RUnlock()
+		return o.marketSnapshot.m, nil
+	}
+	o.marketSnapshotMtx.RUnlock()
+
+	if len(o.currencies.Load().(map[string][]*okxtypes.Currency)) == 0 {
+		if err := o.updateCurrencies(ctx); err != nil {
+			return nil, fmt.Errorf("error fetching currencies: %w", err)
+		}
+	}
+
+	return o.updateMarkets(ctx)
+}
+
+// SubscribeMarket subscribes to order book updates on a market. This must
+// be called before calling VWAP or MidGap.
+func (o *okx) SubscribeMarket(ctx context.Context, baseID, quoteID uint32) error {
+	o.subMarketMtx.Lock()
+	defer o.subMarketMtx.Unlock()
+
+	mkt, err := o.market(baseID, quoteID)
+	if err != nil {
+		return err
+	}
+
+	o.booksMtx.RLock()
+	book, exists := o.books[mkt.InstID]
+	o.booksMtx.RUnlock()
+	if exists {
+		book.mtx.Lock()
+		book.numSubscribers++
+		book.mtx.Unlock()
+		return nil
+	}
+
+	bui, err := asset.UnitInfo(baseID)
+	if err != nil {
+		return fmt.Errorf("error getting unit info for base asset ID %d: %v", baseID, err)
+	}
+	qui, err := asset.UnitInfo(quoteID)
+	if err != nil {
+		return fmt.Errorf("error getting unit info for quote asset ID %d: %v", quoteID, err)
+	}
+
+	book = newOKXBook(o.publicWsURL, mkt.InstID, &bui, &qui, o.log)
+	if err := book.sync(o.ctx); err != nil {
+		return fmt.Errorf("error syncing book: %v", err)
+	}
+
+	o.booksMtx.Lock()
+	o.books[mkt.InstID] = book
+	o.booksMtx.Unlock()
+
+	return nil
+}
+
+// UnsubscribeMarket unsubscribes from order book updates on a market.
+func (o *okx) UnsubscribeMarket(baseID, quoteID uint32) error {
+	o.subMarketMtx.Lock()
+	defer o.subMarketMtx.Unlock()
+
+	book, err := o.book(baseID, quoteID)
+	if err != nil {
+		return err
+	}
+
+	book.mtx.Lock()
+	book.numSubscribers--
+	remaining := book.numSubscribers
+	book.mtx.Unlock()
+
+	if remaining == 0 {
+		o.booksMtx.Lock()
+		delete(o.books, book.instID)
+		o.booksMtx.Unlock()
+		go book.cm.Disconnect()
+	}
+
+	return nil
+}
+
+// SubscribeTradeUpdates returns a channel that the caller can use to
+// listen for updates to a trade's status. When the subscription ID
+// returned from this function is passed as the updaterID argument to
+// Trade, then updates to the trade will be sent on the updated channel
+// returned from this function.
+func (o *okx) SubscribeTradeUpdates() (<-chan *Trade, func(), int) {
+	o.tradeUpdaterMtx.Lock()
+	defer o.tradeUpdaterMtx.Unlock()
+
+	updaterID := o.tradeUpdateCounter
+	o.tradeUpdateCounter++
+	updater := make(chan *Trade, 256)
+	o.tradeUpdaters[updaterID] = updater
+
+	unsubscribe := func() {
+		o.tradeUpdaterMtx.Lock()
+		delete(o.tradeUpdaters, updaterID)
+		o.tradeUpdaterMtx.Unlock()
+	}
+
+	return updater, unsubscribe, updaterID
+}
+
+func (o *okx) generateTradeID() string {
+	nonce := o.tradeIDNonce.Add(1)
+	nonceB := encode.Uint32Bytes(nonce)
+	return hex.EncodeToString(append(o.tradeIDNoncePrefix, nonceB...))
+}
+
+func buildOKXOrderRequest(mkt *okxMarket, bui, qui *dex.UnitInfo, sell bool, orderType OrderType, rate, qty, quoteQty uint64, tradeID string) (*okxtypes.OrderRequest, uint64, error) {
+	if qty > 0 && quoteQty > 0 {
+		return nil, 0, fmt.Errorf("cannot specify both quantity and quote quantity")
+	}
+	if sell && quoteQty > 0 {
+		return nil, 0, fmt.Errorf("quote quantity cannot be used for sell orders")
+	}
+	if !sell && orderType == OrderTypeMarket && qty > 0 {
+		return nil, 0, fmt.Errorf("quoteQty MUST be used for market buys")
+	}
+
+	bFactor, qFactor := bui.Conventional.ConversionFactor, qui.Conventional.ConversionFactor
+
+	qtyString := func(baseQty uint64) (string, error) {
+		if baseQty < mkt.minQty {
+			return "", fmt.Errorf("quantity %s is less than the minimum %s for market %s",
+				bui.FormatConventional(baseQty), bui.FormatConventional(mkt.minQty), mkt.InstID)
+		}
+		convQty := float64(steppedQty(baseQty, mkt.lotSize)) / float64(bFactor)
+		return strconv.FormatFloat(convQty, 'f', mkt.qtyPrec, 64), nil
+	}
+
+	side := "buy"
+	if sell {
+		side = "sell"
+	}
+	req := &okxtypes.OrderRequest{
+		InstID:  mkt.InstID,
+		TdMode:  "cash",
+		ClOrdID: tradeID,
+		Side:    side,
+	}
+
+	var qtyToReturn uint64
+	switch orderType {
+	case OrderTypeLimit, OrderTypeLimitIOC:
+		if quoteQty > 0 {
+			qty = calc.QuoteToBase(rate, quoteQty)
+		}
+		if qty == 0 {
+			return nil, 0, fmt.Errorf("must specify quantity or quote quantity")
+		}
+		qtyStr, err := qtyString(qty)
+		if err != nil {
+			return nil, 0, err
+		}
+		convRate := calc.ConventionalRateAlt(steppedRate(rate, mkt.rateStep), bFactor, qFactor)
+		req.OrdType = "limit"
+		if orderType == OrderTypeLimitIOC {
+			req.OrdType = "ioc"
+		}
+		req.Sz = qtyStr
+		req.Px = strconv.FormatFloat(convRate, 'f', mkt.ratePrec, 64)
+		qtyToReturn = qty
+	case OrderTypeMarket:
+		req.OrdType = "market"
+		if sell {
+			qtyStr, err := qtyString(qty)
+			if err != nil {
+				return nil, 0, err
+			}
+			req.Sz = qtyStr
+			req.TgtCcy = "base_ccy"
+			qtyToReturn = qty
+			break
+		}
+		if quoteQty == 0 {
+			return nil, 0, fmt.Errorf("must specify quote quantity")
+		}
+		req.Sz = strconv.FormatFloat(float64(quoteQty)/float64(qFactor), 'f', mkt.quoteQtyPrec, 64)
+		req.TgtCcy = "quote_ccy"
+		qtyToReturn = quoteQty
+	default:
+		return nil, 0, fmt.Errorf("unknown order type %d", orderType)
+	}
+
+	return req, qtyToReturn, nil
+}
+
+// Trade executes a trade on the CEX.
+//   - subscriptionID takes an ID returned from SubscribeTradeUpdates.
+//   - Rate is ignored for market orders.
+//   - Qty is in units of base asset, quoteQty is in units of quote asset.
+//     Only one of qty or quoteQty should be non-zero.
+//   - QuoteQty is only allowed for BUY orders, and it is required for market
+//     buy orders.
+func (o *okx) Trade(ctx context.Context, baseID, quoteID uint32, sell bool, rate, qty, quoteQty uint64, orderType OrderType, subscriptionID int) (*Trade, error) {
+	mkt, err := o.market(baseID, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	bui, err := asset.UnitInfo(baseID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting unit info for base asset ID %d: %v", baseID, err)
+	}
+	qui, err := asset.UnitInfo(quoteID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting unit info for quote asset ID %d: %v", quoteID, err)
+	}
+
+	req, qtyToReturn, err := buildOKXOrderRequest(mkt, &bui, &qui, sell, orderType, rate, qty, quoteQty, o.generateTradeID())
+	if err != nil {
+		return nil, fmt.Errorf("error building order request: %w", err)
+	}
+
+	// Hold the lock so that order updates are not processed until the
+	// order is registered.
+	o.tradeUpdaterMtx.Lock()
+	defer o.tradeUpdaterMtx.Unlock()
+
+	if _, found := o.tradeUpdaters[subscriptionID]; !found {
+		return nil, fmt.Errorf("no trade updater found for subscription ID %d", subscriptionID)
+	}
+
+	var res []*okxtypes.OrderResult
+	if err := o.request(ctx, http.MethodPost, "/api/v5/trade/order", nil, req, &res); err != nil {
+		return nil, fmt.Errorf("error placing order: %w", err)
+	}
+	if len(res) != 1 {
+		return nil, fmt.Errorf("expected 1 order result, got %d", len(res))
+	}
+	if res[0].SCode != "0" {
+		return nil, fmt.Errorf("error placing order: %s", res[0].SMsg)
+	}
+
+	market := orderType == OrderTypeMarket
+	o.tradeInfo[res[0].OrdID] = &tradeInfo{
+		updaterID: subscriptionID,
+		baseID:    baseID,
+		quoteID:   quoteID,
+		sell:      sell,
+		rate:      rate,
+		qty:       qtyToReturn,
+		market:    market,
+	}
+
+	return &Trade{
+		ID:      res[0].OrdID,
+		Sell:    sell,
+		Rate:    rate,
+		Qty:     qtyToReturn,
+		BaseID:  baseID,
+		QuoteID: quoteID,
+		Market:  market,
+	}, nil
+}
+
+// ValidateTrade validates a trade before it is executed.
+func (o *okx) ValidateTrade(baseID, quoteID uint32, sell bool, rate, qty, quoteQty uint64, orderType OrderType) error {
+	mkt, err := o.market(baseID, quoteID)
+	if err != nil {
+		return err
+	}
+	bui, err := asset.UnitInfo(baseID)
+	if err != nil {
+		return fmt.Errorf("error getting unit info for base asset ID %d: %v", baseID, err)
+	}
+	qui, err := asset.UnitInfo(quoteID)
+	if err != nil {
+		return fmt.Errorf("error getting unit info for quote asset ID %d: %v", quoteID, err)
+	}
+	_, _, err = buildOKXOrderRequest(mkt, &bui, &qui, sell, orderType, rate, qty, quoteQty, "")
+	return err
+}
+
+// CancelTrade cancels a trade on the CEX.
+func (o *okx) CancelTrade(ctx context.Context, baseID, quoteID uint32, tradeID string) error {
+	instID, err := o.instID(baseID, quoteID)
+	if err != nil {
+		return err
+	}
+	var res []*okxtypes.OrderResult
+	req := &okxtypes.CancelRequest{InstID: instID, OrdID: tradeID}
+	if err := o.request(ctx, http.MethodPost, "/api/v5/trade/cancel-order", nil, req, &res); err != nil {
+		return fmt.Errorf("error cancelling order: %w", err)
+	}
+	if len(res) != 1 {
+		return fmt.Errorf("expected 1 cancellation result, got %d", len(res))
+	}
+	if res[0].SCode != "0" {
+		return fmt.Errorf("cancellation failed: %s", res[0].SMsg)
+	}
+	return nil
+}
+
+// TradeStatus returns the current status of a trade.
+func (o *okx) TradeStatus(ctx context.Context, id string, baseID, quoteID uint32) (*Trade, error) {
+	instID, err := o.instID(baseID, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	bui, err := asset.UnitInfo(baseID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting unit info for base asset ID %d: %v", baseID, err)
+	}
+	qui, err := asset.UnitInfo(quoteID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting unit info for quote asset ID %d: %v", quoteID, err)
+	}
+
+	q := url.Values{
+		"instId": []string{instID},
+		"ordId":  []string{id},
+	}
+	var res []*okxtypes.Order
+	if err := o.request(ctx, http.MethodGet, "/api/v5/trade/order", q, nil, &res); err != nil {
+		return nil, fmt.Errorf("error fetching order: %w", err)
+	}
+	if len(res) != 1 {
+		return nil, fmt.Errorf("expected 1 order, got %d", len(res))
+	}
+	ord := res[0]
+
+	sell := ord.Side == "sell"
+	market := ord.OrdType == "market"
+	var qty uint64
+	if ord.TgtCcy == "quote_ccy" {
+		qty = toAtomic(ord.Sz, &qui)
+	} else {
+		qty = toAtomic(ord.Sz, &bui)
+	}
+	baseFilled, quoteFilled := okxFilled(ord, o.idTicker[baseID], o.idTicker[quoteID], &bui, &qui)
+
+	return &Trade{
+		ID:          id,
+		Sell:        sell,
+		Qty:         qty,
+		Rate:        messageRate(parseFloat(ord.Px), &bui, &qui),
+		Market:      market,
+		BaseID:      baseID,
+		QuoteID:     quoteID,
+		BaseFilled:  baseFilled,
+		QuoteFilled: quoteFilled,
+		Complete:    okxOrderComplete(ord.State),
+	}, nil
+}
+
+func (o *okx) book(baseID, quoteID uint32) (*okxBook, error) {
+	instID, err := o.instID(baseID, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	o.booksMtx.RLock()
+	book, found := o.books[instID]
+	o.booksMtx.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("no book for market %s", instID)
+	}
+	return book, nil
+}
+
+// Book generates the CEX's current view of a market's orderbook.
+func (o *okx) Book(baseID, quoteID uint32) (buys, sells []*core.MiniOrder, _ error) {
+	book, err := o.book(baseID, quoteID)
+	if err != nil {
+		return nil, nil, err
+	}
+	bids, asks := book.book.snap()
+	baseFactor := book.bui.Conventional.ConversionFactor
+	quoteFactor := book.qui.Conventional.ConversionFactor
+	buys = convertSide(bids, false, baseFactor, quoteFactor)
+	sells = convertSide(asks, true, baseFactor, quoteFactor)
+	return
+}
+
+// VWAP returns the volume weighted average price for a certain quantity
+// of the base asset on a market.
+func (o *okx) VWAP(baseID, quoteID uint32, sell bool, qty uint64) (vwap, extrema uint64, filled bool, err error) {
+	book, err := o.book(baseID, quoteID)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return book.vwap(!sell, qty)
+}
+
+// InvVWAP returns the inverse volume weighted average price for a certain
+// quantity of the quote asset on a market. SubscribeMarket must be called,
+// and the market must be synced before results can be expected.
+func (o *okx) InvVWAP(baseID, quoteID uint32, sell bool, qty uint64) (vwap, extrema uint64, filled bool, err error) {
+	book, err := o.book(baseID, quoteID)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return book.invVWAP(!sell, qty)
+}
+
+// MidGap returns the mid-gap price for a market.
+func (o *okx) MidGap(baseID, quoteID uint32) uint64 {
+	book, err := o.book(baseID, quoteID)
+	if err != nil {
+		o.log.Errorf("Error getting book: %v", err)
+		return 0
+	}
+	midGap, err := book.midGap()
+	if err != nil {
+		o.log.Errorf("Error getting mid gap: %v", err)
+		return 0
+	}
+	return midGap
+}
+
+// GetDepositAddress returns a deposit address for an asset.
+func (o *okx) GetDepositAddress(ctx context.Context, assetID uint32) (string, error) {
+	c, err := o.currency(assetID)
+	if err != nil {
+		return "", err
+	}
+	if !c.CanDep {
+		return "", fmt.Errorf("deposits of %s on %s are disabled", c.Ccy, c.Chain)
+	}
+	var res []*okxtypes.DepositAddress
+	if err := o.request(ctx, http.MethodGet, "/api/v5/asset/deposit-address", url.Values{"ccy": []string{c.Ccy}}, nil, &res); err != nil {
+		return "", fmt.Errorf("error fetching deposit address: %w", err)
+	}
+	for _, a := range res {
+		if a.Chain == c.Chain {
+			return a.Addr, nil
+		}
+	}
+	return "", fmt.Errorf("no deposit address found for %s", c.Chain)
+}
+
+// transfer moves funds between the funding and trading accounts.
+func (o *okx) transfer(ctx context.Context, ccy string, amt float64, from, to string) error {
+	req := &okxtypes.TransferRequest{
+		Ccy:  ccy,
+		Amt:  strconv.FormatFloat(amt, 'f', -1, 64),
+		From: from,
+		To:   to,
+	}
+	var res []*okxtypes.TransferResult
+	return o.request(ctx, http.MethodPost, "/api/v5/asset/transfer", nil, req, &res)
+}
+
+// ConfirmDeposit checks whether a deposit has been credited. Once it has,
+// the funds are moved from the funding account to the trading account.
+func (o *okx) ConfirmDeposit(ctx context.Context, deposit *DepositData) (bool, uint64) {
+	c, err := o.currency(deposit.AssetID)
+	if err != nil {
+		o.log.Errorf("Error getting currency for deposit: %v", err)
+		return false, 0
+	}
+	ui, err := asset.UnitInfo(deposit.AssetID)
+	if err != nil {
+		o.log.Errorf("Error getting unit info for asset ID %d: %v", deposit.AssetID, err)
+		return false, 0
+	}
+
+	q := url.Values{
+		"ccy":  []string{c.Ccy},
+		"txId": []string{deposit.TxID},
+	}
+	if o.httpURL == fakeOKXURL {
+		// The fake server needs to know the amount to check the harness
+		// wallet.
+		q.Set("amt", strconv.FormatFloat(deposit.AmountConventional, 'f', 9, 64))
+	}
+
+	var res []*okxtypes.Deposit
+	if err := o.request(ctx, http.MethodGet, "/api/v5/asset/deposit-history", q, nil, &res); err != nil {
+		o.log.Errorf("Error fetching deposit history: %v", err)
+		return false, 0
+	}
+
+	for _, d := range res {
+		if d.TxID != deposit.TxID {
+			continue
+		}
+		if d.State != okxtypes.DepositStateCredited && d.State != okxtypes.DepositStateSuccessful {
+			return false, 0
+		}
+		if err := o.transfer(ctx, c.Ccy, d.Amt, okxtypes.AccountFunding, okxtypes.AccountTrading); err != nil {
+			o.log.Errorf("Error transferring deposit %s to the trading account: %v", deposit.TxID, err)
+			return false, 0
+		}
+		return true, toAtomic(d.Amt, &ui)
+	}
+
+	return false, 0
+}
+
+// Withdraw withdraws funds from the CEX to a certain address. OKX adds the
+// fee on top of the amount, so more than the amount specified will be
+// deducted from the balance.
+func (o *okx) Withdraw(ctx context.Context, assetID uint32, amt uint64, address string) (string, uint64, error) {
+	c, err := o.currency(assetID)
+	if err != nil {
+		return "", 0, err
+	}
+	if !c.CanWd {
+		return "", 0, fmt.Errorf("withdrawals of %s on %s are disabled", c.Ccy, c.Chain)
+	}
+	ui, err := asset.UnitInfo(assetID)
+	if err != nil {
+		return "", 0, fmt.Errorf("error getting unit info for asset ID %d: %v", assetID, err)
+	}
+
+	convAmt := toConv(amt, &ui)
+	if c.WdTickSz > 0 {
+		factor := math.Pow10(c.WdTickSz)
+		convAmt = math.Floor(convAmt*factor) / factor
+	}
+	fee := c.MinFee
+
+	// Withdrawals are made from the funding account.
+	if err := o.transfer(ctx, c.Ccy, convAmt+fee, okxtypes.AccountTrading, okxtypes.AccountFunding); err != nil {
+		return "", 0, fmt.Errorf("error transferring funds to the funding account: %w", err)
+	}
+
+	req := &okxtypes.WithdrawalRequest{
+		Ccy:    c.Ccy,
+		Amt:    strconv.FormatFloat(convAmt, 'f', -1, 64),
+		Dest:   "4", // on-chain
+		ToAddr: address,
+		Chain:  c.Chain,
+		Fee:    strconv.FormatFloat(fee, 'f', -1, 64),
+	}
+	var res []*okxtypes.WithdrawalResult
+	if err := o.request(ctx, http.MethodPost, "/api/v5/asset/withdrawal", nil, req, &res); err != nil {
+		if err := o.transfer(ctx, c.Ccy, convAmt+fee, okxtypes.AccountFunding, okxtypes.AccountTrading); err != nil {
+			o.log.Errorf("Error returning funds to the trading account after failed withdrawal: %v", err)
+		}
+		return "", 0, fmt.Errorf("error withdrawing: %w", err)
+	}
+	if len(res) != 1 {
+		return "", 0, fmt.Errorf("expected 1 withdrawal result, got %d", len(res))
+	}
+
+	return res[0].WdID, toAtomic(convAmt+fee, &ui), nil
+}
+
+// ConfirmWithdrawal checks whether a withdrawal has been completed. If the
+// withdrawal has not yet been sent, ErrWithdrawalPending is returned.
+func (o *okx) ConfirmWithdrawal(ctx context.Context, withdrawalID string, assetID uint32) (uint64, string, error) {
+	ui, err := asset.UnitInfo(assetID)
+	if err != nil {
+		return 0, "", fmt.Errorf("error getting unit info for asset ID %d: %v", assetID, err)
+	}
+
+	var res []*okxtypes.Withdrawal
+	q := url.Values{"wdId": []string{withdrawalID}}
+	if err := o.request(ctx, http.MethodGet, "/api/v5/asset/withdrawal-history", q, nil, &res); err != nil {
+		return 0, "", fmt.Errorf("error fetching withdrawal history: %w", err)
+	}
+
+	for _, w := range res {
+		if w.WdID != withdrawalID {
+			continue
+		}
+		switch w.State {
+		case okxtypes.WithdrawalStateCanceled, okxtypes.WithdrawalStateFailed:
+			return 0, "", fmt.Errorf("withdrawal %s failed with state %s", withdrawalID, w.State)
+		}
+		if w.TxID == "" {
+			return 0, "", ErrWithdrawalPending
+		}
+		return toAtomic(w.Amt, &ui), w.TxID, nil
+	}
+
+	return 0, "", fmt.Errorf("withdrawal status not found for %s", withdrawalID)
+}
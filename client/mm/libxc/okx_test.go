@@ -0,0 +1,227 @@
+package libxc
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/mm/libxc/okxtypes"
+	"decred.org/dcrdex/dex/calc"
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestOKXSign(t *testing.T) {
+	const expSig = "HiZhvSfMtWJA3uUIVXV3a/bSXNPCWvYFXoGCVS8V4zY="
+	sig := okxSign("2020-12-08T09:08:57.715Z", "GET", "/api/v5/account/balance?ccy=BTC", "", "22582BD0CFF14C41EDBF1AB98506286D")
+	if sig != expSig {
+		t.Fatalf("wrong signature. expected %s, got %s", expSig, sig)
+	}
+}
+
+func TestOKXFilled(t *testing.T) {
+	btcUI, _ := asset.UnitInfo(0)
+	usdtUI, _ := asset.UnitInfo(60002)
+
+	tests := []struct {
+		name           string
+		ord            *okxtypes.Order
+		expBaseFilled  uint64
+		expQuoteFilled uint64
+	}{
+		{
+			name: "sell, quote fee",
+			ord: &okxtypes.Order{
+				Side:      "sell",
+				AccFillSz: 0.5,
+				AvgPx:     "40000",
+				Fee:       -20,
+				FeeCcy:    "USDT",
+			},
+			expBaseFilled:  5e7,
+			expQuoteFilled: 19_980e6,
+		},
+		{
+			name: "buy, base fee",
+			ord: &okxtypes.Order{
+				Side:      "buy",
+				AccFillSz: 0.5,
+				AvgPx:     "40000",
+				Fee:       -0.0005,
+				FeeCcy:    "BTC",
+			},
+			expBaseFilled:  5e7 - 5e4,
+			expQuoteFilled: 20_000e6,
+		},
+		{
+			name: "buy, rebate",
+			ord: &okxtypes.Order{
+				Side:      "buy",
+				AccFillSz: 0.5,
+				AvgPx:     "40000",
+				Fee:       0.0001,
+				FeeCcy:    "BTC",
+			},
+			expBaseFilled:  5e7,
+			expQuoteFilled: 20_000e6,
+		},
+		{
+			name: "unfilled",
+			ord: &okxtypes.Order{
+				Side:  "buy",
+				AvgPx: "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		baseFilled, quoteFilled := okxFilled(tt.ord, "BTC", "USDT", &btcUI, &usdtUI)
+		if baseFilled != tt.expBaseFilled || quoteFilled != tt.expQuoteFilled {
+			t.Fatalf("%s: expected base %d, quote %d, got base %d, quote %d", tt.name,
+				tt.expBaseFilled, tt.expQuoteFilled, baseFilled, quoteFilled)
+		}
+	}
+}
+
+func TestBuildOKXOrderRequest(t *testing.T) {
+	btcUI, _ := asset.UnitInfo(0)
+	usdtUI, _ := asset.UnitInfo(60002)
+	btcAmt := func(amt float64) uint64 {
+		return uint64(math.Round(amt * float64(btcUI.Conventional.ConversionFactor)))
+	}
+	usdtAmt := func(amt float64) uint64 {
+		return uint64(math.Round(amt * float64(usdtUI.Conventional.ConversionFactor)))
+	}
+	msgRate := func(rate float64) uint64 {
+		return calc.MessageRate(rate, btcUI, usdtUI)
+	}
+
+	mkt := parseOKXMarket(&okxtypes.Instrument{
+		InstID:   "BTC-USDT",
+		BaseCcy:  "BTC",
+		QuoteCcy: "USDT",
+		TickSz:   0.1,
+		LotSz:    0.00000001,
+		MinSz:    0.00001,
+	}, &btcUI, &usdtUI)
+
+	const tradeID = "abc123"
+
+	tests := []struct {
+		name       string
+		sell       bool
+		orderType  OrderType
+		rate       uint64
+		qty        uint64
+		quoteQty   uint64
+		expRequest *okxtypes.OrderRequest
+		wantQtyRet uint64
+		wantErr    bool
+	}{
+		{
+			name:      "limit sell",
+			sell:      true,
+			orderType: OrderTypeLimit,
+			rate:      msgRate(40000.12),
+			qty:       btcAmt(0.5),
+			expRequest: &okxtypes.OrderRequest{
+				InstID:  "BTC-USDT",
+				TdMode:  "cash",
+				ClOrdID: tradeID,
+				Side:    "sell",
+				OrdType: "limit",
+				Sz:      "0.50000000",
+				Px:      "40000.1",
+			},
+			wantQtyRet: btcAmt(0.5),
+		},
+		{
+			name:      "limit IOC buy with quote qty",
+			orderType: OrderTypeLimitIOC,
+			rate:      msgRate(40000),
+			quoteQty:  usdtAmt(4000),
+			expRequest: &okxtypes.OrderRequest{
+				InstID:  "BTC-USDT",
+				TdMode:  "cash",
+				ClOrdID: tradeID,
+				Side:    "buy",
+				OrdType: "ioc",
+				Sz:      "0.10000000",
+				Px:      "40000.0",
+			},
+			wantQtyRet: btcAmt(0.1),
+		},
+		{
+			name:      "market sell",
+			sell:      true,
+			orderType: OrderTypeMarket,
+			qty:       btcAmt(0.25),
+			expRequest: &okxtypes.OrderRequest{
+				InstID:  "BTC-USDT",
+				TdMode:  "cash",
+				ClOrdID: tradeID,
+				Side:    "sell",
+				OrdType: "market",
+				Sz:      "0.25000000",
+				TgtCcy:  "base_ccy",
+			},
+			wantQtyRet: btcAmt(0.25),
+		},
+		{
+			name:      "market buy",
+			orderType: OrderTypeMarket,
+			quoteQty:  usdtAmt(1000.5),
+			expRequest: &okxtypes.OrderRequest{
+				InstID:  "BTC-USDT",
+				TdMode:  "cash",
+				ClOrdID: tradeID,
+				Side:    "buy",
+				OrdType: "market",
+				Sz:      "1000.500000",
+				TgtCcy:  "quote_ccy",
+			},
+			wantQtyRet: usdtAmt(1000.5),
+		},
+		{
+			name:      "market buy with base qty",
+			orderType: OrderTypeMarket,
+			qty:       btcAmt(0.02),
+			wantErr:   true,
+		},
+		{
+			name:      "below min qty",
+			sell:      true,
+			orderType: OrderTypeLimit,
+			rate:      msgRate(40000),
+			qty:       btcAmt(0.000005),
+			wantErr:   true,
+		},
+		{
+			name:      "both qty and quote qty",
+			orderType: OrderTypeLimit,
+			rate:      msgRate(40000),
+			qty:       btcAmt(0.1),
+			quoteQty:  usdtAmt(4000),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		req, qtyRet, err := buildOKXOrderRequest(mkt, &btcUI, &usdtUI, tt.sell, tt.orderType, tt.rate, tt.qty, tt.quoteQty, tradeID)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("%s: expected error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if qtyRet != tt.wantQtyRet {
+			t.Fatalf("%s: expected qty %d, got %d", tt.name, tt.wantQtyRet, qtyRet)
+		}
+		if !reflect.DeepEqual(req, tt.expRequest) {
+			t.Fatalf("%s: expected request %s, got %s", tt.name, spew.Sdump(tt.expRequest), spew.Sdump(req))
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package okxtypes
+
+import "encoding/json"
+
+// Response is the envelope for every OKX REST response. A Code of "0"
+// indicates success.
+type Response struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+type Instrument struct {
+	InstID   string  `json:"instId"`
+	BaseCcy  string  `json:"baseCcy"`
+	QuoteCcy string  `json:"quoteCcy"`
+	TickSz   float64 `json:"tickSz,string"`
+	LotSz    float64 `json:"lotSz,string"`
+	MinSz    float64 `json:"minSz,string"`
+	MaxLmtSz float64 `json:"maxLmtSz,string"`
+	MaxMktSz float64 `json:"maxMktSz,string"`
+	State    string  `json:"state"`
+}
+
+type Ticker struct {
+	InstID    string  `json:"instId"`
+	Last      float64 `json:"last,string"`
+	Open24h   float64 `json:"open24h,string"`
+	High24h   float64 `json:"high24h,string"`
+	Low24h    float64 `json:"low24h,string"`
+	Vol24h    float64 `json:"vol24h,string"`
+	VolCcy24h float64 `json:"volCcy24h,string"`
+}
+
+// Currency is a currency on a specific chain as returned by the
+// /api/v5/asset/currencies endpoint.
+type Currency struct {
+	Ccy      string  `json:"ccy"`
+	Chain    string  `json:"chain"`
+	CanDep   bool    `json:"canDep"`
+	CanWd    bool    `json:"canWd"`
+	MinWd    float64 `json:"minWd,string"`
+	MinFee   float64 `json:"minFee,string"`
+	WdTickSz int     `json:"wdTickSz,string"`
+	MainNet  bool    `json:"mainNet"`
+}
+
+type BalanceDetail struct {
+	Ccy       string  `json:"ccy"`
+	AvailBal  float64 `json:"availBal,string"`
+	FrozenBal float64 `json:"frozenBal,string"`
+	CashBal   float64 `json:"cashBal,string"`
+}
+
+type AccountBalance struct {
+	Details []*BalanceDetail `json:"details"`
+}
+
+type FundingBalance struct {
+	Ccy       string  `json:"ccy"`
+	Bal       float64 `json:"bal,string"`
+	AvailBal  float64 `json:"availBal,string"`
+	FrozenBal float64 `json:"frozenBal,string"`
+}
+
+const (
+	AccountFunding = "6"
+	AccountTrading = "18"
+)
+
+type TransferRequest struct {
+	Ccy  string `json:"ccy"`
+	Amt  string `json:"amt"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type TransferResult struct {
+	TransID string `json:"transId"`
+}
+
+type OrderRequest struct {
+	InstID  string `json:"instId"`
+	TdMode  string `json:"tdMode"`
+	ClOrdID string `json:"clOrdId,omitempty"`
+	Side    string `json:"side"`    // "buy" or "sell"
+	OrdType string `json:"ordType"` // "limit", "market", or "ioc"
+	Sz      string `json:"sz"`
+	Px      string `json:"px,omitempty"`
+	TgtCcy  string `json:"tgtCcy,omitempty"` // "base_ccy" or "quote_ccy"
+}
+
+// OrderResult is the per-order result of a place or cancel request. An SCode
+// other than "0" indicates an error.
+type OrderResult struct {
+	OrdID   string `json:"ordId"`
+	ClOrdID string `json:"clOrdId"`
+	SCode   string `json:"sCode"`
+	SMsg    string `json:"sMsg"`
+}
+
+type CancelRequest struct {
+	InstID string `json:"instId"`
+	OrdID  string `json:"ordId"`
+}
+
+// Order is the order as returned by the order details endpoint and the
+// orders websocket channel. Fee is the accumulated fee, and is negative when
+// charged to the user.
+type Order struct {
+	InstID    string  `json:"instId"`
+	OrdID     string  `json:"ordId"`
+	ClOrdID   string  `json:"clOrdId"`
+	Side      string  `json:"side"`
+	OrdType   string  `json:"ordType"`
+	Px        string  `json:"px"`
+	Sz        float64 `json:"sz,string"`
+	TgtCcy    string  `json:"tgtCcy"`
+	AccFillSz float64 `json:"accFillSz,string"`
+	AvgPx     string  `json:"avgPx"`
+	Fee       float64 `json:"fee,string"`
+	FeeCcy    string  `json:"feeCcy"`
+	State     string  `json:"state"` // live, partially_filled, filled, canceled, mmp_canceled
+}
+
+type DepositAddress struct {
+	Addr     string `json:"addr"`
+	Ccy      string `json:"ccy"`
+	Chain    string `json:"chain"`
+	Selected bool   `json:"selected"`
+}
+
+type Deposit struct {
+	Ccy   string  `json:"ccy"`
+	Chain string  `json:"chain"`
+	Amt   float64 `json:"amt,string"`
+	TxID  string  `json:"txId"`
+	State string  `json:"state"`
+}
+
+const (
+	DepositStateCredited   = "1"
+	DepositStateSuccessful = "2"
+)
+
+type WithdrawalRequest struct {
+	Ccy    string `json:"ccy"`
+	Amt    string `json:"amt"`
+	Dest   string `json:"dest"` // "4" for on-chain
+	ToAddr string `json:"toAddr"`
+	Chain  string `json:"chain"`
+	Fee    string `json:"fee,omitempty"`
+}
+
+type WithdrawalResult struct {
+	WdID string `json:"wdId"`
+}
+
+type Withdrawal struct {
+	WdID  string  `json:"wdId"`
+	TxID  string  `json:"txId"`
+	Ccy   string  `json:"ccy"`
+	Chain string  `json:"chain"`
+	Amt   float64 `json:"amt,string"`
+	Fee   float64 `json:"fee,string"`
+	State string  `json:"state"`
+}
+
+const (
+	WithdrawalStateCanceled = "-2"
+	WithdrawalStateFailed   = "-1"
+)
+
+type WSArg struct {
+	Channel  string `json:"channel"`
+	InstID   string `json:"instId,omitempty"`
+	InstType string `json:"instType,omitempty"`
+}
+
+type WSRequest struct {
+	Op   string `json:"op"`
+	Args []any  `json:"args"`
+}
+
+type LoginArg struct {
+	APIKey     string `json:"apiKey"`
+	Passphrase string `json:"passphrase"`
+	Timestamp  string `json:"timestamp"`
+	Sign       string `json:"sign"`
+}
+
+// WSMessage is the general form of websocket messages. Event is set for
+// responses to subscribe and login requests, and errors. Action is set for
+// books channel data.
+type WSMessage struct {
+	Event  string          `json:"event"`
+	Code   string          `json:"code"`
+	Msg    string          `json:"msg"`
+	Arg    *WSArg          `json:"arg"`
+	Action string          `json:"action"` // "snapshot" or "update"
+	Data   json.RawMessage `json:"data"`
+}
+
+type BookData struct {
+	Asks      [][]json.Number `json:"asks"`
+	Bids      [][]json.Number `json:"bids"`
+	SeqID     int64           `json:"seqId"`
+	PrevSeqID int64           `json:"prevSeqId"`
+}
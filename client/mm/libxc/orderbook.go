@@ -117,6 +117,21 @@ func (ob *orderbook) clear() {
 	ob.asks = *skiplist.New(asksComparable)
 }
 
+// trim removes the entries beyond the specified depth on each side of the
+// book. This is needed for exchanges that send updates for a fixed number of
+// levels, but do not send removals for levels that fall out of range.
+func (ob *orderbook) trim(depth int) {
+	ob.mtx.Lock()
+	defer ob.mtx.Unlock()
+
+	for ob.bids.Len() > depth {
+		ob.bids.RemoveBack()
+	}
+	for ob.asks.Len() > depth {
+		ob.asks.RemoveBack()
+	}
+}
+
 func (ob *orderbook) vwap(bids bool, baseQty uint64) (vwap, extrema uint64, filled bool) {
 	if baseQty == 0 { // avoid division by zero
 		return 0, 0, false
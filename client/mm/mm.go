@@ -45,6 +45,7 @@ type clientCore interface {
 	TradingLimits(host string) (userParcels, parcelLimit uint32, err error)
 	WalletState(assetID uint32) *core.WalletState
 	Exchange(host string) (*core.Exchange, error)
+	EstimateSendTxFee(address string, assetID uint32, amount uint64, subtract, maxWithdraw bool) (fee uint64, isValidAddress bool, err error)
 }
 
 var _ clientCore = (*core.Core)(nil)
@@ -108,10 +109,15 @@ type bot interface {
 	latestCEXProblems() *CEXProblems
 	updateConfig(cfg *BotConfig, autoRebalanceCfg *AutoRebalanceConfig) error
 	updateInventory(balanceDiffs *BotInventoryDiffs)
+	moveInventory(balanceDiffs *BotInventoryDiffs)
 	withPause(func() error) error
+	resetRiskLimits()
 	timeStart() int64
 	botCfg() *BotConfig
 	Book() (buys, sells []*core.MiniOrder, _ error)
+	autoRebalanceCfg() *AutoRebalanceConfig
+	allocatedBalances() (dex, cex map[uint32]uint64)
+	transferFunds(assetID uint32, amount uint64, toCEX bool) error
 }
 
 type runningBot struct {
@@ -127,6 +133,9 @@ func (rb *runningBot) assets() map[uint32]interface{} {
 	assets[cfg.QuoteID] = struct{}{}
 	assets[feeAssetID(cfg.BaseID)] = struct{}{}
 	assets[feeAssetID(cfg.QuoteID)] = struct{}{}
+	for _, assetID := range cfg.extraAssets() {
+		assets[assetID] = struct{}{}
+	}
 
 	return assets
 }
@@ -510,6 +519,12 @@ func (m *MarketMaker) loginAndUnlockWallets(pw []byte, cfg *BotConfig) error {
 		return fmt.Errorf("failed to unlock wallet for asset %d: %w", cfg.QuoteID, err)
 	}
 
+	for _, assetID := range cfg.extraAssets() {
+		if err := m.core.OpenWallet(assetID, pw); err != nil {
+			return fmt.Errorf("failed to unlock wallet for asset %d: %w", assetID, err)
+		}
+	}
+
 	return nil
 }
 
@@ -570,7 +585,7 @@ func (m *MarketMaker) loadCEX(ctx context.Context, cfg *CEXConfig) (*centralized
 	defer m.cexMtx.Unlock()
 	var success bool
 	if cex := m.cexes[cfg.Name]; cex != nil {
-		if cex.APIKey == cfg.APIKey && cex.APISecret == cfg.APISecret {
+		if cex.APIKey == cfg.APIKey && cex.APISecret == cfg.APISecret && cex.APIPassphrase == cfg.APIPassphrase {
 			return cex, nil
 		}
 		if m.cexInUse(cfg.Name) {
@@ -588,10 +603,11 @@ func (m *MarketMaker) loadCEX(ctx context.Context, cfg *CEXConfig) (*centralized
 	}
 	logger := m.log.SubLogger(fmt.Sprintf("CEX-%s", cfg.Name))
 	cex, err := libxc.NewCEX(cfg.Name, &libxc.CEXConfig{
-		APIKey:    cfg.APIKey,
-		SecretKey: cfg.APISecret,
-		Logger:    logger,
-		Net:       m.core.Network(),
+		APIKey:        cfg.APIKey,
+		SecretKey:     cfg.APISecret,
+		APIPassphrase: cfg.APIPassphrase,
+		Logger:        logger,
+		Net:           m.core.Network(),
 		Notify: func(n interface{}) {
 			m.handleCEXUpdate(cfg.Name, n)
 		},
@@ -704,7 +720,14 @@ func (m *MarketMaker) Connect(ctx context.Context) (*sync.WaitGroup, error) {
 }
 
 func (m *MarketMaker) balancesSufficient(balances *BotBalanceAllocation, mkt *MarketWithHost, cexCfg *CEXConfig) error {
-	availableDEXBalances, availableCEXBalances, err := m.availableBalances(mkt, cexCfg)
+	extraAssets := make([]uint32, 0, len(balances.DEX)+len(balances.CEX))
+	for assetID := range balances.DEX {
+		extraAssets = append(extraAssets, assetID)
+	}
+	for assetID := range balances.CEX {
+		extraAssets = append(extraAssets, assetID)
+	}
+	availableDEXBalances, availableCEXBalances, err := m.availableBalances(mkt, cexCfg, extraAssets...)
 	if err != nil {
 		return fmt.Errorf("error getting available balances: %v", err)
 	}
@@ -770,6 +793,10 @@ func (m *MarketMaker) botSubLogger(cfg *BotConfig) dex.Logger {
 		return m.log.SubLogger(fmt.Sprintf("ARB-%s", mktID))
 	case cfg.ArbMarketMakerConfig != nil:
 		return m.log.SubLogger(fmt.Sprintf("AMM-%s", mktID))
+	case cfg.TriangularArbConfig != nil:
+		return m.log.SubLogger(fmt.Sprintf("TRI-%s", mktID))
+	case cfg.CrossHostArbConfig != nil:
+		return m.log.SubLogger(fmt.Sprintf("XARB-%s", mktID))
 	}
 	// This will error in the caller.
 	return m.log.SubLogger(fmt.Sprintf("Bot-%s", mktID))
@@ -799,6 +826,10 @@ func (m *MarketMaker) newBot(cfg *BotConfig, adaptorCfg *exchangeAdaptorCfg) (bo
 		return newBasicMarketMaker(cfg, adaptorCfg, m.oracle, m.log.SubLogger(fmt.Sprintf("MM-%s", mktID)))
 	case cfg.SimpleArbConfig != nil:
 		return newSimpleArbMarketMaker(cfg, adaptorCfg, m.log.SubLogger(fmt.Sprintf("ARB-%s", mktID)))
+	case cfg.TriangularArbConfig != nil:
+		return newTriangularArbMarketMaker(cfg, adaptorCfg, m.log.SubLogger(fmt.Sprintf("TRI-%s", mktID)))
+	case cfg.CrossHostArbConfig != nil:
+		return newCrossHostArbMarketMaker(cfg, adaptorCfg, m.log.SubLogger(fmt.Sprintf("XARB-%s", mktID)))
 	default:
 		return nil, fmt.Errorf("not bot config found")
 	}
@@ -1119,6 +1150,14 @@ func validRunningBotCfgUpdate(oldCfg, newCfg *BotConfig) error {
 		return fmt.Errorf("cannot change bot type for running bot")
 	}
 
+	if oldCfg.TriangularArbConfig == nil != (newCfg.TriangularArbConfig == nil) {
+		return fmt.Errorf("cannot change bot type for running bot")
+	}
+
+	if oldCfg.CrossHostArbConfig == nil != (newCfg.CrossHostArbConfig == nil) {
+		return fmt.Errorf("cannot change bot type for running bot")
+	}
+
 	return nil
 }
 
@@ -1137,7 +1176,7 @@ func (m *MarketMaker) internalTransfer(mkt *MarketWithHost, doTransfer doInterna
 		return fmt.Errorf("internalTransfer called for non-running bot %s", mkt)
 	}
 
-	dex, cex, err := m.availableBalances(mkt, rb.cexCfg)
+	dex, cex, err := m.availableBalances(mkt, rb.cexCfg, rb.botCfg().extraAssets()...)
 	if err != nil {
 		return fmt.Errorf("error getting available balances: %v", err)
 	}
@@ -1238,6 +1277,8 @@ func (m *MarketMaker) UpdateRunningBotCfg(cfg *BotConfig, balanceDiffs *BotInven
 		if balanceDiffs != nil {
 			rb.updateInventory(balanceDiffs)
 		}
+		// A bot paused by a risk limit is resumed with the new config.
+		rb.resetRiskLimits()
 		return nil
 	}); err != nil {
 		rb.cm.Disconnect()
@@ -1506,6 +1547,7 @@ type RunLogFilters struct {
 	CexSells    bool `json:"cexSells"`
 	Deposits    bool `json:"deposits"`
 	Withdrawals bool `json:"withdrawals"`
+	RiskLimits  bool `json:"riskLimits"`
 }
 
 func (f *RunLogFilters) filter(event *MarketMakingEvent) bool {
@@ -1524,6 +1566,8 @@ func (f *RunLogFilters) filter(event *MarketMakingEvent) bool {
 		return f.Deposits
 	case event.WithdrawalEvent != nil:
 		return f.Withdrawals
+	case event.RiskLimitEvent != nil:
+		return f.RiskLimits
 	default:
 		return false
 	}
@@ -1536,6 +1580,7 @@ var noFilters = &RunLogFilters{
 	CexSells:    true,
 	Deposits:    true,
 	Withdrawals: true,
+	RiskLimits:  true,
 }
 
 // RunLogs returns the event logs of a market making run. At most n events are
@@ -1654,7 +1699,10 @@ func marketFees(c clientCore, host string, baseID, quoteID uint32, useMaxFeeRate
 		}, nil
 }
 
-func (m *MarketMaker) availableBalances(mkt *MarketWithHost, cexCfg *CEXConfig) (dexBalances, cexBalances map[uint32]uint64, _ error) {
+// availableBalances returns the balances available for a bot on the specified
+// market. extraAssets are any assets used by the bot other than the market's
+// base and quote assets and their fee assets.
+func (m *MarketMaker) availableBalances(mkt *MarketWithHost, cexCfg *CEXConfig, extraAssets ...uint32) (dexBalances, cexBalances map[uint32]uint64, _ error) {
 	dexAssets := make(map[uint32]interface{})
 	cexAssets := make(map[uint32]interface{})
 
@@ -1662,10 +1710,16 @@ func (m *MarketMaker) availableBalances(mkt *MarketWithHost, cexCfg *CEXConfig)
 	dexAssets[mkt.QuoteID] = struct{}{}
 	dexAssets[feeAssetID(mkt.BaseID)] = struct{}{}
 	dexAssets[feeAssetID(mkt.QuoteID)] = struct{}{}
+	for _, assetID := range extraAssets {
+		dexAssets[assetID] = struct{}{}
+	}
 
 	if cexCfg != nil {
 		cexAssets[mkt.BaseID] = struct{}{}
 		cexAssets[mkt.QuoteID] = struct{}{}
+		for _, assetID := range extraAssets {
+			cexAssets[assetID] = struct{}{}
+		}
 	}
 
 	checkTotalBalances := func() (dexBals, cexBals map[uint32]uint64, err error) {
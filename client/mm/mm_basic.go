@@ -15,6 +15,8 @@ import (
 	"decred.org/dcrdex/client/core"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/candles"
+	"decred.org/dcrdex/dex/msgjson"
 	"decred.org/dcrdex/dex/utils"
 )
 
@@ -37,8 +39,73 @@ const (
 	// GapStrategyPercentPlus sets the spread as a ratio of the mid-gap rate
 	// plus the break-even gap.
 	GapStrategyPercentPlus GapStrategy = "percent-plus"
+	// GapStrategyAvellanedaStoikov places orders around a reservation price
+	// that is skewed away from the basis price based on the bot's DEX
+	// inventory, and sets the spread based on recent volatility. The gap
+	// factor is a multiplier of the resulting half-spread, 1 <= r <= 100.
+	// See AvellanedaStoikovConfig.
+	GapStrategyAvellanedaStoikov GapStrategy = "avellaneda-stoikov"
 )
 
+// AvellanedaStoikovConfig is the configuration for the Avellaneda-Stoikov gap
+// strategy. The reservation price is
+//
+//	r = s * (1 - q * γ * σ²)
+//
+// where s is the basis price, q is the difference between the share of the
+// bot's DEX inventory value held in the base asset and the TargetBaseRatio, γ
+// is the RiskAversion, and σ² is the variance of the log returns of the last
+// VolatilityPeriods candles, scaled to the duration of VolatilityPeriods
+// candles. The half-spread is s * γ * σ² / 2, but never less than the
+// break-even half-spread.
+type AvellanedaStoikovConfig struct {
+	// TargetBaseRatio is the share of the DEX inventory value that the bot
+	// tries to hold in the base asset. 0 < x < 1. Default: 0.5.
+	TargetBaseRatio float64 `json:"targetBaseRatio"`
+	// RiskAversion is the risk aversion parameter, γ. Higher values skew
+	// the reservation price further for the same inventory imbalance, and
+	// widen the spread. 0 < x <= 1000.
+	RiskAversion float64 `json:"riskAversion"`
+	// VolatilityBinSize is the candle duration used to measure volatility.
+	// It must be one of the bin sizes published by the server. Default: 5m.
+	VolatilityBinSize string `json:"volatilityBinSize"`
+	// VolatilityPeriods is the number of candles used to measure
+	// volatility. 2 <= x <= 1000. Default: 24.
+	VolatilityPeriods uint32 `json:"volatilityPeriods"`
+}
+
+func (c *AvellanedaStoikovConfig) validate() error {
+	if c.TargetBaseRatio == 0 {
+		c.TargetBaseRatio = 0.5
+	}
+	if c.TargetBaseRatio <= 0 || c.TargetBaseRatio >= 1 {
+		return fmt.Errorf("target base ratio %f out of bounds", c.TargetBaseRatio)
+	}
+	if c.RiskAversion <= 0 || c.RiskAversion > 1000 {
+		return fmt.Errorf("risk aversion %f out of bounds", c.RiskAversion)
+	}
+	if c.VolatilityBinSize == "" {
+		c.VolatilityBinSize = "5m"
+	}
+	var validBinSize bool
+	for _, binSize := range candles.BinSizes {
+		if binSize == c.VolatilityBinSize {
+			validBinSize = true
+			break
+		}
+	}
+	if !validBinSize {
+		return fmt.Errorf("unknown volatility bin size %q", c.VolatilityBinSize)
+	}
+	if c.VolatilityPeriods == 0 {
+		c.VolatilityPeriods = 24
+	}
+	if c.VolatilityPeriods < 2 || c.VolatilityPeriods > 1000 {
+		return fmt.Errorf("volatility periods %d out of bounds", c.VolatilityPeriods)
+	}
+	return nil
+}
+
 // OrderPlacement represents the distance from the mid-gap and the
 // amount of lots that should be placed at this distance.
 type OrderPlacement struct {
@@ -72,10 +139,15 @@ type BasicMarketMakingConfig struct {
 	// before they are replaced (units: ratio of price). Default: 0.1%.
 	// 0 <= x <= 0.01.
 	DriftTolerance float64 `json:"driftTolerance"`
+
+	// AvellanedaStoikov is the configuration for the Avellaneda-Stoikov gap
+	// strategy. Required if and only if that strategy is selected.
+	AvellanedaStoikov *AvellanedaStoikovConfig `json:"avellanedaStoikov,omitempty"`
 }
 
 func needBreakEvenHalfSpread(strat GapStrategy) bool {
-	return strat == GapStrategyAbsolutePlus || strat == GapStrategyPercentPlus || strat == GapStrategyMultiplier
+	return strat == GapStrategyAbsolutePlus || strat == GapStrategyPercentPlus || strat == GapStrategyMultiplier ||
+		strat == GapStrategyAvellanedaStoikov
 }
 
 func (c *BasicMarketMakingConfig) validate() error {
@@ -90,14 +162,26 @@ func (c *BasicMarketMakingConfig) validate() error {
 		c.GapStrategy != GapStrategyPercent &&
 		c.GapStrategy != GapStrategyPercentPlus &&
 		c.GapStrategy != GapStrategyAbsolute &&
-		c.GapStrategy != GapStrategyAbsolutePlus {
+		c.GapStrategy != GapStrategyAbsolutePlus &&
+		c.GapStrategy != GapStrategyAvellanedaStoikov {
 		return fmt.Errorf("unknown gap strategy %q", c.GapStrategy)
 	}
 
+	if c.GapStrategy == GapStrategyAvellanedaStoikov {
+		if c.AvellanedaStoikov == nil {
+			return errors.New("no avellaneda-stoikov config provided")
+		}
+		if err := c.AvellanedaStoikov.validate(); err != nil {
+			return fmt.Errorf("invalid avellaneda-stoikov config: %w", err)
+		}
+	} else if c.AvellanedaStoikov != nil {
+		return fmt.Errorf("avellaneda-stoikov config provided for %s gap strategy", c.GapStrategy)
+	}
+
 	validatePlacement := func(p *OrderPlacement) error {
 		var limits [2]float64
 		switch c.GapStrategy {
-		case GapStrategyMultiplier:
+		case GapStrategyMultiplier, GapStrategyAvellanedaStoikov:
 			limits = [2]float64{1, 100}
 		case GapStrategyPercent, GapStrategyPercentPlus:
 			limits = [2]float64{0, 0.1}
@@ -151,6 +235,10 @@ func (c *BasicMarketMakingConfig) copy() *BasicMarketMakingConfig {
 
 	cfg.SellPlacements = utils.Map(c.SellPlacements, copyOrderPlacement)
 	cfg.BuyPlacements = utils.Map(c.BuyPlacements, copyOrderPlacement)
+	if c.AvellanedaStoikov != nil {
+		asCfg := *c.AvellanedaStoikov
+		cfg.AvellanedaStoikov = &asCfg
+	}
 
 	return &cfg
 }
@@ -193,14 +281,16 @@ type basicMMCalculator interface {
 	basisPrice() (bp uint64, err error)
 	halfSpread(uint64) (uint64, error)
 	feeGapStats(uint64) (*FeeGapStats, error)
+	avellanedaStoikov(asCfg *AvellanedaStoikovConfig, basisPrice, breakEvenHalfSpread uint64) (reservationPrice, halfSpread uint64, err error)
 }
 
 type basicMMCalculatorImpl struct {
 	*market
-	oracle oracle
-	core   botCoreAdaptor
-	cfg    *BasicMarketMakingConfig
-	log    dex.Logger
+	oracle  oracle
+	core    botCoreAdaptor
+	cfg     *BasicMarketMakingConfig
+	log     dex.Logger
+	candles *candleHistory
 }
 
 var errNoBasisPrice = errors.New("no oracle or fiat rate available")
@@ -326,6 +416,134 @@ func (b *basicMMCalculatorImpl) feeGapStats(basisPrice uint64) (*FeeGapStats, er
 	}, nil
 }
 
+// candleHistory holds the most recent candles of a single bin size, and is
+// used to measure volatility.
+type candleHistory struct {
+	mtx     sync.RWMutex
+	max     int
+	candles []msgjson.Candle
+}
+
+func newCandleHistory(max int) *candleHistory {
+	return &candleHistory{max: max}
+}
+
+// set replaces the history with a fresh set of candles.
+func (h *candleHistory) set(cs []msgjson.Candle) {
+	if len(cs) > h.max {
+		cs = cs[len(cs)-h.max:]
+	}
+	h.mtx.Lock()
+	h.candles = append(make([]msgjson.Candle, 0, h.max), cs...)
+	h.mtx.Unlock()
+}
+
+// update adds a new candle, or replaces the latest candle if they have the same
+// start stamp.
+func (h *candleHistory) update(c *msgjson.Candle) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if n := len(h.candles); n > 0 {
+		last := &h.candles[n-1]
+		if last.StartStamp == c.StartStamp {
+			*last = *c
+			return
+		}
+		if c.StartStamp < last.StartStamp {
+			return
+		}
+	}
+	h.candles = append(h.candles, *c)
+	if len(h.candles) > h.max {
+		h.candles = h.candles[len(h.candles)-h.max:]
+	}
+}
+
+// logReturnVariance returns the sample variance of the log returns between the
+// end rates of consecutive candles, and the number of returns used. Candles
+// with no rate are skipped.
+func (h *candleHistory) logReturnVariance() (variance float64, n int) {
+	h.mtx.RLock()
+	returns := make([]float64, 0, len(h.candles))
+	var prevRate uint64
+	for i := range h.candles {
+		rate := h.candles[i].EndRate
+		if rate == 0 {
+			continue
+		}
+		if prevRate != 0 {
+			returns = append(returns, math.Log(float64(rate)/float64(prevRate)))
+		}
+		prevRate = rate
+	}
+	h.mtx.RUnlock()
+
+	n = len(returns)
+	if n < 2 {
+		return 0, n
+	}
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(n)
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	return variance / float64(n-1), n
+}
+
+// maxReservationSkew is the maximum distance, as a ratio of the basis price,
+// that the Avellaneda-Stoikov reservation price will be moved away from the
+// basis price.
+const maxReservationSkew = 0.1
+
+// avellanedaStoikov calculates the reservation price and half-spread for the
+// Avellaneda-Stoikov gap strategy. The half-spread is never less than the
+// break-even half-spread. See AvellanedaStoikovConfig.
+func (b *basicMMCalculatorImpl) avellanedaStoikov(asCfg *AvellanedaStoikovConfig, basisPrice, breakEvenHalfSpread uint64) (uint64, uint64, error) {
+	if asCfg == nil {
+		return 0, 0, errors.New("no avellaneda-stoikov config")
+	}
+	if basisPrice == 0 {
+		return 0, 0, fmt.Errorf("basis price cannot be zero")
+	}
+
+	baseBal, quoteBal := b.core.DEXBalance(b.baseID), b.core.DEXBalance(b.quoteID)
+	baseValue := float64(calc.BaseToQuote(basisPrice, baseBal.Available+baseBal.Locked+baseBal.Pending))
+	quoteValue := float64(quoteBal.Available + quoteBal.Locked + quoteBal.Pending)
+	if baseValue+quoteValue == 0 {
+		return 0, 0, errors.New("no dex inventory")
+	}
+	q := baseValue/(baseValue+quoteValue) - asCfg.TargetBaseRatio
+
+	var variance float64
+	if b.candles != nil {
+		var n int
+		variance, n = b.candles.logReturnVariance()
+		if n < 2 {
+			b.log.Meter("avellanedaStoikov_nocandles_"+b.market.name, time.Hour).Warnf(
+				"Not enough %s candles to measure volatility for %s. Inventory skew is disabled.",
+				asCfg.VolatilityBinSize, b.market.name)
+		}
+	}
+	horizonVariance := variance * float64(asCfg.VolatilityPeriods)
+
+	skew := q * asCfg.RiskAversion * horizonVariance
+	skew = math.Max(-maxReservationSkew, math.Min(maxReservationSkew, skew))
+	reservationPrice := uint64(math.Round(float64(basisPrice) * (1 - skew)))
+
+	halfSpread := uint64(math.Round(float64(basisPrice) * asCfg.RiskAversion * horizonVariance / 2))
+	halfSpread = max(halfSpread, breakEvenHalfSpread)
+
+	if b.log.Level() == dex.LevelTrace {
+		b.log.Tracef("avellanedaStoikov: basis price = %s, inventory skew = %.4f, variance = %.8f, reservation price = %s, half-spread = %s",
+			b.fmtRate(basisPrice), q, horizonVariance, b.fmtRate(reservationPrice), b.fmtRate(halfSpread))
+	}
+
+	return steppedRate(reservationPrice, b.rateStep.Load()), halfSpread, nil
+}
+
 type basicMarketMaker struct {
 	*unifiedExchangeAdaptor
 	core             botCoreAdaptor
@@ -345,7 +563,7 @@ func (m *basicMarketMaker) orderPrice(basisPrice, feeAdj uint64, sell bool, gapF
 
 	// Apply the base strategy.
 	switch m.cfg().GapStrategy {
-	case GapStrategyMultiplier:
+	case GapStrategyMultiplier, GapStrategyAvellanedaStoikov:
 		adj = uint64(math.Round(float64(feeAdj) * gapFactor))
 	case GapStrategyPercent, GapStrategyPercentPlus:
 		adj = uint64(math.Round(gapFactor * float64(basisPrice)))
@@ -389,6 +607,13 @@ func (m *basicMarketMaker) ordersToPlace() (buyOrders, sellOrders []*TradePlacem
 		feeAdj = feeGap.FeeGap / 2
 	}
 
+	if m.cfg().GapStrategy == GapStrategyAvellanedaStoikov {
+		basisPrice, feeAdj, err = m.calculator.avellanedaStoikov(m.cfg().AvellanedaStoikov, basisPrice, feeAdj)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error calculating avellaneda-stoikov reservation price: %w", err)
+		}
+	}
+
 	if m.log.Level() == dex.LevelTrace {
 		m.log.Tracef("ordersToPlace %s, basis price = %s, break-even fee adjustment = %s",
 			m.name, m.fmtRate(basisPrice), m.fmtRate(feeAdj))
@@ -458,13 +683,24 @@ func (m *basicMarketMaker) botLoop(ctx context.Context) (*sync.WaitGroup, error)
 		return nil, fmt.Errorf("failed to sync book: %v", err)
 	}
 
-	m.calculator = &basicMMCalculatorImpl{
+	calculator := &basicMMCalculatorImpl{
 		market: m.market,
 		oracle: m.oracle,
 		core:   m.core,
 		cfg:    m.cfg(),
 		log:    m.log,
 	}
+	m.calculator = calculator
+
+	var candleBinSize string
+	if asCfg := m.cfg().AvellanedaStoikov; m.cfg().GapStrategy == GapStrategyAvellanedaStoikov && asCfg != nil {
+		candleBinSize = asCfg.VolatilityBinSize
+		calculator.candles = newCandleHistory(int(asCfg.VolatilityPeriods) + 1)
+		if err := bookFeed.Candles(candleBinSize); err != nil {
+			bookFeed.Close()
+			return nil, fmt.Errorf("error subscribing to %s candles: %w", candleBinSize, err)
+		}
+	}
 
 	// Process book updates
 	var wg sync.WaitGroup
@@ -480,9 +716,17 @@ func (m *basicMarketMaker) botLoop(ctx context.Context) (*sync.WaitGroup, error)
 					m.kill()
 					return
 				}
-				switch epoch := ni.Payload.(type) {
+				switch payload := ni.Payload.(type) {
 				case *core.ResolvedEpoch:
-					m.rebalance(epoch.Current)
+					m.rebalance(payload.Current)
+				case *core.CandlesPayload:
+					if calculator.candles != nil && payload.Dur == candleBinSize {
+						calculator.candles.set(payload.Candles)
+					}
+				case core.CandleUpdate:
+					if calculator.candles != nil && payload.Dur == candleBinSize && payload.Candle != nil {
+						calculator.candles.update(payload.Candle)
+					}
 				}
 			case <-ctx.Done():
 				return
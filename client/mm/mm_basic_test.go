@@ -8,6 +8,7 @@ import (
 
 	"decred.org/dcrdex/client/core"
 	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/msgjson"
 )
 
 type tBasicMMCalculator struct {
@@ -15,6 +16,10 @@ type tBasicMMCalculator struct {
 	bpErr error
 
 	hs uint64
+
+	// reservation price and half-spread for the avellaneda-stoikov strategy
+	rp   uint64
+	asHS uint64
 }
 
 var _ basicMMCalculator = (*tBasicMMCalculator)(nil)
@@ -29,6 +34,11 @@ func (r *tBasicMMCalculator) halfSpread(basisPrice uint64) (uint64, error) {
 func (r *tBasicMMCalculator) feeGapStats(basisPrice uint64) (*FeeGapStats, error) {
 	return &FeeGapStats{FeeGap: r.hs * 2}, nil
 }
+
+func (r *tBasicMMCalculator) avellanedaStoikov(_ *AvellanedaStoikovConfig, basisPrice, breakEvenHalfSpread uint64) (uint64, uint64, error) {
+	return r.rp, max(r.asHS, breakEvenHalfSpread), nil
+}
+
 func TestBasisPrice(t *testing.T) {
 	mkt := &core.Market{
 		RateStep:   1,
@@ -247,10 +257,14 @@ func TestBasicMMRebalance(t *testing.T) {
 	const halfSpread uint64 = 2e5
 	const rateStep uint64 = 1e3
 	const atomToConv float64 = 1
+	const reservationPrice uint64 = 4.7e6
+	const asHalfSpread uint64 = 3e5
 
 	calculator := &tBasicMMCalculator{
-		bp: basisPrice,
-		hs: halfSpread,
+		bp:   basisPrice,
+		hs:   halfSpread,
+		rp:   reservationPrice,
+		asHS: asHalfSpread,
 	}
 
 	type test struct {
@@ -381,6 +395,26 @@ func TestBasicMMRebalance(t *testing.T) {
 				{Lots: 1, Rate: steppedRate(basisPrice+halfSpread+1e6, rateStep)},
 			},
 		},
+		{
+			name:     "avellaneda-stoikov",
+			strategy: GapStrategyAvellanedaStoikov,
+			cfgBuyPlacements: []*OrderPlacement{
+				{Lots: 1, GapFactor: 2},
+				{Lots: 2, GapFactor: 1},
+			},
+			cfgSellPlacements: []*OrderPlacement{
+				{Lots: 2, GapFactor: 1},
+				{Lots: 1, GapFactor: 2},
+			},
+			expBuyPlacements: []*TradePlacement{
+				{Lots: 1, Rate: steppedRate(reservationPrice-2*asHalfSpread, rateStep)},
+				{Lots: 2, Rate: steppedRate(reservationPrice-1*asHalfSpread, rateStep)},
+			},
+			expSellPlacements: []*TradePlacement{
+				{Lots: 2, Rate: steppedRate(reservationPrice+1*asHalfSpread, rateStep)},
+				{Lots: 1, Rate: steppedRate(reservationPrice+2*asHalfSpread, rateStep)},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -479,3 +513,255 @@ func TestBasicMMRebalance(t *testing.T) {
 		})
 	}
 }
+
+func TestAvellanedaStoikov(t *testing.T) {
+	const baseID, quoteID = 42, 0
+	const basisPrice uint64 = 1e8 // 1 BTC/DCR
+	const breakEvenHalfSpread uint64 = 1e5
+	const periods = 4
+
+	mkt := &core.Market{
+		RateStep: 1,
+		BaseID:   baseID,
+		QuoteID:  quoteID,
+		LotSize:  1e8,
+	}
+
+	// End rates alternate between +1% and -1% log returns.
+	up := math.Exp(0.01)
+	endRates := []uint64{1e8, uint64(1e8 * up), 1e8, uint64(1e8 * up), 1e8}
+	mkCandles := func() []msgjson.Candle {
+		cs := make([]msgjson.Candle, len(endRates))
+		for i, r := range endRates {
+			cs[i] = msgjson.Candle{StartStamp: uint64(i), EndRate: r}
+		}
+		return cs
+	}
+	variance, n := func() (float64, int) {
+		h := newCandleHistory(periods + 1)
+		h.set(mkCandles())
+		return h.logReturnVariance()
+	}()
+	if n != periods {
+		t.Fatalf("expected %d returns, got %d", periods, n)
+	}
+	// The returns are +r, -r, +r, -r, so the sample variance is 4r²/3.
+	r := math.Log(float64(endRates[1]) / 1e8)
+	if math.Abs(variance-4*r*r/3) > 1e-12 {
+		t.Fatalf("wrong variance %f", variance)
+	}
+	horizonVariance := variance * periods
+
+	tests := []struct {
+		name         string
+		baseBal      uint64
+		quoteBal     uint64
+		riskAversion float64
+		noCandles    bool
+		expRP        uint64
+		expHS        uint64
+		expErr       bool
+	}{
+		{
+			name:         "balanced",
+			baseBal:      5e8,
+			quoteBal:     5e8,
+			riskAversion: 10,
+			expRP:        basisPrice,
+			expHS:        uint64(math.Round(float64(basisPrice) * 10 * horizonVariance / 2)),
+		},
+		{
+			name:         "excess base lowers reservation price",
+			baseBal:      7.5e8,
+			quoteBal:     2.5e8,
+			riskAversion: 10,
+			expRP:        uint64(math.Round(float64(basisPrice) * (1 - 0.25*10*horizonVariance))),
+			expHS:        uint64(math.Round(float64(basisPrice) * 10 * horizonVariance / 2)),
+		},
+		{
+			name:         "excess quote raises reservation price",
+			baseBal:      2.5e8,
+			quoteBal:     7.5e8,
+			riskAversion: 10,
+			expRP:        uint64(math.Round(float64(basisPrice) * (1 + 0.25*10*horizonVariance))),
+			expHS:        uint64(math.Round(float64(basisPrice) * 10 * horizonVariance / 2)),
+		},
+		{
+			name:         "break-even half-spread is the minimum",
+			baseBal:      2.5e8,
+			quoteBal:     7.5e8,
+			riskAversion: 0.1,
+			expRP:        uint64(math.Round(float64(basisPrice) * (1 + 0.25*0.1*horizonVariance))),
+			expHS:        breakEvenHalfSpread,
+		},
+		{
+			name:         "skew is limited",
+			baseBal:      10e8,
+			riskAversion: 1000,
+			expRP:        uint64(math.Round(float64(basisPrice) * (1 - maxReservationSkew))),
+			expHS:        uint64(math.Round(float64(basisPrice) * 1000 * horizonVariance / 2)),
+		},
+		{
+			name:         "no candles",
+			baseBal:      10e8,
+			riskAversion: 10,
+			noCandles:    true,
+			expRP:        basisPrice,
+			expHS:        breakEvenHalfSpread,
+		},
+		{
+			name:         "no inventory",
+			riskAversion: 10,
+			expErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adaptor := newTBotCoreAdaptor(newTCore())
+			adaptor.balances = map[uint32]*BotBalance{
+				baseID:  {Available: tt.baseBal},
+				quoteID: {Available: tt.quoteBal},
+			}
+			calculator := &basicMMCalculatorImpl{
+				market:  mustParseMarket(mkt),
+				core:    adaptor,
+				log:     tLogger,
+				candles: newCandleHistory(periods + 1),
+			}
+			if !tt.noCandles {
+				calculator.candles.set(mkCandles())
+			}
+			asCfg := &AvellanedaStoikovConfig{
+				TargetBaseRatio:   0.5,
+				RiskAversion:      tt.riskAversion,
+				VolatilityBinSize: "5m",
+				VolatilityPeriods: periods,
+			}
+			rp, hs, err := calculator.avellanedaStoikov(asCfg, basisPrice, breakEvenHalfSpread)
+			if tt.expErr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rp != tt.expRP {
+				t.Fatalf("wrong reservation price. expected %d, got %d", tt.expRP, rp)
+			}
+			if hs != tt.expHS {
+				t.Fatalf("wrong half-spread. expected %d, got %d", tt.expHS, hs)
+			}
+		})
+	}
+}
+
+func TestCandleHistory(t *testing.T) {
+	h := newCandleHistory(3)
+	h.set([]msgjson.Candle{{StartStamp: 1, EndRate: 1}, {StartStamp: 2, EndRate: 2}, {StartStamp: 3, EndRate: 3}, {StartStamp: 4, EndRate: 4}})
+	checkRates := func(exp ...uint64) {
+		t.Helper()
+		h.mtx.RLock()
+		defer h.mtx.RUnlock()
+		if len(h.candles) != len(exp) {
+			t.Fatalf("expected %d candles, got %d", len(exp), len(h.candles))
+		}
+		for i, r := range exp {
+			if h.candles[i].EndRate != r {
+				t.Fatalf("candle %d: expected end rate %d, got %d", i, r, h.candles[i].EndRate)
+			}
+		}
+	}
+	checkRates(2, 3, 4)
+	// Updating the latest candle replaces it.
+	h.update(&msgjson.Candle{StartStamp: 4, EndRate: 5})
+	checkRates(2, 3, 5)
+	// A new candle pushes out the oldest.
+	h.update(&msgjson.Candle{StartStamp: 5, EndRate: 6})
+	checkRates(3, 5, 6)
+	// Old candles are ignored.
+	h.update(&msgjson.Candle{StartStamp: 2, EndRate: 7})
+	checkRates(3, 5, 6)
+}
+
+func TestAvellanedaStoikovConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *BasicMarketMakingConfig
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			cfg: &BasicMarketMakingConfig{
+				GapStrategy:       GapStrategyAvellanedaStoikov,
+				AvellanedaStoikov: &AvellanedaStoikovConfig{RiskAversion: 1},
+				SellPlacements:    []*OrderPlacement{{Lots: 1, GapFactor: 1}},
+			},
+		},
+		{
+			name: "missing config",
+			cfg: &BasicMarketMakingConfig{
+				GapStrategy: GapStrategyAvellanedaStoikov,
+			},
+			wantErr: true,
+		},
+		{
+			name: "config for other strategy",
+			cfg: &BasicMarketMakingConfig{
+				GapStrategy:       GapStrategyMultiplier,
+				AvellanedaStoikov: &AvellanedaStoikovConfig{RiskAversion: 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "no risk aversion",
+			cfg: &BasicMarketMakingConfig{
+				GapStrategy:       GapStrategyAvellanedaStoikov,
+				AvellanedaStoikov: &AvellanedaStoikovConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad target ratio",
+			cfg: &BasicMarketMakingConfig{
+				GapStrategy:       GapStrategyAvellanedaStoikov,
+				AvellanedaStoikov: &AvellanedaStoikovConfig{RiskAversion: 1, TargetBaseRatio: 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown bin size",
+			cfg: &BasicMarketMakingConfig{
+				GapStrategy:       GapStrategyAvellanedaStoikov,
+				AvellanedaStoikov: &AvellanedaStoikovConfig{RiskAversion: 1, VolatilityBinSize: "7m"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "gap factor below 1",
+			cfg: &BasicMarketMakingConfig{
+				GapStrategy:       GapStrategyAvellanedaStoikov,
+				AvellanedaStoikov: &AvellanedaStoikovConfig{RiskAversion: 1},
+				BuyPlacements:     []*OrderPlacement{{Lots: 1, GapFactor: 0.5}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wanted error = %t, got %v", tt.wantErr, err)
+			}
+			if err == nil && tt.cfg.AvellanedaStoikov != nil {
+				asCfg := tt.cfg.AvellanedaStoikov
+				if asCfg.TargetBaseRatio != 0.5 || asCfg.VolatilityBinSize != "5m" || asCfg.VolatilityPeriods != 24 {
+					t.Fatalf("defaults not set: %+v", asCfg)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,569 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package mm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/order"
+)
+
+// CrossHostArbConfig is the configuration for an arbitrage bot that trades
+// the bot's market against the same market on a second DEX host. When the
+// books on the two hosts are crossed, the bot sells on the host with the
+// higher bids and buys on the host with the lower asks.
+type CrossHostArbConfig struct {
+	// Host is the second DEX host.
+	Host string `json:"host"`
+	// ProfitTrigger is the minimum profit before a trade sequence is
+	// initiated. Range: 0 < ProfitTrigger << 1. For example, if the
+	// ProfitTrigger is 0.01 and a trade sequence would produce a 1% profit
+	// or better, a trade sequence will be initiated.
+	ProfitTrigger float64 `json:"profitTrigger"`
+	// MaxActiveArbs sets a limit on the number of active arbitrage sequences
+	// that can be open simultaneously.
+	MaxActiveArbs uint32 `json:"maxActiveArbs"`
+	// NumEpochsLeaveOpen is the number of epochs an arbitrage sequence will
+	// stay open if one or both of the orders were not filled.
+	NumEpochsLeaveOpen uint32 `json:"numEpochsLeaveOpen"`
+}
+
+func (c *CrossHostArbConfig) copy() *CrossHostArbConfig {
+	cfg := *c
+	return &cfg
+}
+
+func (c *CrossHostArbConfig) validate(host string) error {
+	if c.Host == "" {
+		return fmt.Errorf("no second host specified")
+	}
+	if c.Host == host {
+		return fmt.Errorf("second host must be different than the bot's host")
+	}
+
+	if c.ProfitTrigger <= 0 || c.ProfitTrigger > 1 {
+		return fmt.Errorf("profit trigger must be 0 < t <= 1, but got %v", c.ProfitTrigger)
+	}
+
+	if c.MaxActiveArbs == 0 {
+		return fmt.Errorf("must allow at least 1 active arb")
+	}
+
+	if c.NumEpochsLeaveOpen < 2 {
+		return fmt.Errorf("arbs must be left open for at least 2 epochs")
+	}
+
+	return nil
+}
+
+// crossHostArbOpportunity describes the trades of a profitable cross-host
+// arbitrage sequence.
+type crossHostArbOpportunity struct {
+	// sellOnBotHost is true if the base asset is sold on the bot's host and
+	// bought on the second host, and false if the reverse.
+	sellOnBotHost bool
+	qty           uint64
+	sellRate      uint64
+	buyRate       uint64
+	// profit is in units of the quote asset.
+	profit uint64
+}
+
+// crossHostArbSequence represents an attempted cross-host arbitrage sequence.
+type crossHostArbSequence struct {
+	sellOnBotHost bool
+	// orders are the orders on the bot's host and the second host, in that
+	// order.
+	orders      [2]*core.Order
+	orderFilled [2]bool
+	startEpoch  uint64
+}
+
+type crossHostArbMarketMaker struct {
+	*unifiedExchangeAdaptor
+	core             botCoreAdaptor
+	book             dexOrderBook
+	hostMarketBook   dexOrderBook
+	rebalanceRunning atomic.Bool
+
+	// hostMarketMtx guards the market on the second host and the parcel
+	// sizes, which are updated when the second host's config changes.
+	hostMarketMtx sync.RWMutex
+	hostMarket    *market
+	// parcelSizes are the parcel sizes of the market on the bot's host and
+	// the second host, in that order.
+	parcelSizes [2]uint32
+
+	activeArbsMtx sync.RWMutex
+	activeArbs    []*crossHostArbSequence
+}
+
+var _ bot = (*crossHostArbMarketMaker)(nil)
+
+func (a *crossHostArbMarketMaker) cfg() *CrossHostArbConfig {
+	return a.botCfg().CrossHostArbConfig
+}
+
+// hostMarketParams returns the market on the second host and the parcel
+// sizes of the markets on both hosts.
+func (a *crossHostArbMarketMaker) hostMarketParams() (*market, [2]uint32) {
+	a.hostMarketMtx.RLock()
+	defer a.hostMarketMtx.RUnlock()
+	return a.hostMarket, a.parcelSizes
+}
+
+// remainingLots returns the number of lots that can be traded on a host
+// before the user's bond tier trading limit is reached. The arb orders cross
+// the book, so they are counted as takers, which have double the weight of
+// makers towards the limit.
+func (a *crossHostArbMarketMaker) remainingLots(host string, parcelSize uint32) (uint64, error) {
+	userParcels, parcelLimit, err := a.clientCore.TradingLimits(host)
+	if err != nil {
+		return 0, fmt.Errorf("error getting %s trading limits: %w", host, err)
+	}
+	if userParcels >= parcelLimit {
+		return 0, nil
+	}
+	return uint64(parcelLimit-userParcels) * uint64(parcelSize) / 2, nil
+}
+
+// crossHostArbSide is the market on one of the hosts.
+type crossHostArbSide struct {
+	mkt           *market
+	book          dexOrderBook
+	buyFees       *OrderFees
+	sellFees      *OrderFees
+	remainingLots uint64
+}
+
+// arbExists checks if an arbitrage opportunity exists in either direction.
+func (a *crossHostArbMarketMaker) arbExists() (*crossHostArbOpportunity, error) {
+	hostMarket, parcelSizes := a.hostMarketParams()
+	hostMarketBuyFees, hostMarketSellFees, err := a.otherMarketOrderFees(hostMarket)
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s fees: %w", hostMarket.host, err)
+	}
+	botHostBuyFees, botHostSellFees, err := a.orderFees()
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s fees: %w", a.host, err)
+	}
+
+	botHostLots, err := a.remainingLots(a.host, parcelSizes[0])
+	if err != nil {
+		return nil, err
+	}
+	hostMarketLots, err := a.remainingLots(hostMarket.host, parcelSizes[1])
+	if err != nil {
+		return nil, err
+	}
+
+	botHost := &crossHostArbSide{a.market, a.book, botHostBuyFees, botHostSellFees, botHostLots}
+	otherHost := &crossHostArbSide{hostMarket, a.hostMarketBook, hostMarketBuyFees, hostMarketSellFees, hostMarketLots}
+
+	opp, err := a.arbExistsInDirection(botHost, otherHost)
+	if err != nil || opp != nil {
+		if opp != nil {
+			opp.sellOnBotHost = true
+		}
+		return opp, err
+	}
+
+	return a.arbExistsInDirection(otherHost, botHost)
+}
+
+// arbExistsInDirection checks if an arbitrage opportunity exists when selling
+// on one host and buying on the other. The quantity is increased one unit at a
+// time, where a unit is the least common multiple of the lot sizes on the two
+// hosts, for as long as the profit increases.
+func (a *crossHostArbMarketMaker) arbExistsInDirection(sellSide, buySide *crossHostArbSide) (*crossHostArbOpportunity, error) {
+	profitTrigger := a.cfg().ProfitTrigger
+
+	sellLotSize, buyLotSize := sellSide.mkt.lotSize.Load(), buySide.mkt.lotSize.Load()
+	unit := sellLotSize / gcd(sellLotSize, buyLotSize) * buyLotSize
+
+	var best *crossHostArbOpportunity
+	for numUnits := uint64(1); ; numUnits++ {
+		qty := numUnits * unit
+		sellLots, buyLots := qty/sellLotSize, qty/buyLotSize
+		if sellLots > sellSide.remainingLots || buyLots > buySide.remainingLots {
+			break
+		}
+
+		sellAvg, sellExtrema, sellFilled, err := sellSide.book.VWAP(sellLots, sellLotSize, false)
+		if err != nil {
+			return nil, fmt.Errorf("error calculating %s VWAP: %w", sellSide.mkt.host, err)
+		}
+		buyAvg, buyExtrema, buyFilled, err := buySide.book.VWAP(buyLots, buyLotSize, true)
+		if err != nil {
+			return nil, fmt.Errorf("error calculating %s VWAP: %w", buySide.mkt.host, err)
+		}
+		if !sellFilled || !buyFilled || sellAvg <= buyAvg {
+			break
+		}
+
+		sellFees, err := a.marketOrderFeesInUnits(sellSide.mkt, sellSide.buyFees, sellSide.sellFees, true, false, sellAvg)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %s fees: %w", sellSide.mkt.host, err)
+		}
+		buyFees, err := a.marketOrderFeesInUnits(buySide.mkt, buySide.buyFees, buySide.sellFees, false, false, buyAvg)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %s fees: %w", buySide.mkt.host, err)
+		}
+
+		revenue := calc.BaseToQuote(sellAvg, qty)
+		spent := calc.BaseToQuote(buyAvg, qty) + sellFees*sellLots + buyFees*buyLots
+		if revenue <= spent {
+			break
+		}
+		profit := revenue - spent
+		if (best != nil && profit < best.profit) || float64(profit)/float64(spent) < profitTrigger {
+			break
+		}
+
+		if !a.sufficientBalanceForDEXTrade(sellSide.mkt.baseID, sellSide.mkt.quoteID, sellLotSize, sellSide.buyFees, sellSide.sellFees, sellExtrema, qty, true) ||
+			!a.sufficientBalanceForDEXTrade(buySide.mkt.baseID, buySide.mkt.quoteID, buyLotSize, buySide.buyFees, buySide.sellFees, buyExtrema, qty, false) {
+			break
+		}
+
+		best = &crossHostArbOpportunity{
+			qty:      qty,
+			sellRate: sellExtrema,
+			buyRate:  buyExtrema,
+			profit:   profit,
+		}
+	}
+
+	if best != nil {
+		a.log.Infof("cross-host arb opportunity - sell %s on %s @ %s, buy on %s @ %s: profit: %s",
+			a.fmtBase(best.qty), sellSide.mkt.host, sellSide.mkt.fmtRate(best.sellRate),
+			buySide.mkt.host, buySide.mkt.fmtRate(best.buyRate), a.fmtQuote(best.profit))
+	}
+
+	return best, nil
+}
+
+// selfMatch checks if an order could match with any of the bot's orders
+// already booked on one of the hosts.
+func (a *crossHostArbMarketMaker) selfMatch(hostIdx int, sell bool, rate uint64) bool {
+	a.activeArbsMtx.RLock()
+	defer a.activeArbsMtx.RUnlock()
+	for _, arb := range a.activeArbs {
+		o := arb.orders[hostIdx]
+		if arb.orderFilled[hostIdx] || o.Sell == sell {
+			continue
+		}
+		if (sell && o.Rate >= rate) || (!sell && o.Rate <= rate) {
+			return true
+		}
+	}
+	return false
+}
+
+// executeArb places the orders of an arbitrage sequence on both hosts. If the
+// second order fails, the first is canceled. An entry is added to the
+// activeArbs slice if both orders are successfully placed.
+func (a *crossHostArbMarketMaker) executeArb(opp *crossHostArbOpportunity, epoch uint64) {
+	a.activeArbsMtx.RLock()
+	numArbs := len(a.activeArbs)
+	a.activeArbsMtx.RUnlock()
+	if numArbs >= int(a.cfg().MaxActiveArbs) {
+		a.log.Info("cannot execute arb because already at max arbs")
+		return
+	}
+
+	botHostRate, hostMarketRate := opp.sellRate, opp.buyRate
+	if !opp.sellOnBotHost {
+		botHostRate, hostMarketRate = opp.buyRate, opp.sellRate
+	}
+	if a.selfMatch(0, opp.sellOnBotHost, botHostRate) || a.selfMatch(1, !opp.sellOnBotHost, hostMarketRate) {
+		a.log.Info("cannot execute arb opportunity due to self-match")
+		return
+	}
+
+	hostMarket, _ := a.hostMarketParams()
+	hostMarketBuyFees, hostMarketSellFees, err := a.otherMarketOrderFees(hostMarket)
+	if err != nil {
+		a.log.Errorf("error getting %s fees: %v", hostMarket.host, err)
+		return
+	}
+
+	// Hold the lock so that order updates for the new orders are not
+	// processed before the sequence is in the activeArbs slice.
+	a.activeArbsMtx.Lock()
+	defer a.activeArbsMtx.Unlock()
+
+	botHostOrder, err := a.core.DEXTrade(botHostRate, opp.qty, opp.sellOnBotHost)
+	if err != nil {
+		a.log.Errorf("error placing %s order: %v", a.host, err)
+		return
+	}
+
+	hostMarketOrder, err := a.dexMarketTrade(hostMarket, hostMarketBuyFees, hostMarketSellFees, hostMarketRate, opp.qty, !opp.sellOnBotHost)
+	if err != nil {
+		a.log.Errorf("error placing %s order: %v", hostMarket.host, err)
+		if err := a.core.Cancel(botHostOrder.ID); err != nil {
+			a.log.Errorf("error canceling %s order: %v", a.host, err)
+		}
+		return
+	}
+
+	a.activeArbs = append(a.activeArbs, &crossHostArbSequence{
+		sellOnBotHost: opp.sellOnBotHost,
+		orders:        [2]*core.Order{botHostOrder, hostMarketOrder},
+		startEpoch:    epoch,
+	})
+}
+
+// cancelArbSequence cancels any of the orders in an arb sequence that have
+// not yet been filled.
+func (a *crossHostArbMarketMaker) cancelArbSequence(arb *crossHostArbSequence) {
+	for i, o := range arb.orders {
+		if arb.orderFilled[i] {
+			continue
+		}
+		if err := a.core.Cancel(o.ID); err != nil {
+			a.log.Errorf("failed to cancel dex order ID %s: %v", o.ID, err)
+		}
+	}
+}
+
+// handleDEXOrderUpdate is called when the DEX sends a notification that the
+// status of an order has changed.
+func (a *crossHostArbMarketMaker) handleDEXOrderUpdate(o *core.Order) {
+	if o.Status <= order.OrderStatusBooked {
+		return
+	}
+
+	a.activeArbsMtx.Lock()
+	defer a.activeArbsMtx.Unlock()
+
+	for i, arb := range a.activeArbs {
+		for j, arbOrder := range arb.orders {
+			if !bytes.Equal(arbOrder.ID, o.ID) {
+				continue
+			}
+			arb.orderFilled[j] = true
+			if arb.orderFilled[0] && arb.orderFilled[1] {
+				a.activeArbs[i] = a.activeArbs[len(a.activeArbs)-1]
+				a.activeArbs = a.activeArbs[:len(a.activeArbs)-1]
+			}
+			return
+		}
+	}
+}
+
+func (a *crossHostArbMarketMaker) tryArb(newEpoch uint64) error {
+	if !(a.checkBotHealth(newEpoch) && a.tradingLimitNotReached(newEpoch)) {
+		return nil
+	}
+
+	opp, err := a.arbExists()
+	if err != nil {
+		return err
+	}
+	if opp != nil {
+		a.executeArb(opp, newEpoch)
+	}
+
+	return nil
+}
+
+// rebalance checks if the books on the two hosts are crossed, and if so,
+// executes trades to capitalize on it.
+func (a *crossHostArbMarketMaker) rebalance(newEpoch uint64) {
+	if !a.rebalanceRunning.CompareAndSwap(false, true) {
+		return
+	}
+	defer a.rebalanceRunning.Store(false)
+	a.log.Tracef("rebalance: epoch %d", newEpoch)
+
+	epochReport := &EpochReport{EpochNum: newEpoch}
+	if err := a.tryArb(newEpoch); err != nil {
+		epochReport.setPreOrderProblems(err)
+	}
+	a.unifiedExchangeAdaptor.updateEpochReport(epochReport)
+
+	a.activeArbsMtx.Lock()
+	remainingArbs := make([]*crossHostArbSequence, 0, len(a.activeArbs))
+	for _, arb := range a.activeArbs {
+		if newEpoch-arb.startEpoch > uint64(a.cfg().NumEpochsLeaveOpen) {
+			a.cancelArbSequence(arb)
+		} else {
+			remainingArbs = append(remainingArbs, arb)
+		}
+	}
+	a.activeArbs = remainingArbs
+	a.activeArbsMtx.Unlock()
+}
+
+// handleHostNotification handles a core notification for the second host.
+// The unifiedExchangeAdaptor only handles config updates for the bot's host,
+// so the market on the second host is updated here.
+func (a *crossHostArbMarketMaker) handleHostNotification(n core.Notification) {
+	note, is := n.(*core.ServerConfigUpdateNote)
+	if !is || note.Host != a.cfg().Host {
+		return
+	}
+	a.updateHostMarket()
+}
+
+// updateHostMarket parses the market on the second host again after a
+// server config update, which may have changed its lot size, rate step or
+// parcel size, and clears its cached order fees.
+func (a *crossHostArbMarketMaker) updateHostMarket() {
+	host := a.cfg().Host
+	coreMkt, err := a.core.ExchangeMarket(host, a.baseID, a.quoteID)
+	if err != nil {
+		a.log.Errorf("Stopping bot due to error getting %s market params: %v", host, err)
+		a.kill()
+		return
+	}
+	hostMarket, err := parseMarket(host, coreMkt)
+	if err != nil {
+		a.log.Errorf("Stopping bot due to error parsing %s market: %v", host, err)
+		a.kill()
+		return
+	}
+
+	a.hostMarketMtx.Lock()
+	a.hostMarket = hostMarket
+	a.parcelSizes[1] = coreMkt.ParcelSize
+	a.hostMarketMtx.Unlock()
+
+	a.feesMtx.Lock()
+	delete(a.otherMarketFees, dexMarketID(host, a.baseID, a.quoteID))
+	a.feesMtx.Unlock()
+}
+
+func (a *crossHostArbMarketMaker) botLoop(ctx context.Context) (*sync.WaitGroup, error) {
+	host := a.cfg().Host
+
+	botHostMkt, err := a.core.ExchangeMarket(a.host, a.baseID, a.quoteID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting market: %w", err)
+	}
+	coreMkt, err := a.core.ExchangeMarket(host, a.baseID, a.quoteID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s market: %w", host, err)
+	}
+	if a.hostMarket, err = parseMarket(host, coreMkt); err != nil {
+		return nil, fmt.Errorf("error parsing %s market: %w", host, err)
+	}
+	a.parcelSizes = [2]uint32{botHostMkt.ParcelSize, coreMkt.ParcelSize}
+
+	book, bookFeed, err := a.core.SyncBook(a.host, a.baseID, a.quoteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync book: %v", err)
+	}
+	a.book = book
+
+	hostMarketBook, hostMarketBookFeed, err := a.core.SyncBook(host, a.baseID, a.quoteID)
+	if err != nil {
+		bookFeed.Close()
+		return nil, fmt.Errorf("failed to sync %s book: %v", host, err)
+	}
+	a.hostMarketBook = hostMarketBook
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer bookFeed.Close()
+		for {
+			select {
+			case ni, ok := <-bookFeed.Next():
+				if !ok {
+					a.log.Error("Stopping bot due to nil book feed.")
+					a.kill()
+					return
+				}
+				switch epoch := ni.Payload.(type) {
+				case *core.ResolvedEpoch:
+					a.rebalance(epoch.Current)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// The second host's book is kept synced by its feed, but its updates are
+	// not needed. The hosts' epochs are not aligned, so rebalancing is only
+	// done on the bot's host's epochs.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer hostMarketBookFeed.Close()
+		for {
+			select {
+			case _, ok := <-hostMarketBookFeed.Next():
+				if !ok {
+					a.log.Errorf("Stopping bot due to nil %s book feed.", host)
+					a.kill()
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		feed := a.clientCore.NotificationFeed()
+		defer feed.ReturnFeed()
+		for {
+			select {
+			case n := <-feed.C:
+				a.handleHostNotification(n)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		orderUpdates := a.core.SubscribeOrderUpdates()
+		for {
+			select {
+			case n := <-orderUpdates:
+				a.handleDEXOrderUpdate(n)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return &wg, nil
+}
+
+func newCrossHostArbMarketMaker(cfg *BotConfig, adaptorCfg *exchangeAdaptorCfg, log dex.Logger) (*crossHostArbMarketMaker, error) {
+	if cfg.CrossHostArbConfig == nil {
+		// implies bug in caller
+		return nil, fmt.Errorf("no cross-host arb config provided")
+	}
+
+	adaptor, err := newUnifiedExchangeAdaptor(adaptorCfg)
+	if err != nil {
+		return nil, fmt.Errorf("error constructing exchange adaptor: %w", err)
+	}
+
+	arb := &crossHostArbMarketMaker{
+		unifiedExchangeAdaptor: adaptor,
+		core:                   adaptor,
+		activeArbs:             make([]*crossHostArbSequence, 0),
+	}
+	adaptor.setBotLoop(arb.botLoop)
+	return arb, nil
+}
@@ -0,0 +1,346 @@
+//go:build !harness && !botlive
+
+package mm
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/order"
+)
+
+func TestCrossHostArbConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		wantErr bool
+	}{{
+		name: "ok",
+		host: "host2.com",
+	}, {
+		name:    "no host",
+		wantErr: true,
+	}, {
+		name:    "same host",
+		host:    "host.com",
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &BotConfig{
+				Host:    "host.com",
+				BaseID:  42,
+				QuoteID: 0,
+				CrossHostArbConfig: &CrossHostArbConfig{
+					Host:               tt.host,
+					ProfitTrigger:      0.01,
+					MaxActiveArbs:      1,
+					NumEpochsLeaveOpen: 10,
+				},
+			}
+			err := cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wanted error = %t, got %v", tt.wantErr, err)
+			}
+			if err == nil && cfg.requiresCEX() {
+				t.Fatalf("cross-host arb should not require a CEX")
+			}
+		})
+	}
+}
+
+// newTestCrossHostArb creates a crossHostArbMarketMaker on the DCR/BTC market
+// of host.com and host2.com. The lot size on host2.com is twice that on
+// host.com.
+func newTestCrossHostArb(t *testing.T) (*crossHostArbMarketMaker, *tCore) {
+	t.Helper()
+
+	u := mustParseAdaptorFromMarket(&core.Market{
+		BaseID:   42,
+		QuoteID:  0,
+		LotSize:  1e8,
+		RateStep: 1e2,
+	})
+	tc := u.clientCore.(*tCore)
+	tc.singleLotSellFees = tFees(1e3, 1e3, 1e3, 0)
+	tc.singleLotBuyFees = tFees(1e3, 1e3, 1e3, 0)
+	tc.parcelLimit = 10
+	u.baseDexBalances = map[uint32]int64{42: 1e10, 0: 1e10}
+	u.fiatRates.Store(map[uint32]float64{42: 20, 0: 60000})
+	u.botCfgV.Store(&BotConfig{
+		Host:    u.host,
+		BaseID:  42,
+		QuoteID: 0,
+		CrossHostArbConfig: &CrossHostArbConfig{
+			Host:               "host2.com",
+			ProfitTrigger:      0.01,
+			MaxActiveArbs:      5,
+			NumEpochsLeaveOpen: 10,
+		},
+	})
+
+	hostMarket, err := parseMarket("host2.com", &core.Market{
+		BaseID:   42,
+		QuoteID:  0,
+		LotSize:  2e8,
+		RateStep: 1e2,
+	})
+	if err != nil {
+		t.Fatalf("error parsing market: %v", err)
+	}
+
+	return &crossHostArbMarketMaker{
+		unifiedExchangeAdaptor: u,
+		core:                   u,
+		hostMarket:             hostMarket,
+		parcelSizes:            [2]uint32{1, 1},
+		activeArbs:             make([]*crossHostArbSequence, 0),
+	}, tc
+}
+
+func TestCrossHostArbExists(t *testing.T) {
+	// Fees are 1e3 atoms per lot for both the swap and the redemption.
+	const feesPerLot = 1e3
+
+	feesInQuote := func(rate, lots uint64) uint64 {
+		return lots * (calc.BaseToQuote(rate, feesPerLot) + feesPerLot)
+	}
+	profit := func(sellRate, buyRate, qty, sellLots, buyLots uint64) uint64 {
+		return calc.BaseToQuote(sellRate, qty) - calc.BaseToQuote(buyRate, qty) -
+			feesInQuote(sellRate, sellLots) - feesInQuote(buyRate, buyLots)
+	}
+
+	type books struct {
+		botHostBids   map[uint64]vwapResult
+		botHostAsks   map[uint64]vwapResult
+		otherHostBids map[uint64]vwapResult
+		otherHostAsks map[uint64]vwapResult
+	}
+
+	tests := []struct {
+		name        string
+		books       *books
+		userParcels uint32
+		exp         *crossHostArbOpportunity
+	}{{
+		name: "books not crossed",
+		books: &books{
+			botHostBids:   map[uint64]vwapResult{2: {3e5, 2.9e5}},
+			otherHostAsks: map[uint64]vwapResult{1: {3.1e5, 3.2e5}},
+			otherHostBids: map[uint64]vwapResult{1: {2.9e5, 2.8e5}},
+			botHostAsks:   map[uint64]vwapResult{2: {3e5, 3.1e5}},
+		},
+	}, {
+		name: "sell on bot host",
+		books: &books{
+			botHostBids:   map[uint64]vwapResult{2: {3.2e5, 3.1e5}},
+			otherHostAsks: map[uint64]vwapResult{1: {3e5, 3.05e5}},
+		},
+		exp: &crossHostArbOpportunity{
+			sellOnBotHost: true,
+			qty:           2e8,
+			sellRate:      3.1e5,
+			buyRate:       3.05e5,
+			profit:        profit(3.2e5, 3e5, 2e8, 2, 1),
+		},
+	}, {
+		name: "sell on other host",
+		books: &books{
+			otherHostBids: map[uint64]vwapResult{1: {3.2e5, 3.1e5}},
+			botHostAsks:   map[uint64]vwapResult{2: {3e5, 3.05e5}},
+		},
+		exp: &crossHostArbOpportunity{
+			qty:      2e8,
+			sellRate: 3.1e5,
+			buyRate:  3.05e5,
+			profit:   profit(3.2e5, 3e5, 2e8, 1, 2),
+		},
+	}, {
+		name: "two units",
+		books: &books{
+			botHostBids:   map[uint64]vwapResult{2: {3.2e5, 3.1e5}, 4: {3.2e5, 3.1e5}, 6: {3.1e5, 3e5}},
+			otherHostAsks: map[uint64]vwapResult{1: {3e5, 3.05e5}, 2: {3e5, 3.05e5}, 3: {3e5, 3.05e5}},
+		},
+		exp: &crossHostArbOpportunity{
+			sellOnBotHost: true,
+			qty:           4e8,
+			sellRate:      3.1e5,
+			buyRate:       3.05e5,
+			profit:        profit(3.2e5, 3e5, 4e8, 4, 2),
+		},
+	}, {
+		name: "limited by trading limit",
+		books: &books{
+			botHostBids:   map[uint64]vwapResult{2: {3.2e5, 3.1e5}, 4: {3.2e5, 3.1e5}},
+			otherHostAsks: map[uint64]vwapResult{1: {3e5, 3.05e5}, 2: {3e5, 3.05e5}},
+		},
+		// 6 parcels remaining on each host, which is 3 lots of taker orders.
+		userParcels: 4,
+		exp: &crossHostArbOpportunity{
+			sellOnBotHost: true,
+			qty:           2e8,
+			sellRate:      3.1e5,
+			buyRate:       3.05e5,
+			profit:        profit(3.2e5, 3e5, 2e8, 2, 1),
+		},
+	}, {
+		name: "taker orders count double",
+		books: &books{
+			botHostBids:   map[uint64]vwapResult{2: {3.2e5, 3.1e5}},
+			otherHostAsks: map[uint64]vwapResult{1: {3e5, 3.05e5}},
+		},
+		// 3 parcels remaining is only 1 lot of taker orders.
+		userParcels: 7,
+	}, {
+		name: "trading limit reached",
+		books: &books{
+			botHostBids:   map[uint64]vwapResult{2: {3.2e5, 3.1e5}},
+			otherHostAsks: map[uint64]vwapResult{1: {3e5, 3.05e5}},
+		},
+		userParcels: 10,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, tc := newTestCrossHostArb(t)
+			tc.userParcels = tt.userParcels
+			a.book = &tOrderBook{bidsVWAP: tt.books.botHostBids, asksVWAP: tt.books.botHostAsks}
+			a.hostMarketBook = &tOrderBook{bidsVWAP: tt.books.otherHostBids, asksVWAP: tt.books.otherHostAsks}
+
+			opp, err := a.arbExists()
+			if err != nil {
+				t.Fatalf("arbExists error: %v", err)
+			}
+			if tt.exp == nil {
+				if opp != nil {
+					t.Fatalf("expected no arb, got %+v", opp)
+				}
+				return
+			}
+			if opp == nil {
+				t.Fatalf("expected arb %+v, got none", tt.exp)
+			}
+			if *opp != *tt.exp {
+				t.Fatalf("expected arb %+v, got %+v", tt.exp, opp)
+			}
+		})
+	}
+}
+
+func TestCrossHostArbExecute(t *testing.T) {
+	a, tc := newTestCrossHostArb(t)
+
+	var oid order.OrderID
+	oid[0] = 1
+	tc.multiTradeResult = []*core.MultiTradeResult{{Order: &core.Order{ID: oid[:]}}}
+
+	opp := &crossHostArbOpportunity{
+		qty:      2e8,
+		sellRate: 3.1e5,
+		buyRate:  3.05e5,
+	}
+	a.executeArb(opp, 100)
+
+	if len(tc.multiTradesPlaced) != 2 {
+		t.Fatalf("expected 2 DEX trades, got %d", len(tc.multiTradesPlaced))
+	}
+	checkTrade := func(form *core.MultiTradeForm, host string, sell bool, rate uint64) {
+		t.Helper()
+		if form.Host != host || form.Base != 42 || form.Quote != 0 || form.Sell != sell {
+			t.Fatalf("wrong host, market or side for trade: %s %d-%d, sell = %t", form.Host, form.Base, form.Quote, form.Sell)
+		}
+		if len(form.Placements) != 1 || form.Placements[0].Qty != opp.qty || form.Placements[0].Rate != rate {
+			t.Fatalf("wrong placements: %+v", form.Placements)
+		}
+	}
+	checkTrade(tc.multiTradesPlaced[0], a.host, false, opp.buyRate)
+	checkTrade(tc.multiTradesPlaced[1], "host2.com", true, opp.sellRate)
+
+	if len(a.activeArbs) != 1 {
+		t.Fatalf("expected 1 active arb, got %d", len(a.activeArbs))
+	}
+
+	// An unfilled sequence is canceled after NumEpochsLeaveOpen.
+	a.book = &tOrderBook{}
+	a.hostMarketBook = &tOrderBook{}
+	a.rebalance(100 + uint64(a.cfg().NumEpochsLeaveOpen) + 1)
+	if len(a.activeArbs) != 0 {
+		t.Fatalf("expected arb to be removed")
+	}
+	if len(tc.cancelsPlaced) != 2 {
+		t.Fatalf("expected 2 cancels, got %d", len(tc.cancelsPlaced))
+	}
+}
+
+func TestCrossHostArbOrderUpdates(t *testing.T) {
+	a, _ := newTestCrossHostArb(t)
+
+	oid := func(b byte) dex.Bytes {
+		var oid order.OrderID
+		oid[0] = b
+		return oid[:]
+	}
+
+	a.activeArbs = []*crossHostArbSequence{{
+		orders: [2]*core.Order{{ID: oid(1)}, {ID: oid(2)}},
+	}, {
+		orders: [2]*core.Order{{ID: oid(3)}, {ID: oid(4)}},
+	}}
+
+	a.handleDEXOrderUpdate(&core.Order{ID: oid(2), Status: order.OrderStatusBooked})
+	if a.activeArbs[0].orderFilled[1] {
+		t.Fatalf("booked order should not be filled")
+	}
+
+	a.handleDEXOrderUpdate(&core.Order{ID: oid(2), Status: order.OrderStatusExecuted})
+	if len(a.activeArbs) != 2 {
+		t.Fatalf("arb removed before both orders were filled")
+	}
+
+	a.handleDEXOrderUpdate(&core.Order{ID: oid(1), Status: order.OrderStatusExecuted})
+	if len(a.activeArbs) != 1 {
+		t.Fatalf("expected 1 active arb, got %d", len(a.activeArbs))
+	}
+	if !bytes.Equal(a.activeArbs[0].orders[0].ID, oid(3)) {
+		t.Fatalf("wrong arb removed")
+	}
+}
+
+func TestCrossHostArbServerConfigUpdate(t *testing.T) {
+	a, tc := newTestCrossHostArb(t)
+
+	hostMktID := dexMarketID("host2.com", 42, 0)
+	a.otherMarketFees = map[string]*cachedOrderFees{
+		hostMktID: {buyFees: &OrderFees{}, sellFees: &OrderFees{}, refreshed: time.Now()},
+	}
+	tc.market = &core.Market{
+		BaseID:     42,
+		QuoteID:    0,
+		LotSize:    5e8,
+		RateStep:   1e3,
+		ParcelSize: 3,
+	}
+
+	// An update for the bot's host is handled by the unifiedExchangeAdaptor.
+	a.handleHostNotification(&core.ServerConfigUpdateNote{Host: a.host})
+	if hostMarket, _ := a.hostMarketParams(); hostMarket.lotSize.Load() != 2e8 {
+		t.Fatalf("second host's market updated for a bot host config update")
+	}
+
+	a.handleHostNotification(&core.ServerConfigUpdateNote{Host: "host2.com"})
+	hostMarket, parcelSizes := a.hostMarketParams()
+	if hostMarket.lotSize.Load() != 5e8 || hostMarket.rateStep.Load() != 1e3 {
+		t.Fatalf("wrong lot size or rate step after update: %d, %d", hostMarket.lotSize.Load(), hostMarket.rateStep.Load())
+	}
+	if parcelSizes != [2]uint32{1, 3} {
+		t.Fatalf("wrong parcel sizes after update: %v", parcelSizes)
+	}
+	if a.otherMarketFees[hostMktID] != nil {
+		t.Fatalf("cached fees not cleared")
+	}
+}
@@ -89,6 +89,7 @@ type tCore struct {
 	parcelLimit       uint32
 	exchange          *core.Exchange
 	walletStates      map[uint32]*core.WalletState
+	sendTxFee         uint64
 }
 
 func newTCore() *tCore {
@@ -203,6 +204,9 @@ func (c *tCore) Send(pw []byte, assetID uint32, value uint64, address string, su
 	})
 	return c.sendCoin, nil
 }
+func (c *tCore) EstimateSendTxFee(address string, assetID uint32, amount uint64, subtract, maxWithdraw bool) (uint64, bool, error) {
+	return c.sendTxFee, true, nil
+}
 func (c *tCore) NewDepositAddress(assetID uint32) (string, error) {
 	return c.newDepositAddress, nil
 }
@@ -279,11 +283,11 @@ type tBotCoreAdaptor struct {
 	tradeResult      *core.Order
 }
 
-func (c *tBotCoreAdaptor) DEXBalance(assetID uint32) (*BotBalance, error) {
-	if c.tCore.assetBalanceErr != nil {
-		return nil, c.tCore.assetBalanceErr
+func (c *tBotCoreAdaptor) DEXBalance(assetID uint32) *BotBalance {
+	if bal := c.balances[assetID]; bal != nil {
+		return bal
 	}
-	return c.balances[assetID], nil
+	return &BotBalance{}
 }
 
 func (c *tBotCoreAdaptor) GroupedBookedOrders() (buys, sells map[uint64][]*core.Order) {
@@ -693,10 +697,22 @@ func (c *tBotCexAdaptor) SufficientBalanceForCEXTrade(baseID, quoteID uint32, se
 
 func (c *tBotCexAdaptor) Book() (_, _ []*core.MiniOrder, _ error) { return nil, nil, nil }
 
+type tTransfer struct {
+	assetID uint32
+	amount  uint64
+	toCEX   bool
+}
+
 type tExchangeAdaptor struct {
-	dexBalances map[uint32]*BotBalance
-	cexBalances map[uint32]*BotBalance
-	cfg         *BotConfig
+	dexBalances    map[uint32]*BotBalance
+	cexBalances    map[uint32]*BotBalance
+	cfg            *BotConfig
+	autoRebalance  *AutoRebalanceConfig
+	dexAllocated   map[uint32]uint64
+	cexAllocated   map[uint32]uint64
+	inventoryDiffs []*BotInventoryDiffs
+	transfers      []*tTransfer
+	pauseErr       error
 }
 
 var _ bot = (*tExchangeAdaptor)(nil)
@@ -726,16 +742,37 @@ func (t *tExchangeAdaptor) updateConfig(cfg *BotConfig, autoRebalanceCfg *AutoRe
 	t.cfg = cfg
 	return nil
 }
-func (t *tExchangeAdaptor) updateInventory(diffs *BotInventoryDiffs) {}
-func (t *tExchangeAdaptor) timeStart() int64                         { return 0 }
+func (t *tExchangeAdaptor) updateInventory(diffs *BotInventoryDiffs) {
+	t.inventoryDiffs = append(t.inventoryDiffs, diffs)
+}
+func (t *tExchangeAdaptor) moveInventory(diffs *BotInventoryDiffs) {
+	t.inventoryDiffs = append(t.inventoryDiffs, diffs)
+}
+func (t *tExchangeAdaptor) timeStart() int64 { return 0 }
 func (t *tExchangeAdaptor) Book() (buys, sells []*core.MiniOrder, _ error) {
 	return nil, nil, nil
 }
-func (t *tExchangeAdaptor) sendStatsUpdate()                {}
-func (t *tExchangeAdaptor) withPause(func() error) error    { return nil }
+func (t *tExchangeAdaptor) sendStatsUpdate() {}
+func (t *tExchangeAdaptor) withPause(f func() error) error {
+	if t.pauseErr != nil {
+		return t.pauseErr
+	}
+	return f()
+}
+func (t *tExchangeAdaptor) resetRiskLimits()                {}
 func (t *tExchangeAdaptor) botCfg() *BotConfig              { return t.cfg }
 func (t *tExchangeAdaptor) latestEpoch() *EpochReport       { return &EpochReport{} }
 func (t *tExchangeAdaptor) latestCEXProblems() *CEXProblems { return nil }
+func (t *tExchangeAdaptor) autoRebalanceCfg() *AutoRebalanceConfig {
+	return t.autoRebalance
+}
+func (t *tExchangeAdaptor) allocatedBalances() (dex, cex map[uint32]uint64) {
+	return t.dexAllocated, t.cexAllocated
+}
+func (t *tExchangeAdaptor) transferFunds(assetID uint32, amount uint64, toCEX bool) error {
+	t.transfers = append(t.transfers, &tTransfer{assetID, amount, toCEX})
+	return nil
+}
 
 func TestAvailableBalances(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
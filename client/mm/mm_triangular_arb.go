@@ -0,0 +1,620 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package mm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/mm/libxc"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/order"
+)
+
+// TriangularArbConfig is the configuration for an arbitrage bot that trades a
+// cycle of three assets across the bot's DEX market, a second DEX market on
+// the same host, and a CEX market. The second DEX market must have the same
+// base asset as the bot's market, and the CEX market must trade the quote
+// assets of the two DEX markets.
+//
+// For example, a bot on the DCR/BTC market with a second DEX market of
+// DCR/USDT and a CEX market of BTC/USDT will look for a profitable cycle where
+// it sells DCR for BTC on the DEX, sells BTC for USDT on the CEX, and buys DCR
+// with USDT on the DEX, or the same cycle in the opposite direction. All three
+// trades are placed at the same time, using the bot's existing balances.
+type TriangularArbConfig struct {
+	// DEXMarket is the second DEX market, as [base, quote].
+	DEXMarket [2]uint32 `json:"dexMarket"`
+	// CEXMarket is the CEX market, as [base, quote].
+	CEXMarket [2]uint32 `json:"cexMarket"`
+	// ProfitTrigger is the minimum profit before a trade sequence is
+	// initiated. Range: 0 < ProfitTrigger << 1. For example, if the
+	// ProfitTrigger is 0.01 and a trade sequence would produce a 1% profit
+	// or better, a trade sequence will be initiated.
+	ProfitTrigger float64 `json:"profitTrigger"`
+	// MaxActiveArbs sets a limit on the number of active arbitrage sequences
+	// that can be open simultaneously.
+	MaxActiveArbs uint32 `json:"maxActiveArbs"`
+	// NumEpochsLeaveOpen is the number of epochs an arbitrage sequence will
+	// stay open if any of the orders were not filled.
+	NumEpochsLeaveOpen uint32 `json:"numEpochsLeaveOpen"`
+}
+
+func (c *TriangularArbConfig) copy() *TriangularArbConfig {
+	cfg := *c
+	return &cfg
+}
+
+func (c *TriangularArbConfig) validate(baseID, quoteID uint32) error {
+	if c.DEXMarket[0] != baseID {
+		return fmt.Errorf("second DEX market must have the same base asset as the bot's market")
+	}
+	if c.DEXMarket[1] == baseID || c.DEXMarket[1] == quoteID {
+		return fmt.Errorf("second DEX market must have a different quote asset than the bot's market")
+	}
+	if !(c.CEXMarket == [2]uint32{quoteID, c.DEXMarket[1]} || c.CEXMarket == [2]uint32{c.DEXMarket[1], quoteID}) {
+		return fmt.Errorf("CEX market must trade the quote assets of the DEX markets")
+	}
+
+	if c.ProfitTrigger <= 0 || c.ProfitTrigger > 1 {
+		return fmt.Errorf("profit trigger must be 0 < t <= 1, but got %v", c.ProfitTrigger)
+	}
+
+	if c.MaxActiveArbs == 0 {
+		return fmt.Errorf("must allow at least 1 active arb")
+	}
+
+	if c.NumEpochsLeaveOpen < 2 {
+		return fmt.Errorf("arbs must be left open for at least 2 epochs")
+	}
+
+	return nil
+}
+
+// triArbOpportunity describes the trades of a profitable triangular arbitrage
+// sequence. The first DEX trade sells qty of the shared base asset for the
+// proceeds asset, the CEX trade converts the proceeds asset to the cost asset,
+// and the second DEX trade buys qty of the base asset with the cost asset.
+type triArbOpportunity struct {
+	// sellOnBotMarket is true if the base asset is sold on the bot's market
+	// and bought on the second DEX market, and false if the reverse.
+	sellOnBotMarket bool
+	qty             uint64
+	sellRate        uint64
+	buyRate         uint64
+	cexSell         bool
+	cexRate         uint64
+	cexQty          uint64
+	// profit is in units of the CEX market's quote asset.
+	profit uint64
+}
+
+// triArbSequence represents an attempted triangular arbitrage sequence.
+type triArbSequence struct {
+	sellOnBotMarket bool
+	// dexOrders are the orders on the bot's market and the second DEX
+	// market, in that order.
+	dexOrders      [2]*core.Order
+	dexOrderFilled [2]bool
+	cexOrderID     string
+	cexOrderFilled bool
+	startEpoch     uint64
+}
+
+func (s *triArbSequence) complete() bool {
+	return s.cexOrderFilled && s.dexOrderFilled[0] && s.dexOrderFilled[1]
+}
+
+type triangularArbMarketMaker struct {
+	*unifiedExchangeAdaptor
+	cex              botCexAdaptor
+	core             botCoreAdaptor
+	book             dexOrderBook
+	dexMarket        *market
+	dexMarketBook    dexOrderBook
+	rebalanceRunning atomic.Bool
+
+	activeArbsMtx sync.RWMutex
+	activeArbs    []*triArbSequence
+}
+
+var _ bot = (*triangularArbMarketMaker)(nil)
+
+func (a *triangularArbMarketMaker) cfg() *TriangularArbConfig {
+	return a.botCfg().TriangularArbConfig
+}
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// arbExists checks if an arbitrage opportunity exists in either direction.
+func (a *triangularArbMarketMaker) arbExists() (*triArbOpportunity, error) {
+	for _, sellOnBotMarket := range []bool{true, false} {
+		opp, err := a.arbExistsInDirection(sellOnBotMarket)
+		if err != nil || opp != nil {
+			return opp, err
+		}
+	}
+	return nil, nil
+}
+
+// arbExistsInDirection checks if an arbitrage opportunity exists when selling
+// the base asset on one of the DEX markets and buying it on the other. The
+// quantity is increased one unit at a time, where a unit is the least common
+// multiple of the DEX markets' lot sizes, for as long as the profit increases.
+func (a *triangularArbMarketMaker) arbExistsInDirection(sellOnBotMarket bool) (*triArbOpportunity, error) {
+	cfg := a.cfg()
+
+	dexMarketBuyFees, dexMarketSellFees, err := a.otherMarketOrderFees(a.dexMarket)
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s fees: %w", a.dexMarket.name, err)
+	}
+	botMktBuyFees, botMktSellFees, err := a.orderFees()
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s fees: %w", a.name, err)
+	}
+
+	// The sell market's quote asset is the proceeds asset, and the buy
+	// market's quote asset is the cost asset.
+	sellMkt, buyMkt, sellBook, buyBook := a.market, a.dexMarket, a.book, a.dexMarketBook
+	sellMktBuyFees, sellMktSellFees, buyMktBuyFees, buyMktSellFees := botMktBuyFees, botMktSellFees, dexMarketBuyFees, dexMarketSellFees
+	if !sellOnBotMarket {
+		sellMkt, buyMkt, sellBook, buyBook = a.dexMarket, a.market, a.dexMarketBook, a.book
+		sellMktBuyFees, sellMktSellFees, buyMktBuyFees, buyMktSellFees = dexMarketBuyFees, dexMarketSellFees, botMktBuyFees, botMktSellFees
+	}
+	proceedsAsset := sellMkt.quoteID
+	cexBaseID, cexQuoteID := cfg.CEXMarket[0], cfg.CEXMarket[1]
+	// If the proceeds asset is the CEX market's base asset, it is sold on
+	// the CEX for the cost asset. Otherwise, the cost asset is bought.
+	cexSell := cexBaseID == proceedsAsset
+
+	sellLotSize, buyLotSize := sellMkt.lotSize.Load(), buyMkt.lotSize.Load()
+	unit := sellLotSize / gcd(sellLotSize, buyLotSize) * buyLotSize
+
+	var best *triArbOpportunity
+	for numUnits := uint64(1); ; numUnits++ {
+		qty := numUnits * unit
+		sellAvg, sellExtrema, sellFilled, err := sellBook.VWAP(qty/sellLotSize, sellLotSize, false)
+		if err != nil {
+			return nil, fmt.Errorf("error calculating %s VWAP: %w", sellMkt.name, err)
+		}
+		buyAvg, buyExtrema, buyFilled, err := buyBook.VWAP(qty/buyLotSize, buyLotSize, true)
+		if err != nil {
+			return nil, fmt.Errorf("error calculating %s VWAP: %w", buyMkt.name, err)
+		}
+		if !sellFilled || !buyFilled {
+			break
+		}
+
+		proceeds := calc.BaseToQuote(sellAvg, qty)
+		cost := calc.BaseToQuote(buyAvg, qty)
+
+		// The CEX trade is sized to use all of the proceeds if selling, or
+		// to buy exactly the cost if buying.
+		var cexQty uint64
+		if cexSell {
+			cexQty = proceeds
+		} else {
+			cexQty = cost
+		}
+		cexAvg, cexExtrema, cexFilled, err := a.CEX.VWAP(cexBaseID, cexQuoteID, !cexSell, cexQty)
+		if err != nil {
+			return nil, fmt.Errorf("error calculating CEX VWAP: %w", err)
+		}
+		if !cexFilled {
+			break
+		}
+
+		sellFees, err := a.marketOrderFeesInUnits(sellMkt, sellMktBuyFees, sellMktSellFees, true, false, sellAvg)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %s fees: %w", sellMkt.name, err)
+		}
+		buyFees, err := a.marketOrderFeesInUnits(buyMkt, buyMktBuyFees, buyMktSellFees, false, false, buyAvg)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %s fees: %w", buyMkt.name, err)
+		}
+		sellFees *= qty / sellLotSize
+		buyFees *= qty / buyLotSize
+
+		// The profit is in units of the CEX market's quote asset, which is
+		// the cost asset if selling on the CEX, and the proceeds asset if
+		// buying. Fees in the CEX base asset are converted at the CEX rate.
+		var revenue, spent uint64
+		if cexSell {
+			revenue = calc.BaseToQuote(cexAvg, proceeds)
+			spent = cost + buyFees + calc.BaseToQuote(cexAvg, sellFees)
+		} else {
+			revenue = proceeds
+			spent = calc.BaseToQuote(cexAvg, cost) + sellFees + calc.BaseToQuote(cexAvg, buyFees)
+		}
+		if revenue <= spent {
+			break
+		}
+		profit := revenue - spent
+		if (best != nil && profit < best.profit) || float64(profit)/float64(spent) < cfg.ProfitTrigger {
+			break
+		}
+
+		if !a.sufficientBalanceForDEXTrade(sellMkt.baseID, sellMkt.quoteID, sellLotSize, sellMktBuyFees, sellMktSellFees, sellExtrema, qty, true) ||
+			!a.sufficientBalanceForDEXTrade(buyMkt.baseID, buyMkt.quoteID, buyLotSize, buyMktBuyFees, buyMktSellFees, buyExtrema, qty, false) ||
+			!a.cex.SufficientBalanceForCEXTrade(cexBaseID, cexQuoteID, cexSell, cexExtrema, cexQty, 0, libxc.OrderTypeLimit) {
+			break
+		}
+
+		best = &triArbOpportunity{
+			sellOnBotMarket: sellOnBotMarket,
+			qty:             qty,
+			sellRate:        sellExtrema,
+			buyRate:         buyExtrema,
+			cexSell:         cexSell,
+			cexRate:         cexExtrema,
+			cexQty:          cexQty,
+			profit:          profit,
+		}
+	}
+
+	if best != nil {
+		fmtProfit := a.fmtQuote
+		if cexQuoteID != a.quoteID {
+			fmtProfit = a.dexMarket.fmtQuote
+		}
+		a.log.Infof("triangular arb opportunity - sell %s on %s @ %s, buy on %s @ %s, %s %s on CEX @ %d: profit: %s",
+			a.fmtBase(best.qty), sellMkt.name, sellMkt.fmtRate(best.sellRate), buyMkt.name, buyMkt.fmtRate(best.buyRate),
+			sellStr(best.cexSell), dex.BipIDSymbol(cexBaseID), best.cexRate, fmtProfit(best.profit))
+	}
+
+	return best, nil
+}
+
+// selfMatch checks if an order could match with any of the bot's orders
+// already booked on a DEX market.
+func (a *triangularArbMarketMaker) selfMatch(mkt *market, sell bool, rate uint64) bool {
+	a.activeArbsMtx.RLock()
+	defer a.activeArbsMtx.RUnlock()
+	for _, arb := range a.activeArbs {
+		for i, o := range arb.dexOrders {
+			if arb.dexOrderFilled[i] || o.BaseID != mkt.baseID || o.QuoteID != mkt.quoteID || o.Sell == sell {
+				continue
+			}
+			if (sell && o.Rate >= rate) || (!sell && o.Rate <= rate) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// executeArb places the three orders of an arbitrage sequence. The CEX order
+// is placed first, and if either of the DEX orders fails, the orders that
+// were already placed are canceled. An entry is added to the activeArbs slice
+// if all of the orders are successfully placed.
+func (a *triangularArbMarketMaker) executeArb(opp *triArbOpportunity, epoch uint64) {
+	cfg := a.cfg()
+
+	a.activeArbsMtx.RLock()
+	numArbs := len(a.activeArbs)
+	a.activeArbsMtx.RUnlock()
+	if numArbs >= int(cfg.MaxActiveArbs) {
+		a.log.Info("cannot execute arb because already at max arbs")
+		return
+	}
+
+	sellMkt, buyMkt := a.market, a.dexMarket
+	if !opp.sellOnBotMarket {
+		sellMkt, buyMkt = a.dexMarket, a.market
+	}
+	if a.selfMatch(sellMkt, true, opp.sellRate) || a.selfMatch(buyMkt, false, opp.buyRate) {
+		a.log.Info("cannot execute arb opportunity due to self-match")
+		return
+	}
+
+	dexMarketBuyFees, dexMarketSellFees, err := a.otherMarketOrderFees(a.dexMarket)
+	if err != nil {
+		a.log.Errorf("error getting %s fees: %v", a.dexMarket.name, err)
+		return
+	}
+
+	// Hold the lock for this entire process because updates to the cex trade
+	// may come even before the Trade function has returned, and in order to
+	// be able to process them, the new triArbSequence must already be in the
+	// activeArbs slice.
+	a.activeArbsMtx.Lock()
+	defer a.activeArbsMtx.Unlock()
+
+	cexBaseID, cexQuoteID := cfg.CEXMarket[0], cfg.CEXMarket[1]
+	cexTrade, err := a.cex.CEXTrade(a.ctx, cexBaseID, cexQuoteID, opp.cexSell, opp.cexRate, opp.cexQty, 0 /* quoteQty */, libxc.OrderTypeLimit)
+	if err != nil {
+		a.log.Errorf("error placing cex order: %v", err)
+		return
+	}
+
+	cancelCEXTrade := func() {
+		if err := a.cex.CancelTrade(a.ctx, cexBaseID, cexQuoteID, cexTrade.ID); err != nil {
+			a.log.Errorf("error canceling cex order: %v", err)
+		}
+	}
+
+	// The first order is always on the bot's market.
+	botMktRate, dexMktRate := opp.sellRate, opp.buyRate
+	if !opp.sellOnBotMarket {
+		botMktRate, dexMktRate = opp.buyRate, opp.sellRate
+	}
+
+	botMktOrder, err := a.core.DEXTrade(botMktRate, opp.qty, opp.sellOnBotMarket)
+	if err != nil {
+		a.log.Errorf("error placing %s order: %v", a.name, err)
+		cancelCEXTrade()
+		return
+	}
+
+	dexMktOrder, err := a.dexMarketTrade(a.dexMarket, dexMarketBuyFees, dexMarketSellFees, dexMktRate, opp.qty, !opp.sellOnBotMarket)
+	if err != nil {
+		a.log.Errorf("error placing %s order: %v", a.dexMarket.name, err)
+		cancelCEXTrade()
+		if err := a.core.Cancel(botMktOrder.ID); err != nil {
+			a.log.Errorf("error canceling %s order: %v", a.name, err)
+		}
+		return
+	}
+
+	a.activeArbs = append(a.activeArbs, &triArbSequence{
+		sellOnBotMarket: opp.sellOnBotMarket,
+		dexOrders:       [2]*core.Order{botMktOrder, dexMktOrder},
+		cexOrderID:      cexTrade.ID,
+		startEpoch:      epoch,
+	})
+}
+
+// cancelArbSequence cancels any of the orders in an arb sequence that have
+// not yet been filled.
+func (a *triangularArbMarketMaker) cancelArbSequence(arb *triArbSequence) {
+	if !arb.cexOrderFilled {
+		cfg := a.cfg()
+		err := a.cex.CancelTrade(a.ctx, cfg.CEXMarket[0], cfg.CEXMarket[1], arb.cexOrderID)
+		if err != nil {
+			a.log.Errorf("failed to cancel cex trade ID %s: %v", arb.cexOrderID, err)
+		}
+	}
+
+	for i, o := range arb.dexOrders {
+		if arb.dexOrderFilled[i] {
+			continue
+		}
+		if err := a.core.Cancel(o.ID); err != nil {
+			a.log.Errorf("failed to cancel dex order ID %s: %v", o.ID, err)
+		}
+	}
+}
+
+// removeActiveArb removes the active arb at index i.
+//
+// activeArbsMtx MUST be held when calling this function.
+func (a *triangularArbMarketMaker) removeActiveArb(i int) {
+	a.activeArbs[i] = a.activeArbs[len(a.activeArbs)-1]
+	a.activeArbs = a.activeArbs[:len(a.activeArbs)-1]
+}
+
+// handleCEXTradeUpdate is called when the CEX sends a notification that the
+// status of a trade has changed.
+func (a *triangularArbMarketMaker) handleCEXTradeUpdate(update *libxc.Trade) {
+	if !update.Complete {
+		return
+	}
+
+	a.activeArbsMtx.Lock()
+	defer a.activeArbsMtx.Unlock()
+
+	for i, arb := range a.activeArbs {
+		if arb.cexOrderID == update.ID {
+			arb.cexOrderFilled = true
+			if arb.complete() {
+				a.removeActiveArb(i)
+			}
+			return
+		}
+	}
+}
+
+// handleDEXOrderUpdate is called when the DEX sends a notification that the
+// status of an order has changed.
+func (a *triangularArbMarketMaker) handleDEXOrderUpdate(o *core.Order) {
+	if o.Status <= order.OrderStatusBooked {
+		return
+	}
+
+	a.activeArbsMtx.Lock()
+	defer a.activeArbsMtx.Unlock()
+
+	for i, arb := range a.activeArbs {
+		for j, dexOrder := range arb.dexOrders {
+			if bytes.Equal(dexOrder.ID, o.ID) {
+				arb.dexOrderFilled[j] = true
+				if arb.complete() {
+					a.removeActiveArb(i)
+				}
+				return
+			}
+		}
+	}
+}
+
+func (a *triangularArbMarketMaker) tryArb(newEpoch uint64) error {
+	if !(a.checkBotHealth(newEpoch) && a.tradingLimitNotReached(newEpoch)) {
+		return nil
+	}
+
+	opp, err := a.arbExists()
+	if err != nil {
+		return err
+	}
+	if opp != nil {
+		a.executeArb(opp, newEpoch)
+	}
+
+	return nil
+}
+
+// rebalance checks if there is a triangular arbitrage opportunity, and if
+// so, executes trades to capitalize on it.
+func (a *triangularArbMarketMaker) rebalance(newEpoch uint64) {
+	if !a.rebalanceRunning.CompareAndSwap(false, true) {
+		return
+	}
+	defer a.rebalanceRunning.Store(false)
+	a.log.Tracef("rebalance: epoch %d", newEpoch)
+
+	epochReport := &EpochReport{EpochNum: newEpoch}
+	if err := a.tryArb(newEpoch); err != nil {
+		epochReport.setPreOrderProblems(err)
+	}
+	a.unifiedExchangeAdaptor.updateEpochReport(epochReport)
+
+	a.activeArbsMtx.Lock()
+	remainingArbs := make([]*triArbSequence, 0, len(a.activeArbs))
+	for _, arb := range a.activeArbs {
+		if newEpoch-arb.startEpoch > uint64(a.cfg().NumEpochsLeaveOpen) {
+			a.cancelArbSequence(arb)
+		} else {
+			remainingArbs = append(remainingArbs, arb)
+		}
+	}
+	a.activeArbs = remainingArbs
+	a.activeArbsMtx.Unlock()
+}
+
+func (a *triangularArbMarketMaker) botLoop(ctx context.Context) (*sync.WaitGroup, error) {
+	cfg := a.cfg()
+
+	coreMkt, err := a.core.ExchangeMarket(a.host, cfg.DEXMarket[0], cfg.DEXMarket[1])
+	if err != nil {
+		return nil, fmt.Errorf("error getting second DEX market: %w", err)
+	}
+	if a.dexMarket, err = parseMarket(a.host, coreMkt); err != nil {
+		return nil, fmt.Errorf("error parsing second DEX market: %w", err)
+	}
+
+	book, bookFeed, err := a.core.SyncBook(a.host, a.baseID, a.quoteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync book: %v", err)
+	}
+	a.book = book
+
+	dexMarketBook, dexMarketBookFeed, err := a.core.SyncBook(a.host, cfg.DEXMarket[0], cfg.DEXMarket[1])
+	if err != nil {
+		bookFeed.Close()
+		return nil, fmt.Errorf("failed to sync %s book: %v", a.dexMarket.name, err)
+	}
+	a.dexMarketBook = dexMarketBook
+
+	err = a.cex.SubscribeMarket(a.ctx, cfg.CEXMarket[0], cfg.CEXMarket[1])
+	if err != nil {
+		bookFeed.Close()
+		dexMarketBookFeed.Close()
+		return nil, fmt.Errorf("failed to subscribe to cex market: %v", err)
+	}
+
+	tradeUpdates := a.cex.SubscribeTradeUpdates()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer bookFeed.Close()
+		for {
+			select {
+			case ni, ok := <-bookFeed.Next():
+				if !ok {
+					a.log.Error("Stopping bot due to nil book feed.")
+					a.kill()
+					return
+				}
+				switch epoch := ni.Payload.(type) {
+				case *core.ResolvedEpoch:
+					a.rebalance(epoch.Current)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// The second market's book is kept synced by its feed, but its updates
+	// are not needed.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer dexMarketBookFeed.Close()
+		for {
+			select {
+			case _, ok := <-dexMarketBookFeed.Next():
+				if !ok {
+					a.log.Errorf("Stopping bot due to nil %s book feed.", a.dexMarket.name)
+					a.kill()
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case update := <-tradeUpdates:
+				a.handleCEXTradeUpdate(update)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		orderUpdates := a.core.SubscribeOrderUpdates()
+		for {
+			select {
+			case n := <-orderUpdates:
+				a.handleDEXOrderUpdate(n)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return &wg, nil
+}
+
+func newTriangularArbMarketMaker(cfg *BotConfig, adaptorCfg *exchangeAdaptorCfg, log dex.Logger) (*triangularArbMarketMaker, error) {
+	if cfg.TriangularArbConfig == nil {
+		// implies bug in caller
+		return nil, fmt.Errorf("no triangular arb config provided")
+	}
+
+	adaptor, err := newUnifiedExchangeAdaptor(adaptorCfg)
+	if err != nil {
+		return nil, fmt.Errorf("error constructing exchange adaptor: %w", err)
+	}
+
+	triArb := &triangularArbMarketMaker{
+		unifiedExchangeAdaptor: adaptor,
+		cex:                    adaptor,
+		core:                   adaptor,
+		activeArbs:             make([]*triArbSequence, 0),
+	}
+	adaptor.setBotLoop(triArb.botLoop)
+	return triArb, nil
+}
@@ -0,0 +1,352 @@
+//go:build !harness && !botlive
+
+package mm
+
+import (
+	"testing"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/mm/libxc"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/order"
+)
+
+func TestTriangularArbConfigValidate(t *testing.T) {
+	const baseID, quoteID = 42, 0
+
+	tests := []struct {
+		name      string
+		dexMarket [2]uint32
+		cexMarket [2]uint32
+		wantErr   bool
+	}{{
+		name:      "ok",
+		dexMarket: [2]uint32{42, 2},
+		cexMarket: [2]uint32{0, 2},
+	}, {
+		name:      "ok, reversed cex market",
+		dexMarket: [2]uint32{42, 2},
+		cexMarket: [2]uint32{2, 0},
+	}, {
+		name:      "different base asset",
+		dexMarket: [2]uint32{60, 2},
+		cexMarket: [2]uint32{0, 2},
+		wantErr:   true,
+	}, {
+		name:      "same market",
+		dexMarket: [2]uint32{42, 0},
+		cexMarket: [2]uint32{0, 2},
+		wantErr:   true,
+	}, {
+		name:      "wrong cex market",
+		dexMarket: [2]uint32{42, 2},
+		cexMarket: [2]uint32{42, 2},
+		wantErr:   true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &BotConfig{
+				BaseID:  baseID,
+				QuoteID: quoteID,
+				TriangularArbConfig: &TriangularArbConfig{
+					DEXMarket:          tt.dexMarket,
+					CEXMarket:          tt.cexMarket,
+					ProfitTrigger:      0.01,
+					MaxActiveArbs:      1,
+					NumEpochsLeaveOpen: 10,
+				},
+			}
+			err := cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wanted error = %t, got %v", tt.wantErr, err)
+			}
+			if err == nil && !cfg.requiresCEX() {
+				t.Fatalf("triangular arb should require a CEX")
+			}
+		})
+	}
+}
+
+// newTestTriangularArb creates a triangularArbMarketMaker on the DCR/BTC
+// market, with a second DEX market of DCR/LTC and a CEX market of BTC/LTC.
+// The lot size of the second market is twice that of the bot's market.
+func newTestTriangularArb(t *testing.T) (*triangularArbMarketMaker, *tCore, *tCEX) {
+	t.Helper()
+
+	const lotSize = 1e8
+	u := mustParseAdaptorFromMarket(&core.Market{
+		BaseID:   42,
+		QuoteID:  0,
+		LotSize:  lotSize,
+		RateStep: 1e2,
+	})
+	tc := u.clientCore.(*tCore)
+	tc.singleLotSellFees = tFees(1e3, 1e3, 1e3, 0)
+	tc.singleLotBuyFees = tFees(1e3, 1e3, 1e3, 0)
+	cex := newTCEX()
+	u.CEX = cex
+	u.baseDexBalances = map[uint32]int64{42: 1e10, 0: 1e10, 2: 1e10}
+	u.baseCexBalances = map[uint32]int64{0: 1e10, 2: 1e10}
+	u.fiatRates.Store(map[uint32]float64{42: 20, 0: 60000, 2: 80})
+	u.botCfgV.Store(&BotConfig{
+		Host:    u.host,
+		BaseID:  42,
+		QuoteID: 0,
+		TriangularArbConfig: &TriangularArbConfig{
+			DEXMarket:          [2]uint32{42, 2},
+			CEXMarket:          [2]uint32{0, 2},
+			ProfitTrigger:      0.01,
+			MaxActiveArbs:      5,
+			NumEpochsLeaveOpen: 10,
+		},
+	})
+
+	return &triangularArbMarketMaker{
+		unifiedExchangeAdaptor: u,
+		cex:                    u,
+		core:                   u,
+		dexMarket: mustParseMarket(&core.Market{
+			BaseID:   42,
+			QuoteID:  2,
+			LotSize:  2 * lotSize,
+			RateStep: 1e2,
+		}),
+		activeArbs: make([]*triArbSequence, 0),
+	}, tc, cex
+}
+
+func TestTriangularArbExists(t *testing.T) {
+	// Fees are 1e3 atoms per lot for both the swap and the redemption.
+	const feesPerLot = 1e3
+
+	// Selling 2 DCR for BTC on DCR/BTC, selling the BTC for LTC on BTC/LTC,
+	// and buying 2 DCR with LTC on DCR/LTC.
+	const sellRate, sellExtrema = 3e5, 2.9e5
+	const buyRate, buyExtrema = 2.8e7, 2.9e7
+	const cexRate, cexExtrema = 1e10, 0.99e10
+	btcProceeds := calc.BaseToQuote(sellRate, 2e8)
+	ltcCost := calc.BaseToQuote(buyRate, 2e8)
+	btcFees := 2 * (calc.BaseToQuote(sellRate, feesPerLot) + feesPerLot)
+	ltcFees := calc.BaseToQuote(buyRate, feesPerLot) + feesPerLot
+	profit := calc.BaseToQuote(cexRate, btcProceeds) - ltcCost - ltcFees - calc.BaseToQuote(cexRate, btcFees)
+
+	// The reverse, buying the BTC needed for DCR/BTC with LTC on the CEX.
+	const revSellRate, revSellExtrema = 3.2e7, 3.1e7
+	const revBuyRate, revBuyExtrema = 3e5, 3.1e5
+	const revCexRate, revCexExtrema = 1e10, 1.01e10
+	btcCost := calc.BaseToQuote(revBuyRate, 2e8)
+	ltcProceeds := calc.BaseToQuote(revSellRate, 2e8)
+	revLtcFees := calc.BaseToQuote(revSellRate, feesPerLot) + feesPerLot
+	revBtcFees := 2 * (calc.BaseToQuote(revBuyRate, feesPerLot) + feesPerLot)
+	revProfit := ltcProceeds - calc.BaseToQuote(revCexRate, btcCost) - revLtcFees - calc.BaseToQuote(revCexRate, revBtcFees)
+
+	type books struct {
+		botBids  map[uint64]vwapResult
+		botAsks  map[uint64]vwapResult
+		dex2Bids map[uint64]vwapResult
+		dex2Asks map[uint64]vwapResult
+		cexBids  map[uint64]vwapResult
+		cexAsks  map[uint64]vwapResult
+	}
+
+	tests := []struct {
+		name  string
+		books *books
+		exp   *triArbOpportunity
+	}{{
+		name: "no arb",
+		books: &books{
+			botBids:  map[uint64]vwapResult{2: {sellRate, sellExtrema}},
+			dex2Asks: map[uint64]vwapResult{1: {3e7, 3.1e7}},
+			cexBids:  map[uint64]vwapResult{btcProceeds: {cexRate, cexExtrema}},
+		},
+	}, {
+		name: "sell on bot market",
+		books: &books{
+			botBids:  map[uint64]vwapResult{2: {sellRate, sellExtrema}},
+			dex2Asks: map[uint64]vwapResult{1: {buyRate, buyExtrema}},
+			cexBids:  map[uint64]vwapResult{btcProceeds: {cexRate, cexExtrema}},
+		},
+		exp: &triArbOpportunity{
+			sellOnBotMarket: true,
+			qty:             2e8,
+			sellRate:        sellExtrema,
+			buyRate:         buyExtrema,
+			cexSell:         true,
+			cexRate:         cexExtrema,
+			cexQty:          btcProceeds,
+			profit:          profit,
+		},
+	}, {
+		name: "sell on second market",
+		books: &books{
+			dex2Bids: map[uint64]vwapResult{1: {revSellRate, revSellExtrema}},
+			botAsks:  map[uint64]vwapResult{2: {revBuyRate, revBuyExtrema}},
+			cexAsks:  map[uint64]vwapResult{btcCost: {revCexRate, revCexExtrema}},
+		},
+		exp: &triArbOpportunity{
+			sellOnBotMarket: false,
+			qty:             2e8,
+			sellRate:        revSellExtrema,
+			buyRate:         revBuyExtrema,
+			cexSell:         false,
+			cexRate:         revCexExtrema,
+			cexQty:          btcCost,
+			profit:          revProfit,
+		},
+	}, {
+		name: "second unit less profitable",
+		books: &books{
+			botBids:  map[uint64]vwapResult{2: {sellRate, sellExtrema}, 4: {2.9e5, 2.8e5}},
+			dex2Asks: map[uint64]vwapResult{1: {buyRate, buyExtrema}, 2: {2.8e7, 2.9e7}},
+			cexBids:  map[uint64]vwapResult{btcProceeds: {cexRate, cexExtrema}, calc.BaseToQuote(2.9e5, 4e8): {cexRate, cexExtrema}},
+		},
+		exp: &triArbOpportunity{
+			sellOnBotMarket: true,
+			qty:             2e8,
+			sellRate:        sellExtrema,
+			buyRate:         buyExtrema,
+			cexSell:         true,
+			cexRate:         cexExtrema,
+			cexQty:          btcProceeds,
+			profit:          profit,
+		},
+	}, {
+		name: "cex book not deep enough",
+		books: &books{
+			botBids:  map[uint64]vwapResult{2: {sellRate, sellExtrema}},
+			dex2Asks: map[uint64]vwapResult{1: {buyRate, buyExtrema}},
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _, cex := newTestTriangularArb(t)
+			a.book = &tOrderBook{bidsVWAP: tt.books.botBids, asksVWAP: tt.books.botAsks}
+			a.dexMarketBook = &tOrderBook{bidsVWAP: tt.books.dex2Bids, asksVWAP: tt.books.dex2Asks}
+			if tt.books.cexBids != nil {
+				cex.bidsVWAP = tt.books.cexBids
+			}
+			if tt.books.cexAsks != nil {
+				cex.asksVWAP = tt.books.cexAsks
+			}
+
+			opp, err := a.arbExists()
+			if err != nil {
+				t.Fatalf("arbExists error: %v", err)
+			}
+			if tt.exp == nil {
+				if opp != nil {
+					t.Fatalf("expected no arb, got %+v", opp)
+				}
+				return
+			}
+			if opp == nil {
+				t.Fatalf("expected arb %+v, got none", tt.exp)
+			}
+			if *opp != *tt.exp {
+				t.Fatalf("expected arb %+v, got %+v", tt.exp, opp)
+			}
+		})
+	}
+}
+
+func TestTriangularArbExecute(t *testing.T) {
+	a, tc, cex := newTestTriangularArb(t)
+
+	var oid order.OrderID
+	oid[0] = 1
+	tc.multiTradeResult = []*core.MultiTradeResult{{Order: &core.Order{ID: oid[:]}}}
+	cex.tradeID = "abc"
+	a.SubscribeTradeUpdates()
+
+	opp := &triArbOpportunity{
+		sellOnBotMarket: false,
+		qty:             2e8,
+		sellRate:        3.1e7,
+		buyRate:         3.1e5,
+		cexSell:         false,
+		cexRate:         1.01e10,
+		cexQty:          6e5,
+	}
+	a.executeArb(opp, 100)
+
+	if len(tc.multiTradesPlaced) != 2 {
+		t.Fatalf("expected 2 DEX trades, got %d", len(tc.multiTradesPlaced))
+	}
+	checkTrade := func(form *core.MultiTradeForm, quoteID uint32, sell bool, rate uint64) {
+		t.Helper()
+		if form.Base != 42 || form.Quote != quoteID || form.Sell != sell {
+			t.Fatalf("wrong market or side for trade: %d-%d, sell = %t", form.Base, form.Quote, form.Sell)
+		}
+		if len(form.Placements) != 1 || form.Placements[0].Qty != opp.qty || form.Placements[0].Rate != rate {
+			t.Fatalf("wrong placements: %+v", form.Placements)
+		}
+	}
+	checkTrade(tc.multiTradesPlaced[0], 0, false, opp.buyRate)
+	checkTrade(tc.multiTradesPlaced[1], 2, true, opp.sellRate)
+
+	if cex.lastTrade == nil {
+		t.Fatalf("no CEX trade placed")
+	}
+	if cex.lastTrade.BaseID != 0 || cex.lastTrade.QuoteID != 2 || cex.lastTrade.Sell || cex.lastTrade.Rate != opp.cexRate || cex.lastTrade.Qty != opp.cexQty {
+		t.Fatalf("wrong CEX trade: %+v", cex.lastTrade)
+	}
+
+	if len(a.activeArbs) != 1 {
+		t.Fatalf("expected 1 active arb, got %d", len(a.activeArbs))
+	}
+
+	// An unfilled sequence is canceled after NumEpochsLeaveOpen.
+	a.book = &tOrderBook{}
+	a.dexMarketBook = &tOrderBook{}
+	a.rebalance(100 + uint64(a.cfg().NumEpochsLeaveOpen) + 1)
+	if len(a.activeArbs) != 0 {
+		t.Fatalf("expected arb to be removed")
+	}
+	if len(tc.cancelsPlaced) != 2 {
+		t.Fatalf("expected 2 DEX cancels, got %d", len(tc.cancelsPlaced))
+	}
+	if len(cex.cancelledTrades) != 1 || cex.cancelledTrades[0] != "abc" {
+		t.Fatalf("expected CEX trade to be canceled")
+	}
+}
+
+func TestTriangularArbTradeUpdates(t *testing.T) {
+	a, _, _ := newTestTriangularArb(t)
+
+	oid := func(b byte) dex.Bytes {
+		var oid order.OrderID
+		oid[0] = b
+		return oid[:]
+	}
+
+	a.activeArbs = []*triArbSequence{{
+		dexOrders:  [2]*core.Order{{ID: oid(1)}, {ID: oid(2)}},
+		cexOrderID: "abc",
+	}, {
+		dexOrders:  [2]*core.Order{{ID: oid(3)}, {ID: oid(4)}},
+		cexOrderID: "def",
+	}}
+
+	a.handleDEXOrderUpdate(&core.Order{ID: oid(2), Status: order.OrderStatusBooked})
+	if a.activeArbs[0].dexOrderFilled[1] {
+		t.Fatalf("booked order should not be filled")
+	}
+
+	a.handleDEXOrderUpdate(&core.Order{ID: oid(2), Status: order.OrderStatusExecuted})
+	a.handleCEXTradeUpdate(&libxc.Trade{ID: "abc", Complete: true})
+	if len(a.activeArbs) != 2 {
+		t.Fatalf("arb removed before all orders were filled")
+	}
+
+	a.handleDEXOrderUpdate(&core.Order{ID: oid(1), Status: order.OrderStatusExecuted})
+	if len(a.activeArbs) != 1 {
+		t.Fatalf("expected 1 active arb, got %d", len(a.activeArbs))
+	}
+	if a.activeArbs[0].cexOrderID != "def" {
+		t.Fatalf("wrong arb removed")
+	}
+}
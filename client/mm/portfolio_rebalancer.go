@@ -0,0 +1,339 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package mm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex"
+)
+
+// PortfolioRebalanceConfig is the configuration for the portfolio rebalancer,
+// which moves funds between the allocations of the running bots.
+type PortfolioRebalanceConfig struct {
+	// MinTransfer is the minimum amount of an asset that will be moved,
+	// keyed by asset ID. Surpluses and shortages smaller than this are
+	// ignored.
+	MinTransfer map[uint32]uint64 `json:"minTransfer"`
+	// FeeMultiplier is the minimum size of a deposit or withdrawal as a
+	// multiple of its estimated network fee. Moves between bots on the same
+	// DEX or CEX do not have a fee.
+	FeeMultiplier float64 `json:"feeMultiplier"`
+}
+
+func (c *PortfolioRebalanceConfig) validate() error {
+	if c.FeeMultiplier < 1 {
+		return fmt.Errorf("fee multiplier must be at least 1, got %v", c.FeeMultiplier)
+	}
+	return nil
+}
+
+// PortfolioTransfer is a move of funds proposed by the portfolio rebalancer.
+// If From and To are different bots, the funds are moved from one bot's
+// balance to the other's on the From side. If FromCEX and ToCEX are
+// different, the To bot then deposits the funds to or withdraws them from
+// the CEX.
+type PortfolioTransfer struct {
+	AssetID uint32         `json:"assetID"`
+	Amount  uint64         `json:"amount"`
+	From    MarketWithHost `json:"from"`
+	FromCEX bool           `json:"fromCEX"`
+	To      MarketWithHost `json:"to"`
+	ToCEX   bool           `json:"toCEX"`
+	// Fees is the estimated network fee of a deposit or withdrawal, in units
+	// of the asset.
+	Fees uint64 `json:"fees"`
+}
+
+// portfolioSlot is a bot's holdings of an asset on the DEX or the CEX.
+type portfolioSlot struct {
+	mkt     MarketWithHost
+	cex     bool
+	cexName string
+	// delta is the bot's balance minus its allocation. A positive delta is
+	// a surplus, and a negative delta is a shortage.
+	delta     int64
+	available uint64
+}
+
+// portfolioSlots returns the holdings of an asset by all of the running bots
+// that use it. The DEX and CEX balances of bots that do their own auto-
+// rebalancing are combined into a single slot on the DEX, so that the
+// portfolio rebalancer does not interfere with the bot's own distribution.
+func portfolioSlots(assetID uint32, bots map[MarketWithHost]*runningBot) []*portfolioSlot {
+	slots := make([]*portfolioSlot, 0, len(bots)*2)
+	for mkt, rb := range bots {
+		if _, found := rb.assets()[assetID]; !found {
+			continue
+		}
+
+		dexAlloc, cexAlloc := rb.allocatedBalances()
+		dexBal := rb.DEXBalance(assetID)
+		dexTotal := dexBal.Available + dexBal.Locked + dexBal.Pending
+		dexSlot := &portfolioSlot{
+			mkt:       mkt,
+			cexName:   rb.cexName(),
+			delta:     int64(dexTotal) - int64(dexAlloc[assetID]),
+			available: dexBal.Available,
+		}
+		slots = append(slots, dexSlot)
+
+		if rb.cexName() == "" {
+			continue
+		}
+
+		cexBal := rb.CEXBalance(assetID)
+		cexTotal := cexBal.Available + cexBal.Locked + cexBal.Pending + cexBal.Reserved
+		cexDelta := int64(cexTotal) - int64(cexAlloc[assetID])
+		if rb.autoRebalanceCfg() != nil {
+			dexSlot.delta += cexDelta
+			dexSlot.cexName = ""
+			continue
+		}
+		slots = append(slots, &portfolioSlot{
+			mkt:       mkt,
+			cex:       true,
+			cexName:   rb.cexName(),
+			delta:     cexDelta,
+			available: cexBal.Available,
+		})
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].mkt != slots[j].mkt {
+			return slots[i].mkt.String() < slots[j].mkt.String()
+		}
+		return !slots[i].cex
+	})
+
+	return slots
+}
+
+// planPortfolioTransfers matches the surpluses of an asset with the shortages.
+// Moves between bots on the same DEX or CEX are planned first, because they
+// are free. The remaining shortages are covered by deposits and withdrawals
+// if they are large enough relative to the estimated network fee.
+func planPortfolioTransfers(assetID uint32, slots []*portfolioSlot, cfg *PortfolioRebalanceConfig, estimateFee func(assetID uint32, amt uint64) (uint64, error), log dex.Logger) []*PortfolioTransfer {
+	minTransfer := cfg.MinTransfer[assetID]
+	if minTransfer == 0 {
+		minTransfer = 1
+	}
+
+	movable := func(s *portfolioSlot) uint64 {
+		if s.delta <= 0 {
+			return 0
+		}
+		return min(uint64(s.delta), s.available)
+	}
+
+	var transfers []*PortfolioTransfer
+	move := func(from, to *portfolioSlot, amt, fees uint64) {
+		from.delta -= int64(amt)
+		from.available -= amt
+		to.delta += int64(amt)
+		transfers = append(transfers, &PortfolioTransfer{
+			AssetID: assetID,
+			Amount:  amt,
+			From:    from.mkt,
+			FromCEX: from.cex,
+			To:      to.mkt,
+			ToCEX:   to.cex,
+			Fees:    fees,
+		})
+	}
+
+	for _, to := range slots {
+		for _, from := range slots {
+			if to.delta > -int64(minTransfer) {
+				break
+			}
+			if from == to || from.cex != to.cex || (from.cex && from.cexName != to.cexName) {
+				continue
+			}
+			if amt := min(uint64(-to.delta), movable(from)); amt >= minTransfer {
+				move(from, to, amt, 0)
+			}
+		}
+	}
+
+	for _, to := range slots {
+		for _, from := range slots {
+			if to.delta > -int64(minTransfer) {
+				break
+			}
+			// The funds are moved on the From side, so a withdrawal can only
+			// be made to a bot on the same CEX.
+			if from.cex == to.cex || (from.cex && from.cexName != to.cexName) {
+				continue
+			}
+			amt := min(uint64(-to.delta), movable(from))
+			if amt < minTransfer {
+				continue
+			}
+			fees, err := estimateFee(assetID, amt)
+			if err != nil {
+				log.Errorf("Error estimating %s transfer fees: %v", dex.BipIDSymbol(assetID), err)
+				continue
+			}
+			if float64(amt) < float64(fees)*cfg.FeeMultiplier {
+				log.Debugf("Not transferring %d %s because the estimated fee of %d is too high",
+					amt, dex.BipIDSymbol(assetID), fees)
+				continue
+			}
+			move(from, to, amt, fees)
+		}
+	}
+
+	return transfers
+}
+
+// portfolioTransfers returns the transfers that would move the surpluses of
+// the running bots to the bots with shortages.
+func (m *MarketMaker) portfolioTransfers(cfg *PortfolioRebalanceConfig) ([]*PortfolioTransfer, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	runningBots := m.runningBotsLookup()
+	assets := make(map[uint32]bool)
+	for _, rb := range runningBots {
+		rb.refreshAllPendingEvents(m.ctx)
+		for assetID := range rb.assets() {
+			assets[assetID] = true
+		}
+	}
+
+	assetIDs := make([]uint32, 0, len(assets))
+	for assetID := range assets {
+		assetIDs = append(assetIDs, assetID)
+	}
+	sort.Slice(assetIDs, func(i, j int) bool { return assetIDs[i] < assetIDs[j] })
+
+	var transfers []*PortfolioTransfer
+	for _, assetID := range assetIDs {
+		slots := portfolioSlots(assetID, runningBots)
+		transfers = append(transfers, planPortfolioTransfers(assetID, slots, cfg, m.estimateTransferFee, m.log)...)
+	}
+
+	return transfers, nil
+}
+
+// estimateTransferFee estimates the network fee of sending an asset to or
+// from a CEX. The fees of tokens are converted to units of the token using
+// the fiat rates.
+func (m *MarketMaker) estimateTransferFee(assetID uint32, amt uint64) (uint64, error) {
+	fee, _, err := m.core.EstimateSendTxFee("", assetID, amt, false, false)
+	if err != nil {
+		return 0, err
+	}
+
+	feeID := feeAssetID(assetID)
+	if feeID == assetID {
+		return fee, nil
+	}
+
+	fiatRates := m.core.FiatConversionRates()
+	assetRate, feeRate := fiatRates[assetID], fiatRates[feeID]
+	if assetRate == 0 || feeRate == 0 {
+		return 0, fmt.Errorf("no fiat rate for %s or %s", dex.BipIDSymbol(assetID), dex.BipIDSymbol(feeID))
+	}
+	ui, err := asset.UnitInfo(assetID)
+	if err != nil {
+		return 0, err
+	}
+	feeUI, err := asset.UnitInfo(feeID)
+	if err != nil {
+		return 0, err
+	}
+	conv := float64(fee) / float64(feeUI.Conventional.ConversionFactor) * feeRate / assetRate
+	return uint64(math.Ceil(conv * float64(ui.Conventional.ConversionFactor))), nil
+}
+
+// ProposePortfolioRebalance returns the transfers that would move the
+// surpluses of the running bots' allocations to bots with shortages. A bot's
+// surplus or shortage of an asset on the DEX or CEX is the difference between
+// its balance and the amount allocated to it.
+func (m *MarketMaker) ProposePortfolioRebalance(cfg *PortfolioRebalanceConfig) ([]*PortfolioTransfer, error) {
+	return m.portfolioTransfers(cfg)
+}
+
+// ExecutePortfolioRebalance performs the transfers proposed by
+// ProposePortfolioRebalance. Moves between bots change the bots' balances but
+// not their allocations, and are recorded as inventory updates in both bots'
+// event logs, and deposits and withdrawals are recorded
+// in the event log of the bot that makes them. The transfers that were
+// performed are returned.
+func (m *MarketMaker) ExecutePortfolioRebalance(cfg *PortfolioRebalanceConfig) ([]*PortfolioTransfer, error) {
+	m.startUpdateMtx.Lock()
+	defer m.startUpdateMtx.Unlock()
+
+	transfers, err := m.portfolioTransfers(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	runningBots := m.runningBotsLookup()
+	// moveBetweenBots pauses both bots and moves the funds from one to the
+	// other, so that either both balances change or neither does. Both bots
+	// are resumed by withPause, and a bot that can't be resumed is stopped.
+	moveBetweenBots := func(t *PortfolioTransfer) error {
+		from, to := runningBots[t.From], runningBots[t.To]
+		diffs := func(diff int64) *BotInventoryDiffs {
+			diffs := &BotInventoryDiffs{DEX: map[uint32]int64{}, CEX: map[uint32]int64{}}
+			if t.FromCEX {
+				diffs.CEX[t.AssetID] = diff
+			} else {
+				diffs.DEX[t.AssetID] = diff
+			}
+			return diffs
+		}
+		var toErr error
+		err := from.withPause(func() error {
+			toErr = to.withPause(func() error {
+				from.moveInventory(diffs(-int64(t.Amount)))
+				to.moveInventory(diffs(int64(t.Amount)))
+				return nil
+			})
+			return toErr
+		})
+		if err == nil {
+			return nil
+		}
+		// If the From bot resumed, its error is the To bot's error.
+		if errors.Is(err, errBotNotResumed) && err != toErr {
+			m.log.Errorf("Stopping %s bot after a failed portfolio rebalance: %v", t.From, err)
+			m.StopBot(&t.From)
+		}
+		if errors.Is(toErr, errBotNotResumed) {
+			m.log.Errorf("Stopping %s bot after a failed portfolio rebalance: %v", t.To, toErr)
+			m.StopBot(&t.To)
+		}
+		return fmt.Errorf("inventory move error: %w", err)
+	}
+
+	for i, t := range transfers {
+		from, to := runningBots[t.From], runningBots[t.To]
+		if from == nil || to == nil {
+			return transfers[:i], fmt.Errorf("bot stopped during portfolio rebalance")
+		}
+
+		if t.From != t.To {
+			if err := moveBetweenBots(t); err != nil {
+				return transfers[:i], err
+			}
+		}
+
+		if t.FromCEX != t.ToCEX {
+			if err := to.transferFunds(t.AssetID, t.Amount, t.ToCEX); err != nil {
+				return transfers[:i], fmt.Errorf("error transferring %s for %s: %w", dex.BipIDSymbol(t.AssetID), t.To, err)
+			}
+		}
+	}
+
+	return transfers, nil
+}
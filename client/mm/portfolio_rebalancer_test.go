@@ -0,0 +1,249 @@
+//go:build !harness && !botlive
+
+package mm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/mm/libxc"
+	"decred.org/dcrdex/dex"
+	"github.com/davecgh/go-spew/spew"
+)
+
+// newTestPortfolio creates a MarketMaker with two bots running on the same
+// CEX. The DCR/BTC bot has a DCR surplus of 2e5 on the DEX. The DCR/ETH bot
+// has a DCR shortage of 1e5 on the DEX and 2e5 on the CEX.
+func newTestPortfolio(t *testing.T) (*MarketMaker, *tCore, *tExchangeAdaptor, *tExchangeAdaptor) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tCore := newTCore()
+	tCore.sendTxFee = 1e4
+
+	dcrBtc := MarketWithHost{Host: "dex.com", BaseID: 42, QuoteID: 0}
+	dcrEth := MarketWithHost{Host: "dex.com", BaseID: 42, QuoteID: 60}
+
+	botA := &tExchangeAdaptor{
+		dexBalances:  map[uint32]*BotBalance{42: {Available: 4e5, Locked: 1e5}},
+		dexAllocated: map[uint32]uint64{42: 3e5},
+		cfg:          &BotConfig{Host: "dex.com", BaseID: 42, QuoteID: 0, CEXName: libxc.Binance},
+	}
+	botB := &tExchangeAdaptor{
+		dexBalances:  map[uint32]*BotBalance{42: {Available: 1e5}},
+		cexBalances:  map[uint32]*BotBalance{42: {Available: 1e5}},
+		dexAllocated: map[uint32]uint64{42: 2e5},
+		cexAllocated: map[uint32]uint64{42: 3e5},
+		cfg:          &BotConfig{Host: "dex.com", BaseID: 42, QuoteID: 60, CEXName: libxc.Binance},
+	}
+
+	mm := &MarketMaker{
+		ctx:  ctx,
+		log:  tLogger,
+		core: tCore,
+		runningBots: map[MarketWithHost]*runningBot{
+			dcrBtc: {bot: botA, cm: dex.NewConnectionMaster(botA)},
+			dcrEth: {bot: botB, cm: dex.NewConnectionMaster(botB)},
+		},
+	}
+
+	return mm, tCore, botA, botB
+}
+
+func TestProposePortfolioRebalance(t *testing.T) {
+	dcrBtc := MarketWithHost{Host: "dex.com", BaseID: 42, QuoteID: 0}
+	dcrEth := MarketWithHost{Host: "dex.com", BaseID: 42, QuoteID: 60}
+
+	tests := []struct {
+		name          string
+		cfg           *PortfolioRebalanceConfig
+		autoRebalance bool
+		exp           []*PortfolioTransfer
+		wantErr       bool
+	}{{
+		name: "move and deposit",
+		cfg:  &PortfolioRebalanceConfig{FeeMultiplier: 5},
+		exp: []*PortfolioTransfer{{
+			AssetID: 42,
+			Amount:  1e5,
+			From:    dcrBtc,
+			To:      dcrEth,
+		}, {
+			AssetID: 42,
+			Amount:  1e5,
+			From:    dcrBtc,
+			To:      dcrEth,
+			ToCEX:   true,
+			Fees:    1e4,
+		}},
+	}, {
+		name: "deposit too small for fee",
+		cfg:  &PortfolioRebalanceConfig{FeeMultiplier: 20},
+		exp: []*PortfolioTransfer{{
+			AssetID: 42,
+			Amount:  1e5,
+			From:    dcrBtc,
+			To:      dcrEth,
+		}},
+	}, {
+		name: "min transfer",
+		cfg: &PortfolioRebalanceConfig{
+			FeeMultiplier: 5,
+			MinTransfer:   map[uint32]uint64{42: 2e5},
+		},
+		exp: []*PortfolioTransfer{{
+			AssetID: 42,
+			Amount:  2e5,
+			From:    dcrBtc,
+			To:      dcrEth,
+			ToCEX:   true,
+			Fees:    1e4,
+		}},
+	}, {
+		// The DCR/ETH bot's DEX and CEX balances are combined, so its
+		// total shortage of 3e5 is covered by the surplus on the DEX.
+		name:          "auto-rebalancing bot",
+		cfg:           &PortfolioRebalanceConfig{FeeMultiplier: 5},
+		autoRebalance: true,
+		exp: []*PortfolioTransfer{{
+			AssetID: 42,
+			Amount:  2e5,
+			From:    dcrBtc,
+			To:      dcrEth,
+		}},
+	}, {
+		name:    "invalid fee multiplier",
+		cfg:     &PortfolioRebalanceConfig{FeeMultiplier: 0.5},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mm, _, _, botB := newTestPortfolio(t)
+			if tt.autoRebalance {
+				botB.autoRebalance = &AutoRebalanceConfig{}
+			}
+
+			transfers, err := mm.ProposePortfolioRebalance(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wanted error = %t, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(transfers, tt.exp) {
+				t.Fatalf("wrong transfers. wanted %s, got %s", spew.Sdump(tt.exp), spew.Sdump(transfers))
+			}
+		})
+	}
+}
+
+func TestExecutePortfolioRebalance(t *testing.T) {
+	mm, _, botA, botB := newTestPortfolio(t)
+
+	transfers, err := mm.ExecutePortfolioRebalance(&PortfolioRebalanceConfig{FeeMultiplier: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(transfers) != 2 {
+		t.Fatalf("expected 2 transfers, got %d", len(transfers))
+	}
+
+	// Both transfers move DCR from the DCR/BTC bot to the DCR/ETH bot on the
+	// DEX, and the DCR/ETH bot then deposits the second one.
+	expA := []*BotInventoryDiffs{
+		{DEX: map[uint32]int64{42: -1e5}, CEX: map[uint32]int64{}},
+		{DEX: map[uint32]int64{42: -1e5}, CEX: map[uint32]int64{}},
+	}
+	expB := []*BotInventoryDiffs{
+		{DEX: map[uint32]int64{42: 1e5}, CEX: map[uint32]int64{}},
+		{DEX: map[uint32]int64{42: 1e5}, CEX: map[uint32]int64{}},
+	}
+	if !reflect.DeepEqual(botA.inventoryDiffs, expA) {
+		t.Fatalf("wrong inventory diffs for DCR/BTC bot: %+v", botA.inventoryDiffs)
+	}
+	if !reflect.DeepEqual(botB.inventoryDiffs, expB) {
+		t.Fatalf("wrong inventory diffs for DCR/ETH bot: %+v", botB.inventoryDiffs)
+	}
+
+	if len(botA.transfers) != 0 {
+		t.Fatalf("DCR/BTC bot should not have transferred funds")
+	}
+	expTransfers := []*tTransfer{{assetID: 42, amount: 1e5, toCEX: true}}
+	if !reflect.DeepEqual(botB.transfers, expTransfers) {
+		t.Fatalf("wrong transfers for DCR/ETH bot: %+v", botB.transfers)
+	}
+}
+
+func TestExecutePortfolioRebalanceMoveFailure(t *testing.T) {
+	mm, _, botA, botB := newTestPortfolio(t)
+
+	// The receiving bot can't be paused, so neither bot's balance changes.
+	botB.pauseErr = errors.New("test error")
+	transfers, err := mm.ExecutePortfolioRebalance(&PortfolioRebalanceConfig{FeeMultiplier: 5})
+	if err == nil {
+		t.Fatalf("no error for failed move")
+	}
+	if len(transfers) != 0 {
+		t.Fatalf("expected no transfers, got %d", len(transfers))
+	}
+	if len(botA.inventoryDiffs) != 0 || len(botB.inventoryDiffs) != 0 {
+		t.Fatalf("inventory changed by a failed move: %+v, %+v", botA.inventoryDiffs, botB.inventoryDiffs)
+	}
+	if len(botB.transfers) != 0 {
+		t.Fatalf("funds transferred after a failed move")
+	}
+}
+
+// tPausableBot is a tExchangeAdaptor that is paused and resumed like a running
+// bot.
+type tPausableBot struct {
+	*tExchangeAdaptor
+	u *unifiedExchangeAdaptor
+}
+
+func (b *tPausableBot) withPause(f func() error) error {
+	return b.u.withPause(f)
+}
+
+func TestExecutePortfolioRebalanceResume(t *testing.T) {
+	mm, _, botA, botB := newTestPortfolio(t)
+
+	u := mustParseAdaptorFromMarket(&core.Market{
+		BaseID:   42,
+		QuoteID:  0,
+		LotSize:  1e8,
+		RateStep: 1e2,
+		EpochLen: 100,
+	})
+	u.ctx, u.kill = context.WithCancel(context.Background())
+	t.Cleanup(u.kill)
+	u.botCfgV.Store(&BotConfig{Host: u.host, BaseID: 42, QuoteID: 0})
+	if err := u.runBotLoop(u.ctx); err != nil {
+		t.Fatalf("runBotLoop error: %v", err)
+	}
+	dcrBtc := MarketWithHost{Host: "dex.com", BaseID: 42, QuoteID: 0}
+	rb := &tPausableBot{tExchangeAdaptor: botA, u: u}
+	mm.runningBots[dcrBtc] = &runningBot{bot: rb, cm: dex.NewConnectionMaster(rb)}
+
+	// The DCR/BTC bot is paused, but the DCR/ETH bot can't be. The DCR/BTC
+	// bot is resumed and keeps running.
+	botB.pauseErr = errors.New("test error")
+	if _, err := mm.ExecutePortfolioRebalance(&PortfolioRebalanceConfig{FeeMultiplier: 5}); err == nil {
+		t.Fatalf("no error for failed move")
+	}
+	if !u.botLoop.On() {
+		t.Fatalf("bot loop not resumed after a failed move")
+	}
+	if mm.runningBots[dcrBtc] == nil {
+		t.Fatalf("bot stopped after a failed move")
+	}
+	if len(botA.inventoryDiffs) != 0 {
+		t.Fatalf("inventory changed by a failed move: %+v", botA.inventoryDiffs)
+	}
+}
@@ -0,0 +1,789 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package mm
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/orderbook"
+	"decred.org/dcrdex/dex"
+)
+
+// A market recording is a gzip stream that starts with a header made up of
+// recordingMagic, a version byte, and the length prefixed JSON encoding of
+// the RecordingInfo. The header is followed by a series of records, each of
+// which starts with a recordType byte and the varint encoded number of
+// milliseconds since the previous record. The rest of the record is made up
+// of uvarint encoded integers, single byte booleans, and length prefixed
+// byte slices, as described for each recordType below.
+const (
+	recordingMagic   = "DEXREC"
+	recordingVersion = 0
+
+	// maxTokenLen is the maximum length of an order token in a recording.
+	maxTokenLen = 32
+)
+
+type recordType byte
+
+const (
+	// recordDEXBook is a full snapshot of the DEX order book. The payload is
+	// the buys followed by the sells. Each side is the number of orders
+	// followed by the token, rate and quantity of each order.
+	recordDEXBook recordType = iota + 1
+	// recordBookOrder is an order added to the DEX book. The payload is the
+	// token, sell flag, rate and quantity of the order.
+	recordBookOrder
+	// recordUnbookOrder is an order removed from the DEX book. The payload is
+	// the token of the order.
+	recordUnbookOrder
+	// recordUpdateRemaining is a change in the remaining quantity of an order
+	// on the DEX book. The payload is the token and the new quantity.
+	recordUpdateRemaining
+	// recordEpochReport is the match summary of a DEX epoch. The payload is
+	// the epoch index and the number of matches followed by the rate,
+	// quantity, stamp and sell flag of each match.
+	recordEpochReport
+	// recordCEXBook is a full snapshot of a CEX order book. The payload is
+	// the base and quote asset IDs, followed by the buy levels and the sell
+	// levels. Each side is the number of levels followed by the rate and
+	// quantity of each level.
+	recordCEXBook
+	// recordCEXDepth is a set of changes to the levels of a CEX order book
+	// since the previous record for the market. The payload is encoded the
+	// same as recordCEXBook. A zero quantity removes the level.
+	recordCEXDepth
+)
+
+// RecordingInfo describes the markets that a recording was made on.
+type RecordingInfo struct {
+	Host    string `json:"host"`
+	BaseID  uint32 `json:"baseID"`
+	QuoteID uint32 `json:"quoteID"`
+	CEXName string `json:"cexName,omitempty"`
+	// Start is the time the recording was started, in milliseconds.
+	Start int64 `json:"start"`
+}
+
+// recordingWriter encodes market updates to a recording.
+type recordingWriter struct {
+	gz    *gzip.Writer
+	buf   []byte
+	stamp int64
+}
+
+func newRecordingWriter(w io.Writer, info *RecordingInfo) (*recordingWriter, error) {
+	infoB, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding recording info: %w", err)
+	}
+	b := append([]byte(recordingMagic), recordingVersion)
+	b = binary.AppendUvarint(b, uint64(len(infoB)))
+	b = append(b, infoB...)
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(b); err != nil {
+		return nil, fmt.Errorf("error writing recording header: %w", err)
+	}
+	return &recordingWriter{
+		gz:    gz,
+		stamp: info.Start,
+	}, nil
+}
+
+// start begins a new record in the buffer.
+func (w *recordingWriter) start(typ recordType, stamp int64) {
+	w.buf = append(w.buf[:0], byte(typ))
+	w.buf = binary.AppendVarint(w.buf, stamp-w.stamp)
+	w.stamp = stamp
+}
+
+func (w *recordingWriter) uvarint(v uint64) {
+	w.buf = binary.AppendUvarint(w.buf, v)
+}
+
+func (w *recordingWriter) bool(b bool) {
+	if b {
+		w.buf = append(w.buf, 1)
+	} else {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+// token encodes an order token, which is the hex encoding of the first bytes
+// of the order ID.
+func (w *recordingWriter) token(tkn string) error {
+	b, err := hex.DecodeString(tkn)
+	if err != nil || len(b) > maxTokenLen {
+		return fmt.Errorf("invalid order token %q", tkn)
+	}
+	w.uvarint(uint64(len(b)))
+	w.buf = append(w.buf, b...)
+	return nil
+}
+
+// commit writes the buffered record.
+func (w *recordingWriter) commit() error {
+	_, err := w.gz.Write(w.buf)
+	return err
+}
+
+func (w *recordingWriter) writeDEXBook(stamp int64, book *core.OrderBook) error {
+	w.start(recordDEXBook, stamp)
+	for _, ords := range [][]*core.MiniOrder{book.Buys, book.Sells} {
+		w.uvarint(uint64(len(ords)))
+		for _, o := range ords {
+			if err := w.token(o.Token); err != nil {
+				return err
+			}
+			w.uvarint(o.MsgRate)
+			w.uvarint(o.QtyAtomic)
+		}
+	}
+	return w.commit()
+}
+
+func (w *recordingWriter) writeBookOrder(stamp int64, o *core.MiniOrder) error {
+	w.start(recordBookOrder, stamp)
+	if err := w.token(o.Token); err != nil {
+		return err
+	}
+	w.bool(o.Sell)
+	w.uvarint(o.MsgRate)
+	w.uvarint(o.QtyAtomic)
+	return w.commit()
+}
+
+func (w *recordingWriter) writeUnbookOrder(stamp int64, tkn string) error {
+	w.start(recordUnbookOrder, stamp)
+	if err := w.token(tkn); err != nil {
+		return err
+	}
+	return w.commit()
+}
+
+func (w *recordingWriter) writeUpdateRemaining(stamp int64, u *core.RemainderUpdate) error {
+	w.start(recordUpdateRemaining, stamp)
+	if err := w.token(u.Token); err != nil {
+		return err
+	}
+	w.uvarint(u.QtyAtomic)
+	return w.commit()
+}
+
+func (w *recordingWriter) writeEpochReport(stamp int64, r *core.EpochMatchSummaryPayload) error {
+	w.start(recordEpochReport, stamp)
+	w.uvarint(r.Epoch)
+	w.uvarint(uint64(len(r.MatchSummaries)))
+	for _, m := range r.MatchSummaries {
+		w.uvarint(m.Rate)
+		w.uvarint(m.Qty)
+		w.uvarint(m.Stamp)
+		w.bool(m.Sell)
+	}
+	return w.commit()
+}
+
+// writeCEXLevels writes a recordCEXBook or recordCEXDepth record.
+func (w *recordingWriter) writeCEXLevels(typ recordType, stamp int64, baseID, quoteID uint32, buys, sells []*cexLevel) error {
+	w.start(typ, stamp)
+	w.uvarint(uint64(baseID))
+	w.uvarint(uint64(quoteID))
+	for _, lvls := range [][]*cexLevel{buys, sells} {
+		w.uvarint(uint64(len(lvls)))
+		for _, l := range lvls {
+			w.uvarint(l.rate)
+			w.uvarint(l.qty)
+		}
+	}
+	return w.commit()
+}
+
+// flush makes sure that everything written so far can be read back, even if
+// the recording is never closed.
+func (w *recordingWriter) flush() error {
+	return w.gz.Flush()
+}
+
+// close writes the gzip footer. The underlying writer is not closed.
+func (w *recordingWriter) close() error {
+	return w.gz.Close()
+}
+
+// cexDepth is the aggregated quantity at each rate of a CEX order book.
+type cexDepth struct {
+	buys, sells map[uint64]uint64
+}
+
+func newCEXDepth() *cexDepth {
+	return &cexDepth{
+		buys:  make(map[uint64]uint64),
+		sells: make(map[uint64]uint64),
+	}
+}
+
+func cexDepthFromBook(buys, sells []*core.MiniOrder) *cexDepth {
+	d := newCEXDepth()
+	for _, o := range buys {
+		d.buys[o.MsgRate] += o.QtyAtomic
+	}
+	for _, o := range sells {
+		d.sells[o.MsgRate] += o.QtyAtomic
+	}
+	return d
+}
+
+// levels returns the levels on one side of the book, best rate first.
+func (d *cexDepth) levels(sell bool) []*cexLevel {
+	side := d.buys
+	if sell {
+		side = d.sells
+	}
+	lvls := make([]*cexLevel, 0, len(side))
+	for rate, qty := range side {
+		lvls = append(lvls, &cexLevel{rate: rate, qty: qty})
+	}
+	sort.Slice(lvls, func(i, j int) bool {
+		if sell {
+			return lvls[i].rate < lvls[j].rate
+		}
+		return lvls[i].rate > lvls[j].rate
+	})
+	return lvls
+}
+
+// diff returns the levels on each side of the book that are different in
+// newer. Levels that are not in newer have a zero quantity.
+func (d *cexDepth) diff(newer *cexDepth) (buys, sells []*cexLevel) {
+	sideDiff := func(old, new map[uint64]uint64) []*cexLevel {
+		lvls := make([]*cexLevel, 0)
+		for rate, qty := range new {
+			if old[rate] != qty {
+				lvls = append(lvls, &cexLevel{rate: rate, qty: qty})
+			}
+		}
+		for rate := range old {
+			if _, found := new[rate]; !found {
+				lvls = append(lvls, &cexLevel{rate: rate})
+			}
+		}
+		sort.Slice(lvls, func(i, j int) bool { return lvls[i].rate < lvls[j].rate })
+		return lvls
+	}
+	return sideDiff(d.buys, newer.buys), sideDiff(d.sells, newer.sells)
+}
+
+// apply updates the depth with levels from a recordCEXDepth record.
+func (d *cexDepth) apply(buys, sells []*cexLevel) {
+	applySide := func(side map[uint64]uint64, lvls []*cexLevel) {
+		for _, l := range lvls {
+			if l.qty == 0 {
+				delete(side, l.rate)
+			} else {
+				side[l.rate] = l.qty
+			}
+		}
+	}
+	applySide(d.buys, buys)
+	applySide(d.sells, sells)
+}
+
+// snapshot generates a CEXBookSnapshot from the depth.
+func (d *cexDepth) snapshot(baseID, quoteID uint32) *CEXBookSnapshot {
+	miniOrders := func(sell bool) []*core.MiniOrder {
+		lvls := d.levels(sell)
+		ords := make([]*core.MiniOrder, 0, len(lvls))
+		for _, l := range lvls {
+			ords = append(ords, &core.MiniOrder{
+				MsgRate:   l.rate,
+				QtyAtomic: l.qty,
+				Sell:      sell,
+			})
+		}
+		return ords
+	}
+	return &CEXBookSnapshot{
+		BaseID:  baseID,
+		QuoteID: quoteID,
+		Buys:    miniOrders(false),
+		Sells:   miniOrders(true),
+	}
+}
+
+// RecordingReader reads the updates in a market recording back in the order
+// they were recorded. RecordingReader implements ReplaySource, so recordings
+// can be used to backtest bots. Only the atomic rates and quantities of the
+// orders are populated.
+type RecordingReader struct {
+	gz       *gzip.Reader
+	r        *bufio.Reader
+	info     *RecordingInfo
+	stamp    int64
+	cexBooks map[[2]uint32]*cexDepth
+	// err is set if an error is encountered while decoding a record.
+	err error
+}
+
+var _ ReplaySource = (*RecordingReader)(nil)
+
+// NewRecordingReader reads the header of a market recording and returns a
+// RecordingReader.
+func NewRecordingReader(r io.Reader) (*RecordingReader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error opening recording: %w", err)
+	}
+	rr := &RecordingReader{
+		gz:       gz,
+		r:        bufio.NewReader(gz),
+		cexBooks: make(map[[2]uint32]*cexDepth),
+	}
+
+	magic := make([]byte, len(recordingMagic)+1)
+	if _, err := io.ReadFull(rr.r, magic); err != nil {
+		return nil, fmt.Errorf("error reading recording header: %w", err)
+	}
+	if string(magic[:len(recordingMagic)]) != recordingMagic {
+		return nil, errors.New("not a market recording")
+	}
+	if ver := magic[len(recordingMagic)]; ver != recordingVersion {
+		return nil, fmt.Errorf("unknown recording version %d", ver)
+	}
+	infoB := rr.bytes(1 << 16)
+	if rr.err != nil {
+		return nil, fmt.Errorf("error reading recording info: %w", rr.err)
+	}
+	if err := json.Unmarshal(infoB, &rr.info); err != nil {
+		return nil, fmt.Errorf("error decoding recording info: %w", err)
+	}
+	rr.stamp = rr.info.Start
+
+	return rr, nil
+}
+
+// Info returns the description of the recorded markets.
+func (r *RecordingReader) Info() *RecordingInfo {
+	return r.info
+}
+
+// Close closes the gzip reader. The underlying reader is not closed.
+func (r *RecordingReader) Close() error {
+	return r.gz.Close()
+}
+
+func (r *RecordingReader) setErr(err error) {
+	if r.err == nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		r.err = err
+	}
+}
+
+func (r *RecordingReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		r.setErr(err)
+	}
+	return v
+}
+
+func (r *RecordingReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(r.r)
+	if err != nil {
+		r.setErr(err)
+	}
+	return v
+}
+
+func (r *RecordingReader) bool() bool {
+	if r.err != nil {
+		return false
+	}
+	b, err := r.r.ReadByte()
+	if err != nil {
+		r.setErr(err)
+	}
+	return b == 1
+}
+
+// bytes reads a length prefixed byte slice.
+func (r *RecordingReader) bytes(maxLen int) []byte {
+	n := r.uvarint()
+	if r.err != nil {
+		return nil
+	}
+	if n > uint64(maxLen) {
+		r.setErr(fmt.Errorf("length %d exceeds maximum of %d", n, maxLen))
+		return nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		r.setErr(err)
+		return nil
+	}
+	return b
+}
+
+func (r *RecordingReader) token() string {
+	return hex.EncodeToString(r.bytes(maxTokenLen))
+}
+
+// count reads the number of items that follow. The returned value is only
+// meant to be used as a capacity hint, so that a corrupt count won't cause a
+// huge allocation.
+func (r *RecordingReader) count() (n uint64, capHint int) {
+	n = r.uvarint()
+	return n, int(min(n, 1024))
+}
+
+func (r *RecordingReader) readDEXBook() *core.OrderBook {
+	side := func(sell bool) []*core.MiniOrder {
+		n, capHint := r.count()
+		ords := make([]*core.MiniOrder, 0, capHint)
+		for i := uint64(0); i < n && r.err == nil; i++ {
+			ords = append(ords, &core.MiniOrder{
+				Token:     r.token(),
+				MsgRate:   r.uvarint(),
+				QtyAtomic: r.uvarint(),
+				Sell:      sell,
+			})
+		}
+		return ords
+	}
+	buys := side(false)
+	sells := side(true)
+	return &core.OrderBook{
+		Buys:  buys,
+		Sells: sells,
+	}
+}
+
+func (r *RecordingReader) readEpochReport() *core.EpochMatchSummaryPayload {
+	epoch := r.uvarint()
+	n, capHint := r.count()
+	matches := make([]*orderbook.MatchSummary, 0, capHint)
+	for i := uint64(0); i < n && r.err == nil; i++ {
+		matches = append(matches, &orderbook.MatchSummary{
+			Rate:  r.uvarint(),
+			Qty:   r.uvarint(),
+			Stamp: r.uvarint(),
+			Sell:  r.bool(),
+		})
+	}
+	return &core.EpochMatchSummaryPayload{
+		Epoch:          epoch,
+		MatchSummaries: matches,
+	}
+}
+
+func (r *RecordingReader) readCEXLevels() (baseID, quoteID uint32, buys, sells []*cexLevel) {
+	baseID = uint32(r.uvarint())
+	quoteID = uint32(r.uvarint())
+	side := func() []*cexLevel {
+		n, capHint := r.count()
+		lvls := make([]*cexLevel, 0, capHint)
+		for i := uint64(0); i < n && r.err == nil; i++ {
+			lvls = append(lvls, &cexLevel{
+				rate: r.uvarint(),
+				qty:  r.uvarint(),
+			})
+		}
+		return lvls
+	}
+	buys = side()
+	sells = side()
+	return
+}
+
+// Next returns the next recorded update. CEX depth changes are applied to
+// the previous snapshot of the CEX book, and returned as a full snapshot.
+// io.EOF is returned when there are no more updates. A recording that was
+// not closed properly can be read up to the last flush.
+func (r *RecordingReader) Next() (*ReplayEvent, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	b, err := r.r.ReadByte()
+	if err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = io.EOF
+		}
+		r.err = err
+		return nil, err
+	}
+
+	r.stamp += r.varint()
+	e := &ReplayEvent{Stamp: r.stamp}
+
+	switch typ := recordType(b); typ {
+	case recordDEXBook:
+		e.DEXBook = r.readDEXBook()
+	case recordBookOrder:
+		e.BookOrder = &core.MiniOrder{
+			Token:     r.token(),
+			Sell:      r.bool(),
+			MsgRate:   r.uvarint(),
+			QtyAtomic: r.uvarint(),
+		}
+	case recordUnbookOrder:
+		e.UnbookOrder = &core.MiniOrder{Token: r.token()}
+	case recordUpdateRemaining:
+		e.UpdateRemaining = &core.RemainderUpdate{
+			Token:     r.token(),
+			QtyAtomic: r.uvarint(),
+		}
+	case recordEpochReport:
+		e.EpochReport = r.readEpochReport()
+	case recordCEXBook, recordCEXDepth:
+		baseID, quoteID, buys, sells := r.readCEXLevels()
+		if r.err != nil {
+			break
+		}
+		mkt := [2]uint32{baseID, quoteID}
+		depth := r.cexBooks[mkt]
+		if typ == recordCEXBook {
+			depth = newCEXDepth()
+			r.cexBooks[mkt] = depth
+		} else if depth == nil {
+			r.err = fmt.Errorf("CEX depth update for market %d-%d before snapshot", baseID, quoteID)
+			break
+		}
+		depth.apply(buys, sells)
+		e.CEXBook = depth.snapshot(baseID, quoteID)
+	default:
+		r.err = fmt.Errorf("unknown record type %d", b)
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return e, nil
+}
+
+// cexBookSource is the part of the libxc.CEX interface used by the Recorder.
+type cexBookSource interface {
+	SubscribeMarket(ctx context.Context, baseID, quoteID uint32) error
+	UnsubscribeMarket(baseID, quoteID uint32) error
+	Book(baseID, quoteID uint32) (buys, sells []*core.MiniOrder, _ error)
+}
+
+// RecorderConfig is the configuration for a Recorder.
+type RecorderConfig struct {
+	// Host, BaseID and QuoteID identify the DEX market to record. The CEX
+	// market with the same assets is recorded.
+	Host    string `json:"host"`
+	BaseID  uint32 `json:"baseID"`
+	QuoteID uint32 `json:"quoteID"`
+	// CEXName is the name of the CEX to record. If empty, only the DEX market
+	// is recorded.
+	CEXName string `json:"cexName"`
+	// CEXPollInterval is how often the CEX order book is checked for
+	// changes. Defaults to 1 second.
+	CEXPollInterval time.Duration `json:"cexPollInterval"`
+	// CEXSnapshotInterval is how often a full snapshot of the CEX order book
+	// is recorded. In between, only changes are recorded. Defaults to 5
+	// minutes.
+	CEXSnapshotInterval time.Duration `json:"cexSnapshotInterval"`
+}
+
+const (
+	defaultCEXPollInterval     = time.Second
+	defaultCEXSnapshotInterval = 5 * time.Minute
+)
+
+// Recorder records the updates to a DEX market's order book and epoch match
+// summaries, and optionally the order book of the same market on a CEX. The
+// recording can be read back using a RecordingReader.
+type Recorder struct {
+	cfg  *RecorderConfig
+	core clientCore
+	cex  cexBookSource
+	w    io.Writer
+	log  dex.Logger
+
+	// The following fields are only accessed from Run.
+	rw              *recordingWriter
+	cexDepth        *cexDepth
+	lastCEXSnapshot time.Time
+}
+
+// NewRecorder is the constructor for a Recorder. The recording is written to
+// w. cex must be connected, and must be non-nil if cfg.CEXName is set.
+func NewRecorder(c clientCore, cex cexBookSource, cfg *RecorderConfig, w io.Writer, log dex.Logger) (*Recorder, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("no host specified")
+	}
+	if (cfg.CEXName == "") != (cex == nil) {
+		return nil, errors.New("a CEX must be provided if and only if a CEX name is specified")
+	}
+	cfgCopy := *cfg
+	if cfgCopy.CEXPollInterval <= 0 {
+		cfgCopy.CEXPollInterval = defaultCEXPollInterval
+	}
+	if cfgCopy.CEXSnapshotInterval <= 0 {
+		cfgCopy.CEXSnapshotInterval = defaultCEXSnapshotInterval
+	}
+	return &Recorder{
+		cfg:  &cfgCopy,
+		core: c,
+		cex:  cex,
+		w:    w,
+		log:  log,
+	}, nil
+}
+
+// Run records the markets until the context is canceled or an error is
+// encountered.
+func (r *Recorder) Run(ctx context.Context) error {
+	cfg := r.cfg
+	_, feed, err := r.core.SyncBook(cfg.Host, cfg.BaseID, cfg.QuoteID)
+	if err != nil {
+		return fmt.Errorf("error syncing book: %w", err)
+	}
+	defer feed.Close()
+
+	r.rw, err = newRecordingWriter(r.w, &RecordingInfo{
+		Host:    cfg.Host,
+		BaseID:  cfg.BaseID,
+		QuoteID: cfg.QuoteID,
+		CEXName: cfg.CEXName,
+		Start:   time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.rw.close(); err != nil {
+			r.log.Errorf("Error closing recording: %v", err)
+		}
+	}()
+
+	var cexPoll <-chan time.Time
+	if r.cex != nil {
+		if err := r.cex.SubscribeMarket(ctx, cfg.BaseID, cfg.QuoteID); err != nil {
+			return fmt.Errorf("error subscribing to %s market: %w", cfg.CEXName, err)
+		}
+		defer func() {
+			if err := r.cex.UnsubscribeMarket(cfg.BaseID, cfg.QuoteID); err != nil {
+				r.log.Errorf("Error unsubscribing from %s market: %v", cfg.CEXName, err)
+			}
+		}()
+		ticker := time.NewTicker(cfg.CEXPollInterval)
+		defer ticker.Stop()
+		cexPoll = ticker.C
+	}
+
+	for {
+		select {
+		case u, ok := <-feed.Next():
+			if !ok {
+				return errors.New("book feed closed")
+			}
+			if err := r.recordBookUpdate(u); err != nil {
+				return fmt.Errorf("error recording DEX book update: %w", err)
+			}
+		case <-cexPoll:
+			if err := r.recordCEXBook(); err != nil {
+				return fmt.Errorf("error recording CEX book: %w", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (r *Recorder) recordBookUpdate(u *core.BookUpdate) error {
+	stamp := time.Now().UnixMilli()
+	switch u.Action {
+	case core.FreshBookAction:
+		mob, ok := u.Payload.(*core.MarketOrderBook)
+		if !ok {
+			r.log.Errorf("Unexpected payload type %T for %s", u.Payload, u.Action)
+			return nil
+		}
+		if err := r.rw.writeDEXBook(stamp, mob.Book); err != nil {
+			return err
+		}
+		return r.rw.flush()
+	case core.BookOrderAction:
+		o, ok := u.Payload.(*core.MiniOrder)
+		if !ok {
+			r.log.Errorf("Unexpected payload type %T for %s", u.Payload, u.Action)
+			return nil
+		}
+		return r.rw.writeBookOrder(stamp, o)
+	case core.UnbookOrderAction:
+		o, ok := u.Payload.(*core.MiniOrder)
+		if !ok {
+			r.log.Errorf("Unexpected payload type %T for %s", u.Payload, u.Action)
+			return nil
+		}
+		return r.rw.writeUnbookOrder(stamp, o.Token)
+	case core.UpdateRemainingAction:
+		ru, ok := u.Payload.(*core.RemainderUpdate)
+		if !ok {
+			r.log.Errorf("Unexpected payload type %T for %s", u.Payload, u.Action)
+			return nil
+		}
+		return r.rw.writeUpdateRemaining(stamp, ru)
+	case core.EpochMatchSummary:
+		report, ok := u.Payload.(*core.EpochMatchSummaryPayload)
+		if !ok {
+			r.log.Errorf("Unexpected payload type %T for %s", u.Payload, u.Action)
+			return nil
+		}
+		if err := r.rw.writeEpochReport(stamp, report); err != nil {
+			return err
+		}
+		return r.rw.flush()
+	}
+	return nil
+}
+
+// recordCEXBook records a full snapshot of the CEX book if one is due, or
+// the changes since the last time the book was checked.
+func (r *Recorder) recordCEXBook() error {
+	cfg := r.cfg
+	buys, sells, err := r.cex.Book(cfg.BaseID, cfg.QuoteID)
+	if err != nil {
+		r.log.Debugf("Error getting %s book: %v", cfg.CEXName, err)
+		return nil
+	}
+	now := time.Now()
+	depth := cexDepthFromBook(buys, sells)
+
+	if r.cexDepth == nil || now.Sub(r.lastCEXSnapshot) >= cfg.CEXSnapshotInterval {
+		err = r.rw.writeCEXLevels(recordCEXBook, now.UnixMilli(), cfg.BaseID, cfg.QuoteID, depth.levels(false), depth.levels(true))
+		r.lastCEXSnapshot = now
+	} else {
+		buyDiff, sellDiff := r.cexDepth.diff(depth)
+		if len(buyDiff) == 0 && len(sellDiff) == 0 {
+			return nil
+		}
+		err = r.rw.writeCEXLevels(recordCEXDepth, now.UnixMilli(), cfg.BaseID, cfg.QuoteID, buyDiff, sellDiff)
+	}
+	if err != nil {
+		return err
+	}
+	r.cexDepth = depth
+	return r.rw.flush()
+}
@@ -0,0 +1,229 @@
+package mm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/orderbook"
+	"github.com/davecgh/go-spew/spew"
+)
+
+type tCEXBook struct {
+	mtx          sync.Mutex
+	buys, sells  []*core.MiniOrder
+	subscribed   bool
+	unsubscribed bool
+}
+
+func (c *tCEXBook) SubscribeMarket(ctx context.Context, baseID, quoteID uint32) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.subscribed = true
+	return nil
+}
+
+func (c *tCEXBook) UnsubscribeMarket(baseID, quoteID uint32) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.unsubscribed = true
+	return nil
+}
+
+func (c *tCEXBook) Book(baseID, quoteID uint32) (buys, sells []*core.MiniOrder, _ error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.buys, c.sells, nil
+}
+
+func (c *tCEXBook) setBook(buys, sells []*core.MiniOrder) {
+	c.mtx.Lock()
+	c.buys, c.sells = buys, sells
+	c.mtx.Unlock()
+}
+
+func TestRecorder(t *testing.T) {
+	const baseID, quoteID = 42, 0
+
+	tCore := newTCore()
+	cex := &tCEXBook{}
+
+	cexOrd := func(sell bool, rate, qty uint64) *core.MiniOrder {
+		return &core.MiniOrder{MsgRate: rate, QtyAtomic: qty, Sell: sell}
+	}
+	cex.setBook(
+		[]*core.MiniOrder{cexOrd(false, 4000, 3e8), cexOrd(false, 3900, 1e8), cexOrd(false, 4000, 2e8)},
+		[]*core.MiniOrder{cexOrd(true, 4100, 1e8), cexOrd(true, 4200, 2e8)},
+	)
+
+	var buf bytes.Buffer
+	rec, err := NewRecorder(tCore, cex, &RecorderConfig{
+		Host:            "dex.com",
+		BaseID:          baseID,
+		QuoteID:         quoteID,
+		CEXName:         "Binance",
+		CEXPollInterval: 5 * time.Millisecond,
+	}, &buf, tLogger)
+	if err != nil {
+		t.Fatalf("NewRecorder error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runErr := make(chan error, 1)
+	go func() { runErr <- rec.Run(ctx) }()
+
+	dexBook := &core.OrderBook{
+		Buys:  []*core.MiniOrder{{Token: "0a0b0c0d", MsgRate: 3950, QtyAtomic: 1e8}},
+		Sells: []*core.MiniOrder{{Token: "01020304", MsgRate: 4150, QtyAtomic: 2e8, Sell: true}},
+	}
+	bookOrder := &core.MiniOrder{Token: "aabbccdd", MsgRate: 4125, QtyAtomic: 5e8, Sell: true}
+	unbookOrder := &core.MiniOrder{Token: "0a0b0c0d"}
+	updateRemaining := &core.RemainderUpdate{Token: "01020304", QtyAtomic: 1e8}
+	epochReport := &core.EpochMatchSummaryPayload{
+		Epoch: 1234,
+		MatchSummaries: []*orderbook.MatchSummary{
+			{Rate: 4150, Qty: 1e8, Stamp: 5678, Sell: false},
+			{Rate: 3950, Qty: 2e8, Stamp: 5678, Sell: true},
+		},
+	}
+
+	updates := []*core.BookUpdate{
+		{Action: core.FreshBookAction, Payload: &core.MarketOrderBook{Base: baseID, Quote: quoteID, Book: dexBook}},
+		{Action: core.BookOrderAction, Payload: bookOrder},
+		{Action: core.UnbookOrderAction, Payload: unbookOrder},
+		{Action: core.UpdateRemainingAction, Payload: updateRemaining},
+		{Action: core.EpochMatchSummary, Payload: epochReport},
+		// Not recorded.
+		{Action: core.EpochResolved, Payload: &core.ResolvedEpoch{Current: 1235, Resolved: 1234}},
+	}
+	for _, u := range updates {
+		tCore.bookFeed.c <- u
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	cex.setBook(
+		[]*core.MiniOrder{cexOrd(false, 4000, 5e8)},
+		[]*core.MiniOrder{cexOrd(true, 4100, 3e8), cexOrd(true, 4150, 1e8)},
+	)
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	if err := <-runErr; err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if !cex.subscribed || !cex.unsubscribed {
+		t.Fatalf("CEX market not subscribed and unsubscribed")
+	}
+
+	rr, err := NewRecordingReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("NewRecordingReader error: %v", err)
+	}
+	info := rr.Info()
+	if info.Host != "dex.com" || info.BaseID != baseID || info.QuoteID != quoteID || info.CEXName != "Binance" {
+		t.Fatalf("wrong recording info %+v", info)
+	}
+
+	var dexEvents []*ReplayEvent
+	var cexBooks []*CEXBookSnapshot
+	lastStamp := info.Start
+	for {
+		e, err := rr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next error: %v", err)
+		}
+		if e.Stamp < lastStamp {
+			t.Fatalf("event stamp %d before previous stamp %d", e.Stamp, lastStamp)
+		}
+		lastStamp = e.Stamp
+		if e.CEXBook != nil {
+			cexBooks = append(cexBooks, e.CEXBook)
+		} else {
+			dexEvents = append(dexEvents, e)
+		}
+	}
+
+	expDEXEvents := []*ReplayEvent{
+		{DEXBook: &core.OrderBook{Buys: dexBook.Buys, Sells: dexBook.Sells}},
+		{BookOrder: bookOrder},
+		{UnbookOrder: unbookOrder},
+		{UpdateRemaining: updateRemaining},
+		{EpochReport: epochReport},
+	}
+	if len(dexEvents) != len(expDEXEvents) {
+		t.Fatalf("expected %d DEX events, got %d", len(expDEXEvents), len(dexEvents))
+	}
+	for i, e := range dexEvents {
+		e.Stamp = 0
+		if !reflect.DeepEqual(e, expDEXEvents[i]) {
+			t.Fatalf("wrong DEX event %d. expected %s, got %s", i, spew.Sdump(expDEXEvents[i]), spew.Sdump(e))
+		}
+	}
+
+	// The first CEX book is a snapshot with the levels aggregated, and the
+	// second is rebuilt from the changes.
+	expCEXBooks := []*CEXBookSnapshot{
+		{
+			BaseID:  baseID,
+			QuoteID: quoteID,
+			Buys:    []*core.MiniOrder{cexOrd(false, 4000, 5e8), cexOrd(false, 3900, 1e8)},
+			Sells:   []*core.MiniOrder{cexOrd(true, 4100, 1e8), cexOrd(true, 4200, 2e8)},
+		},
+		{
+			BaseID:  baseID,
+			QuoteID: quoteID,
+			Buys:    []*core.MiniOrder{cexOrd(false, 4000, 5e8)},
+			Sells:   []*core.MiniOrder{cexOrd(true, 4100, 3e8), cexOrd(true, 4150, 1e8)},
+		},
+	}
+	if !reflect.DeepEqual(cexBooks, expCEXBooks) {
+		t.Fatalf("wrong CEX books. expected %s, got %s", spew.Sdump(expCEXBooks), spew.Sdump(cexBooks))
+	}
+}
+
+func TestRecordingReaderUnclosed(t *testing.T) {
+	var buf bytes.Buffer
+	rw, err := newRecordingWriter(&buf, &RecordingInfo{Host: "dex.com", Start: 1000})
+	if err != nil {
+		t.Fatalf("newRecordingWriter error: %v", err)
+	}
+	if err := rw.writeUnbookOrder(1500, "01020304"); err != nil {
+		t.Fatalf("writeUnbookOrder error: %v", err)
+	}
+	if err := rw.flush(); err != nil {
+		t.Fatalf("flush error: %v", err)
+	}
+	// Not flushed.
+	if err := rw.writeUnbookOrder(2000, "05060708"); err != nil {
+		t.Fatalf("writeUnbookOrder error: %v", err)
+	}
+
+	rr, err := NewRecordingReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("NewRecordingReader error: %v", err)
+	}
+	e, err := rr.Next()
+	if err != nil {
+		t.Fatalf("Next error: %v", err)
+	}
+	if e.Stamp != 1500 || e.UnbookOrder == nil || e.UnbookOrder.Token != "01020304" {
+		t.Fatalf("wrong event %+v", e)
+	}
+	if _, err := rr.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+
+	if err := rw.writeUnbookOrder(2500, "not hex"); err == nil {
+		t.Fatalf("no error for invalid token")
+	}
+}
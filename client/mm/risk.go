@@ -0,0 +1,240 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package mm
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/order"
+)
+
+// RiskLimitType identifies one of the limits in RiskLimits.
+type RiskLimitType string
+
+const (
+	RiskLimitDrawdown         RiskLimitType = "drawdown"
+	RiskLimitInventorySkew    RiskLimitType = "inventoryskew"
+	RiskLimitCEXFailures      RiskLimitType = "cexfailures"
+	RiskLimitDEXMatchFailures RiskLimitType = "dexmatchfailures"
+)
+
+// riskTracker tracks the bot's state that is checked against its RiskLimits.
+type riskTracker struct {
+	// tripped is set when a limit is crossed, and is cleared when a paused
+	// bot is resumed by a config update.
+	tripped atomic.Bool
+
+	mtx                    sync.Mutex
+	peakProfit             float64
+	havePeak               bool
+	consecutiveCEXFailures uint32
+	// failedMatches are the revoked matches that have been counted, and
+	// matchFailureStamps are the times they were counted.
+	failedMatches      map[order.MatchID]bool
+	matchFailureStamps []time.Time
+}
+
+// reset resets the state for a resumed bot. Matches that have already been
+// counted are not counted again.
+func (r *riskTracker) reset() {
+	r.mtx.Lock()
+	r.havePeak = false
+	r.consecutiveCEXFailures = 0
+	r.matchFailureStamps = nil
+	r.mtx.Unlock()
+	r.tripped.Store(false)
+}
+
+// inventoryShares returns the fraction of the total fiat value of the balances
+// that is held in each asset. nil is returned if the total value is not
+// positive, e.g. if fiat rates are not available.
+func inventoryShares(balances map[uint32]int64, fiatRates map[uint32]float64) map[uint32]float64 {
+	values := make(map[uint32]float64, len(balances))
+	var total float64
+	for assetID, atoms := range balances {
+		ui, err := asset.UnitInfo(assetID)
+		if err != nil || atoms <= 0 {
+			continue
+		}
+		v := float64(atoms) / float64(ui.Conventional.ConversionFactor) * fiatRates[assetID]
+		values[assetID] = v
+		total += v
+	}
+	if total <= 0 {
+		return nil
+	}
+	for assetID, v := range values {
+		values[assetID] = v / total
+	}
+	return values
+}
+
+// checkRiskLimits checks the bot's profit and inventory against the drawdown
+// and inventory skew limits.
+func (u *unifiedExchangeAdaptor) checkRiskLimits(stats *RunStats) {
+	limits := u.botCfg().RiskLimits
+	if limits == nil || stats == nil || stats.ProfitLoss == nil {
+		return
+	}
+
+	if limits.MaxDrawdownUSD > 0 {
+		profit := stats.ProfitLoss.Profit
+		u.risk.mtx.Lock()
+		if !u.risk.havePeak || profit > u.risk.peakProfit {
+			u.risk.peakProfit, u.risk.havePeak = profit, true
+		}
+		drawdown := u.risk.peakProfit - profit
+		u.risk.mtx.Unlock()
+		if drawdown > limits.MaxDrawdownUSD {
+			u.riskLimitReached(RiskLimitDrawdown, fmt.Sprintf("drawdown of %.2f USD exceeds the limit of %.2f USD",
+				drawdown, limits.MaxDrawdownUSD))
+			return
+		}
+	}
+
+	if len(limits.MaxInventorySkew) == 0 {
+		return
+	}
+
+	u.balancesMtx.RLock()
+	allocated := make(map[uint32]int64, len(u.initialBalances))
+	for assetID, bal := range u.initialBalances {
+		allocated[assetID] = int64(bal)
+	}
+	for assetID, mod := range u.inventoryMods {
+		allocated[assetID] += mod
+	}
+	u.balancesMtx.RUnlock()
+
+	current := make(map[uint32]int64, len(stats.ProfitLoss.Final))
+	for assetID, amt := range stats.ProfitLoss.Final {
+		current[assetID] = amt.Atoms
+	}
+
+	fiatRates := u.fiatRates.Load().(map[uint32]float64)
+	allocatedShares := inventoryShares(allocated, fiatRates)
+	currentShares := inventoryShares(current, fiatRates)
+	if allocatedShares == nil || currentShares == nil {
+		return
+	}
+
+	for assetID, maxSkew := range limits.MaxInventorySkew {
+		if maxSkew <= 0 {
+			continue
+		}
+		skew := math.Abs(currentShares[assetID] - allocatedShares[assetID])
+		if skew > maxSkew {
+			u.riskLimitReached(RiskLimitInventorySkew, fmt.Sprintf("%s share of inventory moved from %.1f%% to %.1f%%, exceeding the limit of %.1f%%",
+				dex.BipIDSymbol(assetID), allocatedShares[assetID]*100, currentShares[assetID]*100, maxSkew*100))
+			return
+		}
+	}
+}
+
+// recordCEXTradeResult updates the count of consecutive failed CEX trades.
+func (u *unifiedExchangeAdaptor) recordCEXTradeResult(err error) {
+	u.risk.mtx.Lock()
+	if err == nil {
+		u.risk.consecutiveCEXFailures = 0
+		u.risk.mtx.Unlock()
+		return
+	}
+	u.risk.consecutiveCEXFailures++
+	failures := u.risk.consecutiveCEXFailures
+	u.risk.mtx.Unlock()
+
+	limits := u.botCfg().RiskLimits
+	if limits == nil || limits.MaxConsecutiveCEXFailures == 0 {
+		return
+	}
+	if failures >= limits.MaxConsecutiveCEXFailures {
+		u.riskLimitReached(RiskLimitCEXFailures, fmt.Sprintf("%d consecutive CEX trades failed. Last error: %v", failures, err))
+	}
+}
+
+// recordDEXMatchFailures counts the order's revoked matches towards the hourly
+// DEX match failure limit.
+func (u *unifiedExchangeAdaptor) recordDEXMatchFailures(o *core.Order) {
+	now := time.Now()
+	hourAgo := now.Add(-time.Hour)
+
+	u.risk.mtx.Lock()
+	var newFailures bool
+	for _, match := range o.Matches {
+		if !match.Revoked || match.IsCancel {
+			continue
+		}
+		var matchID order.MatchID
+		copy(matchID[:], match.MatchID)
+		if u.risk.failedMatches[matchID] {
+			continue
+		}
+		if u.risk.failedMatches == nil {
+			u.risk.failedMatches = make(map[order.MatchID]bool)
+		}
+		u.risk.failedMatches[matchID] = true
+		u.risk.matchFailureStamps = append(u.risk.matchFailureStamps, now)
+		newFailures = true
+	}
+	for len(u.risk.matchFailureStamps) > 0 && u.risk.matchFailureStamps[0].Before(hourAgo) {
+		u.risk.matchFailureStamps = u.risk.matchFailureStamps[1:]
+	}
+	failures := uint32(len(u.risk.matchFailureStamps))
+	u.risk.mtx.Unlock()
+
+	if !newFailures {
+		return
+	}
+	limits := u.botCfg().RiskLimits
+	if limits == nil || limits.MaxDEXMatchFailuresPerHour == 0 {
+		return
+	}
+	if failures >= limits.MaxDEXMatchFailuresPerHour {
+		u.riskLimitReached(RiskLimitDEXMatchFailures, fmt.Sprintf("%d DEX matches failed in the last hour", failures))
+	}
+}
+
+// riskLimitReached records the crossed limit in the event log, and pauses or
+// stops the bot. Only the first call has an effect until a paused bot is
+// resumed. riskLimitReached does not block, and may be called with the
+// balancesMtx locked.
+func (u *unifiedExchangeAdaptor) riskLimitReached(limit RiskLimitType, reason string) {
+	if !u.risk.tripped.CompareAndSwap(false, true) {
+		return
+	}
+	action := u.botCfg().RiskLimits.action()
+
+	u.log.Errorf("Risk limit reached: %s. Bot will %s.", reason, action)
+
+	go func() {
+		e := &MarketMakingEvent{
+			ID:        u.eventLogID.Add(1),
+			TimeStamp: time.Now().Unix(),
+			RiskLimitEvent: &RiskLimitEvent{
+				Limit:  limit,
+				Reason: reason,
+				Action: action,
+			},
+		}
+		u.eventLogDB.storeEvent(u.startTime.Load(), u.mwh, e, u.balanceState())
+		u.notifyEvent(e)
+
+		if action == RiskLimitStop {
+			// Orders are canceled when the adaptor shuts down.
+			u.kill()
+			return
+		}
+		if u.botLoop != nil {
+			u.botLoop.Disconnect()
+		}
+		u.cancelAllOrders(u.ctx)
+	}()
+}
@@ -0,0 +1,279 @@
+package mm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/dex/order"
+)
+
+func TestRiskLimitsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  *RiskLimits
+		wantErr bool
+	}{{
+		name:   "empty",
+		limits: &RiskLimits{},
+	}, {
+		name: "ok",
+		limits: &RiskLimits{
+			MaxDrawdownUSD:             100,
+			MaxInventorySkew:           map[uint32]float64{42: 0.25},
+			MaxConsecutiveCEXFailures:  3,
+			MaxDEXMatchFailuresPerHour: 2,
+			Action:                     RiskLimitStop,
+		},
+	}, {
+		name:    "negative drawdown",
+		limits:  &RiskLimits{MaxDrawdownUSD: -1},
+		wantErr: true,
+	}, {
+		name:    "skew too high",
+		limits:  &RiskLimits{MaxInventorySkew: map[uint32]float64{42: 1.5}},
+		wantErr: true,
+	}, {
+		name:    "unknown action",
+		limits:  &RiskLimits{Action: "panic"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &BotConfig{
+				RiskLimits:      tt.limits,
+				SimpleArbConfig: &SimpleArbConfig{ProfitTrigger: 0.01, MaxActiveArbs: 1, NumEpochsLeaveOpen: 10},
+			}
+			err := cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wanted error = %t, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRiskLimits(t *testing.T) {
+	const baseID, quoteID = 42, 0
+
+	newAdaptor := func(limits *RiskLimits) (*unifiedExchangeAdaptor, *tEventLogDB) {
+		t.Helper()
+		u := mustParseAdaptorFromMarket(&core.Market{
+			BaseID:   baseID,
+			QuoteID:  quoteID,
+			LotSize:  1e8,
+			RateStep: 1e2,
+			EpochLen: 100,
+		})
+		eventLogDB := newTEventLogDB()
+		u.eventLogDB = eventLogDB
+		u.ctx, u.kill = context.WithCancel(context.Background())
+		t.Cleanup(u.kill)
+		u.fiatRates.Store(map[uint32]float64{baseID: 1, quoteID: 1})
+		u.initialBalances = map[uint32]uint64{baseID: 1e8, quoteID: 1e8}
+		u.botCfgV.Store(&BotConfig{Host: u.host, BaseID: baseID, QuoteID: quoteID, RiskLimits: limits})
+		if err := u.runBotLoop(u.ctx); err != nil {
+			t.Fatalf("runBotLoop error: %v", err)
+		}
+		return u, eventLogDB
+	}
+
+	waitForRiskEvent := func(eventLogDB *tEventLogDB, limit RiskLimitType, action RiskLimitAction) {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			eventLogDB.storedEventsMtx.Lock()
+			n := len(eventLogDB.storedEvents)
+			var e *MarketMakingEvent
+			if n > 0 {
+				e = eventLogDB.storedEvents[n-1]
+			}
+			eventLogDB.storedEventsMtx.Unlock()
+			if e != nil {
+				if e.RiskLimitEvent == nil || e.RiskLimitEvent.Limit != limit || e.RiskLimitEvent.Action != action {
+					t.Fatalf("wrong event stored: %+v", e)
+				}
+				if n > 1 {
+					t.Fatalf("%d events stored", n)
+				}
+				return
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+		t.Fatalf("no %s risk limit event stored", limit)
+	}
+
+	waitForPause := func(u *unifiedExchangeAdaptor) {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			if !u.botLoop.On() {
+				return
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+		t.Fatalf("bot loop not paused")
+	}
+
+	ensureNoEvents := func(eventLogDB *tEventLogDB) {
+		t.Helper()
+		time.Sleep(time.Millisecond * 50)
+		eventLogDB.storedEventsMtx.Lock()
+		defer eventLogDB.storedEventsMtx.Unlock()
+		if len(eventLogDB.storedEvents) > 0 {
+			t.Fatalf("unexpected event stored: %+v", eventLogDB.storedEvents[0])
+		}
+	}
+
+	statsWithProfit := func(profit float64) *RunStats {
+		return &RunStats{ProfitLoss: &ProfitLoss{Profit: profit}}
+	}
+
+	t.Run("drawdown", func(t *testing.T) {
+		u, eventLogDB := newAdaptor(&RiskLimits{MaxDrawdownUSD: 10})
+		u.checkRiskLimits(statsWithProfit(5))
+		u.checkRiskLimits(statsWithProfit(12))
+		u.checkRiskLimits(statsWithProfit(3))
+		ensureNoEvents(eventLogDB)
+		u.checkRiskLimits(statsWithProfit(1))
+		waitForRiskEvent(eventLogDB, RiskLimitDrawdown, RiskLimitPause)
+		waitForPause(u)
+
+		// Only one event until the bot is resumed.
+		u.checkRiskLimits(statsWithProfit(-10))
+
+		// Internal pauses, e.g. for inventory updates, don't resume the bot.
+		if err := u.withPause(func() error { return nil }); err != nil {
+			t.Fatalf("withPause error: %v", err)
+		}
+		if u.botLoop.On() {
+			t.Fatalf("bot loop resumed by an internal pause")
+		}
+
+		// Resuming with a config update restarts the bot loop and measures the
+		// drawdown from the profit at the time of resumption.
+		if err := u.withPause(func() error {
+			u.resetRiskLimits()
+			return nil
+		}); err != nil {
+			t.Fatalf("withPause error: %v", err)
+		}
+		if !u.botLoop.On() {
+			t.Fatalf("bot loop not resumed")
+		}
+		eventLogDB.storedEventsMtx.Lock()
+		eventLogDB.storedEvents = nil
+		eventLogDB.storedEventsMtx.Unlock()
+		u.checkRiskLimits(statsWithProfit(-10))
+		u.checkRiskLimits(statsWithProfit(-15))
+		ensureNoEvents(eventLogDB)
+	})
+
+	t.Run("inventory skew", func(t *testing.T) {
+		u, eventLogDB := newAdaptor(&RiskLimits{MaxInventorySkew: map[uint32]float64{baseID: 0.3}})
+		stats := func(base, quote int64) *RunStats {
+			return &RunStats{ProfitLoss: &ProfitLoss{Final: map[uint32]*Amount{
+				baseID:  {Atoms: base},
+				quoteID: {Atoms: quote},
+			}}}
+		}
+		// 75% base is a 25% skew.
+		u.checkRiskLimits(stats(3e8, 1e8))
+		ensureNoEvents(eventLogDB)
+		// 90% base is a 40% skew.
+		u.checkRiskLimits(stats(9e8, 1e8))
+		waitForRiskEvent(eventLogDB, RiskLimitInventorySkew, RiskLimitPause)
+		waitForPause(u)
+	})
+
+	t.Run("cex failures", func(t *testing.T) {
+		u, eventLogDB := newAdaptor(&RiskLimits{MaxConsecutiveCEXFailures: 2})
+		tErr := errors.New("test error")
+		u.recordCEXTradeResult(tErr)
+		u.recordCEXTradeResult(nil)
+		u.recordCEXTradeResult(tErr)
+		ensureNoEvents(eventLogDB)
+		u.recordCEXTradeResult(tErr)
+		waitForRiskEvent(eventLogDB, RiskLimitCEXFailures, RiskLimitPause)
+		waitForPause(u)
+	})
+
+	t.Run("dex match failures", func(t *testing.T) {
+		u, eventLogDB := newAdaptor(&RiskLimits{MaxDEXMatchFailuresPerHour: 2, Action: RiskLimitStop})
+		matchID := func(b byte) []byte {
+			var mid order.MatchID
+			mid[0] = b
+			return mid[:]
+		}
+		o := &core.Order{Matches: []*core.Match{
+			{MatchID: matchID(1), Revoked: true},
+			{MatchID: matchID(2)},
+		}}
+		u.recordDEXMatchFailures(o)
+		// The same revoked match isn't counted twice.
+		u.recordDEXMatchFailures(o)
+		// Revoked cancel matches aren't counted.
+		u.recordDEXMatchFailures(&core.Order{Matches: []*core.Match{{MatchID: matchID(3), Revoked: true, IsCancel: true}}})
+		ensureNoEvents(eventLogDB)
+		u.recordDEXMatchFailures(&core.Order{Matches: []*core.Match{{MatchID: matchID(4), Revoked: true}}})
+		waitForRiskEvent(eventLogDB, RiskLimitDEXMatchFailures, RiskLimitStop)
+		select {
+		case <-u.ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatalf("bot not stopped")
+		}
+	})
+
+	t.Run("no limits", func(t *testing.T) {
+		u, eventLogDB := newAdaptor(nil)
+		u.checkRiskLimits(statsWithProfit(-1e6))
+		for i := 0; i < 10; i++ {
+			u.recordCEXTradeResult(errors.New("test error"))
+		}
+		ensureNoEvents(eventLogDB)
+	})
+}
+
+func TestWithPauseRiskLimit(t *testing.T) {
+	const baseID, quoteID = 42, 0
+	u := mustParseAdaptorFromMarket(&core.Market{
+		BaseID:   baseID,
+		QuoteID:  quoteID,
+		LotSize:  1e8,
+		RateStep: 1e2,
+		EpochLen: 100,
+	})
+	u.eventLogDB = newTEventLogDB()
+	u.ctx, u.kill = context.WithCancel(context.Background())
+	t.Cleanup(u.kill)
+	u.fiatRates.Store(map[uint32]float64{baseID: 1, quoteID: 1})
+	u.botCfgV.Store(&BotConfig{Host: u.host, BaseID: baseID, QuoteID: quoteID, RiskLimits: &RiskLimits{MaxDrawdownUSD: 10}})
+	if err := u.runBotLoop(u.ctx); err != nil {
+		t.Fatalf("runBotLoop error: %v", err)
+	}
+
+	// The bot is paused by a risk limit.
+	u.riskLimitReached(RiskLimitDrawdown, "test")
+	for i := 0; u.botLoop.On(); i++ {
+		if i == 100 {
+			t.Fatalf("bot loop not paused")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	// An internal pause, e.g. a portfolio rebalancer move, runs the function
+	// but leaves the bot paused.
+	var ran bool
+	if err := u.withPause(func() error {
+		ran = true
+		return nil
+	}); err != nil {
+		t.Fatalf("withPause error: %v", err)
+	}
+	if !ran {
+		t.Fatalf("function not run")
+	}
+	if u.botLoop.On() || !u.risk.tripped.Load() {
+		t.Fatalf("bot paused by a risk limit was resumed by an internal pause")
+	}
+}
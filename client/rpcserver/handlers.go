@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -57,6 +58,10 @@ const (
 	updateRunningBotInvRoute   = "updaterunningbotinv"
 	mmAvailableBalancesRoute   = "mmavailablebalances"
 	mmStatusRoute              = "mmstatus"
+	mmExportRunRoute           = "mmexportrun"
+	mmExportRunsRoute          = "mmexportruns"
+	mmProposeRebalanceRoute    = "mmproposerebalance"
+	mmRebalanceRoute           = "mmrebalance"
 	multiTradeRoute            = "multitrade"
 	stakeStatusRoute           = "stakestatus"
 	setVSPRoute                = "setvsp"
@@ -71,6 +76,14 @@ const (
 	pendingBridgesRoute        = "pendingbridges"
 	bridgeHistoryRoute         = "bridgehistory"
 	supportedBridgesRoute      = "supportedbridges"
+	conditionalOrderRoute      = "conditionalorder"
+	cancelConditionalRoute     = "cancelconditionalorder"
+	conditionalOrdersRoute     = "conditionalorders"
+	executionOrderRoute        = "executionorder"
+	pauseExecutionRoute        = "pauseexecution"
+	resumeExecutionRoute       = "resumeexecution"
+	cancelExecutionRoute       = "cancelexecution"
+	executionOrdersRoute       = "executionorders"
 )
 
 const (
@@ -79,6 +92,10 @@ const (
 	walletLockedStr   = "%s wallet locked"
 	walletUnlockedStr = "%s wallet unlocked"
 	canceledOrderStr  = "canceled order %s"
+	canceledCondStr   = "canceled conditional order %s"
+	pausedExecStr     = "paused execution order %s"
+	resumedExecStr    = "resumed execution order %s"
+	canceledExecStr   = "canceled execution order %s"
 	logoutStr         = "goodbye"
 	walletStatusStr   = "%s wallet has been %s"
 	setVotePrefsStr   = "vote preferences set"
@@ -138,6 +155,10 @@ var routes = map[string]func(s *RPCServer, params *RawParams) *msgjson.ResponseP
 	stopBotRoute:               handleStopBot,
 	mmAvailableBalancesRoute:   handleMMAvailableBalances,
 	mmStatusRoute:              handleMMStatus,
+	mmExportRunRoute:           handleMMExportRun,
+	mmExportRunsRoute:          handleMMExportRuns,
+	mmProposeRebalanceRoute:    handleMMProposeRebalance,
+	mmRebalanceRoute:           handleMMRebalance,
 	updateRunningBotCfgRoute:   handleUpdateRunningBotCfg,
 	updateRunningBotInvRoute:   handleUpdateRunningBotInventory,
 	multiTradeRoute:            handleMultiTrade,
@@ -154,6 +175,14 @@ var routes = map[string]func(s *RPCServer, params *RawParams) *msgjson.ResponseP
 	pendingBridgesRoute:        handlePendingBridges,
 	bridgeHistoryRoute:         handleBridgeHistory,
 	supportedBridgesRoute:      handleSupportedBridges,
+	conditionalOrderRoute:      handleConditionalOrder,
+	cancelConditionalRoute:     handleCancelConditionalOrder,
+	conditionalOrdersRoute:     handleConditionalOrders,
+	executionOrderRoute:        handleExecutionOrder,
+	pauseExecutionRoute:        handlePauseExecution,
+	resumeExecutionRoute:       handleResumeExecution,
+	cancelExecutionRoute:       handleCancelExecution,
+	executionOrdersRoute:       handleExecutionOrders,
 }
 
 // handleHelp handles requests for help. Returns general help for all commands
@@ -571,6 +600,115 @@ func handleCancel(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
 	return createResponse(cancelRoute, &res, nil)
 }
 
+// handleConditionalOrder handles requests for conditionalorder.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleConditionalOrder(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	form, err := parseConditionalOrderArgs(params)
+	if err != nil {
+		return usage(conditionalOrderRoute, err)
+	}
+	res, err := s.core.PlaceConditionalOrder(form)
+	if err != nil {
+		resErr := msgjson.NewError(msgjson.RPCConditionalOrderError, "unable to place conditional order: %v", err)
+		return createResponse(conditionalOrderRoute, nil, resErr)
+	}
+	return createResponse(conditionalOrderRoute, res, nil)
+}
+
+// handleCancelConditionalOrder handles requests for cancelconditionalorder.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleCancelConditionalOrder(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	id, err := parseCancelConditionalOrderArgs(params)
+	if err != nil {
+		return usage(cancelConditionalRoute, err)
+	}
+	if err := s.core.CancelConditionalOrder(id); err != nil {
+		resErr := msgjson.NewError(msgjson.RPCConditionalOrderError, "unable to cancel conditional order %q: %v", id, err)
+		return createResponse(cancelConditionalRoute, nil, resErr)
+	}
+	res := fmt.Sprintf(canceledCondStr, id)
+	return createResponse(cancelConditionalRoute, &res, nil)
+}
+
+// handleConditionalOrders handles requests for conditionalorders.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleConditionalOrders(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	activeOnly, err := parseActiveOnlyArgs(params)
+	if err != nil {
+		return usage(conditionalOrdersRoute, err)
+	}
+	ords, err := s.core.ConditionalOrders(activeOnly)
+	if err != nil {
+		resErr := msgjson.NewError(msgjson.RPCConditionalOrderError, "unable to get conditional orders: %v", err)
+		return createResponse(conditionalOrdersRoute, nil, resErr)
+	}
+	return createResponse(conditionalOrdersRoute, ords, nil)
+}
+
+// handleExecutionOrder handles requests for executionorder.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleExecutionOrder(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	form, err := parseExecutionOrderArgs(params)
+	if err != nil {
+		return usage(executionOrderRoute, err)
+	}
+	res, err := s.core.PlaceExecutionOrder(form)
+	if err != nil {
+		resErr := msgjson.NewError(msgjson.RPCExecutionOrderError, "unable to place execution order: %v", err)
+		return createResponse(executionOrderRoute, nil, resErr)
+	}
+	return createResponse(executionOrderRoute, res, nil)
+}
+
+// handleExecutionControl handles requests for the routes that pause, resume
+// and cancel execution orders.
+func handleExecutionControl(s *RPCServer, params *RawParams, route, resStr string, f func(dex.Bytes) error) *msgjson.ResponsePayload {
+	id, err := parseExecutionIDArgs(params)
+	if err != nil {
+		return usage(route, err)
+	}
+	if err := f(id); err != nil {
+		resErr := msgjson.NewError(msgjson.RPCExecutionOrderError, "unable to %s execution order %q: %v",
+			strings.TrimSuffix(route, "execution"), id, err)
+		return createResponse(route, nil, resErr)
+	}
+	res := fmt.Sprintf(resStr, id)
+	return createResponse(route, &res, nil)
+}
+
+// handlePauseExecution handles requests for pauseexecution.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handlePauseExecution(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	return handleExecutionControl(s, params, pauseExecutionRoute, pausedExecStr, s.core.PauseExecutionOrder)
+}
+
+// handleResumeExecution handles requests for resumeexecution.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleResumeExecution(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	return handleExecutionControl(s, params, resumeExecutionRoute, resumedExecStr, s.core.ResumeExecutionOrder)
+}
+
+// handleCancelExecution handles requests for cancelexecution.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleCancelExecution(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	return handleExecutionControl(s, params, cancelExecutionRoute, canceledExecStr, s.core.CancelExecutionOrder)
+}
+
+// handleExecutionOrders handles requests for executionorders.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleExecutionOrders(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	activeOnly, err := parseActiveOnlyArgs(params)
+	if err != nil {
+		return usage(executionOrdersRoute, err)
+	}
+	ords, err := s.core.ExecutionOrders(activeOnly)
+	if err != nil {
+		resErr := msgjson.NewError(msgjson.RPCExecutionOrderError, "unable to get execution orders: %v", err)
+		return createResponse(executionOrdersRoute, nil, resErr)
+	}
+	return createResponse(executionOrdersRoute, ords, nil)
+}
+
 // handleWithdraw handles requests for withdraw. *msgjson.ResponsePayload.Error
 // is empty if successful.
 func handleWithdraw(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
@@ -982,6 +1120,106 @@ func handleMMStatus(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
 	return createResponse(mmStatusRoute, status, nil)
 }
 
+func handleMMExportRun(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	form, err := parseMMExportRunArgs(params)
+	if err != nil {
+		return usage(mmExportRunRoute, err)
+	}
+
+	export, err := s.mm.ExportRun(form.startTime, form.mkt)
+	if err != nil {
+		resErr := msgjson.NewError(msgjson.RPCMMExportError, "unable to export run: %v", err)
+		return createResponse(mmExportRunRoute, nil, resErr)
+	}
+
+	return writeRunExports(mmExportRunRoute, form, []*mm.RunExport{export})
+}
+
+func handleMMExportRuns(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	form, err := parseMMExportRunsArgs(params)
+	if err != nil {
+		return usage(mmExportRunsRoute, err)
+	}
+
+	exports, err := s.mm.ExportRuns(form.from, form.to)
+	if err != nil {
+		resErr := msgjson.NewError(msgjson.RPCMMExportError, "unable to export runs: %v", err)
+		return createResponse(mmExportRunsRoute, nil, resErr)
+	}
+
+	return writeRunExports(mmExportRunsRoute, form, exports)
+}
+
+func handleMMProposeRebalance(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	cfg, err := parsePortfolioRebalanceArgs(params)
+	if err != nil {
+		return usage(mmProposeRebalanceRoute, err)
+	}
+
+	transfers, err := s.mm.ProposePortfolioRebalance(cfg)
+	if err != nil {
+		resErr := msgjson.NewError(msgjson.RPCMMPortfolioRebalanceError, "unable to propose rebalance: %v", err)
+		return createResponse(mmProposeRebalanceRoute, nil, resErr)
+	}
+
+	return createResponse(mmProposeRebalanceRoute, transfers, nil)
+}
+
+func handleMMRebalance(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	cfg, err := parsePortfolioRebalanceArgs(params)
+	if err != nil {
+		return usage(mmRebalanceRoute, err)
+	}
+
+	transfers, err := s.mm.ExecutePortfolioRebalance(cfg)
+	if err != nil {
+		resErr := msgjson.NewError(msgjson.RPCMMPortfolioRebalanceError,
+			"rebalance stopped after %d transfers: %v", len(transfers), err)
+		return createResponse(mmRebalanceRoute, nil, resErr)
+	}
+
+	return createResponse(mmRebalanceRoute, transfers, nil)
+}
+
+// writeRunExports writes market making run exports to the file specified in
+// the form. The file must not already exist.
+func writeRunExports(route string, form *mmExportForm, exports []*mm.RunExport) *msgjson.ResponsePayload {
+	path := dex.CleanAndExpandPath(form.filePath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		resErr := msgjson.NewError(msgjson.RPCMMExportError, "unable to create directory: %v", err)
+		return createResponse(route, nil, resErr)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		resErr := msgjson.NewError(msgjson.RPCMMExportError, "unable to create export file: %v", err)
+		return createResponse(route, nil, resErr)
+	}
+	err = mm.WriteRunExports(f, exports, form.format)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		resErr := msgjson.NewError(msgjson.RPCMMExportError, "unable to write export file: %v", err)
+		return createResponse(route, nil, resErr)
+	}
+
+	var nEntries int
+	for _, export := range exports {
+		nEntries += len(export.Entries)
+	}
+	res := struct {
+		Path    string `json:"path"`
+		Runs    int    `json:"runs"`
+		Entries int    `json:"entries"`
+	}{
+		Path:    path,
+		Runs:    len(exports),
+		Entries: nEntries,
+	}
+	return createResponse(route, res, nil)
+}
+
 func handleSetVSP(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
 	form, err := parseSetVSPArgs(params)
 	if err != nil {
@@ -1603,7 +1841,10 @@ var helpMsgs = map[string]helpMsg{
     qty (int): The number of units to buy/sell. Must be a multiple of the lot size.
     rate (int): The atoms quote asset to pay/accept per unit base asset. e.g.
       156000 satoshi/DCR for the DCR(base)_BTC(quote).
-    immediate (bool): Require immediate match. Do not book the order.
+    immediate (bool|string): Require immediate match. Do not book the order.
+      May instead be the limit order time in force, one of "standing",
+      "immediate", "fillorkill" (match completely in one epoch or not at all),
+      or "postonly" (book the order, but do not match it as a taker).
     options (string): A JSON-encoded string->string mapping of additional
        trade options.`,
 		returns: `Returns:
@@ -1784,6 +2025,8 @@ needed to complete a swap.`,
       "canceled" (bool): Whether this order has been canceled.
       "tif" (string): "immediate" if this limit order will only match for one epoch.
         "standing" if the order can continue matching until filled or cancelled.
+        "fill-or-kill" if the order must be completely filled in one epoch.
+        "post-only" if the order is standing but will not match as a taker.
       "matches": (array): An array of matches associated with the order.
       [
         {
@@ -1877,6 +2120,76 @@ needed to complete a swap.`,
 	mmStatusRoute: {
 		cmdSummary: `Get market making status.`,
 	},
+	mmExportRunRoute: {
+		cmdSummary: `Export the history of a market making run to a file. The export includes
+    the DEX and CEX orders, deposits, withdrawals and inventory updates of the run,
+    with their fees and fiat values at the time of each event. Note that the file
+    location is from the perspective of bisonw and not the caller.`,
+		argsShort: `"path" "format" "host" baseID quoteID startTime`,
+		argsLong: `Args:
+    path (string): The path of the file to write. The file must not exist.
+    format (string): "csv" or "json". The json format also includes the run
+      overview.
+    host (string): The DEX address.
+    baseID (int): The base asset's BIP-44 registered coin index.
+    quoteID (int): The quote asset's BIP-44 registered coin index.
+    startTime (int): The start time of the run in unix seconds.`,
+		returns: `Returns:
+    obj: The export result.
+    {
+      "path" (string): The path of the file written.
+      "runs" (int): The number of runs exported.
+      "entries" (int): The number of ledger entries exported.
+    }`,
+	},
+	mmExportRunsRoute: {
+		cmdSummary: `Export the history of all market making runs started in a date range to a
+    file. See mmexportrun. Note that the file location is from the perspective of
+    bisonw and not the caller.`,
+		argsShort: `"path" "format" from (to)`,
+		argsLong: `Args:
+    path (string): The path of the file to write. The file must not exist.
+    format (string): "csv" or "json".
+    from (int): The earliest run start time in unix seconds.
+    to (int): Optional. The latest run start time in unix seconds. Defaults to
+      no limit.`,
+		returns: `Returns:
+    obj: The export result. See mmexportrun.`,
+	},
+	mmProposeRebalanceRoute: {
+		cmdSummary: `Propose transfers that move the surpluses of the running bots' allocations
+    to the bots with shortages. Moves between bots on the same DEX or CEX are
+    free. Deposits and withdrawals are only proposed if they are large enough
+    relative to their network fee. Nothing is moved. See mmrebalance.`,
+		argsShort: `feeMultiplier (minTransfer)`,
+		argsLong: `Args:
+    feeMultiplier (float): The minimum size of a deposit or withdrawal as a
+      multiple of its estimated network fee. Must be at least 1.
+    minTransfer (obj): Optional. The minimum amount of each asset to move i.e.
+      [[60,1000000],[42,10000000]].`,
+		returns: `Returns:
+    array: The proposed transfers.
+    [
+      {
+        "assetID" (int): The asset's BIP-44 registered coin index.
+        "amount" (int): The amount to move in atoms.
+        "from" (obj): The market of the bot with the surplus.
+        "fromCEX" (bool): Whether the surplus is on the CEX.
+        "to" (obj): The market of the bot with the shortage.
+        "toCEX" (bool): Whether the shortage is on the CEX.
+        "fees" (int): The estimated network fee of a deposit or withdrawal.
+      },...
+    ]`,
+	},
+	mmRebalanceRoute: {
+		cmdSummary: `Perform the transfers that would be proposed by mmproposerebalance.`,
+		argsShort:  `feeMultiplier (minTransfer)`,
+		argsLong: `Args:
+    feeMultiplier (float): See mmproposerebalance.
+    minTransfer (obj): Optional. See mmproposerebalance.`,
+		returns: `Returns:
+    array: The transfers that were performed. See mmproposerebalance.`,
+	},
 	updateRunningBotCfgRoute: {
 		cmdSummary: `Update the config and optionally the inventory of a running bot`,
 		argsShort:  `(cfgPath) (host) (baseID) (quoteID) (dexInventory) (cexInventory)`,
@@ -2059,4 +2372,140 @@ an spv wallet and enables options to view and set the vsp.
 		argsLong: `Args:
 		assetID (int): The asset's BIP-44 registered coin index to get bridge destinations for.`,
 	},
+	conditionalOrderRoute: {
+		argsShort: `"host" isLimit sell base quote qty rate immediate type trigger triggerPrice (options)`,
+		cmdSummary: `Place a stop-loss or take-profit order. The order is held by the client
+and placed when the trigger price is reached. The wallets must be unlocked
+at that time for the order to be placed.`,
+		argsLong: `Args:
+    host (string): The DEX to trade on.
+    isLimit (bool): Whether the order is a limit order.
+    sell (bool): Whether the order is selling.
+    base (int): The BIP-44 coin index for the market's base asset.
+    quote (int): The BIP-44 coin index for the market's quote asset.
+    qty (int): The number of units to buy/sell. Must be a multiple of the lot size.
+    rate (int): The atoms quote asset to pay/accept per unit base asset. e.g.
+      156000 satoshi/DCR for the DCR(base)_BTC(quote).
+    immediate (bool): Require immediate match. Do not book the order.
+    type (string): "stoploss" or "takeprofit". A stop-loss sell is triggered
+      when the price falls to the trigger price, and a stop-loss buy when the
+      price rises to the trigger price. Take-profit orders are the opposite.
+    trigger (string): "midgap" to trigger on the order book mid-gap rate, or
+      "fiat" to trigger on the fiat rate of the base asset.
+    triggerPrice (int or float): The mid-gap rate in atoms quote asset per
+      unit base asset for midgap triggers, or the fiat rate for fiat triggers.
+    options (string): A JSON-encoded string->string mapping of additional
+       trade options.`,
+		returns: `Returns:
+    obj: The conditional order.
+    {
+      "id" (string): The conditional order's unique hex identifier.
+      "trade" (obj): The order that will be placed when triggered.
+      "type" (string): The conditional order type.
+      "trigger" (string): The price that triggers the order.
+      "triggerRate" (int): The mid-gap trigger rate.
+      "triggerFiatRate" (float): The fiat trigger rate.
+      "stamp" (int): The time the conditional order was created in milliseconds
+        since 00:00:00 Jan 1 1970.
+      "status" (string): "active", "triggered", "canceled" or "failed".
+    }`,
+	},
+	cancelConditionalRoute: {
+		argsShort:  `"id"`,
+		cmdSummary: `Cancel an active conditional order.`,
+		argsLong: `Args:
+    id (string): The hex ID of the conditional order to cancel.`,
+		returns: `Returns:
+    string: The message "` + fmt.Sprintf(canceledCondStr, "[id]") + `"`,
+	},
+	conditionalOrdersRoute: {
+		argsShort:  `(activeOnly)`,
+		cmdSummary: `List conditional orders, newest first.`,
+		argsLong: `Args:
+    activeOnly (bool): Only list orders that have not been triggered or canceled.
+      Default is false.`,
+		returns: `Returns:
+    array: The conditional orders. See the conditionalorder route for the
+      format. Triggered orders have the "orderID" of the placed order, and
+      failed orders have an "error".`,
+	},
+	executionOrderRoute: {
+		argsShort: `"host" sell base quote qty rate "algo" [slices interval | visibleQty] (options)`,
+		cmdSummary: `Place a large limit order that is executed as a series of smaller
+standing limit orders at the same rate. A TWAP order is split into equal
+slices that are placed at regular intervals. An iceberg order keeps a single
+child order of the visible quantity booked, and places another when it is
+filled. The wallets must be unlocked for child orders to be placed. If a child
+order cannot be placed, the execution order is paused.`,
+		argsLong: `Args:
+    host (string): The DEX to trade on.
+    sell (bool): Whether the order is selling.
+    base (int): The BIP-44 coin index for the market's base asset.
+    quote (int): The BIP-44 coin index for the market's quote asset.
+    qty (int): The total number of units to buy/sell. Must be a multiple of
+      the lot size.
+    rate (int): The atoms quote asset to pay/accept per unit base asset for
+      all child orders.
+    algo (string): "twap" or "iceberg".
+    slices (int): For "twap", the number of child orders.
+    interval (int): For "twap", the number of seconds between child orders.
+      Must be at least one epoch.
+    visibleQty (int): For "iceberg", the quantity of each child order. Must
+      be a multiple of the lot size.
+    options (string): A JSON-encoded string->string mapping of additional
+      trade options for the child orders.`,
+		returns: `Returns:
+    obj: The execution order.
+    {
+      "id" (string): The execution order's unique hex identifier.
+      "host" (string): The DEX address.
+      "base" (int): The BIP-44 coin index for the market's base asset.
+      "quote" (int): The BIP-44 coin index for the market's quote asset.
+      "sell" (bool): Whether the order is selling.
+      "qty" (int): The total quantity.
+      "rate" (int): The rate of the child orders.
+      "algo" (string): "twap" or "iceberg".
+      "stamp" (int): The time the execution order was created in milliseconds
+        since 00:00:00 Jan 1 1970.
+      "status" (string): "active", "paused", "complete" or "canceled".
+      "filled" (int): The quantity filled by the child orders.
+      "childOrderIDs" ([string]): The IDs of the child orders placed so far.
+    }`,
+	},
+	pauseExecutionRoute: {
+		argsShort: `"id"`,
+		cmdSummary: `Pause an execution order. No new child orders are placed until it is
+resumed. Booked child orders are not canceled.`,
+		argsLong: `Args:
+    id (string): The hex ID of the execution order.`,
+		returns: `Returns:
+    string: The message "` + fmt.Sprintf(pausedExecStr, "[id]") + `"`,
+	},
+	resumeExecutionRoute: {
+		argsShort:  `"id"`,
+		cmdSummary: `Resume a paused execution order.`,
+		argsLong: `Args:
+    id (string): The hex ID of the execution order.`,
+		returns: `Returns:
+    string: The message "` + fmt.Sprintf(resumedExecStr, "[id]") + `"`,
+	},
+	cancelExecutionRoute: {
+		argsShort:  `"id"`,
+		cmdSummary: `Cancel an execution order and any of its child orders that are booked.`,
+		argsLong: `Args:
+    id (string): The hex ID of the execution order.`,
+		returns: `Returns:
+    string: The message "` + fmt.Sprintf(canceledExecStr, "[id]") + `"`,
+	},
+	executionOrdersRoute: {
+		argsShort:  `(activeOnly)`,
+		cmdSummary: `List execution orders, newest first.`,
+		argsLong: `Args:
+    activeOnly (bool): Only list orders that are active or paused. Default is
+      false.`,
+		returns: `Returns:
+    array: The execution orders. See the executionorder route for the format.
+      Paused orders may have an "error" describing why a child order could not
+      be placed.`,
+	},
 }
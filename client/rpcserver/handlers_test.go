@@ -762,6 +762,145 @@ func TestHandleCancel(t *testing.T) {
 	}
 }
 
+func TestHandleConditionalOrder(t *testing.T) {
+	params := &RawParams{
+		Args: []string{"dex", "true", "true", "42", "0", "100000000", "150000", "false", "stoploss", "midgap", "160000"},
+	}
+	tests := []struct {
+		name                string
+		params              *RawParams
+		conditionalOrderErr error
+		wantErrCode         int
+	}{{
+		name:        "ok",
+		params:      params,
+		wantErrCode: -1,
+	}, {
+		name:                "core.PlaceConditionalOrder error",
+		params:              params,
+		conditionalOrderErr: errors.New("error"),
+		wantErrCode:         msgjson.RPCConditionalOrderError,
+	}, {
+		name:        "bad params",
+		params:      &RawParams{},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}}
+	for _, test := range tests {
+		tc := &TCore{conditionalOrderErr: test.conditionalOrderErr}
+		r := &RPCServer{core: tc}
+		payload := handleConditionalOrder(r, test.params)
+		res := new(core.ConditionalOrder)
+		if err := verifyResponse(payload, res, test.wantErrCode); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHandleCancelConditionalOrder(t *testing.T) {
+	params := &RawParams{Args: []string{"0102030405060708"}}
+	tests := []struct {
+		name                string
+		params              *RawParams
+		conditionalOrderErr error
+		wantErrCode         int
+	}{{
+		name:        "ok",
+		params:      params,
+		wantErrCode: -1,
+	}, {
+		name:                "core.CancelConditionalOrder error",
+		params:              params,
+		conditionalOrderErr: errors.New("error"),
+		wantErrCode:         msgjson.RPCConditionalOrderError,
+	}, {
+		name:        "bad params",
+		params:      &RawParams{},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}}
+	for _, test := range tests {
+		tc := &TCore{conditionalOrderErr: test.conditionalOrderErr}
+		r := &RPCServer{core: tc}
+		payload := handleCancelConditionalOrder(r, test.params)
+		res := ""
+		if err := verifyResponse(payload, &res, test.wantErrCode); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHandleExecutionOrder(t *testing.T) {
+	params := &RawParams{
+		Args: []string{"dex", "true", "42", "0", "1000000000", "150000", "twap", "10", "600"},
+	}
+	tests := []struct {
+		name              string
+		params            *RawParams
+		executionOrderErr error
+		wantErrCode       int
+	}{{
+		name:        "ok",
+		params:      params,
+		wantErrCode: -1,
+	}, {
+		name:              "core.PlaceExecutionOrder error",
+		params:            params,
+		executionOrderErr: errors.New("error"),
+		wantErrCode:       msgjson.RPCExecutionOrderError,
+	}, {
+		name:        "bad params",
+		params:      &RawParams{},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}}
+	for _, test := range tests {
+		tc := &TCore{executionOrderErr: test.executionOrderErr}
+		r := &RPCServer{core: tc}
+		payload := handleExecutionOrder(r, test.params)
+		res := new(core.ExecutionOrder)
+		if err := verifyResponse(payload, res, test.wantErrCode); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHandleExecutionControl(t *testing.T) {
+	params := &RawParams{Args: []string{"0102030405060708"}}
+	handlers := map[string]func(*RPCServer, *RawParams) *msgjson.ResponsePayload{
+		pauseExecutionRoute:  handlePauseExecution,
+		resumeExecutionRoute: handleResumeExecution,
+		cancelExecutionRoute: handleCancelExecution,
+	}
+	tests := []struct {
+		name              string
+		params            *RawParams
+		executionOrderErr error
+		wantErrCode       int
+	}{{
+		name:        "ok",
+		params:      params,
+		wantErrCode: -1,
+	}, {
+		name:              "core error",
+		params:            params,
+		executionOrderErr: errors.New("error"),
+		wantErrCode:       msgjson.RPCExecutionOrderError,
+	}, {
+		name:        "bad params",
+		params:      &RawParams{},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}}
+	for route, handler := range handlers {
+		for _, test := range tests {
+			tc := &TCore{executionOrderErr: test.executionOrderErr}
+			r := &RPCServer{core: tc}
+			payload := handler(r, test.params)
+			res := ""
+			if err := verifyResponse(payload, &res, test.wantErrCode); err != nil {
+				t.Fatalf("%s: %v", route, err)
+			}
+		}
+	}
+}
+
 // tCoin satisfies the asset.Coin interface.
 type tCoin struct{}
 
@@ -38,7 +38,7 @@ const (
 	// patch for bug fixes. bwctl requiredRPCSemVer should be kept up to date
 	// with this version.
 	rpcSemverMajor uint32 = 0
-	rpcSemverMinor uint32 = 4
+	rpcSemverMinor uint32 = 5
 	rpcSemverPatch uint32 = 0
 
 	// rpcTimeoutSeconds is the number of seconds a connection to the RPC server
@@ -93,6 +93,14 @@ type clientCore interface {
 	PendingBridges(assetID uint32) ([]*asset.WalletTransaction, error)
 	BridgeHistory(assetID uint32, n int, refID *string, past bool) ([]*asset.WalletTransaction, error)
 	SupportedBridgeDestinations(assetID uint32) (map[string][]uint32, error)
+	PlaceConditionalOrder(form *core.ConditionalOrderForm) (*core.ConditionalOrder, error)
+	CancelConditionalOrder(id dex.Bytes) error
+	ConditionalOrders(activeOnly bool) ([]*core.ConditionalOrder, error)
+	PlaceExecutionOrder(form *core.ExecutionOrderForm) (*core.ExecutionOrder, error)
+	PauseExecutionOrder(id dex.Bytes) error
+	ResumeExecutionOrder(id dex.Bytes) error
+	CancelExecutionOrder(id dex.Bytes) error
+	ExecutionOrders(activeOnly bool) ([]*core.ExecutionOrder, error)
 
 	// These are core's ticket buying interface.
 	StakeStatus(assetID uint32) (*asset.TicketStakingStatus, error)
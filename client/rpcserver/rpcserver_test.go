@@ -71,6 +71,8 @@ type TCore struct {
 	stakeStatus              *asset.TicketStakingStatus
 	stakeStatusErr           error
 	setVotingPrefErr         error
+	conditionalOrderErr      error
+	executionOrderErr        error
 }
 
 func (c *TCore) Balance(uint32) (uint64, error) {
@@ -222,6 +224,36 @@ func (c *TCore) PendingBridges(fromAssetID uint32) ([]*asset.WalletTransaction,
 func (c *TCore) SupportedBridgeDestinations(assetID uint32) (map[string][]uint32, error) {
 	return nil, nil
 }
+func (c *TCore) PlaceConditionalOrder(form *core.ConditionalOrderForm) (*core.ConditionalOrder, error) {
+	if c.conditionalOrderErr != nil {
+		return nil, c.conditionalOrderErr
+	}
+	return &core.ConditionalOrder{Trade: form.Trade, Type: form.Type, Trigger: form.Trigger}, nil
+}
+func (c *TCore) CancelConditionalOrder(id dex.Bytes) error {
+	return c.conditionalOrderErr
+}
+func (c *TCore) ConditionalOrders(activeOnly bool) ([]*core.ConditionalOrder, error) {
+	return nil, c.conditionalOrderErr
+}
+func (c *TCore) PlaceExecutionOrder(form *core.ExecutionOrderForm) (*core.ExecutionOrder, error) {
+	if c.executionOrderErr != nil {
+		return nil, c.executionOrderErr
+	}
+	return &core.ExecutionOrder{Host: form.Host, Qty: form.Qty, Rate: form.Rate, Algo: form.Algo}, nil
+}
+func (c *TCore) PauseExecutionOrder(id dex.Bytes) error {
+	return c.executionOrderErr
+}
+func (c *TCore) ResumeExecutionOrder(id dex.Bytes) error {
+	return c.executionOrderErr
+}
+func (c *TCore) CancelExecutionOrder(id dex.Bytes) error {
+	return c.executionOrderErr
+}
+func (c *TCore) ExecutionOrders(activeOnly bool) ([]*core.ExecutionOrder, error) {
+	return nil, c.executionOrderErr
+}
 
 type tBookFeed struct{}
 
@@ -200,6 +200,16 @@ type mmAvailableBalancesForm struct {
 	cexName *string
 }
 
+// mmExportForm is the information necessary to export market making run
+// history. If mkt is nil, the runs started between from and to are exported.
+type mmExportForm struct {
+	filePath  string
+	format    string
+	mkt       *mm.MarketWithHost
+	startTime int64
+	from, to  int64
+}
+
 type startBotForm struct {
 	appPass     encode.PassBytes
 	cfgFilePath string
@@ -288,6 +298,28 @@ func checkBoolArg(arg, name string) (bool, error) {
 	return b, nil
 }
 
+// checkTimeInForceArg parses a limit order time in force, which is either a
+// boolean, true for immediate and false for standing, or one of "standing",
+// "immediate", "fillorkill", or "postonly".
+func checkTimeInForceArg(arg string) (tifNow, fillOrKill, postOnly bool, err error) {
+	switch arg {
+	case "standing":
+	case "immediate":
+		tifNow = true
+	case "fillorkill":
+		fillOrKill = true
+	case "postonly":
+		postOnly = true
+	default:
+		tifNow, err = strconv.ParseBool(arg)
+		if err != nil {
+			err = fmt.Errorf("%w: immediate must be a boolean, or one of standing, "+
+				"immediate, fillorkill, or postonly", errArgs)
+		}
+	}
+	return
+}
+
 func checkMapArg(arg, name string) (map[string]string, error) {
 	m := make(map[string]string)
 	err := json.Unmarshal([]byte(arg), &m)
@@ -585,7 +617,7 @@ func parseTradeArgs(params *RawParams) (*tradeForm, error) {
 	if err != nil {
 		return nil, err
 	}
-	tifnow, err := checkBoolArg(params.Args[7], "immediate")
+	tifnow, fillOrKill, postOnly, err := checkTimeInForceArg(params.Args[7])
 	if err != nil {
 		return nil, err
 	}
@@ -596,15 +628,17 @@ func parseTradeArgs(params *RawParams) (*tradeForm, error) {
 	req := &tradeForm{
 		appPass: params.PWArgs[0],
 		srvForm: &core.TradeForm{
-			Host:    params.Args[0],
-			IsLimit: isLimit,
-			Sell:    sell,
-			Base:    uint32(base),
-			Quote:   uint32(quote),
-			Qty:     qty,
-			Rate:    rate,
-			TifNow:  tifnow,
-			Options: options,
+			Host:       params.Args[0],
+			IsLimit:    isLimit,
+			Sell:       sell,
+			Base:       uint32(base),
+			Quote:      uint32(quote),
+			Qty:        qty,
+			Rate:       rate,
+			TifNow:     tifnow,
+			FillOrKill: fillOrKill,
+			PostOnly:   postOnly,
+			Options:    options,
 		},
 	}
 	return req, nil
@@ -669,6 +703,178 @@ func parseMultiTradeArgs(params *RawParams) (*multiTradeForm, error) {
 	}, nil
 }
 
+func parseConditionalOrderArgs(params *RawParams) (*core.ConditionalOrderForm, error) {
+	if err := checkNArgs(params, []int{0}, []int{11, 12}); err != nil {
+		return nil, err
+	}
+	isLimit, err := checkBoolArg(params.Args[1], "isLimit")
+	if err != nil {
+		return nil, err
+	}
+	sell, err := checkBoolArg(params.Args[2], "sell")
+	if err != nil {
+		return nil, err
+	}
+	base, err := checkUIntArg(params.Args[3], "base", 32)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := checkUIntArg(params.Args[4], "quote", 32)
+	if err != nil {
+		return nil, err
+	}
+	qty, err := checkUIntArg(params.Args[5], "qty", 64)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := checkUIntArg(params.Args[6], "rate", 64)
+	if err != nil {
+		return nil, err
+	}
+	tifnow, err := checkBoolArg(params.Args[7], "immediate")
+	if err != nil {
+		return nil, err
+	}
+	form := &core.ConditionalOrderForm{
+		Trade: &core.TradeForm{
+			Host:    params.Args[0],
+			IsLimit: isLimit,
+			Sell:    sell,
+			Base:    uint32(base),
+			Quote:   uint32(quote),
+			Qty:     qty,
+			Rate:    rate,
+			TifNow:  tifnow,
+		},
+		Type:    params.Args[8],
+		Trigger: params.Args[9],
+	}
+	switch form.Trigger {
+	case "midgap":
+		form.TriggerRate, err = checkUIntArg(params.Args[10], "triggerPrice", 64)
+		if err != nil {
+			return nil, err
+		}
+	case "fiat":
+		form.TriggerFiatRate, err = strconv.ParseFloat(params.Args[10], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot parse triggerPrice: %v", errArgs, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown trigger %q", errArgs, form.Trigger)
+	}
+	if len(params.Args) > 11 {
+		form.Trade.Options, err = checkMapArg(params.Args[11], "options")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return form, nil
+}
+
+func parseCancelConditionalOrderArgs(params *RawParams) (dex.Bytes, error) {
+	if err := checkNArgs(params, []int{0}, []int{1}); err != nil {
+		return nil, err
+	}
+	id, err := hex.DecodeString(params.Args[0])
+	if err != nil || len(id) == 0 {
+		return nil, fmt.Errorf("%w: invalid conditional order id hex", errArgs)
+	}
+	return id, nil
+}
+
+func parseActiveOnlyArgs(params *RawParams) (activeOnly bool, err error) {
+	if err := checkNArgs(params, []int{0}, []int{0, 1}); err != nil {
+		return false, err
+	}
+	if len(params.Args) > 0 {
+		return checkBoolArg(params.Args[0], "activeOnly")
+	}
+	return false, nil
+}
+
+func parseExecutionOrderArgs(params *RawParams) (*core.ExecutionOrderForm, error) {
+	if err := checkNArgs(params, []int{0}, []int{8, 10}); err != nil {
+		return nil, err
+	}
+	sell, err := checkBoolArg(params.Args[1], "sell")
+	if err != nil {
+		return nil, err
+	}
+	base, err := checkUIntArg(params.Args[2], "base", 32)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := checkUIntArg(params.Args[3], "quote", 32)
+	if err != nil {
+		return nil, err
+	}
+	qty, err := checkUIntArg(params.Args[4], "qty", 64)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := checkUIntArg(params.Args[5], "rate", 64)
+	if err != nil {
+		return nil, err
+	}
+	form := &core.ExecutionOrderForm{
+		Host:  params.Args[0],
+		Sell:  sell,
+		Base:  uint32(base),
+		Quote: uint32(quote),
+		Qty:   qty,
+		Rate:  rate,
+		Algo:  params.Args[6],
+	}
+	var nextArg int
+	switch form.Algo {
+	case "twap":
+		if len(params.Args) < 9 {
+			return nil, fmt.Errorf("%w: twap orders require slices and interval", errArgs)
+		}
+		slices, err := checkUIntArg(params.Args[7], "slices", 32)
+		if err != nil {
+			return nil, err
+		}
+		form.Slices = uint32(slices)
+		form.Interval, err = checkUIntArg(params.Args[8], "interval", 64)
+		if err != nil {
+			return nil, err
+		}
+		nextArg = 9
+	case "iceberg":
+		form.VisibleQty, err = checkUIntArg(params.Args[7], "visibleQty", 64)
+		if err != nil {
+			return nil, err
+		}
+		nextArg = 8
+	default:
+		return nil, fmt.Errorf("%w: unknown algorithm %q", errArgs, form.Algo)
+	}
+	switch len(params.Args) - nextArg {
+	case 0:
+	case 1:
+		form.Options, err = checkMapArg(params.Args[nextArg], "options")
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: too many arguments", errArgs)
+	}
+	return form, nil
+}
+
+func parseExecutionIDArgs(params *RawParams) (dex.Bytes, error) {
+	if err := checkNArgs(params, []int{0}, []int{1}); err != nil {
+		return nil, err
+	}
+	id, err := hex.DecodeString(params.Args[0])
+	if err != nil || len(id) == 0 {
+		return nil, fmt.Errorf("%w: invalid execution order id hex", errArgs)
+	}
+	return id, nil
+}
+
 func parseCancelArgs(params *RawParams) (*cancelForm, error) {
 	if err := checkNArgs(params, []int{0}, []int{1}); err != nil {
 		return nil, err
@@ -924,6 +1130,84 @@ func parseBotBalances(balanceArg string) (map[uint32]uint64, error) {
 	return toReturn, nil
 }
 
+func checkExportFormat(format string) error {
+	switch format {
+	case mm.RunExportCSV, mm.RunExportJSON:
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown export format %q", errArgs, format)
+	}
+}
+
+func parseMMExportRunArgs(params *RawParams) (*mmExportForm, error) {
+	if err := checkNArgs(params, []int{0}, []int{6}); err != nil {
+		return nil, err
+	}
+	form := &mmExportForm{
+		filePath: params.Args[0],
+		format:   params.Args[1],
+	}
+	if err := checkExportFormat(form.format); err != nil {
+		return nil, err
+	}
+	mkt, err := parseMktWithHost(params.Args[2], params.Args[3], params.Args[4])
+	if err != nil {
+		return nil, err
+	}
+	form.mkt = mkt
+	form.startTime, err = checkIntArg(params.Args[5], "startTime", 64)
+	if err != nil {
+		return nil, err
+	}
+	return form, nil
+}
+
+func parseMMExportRunsArgs(params *RawParams) (*mmExportForm, error) {
+	if err := checkNArgs(params, []int{0}, []int{3, 4}); err != nil {
+		return nil, err
+	}
+	form := &mmExportForm{
+		filePath: params.Args[0],
+		format:   params.Args[1],
+	}
+	if err := checkExportFormat(form.format); err != nil {
+		return nil, err
+	}
+	var err error
+	form.from, err = checkIntArg(params.Args[2], "from", 64)
+	if err != nil {
+		return nil, err
+	}
+	if len(params.Args) > 3 {
+		form.to, err = checkIntArg(params.Args[3], "to", 64)
+		if err != nil {
+			return nil, err
+		}
+		if form.to < form.from {
+			return nil, fmt.Errorf("%w: to is before from", errArgs)
+		}
+	}
+	return form, nil
+}
+
+func parsePortfolioRebalanceArgs(params *RawParams) (*mm.PortfolioRebalanceConfig, error) {
+	if err := checkNArgs(params, []int{0}, []int{1, 2}); err != nil {
+		return nil, err
+	}
+	feeMultiplier, err := strconv.ParseFloat(params.Args[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot parse feeMultiplier: %v", errArgs, err)
+	}
+	cfg := &mm.PortfolioRebalanceConfig{FeeMultiplier: feeMultiplier}
+	if len(params.Args) > 1 {
+		cfg.MinTransfer, err = parseBotBalances(params.Args[1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid minTransfer: %v", errArgs, err)
+		}
+	}
+	return cfg, nil
+}
+
 func parseStartBotArgs(params *RawParams) (*startBotForm, error) {
 	if err := checkNArgs(params, []int{1}, []int{6}); err != nil {
 		return nil, err
@@ -7,8 +7,11 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/mm"
 	"decred.org/dcrdex/dex/encode"
 )
 
@@ -445,6 +448,35 @@ func TestTradeArgs(t *testing.T) {
 	}
 }
 
+func TestTradeArgsTimeInForce(t *testing.T) {
+	tests := []struct {
+		arg                          string
+		tifNow, fillOrKill, postOnly bool
+	}{
+		{arg: "false"},
+		{arg: "standing"},
+		{arg: "true", tifNow: true},
+		{arg: "immediate", tifNow: true},
+		{arg: "fillorkill", fillOrKill: true},
+		{arg: "postonly", postOnly: true},
+	}
+	for _, test := range tests {
+		params := &RawParams{
+			PWArgs: []encode.PassBytes{encode.PassBytes("password123")},
+			Args:   []string{"dex:1234", "true", "true", "0", "42", "1", "1", test.arg, "{}"},
+		}
+		form, err := parseTradeArgs(params)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.arg, err)
+		}
+		f := form.srvForm
+		if f.TifNow != test.tifNow || f.FillOrKill != test.fillOrKill || f.PostOnly != test.postOnly {
+			t.Fatalf("%s: wrong time in force flags. tifNow = %t, fillOrKill = %t, postOnly = %t",
+				test.arg, f.TifNow, f.FillOrKill, f.PostOnly)
+		}
+	}
+}
+
 func TestParseCancelArgs(t *testing.T) {
 	paramsWithOrderID := func(orderID string) *RawParams {
 		return &RawParams{Args: []string{orderID}}
@@ -482,6 +514,119 @@ func TestParseCancelArgs(t *testing.T) {
 	}
 }
 
+func TestParseConditionalOrderArgs(t *testing.T) {
+	paramsWithArgs := func(trigger, triggerPrice string) *RawParams {
+		return &RawParams{Args: []string{"dex", "true", "false", "42", "0", "100000000", "150000", "false", "takeprofit", trigger, triggerPrice}}
+	}
+	tests := []struct {
+		name                string
+		params              *RawParams
+		wantTriggerRate     uint64
+		wantTriggerFiatRate float64
+		wantErr             error
+	}{{
+		name:            "ok midgap",
+		params:          paramsWithArgs("midgap", "140000"),
+		wantTriggerRate: 140000,
+	}, {
+		name:                "ok fiat",
+		params:              paramsWithArgs("fiat", "12.5"),
+		wantTriggerFiatRate: 12.5,
+	}, {
+		name:    "midgap price not an integer",
+		params:  paramsWithArgs("midgap", "12.5"),
+		wantErr: errArgs,
+	}, {
+		name:    "fiat price not a number",
+		params:  paramsWithArgs("fiat", "abc"),
+		wantErr: errArgs,
+	}, {
+		name:    "unknown trigger",
+		params:  paramsWithArgs("spot", "140000"),
+		wantErr: errArgs,
+	}, {
+		name:    "too few args",
+		params:  &RawParams{Args: []string{"dex", "true"}},
+		wantErr: errArgs,
+	}}
+	for _, test := range tests {
+		form, err := parseConditionalOrderArgs(test.params)
+		if test.wantErr != nil {
+			if errors.Is(err, test.wantErr) {
+				continue
+			}
+			t.Fatalf("expected error for test %v", test.name)
+		}
+		if err != nil {
+			t.Fatalf("unexpected error %v for test %s", err, test.name)
+		}
+		if form.Trade.Host != "dex" || !form.Trade.IsLimit || form.Trade.Sell || form.Trade.Base != 42 ||
+			form.Trade.Qty != 100000000 || form.Trade.Rate != 150000 || form.Type != "takeprofit" {
+			t.Fatalf("wrong form for test %s: %+v", test.name, form)
+		}
+		if form.TriggerRate != test.wantTriggerRate || form.TriggerFiatRate != test.wantTriggerFiatRate {
+			t.Fatalf("wrong trigger price for test %s", test.name)
+		}
+	}
+}
+
+func TestParseExecutionOrderArgs(t *testing.T) {
+	paramsWithArgs := func(args ...string) *RawParams {
+		return &RawParams{Args: append([]string{"dex", "true", "42", "0", "1000000000", "150000"}, args...)}
+	}
+	tests := []struct {
+		name    string
+		params  *RawParams
+		want    *core.ExecutionOrderForm
+		wantErr error
+	}{{
+		name:   "ok twap",
+		params: paramsWithArgs("twap", "10", "600"),
+		want:   &core.ExecutionOrderForm{Algo: "twap", Slices: 10, Interval: 600},
+	}, {
+		name:   "ok iceberg with options",
+		params: paramsWithArgs("iceberg", "100000000", `{"swapfeebump":"1.2"}`),
+		want: &core.ExecutionOrderForm{
+			Algo:       "iceberg",
+			VisibleQty: 100000000,
+			Options:    map[string]string{"swapfeebump": "1.2"},
+		},
+	}, {
+		name:    "twap missing interval",
+		params:  paramsWithArgs("twap", "10"),
+		wantErr: errArgs,
+	}, {
+		name:    "iceberg too many args",
+		params:  paramsWithArgs("iceberg", "100000000", "{}", "{}"),
+		wantErr: errArgs,
+	}, {
+		name:    "unknown algo",
+		params:  paramsWithArgs("vwap", "10"),
+		wantErr: errArgs,
+	}, {
+		name:    "bad slices",
+		params:  paramsWithArgs("twap", "ten", "600"),
+		wantErr: errArgs,
+	}}
+	for _, test := range tests {
+		form, err := parseExecutionOrderArgs(test.params)
+		if test.wantErr != nil {
+			if errors.Is(err, test.wantErr) {
+				continue
+			}
+			t.Fatalf("expected error for test %v", test.name)
+		}
+		if err != nil {
+			t.Fatalf("unexpected error %v for test %s", err, test.name)
+		}
+		want := test.want
+		want.Host, want.Sell, want.Base, want.Quote, want.Qty, want.Rate = "dex", true, 42, 0, 1000000000, 150000
+		if !reflect.DeepEqual(form, want) {
+			t.Fatalf("wrong form for test %s: %+v", test.name, form)
+		}
+	}
+}
+
 func TestParseSendOrWithdrawArgs(t *testing.T) {
 	paramsWithArgs := func(id, value string) *RawParams {
 		pw := encode.PassBytes("password123")
@@ -760,6 +905,112 @@ func TestDeleteArchivedRecordsArgs(t *testing.T) {
 	}
 }
 
+func TestMMExportArgs(t *testing.T) {
+	paramsWithArgs := func(args ...string) *RawParams {
+		return &RawParams{Args: args}
+	}
+	tests := []struct {
+		name    string
+		parse   func(*RawParams) (*mmExportForm, error)
+		params  *RawParams
+		wantErr error
+	}{{
+		name:   "ok run",
+		parse:  parseMMExportRunArgs,
+		params: paramsWithArgs("run.csv", "csv", "dex.com", "42", "0", "1700000000"),
+	}, {
+		name:    "run unknown format",
+		parse:   parseMMExportRunArgs,
+		params:  paramsWithArgs("run.xml", "xml", "dex.com", "42", "0", "1700000000"),
+		wantErr: errArgs,
+	}, {
+		name:    "run start time not a number",
+		parse:   parseMMExportRunArgs,
+		params:  paramsWithArgs("run.csv", "csv", "dex.com", "42", "0", "abc"),
+		wantErr: errArgs,
+	}, {
+		name:   "ok runs no end",
+		parse:  parseMMExportRunsArgs,
+		params: paramsWithArgs("runs.json", "json", "1700000000"),
+	}, {
+		name:   "ok runs",
+		parse:  parseMMExportRunsArgs,
+		params: paramsWithArgs("runs.json", "json", "1700000000", "1800000000"),
+	}, {
+		name:    "runs end before start",
+		parse:   parseMMExportRunsArgs,
+		params:  paramsWithArgs("runs.json", "json", "1800000000", "1700000000"),
+		wantErr: errArgs,
+	}, {
+		name:    "runs no start",
+		parse:   parseMMExportRunsArgs,
+		params:  paramsWithArgs("runs.json", "json"),
+		wantErr: errArgs,
+	}}
+	for _, test := range tests {
+		_, err := test.parse(test.params)
+		if test.wantErr != nil {
+			if errors.Is(err, test.wantErr) {
+				continue
+			}
+			t.Fatalf("%q: expected error %v, got %v", test.name, test.wantErr, err)
+		}
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", test.name, err)
+		}
+	}
+}
+
+func TestParsePortfolioRebalanceArgs(t *testing.T) {
+	paramsWithArgs := func(args ...string) *RawParams {
+		return &RawParams{Args: args}
+	}
+	tests := []struct {
+		name    string
+		params  *RawParams
+		wantCfg *mm.PortfolioRebalanceConfig
+		wantErr error
+	}{{
+		name:    "ok",
+		params:  paramsWithArgs("2.5"),
+		wantCfg: &mm.PortfolioRebalanceConfig{FeeMultiplier: 2.5},
+	}, {
+		name:   "ok with min transfer",
+		params: paramsWithArgs("2", "[[60,1000000],[42,10000000]]"),
+		wantCfg: &mm.PortfolioRebalanceConfig{
+			FeeMultiplier: 2,
+			MinTransfer:   map[uint32]uint64{60: 1e6, 42: 1e7},
+		},
+	}, {
+		name:    "bad fee multiplier",
+		params:  paramsWithArgs("abc"),
+		wantErr: errArgs,
+	}, {
+		name:    "negative min transfer",
+		params:  paramsWithArgs("2", "[[60,-1]]"),
+		wantErr: errArgs,
+	}, {
+		name:    "no args",
+		params:  paramsWithArgs(),
+		wantErr: errArgs,
+	}}
+	for _, test := range tests {
+		cfg, err := parsePortfolioRebalanceArgs(test.params)
+		if test.wantErr != nil {
+			if errors.Is(err, test.wantErr) {
+				continue
+			}
+			t.Fatalf("%q: expected error %v, got %v", test.name, test.wantErr, err)
+		}
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(cfg, test.wantCfg) {
+			t.Fatalf("%q: wanted %+v, got %+v", test.name, test.wantCfg, cfg)
+		}
+	}
+}
+
 func TestParseSetVSPArgs(t *testing.T) {
 	paramsWithArgs := func(args ...string) *RawParams {
 		return &RawParams{Args: args}
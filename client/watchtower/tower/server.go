@@ -0,0 +1,73 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package tower
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+
+	"decred.org/dcrdex/client/watchtower"
+	"github.com/go-chi/chi/v5"
+)
+
+// maxJobSize is the maximum size of a job request body.
+const maxJobSize = 1 << 20
+
+// Handler returns the HTTP handler for the Tower's API. If apiKey is not
+// empty, requests must set the watchtower.APIKeyHeader header to apiKey.
+//
+//	POST /watch    body: watchtower.Job         response: watchtower.WatchResult
+//	GET  /job/{id}                              response: watchtower.JobState
+func (t *Tower) Handler(apiKey string) http.Handler {
+	mux := chi.NewRouter()
+	if apiKey != "" {
+		mux.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				k := r.Header.Get(watchtower.APIKeyHeader)
+				if subtle.ConstantTimeCompare([]byte(k), []byte(apiKey)) != 1 {
+					writeError(w, http.StatusUnauthorized, "unauthorized")
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		})
+	}
+	mux.Post("/watch", t.handleWatch)
+	mux.Get("/job/{id}", t.handleJob)
+	return mux
+}
+
+func (t *Tower) handleWatch(w http.ResponseWriter, r *http.Request) {
+	job := new(watchtower.Job)
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJobSize)).Decode(job); err != nil {
+		writeError(w, http.StatusBadRequest, "error decoding job: "+err.Error())
+		return
+	}
+	id, err := t.Watch(job)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, &watchtower.WatchResult{ID: id})
+}
+
+func (t *Tower) handleJob(w http.ResponseWriter, r *http.Request) {
+	js, found := t.Job(chi.URLParam(r, "id"))
+	if !found {
+		writeError(w, http.StatusNotFound, "unknown job")
+		return
+	}
+	writeJSON(w, http.StatusOK, js)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, &watchtower.ErrorResult{Error: msg})
+}
+
+func writeJSON(w http.ResponseWriter, code int, thing any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(thing)
+}
@@ -0,0 +1,353 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+// Package tower implements a swap watchtower. The Tower monitors the swap
+// contracts of watchtower.Jobs using the same asset backends that the DEX
+// server uses, and broadcasts the jobs' pre-signed transactions when the
+// client is unable to.
+package tower
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/client/watchtower"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/asset"
+	"go.etcd.io/bbolt"
+)
+
+const (
+	// DefaultMaxJobs is the default maximum number of unfinished jobs.
+	DefaultMaxJobs = 10_000
+	// unseenExpiry is how long a job's contract can go unseen before the job
+	// expires.
+	unseenExpiry = 48 * time.Hour
+	// finishedRetention is how long finished jobs are kept so that their
+	// status can be queried.
+	finishedRetention = 7 * 24 * time.Hour
+)
+
+var jobsBucket = []byte("jobs")
+
+// Backend is the part of a server asset backend that the Tower uses.
+type Backend interface {
+	// Contract returns the swap contract if it is unspent. If the contract
+	// has been spent or does not exist, asset.CoinNotFoundError is returned.
+	Contract(coinID []byte, contractData []byte) (*asset.Contract, error)
+	// ValidateContract checks that the contract data is valid for the asset.
+	ValidateContract(contract []byte) error
+	// ValidateCoinID checks that the coin ID is valid for the asset.
+	ValidateCoinID(coinID []byte) (string, error)
+	// BlockChannel returns a channel that receives a message for every new
+	// block.
+	BlockChannel(size int) <-chan *asset.BlockUpdate
+	// SendRawTransaction broadcasts a raw transaction, returning a coin ID.
+	SendRawTransaction(rawTx []byte) (coinID []byte, err error)
+}
+
+// Config is the configuration for a Tower.
+type Config struct {
+	// Backends are the connected asset backends, keyed by asset ID.
+	Backends map[uint32]Backend
+	// DBPath is the path of the database file. The file is created if it
+	// does not exist.
+	DBPath string
+	// MaxJobs is the maximum number of unfinished jobs. The default is
+	// DefaultMaxJobs.
+	MaxJobs int
+	Logger  dex.Logger
+}
+
+// Tower watches swap contracts and broadcasts the transactions that spend
+// them.
+type Tower struct {
+	log      dex.Logger
+	db       *bbolt.DB
+	backends map[uint32]Backend
+	maxJobs  int
+
+	jobsMtx sync.RWMutex
+	jobs    map[string]*watchtower.JobState
+
+	// now can be replaced for testing.
+	now func() time.Time
+}
+
+// New is the constructor for a Tower. Jobs saved in the database are loaded.
+func New(cfg *Config) (*Tower, error) {
+	if len(cfg.Backends) == 0 {
+		return nil, errors.New("no asset backends")
+	}
+	maxJobs := cfg.MaxJobs
+	if maxJobs == 0 {
+		maxJobs = DefaultMaxJobs
+	}
+
+	db, err := bbolt.Open(cfg.DBPath, 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening database: %w", err)
+	}
+
+	jobs := make(map[string]*watchtower.JobState)
+	err = db.Update(func(tx *bbolt.Tx) error {
+		bkt, err := tx.CreateBucketIfNotExists(jobsBucket)
+		if err != nil {
+			return err
+		}
+		return bkt.ForEach(func(k, v []byte) error {
+			js := new(watchtower.JobState)
+			if err := json.Unmarshal(v, js); err != nil {
+				return fmt.Errorf("error decoding job %s: %w", k, err)
+			}
+			jobs[string(k)] = js
+			return nil
+		})
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Tower{
+		log:      cfg.Logger,
+		db:       db,
+		backends: cfg.Backends,
+		maxJobs:  maxJobs,
+		jobs:     jobs,
+		now:      time.Now,
+	}, nil
+}
+
+// Run monitors the chains of the configured assets until the context is
+// canceled. The jobs of an asset are checked on startup and with every new
+// block.
+func (t *Tower) Run(ctx context.Context) {
+	defer t.db.Close()
+
+	var wg sync.WaitGroup
+	for assetID, be := range t.backends {
+		blocks := be.BlockChannel(8)
+		wg.Add(1)
+		go func(assetID uint32) {
+			defer wg.Done()
+			t.checkJobs(ctx, assetID)
+			for {
+				select {
+				case u := <-blocks:
+					if u.Err != nil {
+						t.log.Errorf("%s block update error: %v", dex.BipIDSymbol(assetID), u.Err)
+						continue
+					}
+					t.checkJobs(ctx, assetID)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(assetID)
+	}
+	wg.Wait()
+}
+
+// Watch adds a job. If a job with the same ID already exists, its transaction
+// is replaced and it is watched again.
+func (t *Tower) Watch(job *watchtower.Job) (string, error) {
+	if err := job.Validate(); err != nil {
+		return "", err
+	}
+	be, found := t.backends[job.AssetID]
+	if !found {
+		return "", fmt.Errorf("asset %d is not supported", job.AssetID)
+	}
+	if _, err := be.ValidateCoinID(job.CoinID); err != nil {
+		return "", fmt.Errorf("invalid coin ID: %w", err)
+	}
+	if err := be.ValidateContract(job.Contract); err != nil {
+		return "", fmt.Errorf("invalid contract: %w", err)
+	}
+
+	id := job.ID()
+	t.jobsMtx.Lock()
+	defer t.jobsMtx.Unlock()
+	if _, found := t.jobs[id]; !found && t.unfinishedJobs() >= t.maxJobs {
+		return "", errors.New("too many jobs")
+	}
+	js := &watchtower.JobState{
+		Job:    job,
+		Status: watchtower.StatusWatching,
+		Added:  t.now().Unix(),
+	}
+	if err := t.saveJob(id, js); err != nil {
+		return "", err
+	}
+	t.jobs[id] = js
+	t.log.Infof("Watching %s %s contract %s", dex.BipIDSymbol(job.AssetID), job.Action, job.CoinID)
+	return id, nil
+}
+
+// Job returns a copy of the state of the job with the specified ID.
+func (t *Tower) Job(id string) (*watchtower.JobState, bool) {
+	t.jobsMtx.RLock()
+	defer t.jobsMtx.RUnlock()
+	js, found := t.jobs[id]
+	if !found {
+		return nil, false
+	}
+	jsCopy := *js
+	return &jsCopy, true
+}
+
+// unfinishedJobs is the number of jobs that are watching or broadcast. The
+// jobsMtx must be held.
+func (t *Tower) unfinishedJobs() (n int) {
+	for _, js := range t.jobs {
+		if js.Finished == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (t *Tower) saveJob(id string, js *watchtower.JobState) error {
+	b, err := json.Marshal(js)
+	if err != nil {
+		return err
+	}
+	return t.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(jobsBucket).Put([]byte(id), b)
+	})
+}
+
+func (t *Tower) deleteJob(id string) error {
+	return t.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(jobsBucket).Delete([]byte(id))
+	})
+}
+
+// checkJobs checks the contracts of an asset's unfinished jobs, broadcasting
+// transactions that are ready, and removes jobs that finished long ago. The
+// jobs are copied so that the backend is not queried with the jobsMtx held.
+func (t *Tower) checkJobs(ctx context.Context, assetID uint32) {
+	be := t.backends[assetID]
+	now := t.now()
+
+	type jobCopy struct {
+		id   string
+		orig *watchtower.JobState
+		js   watchtower.JobState
+	}
+
+	t.jobsMtx.Lock()
+	ids := make([]string, 0, len(t.jobs))
+	for id, js := range t.jobs {
+		if js.AssetID == assetID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	unfinished := make([]*jobCopy, 0, len(ids))
+	for _, id := range ids {
+		js := t.jobs[id]
+		if js.Finished == 0 {
+			unfinished = append(unfinished, &jobCopy{id: id, orig: js, js: *js})
+			continue
+		}
+		if now.Sub(time.Unix(js.Finished, 0)) > finishedRetention {
+			if err := t.deleteJob(id); err != nil {
+				t.log.Errorf("Error deleting job %s: %v", id, err)
+				continue
+			}
+			delete(t.jobs, id)
+		}
+	}
+	t.jobsMtx.Unlock()
+
+	for _, jc := range unfinished {
+		if !t.checkJob(ctx, be, &jc.js, now) {
+			continue
+		}
+		t.jobsMtx.Lock()
+		// Skip the update if the job was replaced by Watch while it was being
+		// checked.
+		if t.jobs[jc.id] == jc.orig {
+			*jc.orig = jc.js
+			if err := t.saveJob(jc.id, jc.orig); err != nil {
+				t.log.Errorf("Error saving job %s: %v", jc.id, err)
+			}
+		}
+		t.jobsMtx.Unlock()
+	}
+}
+
+// checkJob checks the contract of an unfinished job and broadcasts its
+// transaction if it is ready. The returned bool is true if the job's state
+// changed.
+func (t *Tower) checkJob(ctx context.Context, be Backend, js *watchtower.JobState, now time.Time) bool {
+	symbol := dex.BipIDSymbol(js.AssetID)
+	contract, err := be.Contract(js.CoinID, js.Contract)
+	if err != nil {
+		if !errors.Is(err, asset.CoinNotFoundError) {
+			t.log.Errorf("Error checking %s contract %s: %v", symbol, js.CoinID, err)
+			return false
+		}
+		switch {
+		case js.Seen:
+			// We can't tell whether the contract was spent by our
+			// transaction or by the counterparty's.
+			js.Status = watchtower.StatusSpent
+			t.log.Infof("%s contract %s for %s job has been spent", symbol, js.CoinID, js.Action)
+		case now.Sub(time.Unix(js.Added, 0)) > unseenExpiry:
+			js.Status = watchtower.StatusExpired
+			t.log.Warnf("%s contract %s for %s job was never found", symbol, js.CoinID, js.Action)
+		default:
+			return false
+		}
+		js.Finished = now.Unix()
+		return true
+	}
+
+	changed := !js.Seen
+	js.Seen = true
+	js.LockTime = contract.LockTime.Unix()
+
+	switch js.Action {
+	case watchtower.ActionRefund:
+		if now.Before(contract.LockTime) {
+			return changed
+		}
+	case watchtower.ActionRedeem:
+		if js.Confs > 0 {
+			confs, err := contract.Confirmations(ctx)
+			if err != nil {
+				t.log.Errorf("Error getting confirmations for %s contract %s: %v", symbol, js.CoinID, err)
+				return changed
+			}
+			if confs < int64(js.Confs) {
+				return changed
+			}
+		}
+	}
+
+	// The transaction is broadcast again with every block until the contract
+	// is spent, in case it was dropped from the mempool.
+	coinID, err := be.SendRawTransaction(js.Tx)
+	if err != nil {
+		// A refund may be rejected for a short time after the locktime if
+		// the chain's median time has not caught up.
+		t.log.Errorf("Error broadcasting %s %s for contract %s: %v", symbol, js.Action, js.CoinID, err)
+		js.Error = err.Error()
+		return true
+	}
+	if js.Status != watchtower.StatusBroadcast {
+		t.log.Infof("Broadcast %s %s %s for contract %s", symbol, js.Action, dex.Bytes(coinID), js.CoinID)
+	}
+	js.Status = watchtower.StatusBroadcast
+	js.BroadcastID = coinID
+	js.Error = ""
+	return true
+}
@@ -0,0 +1,351 @@
+package tower
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/client/watchtower"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/asset"
+)
+
+var (
+	tLogger = dex.StdOutLogger("TOWER_TEST", dex.LevelInfo)
+	tCtx    = context.Background()
+)
+
+type tCoin struct {
+	asset.Coin
+	confs int64
+}
+
+func (c *tCoin) Confirmations(context.Context) (int64, error) { return c.confs, nil }
+
+type tBackend struct {
+	contract    *asset.Contract
+	contractErr error
+	sent        [][]byte
+	sendErr     error
+	blocks      chan *asset.BlockUpdate
+	// contractCalled and contractRelease, if set, make Contract block.
+	contractCalled  chan struct{}
+	contractRelease chan struct{}
+}
+
+func newTBackend() *tBackend {
+	return &tBackend{
+		contractErr: asset.CoinNotFoundError,
+		blocks:      make(chan *asset.BlockUpdate, 1),
+	}
+}
+
+func (b *tBackend) Contract(coinID []byte, contractData []byte) (*asset.Contract, error) {
+	if b.contractCalled != nil {
+		b.contractCalled <- struct{}{}
+		<-b.contractRelease
+	}
+	return b.contract, b.contractErr
+}
+func (b *tBackend) ValidateContract(contract []byte) error { return nil }
+func (b *tBackend) ValidateCoinID(coinID []byte) (string, error) {
+	return dex.Bytes(coinID).String(), nil
+}
+func (b *tBackend) BlockChannel(size int) <-chan *asset.BlockUpdate { return b.blocks }
+func (b *tBackend) SendRawTransaction(rawTx []byte) ([]byte, error) {
+	if b.sendErr != nil {
+		return nil, b.sendErr
+	}
+	b.sent = append(b.sent, rawTx)
+	return []byte{0x0b}, nil
+}
+
+func newTestTower(t *testing.T, be *tBackend) *Tower {
+	t.Helper()
+	tower, err := New(&Config{
+		Backends: map[uint32]Backend{0: be},
+		DBPath:   filepath.Join(t.TempDir(), "tower.db"),
+		MaxJobs:  2,
+		Logger:   tLogger,
+	})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	return tower
+}
+
+func tJob(action string, coinID byte) *watchtower.Job {
+	return &watchtower.Job{
+		AssetID:  0,
+		CoinID:   dex.Bytes{coinID},
+		Contract: dex.Bytes{0x02},
+		Action:   action,
+		Tx:       dex.Bytes{0x03},
+	}
+}
+
+func TestRefundJob(t *testing.T) {
+	be := newTBackend()
+	tower := newTestTower(t, be)
+	defer tower.db.Close()
+
+	now := time.Now()
+	tower.now = func() time.Time { return now }
+
+	id, err := tower.Watch(tJob(watchtower.ActionRefund, 0x01))
+	if err != nil {
+		t.Fatalf("Watch error: %v", err)
+	}
+
+	checkStatus := func(tag, status string) *watchtower.JobState {
+		t.Helper()
+		js, found := tower.Job(id)
+		if !found {
+			t.Fatalf("%s: job not found", tag)
+		}
+		if js.Status != status {
+			t.Fatalf("%s: wanted status %s, got %s", tag, status, js.Status)
+		}
+		return js
+	}
+
+	// Contract not found yet.
+	tower.checkJobs(tCtx, 0)
+	checkStatus("unseen", watchtower.StatusWatching)
+
+	// Contract found, but the locktime has not passed.
+	be.contract = &asset.Contract{LockTime: now.Add(time.Hour)}
+	be.contractErr = nil
+	tower.checkJobs(tCtx, 0)
+	if js := checkStatus("locked", watchtower.StatusWatching); !js.Seen {
+		t.Fatalf("contract not marked seen")
+	}
+	if len(be.sent) != 0 {
+		t.Fatalf("refund broadcast before locktime")
+	}
+
+	// Locktime passed, but broadcast fails.
+	now = now.Add(2 * time.Hour)
+	be.sendErr = errors.New("test error")
+	tower.checkJobs(tCtx, 0)
+	if js := checkStatus("send error", watchtower.StatusWatching); js.Error == "" {
+		t.Fatalf("broadcast error not recorded")
+	}
+
+	// Broadcast succeeds on the next block.
+	be.sendErr = nil
+	tower.checkJobs(tCtx, 0)
+	if js := checkStatus("broadcast", watchtower.StatusBroadcast); js.Error != "" {
+		t.Fatalf("broadcast error not cleared")
+	}
+	if len(be.sent) != 1 {
+		t.Fatalf("expected 1 broadcast, got %d", len(be.sent))
+	}
+
+	// Contract spent.
+	be.contractErr = asset.CoinNotFoundError
+	tower.checkJobs(tCtx, 0)
+	if js := checkStatus("spent", watchtower.StatusSpent); js.Finished == 0 {
+		t.Fatalf("finished time not set")
+	}
+
+	// Finished jobs are pruned after the retention period.
+	now = now.Add(finishedRetention + time.Hour)
+	tower.checkJobs(tCtx, 0)
+	if _, found := tower.Job(id); found {
+		t.Fatalf("finished job not pruned")
+	}
+}
+
+func TestCheckJobsUnlocked(t *testing.T) {
+	be := newTBackend()
+	tower := newTestTower(t, be)
+	defer tower.db.Close()
+
+	id, err := tower.Watch(tJob(watchtower.ActionRefund, 0x01))
+	if err != nil {
+		t.Fatalf("Watch error: %v", err)
+	}
+
+	be.contract = &asset.Contract{LockTime: time.Now().Add(time.Hour)}
+	be.contractErr = nil
+	be.contractCalled = make(chan struct{})
+	be.contractRelease = make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		tower.checkJobs(tCtx, 0)
+		close(done)
+	}()
+	<-be.contractCalled
+
+	// The jobs can be read and added while the backend is being queried.
+	accessed := make(chan struct{})
+	go func() {
+		tower.Job(id)
+		if _, err := tower.Watch(tJob(watchtower.ActionRefund, 0x02)); err != nil {
+			t.Errorf("Watch error: %v", err)
+		}
+		close(accessed)
+	}()
+	select {
+	case <-accessed:
+	case <-time.After(time.Second):
+		t.Fatalf("jobs locked during backend query")
+	}
+
+	close(be.contractRelease)
+	<-done
+	if js, _ := tower.Job(id); !js.Seen {
+		t.Fatalf("contract not marked seen")
+	}
+}
+
+func TestRedeemJob(t *testing.T) {
+	be := newTBackend()
+	tower := newTestTower(t, be)
+	defer tower.db.Close()
+
+	coin := &tCoin{confs: 1}
+	be.contract = &asset.Contract{Coin: coin, LockTime: time.Now().Add(time.Hour)}
+	be.contractErr = nil
+	job := tJob(watchtower.ActionRedeem, 0x01)
+	job.Confs = 2
+	id, err := tower.Watch(job)
+	if err != nil {
+		t.Fatalf("Watch error: %v", err)
+	}
+
+	// Not enough confirmations.
+	tower.checkJobs(tCtx, 0)
+	js, _ := tower.Job(id)
+	if !js.Seen || js.Status != watchtower.StatusWatching || len(be.sent) != 0 {
+		t.Fatalf("redemption broadcast before the contract was confirmed")
+	}
+
+	coin.confs = 2
+	tower.checkJobs(tCtx, 0)
+	js, _ = tower.Job(id)
+	if js.Status != watchtower.StatusBroadcast || len(be.sent) != 1 {
+		t.Fatalf("redemption not broadcast")
+	}
+}
+
+func TestUnseenExpiry(t *testing.T) {
+	be := newTBackend()
+	tower := newTestTower(t, be)
+	defer tower.db.Close()
+
+	now := time.Now()
+	tower.now = func() time.Time { return now }
+	id, err := tower.Watch(tJob(watchtower.ActionRefund, 0x01))
+	if err != nil {
+		t.Fatalf("Watch error: %v", err)
+	}
+	now = now.Add(unseenExpiry + time.Minute)
+	tower.checkJobs(tCtx, 0)
+	js, _ := tower.Job(id)
+	if js.Status != watchtower.StatusExpired {
+		t.Fatalf("wanted status expired, got %s", js.Status)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	be := newTBackend()
+	tower := newTestTower(t, be)
+	defer tower.db.Close()
+
+	badAsset := tJob(watchtower.ActionRefund, 0x01)
+	badAsset.AssetID = 42
+	badAction := tJob("steal", 0x01)
+	noTx := tJob(watchtower.ActionRefund, 0x01)
+	noTx.Tx = nil
+	for _, job := range []*watchtower.Job{badAsset, badAction, noTx} {
+		if _, err := tower.Watch(job); err == nil {
+			t.Fatalf("no error for bad job %+v", job)
+		}
+	}
+
+	if _, err := tower.Watch(tJob(watchtower.ActionRefund, 0x01)); err != nil {
+		t.Fatalf("Watch error: %v", err)
+	}
+	if _, err := tower.Watch(tJob(watchtower.ActionRefund, 0x02)); err != nil {
+		t.Fatalf("Watch error: %v", err)
+	}
+	// Replacing an existing job is allowed at the limit.
+	if _, err := tower.Watch(tJob(watchtower.ActionRefund, 0x02)); err != nil {
+		t.Fatalf("Watch error for replacement: %v", err)
+	}
+	if _, err := tower.Watch(tJob(watchtower.ActionRefund, 0x03)); err == nil {
+		t.Fatalf("no error for too many jobs")
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	be := newTBackend()
+	dbPath := filepath.Join(t.TempDir(), "tower.db")
+	cfg := &Config{
+		Backends: map[uint32]Backend{0: be},
+		DBPath:   dbPath,
+		Logger:   tLogger,
+	}
+	tower, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	id, err := tower.Watch(tJob(watchtower.ActionRefund, 0x01))
+	if err != nil {
+		t.Fatalf("Watch error: %v", err)
+	}
+	tower.db.Close()
+
+	tower, err = New(cfg)
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	defer tower.db.Close()
+	if _, found := tower.Job(id); !found {
+		t.Fatalf("job not loaded from database")
+	}
+}
+
+func TestServer(t *testing.T) {
+	be := newTBackend()
+	tower := newTestTower(t, be)
+	defer tower.db.Close()
+
+	srv := httptest.NewServer(tower.Handler("key"))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	job := tJob(watchtower.ActionRefund, 0x01)
+	if _, err := watchtower.NewClient(srv.URL, "wrong").Watch(ctx, job); err == nil {
+		t.Fatalf("no error for wrong API key")
+	}
+
+	cl := watchtower.NewClient(srv.URL, "key")
+	id, err := cl.Watch(ctx, job)
+	if err != nil {
+		t.Fatalf("Watch error: %v", err)
+	}
+	if id != job.ID() {
+		t.Fatalf("wrong job ID %s", id)
+	}
+	js, err := cl.Job(ctx, id)
+	if err != nil {
+		t.Fatalf("Job error: %v", err)
+	}
+	if js.Status != watchtower.StatusWatching || !js.CoinID.Equal(job.CoinID) {
+		t.Fatalf("wrong job state %+v", js)
+	}
+	if _, err := cl.Job(ctx, "nope"); err == nil {
+		t.Fatalf("no error for unknown job")
+	}
+	if _, err := cl.Watch(ctx, tJob("steal", 0x02)); err == nil {
+		t.Fatalf("no error for bad job")
+	}
+}
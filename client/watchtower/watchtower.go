@@ -0,0 +1,187 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+// Package watchtower defines the jobs that a client hands to a swap watchtower
+// and a client for the watchtower's HTTP API. A watchtower monitors swap
+// contracts on the client's behalf and broadcasts the client's pre-signed
+// refund and redemption transactions while the client is offline. See the
+// tower package for the watchtower itself.
+package watchtower
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/dexnet"
+)
+
+// Job actions.
+const (
+	// ActionRefund jobs broadcast the transaction once the contract's
+	// locktime has passed, if the contract has not been spent.
+	ActionRefund = "refund"
+	// ActionRedeem jobs broadcast the transaction once the unspent contract
+	// has the job's required confirmations.
+	ActionRedeem = "redeem"
+)
+
+// Job statuses.
+const (
+	// StatusWatching is the status of a job whose transaction has not been
+	// broadcast.
+	StatusWatching = "watching"
+	// StatusBroadcast is the status of a job whose transaction has been
+	// broadcast, but whose contract has not been seen spent.
+	StatusBroadcast = "broadcast"
+	// StatusSpent is the status of a job whose contract has been spent,
+	// either by the job's transaction or by another one.
+	StatusSpent = "spent"
+	// StatusExpired is the status of a job whose contract was never found.
+	StatusExpired = "expired"
+)
+
+// APIKeyHeader is the HTTP header that carries the watchtower's API key.
+const APIKeyHeader = "X-Api-Key"
+
+// Job is a swap contract that the watchtower should watch, and the signed
+// transaction that spends it.
+type Job struct {
+	AssetID uint32 `json:"assetID"`
+	// CoinID is the coin ID of the swap contract.
+	CoinID dex.Bytes `json:"coinID"`
+	// Contract is the swap contract data, e.g. the redeem script for UTXO
+	// assets.
+	Contract dex.Bytes `json:"contract"`
+	// Action is ActionRefund or ActionRedeem.
+	Action string `json:"action"`
+	// Tx is the serialized, signed transaction that spends the contract.
+	Tx dex.Bytes `json:"tx"`
+	// Confs is the number of confirmations the contract of an ActionRedeem
+	// job must have before the redemption is broadcast. Broadcasting a
+	// redemption reveals the secret, so a maker should not redeem a
+	// counterparty contract that could still be reorged out.
+	Confs uint32 `json:"confs,omitempty"`
+}
+
+// ID is a unique identifier for the job.
+func (j *Job) ID() string {
+	return fmt.Sprintf("%d-%s-%s", j.AssetID, j.CoinID, j.Action)
+}
+
+// Validate checks that the job has all of its fields set.
+func (j *Job) Validate() error {
+	switch j.Action {
+	case ActionRefund, ActionRedeem:
+	default:
+		return fmt.Errorf("unknown action %q", j.Action)
+	}
+	if len(j.CoinID) == 0 {
+		return errors.New("no coin ID")
+	}
+	if len(j.Contract) == 0 {
+		return errors.New("no contract")
+	}
+	if len(j.Tx) == 0 {
+		return errors.New("no transaction")
+	}
+	return nil
+}
+
+// JobState is a job and its progress.
+type JobState struct {
+	*Job
+	Status string `json:"status"`
+	// Added is the time the job was added, in unix seconds.
+	Added int64 `json:"added"`
+	// Seen is true once the contract has been found unspent.
+	Seen bool `json:"seen"`
+	// LockTime is the contract's locktime, in unix seconds. It is set once
+	// the contract has been seen.
+	LockTime int64 `json:"lockTime,omitempty"`
+	// BroadcastID is the coin ID returned by the last successful broadcast of
+	// the transaction.
+	BroadcastID dex.Bytes `json:"broadcastID,omitempty"`
+	// Finished is the time the job was spent or expired, in unix seconds.
+	Finished int64 `json:"finished,omitempty"`
+	// Error is the last error encountered broadcasting the transaction.
+	Error string `json:"error,omitempty"`
+}
+
+// WatchResult is the watchtower's response to a new job.
+type WatchResult struct {
+	ID string `json:"id"`
+}
+
+// ErrorResult is the body of the watchtower's HTTP error responses.
+type ErrorResult struct {
+	Error string `json:"error"`
+}
+
+// Client submits jobs to a watchtower.
+type Client struct {
+	url    string
+	apiKey string
+}
+
+// NewClient is the constructor for a Client. The url is the watchtower's
+// base URL, e.g. http://127.0.0.1:7242.
+func NewClient(url, apiKey string) *Client {
+	return &Client{
+		url:    strings.TrimRight(url, "/"),
+		apiKey: apiKey,
+	}
+}
+
+func (c *Client) do(req *http.Request, thing any) error {
+	errRes := new(ErrorResult)
+	opts := []*dexnet.RequestOption{dexnet.WithErrorParsing(errRes)}
+	if c.apiKey != "" {
+		opts = append(opts, dexnet.WithRequestHeader(APIKeyHeader, c.apiKey))
+	}
+	if err := dexnet.Do(req, thing, opts...); err != nil {
+		if errRes.Error != "" {
+			return fmt.Errorf("%w: %s", err, errRes.Error)
+		}
+		return err
+	}
+	return nil
+}
+
+// Watch submits a job to the watchtower, returning the job's ID. Submitting
+// a job with the same ID as an existing job replaces its transaction.
+func (c *Client) Watch(ctx context.Context, job *Job) (string, error) {
+	b, err := json.Marshal(job)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/watch", bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var res WatchResult
+	if err := c.do(req, &res); err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+// Job retrieves the state of a job by ID.
+func (c *Client) Job(ctx context.Context, id string) (*JobState, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"/job/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	var js JobState
+	if err := c.do(req, &js); err != nil {
+		return nil, err
+	}
+	return &js, nil
+}
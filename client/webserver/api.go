@@ -918,6 +918,65 @@ func (s *WebServer) apiCancel(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, simpleAck())
 }
 
+// apiConditionalOrder is the handler for the '/conditionalorder' API request.
+func (s *WebServer) apiConditionalOrder(w http.ResponseWriter, r *http.Request) {
+	form := new(core.ConditionalOrderForm)
+	if !readPost(w, r, form) {
+		return
+	}
+	ord, err := s.core.PlaceConditionalOrder(form)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("error placing conditional order: %w", err))
+		return
+	}
+	writeJSON(w, &struct {
+		OK    bool                   `json:"ok"`
+		Order *core.ConditionalOrder `json:"order"`
+	}{
+		OK:    true,
+		Order: ord,
+	})
+}
+
+// apiCancelConditionalOrder is the handler for the '/cancelconditionalorder'
+// API request.
+func (s *WebServer) apiCancelConditionalOrder(w http.ResponseWriter, r *http.Request) {
+	form := &struct {
+		ID dex.Bytes `json:"id"`
+	}{}
+	if !readPost(w, r, form) {
+		return
+	}
+	if err := s.core.CancelConditionalOrder(form.ID); err != nil {
+		s.writeAPIError(w, fmt.Errorf("error cancelling conditional order %s: %w", form.ID, err))
+		return
+	}
+	writeJSON(w, simpleAck())
+}
+
+// apiConditionalOrders is the handler for the '/conditionalorders' API
+// request.
+func (s *WebServer) apiConditionalOrders(w http.ResponseWriter, r *http.Request) {
+	form := &struct {
+		ActiveOnly bool `json:"activeOnly"`
+	}{}
+	if !readPost(w, r, form) {
+		return
+	}
+	ords, err := s.core.ConditionalOrders(form.ActiveOnly)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("ConditionalOrders error: %w", err))
+		return
+	}
+	writeJSON(w, &struct {
+		OK     bool                     `json:"ok"`
+		Orders []*core.ConditionalOrder `json:"orders"`
+	}{
+		OK:     true,
+		Orders: ords,
+	})
+}
+
 // apiCloseWallet is the handler for the '/closewallet' API request.
 func (s *WebServer) apiCloseWallet(w http.ResponseWriter, r *http.Request) {
 	form := &struct {
@@ -1846,6 +1905,44 @@ func (s *WebServer) apiMaxFundingFees(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (s *WebServer) apiProposePortfolioRebalance(w http.ResponseWriter, r *http.Request) {
+	var cfg mm.PortfolioRebalanceConfig
+	if !readPost(w, r, &cfg) {
+		return
+	}
+	transfers, err := s.mm.ProposePortfolioRebalance(&cfg)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("error proposing portfolio rebalance: %w", err))
+		return
+	}
+	writeJSON(w, &struct {
+		OK        bool                    `json:"ok"`
+		Transfers []*mm.PortfolioTransfer `json:"transfers"`
+	}{
+		OK:        true,
+		Transfers: transfers,
+	})
+}
+
+func (s *WebServer) apiPortfolioRebalance(w http.ResponseWriter, r *http.Request) {
+	var cfg mm.PortfolioRebalanceConfig
+	if !readPost(w, r, &cfg) {
+		return
+	}
+	transfers, err := s.mm.ExecutePortfolioRebalance(&cfg)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("portfolio rebalance stopped after %d transfers: %w", len(transfers), err))
+		return
+	}
+	writeJSON(w, &struct {
+		OK        bool                    `json:"ok"`
+		Transfers []*mm.PortfolioTransfer `json:"transfers"`
+	}{
+		OK:        true,
+		Transfers: transfers,
+	})
+}
+
 func (s *WebServer) apiSetVSP(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		AssetID uint32 `json:"assetID"`
@@ -2279,3 +2376,24 @@ func (s *WebServer) resolvePass(appPW []byte, r *http.Request) ([]byte, error) {
 	}
 	return cachedPass, nil
 }
+
+// apiTaxReport generates a report of the realized gains from the user's
+// trades.
+func (s *WebServer) apiTaxReport(w http.ResponseWriter, r *http.Request) {
+	form := new(core.TaxReportForm)
+	if !readPost(w, r, form) {
+		return
+	}
+	report, err := s.core.TaxReport(form)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("tax report error: %w", err))
+		return
+	}
+	writeJSON(w, &struct {
+		OK     bool            `json:"ok"`
+		Report *core.TaxReport `json:"report"`
+	}{
+		OK:     true,
+		Report: report,
+	})
+}
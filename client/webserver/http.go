@@ -36,6 +36,9 @@ const (
 	mmSettingsRoute  = "/mmsettings"
 	mmArchivesRoute  = "/mmarchives"
 	mmLogsRoute      = "/mmlogs"
+
+	taxReportRoute       = "/taxreport"
+	exportTaxReportRoute = "/taxreport/export"
 )
 
 // sendTemplate processes the template and sends the result.
@@ -569,3 +572,78 @@ func (s *WebServer) orderReader(ord *core.Order) *core.OrderReader {
 		QuoteFeeAssetSymbol: quoteFeeAssetSymbol,
 	}
 }
+
+type taxReportTmplData struct {
+	CommonArguments
+	RateSources []string
+}
+
+// handleTaxReport is the handler for the '/taxreport' page request.
+func (s *WebServer) handleTaxReport(w http.ResponseWriter, r *http.Request) {
+	s.sendTemplate(w, "taxreport", &taxReportTmplData{
+		CommonArguments: *s.commonArgs(r, "Tax Report | Bison Wallet"),
+		RateSources:     core.HistoricalRateSources(),
+	})
+}
+
+// handleExportTaxReport is the handler for the /taxreport/export page
+// request. The report's realized gains are downloaded as CSV.
+func (s *WebServer) handleExportTaxReport(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		log.Errorf("error parsing form for tax report export: %v", err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	parseStamp := func(k string) (uint64, bool) {
+		v := r.Form.Get(k)
+		if v == "" {
+			return 0, true
+		}
+		stamp, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			log.Errorf("error parsing tax report %s: %v", k, err)
+			return 0, false
+		}
+		return stamp, true
+	}
+	from, ok := parseStamp("from")
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	to, ok := parseStamp("to")
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	form := &core.TaxReportForm{
+		From:       from,
+		To:         to,
+		Method:     core.CostBasisMethod(r.Form.Get("method")),
+		RateSource: r.Form.Get("source"),
+	}
+	for _, assetStrID := range r.Form["assets"] {
+		assetID, err := strconv.ParseUint(assetStrID, 10, 32)
+		if err != nil {
+			log.Errorf("error parsing asset id: %v", err)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		form.AssetIDs = append(form.AssetIDs, uint32(assetID))
+	}
+
+	report, err := s.core.TaxReport(form)
+	if err != nil {
+		log.Errorf("error generating tax report: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", "attachment; filename=taxreport.csv")
+	w.Header().Set("Content-Type", "text/csv")
+	w.WriteHeader(http.StatusOK)
+	if err := report.WriteCSV(w); err != nil {
+		log.Errorf("error writing CSV: %v", err)
+	}
+}
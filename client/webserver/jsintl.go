@@ -180,6 +180,8 @@ const (
 	txTypeMixID                      = "TX_TYPE_MIX"
 	txTypeBridgeInitiationID         = "TX_TYPE_BRIDGE_INITIATION"
 	txTypeBridgeCompletionID         = "TX_TYPE_BRIDGE_COMPLETION"
+	txTypeShieldID                   = "TX_TYPE_SHIELD"
+	txTypeDeshieldID                 = "TX_TYPE_DESHIELD"
 	swapOrSendTooltipID              = "SWAP_OR_SEND_TOOLTIP"
 	missingCexCredsID                = "MISSING_CEX_CREDS"
 	matchBufferID                    = "MATCH_BUFFER"
@@ -404,6 +406,8 @@ var enUS = map[string]*intl.Translation{
 	txTypeMixID:                      {T: "Mix"},
 	txTypeBridgeInitiationID:         {T: "Bridge initiation"},
 	txTypeBridgeCompletionID:         {T: "Bridge completion"},
+	txTypeShieldID:                   {T: "Shield"},
+	txTypeDeshieldID:                 {T: "Deshield"},
 	swapOrSendTooltipID:              {T: "The wallet was unable to determine if this transaction was a swap or a send."},
 	missingCexCredsID:                {T: "specify both key and secret"},
 	matchBufferID:                    {T: "Match buffer"},
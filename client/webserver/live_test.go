@@ -1756,6 +1756,25 @@ func (c *TCore) Cancel(oid dex.Bytes) error {
 	return nil
 }
 
+func (c *TCore) PlaceConditionalOrder(form *core.ConditionalOrderForm) (*core.ConditionalOrder, error) {
+	return &core.ConditionalOrder{
+		ID:              encode.RandomBytes(8),
+		Trade:           form.Trade,
+		Type:            form.Type,
+		Trigger:         form.Trigger,
+		TriggerRate:     form.TriggerRate,
+		TriggerFiatRate: form.TriggerFiatRate,
+		Stamp:           uint64(time.Now().UnixMilli()),
+		Status:          "active",
+	}, nil
+}
+
+func (c *TCore) CancelConditionalOrder(id dex.Bytes) error { return nil }
+
+func (c *TCore) ConditionalOrders(activeOnly bool) ([]*core.ConditionalOrder, error) {
+	return nil, nil
+}
+
 func (c *TCore) NotificationFeed() *core.NoteFeed {
 	return &core.NoteFeed{
 		C: c.noteFeed,
@@ -1926,6 +1945,55 @@ func (c *TCore) ToggleRateSourceStatus(src string, disable bool) error {
 	c.fiatSources[src] = !disable
 	return nil
 }
+func (c *TCore) TaxReport(form *core.TaxReportForm) (*core.TaxReport, error) {
+	to := form.To
+	if to == 0 {
+		to = uint64(time.Now().UnixMilli())
+	}
+	report := &core.TaxReport{
+		From:       form.From,
+		To:         to,
+		Method:     form.Method,
+		RateSource: form.RateSource,
+		Currency:   core.DefaultFiatCurrency,
+	}
+	assets := make(map[uint32]*core.AssetGains)
+	for i := 0; i < 20; i++ {
+		assetID := uint32(42)
+		if i%3 == 0 {
+			assetID = 0
+		}
+		proceeds := rand.Float64() * 1000
+		g := &core.RealizedGain{
+			AssetID:   assetID,
+			Symbol:    dex.BipIDSymbol(assetID),
+			Stamp:     to - uint64(i)*3600_000,
+			Host:      firstDEX,
+			MarketID:  "dcr_btc",
+			OrderID:   encode.RandomBytes(32),
+			MatchID:   encode.RandomBytes(32),
+			Qty:       uint64(rand.Float64() * 1e8),
+			Proceeds:  proceeds,
+			CostBasis: proceeds * (0.5 + rand.Float64()),
+			Fees:      rand.Float64(),
+		}
+		g.Gain = g.Proceeds - g.CostBasis - g.Fees
+		report.Gains = append(report.Gains, g)
+		ag := assets[assetID]
+		if ag == nil {
+			ag = &core.AssetGains{AssetID: assetID, Symbol: g.Symbol}
+			assets[assetID] = ag
+			report.Assets = append(report.Assets, ag)
+		}
+		ag.Disposed += g.Qty
+		ag.Proceeds += g.Proceeds
+		ag.CostBasis += g.CostBasis
+		ag.Fees += g.Fees
+		ag.Gain += g.Gain
+		report.TotalGain += g.Gain
+	}
+	return report, nil
+}
 func (c *TCore) FiatRateSources() map[string]bool {
 	return c.fiatSources
 }
@@ -2611,6 +2679,14 @@ func (m *TMarketMaker) MaxFundingFees(mkt *mm.MarketWithHost, maxBuyPlacements,
 	return 1e4, 1e4, nil
 }
 
+func (m *TMarketMaker) ProposePortfolioRebalance(cfg *mm.PortfolioRebalanceConfig) ([]*mm.PortfolioTransfer, error) {
+	return nil, nil
+}
+
+func (m *TMarketMaker) ExecutePortfolioRebalance(cfg *mm.PortfolioRebalanceConfig) ([]*mm.PortfolioTransfer, error) {
+	return nil, nil
+}
+
 func (m *TMarketMaker) UpdateRunningBotCfg(cfg *mm.BotConfig, balanceDiffs *mm.BotInventoryDiffs, autoRebalanceCfg *mm.AutoRebalanceConfig, saveUpdate bool) error {
 	return nil
 }
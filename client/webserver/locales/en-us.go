@@ -557,6 +557,7 @@ var EnUS = map[string]*intl.Translation{
 	"configure_cex_prompt":        {T: "Configure your exchange API to enable arbitrage features."},
 	"API Key":                     {T: "API Key"},
 	"API Secret":                  {T: "API Secret"},
+	"API Passphrase":              {T: "API Passphrase"},
 	"Available":                   {T: "Available"},
 	"Locked":                      {T: "Locked"},
 	"profit_loss":                 {T: "Profit / Loss"},
@@ -708,4 +709,22 @@ var EnUS = map[string]*intl.Translation{
 	"limit_order_buffer_tooltip":  {T: "This specifies the buffer to apply to the limit order rate for the second leg of a multi-hop arb. The buffer will make the rate 'worse' (lower for sell orders, higher for buy orders) resulting in a higher probability of the trade being filled in order to avoid having funds stuck in the intermediate asset."},
 	"user_op_id":                  {T: "User Op ID"},
 	"user_op_required":            {T: "You do not have enough funds for redemption. A bundler will be used for gasless redemption."},
+	"tax_report":                  {T: "Tax Report"},
+	"tax_report_desc":             {T: "Realized gains and losses from settled trades. Trades before the start date are used to determine the cost basis of later disposals."},
+	"start_date":                  {T: "Start Date"},
+	"end_date":                    {T: "End Date"},
+	"cost_basis_method":           {T: "Cost Basis Method"},
+	"FIFO":                        {T: "First In, First Out"},
+	"LIFO":                        {T: "Last In, First Out"},
+	"Average Cost":                {T: "Average Cost"},
+	"historical_rate_source":      {T: "Historical Rate Source"},
+	"Generate Report":             {T: "Generate Report"},
+	"Export CSV":                  {T: "Export CSV"},
+	"Proceeds":                    {T: "Proceeds"},
+	"Cost Basis":                  {T: "Cost Basis"},
+	"Gain":                        {T: "Gain"},
+	"Total Gain":                  {T: "Total Gain"},
+	"unknown_basis":               {T: "Unknown Basis"},
+	"unknown_basis_tooltip":       {T: "Amount that could not be matched with an earlier trade, such as funds deposited to the wallet. It is given a cost basis of zero."},
+	"missing_rates_warning":       {T: "No historical fiat rates were found for some days. Values that depend on them may be zero."},
 }
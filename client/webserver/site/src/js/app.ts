@@ -11,6 +11,7 @@ import MarketMakerPage from './mm'
 import MarketMakerSettingsPage from './mmsettings'
 import DexSettingsPage from './dexsettings'
 import MarketMakerArchivesPage from './mmarchives'
+import TaxReportPage from './taxreport'
 import MarketMakerLogsPage from './mmlogs'
 import InitPage from './init'
 import { MM } from './mmutil'
@@ -56,6 +57,7 @@ import {
   TransactionActionNote,
   CoreActionRequiredNote,
   RejectedRedemptionData,
+  ShieldFailedData,
   MarketMakingStatus,
   RunStatsNote,
   MMBotStatus,
@@ -108,7 +110,8 @@ const constructors: Record<string, PageClass> = {
   mm: MarketMakerPage,
   mmsettings: MarketMakerSettingsPage,
   mmarchives: MarketMakerArchivesPage,
-  mmlogs: MarketMakerLogsPage
+  mmlogs: MarketMakerLogsPage,
+  taxreport: TaxReportPage
 }
 
 interface LangData {
@@ -654,6 +657,8 @@ export default class Application {
         return this.lostNonceAction(req)
       case 'redeemRejected':
         return this.redeemRejectedAction(req)
+      case 'shieldFailed':
+        return this.shieldFailedAction(req)
     }
     throw Error('unknown required action ID ' + req.actionID)
   }
@@ -780,6 +785,25 @@ export default class Application {
     return div
   }
 
+  shieldFailedAction (req: ActionRequiredNote) {
+    const { assetID } = req
+    const { amount, error } = req.payload as ShieldFailedData
+    const div = this.page.shieldFailedTmpl.cloneNode(true) as PageElement
+    const tmpl = Doc.parseTemplate(div)
+    const { name, unitInfo: ui } = this.assets[assetID]
+    tmpl.assetName.textContent = name
+    tmpl.amount.textContent = Doc.formatCoinValue(amount, ui)
+    tmpl.unit.textContent = ui.conventional.unit
+    tmpl.shieldErr.textContent = error
+    Doc.bind(tmpl.doNothingBttn, 'click', () => {
+      this.submitAction(req, { retry: false }, tmpl.errMsg)
+    })
+    Doc.bind(tmpl.tryAgainBttn, 'click', () => {
+      this.submitAction(req, { retry: true }, tmpl.errMsg)
+    })
+    return div
+  }
+
   showRequestedAction (uniqueID: string) {
     const { page, requiredActions } = this
     Doc.hide(page.actionDialogCollapsed)
@@ -33,7 +33,7 @@ import {
 } from './registry'
 import { XYRangeHandler } from './opts'
 import { CoinExplorers } from './coinexplorers'
-import { MM, setCexElements } from './mmutil'
+import { MM, setCexElements, CEXDisplayInfos } from './mmutil'
 
 interface ConfigOptionInput extends HTMLInputElement {
   configOpt: ConfigOption
@@ -2192,6 +2192,8 @@ export class CEXConfigurationForm {
     Doc.hide(page.cexConfigPrompt, page.cexConnectErrBox, page.cexFormErr)
     page.cexApiKeyInput.value = ''
     page.cexSecretInput.value = ''
+    page.cexPassphraseInput.value = ''
+    Doc.setVis(CEXDisplayInfos[cexName].needsPassphrase, page.cexPassphraseBox)
     const cexStatus = app().mmStatus.cexes[cexName]
     const connectErr = cexStatus?.connectErr
     if (connectErr) {
@@ -2199,6 +2201,7 @@ export class CEXConfigurationForm {
       page.cexConnectErr.textContent = connectErr
       page.cexApiKeyInput.value = cexStatus.config.apiKey
       page.cexSecretInput.value = cexStatus.config.apiSecret
+      page.cexPassphraseInput.value = cexStatus.config.apiPassphrase ?? ''
     } else {
       Doc.show(page.cexConfigPrompt)
     }
@@ -2212,7 +2215,8 @@ export class CEXConfigurationForm {
     Doc.hide(page.cexFormErr)
     const apiKey = page.cexApiKeyInput.value
     const apiSecret = page.cexSecretInput.value
-    if (!apiKey || !apiSecret) {
+    const apiPassphrase = page.cexPassphraseInput.value
+    if (!apiKey || !apiSecret || (CEXDisplayInfos[cexName].needsPassphrase && !apiPassphrase)) {
       Doc.show(page.cexFormErr)
       page.cexFormErr.textContent = intl.prep(intl.ID_NO_PASS_ERROR_MSG)
       return
@@ -2222,7 +2226,8 @@ export class CEXConfigurationForm {
       const res = await MM.updateCEXConfig({
         name: cexName,
         apiKey: apiKey,
-        apiSecret: apiSecret
+        apiSecret: apiSecret,
+        apiPassphrase: apiPassphrase || undefined
       })
       if (!app().checkResponse(res)) throw res
       this.updated(cexName, true)
@@ -181,6 +181,8 @@ export const ID_TX_TYPE_SWAP_OR_SEND = 'TX_TYPE_SWAP_OR_SEND'
 export const ID_TX_TYPE_MIX = 'TX_TYPE_MIX'
 export const ID_TX_TYPE_BRIDGE_INITIATION = 'TX_TYPE_BRIDGE_INITIATION'
 export const ID_TX_TYPE_BRIDGE_COMPLETION = 'TX_TYPE_BRIDGE_COMPLETION'
+export const ID_TX_TYPE_SHIELD = 'TX_TYPE_SHIELD'
+export const ID_TX_TYPE_DESHIELD = 'TX_TYPE_DESHIELD'
 export const ID_SWAP_OR_SEND_TOOLTIP = 'SWAP_OR_SEND_TOOLTIP'
 export const ID_MISSING_CEX_CREDS = 'MISSING_CEX_CREDS'
 export const ID_MATCH_BUFFER = 'MATCH_BUFFER'
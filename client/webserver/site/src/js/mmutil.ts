@@ -49,6 +49,9 @@ export const botTypeBasicArb = 'basicArb'
 export interface CEXDisplayInfo {
   name: string
   logo: string
+  // needsPassphrase is true if the CEX requires an API passphrase in
+  // addition to the API key and secret.
+  needsPassphrase?: boolean
 }
 
 export const CEXDisplayInfos: Record<string, CEXDisplayInfo> = {
@@ -63,6 +66,15 @@ export const CEXDisplayInfos: Record<string, CEXDisplayInfo> = {
   'Coinbase': {
     name: 'Coinbase',
     logo: '/img/coinbase.com.png'
+  },
+  'Kraken': {
+    name: 'Kraken',
+    logo: '/img/kraken.com.png'
+  },
+  'OKX': {
+    name: 'OKX',
+    logo: '/img/okx.com.png',
+    needsPassphrase: true
   }
 }
 
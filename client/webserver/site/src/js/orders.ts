@@ -107,6 +107,8 @@ export default class OrdersPage extends BasePage {
       this.exportOrders()
     })
 
+    Doc.bind(page.taxReport, 'click', () => { app().loadPage('taxreport') })
+
     page.showArchivedDateField.addEventListener('change', () => {
       if (page.showArchivedDateField.checked) Doc.show(page.archivedDateField)
       else Doc.hide(page.archivedDateField, page.deleteArchivedRecordsErr)
@@ -471,6 +471,11 @@ export interface RejectedRedemptionData {
   coinFmt: string
 }
 
+export interface ShieldFailedData {
+  amount: number
+  error: string
+}
+
 export interface SpotPriceNote extends CoreNote {
   host: string
   spots: Record<string, Spot>
@@ -849,6 +854,7 @@ export interface CEXConfig {
   name: string
   apiKey: string
   apiSecret: string
+  apiPassphrase?: string
 }
 
 export interface MarketWithHost {
@@ -0,0 +1,165 @@
+import { app, PageElement } from './registry'
+import { postJSON } from './http'
+import Doc from './doc'
+import BasePage from './basepage'
+
+interface RealizedGain {
+  assetID: number
+  symbol: string
+  stamp: number
+  host: string
+  marketID: string
+  orderID: string
+  matchID: string
+  qty: number
+  proceeds: number
+  costBasis: number
+  fees: number
+  gain: number
+  unknownBasisQty: number
+}
+
+interface AssetGains {
+  assetID: number
+  symbol: string
+  disposed: number
+  proceeds: number
+  costBasis: number
+  fees: number
+  gain: number
+  unknownBasisQty: number
+}
+
+interface TaxReport {
+  from: number
+  to: number
+  method: string
+  rateSource: string
+  currency: string
+  gains: RealizedGain[]
+  assets: AssetGains[]
+  totalGain: number
+  missingRates?: Record<string, string[]>
+}
+
+interface TaxReportForm {
+  from: number
+  to: number
+  method: string
+  rateSource: string
+}
+
+export default class TaxReportPage extends BasePage {
+  page: Record<string, PageElement>
+
+  constructor (main: HTMLElement) {
+    super()
+    const page = this.page = Doc.idDescendants(main)
+    Doc.cleanTemplates(page.assetRowTmpl, page.gainRowTmpl)
+    Doc.bind(page.backButton, 'click', () => { app().loadPage('orders') })
+
+    // Default to the previous calendar year.
+    const lastYear = new Date().getFullYear() - 1
+    page.startDate.value = `${lastYear}-01-01`
+    page.endDate.value = `${lastYear}-12-31`
+
+    Doc.bind(page.generateButton, 'click', () => { this.generate() })
+    Doc.bind(page.exportButton, 'click', () => { this.exportCSV() })
+  }
+
+  /* form is the report form for the current inputs. The end date is
+   * inclusive. */
+  form (): TaxReportForm {
+    const page = this.page
+    const dayStart = (v?: string) => v ? new Date(`${v}T00:00:00`).getTime() : 0
+    const end = dayStart(page.endDate.value)
+    return {
+      from: dayStart(page.startDate.value),
+      to: end ? end + 86400000 - 1 : 0,
+      method: page.methodSelect.value || '',
+      rateSource: page.sourceSelect.value || ''
+    }
+  }
+
+  /* generate fetches and displays the report for the current inputs. */
+  async generate () {
+    const page = this.page
+    Doc.hide(page.reportErr, page.missingRates, page.reportBox)
+    const loaded = app().loading(page.reportBox.parentElement as HTMLElement)
+    const res = await postJSON('/api/taxreport', this.form())
+    loaded()
+    if (!app().checkResponse(res)) {
+      page.reportErr.textContent = res.msg
+      Doc.show(page.reportErr)
+      return
+    }
+    this.showReport(res.report)
+  }
+
+  showReport (report: TaxReport) {
+    const page = this.page
+    const fiat = (v: number) => `${Doc.formatFiatValue(v)} ${report.currency}`
+    const setGain = (el: PageElement, v: number) => {
+      el.textContent = fiat(v)
+      if (v > 0) el.classList.add('buycolor')
+      else if (v < 0) el.classList.add('sellcolor')
+    }
+    const coin = (assetID: number, v: number) => Doc.formatCoinValue(v, app().unitInfo(assetID))
+
+    Doc.empty(page.assetTableBody, page.gainTableBody)
+    page.totalGain.textContent = ''
+    page.totalGain.classList.remove('buycolor', 'sellcolor')
+    setGain(page.totalGain, report.totalGain)
+
+    for (const a of report.assets) {
+      const row = page.assetRowTmpl.cloneNode(true) as PageElement
+      const tmpl = Doc.parseTemplate(row)
+      tmpl.logo.src = Doc.logoPath(a.symbol)
+      tmpl.symbol.textContent = a.symbol.toUpperCase()
+      tmpl.qty.textContent = coin(a.assetID, a.disposed)
+      tmpl.proceeds.textContent = fiat(a.proceeds)
+      tmpl.costBasis.textContent = fiat(a.costBasis)
+      tmpl.fees.textContent = fiat(a.fees)
+      setGain(tmpl.gain, a.gain)
+      tmpl.unknownQty.textContent = coin(a.assetID, a.unknownBasisQty)
+      page.assetTableBody.appendChild(row)
+    }
+
+    for (const g of report.gains) {
+      const row = page.gainRowTmpl.cloneNode(true) as PageElement
+      const tmpl = Doc.parseTemplate(row)
+      tmpl.time.textContent = new Date(g.stamp).toLocaleString()
+      tmpl.logo.src = Doc.logoPath(g.symbol)
+      tmpl.symbol.textContent = g.symbol.toUpperCase()
+      tmpl.market.textContent = `${g.marketID} @ ${g.host}`
+      tmpl.qty.textContent = coin(g.assetID, g.qty)
+      tmpl.proceeds.textContent = fiat(g.proceeds)
+      tmpl.costBasis.textContent = fiat(g.costBasis)
+      tmpl.fees.textContent = fiat(g.fees)
+      setGain(tmpl.gain, g.gain)
+      Doc.bind(row, 'click', () => { app().loadPage(`order/${g.orderID}`) })
+      page.gainTableBody.appendChild(row)
+    }
+
+    if (report.missingRates) {
+      page.missingRatesList.textContent = Object.entries(report.missingRates)
+        .map(([symbol, days]) => `${symbol.toUpperCase()}: ${days.join(', ')}`).join('; ')
+      Doc.show(page.missingRates)
+    }
+    Doc.show(page.reportBox)
+  }
+
+  /* export downloads the report for the current inputs as a CSV file. */
+  exportCSV () {
+    const form = this.form()
+    const url = new URL(window.location.href)
+    const search = new URLSearchParams('')
+    search.append('from', String(form.from))
+    search.append('to', String(form.to))
+    search.append('method', form.method)
+    search.append('source', form.rateSource)
+    url.search = search.toString()
+    url.pathname = '/taxreport/export'
+    window.open(url.toString())
+  }
+}
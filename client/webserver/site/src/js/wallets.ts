@@ -115,6 +115,8 @@ export const txTypeSwapOrSend = 16
 export const txTypeMixing = 17
 export const txTypeBridgeInitiation = 18
 export const txTypeBridgeCompletion = 19
+export const txTypeShield = 20
+export const txTypeDeshield = 21
 
 const positiveTxTypes : number[] = [
   txTypeReceive,
@@ -168,7 +170,9 @@ const txTypeTranslationKeys = [
   intl.ID_TX_TYPE_SWAP_OR_SEND,
   intl.ID_TX_TYPE_MIX,
   intl.ID_TX_TYPE_BRIDGE_INITIATION,
-  intl.ID_TX_TYPE_BRIDGE_COMPLETION
+  intl.ID_TX_TYPE_BRIDGE_COMPLETION,
+  intl.ID_TX_TYPE_SHIELD,
+  intl.ID_TX_TYPE_DESHIELD
 ]
 
 export function txTypeString (txType: number) : string {
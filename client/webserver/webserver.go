@@ -132,6 +132,9 @@ type clientCore interface {
 	Trade(pw []byte, form *core.TradeForm) (*core.Order, error)
 	TradeAsync(pw []byte, form *core.TradeForm) (*core.InFlightOrder, error)
 	Cancel(oid dex.Bytes) error
+	PlaceConditionalOrder(form *core.ConditionalOrderForm) (*core.ConditionalOrder, error)
+	CancelConditionalOrder(id dex.Bytes) error
+	ConditionalOrders(activeOnly bool) ([]*core.ConditionalOrder, error)
 	NotificationFeed() *core.NoteFeed
 	Logout() error
 	Orders(*core.OrderFilter) ([]*core.Order, error)
@@ -154,6 +157,7 @@ type clientCore interface {
 	WalletRestorationInfo(pw []byte, assetID uint32) ([]*asset.WalletRestoration, error)
 	ToggleRateSourceStatus(src string, disable bool) error
 	FiatRateSources() map[string]bool
+	TaxReport(form *core.TaxReportForm) (*core.TaxReport, error)
 	EstimateSendTxFee(address string, assetID uint32, value uint64, subtract, maxWithdraw bool) (fee uint64, isValidAddress bool, err error)
 	ValidateAddress(address string, assetID uint32) (bool, error)
 	DeleteArchivedRecordsWithBackup(olderThan *time.Time, saveMatchesToFile, saveOrdersToFile bool) (string, int, error)
@@ -196,6 +200,8 @@ type MMCore interface {
 	UpdateRunningBotCfg(cfg *mm.BotConfig, balanceDiffs *mm.BotInventoryDiffs, autoRebalanceCfg *mm.AutoRebalanceConfig, saveUpdate bool) error
 	AvailableBalances(mkt *mm.MarketWithHost, cexName *string) (dexBalances, cexBalances map[uint32]uint64, _ error)
 	MaxFundingFees(mkt *mm.MarketWithHost, maxBuyPlacements, maxSellPlacements uint32, baseOptions, quoteOptions map[string]string) (buyFees, sellFees uint64, err error)
+	ProposePortfolioRebalance(cfg *mm.PortfolioRebalanceConfig) ([]*mm.PortfolioTransfer, error)
+	ExecutePortfolioRebalance(cfg *mm.PortfolioRebalanceConfig) ([]*mm.PortfolioTransfer, error)
 }
 
 // genCertPair generates a key/cert pair to the paths provided.
@@ -508,6 +514,8 @@ func New(cfg *Config) (*WebServer, error) {
 				webDC.With(orderIDCtx).Get("/order/{oid}", s.handleOrder)
 				webDC.Get(ordersRoute, s.handleOrders)
 				webDC.Get(exportOrderRoute, s.handleExportOrders)
+				webDC.Get(taxReportRoute, s.handleTaxReport)
+				webDC.Get(exportTaxReportRoute, s.handleExportTaxReport)
 				webDC.Get(marketsRoute, s.handleMarkets)
 				webDC.Get(mmSettingsRoute, s.handleMMSettings)
 				webDC.Get(mmArchivesRoute, s.handleMMArchives)
@@ -557,6 +565,9 @@ func New(cfg *Config) (*WebServer, error) {
 			apiAuth.Post("/trade", s.apiTrade)
 			apiAuth.Post("/tradeasync", s.apiTradeAsync)
 			apiAuth.Post("/cancel", s.apiCancel)
+			apiAuth.Post("/conditionalorder", s.apiConditionalOrder)
+			apiAuth.Post("/cancelconditionalorder", s.apiCancelConditionalOrder)
+			apiAuth.Post("/conditionalorders", s.apiConditionalOrders)
 			apiAuth.Post("/logout", s.apiLogout)
 			apiAuth.Post("/balance", s.apiGetBalance)
 			apiAuth.Post("/parseconfig", s.apiParseConfig)
@@ -565,6 +576,7 @@ func New(cfg *Config) (*WebServer, error) {
 			apiAuth.Post("/walletsettings", s.apiWalletSettings)
 			apiAuth.Post("/togglewalletstatus", s.apiToggleWalletStatus)
 			apiAuth.Post("/orders", s.apiOrders)
+			apiAuth.Post("/taxreport", s.apiTaxReport)
 			apiAuth.Post("/order", s.apiOrder)
 			apiAuth.Post("/send", s.apiSend)
 			apiAuth.Post("/maxbuy", s.apiMaxBuy)
@@ -619,6 +631,8 @@ func New(cfg *Config) (*WebServer, error) {
 			apiAuth.Post("/cexbook", s.apiCEXBook)
 			apiAuth.Post("/availablebalances", s.apiAvailableBalances)
 			apiAuth.Post("/maxfundingfees", s.apiMaxFundingFees)
+			apiAuth.Post("/proposeportfoliorebalance", s.apiProposePortfolioRebalance)
+			apiAuth.Post("/portfoliorebalance", s.apiPortfolioRebalance)
 
 		})
 	})
@@ -709,6 +723,7 @@ func (s *WebServer) buildTemplates(lang string) error {
 		addTemplate("wallets", bb, "forms").
 		addTemplate("settings", bb, "forms").
 		addTemplate("orders", bb).
+		addTemplate("taxreport", bb).
 		addTemplate("order", bb, "forms").
 		addTemplate("dexsettings", bb, "forms").
 		addTemplate("init", bb).
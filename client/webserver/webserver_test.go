@@ -121,6 +121,9 @@ func (c *TCore) BondsFeeBuffer(assetID uint32) (uint64, error) {
 func (c *TCore) ToggleRateSourceStatus(src string, disable bool) error {
 	return c.rateSourceErr
 }
+func (c *TCore) TaxReport(form *core.TaxReportForm) (*core.TaxReport, error) {
+	return &core.TaxReport{}, nil
+}
 func (c *TCore) FiatRateSources() map[string]bool {
 	return nil
 }
@@ -220,6 +223,13 @@ func trade(form *core.TradeForm) *core.Order {
 	}
 }
 func (c *TCore) Cancel(oid dex.Bytes) error { return nil }
+func (c *TCore) PlaceConditionalOrder(form *core.ConditionalOrderForm) (*core.ConditionalOrder, error) {
+	return &core.ConditionalOrder{Trade: form.Trade, Type: form.Type, Trigger: form.Trigger}, nil
+}
+func (c *TCore) CancelConditionalOrder(id dex.Bytes) error { return nil }
+func (c *TCore) ConditionalOrders(activeOnly bool) ([]*core.ConditionalOrder, error) {
+	return nil, nil
+}
 
 func (c *TCore) NotificationFeed() *core.NoteFeed {
 	return &core.NoteFeed{
@@ -52,6 +52,9 @@ type MarketInfo struct {
 	EpochDuration          uint64 // msec
 	MarketBuyBuffer        float64
 	MaxUserCancelsPerEpoch uint32
+	// PrivateSwaps indicates that matches are settled with adaptor signature
+	// private swaps. Both assets must support private swaps.
+	PrivateSwaps bool
 }
 
 func marketName(base, quote string) string {
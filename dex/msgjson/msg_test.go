@@ -432,6 +432,125 @@ func TestRedemption(t *testing.T) {
 	}
 }
 
+func TestPrivateAudit(t *testing.T) {
+	// serialization: orderid (32) + matchid (32) + coin ID (36) + tx data
+	// (variable) + unsigned redeem (variable) + timestamp (8)
+	oid, _ := hex.DecodeString("d6c752bb34d833b6e0eb4d114d690d044f8ab3f6de9defa08e9d7d237f670fe4")
+	mid, _ := hex.DecodeString("79f84ef6c60e72edd305047c015d7b7ade64525a301fdac136976f05edb6172b")
+	coinID, _ := hex.DecodeString("3cdabd9bd62dfbd7d8b020d5de4e643b439886f4b0dc86cb8a56dff8e61c5ec333487a97")
+	audit := &PrivateAudit{
+		PrivateInit: PrivateInit{
+			OrderID:        oid,
+			MatchID:        mid,
+			CoinID:         coinID,
+			TxData:         []byte{0x01, 0x02, 0x03},
+			UnsignedRedeem: []byte{0x04, 0x05},
+		},
+		Time: 1570705920,
+	}
+
+	exp := []byte{
+		// Order ID 32 bytes
+		0xd6, 0xc7, 0x52, 0xbb, 0x34, 0xd8, 0x33, 0xb6, 0xe0, 0xeb, 0x4d, 0x11,
+		0x4d, 0x69, 0x0d, 0x04, 0x4f, 0x8a, 0xb3, 0xf6, 0xde, 0x9d, 0xef, 0xa0,
+		0x8e, 0x9d, 0x7d, 0x23, 0x7f, 0x67, 0x0f, 0xe4,
+		// Match ID 32 bytes
+		0x79, 0xf8, 0x4e, 0xf6, 0xc6, 0x0e, 0x72, 0xed, 0xd3, 0x05, 0x04, 0x7c,
+		0x01, 0x5d, 0x7b, 0x7a, 0xde, 0x64, 0x52, 0x5a, 0x30, 0x1f, 0xda, 0xc1,
+		0x36, 0x97, 0x6f, 0x05, 0xed, 0xb6, 0x17, 0x2b,
+		// Coin ID
+		0x3c, 0xda, 0xbd, 0x9b, 0xd6, 0x2d, 0xfb, 0xd7, 0xd8, 0xb0, 0x20, 0xd5,
+		0xde, 0x4e, 0x64, 0x3b, 0x43, 0x98, 0x86, 0xf4, 0xb0, 0xdc, 0x86, 0xcb,
+		0x8a, 0x56, 0xdf, 0xf8, 0xe6, 0x1c, 0x5e, 0xc3, 0x33, 0x48, 0x7a, 0x97,
+		// Tx data 3 bytes (shortened for testing)
+		0x01, 0x02, 0x03,
+		// Unsigned redeem 2 bytes (shortened for testing)
+		0x04, 0x05,
+		// Timestamp 8 bytes
+		0x00, 0x00, 0x00, 0x00, 0x5d, 0x9f, 0x12, 0x00,
+	}
+
+	b := audit.Serialize()
+	if !bytes.Equal(b, exp) {
+		t.Fatalf("unexpected serialization. Wanted %x, got %x", exp, b)
+	}
+
+	auditB, err := json.Marshal(audit)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+
+	var auditBack PrivateAudit
+	err = json.Unmarshal(auditB, &auditBack)
+	if err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+
+	if !bytes.Equal(auditBack.Serialize(), exp) {
+		t.Fatalf("wrong serialization after round trip. Wanted %x, got %x", exp, auditBack.Serialize())
+	}
+}
+
+func TestAdaptorSigs(t *testing.T) {
+	// serialization: orderid (32) + matchid (32) + adaptor pub + redeem sig +
+	// refund sig + unsigned redeem
+	oid, _ := hex.DecodeString("ee17139af2d86bd6052829389c0531f71042ed0b0539e617213a9a7151215a1b")
+	mid, _ := hex.DecodeString("6ea1227b03d7bf05ce1e23f3edf57368f69ba9ee0cc069f09ab0952a36d964c5")
+	sigs := &AdaptorSigs{
+		OrderID:        oid,
+		MatchID:        mid,
+		AdaptorPub:     []byte{0x02, 0x01},
+		RedeemSig:      []byte{0x03},
+		RefundSig:      []byte{0x04, 0x05},
+		UnsignedRedeem: []byte{0x06},
+	}
+
+	exp := []byte{
+		// Order ID 32 bytes
+		0xee, 0x17, 0x13, 0x9a, 0xf2, 0xd8, 0x6b, 0xd6, 0x05, 0x28, 0x29, 0x38,
+		0x9c, 0x05, 0x31, 0xf7, 0x10, 0x42, 0xed, 0x0b, 0x05, 0x39, 0xe6, 0x17,
+		0x21, 0x3a, 0x9a, 0x71, 0x51, 0x21, 0x5a, 0x1b,
+		// Match ID 32 bytes
+		0x6e, 0xa1, 0x22, 0x7b, 0x03, 0xd7, 0xbf, 0x05, 0xce, 0x1e, 0x23, 0xf3,
+		0xed, 0xf5, 0x73, 0x68, 0xf6, 0x9b, 0xa9, 0xee, 0x0c, 0xc0, 0x69, 0xf0,
+		0x9a, 0xb0, 0x95, 0x2a, 0x36, 0xd9, 0x64, 0xc5,
+		// Adaptor pub, redeem sig, refund sig, and unsigned redeem (shortened
+		// for testing)
+		0x02, 0x01, 0x03, 0x04, 0x05, 0x06,
+	}
+
+	b := sigs.Serialize()
+	if !bytes.Equal(b, exp) {
+		t.Fatalf("unexpected serialization. Wanted %x, got %x", exp, b)
+	}
+
+	// The taker's sigs have only the refund sig, and omit the others from the
+	// JSON.
+	takerSigs := &AdaptorSigs{
+		OrderID:   oid,
+		MatchID:   mid,
+		RefundSig: []byte{0x04, 0x05},
+	}
+	sigsB, err := json.Marshal(takerSigs)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	if bytes.Contains(sigsB, []byte("adaptorpub")) {
+		t.Fatalf("empty adaptor pub not omitted: %s", sigsB)
+	}
+	var sigsBack AdaptorSigs
+	err = json.Unmarshal(sigsB, &sigsBack)
+	if err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if !bytes.Equal(sigsBack.RefundSig, takerSigs.RefundSig) {
+		t.Fatal(sigsBack.RefundSig, takerSigs.RefundSig)
+	}
+	if !bytes.Equal(sigsBack.Serialize(), takerSigs.Serialize()) {
+		t.Fatalf("wrong serialization after round trip")
+	}
+}
+
 func TestPrefix(t *testing.T) {
 	// serialization: account ID (32) + base asset (4) + quote asset (4) +
 	// order type (1), client time (8), server time (8) = 57 bytes
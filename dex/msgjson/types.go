@@ -99,6 +99,10 @@ const (
 	RPCUpdateRunningBotInvError          // 81
 	RPCMMStatusError                     // 82
 	RPCBridgeError                       // 83
+	RPCConditionalOrderError             // 84
+	RPCExecutionOrderError               // 85
+	RPCMMExportError                     // 86
+	RPCMMPortfolioRebalanceError         // 87
 )
 
 // Routes are destinations for a "payload" of data. The type of data being
@@ -133,6 +137,37 @@ const (
 	// relaying redemption transaction (from RedeemRoute) details from one client
 	// to the other.
 	RedemptionRoute = "redemption"
+	// PrivateKeysRoute is the route of a client-originating request-type
+	// message providing the public keys for a private (adaptor signature)
+	// swap on a market with private swaps.
+	PrivateKeysRoute = "private_keys"
+	// CounterpartyKeysRoute is the route of a DEX-originating request-type
+	// message relaying the public keys (from PrivateKeysRoute) from one client
+	// to the other.
+	CounterpartyKeysRoute = "counterparty_keys"
+	// PrivateInitRoute is the route of a client-originating request-type
+	// message notifying the DEX, and subsequently the match counter-party, of
+	// a private swap transaction.
+	PrivateInitRoute = "private_init"
+	// PrivateAuditRoute is the route of a DEX-originating request-type message
+	// relaying private swap details (from PrivateInitRoute) from one client to
+	// the other.
+	PrivateAuditRoute = "private_audit"
+	// AdaptorSigsRoute is the route of a client-originating request-type
+	// message providing the adaptor signatures for a private swap.
+	AdaptorSigsRoute = "adaptor_sigs"
+	// CounterpartyAdaptorSigsRoute is the route of a DEX-originating
+	// request-type message relaying adaptor signatures (from AdaptorSigsRoute)
+	// from one client to the other.
+	CounterpartyAdaptorSigsRoute = "counterparty_adaptor_sigs"
+	// PrivateRedeemRoute is the route of a client-originating request-type
+	// message notifying the DEX, and subsequently the match counter-party, of
+	// a private swap redemption transaction.
+	PrivateRedeemRoute = "private_redeem"
+	// PrivateRedemptionRoute is the route of a DEX-originating request-type
+	// message relaying private swap redemption details (from
+	// PrivateRedeemRoute) from one client to the other.
+	PrivateRedemptionRoute = "private_redemption"
 	// RevokeMatchRoute is a DEX-originating notification-type message informing
 	// a client that a match has been revoked.
 	RevokeMatchRoute = "revoke_match"
@@ -231,6 +266,26 @@ const (
 	// CandlesRoute is the HTTP request to get the set of candlesticks
 	// representing market activity history.
 	CandlesRoute = "candles"
+	// MarketDataSubscribeRoute is the client-originating request subscribing
+	// to the data API's streaming feed for a market. The feed is public and
+	// does not require the connection to be authorized.
+	MarketDataSubscribeRoute = "marketdata_subscribe"
+	// MarketDataUnsubscribeRoute is the client-originating request ending a
+	// MarketDataSubscribeRoute subscription.
+	MarketDataUnsubscribeRoute = "marketdata_unsubscribe"
+	// SpotUpdateRoute is a dex-originating notification sent to market data
+	// subscribers with the market's Spot at the end of every epoch.
+	SpotUpdateRoute = "spot_update"
+	// CandleUpdateRoute is a dex-originating notification sent to market data
+	// subscribers when a candle of a subscribed bin size is completed.
+	CandleUpdateRoute = "candle_update"
+	// EpochSummaryRoute is a dex-originating notification sent to market data
+	// subscribers with the match statistics of every epoch.
+	EpochSummaryRoute = "epoch_summary"
+	// ConfigChangeRoute is the DEX-originating notification-type message
+	// delivering the updated ConfigResult when the DEX configuration is
+	// changed at runtime, such as when a market is added or retired.
+	ConfigChangeRoute = "config_change"
 )
 
 const errNullRespPayload = dex.ErrorKind("null response payload")
@@ -510,6 +565,9 @@ func (m *Match) Serialize() []byte {
 // NoMatch is the payload for a server-originating NoMatchRoute notification.
 type NoMatch struct {
 	OrderID Bytes `json:"orderid"`
+	// Rejected is set for a post-only order that would have matched, and was
+	// not booked.
+	Rejected bool `json:"rejected,omitempty"`
 }
 
 // MatchRequest details a match for the MatchStatusRoute request. The actual
@@ -667,17 +725,160 @@ func (r *Redemption) Serialize() []byte {
 	return append(s, uint64Bytes(r.Time)...)
 }
 
+// PrivateKeys is the payload for a client-originating PrivateKeysRoute
+// request. The RedeemKey is from the wallet that will redeem the counterparty's
+// swap, and the RefundKey is from the wallet that will fund the client's own
+// swap. PrivateKeys is also the payload of the DEX-originating
+// CounterpartyKeysRoute request, in which case the keys are the
+// counterparty's, and the OrderID is the recipient's order.
+type PrivateKeys struct {
+	Signature
+	OrderID   Bytes `json:"orderid"`
+	MatchID   Bytes `json:"matchid"`
+	RedeemKey Bytes `json:"redeemkey"`
+	RefundKey Bytes `json:"refundkey"`
+}
+
+var _ Signable = (*PrivateKeys)(nil)
+
+// Serialize serializes the PrivateKeys data.
+func (keys *PrivateKeys) Serialize() []byte {
+	// PrivateKeys serialization is orderid (32) + matchid (32) + redeem key
+	// (33 or more) + refund key (33 or more) = 130 or more
+	s := make([]byte, 0, 130)
+	s = append(s, keys.OrderID...)
+	s = append(s, keys.MatchID...)
+	s = append(s, keys.RedeemKey...)
+	return append(s, keys.RefundKey...)
+}
+
+// PrivateInit is the payload for a client-originating PrivateInitRoute
+// request. There is no contract script. The counterparty audits the swap
+// output using the exchanged keys. The taker includes their unsigned
+// redemption of the maker's swap, for which the maker will create an adaptor
+// signature.
+type PrivateInit struct {
+	Signature
+	OrderID        Bytes `json:"orderid"`
+	MatchID        Bytes `json:"matchid"`
+	CoinID         Bytes `json:"coinid"`
+	TxData         Bytes `json:"txdata"`
+	UnsignedRedeem Bytes `json:"unsignedredeem,omitempty"`
+}
+
+var _ Signable = (*PrivateInit)(nil)
+
+// Serialize serializes the PrivateInit data.
+func (init *PrivateInit) Serialize() []byte {
+	// PrivateInit serialization is orderid (32) + matchid (32) + coin ID (36)
+	// + tx data (variable) + unsigned redeem (variable, taker only).
+	s := make([]byte, 0, 100+len(init.TxData)+len(init.UnsignedRedeem))
+	s = append(s, init.OrderID...)
+	s = append(s, init.MatchID...)
+	s = append(s, init.CoinID...)
+	s = append(s, init.TxData...)
+	return append(s, init.UnsignedRedeem...)
+}
+
+// PrivateAudit is the payload for a DEX-originating PrivateAuditRoute request.
+type PrivateAudit struct {
+	PrivateInit
+	Time uint64 `json:"timestamp"`
+}
+
+// Serialize serializes the PrivateAudit data.
+func (audit *PrivateAudit) Serialize() []byte {
+	// PrivateAudit serialization is PrivateInit + timestamp (8).
+	s := audit.PrivateInit.Serialize()
+	return append(s, uint64Bytes(audit.Time)...)
+}
+
+// AdaptorSigs is the payload for a client-originating AdaptorSigsRoute
+// request. The maker, who generated the adaptor secret, sends the AdaptorPub,
+// a RedeemSig for the maker's redemption of the taker's swap, a RefundSig for
+// the taker's redemption of the maker's swap, and the maker's UnsignedRedeem.
+// The taker responds with only a RefundSig, the public key tweaked adaptor for
+// the maker's redemption of the taker's swap. AdaptorSigs is also the payload
+// of the DEX-originating CounterpartyAdaptorSigsRoute request, in which case
+// the OrderID is the recipient's order.
+type AdaptorSigs struct {
+	Signature
+	OrderID        Bytes `json:"orderid"`
+	MatchID        Bytes `json:"matchid"`
+	AdaptorPub     Bytes `json:"adaptorpub,omitempty"`
+	RedeemSig      Bytes `json:"redeemsig,omitempty"`
+	RefundSig      Bytes `json:"refundsig"`
+	UnsignedRedeem Bytes `json:"unsignedredeem,omitempty"`
+}
+
+var _ Signable = (*AdaptorSigs)(nil)
+
+// Serialize serializes the AdaptorSigs data.
+func (sigs *AdaptorSigs) Serialize() []byte {
+	// AdaptorSigs serialization is orderid (32) + matchid (32) + adaptor pub
+	// (33, maker only) + redeem sig (variable, maker only) + refund sig
+	// (variable) + unsigned redeem (variable, maker only).
+	s := make([]byte, 0, 64+len(sigs.AdaptorPub)+len(sigs.RedeemSig)+
+		len(sigs.RefundSig)+len(sigs.UnsignedRedeem))
+	s = append(s, sigs.OrderID...)
+	s = append(s, sigs.MatchID...)
+	s = append(s, sigs.AdaptorPub...)
+	s = append(s, sigs.RedeemSig...)
+	s = append(s, sigs.RefundSig...)
+	return append(s, sigs.UnsignedRedeem...)
+}
+
+// PrivateRedeem is the payload for a client-originating PrivateRedeemRoute
+// request. There is no secret. The counterparty recovers the adaptor secret
+// from the redemption transaction.
+type PrivateRedeem struct {
+	Signature
+	OrderID Bytes `json:"orderid"`
+	MatchID Bytes `json:"matchid"`
+	CoinID  Bytes `json:"coinid"`
+	TxData  Bytes `json:"txdata"`
+}
+
+var _ Signable = (*PrivateRedeem)(nil)
+
+// Serialize serializes the PrivateRedeem data.
+func (redeem *PrivateRedeem) Serialize() []byte {
+	// PrivateRedeem serialization is orderid (32) + matchid (32) + coin ID
+	// (36) + tx data (variable).
+	s := make([]byte, 0, 100+len(redeem.TxData))
+	s = append(s, redeem.OrderID...)
+	s = append(s, redeem.MatchID...)
+	s = append(s, redeem.CoinID...)
+	return append(s, redeem.TxData...)
+}
+
+// PrivateRedemption is the payload for a DEX-originating
+// PrivateRedemptionRoute request.
+type PrivateRedemption struct {
+	PrivateRedeem
+	Time uint64 `json:"timestamp"`
+}
+
+// Serialize serializes the PrivateRedemption data.
+func (r *PrivateRedemption) Serialize() []byte {
+	// PrivateRedemption serialization is PrivateRedeem + timestamp (8).
+	s := r.PrivateRedeem.Serialize()
+	return append(s, uint64Bytes(r.Time)...)
+}
+
 // Certain order properties are specified with the following constants. These
-// properties include buy/sell (side), standing/immediate (force),
-// limit/market/cancel (order type).
+// properties include buy/sell (side), standing/immediate/fill-or-kill/post-only
+// (force), limit/market/cancel (order type).
 const (
-	BuyOrderNum       = 1
-	SellOrderNum      = 2
-	StandingOrderNum  = 1
-	ImmediateOrderNum = 2
-	LimitOrderNum     = 1
-	MarketOrderNum    = 2
-	CancelOrderNum    = 3
+	BuyOrderNum        = 1
+	SellOrderNum       = 2
+	StandingOrderNum   = 1
+	ImmediateOrderNum  = 2
+	FillOrKillOrderNum = 3
+	PostOnlyOrderNum   = 4
+	LimitOrderNum      = 1
+	MarketOrderNum     = 2
+	CancelOrderNum     = 3
 )
 
 // Coin is information for validating funding coins. Some number of
@@ -914,7 +1115,9 @@ type TradeResumption struct {
 	MarketID   string `json:"marketid"`
 	ResumeTime uint64 `json:"resumetime,omitempty"` // only set in advance of resume
 	StartEpoch uint64 `json:"startepoch"`
-	// TODO: ConfigChange bool or entire Config Market here.
+	// ConfigChange indicates that the market's configuration, such as the lot
+	// size or rate step, changed while the market was suspended.
+	ConfigChange bool `json:"configchange,omitempty"`
 }
 
 // PreimageRequest is the server-originating preimage request payload.
@@ -1224,7 +1427,10 @@ type Market struct {
 	RateStep        uint64  `json:"ratestep"`
 	MarketBuyBuffer float64 `json:"buybuffer"`
 	ParcelSize      uint32  `json:"parcelSize"`
-	MarketStatus    `json:"status"`
+	// PrivateSwaps indicates that matches on the market are settled with
+	// adaptor signature private swaps instead of HTLC swaps.
+	PrivateSwaps bool `json:"privateswaps,omitempty"`
+	MarketStatus `json:"status"`
 }
 
 // Running indicates if the market should be running given the known StartEpoch,
@@ -1364,6 +1570,35 @@ func (wc *WireCandles) Candles() []*Candle {
 	return candles
 }
 
+// MarketDataSubscription is the payload of a MarketDataSubscribeRoute request,
+// and of a MarketDataUnsubscribeRoute request, for which BinSizes is ignored.
+// Subscribing again to a market replaces the subscribed BinSizes.
+type MarketDataSubscription struct {
+	Base  uint32 `json:"base"`
+	Quote uint32 `json:"quote"`
+	// BinSizes are the candle durations, e.g. "1h", for which CandleUpdate
+	// notifications are sent. Spot and epoch summary notifications are always
+	// sent.
+	BinSizes []string `json:"binSizes,omitempty"`
+}
+
+// CandleUpdate is the payload of a CandleUpdateRoute notification.
+type CandleUpdate struct {
+	MarketID string `json:"marketid"`
+	BinSize  string `json:"binSize"`
+	Candle
+}
+
+// EpochSummary is the payload of an EpochSummaryRoute notification. The
+// embedded Candle spans the epoch.
+type EpochSummary struct {
+	MarketID  string `json:"marketid"`
+	Epoch     uint64 `json:"epoch"`
+	BookBuys  uint64 `json:"bookBuys"`
+	BookSells uint64 `json:"bookSells"`
+	Candle
+}
+
 // EpochReportNote is a report about an epoch sent after all of the epoch's book
 // updates. Like TradeResumption, and TradeSuspension when Persist is true, Seq
 // is omitted since it doesn't modify the book.
@@ -12,9 +12,11 @@ import (
 	"fmt"
 
 	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
 	"decred.org/dcrdex/server/account"
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/txscript"
@@ -1150,3 +1152,80 @@ func PayToTaprootScript(taprootKey *btcec.PublicKey) ([]byte, error) {
 		AddData(schnorr.SerializePubKey(taprootKey)).
 		Script()
 }
+
+// EncodePrivateSwapPubKey encodes a public key and musig2 public nonce to be
+// sent to the counterparty of a private swap.
+func EncodePrivateSwapPubKey(pubKey *btcec.PublicKey, pubNonce [66]byte) []byte {
+	return encode.BuildyBytes{0}.
+		AddData(pubKey.SerializeCompressed()).
+		AddData(pubNonce[:])
+}
+
+// DecodePrivateSwapPubKey decodes a public key and musig2 public nonce encoded
+// with EncodePrivateSwapPubKey.
+func DecodePrivateSwapPubKey(b []byte) (pubKey *btcec.PublicKey, pubNonce [66]byte, err error) {
+	ver, pushes, err := encode.DecodeBlob(b)
+	if err != nil {
+		return nil, pubNonce, fmt.Errorf("error decoding blob: %w", err)
+	}
+	if ver != 0 {
+		return nil, pubNonce, fmt.Errorf("invalid version")
+	}
+	if len(pushes) != 2 {
+		return nil, pubNonce, fmt.Errorf("expected 2 pushes")
+	}
+	pubKey, err = btcec.ParsePubKey(pushes[0])
+	if err != nil {
+		return nil, pubNonce, fmt.Errorf("error parsing public key: %w", err)
+	}
+	if len(pushes[1]) != 66 {
+		return nil, pubNonce, fmt.Errorf("expected 66 byte public nonce")
+	}
+	copy(pubNonce[:], pushes[1])
+	return pubKey, pubNonce, nil
+}
+
+// PrivateSwapTree is the taproot output of a private swap. The output can be
+// spent with a key path spend by the musig2 aggregate of the redeem and refund
+// keys, or with a script path spend of the refund script after the lock time.
+type PrivateSwapTree struct {
+	RefundScript []byte
+	Leaf         txscript.TapLeaf
+	ControlBlock *txscript.ControlBlock
+	RootHash     []byte
+	PkScript     []byte
+}
+
+// NewPrivateSwapTree creates the taproot output of a private swap.
+func NewPrivateSwapTree(redeemPubKey, refundPubKey *btcec.PublicKey, lockTime int64) (*PrivateSwapTree, error) {
+	// Create the combined key which is used to redeem the contract.
+	combinedKey, _, _, err := musig2.AggregateKeys([]*btcec.PublicKey{redeemPubKey, refundPubKey}, true)
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating keys: %w", err)
+	}
+
+	// Create the refund script and the taproot script tree, which only
+	// contains one leaf.
+	refundScript, err := PrivateSwapRefundScript(refundPubKey, lockTime)
+	if err != nil {
+		return nil, fmt.Errorf("error creating refund script: %w", err)
+	}
+
+	refundLeaf := txscript.NewBaseTapLeaf(refundScript)
+	tapScriptTree := txscript.AssembleTaprootScriptTree(refundLeaf)
+	tapScriptRootHash := tapScriptTree.RootNode.TapHash()
+	controlBlock := tapScriptTree.LeafMerkleProofs[0].ToControlBlock(combinedKey.FinalKey)
+	outputKey := txscript.ComputeTaprootOutputKey(combinedKey.FinalKey, tapScriptRootHash[:])
+	pkScript, err := PayToTaprootScript(outputKey)
+	if err != nil {
+		return nil, fmt.Errorf("error creating pay-to-taproot script: %w", err)
+	}
+
+	return &PrivateSwapTree{
+		RefundScript: refundScript,
+		Leaf:         refundLeaf,
+		ControlBlock: &controlBlock,
+		RootHash:     tapScriptRootHash[:],
+		PkScript:     pkScript,
+	}, nil
+}
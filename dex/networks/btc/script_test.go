@@ -620,3 +620,58 @@ func TestMsgTxVBytes(t *testing.T) {
 		t.Errorf("wanted tx virtual size %d, got %d", wantVSize, gotVSize)
 	}
 }
+
+func TestPrivateSwapTree(t *testing.T) {
+	newKey := func() *btcec.PublicKey {
+		priv, err := btcec.NewPrivateKey()
+		if err != nil {
+			t.Fatalf("error creating key: %v", err)
+		}
+		return priv.PubKey()
+	}
+	redeemPubKey, refundPubKey := newKey(), newKey()
+
+	// The exchanged keys round trip.
+	var pubNonce [66]byte
+	copy(pubNonce[:], randBytes(66))
+	pubKey, nonce, err := DecodePrivateSwapPubKey(EncodePrivateSwapPubKey(redeemPubKey, pubNonce))
+	if err != nil {
+		t.Fatalf("error decoding key: %v", err)
+	}
+	if !pubKey.IsEqual(redeemPubKey) || nonce != pubNonce {
+		t.Fatalf("wrong decoded key")
+	}
+	if _, _, err := DecodePrivateSwapPubKey(redeemPubKey.SerializeCompressed()); err == nil {
+		t.Fatalf("no error decoding a bare public key")
+	}
+
+	tree, err := NewPrivateSwapTree(redeemPubKey, refundPubKey, tStamp)
+	if err != nil {
+		t.Fatalf("error creating tree: %v", err)
+	}
+	class, _, _, err := txscript.ExtractPkScriptAddrs(tree.PkScript, tParams)
+	if err != nil {
+		t.Fatalf("error parsing pkScript: %v", err)
+	}
+	if class != txscript.WitnessV1TaprootTy {
+		t.Fatalf("expected a taproot pkScript, got %s", class)
+	}
+
+	// Changing the keys or the lock time changes the pkScript.
+	for _, tt := range []struct {
+		name                       string
+		redeemPubKey, refundPubKey *btcec.PublicKey
+		lockTime                   int64
+	}{
+		{"swapped keys", refundPubKey, redeemPubKey, tStamp},
+		{"different lock time", redeemPubKey, refundPubKey, tStamp + 1},
+	} {
+		otherTree, err := NewPrivateSwapTree(tt.redeemPubKey, tt.refundPubKey, tt.lockTime)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if bytes.Equal(tree.PkScript, otherTree.PkScript) {
+			t.Fatalf("%s: same pkScript", tt.name)
+		}
+	}
+}
@@ -381,6 +381,21 @@ func MakePrivateContract(redeemPKH, refundPKH []byte, locktime int64) ([]byte, e
 	return script, nil
 }
 
+// PrivateContractPkScript creates the private swap contract for the redeem and
+// refund public keys, and the P2SH pkScript that pays to it.
+func PrivateContractPkScript(redeemPubKey, refundPubKey []byte, lockTime int64, params stdaddr.AddressParamsV0) (contract, pkScript []byte, err error) {
+	contract, err = MakePrivateContract(stdaddr.Hash160(redeemPubKey), stdaddr.Hash160(refundPubKey), lockTime)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create pubkey script: %w", err)
+	}
+	addr, err := stdaddr.NewAddressScriptHashV0(contract, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error encoding script address: %w", err)
+	}
+	_, pkScript = addr.PaymentScript()
+	return contract, pkScript, nil
+}
+
 // MakeContract creates an atomic swap contract. The secretHash MUST be computed
 // from a secret of length SecretKeySize bytes or the resulting contract will be
 // invalid.
@@ -288,6 +288,46 @@ func TestMakePrivateContract(t *testing.T) {
 	}
 }
 
+func TestPrivateContractPkScript(t *testing.T) {
+	redeemPubKey, refundPubKey := randBytes(33), randBytes(33)
+	contract, pkScript, err := PrivateContractPkScript(redeemPubKey, refundPubKey, tStamp, tParams)
+	if err != nil {
+		t.Fatalf("error for valid contract parameters: %v", err)
+	}
+	refunder, redeemer, lockTime, err := ExtractPrivateSwapDetails(contract)
+	if err != nil {
+		t.Fatalf("error extracting private swap details: %v", err)
+	}
+	if !bytes.Equal(redeemer[:], stdaddr.Hash160(redeemPubKey)) || !bytes.Equal(refunder[:], stdaddr.Hash160(refundPubKey)) {
+		t.Fatalf("wrong contract pubkey hashes")
+	}
+	if lockTime != tStamp {
+		t.Fatalf("wrong lock time %d, wanted %d", lockTime, tStamp)
+	}
+	scriptHash := ExtractScriptHash(0, pkScript)
+	if !bytes.Equal(scriptHash, stdaddr.Hash160(contract)) {
+		t.Fatalf("pkScript does not pay to the contract")
+	}
+
+	// Changing the keys or the lock time changes the pkScript.
+	for _, tt := range []struct {
+		name                       string
+		redeemPubKey, refundPubKey []byte
+		lockTime                   int64
+	}{
+		{"swapped keys", refundPubKey, redeemPubKey, tStamp},
+		{"different lock time", redeemPubKey, refundPubKey, tStamp + 1},
+	} {
+		_, otherPkScript, err := PrivateContractPkScript(tt.redeemPubKey, tt.refundPubKey, tt.lockTime, tParams)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if bytes.Equal(pkScript, otherPkScript) {
+			t.Fatalf("%s: same pkScript", tt.name)
+		}
+	}
+}
+
 func TestIsDust(t *testing.T) {
 	pkScript := []byte{0x76, 0xa9, 0x21, 0x03, 0x2f, 0x7e, 0x43,
 		0x0a, 0xa4, 0xc9, 0xd1, 0x59, 0x43, 0x7e, 0x84, 0xb9,
@@ -147,13 +147,19 @@ func (ot OrderType) String() string {
 // epoch, the order may become a standing order or be revoked without a fill.
 type TimeInForce uint8
 
-// The TimeInForce is either ImmediateTiF, which prevents the order from
-// becoming a standing order if there is no match during epoch processing, or
+// The TimeInForce is one of ImmediateTiF, which prevents the order from
+// becoming a standing order if there is no match during epoch processing,
 // StandingTiF, which allows limit orders to enter the order book if not
-// immediately matched during epoch processing.
+// immediately matched during epoch processing, FillOrKillTiF, which is like
+// ImmediateTiF except that the order must be completely filled during epoch
+// processing or it is not matched at all, or PostOnlyTiF, which is like
+// StandingTiF except that the order is rejected instead of matched if it would
+// take liquidity from the book during epoch processing.
 const (
 	ImmediateTiF TimeInForce = iota
 	StandingTiF
+	FillOrKillTiF
+	PostOnlyTiF
 )
 
 // String satisfies the Stringer interface.
@@ -163,10 +169,20 @@ func (t TimeInForce) String() string {
 		return "immediate"
 	case StandingTiF:
 		return "standing"
+	case FillOrKillTiF:
+		return "fill-or-kill"
+	case PostOnlyTiF:
+		return "post-only"
 	}
 	return fmt.Sprintf("unknown (%d)", t)
 }
 
+// Standing is true if an order with this time-in-force may be placed on the
+// book, i.e. StandingTiF or PostOnlyTiF.
+func (t TimeInForce) Standing() bool {
+	return t == StandingTiF || t == PostOnlyTiF
+}
+
 // Order specifies the methods required for a type to function as a DEX order.
 // See the concrete implementations of MarketOrder, LimitOrder, and CancelOrder.
 type Order interface {
@@ -787,9 +803,9 @@ func ValidateOrder(ord Order, status OrderStatus, lotSize uint64) error {
 		switch status {
 		case OrderStatusEpoch, OrderStatusExecuted, OrderStatusRevoked:
 		case OrderStatusBooked, OrderStatusCanceled:
-			// Immediate time in force limit orders may not be canceled, and may
-			// not be in the order book.
-			if ot.Force == ImmediateTiF {
+			// Immediate and fill-or-kill time in force limit orders may not
+			// be canceled, and may not be in the order book.
+			if !ot.Force.Standing() {
 				return fmt.Errorf("invalid immediate limit order status %d -> %s", status, status)
 			}
 		default:
@@ -525,6 +525,48 @@ func TestLimitOrder_ID(t *testing.T) {
 	}
 }
 
+func TestLimitOrder_EncodeTiF(t *testing.T) {
+	for _, tif := range []TimeInForce{ImmediateTiF, StandingTiF, FillOrKillTiF, PostOnlyTiF} {
+		lo := &LimitOrder{
+			P: Prefix{
+				AccountID:  acct0,
+				BaseAsset:  AssetDCR,
+				QuoteAsset: AssetBTC,
+				OrderType:  LimitOrderType,
+				ClientTime: time.UnixMilli(1566497653000),
+				ServerTime: time.UnixMilli(1566497656000),
+				Commit:     commit0,
+			},
+			T: Trade{
+				Coins: []CoinID{
+					utxoCoinID("01516d9c7ffbe260b811dc04462cedd3f8969ce3a3ffe6231ae870775a92e9b0", 1),
+				},
+				Quantity: 132413241324,
+				Address:  "DcqXswjTPnUcd4FRCkX4vRJxmVtfgGVa5ui",
+			},
+			Rate:  13241324,
+			Force: tif,
+		}
+		ord, err := DecodeOrder(EncodeOrder(lo))
+		if err != nil {
+			t.Fatalf("%s: DecodeOrder error: %v", tif, err)
+		}
+		loBack, ok := ord.(*LimitOrder)
+		if !ok {
+			t.Fatalf("%s: decoded a %T, not a *LimitOrder", tif, ord)
+		}
+		if loBack.Force != tif {
+			t.Fatalf("wrong time-in-force. wanted %s, got %s", tif, loBack.Force)
+		}
+		if loBack.ID() != lo.ID() {
+			t.Fatalf("%s: decoded order ID mismatch", tif)
+		}
+	}
+	if !StandingTiF.Standing() || !PostOnlyTiF.Standing() || ImmediateTiF.Standing() || FillOrKillTiF.Standing() {
+		t.Fatalf("wrong Standing result")
+	}
+}
+
 func TestCancelOrder_ID(t *testing.T) {
 	limitOrderID0, _ := hex.DecodeString("8490aca39a672a79a1d93d70b531bee2297c56040e970cac6d2be755c932508a")
 	var limitOrderID OrderID
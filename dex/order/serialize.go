@@ -212,11 +212,13 @@ func matchDecoder_v0(pushes [][]byte) (*UserMatch, error) {
 
 // Length-1 byte slices used as flags to indicate common order constants.
 var (
-	orderTypeLimit    = []byte{'l'}
-	orderTypeMarket   = []byte{'m'}
-	orderTypeCancel   = []byte{'c'}
-	orderTifImmediate = []byte{'i'}
-	orderTifStanding  = []byte{'s'}
+	orderTypeLimit     = []byte{'l'}
+	orderTypeMarket    = []byte{'m'}
+	orderTypeCancel    = []byte{'c'}
+	orderTifImmediate  = []byte{'i'}
+	orderTifStanding   = []byte{'s'}
+	orderTifFillOrKill = []byte{'f'}
+	orderTifPostOnly   = []byte{'p'}
 )
 
 // EncodeOrder encodes the order to bytes suitable for wire communications or
@@ -224,9 +226,16 @@ var (
 func EncodeOrder(ord Order) []byte {
 	switch o := ord.(type) {
 	case *LimitOrder:
-		tif := orderTifStanding
-		if o.Force == ImmediateTiF {
+		var tif []byte
+		switch o.Force {
+		case ImmediateTiF:
 			tif = orderTifImmediate
+		case FillOrKillTiF:
+			tif = orderTifFillOrKill
+		case PostOnlyTiF:
+			tif = orderTifPostOnly
+		default:
+			tif = orderTifStanding
 		}
 		return encode.BuildyBytes{0}.
 			AddData(orderTypeLimit).
@@ -296,8 +305,13 @@ func decodeOrder_v0(pushes [][]byte) (Order, error) {
 		}
 		rateB, tifB := flags[0], flags[1]
 		tif := ImmediateTiF
-		if bEqual(tifB, orderTifStanding) {
+		switch {
+		case bEqual(tifB, orderTifStanding):
 			tif = StandingTiF
+		case bEqual(tifB, orderTifFillOrKill):
+			tif = FillOrKillTiF
+		case bEqual(tifB, orderTifPostOnly):
+			tif = PostOnlyTiF
 		}
 		return &LimitOrder{
 			P:     *prefix,
@@ -11,6 +11,7 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -21,6 +22,8 @@ import (
 	"decred.org/dcrdex/server/account"
 	dexsrv "decred.org/dcrdex/server/dex"
 	"decred.org/dcrdex/server/market"
+	"decred.org/dcrdex/server/metrics"
+	"decred.org/dcrdex/server/swap"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -57,6 +60,31 @@ func apiPing(w http.ResponseWriter, _ *http.Request) {
 	writeJSON(w, pongStr)
 }
 
+// apiMetrics is the handler for the '/metrics' API request. The response is in
+// the Prometheus text exposition format.
+func apiMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := metrics.DefaultRegistry.WriteTo(w); err != nil {
+		log.Errorf("unable to write metrics: %v", err)
+	}
+}
+
+// apiRateLimited is the handler for the '/ratelimited?n=N' API request. It
+// returns the n client IPs with the most rate limiter rejections.
+func (s *Server) apiRateLimited(w http.ResponseWriter, r *http.Request) {
+	n := 20
+	if nStr := r.URL.Query().Get(nKey); nStr != "" {
+		var err error
+		n, err = strconv.Atoi(nStr)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("invalid n %q", nStr), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, s.core.RateLimitOffenders(n))
+}
+
 // apiConfig is the handler for the '/config' API request.
 func (s *Server) apiConfig(w http.ResponseWriter, _ *http.Request) {
 	writeJSON(w, s.core.ConfigMsg())
@@ -324,6 +352,118 @@ func (s *Server) apiMarketMatches(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// apiActiveSwaps is the handler for the '/swaps' and '/market/{marketName}/swaps'
+// API requests. If revokewithin is a duration, only matches that will be
+// revoked within that duration if the actor does not act are returned. Matches
+// are sorted by deadline, soonest first.
+func (s *Server) apiActiveSwaps(w http.ResponseWriter, r *http.Request) {
+	var revokeWithin time.Duration
+	if withinStr := r.URL.Query().Get(revokeWithinKey); withinStr != "" {
+		var err error
+		revokeWithin, err = time.ParseDuration(withinStr)
+		if err != nil || revokeWithin <= 0 {
+			http.Error(w, fmt.Sprintf("invalid revokewithin duration %q", withinStr), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var base, quote uint32
+	mkt := strings.ToLower(chi.URLParam(r, marketNameKey))
+	if mkt != "" {
+		status := s.core.MarketStatus(mkt)
+		if status == nil {
+			http.Error(w, fmt.Sprintf("unknown market %q", mkt), http.StatusBadRequest)
+			return
+		}
+		base, quote = status.Base, status.Quote
+	}
+
+	now := time.Now()
+	swaps := make([]*swap.ActiveSwap, 0)
+	for _, as := range s.core.ActiveSwaps(r.Context()) {
+		if mkt != "" && (as.Base != base || as.Quote != quote) {
+			continue
+		}
+		if revokeWithin > 0 && (as.Deadline.IsZero() || as.Deadline.Sub(now) > revokeWithin) {
+			continue
+		}
+		swaps = append(swaps, as)
+	}
+	sort.Slice(swaps, func(i, j int) bool {
+		di, dj := swaps[i].Deadline, swaps[j].Deadline
+		if di.IsZero() || dj.IsZero() {
+			return !di.IsZero()
+		}
+		return di.Before(dj)
+	})
+
+	res := make([]*ActiveSwap, 0, len(swaps))
+	for _, as := range swaps {
+		res = append(res, newActiveSwap(as, now))
+	}
+	writeJSON(w, res)
+}
+
+// optionalAPITime is a *APITime that is nil for the zero time.
+func optionalAPITime(t time.Time) *APITime {
+	if t.IsZero() {
+		return nil
+	}
+	return &APITime{t}
+}
+
+func newSwapProgress(p *swap.SwapProgress) *SwapProgress {
+	return &SwapProgress{
+		Asset:         dex.BipIDSymbol(p.SwapAsset),
+		Swap:          p.Swap,
+		SwapTime:      optionalAPITime(p.SwapTime),
+		Confs:         p.Confs,
+		RequiredConfs: p.RequiredConfs,
+		ConfirmTime:   optionalAPITime(p.ConfirmTime),
+		LockTime:      optionalAPITime(p.LockTime),
+		Redeem:        p.Redeem,
+		RedeemTime:    optionalAPITime(p.RedeemTime),
+	}
+}
+
+func newActiveSwap(as *swap.ActiveSwap, now time.Time) *ActiveSwap {
+	mktName, err := dex.MarketName(as.Base, as.Quote)
+	if err != nil {
+		mktName = fmt.Sprintf("%d_%d", as.Base, as.Quote)
+	}
+	res := &ActiveSwap{
+		ID:         as.MatchID.String(),
+		Market:     mktName,
+		Status:     as.Status.String(),
+		MakerOrder: as.MakerOrder.String(),
+		MakerAcct:  as.MakerAcct.String(),
+		TakerOrder: as.TakerOrder.String(),
+		TakerAcct:  as.TakerAcct.String(),
+		Quantity:   as.Quantity,
+		Rate:       as.Rate,
+		Private:    as.Private,
+		MatchTime:  APITime{as.MatchTime},
+		StepStart:  optionalAPITime(as.StepStart),
+		Deadline:   optionalAPITime(as.Deadline),
+		Maker:      newSwapProgress(as.Maker),
+		Taker:      newSwapProgress(as.Taker),
+	}
+	if !as.StepStart.IsZero() {
+		res.TimeInStep = now.Sub(as.StepStart).Round(time.Second).String()
+	}
+	if !as.Deadline.IsZero() {
+		res.TimeToDeadline = as.Deadline.Sub(now).Round(time.Second).String()
+	}
+	if as.Status != order.MatchComplete {
+		res.Actor = "taker"
+		if as.MakerActs {
+			res.Actor = "maker"
+		}
+		res.ActorAcct = as.Actor().String()
+	}
+	return res
+}
+
 // handler for route '/market/{marketName}/resume?t=UNIXMS'
 func (s *Server) apiResume(w http.ResponseWriter, r *http.Request) {
 	// Ensure the market exists and is not running.
@@ -432,6 +572,147 @@ func (s *Server) apiSuspend(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handler for route '/market/{marketName}/add', with the market parameters
+// POSTed as JSON. Only markets between configured assets can be added, since
+// asset backends are not created at runtime.
+func (s *Server) apiAddMarket(w http.ResponseWriter, r *http.Request) {
+	mkt := strings.ToLower(chi.URLParam(r, marketNameKey))
+	if found, _ := s.core.MarketRunning(mkt); found {
+		http.Error(w, fmt.Sprintf("market %q already exists", mkt), http.StatusBadRequest)
+		return
+	}
+	baseSymbol, quoteSymbol, ok := strings.Cut(mkt, "_")
+	if !ok {
+		http.Error(w, fmt.Sprintf("invalid market name %q", mkt), http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to read request body: %v", err), http.StatusInternalServerError)
+		return
+	}
+	var form MarketPost
+	if err := json.Unmarshal(body, &form); err != nil {
+		http.Error(w, fmt.Sprintf("invalid market parameters: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	mktInfo, err := dex.NewMarketInfoFromSymbols(baseSymbol, quoteSymbol, form.LotSize,
+		form.RateStep, form.Duration, form.ParcelSize, form.MBBuffer)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid market: %v", err), http.StatusBadRequest)
+		return
+	}
+	mktInfo.PrivateSwaps = form.PrivateSwaps
+
+	startEpoch, startTime, err := s.core.AddMarket(mktInfo)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to add market: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &AddMarketResult{
+		Market:     mkt,
+		StartEpoch: startEpoch,
+		StartTime:  APITime{startTime},
+	})
+}
+
+// handler for route '/market/{marketName}/retire'
+func (s *Server) apiRetireMarket(w http.ResponseWriter, r *http.Request) {
+	mkt := strings.ToLower(chi.URLParam(r, marketNameKey))
+	if found, _ := s.core.MarketRunning(mkt); !found {
+		http.Error(w, fmt.Sprintf("unknown market %q", mkt), http.StatusBadRequest)
+		return
+	}
+
+	suspEpoch, err := s.core.RetireMarket(mkt)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to retire market: %v", err)
+		log.Errorf(msg)
+		http.Error(w, msg, http.StatusInternalServerError)
+		return
+	}
+
+	res := &RetireResult{Market: mkt}
+	if suspEpoch != nil {
+		res.FinalEpoch = suspEpoch.Idx
+		res.SuspendTime = &APITime{suspEpoch.End}
+	}
+	writeJSON(w, res)
+}
+
+// handler for route '/market/{marketName}/reconfigure?lotsize=LOTSIZE&ratestep=RATESTEP&t=EPOCH-MS'.
+// At least one of lotsize and ratestep must be specified.
+func (s *Server) apiReconfigureMarket(w http.ResponseWriter, r *http.Request) {
+	mkt := strings.ToLower(chi.URLParam(r, marketNameKey))
+	if found, _ := s.core.MarketRunning(mkt); !found {
+		http.Error(w, fmt.Sprintf("unknown market %q", mkt), http.StatusBadRequest)
+		return
+	}
+
+	parseUint := func(key string) (uint64, bool) {
+		str := r.URL.Query().Get(key)
+		if str == "" {
+			return 0, true
+		}
+		v, err := strconv.ParseUint(str, 10, 64)
+		if err != nil || v == 0 {
+			http.Error(w, fmt.Sprintf("invalid %s %q", key, str), http.StatusBadRequest)
+			return 0, false
+		}
+		return v, true
+	}
+	lotSize, ok := parseUint("lotsize")
+	if !ok {
+		return
+	}
+	rateStep, ok := parseUint("ratestep")
+	if !ok {
+		return
+	}
+	if lotSize == 0 && rateStep == 0 {
+		http.Error(w, "no lotsize or ratestep specified", http.StatusBadRequest)
+		return
+	}
+
+	// Validate the time provided in the "t" query. If not specified, the zero
+	// time.Time is used to indicate ASAP.
+	var asSoonAs time.Time
+	if tStr := r.URL.Query().Get("t"); tStr != "" {
+		tMs, err := strconv.ParseInt(tStr, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid reconfigure time %q: %v", tStr, err), http.StatusBadRequest)
+			return
+		}
+		asSoonAs = time.UnixMilli(tMs)
+		if time.Until(asSoonAs) < 0 {
+			http.Error(w, fmt.Sprintf("specified market reconfigure time is in the past: %v", asSoonAs),
+				http.StatusBadRequest)
+			return
+		}
+	}
+
+	suspEpoch, err := s.core.ReconfigureMarket(mkt, lotSize, rateStep, asSoonAs)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to reconfigure market: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	res := &ReconfigureResult{
+		Market:   mkt,
+		LotSize:  lotSize,
+		RateStep: rateStep,
+	}
+	if suspEpoch != nil {
+		res.FinalEpoch = suspEpoch.Idx
+		res.SuspendTime = &APITime{suspEpoch.End}
+	}
+	writeJSON(w, res)
+}
+
 // apiEnableDataAPI is the handler for the `/enabledataapi/{yes}` API request,
 // used to enable or disable the HTTP data API.
 func (s *Server) apiEnableDataAPI(w http.ResponseWriter, r *http.Request) {
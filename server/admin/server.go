@@ -24,9 +24,11 @@ import (
 	"decred.org/dcrdex/server/account"
 	"decred.org/dcrdex/server/asset"
 	"decred.org/dcrdex/server/auth"
+	"decred.org/dcrdex/server/comms"
 	"decred.org/dcrdex/server/db"
 	dexsrv "decred.org/dcrdex/server/dex"
 	"decred.org/dcrdex/server/market"
+	"decred.org/dcrdex/server/swap"
 	"github.com/decred/slog"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -49,6 +51,7 @@ const (
 	nKey               = "n"
 	daysKey            = "days"
 	strengthKey        = "strength"
+	revokeWithinKey    = "revokewithin"
 )
 
 var (
@@ -70,6 +73,9 @@ type SvrCore interface {
 	MarketStatuses() map[string]*market.Status
 	SuspendMarket(name string, tSusp time.Time, persistBooks bool) (*market.SuspendEpoch, error)
 	ResumeMarket(name string, asSoonAs time.Time) (startEpoch int64, startTime time.Time, err error)
+	AddMarket(mktInf *dex.MarketInfo) (startEpoch int64, startTime time.Time, err error)
+	RetireMarket(name string) (*market.SuspendEpoch, error)
+	ReconfigureMarket(name string, lotSize, rateStep uint64, asSoonAs time.Time) (*market.SuspendEpoch, error)
 	ForgiveMatchFail(aid account.AccountID, mid order.MatchID) (forgiven, unbanned bool, err error)
 	AccountMatchOutcomesN(user account.AccountID, n int) ([]*auth.MatchOutcome, error)
 	BookOrders(base, quote uint32) (orders []*order.LimitOrder, err error)
@@ -78,6 +84,8 @@ type SvrCore interface {
 	EnableDataAPI(yes bool)
 	CreatePrepaidBonds(n int, strength uint32, durSecs int64) ([][]byte, error)
 	ForgiveUser(user account.AccountID) error
+	ActiveSwaps(ctx context.Context) []*swap.ActiveSwap
+	RateLimitOffenders(n int) []*comms.RateLimitOffender
 }
 
 // Server is a multi-client https server.
@@ -166,15 +174,22 @@ func NewServer(cfg *SrvConfig) (*Server, error) {
 		})
 		r.Post("/notifyall", s.apiNotifyAll)
 		r.Get("/markets", s.apiMarkets)
+		r.Get("/swaps", s.apiActiveSwaps)
 		r.Route("/market/{"+marketNameKey+"}", func(rm chi.Router) {
 			rm.Get("/", s.apiMarketInfo)
 			rm.Get("/orderbook", s.apiMarketOrderBook)
 			rm.Get("/epochorders", s.apiMarketEpochOrders)
 			rm.Get("/matches", s.apiMarketMatches)
+			rm.Get("/swaps", s.apiActiveSwaps)
 			rm.Get("/suspend", s.apiSuspend)
 			rm.Get("/resume", s.apiResume)
+			rm.Post("/add", s.apiAddMarket)
+			rm.Get("/retire", s.apiRetireMarket)
+			rm.Get("/reconfigure", s.apiReconfigureMarket)
 		})
 		r.Get("/prepaybonds", s.prepayBonds)
+		r.Get("/metrics", apiMetrics)
+		r.Get("/ratelimited", s.apiRateLimited)
 	})
 
 	return s, nil
@@ -24,14 +24,18 @@ import (
 	"time"
 
 	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
 	"decred.org/dcrdex/dex/msgjson"
 	"decred.org/dcrdex/dex/order"
 	"decred.org/dcrdex/server/account"
 	"decred.org/dcrdex/server/asset"
 	"decred.org/dcrdex/server/auth"
+	"decred.org/dcrdex/server/comms"
 	"decred.org/dcrdex/server/db"
 	dexsrv "decred.org/dcrdex/server/dex"
 	"decred.org/dcrdex/server/market"
+	"decred.org/dcrdex/server/metrics"
+	"decred.org/dcrdex/server/swap"
 	"github.com/decred/dcrd/certgen"
 	"github.com/decred/slog"
 	"github.com/go-chi/chi/v5"
@@ -43,6 +47,7 @@ func init() {
 }
 
 type TMarket struct {
+	base, quote uint32
 	running     bool
 	dur         uint64
 	suspend     *market.SuspendEpoch
@@ -51,6 +56,7 @@ type TMarket struct {
 	resumeEpoch int64
 	resumeTime  time.Time
 	persist     bool
+	private     bool
 }
 
 type TCore struct {
@@ -67,7 +73,11 @@ type TCore struct {
 	epochOrdersErr   error
 	marketMatches    []*dexsrv.MatchData
 	marketMatchesErr error
+	activeSwaps      []*swap.ActiveSwap
 	dataEnabled      uint32
+	addMarketErr     error
+	reconfigureErr   error
+	offenders        []*comms.RateLimitOffender
 }
 
 func (c *TCore) ConfigMsg() json.RawMessage { return nil }
@@ -97,6 +107,51 @@ func (c *TCore) SuspendMarket(name string, tSusp time.Time, persistBooks bool) (
 	return tMkt.suspend, nil
 }
 
+func (c *TCore) AddMarket(mktInf *dex.MarketInfo) (startEpoch int64, startTime time.Time, err error) {
+	if c.addMarketErr != nil {
+		return 0, time.Time{}, c.addMarketErr
+	}
+	dur := int64(mktInf.EpochDuration)
+	startEpoch = 1 + time.Now().UnixMilli()/dur
+	c.markets[mktInf.Name] = &TMarket{
+		running:    true,
+		dur:        mktInf.EpochDuration,
+		startEpoch: startEpoch,
+		private:    mktInf.PrivateSwaps,
+	}
+	return startEpoch, time.UnixMilli(startEpoch * dur), nil
+}
+func (c *TCore) RetireMarket(name string) (*market.SuspendEpoch, error) {
+	tMkt := c.markets[name]
+	if tMkt == nil {
+		return nil, fmt.Errorf("unknown market %s", name)
+	}
+	delete(c.markets, name)
+	if !tMkt.running {
+		return nil, nil
+	}
+	return &market.SuspendEpoch{
+		Idx: tMkt.activeEpoch,
+		End: time.UnixMilli((tMkt.activeEpoch + 1) * int64(tMkt.dur)),
+	}, nil
+}
+func (c *TCore) ReconfigureMarket(name string, lotSize, rateStep uint64, asSoonAs time.Time) (*market.SuspendEpoch, error) {
+	if c.reconfigureErr != nil {
+		return nil, c.reconfigureErr
+	}
+	tMkt := c.markets[name]
+	if tMkt == nil {
+		return nil, fmt.Errorf("unknown market %s", name)
+	}
+	if !tMkt.running {
+		return nil, nil
+	}
+	return &market.SuspendEpoch{
+		Idx: tMkt.activeEpoch,
+		End: time.UnixMilli((tMkt.activeEpoch + 1) * int64(tMkt.dur)),
+	}, nil
+}
+
 func (c *TCore) market(name string) *TMarket {
 	if c.markets == nil {
 		return nil
@@ -114,6 +169,8 @@ func (c *TCore) MarketStatus(mktName string) *market.Status {
 		suspendEpoch = mkt.suspend.Idx
 	}
 	return &market.Status{
+		Base:          mkt.base,
+		Quote:         mkt.quote,
 		Running:       mkt.running,
 		EpochDuration: mkt.dur,
 		ActiveEpoch:   mkt.activeEpoch,
@@ -174,6 +231,13 @@ func (c *TCore) MarketRunning(mktName string) (found, running bool) {
 	return true, mkt.running
 }
 
+func (c *TCore) RateLimitOffenders(n int) []*comms.RateLimitOffender {
+	if len(c.offenders) > n {
+		return c.offenders[:n]
+	}
+	return c.offenders
+}
+
 func (c *TCore) EnableDataAPI(yes bool) {
 	var v uint32
 	if yes {
@@ -223,6 +287,9 @@ func (c *TCore) AccountMatchOutcomesN(user account.AccountID, n int) ([]*auth.Ma
 func (c *TCore) Notify(_ account.AccountID, _ *msgjson.Message) {}
 func (c *TCore) NotifyAll(_ *msgjson.Message)                   {}
 func (c *TCore) ForgiveUser(account.AccountID) error            { return nil }
+func (c *TCore) ActiveSwaps(context.Context) []*swap.ActiveSwap {
+	return c.activeSwaps
+}
 
 // genCertPair generates a key/cert pair to the paths provided.
 func genCertPair(certFile, keyFile string) error {
@@ -315,6 +382,27 @@ func TestPing(t *testing.T) {
 	}
 }
 
+func TestMetrics(t *testing.T) {
+	w := httptest.NewRecorder()
+	apiMetrics(w, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("apiMetrics returned code %d, expected 200", w.Code)
+	}
+	if ct := w.Result().Header.Get("Content-Type"); ct != metrics.ContentType {
+		t.Errorf("Content-Type incorrect. got %q, expected %q", ct, metrics.ContentType)
+	}
+	// The comms package registers its metrics with the default registry.
+	body := w.Body.String()
+	for _, want := range []string{
+		"# TYPE dcrdex_comms_ws_connections gauge\n",
+		"# TYPE dcrdex_comms_rate_limit_rejections_total counter\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics response missing %q", want)
+		}
+	}
+}
+
 func TestMarkets(t *testing.T) {
 	core := &TCore{
 		markets: make(map[string]*TMarket),
@@ -743,6 +831,123 @@ func TestMarketMatches(t *testing.T) {
 	}
 }
 
+func TestActiveSwaps(t *testing.T) {
+	core := &TCore{
+		markets: map[string]*TMarket{
+			"dcr_btc": {base: 42, quote: 0},
+		},
+	}
+	srv := &Server{
+		core: core,
+	}
+	mux := chi.NewRouter()
+	mux.Get("/swaps", srv.apiActiveSwaps)
+	mux.Get("/market/{"+marketNameKey+"}/swaps", srv.apiActiveSwaps)
+
+	now := time.Now()
+	newSwap := func(base, quote uint32, status order.MatchStatus, deadline time.Time) *swap.ActiveSwap {
+		var mid order.MatchID
+		copy(mid[:], encode.RandomBytes(order.MatchIDSize))
+		return &swap.ActiveSwap{
+			MatchID:   mid,
+			Base:      base,
+			Quote:     quote,
+			Status:    status,
+			MakerAcct: account.AccountID{0x01},
+			TakerAcct: account.AccountID{0x02},
+			MatchTime: now.Add(-time.Hour),
+			StepStart: now.Add(-time.Minute),
+			MakerActs: status == order.NewlyMatched,
+			Deadline:  deadline,
+			Maker:     &swap.SwapProgress{SwapAsset: base, Confs: 1},
+			Taker:     &swap.SwapProgress{SwapAsset: quote, Confs: -1},
+		}
+	}
+	late := newSwap(42, 0, order.MakerSwapCast, now.Add(time.Hour))
+	soon := newSwap(42, 0, order.NewlyMatched, now.Add(time.Minute))
+	complete := newSwap(0, 2, order.MatchComplete, time.Time{})
+	core.activeSwaps = []*swap.ActiveSwap{late, complete, soon}
+
+	tests := []struct {
+		name, path string
+		wantCode   int
+		wantIDs    []order.MatchID
+	}{{
+		name:     "all",
+		path:     "/swaps",
+		wantCode: http.StatusOK,
+		wantIDs:  []order.MatchID{soon.MatchID, late.MatchID, complete.MatchID},
+	}, {
+		name:     "near revocation",
+		path:     "/swaps?" + revokeWithinKey + "=10m",
+		wantCode: http.StatusOK,
+		wantIDs:  []order.MatchID{soon.MatchID},
+	}, {
+		name:     "market",
+		path:     "/market/dcr_btc/swaps",
+		wantCode: http.StatusOK,
+		wantIDs:  []order.MatchID{soon.MatchID, late.MatchID},
+	}, {
+		name:     "market near revocation",
+		path:     "/market/dcr_btc/swaps?" + revokeWithinKey + "=2h",
+		wantCode: http.StatusOK,
+		wantIDs:  []order.MatchID{soon.MatchID, late.MatchID},
+	}, {
+		name:     "bad duration",
+		path:     "/swaps?" + revokeWithinKey + "=soon",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "unknown market",
+		path:     "/market/btc_dcr/swaps",
+		wantCode: http.StatusBadRequest,
+	}}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "https://localhost"+test.path, nil)
+		r.RemoteAddr = "localhost"
+
+		mux.ServeHTTP(w, r)
+
+		if w.Code != test.wantCode {
+			t.Fatalf("%q: apiActiveSwaps returned code %d, expected %d", test.name, w.Code, test.wantCode)
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		var res []*ActiveSwap
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("%q: failed to unmarshal response: %v", test.name, err)
+		}
+		if len(res) != len(test.wantIDs) {
+			t.Fatalf("%q: expected %d swaps, got %d", test.name, len(test.wantIDs), len(res))
+		}
+		for i, as := range res {
+			if as.ID != test.wantIDs[i].String() {
+				t.Fatalf("%q: wrong swap at index %d", test.name, i)
+			}
+		}
+	}
+
+	// Check the conversion of a newly matched swap.
+	as := newActiveSwap(soon, now)
+	if as.Market != "dcr_btc" || as.Status != order.NewlyMatched.String() {
+		t.Fatalf("wrong market or status: %s, %s", as.Market, as.Status)
+	}
+	if as.Actor != "maker" || as.ActorAcct != soon.MakerAcct.String() {
+		t.Fatalf("wrong actor %s (%s)", as.Actor, as.ActorAcct)
+	}
+	if as.TimeInStep != "1m0s" || as.TimeToDeadline != "1m0s" {
+		t.Fatalf("wrong step times %s, %s", as.TimeInStep, as.TimeToDeadline)
+	}
+	if as.Maker.Asset != "dcr" || as.Taker.Confs != -1 || as.Taker.SwapTime != nil {
+		t.Fatalf("wrong swap progress")
+	}
+	// A complete match has no actor.
+	if as = newActiveSwap(complete, now); as.Actor != "" || as.Deadline != nil {
+		t.Fatalf("complete match has an actor or deadline")
+	}
+}
+
 func TestResume(t *testing.T) {
 	core := &TCore{
 		markets: make(map[string]*TMarket),
@@ -1038,6 +1243,257 @@ func TestSuspend(t *testing.T) {
 	}
 }
 
+func TestAddMarket(t *testing.T) {
+	core := &TCore{
+		markets: make(map[string]*TMarket),
+	}
+	srv := &Server{
+		core: core,
+	}
+
+	mux := chi.NewRouter()
+	mux.Post("/market/{"+marketNameKey+"}/add", srv.apiAddMarket)
+
+	const validBody = `{"lotSize":100000000,"rateStep":100,"parcelSize":2,"epochDuration":6000,"marketBuyBuffer":1.2}`
+
+	tests := []struct {
+		name        string
+		mkt         string
+		body        string
+		addErr      error
+		existing    bool
+		wantCode    int
+		wantPrivate bool
+	}{{
+		name:     "ok",
+		mkt:      "dcr_btc",
+		body:     validBody,
+		wantCode: http.StatusOK,
+	}, {
+		name:        "private swaps",
+		mkt:         "dcr_btc",
+		body:        `{"lotSize":100000000,"rateStep":100,"parcelSize":2,"epochDuration":6000,"privateSwaps":true}`,
+		wantCode:    http.StatusOK,
+		wantPrivate: true,
+	}, {
+		name:     "existing market",
+		mkt:      "dcr_btc",
+		body:     validBody,
+		existing: true,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "bad market name",
+		mkt:      "dcrbtc",
+		body:     validBody,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "unknown asset",
+		mkt:      "dcr_abc",
+		body:     validBody,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "bad body",
+		mkt:      "dcr_btc",
+		body:     `{"lotSize":"abc"}`,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "core error",
+		mkt:      "dcr_btc",
+		body:     validBody,
+		addErr:   errors.New(""),
+		wantCode: http.StatusBadRequest,
+	}}
+
+	for _, test := range tests {
+		core.markets = make(map[string]*TMarket)
+		if test.existing {
+			core.markets[test.mkt] = &TMarket{running: true, dur: 6000}
+		}
+		core.addMarketErr = test.addErr
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "https://localhost/market/"+test.mkt+"/add", strings.NewReader(test.body))
+		r.RemoteAddr = "localhost"
+
+		mux.ServeHTTP(w, r)
+
+		if w.Code != test.wantCode {
+			t.Fatalf("%q: apiAddMarket returned code %d, expected %d", test.name, w.Code, test.wantCode)
+		}
+		if test.wantCode != http.StatusOK {
+			continue
+		}
+
+		var res AddMarketResult
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("%q: failed to unmarshal result: %v", test.name, err)
+		}
+		tMkt := core.markets[test.mkt]
+		if tMkt == nil {
+			t.Fatalf("%q: market not added", test.name)
+		}
+		if res.Market != test.mkt || res.StartEpoch != tMkt.startEpoch ||
+			res.StartTime.UnixMilli() != tMkt.startEpoch*6000 {
+			t.Fatalf("%q: unexpected result %+v", test.name, res)
+		}
+		if tMkt.private != test.wantPrivate {
+			t.Fatalf("%q: expected private swaps %t, got %t", test.name, test.wantPrivate, tMkt.private)
+		}
+	}
+}
+
+func TestRetireMarket(t *testing.T) {
+	core := &TCore{
+		markets: make(map[string]*TMarket),
+	}
+	srv := &Server{
+		core: core,
+	}
+
+	mux := chi.NewRouter()
+	mux.Get("/market/{"+marketNameKey+"}/retire", srv.apiRetireMarket)
+
+	name := "dcr_btc"
+	retire := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "https://localhost/market/"+name+"/retire", nil)
+		r.RemoteAddr = "localhost"
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	// Non-existent market
+	if w := retire(); w.Code != http.StatusBadRequest {
+		t.Fatalf("apiRetireMarket returned code %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+
+	// Running market is suspended before removal.
+	core.markets[name] = &TMarket{
+		running:     true,
+		dur:         6000,
+		activeEpoch: 12345,
+	}
+	w := retire()
+	if w.Code != http.StatusOK {
+		t.Fatalf("apiRetireMarket returned code %d, expected %d", w.Code, http.StatusOK)
+	}
+	var res RetireResult
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("failed to unmarshal result: %v", err)
+	}
+	if res.Market != name || res.FinalEpoch != 12345 || res.SuspendTime == nil ||
+		res.SuspendTime.UnixMilli() != 12346*6000 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if core.markets[name] != nil {
+		t.Fatalf("market not retired")
+	}
+
+	// Stopped market is removed immediately.
+	core.markets[name] = &TMarket{dur: 6000}
+	w = retire()
+	if w.Code != http.StatusOK {
+		t.Fatalf("apiRetireMarket returned code %d, expected %d", w.Code, http.StatusOK)
+	}
+	wantBody := "{\n    \"market\": \"dcr_btc\"\n}\n"
+	if w.Body.String() != wantBody {
+		t.Fatalf("expected body %q, got %q", wantBody, w.Body.String())
+	}
+}
+
+func TestReconfigureMarket(t *testing.T) {
+	core := &TCore{
+		markets: map[string]*TMarket{
+			"dcr_btc": {
+				running:     true,
+				dur:         6000,
+				activeEpoch: 12345,
+			},
+			"ltc_btc": {
+				dur: 6000,
+			},
+		},
+	}
+	srv := &Server{
+		core: core,
+	}
+
+	mux := chi.NewRouter()
+	mux.Get("/market/{"+marketNameKey+"}/reconfigure", srv.apiReconfigureMarket)
+
+	tests := []struct {
+		name, mkt, query string
+		reconfigureErr   error
+		wantCode         int
+		wantFinalEpoch   int64
+	}{{
+		name:           "ok running",
+		mkt:            "dcr_btc",
+		query:          "?lotsize=200000000&ratestep=1000",
+		wantCode:       http.StatusOK,
+		wantFinalEpoch: 12345,
+	}, {
+		name:     "ok stopped",
+		mkt:      "ltc_btc",
+		query:    "?ratestep=1000",
+		wantCode: http.StatusOK,
+	}, {
+		name:     "unknown market",
+		mkt:      "doge_btc",
+		query:    "?lotsize=200000000",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "no parameters",
+		mkt:      "dcr_btc",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "bad lot size",
+		mkt:      "dcr_btc",
+		query:    "?lotsize=abc",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "zero rate step",
+		mkt:      "dcr_btc",
+		query:    "?ratestep=0",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "time in past",
+		mkt:      "dcr_btc",
+		query:    "?lotsize=200000000&t=1000",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:           "core error",
+		mkt:            "dcr_btc",
+		query:          "?lotsize=1",
+		reconfigureErr: errors.New("lot size too low"),
+		wantCode:       http.StatusBadRequest,
+	}}
+
+	for _, test := range tests {
+		core.reconfigureErr = test.reconfigureErr
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "https://localhost/market/"+test.mkt+"/reconfigure"+test.query, nil)
+		r.RemoteAddr = "localhost"
+		mux.ServeHTTP(w, r)
+		if w.Code != test.wantCode {
+			t.Fatalf("%s: apiReconfigureMarket returned code %d, expected %d", test.name, w.Code, test.wantCode)
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		var res ReconfigureResult
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("%s: failed to unmarshal result: %v", test.name, err)
+		}
+		if res.Market != test.mkt || res.FinalEpoch != test.wantFinalEpoch {
+			t.Fatalf("%s: unexpected result %+v", test.name, res)
+		}
+		if (res.SuspendTime != nil) != (test.wantFinalEpoch != 0) {
+			t.Fatalf("%s: unexpected suspend time %v", test.name, res.SuspendTime)
+		}
+	}
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	pass := "password123"
 	authSHA := sha256.Sum256([]byte(pass))
@@ -1325,6 +1781,56 @@ func TestNotifyAll(t *testing.T) {
 	}
 }
 
+func TestRateLimited(t *testing.T) {
+	core := &TCore{offenders: []*comms.RateLimitOffender{
+		{IP: "10.0.0.1", Rejections: 5, ByLimiter: map[string]uint64{"ip_http": 5}},
+		{IP: "10.0.0.2", Rejections: 2, ByLimiter: map[string]uint64{"ws_route": 2}},
+	}}
+	srv := &Server{core: core}
+	mux := chi.NewRouter()
+	mux.Get("/ratelimited", srv.apiRateLimited)
+
+	tests := []struct {
+		name, query string
+		wantCode    int
+		wantN       int
+	}{{
+		name:     "default",
+		wantCode: http.StatusOK,
+		wantN:    2,
+	}, {
+		name:     "top 1",
+		query:    "?n=1",
+		wantCode: http.StatusOK,
+		wantN:    1,
+	}, {
+		name:     "bad n",
+		query:    "?n=-1",
+		wantCode: http.StatusBadRequest,
+	}}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "https://localhost/ratelimited"+test.query, nil)
+		r.RemoteAddr = "localhost"
+
+		mux.ServeHTTP(w, r)
+
+		if w.Code != test.wantCode {
+			t.Fatalf("%q: apiRateLimited returned code %d, expected %d", test.name, w.Code, test.wantCode)
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		var offenders []*comms.RateLimitOffender
+		if err := json.Unmarshal(w.Body.Bytes(), &offenders); err != nil {
+			t.Fatalf("%q: error decoding response: %v", test.name, err)
+		}
+		if len(offenders) != test.wantN {
+			t.Fatalf("%q: expected %d offenders, got %d", test.name, test.wantN, len(offenders))
+		}
+	}
+}
+
 func TestEnableDataAPI(t *testing.T) {
 	core := new(TCore)
 	srv := &Server{
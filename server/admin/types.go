@@ -14,6 +14,18 @@ type AssetPost struct {
 	FeeRateScale *float64 `json:"feeRateScale,omitempty"`
 }
 
+// MarketPost is the expected structure of the market POST data used to add a
+// market. The fields match those of a market in the markets config file.
+type MarketPost struct {
+	LotSize    uint64  `json:"lotSize"`
+	ParcelSize uint32  `json:"parcelSize"`
+	RateStep   uint64  `json:"rateStep"`
+	Duration   uint64  `json:"epochDuration"`
+	MBBuffer   float64 `json:"marketBuyBuffer"`
+	// PrivateSwaps enables adaptor signature private swaps for the market.
+	PrivateSwaps bool `json:"privateSwaps,omitempty"`
+}
+
 // AssetInfo is the result of the asset GET. Note that ScaledFeeRate is
 // CurrentFeeRate multiplied by an operator-specified fee scale rate for this
 // asset, and then limited by the dex.Asset.MaxFeeRate.
@@ -60,6 +72,45 @@ type MatchData struct {
 	Status      string `json:"status"`
 }
 
+// SwapProgress describes one party's swap in an active match.
+type SwapProgress struct {
+	Asset         string   `json:"asset"`
+	Swap          string   `json:"swap,omitempty"`
+	SwapTime      *APITime `json:"swapTime,omitempty"`
+	Confs         int64    `json:"confs"`
+	RequiredConfs uint32   `json:"requiredConfs"`
+	ConfirmTime   *APITime `json:"confirmTime,omitempty"`
+	LockTime      *APITime `json:"lockTime,omitempty"`
+	Redeem        string   `json:"redeem,omitempty"`
+	RedeemTime    *APITime `json:"redeemTime,omitempty"`
+}
+
+// ActiveSwap describes a match that is being settled. Actor is "maker" or
+// "taker", and is empty if the match is complete. Deadline is the time at
+// which the match will be revoked if the actor does not act, and is omitted if
+// the revocation clock has not started.
+type ActiveSwap struct {
+	ID             string        `json:"id"`
+	Market         string        `json:"market"`
+	Status         string        `json:"status"`
+	MakerOrder     string        `json:"makerOrder"`
+	MakerAcct      string        `json:"makerAcct"`
+	TakerOrder     string        `json:"takerOrder"`
+	TakerAcct      string        `json:"takerAcct"`
+	Quantity       uint64        `json:"quantity"`
+	Rate           uint64        `json:"rate"`
+	Private        bool          `json:"private,omitempty"`
+	MatchTime      APITime       `json:"matchTime"`
+	StepStart      *APITime      `json:"stepStart,omitempty"`
+	TimeInStep     string        `json:"timeInStep,omitempty"`
+	Actor          string        `json:"actor,omitempty"`
+	ActorAcct      string        `json:"actorAcct,omitempty"`
+	Deadline       *APITime      `json:"deadline,omitempty"`
+	TimeToDeadline string        `json:"timeToDeadline,omitempty"`
+	Maker          *SwapProgress `json:"maker"`
+	Taker          *SwapProgress `json:"taker"`
+}
+
 // APITime marshals and unmarshals a time value in time.RFC3339Nano format.
 type APITime struct {
 	time.Time
@@ -82,6 +133,34 @@ type ResumeResult struct {
 	StartTime  APITime `json:"starttime"`
 }
 
+// AddMarketResult is the result of a market add request.
+type AddMarketResult struct {
+	Market     string  `json:"market"`
+	StartEpoch int64   `json:"startepoch"`
+	StartTime  APITime `json:"starttime"`
+}
+
+// RetireResult is the result of a market retire request. If the market was
+// running, FinalEpoch and SuspendTime describe the scheduled suspension, after
+// which the market is removed. Otherwise, the market is removed immediately.
+type RetireResult struct {
+	Market      string   `json:"market"`
+	FinalEpoch  int64    `json:"finalepoch,omitempty"`
+	SuspendTime *APITime `json:"suspendtime,omitempty"`
+}
+
+// ReconfigureResult is the result of a market reconfigure request. If the
+// market was running, FinalEpoch and SuspendTime describe the scheduled
+// suspension, after which the new lot size and rate step are applied and the
+// market is resumed. Otherwise, the change is applied immediately.
+type ReconfigureResult struct {
+	Market      string   `json:"market"`
+	LotSize     uint64   `json:"lotSize"`
+	RateStep    uint64   `json:"rateStep"`
+	FinalEpoch  int64    `json:"finalepoch,omitempty"`
+	SuspendTime *APITime `json:"suspendtime,omitempty"`
+}
+
 // RFC3339Milli is the RFC3339 time formatting with millisecond precision.
 const RFC3339Milli = "2006-01-02T15:04:05.999Z07:00"
 
@@ -20,7 +20,10 @@ import (
 var (
 	// Our internal millisecond representation of the bin sizes.
 	binSizes []uint64
-	started  uint32
+	// binSizeNames maps the millisecond bin sizes back to their string
+	// representation, e.g. "5m".
+	binSizeNames = make(map[uint64]string)
+	started      uint32
 )
 
 // DBSource is a source of persistent data. DBSource is used to prime the
@@ -50,10 +53,23 @@ type cacheWithStoredTime struct {
 	lastStoredEndStamp uint64 // protected by DataAPI.cacheMtx
 }
 
+// candleUpdate is a completed candle to be sent to data subscribers of its bin
+// size.
+type candleUpdate struct {
+	binSize uint64
+	*msgjson.CandleUpdate
+}
+
+// dataSubscriber is a websocket connection subscribed to a market's data feed.
+type dataSubscriber struct {
+	conn     comms.Link
+	binSizes map[uint64]bool
+}
+
 // DataAPI is a data API backend.
 type DataAPI struct {
 	db             DBSource
-	epochDurations map[string]uint64
+	epochDurations map[string]uint64 // protected by cacheMtx
 	bookSource     BookSource
 
 	spotsMtx sync.RWMutex
@@ -61,21 +77,31 @@ type DataAPI struct {
 
 	cacheMtx     sync.RWMutex
 	marketCaches map[string]map[uint64]*cacheWithStoredTime
+
+	subsMtx sync.RWMutex
+	subs    map[string]map[uint64]*dataSubscriber // market -> link ID -> sub
 }
 
-// NewDataAPI is the constructor for a new DataAPI.
-func NewDataAPI(dbSrc DBSource, registerHTTP func(route string, handler comms.HTTPHandler)) *DataAPI {
+// NewDataAPI is the constructor for a new DataAPI. The HTTP routes are
+// registered with registerHTTP, and the websocket-only market data
+// subscription routes are registered with routeData.
+func NewDataAPI(dbSrc DBSource, registerHTTP func(route string, handler comms.HTTPHandler),
+	routeData func(route string, handler comms.MsgHandler)) *DataAPI {
+
 	s := &DataAPI{
 		db:             dbSrc,
 		epochDurations: make(map[string]uint64),
 		spots:          make(map[string]json.RawMessage),
 		marketCaches:   make(map[string]map[uint64]*cacheWithStoredTime),
+		subs:           make(map[string]map[uint64]*dataSubscriber),
 	}
 
 	if atomic.CompareAndSwapUint32(&started, 0, 1) {
 		registerHTTP(msgjson.SpotsRoute, s.handleSpots)
 		registerHTTP(msgjson.CandlesRoute, s.handleCandles)
 		registerHTTP(msgjson.OrderBookRoute, s.handleOrderBook)
+		routeData(msgjson.MarketDataSubscribeRoute, s.handleSubscribe)
+		routeData(msgjson.MarketDataUnsubscribeRoute, s.handleUnsubscribe)
 	}
 	return s
 }
@@ -87,7 +113,6 @@ func (s *DataAPI) AddMarketSource(mkt MarketSource) error {
 		return err
 	}
 	epochDur := mkt.EpochDuration()
-	s.epochDurations[mktName] = epochDur
 	binCaches := make(map[uint64]*cacheWithStoredTime, len(binSizes)+1)
 	cacheList := make([]*candles.Cache, 0, len(binSizes)+1)
 	for _, binSize := range append([]uint64{epochDur}, binSizes...) {
@@ -105,24 +130,49 @@ func (s *DataAPI) AddMarketSource(mkt MarketSource) error {
 		return err
 	}
 	s.cacheMtx.Lock()
+	s.epochDurations[mktName] = epochDur
 	s.marketCaches[mktName] = binCaches
 	s.cacheMtx.Unlock()
 	return nil
 }
 
+// RemoveMarketSource removes a MarketSource added with AddMarketSource. The
+// market's candles and spot price are no longer served.
+func (s *DataAPI) RemoveMarketSource(mkt MarketSource) error {
+	mktName, err := dex.MarketName(mkt.Base(), mkt.Quote())
+	if err != nil {
+		return err
+	}
+	s.cacheMtx.Lock()
+	delete(s.epochDurations, mktName)
+	delete(s.marketCaches, mktName)
+	s.cacheMtx.Unlock()
+	s.spotsMtx.Lock()
+	delete(s.spots, mktName)
+	s.spotsMtx.Unlock()
+	s.subsMtx.Lock()
+	delete(s.subs, mktName)
+	s.subsMtx.Unlock()
+	return nil
+}
+
 // SetBookSource should be called before the first call to handleBook.
 func (s *DataAPI) SetBookSource(bs BookSource) {
 	s.bookSource = bs
 }
 
 // ReportEpoch should be called by every Market after every match cycle to
-// report their epoch stats.
+// report their epoch stats. The spot, the epoch summary, and any candles
+// completed by the epoch are sent to the market's data subscribers.
 func (s *DataAPI) ReportEpoch(base, quote uint32, epochIdx uint64, stats *matcher.MatchCycleStats) (*msgjson.Spot, error) {
 	mktName, err := dex.MarketName(base, quote)
 	if err != nil {
 		return nil, err
 	}
 
+	var epochCandle candles.Candle
+	var candleUpdates []*candleUpdate
+
 	// Add the candlestick.
 	addCandle := func() (change24 float64, vol24, high24, low24 uint64, err error) {
 		s.cacheMtx.Lock()
@@ -146,6 +196,7 @@ func (s *DataAPI) ReportEpoch(base, quote uint32, epochIdx uint64, stats *matche
 			StartRate:   stats.StartRate,
 			EndRate:     stats.EndRate,
 		}
+		epochCandle = *candle
 		for dur, cache := range mktCaches {
 			if dur == fiveMins {
 				cache5min = cache
@@ -166,6 +217,16 @@ func (s *DataAPI) ReportEpoch(base, quote uint32, epochIdx uint64, stats *matche
 				return 0, 0, 0, 0, fmt.Errorf("InsertCandles: %w", err)
 			}
 			cache.lastStoredEndStamp = newCandles[len(newCandles)-1].EndStamp
+			for _, c := range newCandles {
+				candleUpdates = append(candleUpdates, &candleUpdate{
+					binSize: cache.BinSize,
+					CandleUpdate: &msgjson.CandleUpdate{
+						MarketID: mktName,
+						BinSize:  binSizeNames[cache.BinSize],
+						Candle:   *c, // copy, the cache owns c
+					},
+				})
+			}
 		}
 		if cache5min == nil {
 			return 0, 0, 0, 0, fmt.Errorf("no 5 minute cache")
@@ -194,7 +255,91 @@ func (s *DataAPI) ReportEpoch(base, quote uint32, epochIdx uint64, stats *matche
 	s.spotsMtx.Lock()
 	s.spots[mktName], err = json.Marshal(spot)
 	s.spotsMtx.Unlock()
-	return spot, err
+	if err != nil {
+		return spot, err
+	}
+
+	epochSummary := &msgjson.EpochSummary{
+		MarketID:  mktName,
+		Epoch:     epochIdx,
+		BookBuys:  stats.BookBuys,
+		BookSells: stats.BookSells,
+		Candle:    epochCandle,
+	}
+	return spot, s.sendMarketData(mktName, spot, epochSummary, candleUpdates)
+}
+
+// sendMarketData sends the epoch's notifications to the market's data
+// subscribers. Each notification is only encoded once. Subscribers that cannot
+// be sent to are removed.
+func (s *DataAPI) sendMarketData(mktName string, spot *msgjson.Spot, epochSummary *msgjson.EpochSummary,
+	candleUpdates []*candleUpdate) error {
+
+	s.subsMtx.RLock()
+	numSubs := len(s.subs[mktName])
+	s.subsMtx.RUnlock()
+	if numSubs == 0 {
+		return nil
+	}
+
+	encode := func(route string, payload any) ([]byte, error) {
+		msg, err := msgjson.NewNotification(route, payload)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding %s notification: %w", route, err)
+		}
+		return json.Marshal(msg)
+	}
+
+	spotB, err := encode(msgjson.SpotUpdateRoute, spot)
+	if err != nil {
+		return err
+	}
+	epochB, err := encode(msgjson.EpochSummaryRoute, epochSummary)
+	if err != nil {
+		return err
+	}
+	type encodedCandle struct {
+		binSize uint64
+		b       []byte
+	}
+	candleBs := make([]*encodedCandle, 0, len(candleUpdates))
+	for _, cu := range candleUpdates {
+		b, err := encode(msgjson.CandleUpdateRoute, cu.CandleUpdate)
+		if err != nil {
+			return err
+		}
+		candleBs = append(candleBs, &encodedCandle{cu.binSize, b})
+	}
+
+	var deletes []uint64
+	s.subsMtx.RLock()
+	for id, sub := range s.subs[mktName] {
+		err := sub.conn.SendRaw(spotB)
+		if err == nil {
+			err = sub.conn.SendRaw(epochB)
+		}
+		for _, c := range candleBs {
+			if err != nil {
+				break
+			}
+			if sub.binSizes[c.binSize] {
+				err = sub.conn.SendRaw(c.b)
+			}
+		}
+		if err != nil {
+			deletes = append(deletes, id)
+		}
+	}
+	s.subsMtx.RUnlock()
+
+	if len(deletes) > 0 {
+		s.subsMtx.Lock()
+		for _, id := range deletes {
+			delete(s.subs[mktName], id)
+		}
+		s.subsMtx.Unlock()
+	}
+	return nil
 }
 
 // handleSpots implements comms.HTTPHandler for the /spots endpoint.
@@ -247,6 +392,89 @@ func (s *DataAPI) handleCandles(thing any) (any, error) {
 	return cache.WireCandles(req.NumCandles), nil
 }
 
+// handleSubscribe handles a marketdata_subscribe request, subscribing the
+// connection to spot updates, epoch summaries, and the requested candle bin
+// sizes for a market. The response is the market's latest Spot, or null if no
+// epoch has been reported.
+func (s *DataAPI) handleSubscribe(conn comms.Link, msg *msgjson.Message) *msgjson.Error {
+	sub := new(msgjson.MarketDataSubscription)
+	if err := msg.Unmarshal(sub); err != nil {
+		return msgjson.NewError(msgjson.RPCParseError, "error parsing %s request", msg.Route)
+	}
+	mktName, err := dex.MarketName(sub.Base, sub.Quote)
+	if err != nil {
+		return msgjson.NewError(msgjson.UnknownMarket, "can't parse requested market")
+	}
+	subBins := make(map[uint64]bool, len(sub.BinSizes))
+	for _, binSizeStr := range sub.BinSizes {
+		binSizeDuration, err := time.ParseDuration(binSizeStr)
+		if err != nil {
+			return msgjson.NewError(msgjson.RPCParseError, "error parsing binSize %q", binSizeStr)
+		}
+		binSize := uint64(binSizeDuration / time.Millisecond)
+		if _, found := binSizeNames[binSize]; !found {
+			return msgjson.NewError(msgjson.RPCParseError, "unsupported binSize %q", binSizeStr)
+		}
+		subBins[binSize] = true
+	}
+
+	s.cacheMtx.RLock()
+	_, found := s.marketCaches[mktName]
+	s.cacheMtx.RUnlock()
+	if !found {
+		return msgjson.NewError(msgjson.UnknownMarket, "unknown market %s", mktName)
+	}
+
+	s.subsMtx.Lock()
+	mktSubs := s.subs[mktName]
+	if mktSubs == nil {
+		mktSubs = make(map[uint64]*dataSubscriber)
+		s.subs[mktName] = mktSubs
+	}
+	mktSubs[conn.ID()] = &dataSubscriber{conn: conn, binSizes: subBins}
+	s.subsMtx.Unlock()
+
+	s.spotsMtx.RLock()
+	spot := s.spots[mktName]
+	s.spotsMtx.RUnlock()
+
+	resp, err := msgjson.NewResponse(msg.ID, spot, nil)
+	if err != nil {
+		return msgjson.NewError(msgjson.RPCInternal, "error encoding response")
+	}
+	// A send error means the link is going down, and the subscription will be
+	// removed with the next failed notification.
+	_ = conn.Send(resp)
+	return nil
+}
+
+// handleUnsubscribe handles a marketdata_unsubscribe request.
+func (s *DataAPI) handleUnsubscribe(conn comms.Link, msg *msgjson.Message) *msgjson.Error {
+	unsub := new(msgjson.MarketDataSubscription)
+	if err := msg.Unmarshal(unsub); err != nil {
+		return msgjson.NewError(msgjson.RPCParseError, "error parsing %s request", msg.Route)
+	}
+	mktName, err := dex.MarketName(unsub.Base, unsub.Quote)
+	if err != nil {
+		return msgjson.NewError(msgjson.UnknownMarket, "can't parse requested market")
+	}
+
+	s.subsMtx.Lock()
+	_, found := s.subs[mktName][conn.ID()]
+	delete(s.subs[mktName], conn.ID())
+	s.subsMtx.Unlock()
+	if !found {
+		return msgjson.NewError(msgjson.NotSubscribedError, "not subscribed to %s", mktName)
+	}
+
+	resp, err := msgjson.NewResponse(msg.ID, true, nil)
+	if err != nil {
+		return msgjson.NewError(msgjson.RPCInternal, "error encoding response")
+	}
+	_ = conn.Send(resp)
+	return nil
+}
+
 // handleOrderBook implements comms.HTTPHandler for the /orderbook endpoints.
 func (s *DataAPI) handleOrderBook(thing any) (any, error) {
 	req, ok := thing.(*msgjson.OrderBookSubscription)
@@ -268,5 +496,6 @@ func init() {
 			panic("error parsing bin size '" + s + "': " + err.Error())
 		}
 		binSizes = append(binSizes, uint64(dur/time.Millisecond))
+		binSizeNames[uint64(dur/time.Millisecond)] = s
 	}
 }
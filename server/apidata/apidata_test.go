@@ -49,6 +49,32 @@ func (bs *TBookSource) Book(mktName string) (*msgjson.OrderBook, error) {
 	return bs.book, nil
 }
 
+type TLink struct {
+	comms.Link
+	id      uint64
+	sent    []*msgjson.Message
+	sendErr error
+}
+
+func (l *TLink) ID() uint64 { return l.id }
+
+func (l *TLink) Send(msg *msgjson.Message) error {
+	l.sent = append(l.sent, msg)
+	return nil
+}
+
+func (l *TLink) SendRaw(b []byte) error {
+	if l.sendErr != nil {
+		return l.sendErr
+	}
+	msg, err := msgjson.DecodeMessage(b)
+	if err != nil {
+		return err
+	}
+	l.sent = append(l.sent, msg)
+	return nil
+}
+
 type testRig struct {
 	db  *TDBSource
 	api *DataAPI
@@ -57,8 +83,9 @@ type testRig struct {
 func newTestRig() *testRig {
 	db := new(TDBSource)
 	return &testRig{
-		db:  db,
-		api: NewDataAPI(db, func(route string, handler comms.HTTPHandler) {}),
+		db: db,
+		api: NewDataAPI(db, func(route string, handler comms.HTTPHandler) {},
+			func(route string, handler comms.MsgHandler) {}),
 	}
 }
 
@@ -88,6 +115,29 @@ func TestAddMarketSource(t *testing.T) {
 	}
 }
 
+func TestRemoveMarketSource(t *testing.T) {
+	rig := newTestRig()
+	src := &TMarketSource{42, 0}
+	if err := rig.api.AddMarketSource(src); err != nil {
+		t.Fatalf("AddMarketSource error: %v", err)
+	}
+	if _, err := rig.api.ReportEpoch(42, 0, 1, &matcher.MatchCycleStats{}); err != nil {
+		t.Fatalf("ReportEpoch error: %v", err)
+	}
+	if err := rig.api.RemoveMarketSource(src); err != nil {
+		t.Fatalf("RemoveMarketSource error: %v", err)
+	}
+	if len(rig.api.spots) != 0 {
+		t.Fatalf("spot not removed")
+	}
+	if _, err := rig.api.ReportEpoch(42, 0, 2, &matcher.MatchCycleStats{}); err == nil {
+		t.Fatalf("no error for removed market")
+	}
+	if err := rig.api.RemoveMarketSource(&TMarketSource{42, 54321}); err == nil {
+		t.Fatalf("no error for unknown asset")
+	}
+}
+
 func TestReportEpoch(t *testing.T) {
 	rig := newTestRig()
 	mktSrc := &TMarketSource{42, 0}
@@ -195,3 +245,113 @@ func TestOrderBook(t *testing.T) {
 		t.Fatalf("where did this book come from?")
 	}
 }
+
+func TestMarketDataSubscriptions(t *testing.T) {
+	rig := newTestRig()
+	mktSrc := &TMarketSource{42, 0}
+	if err := rig.api.AddMarketSource(mktSrc); err != nil {
+		t.Fatalf("AddMarketSource error: %v", err)
+	}
+
+	subscribe := func(link *TLink, sub *msgjson.MarketDataSubscription) *msgjson.Error {
+		t.Helper()
+		msg, _ := msgjson.NewRequest(1, msgjson.MarketDataSubscribeRoute, sub)
+		return rig.api.handleSubscribe(link, msg)
+	}
+	unsubscribe := func(link *TLink) *msgjson.Error {
+		t.Helper()
+		msg, _ := msgjson.NewRequest(2, msgjson.MarketDataUnsubscribeRoute, &msgjson.MarketDataSubscription{Base: 42})
+		return rig.api.handleUnsubscribe(link, msg)
+	}
+
+	// Unknown market.
+	if msgErr := subscribe(&TLink{id: 1}, &msgjson.MarketDataSubscription{Base: 42, Quote: 2}); msgErr == nil || msgErr.Code != msgjson.UnknownMarket {
+		t.Fatalf("wrong error for unknown market: %v", msgErr)
+	}
+	// Unsupported bin size.
+	if msgErr := subscribe(&TLink{id: 1}, &msgjson.MarketDataSubscription{Base: 42, BinSizes: []string{"7m"}}); msgErr == nil {
+		t.Fatalf("no error for unsupported bin size")
+	}
+
+	candleSub := &TLink{id: 1}
+	if msgErr := subscribe(candleSub, &msgjson.MarketDataSubscription{Base: 42, BinSizes: []string{"5m"}}); msgErr != nil {
+		t.Fatalf("subscribe error: %v", msgErr)
+	}
+	spotSub := &TLink{id: 2}
+	if msgErr := subscribe(spotSub, &msgjson.MarketDataSubscription{Base: 42}); msgErr != nil {
+		t.Fatalf("subscribe error: %v", msgErr)
+	}
+	// The response is the null spot, since no epochs have been reported.
+	if len(spotSub.sent) != 1 || string(spotSub.sent[0].Payload) != `{"result":null}` {
+		t.Fatalf("wrong subscribe response %v", spotSub.sent)
+	}
+	candleSub.sent, spotSub.sent = nil, nil
+
+	// An epoch from yesterday completes a candle for every bin size.
+	epoch := uint64(time.Now().UnixMilli()) / mktSrc.EpochDuration()
+	epochsPerDay := uint64(time.Hour*24/time.Millisecond) / mktSrc.EpochDuration()
+	stats := &matcher.MatchCycleStats{
+		MatchVolume: 123,
+		BookBuys:    5,
+		EndRate:     5,
+	}
+	if _, err := rig.api.ReportEpoch(42, 0, epoch-epochsPerDay-1, stats); err != nil {
+		t.Fatalf("ReportEpoch error: %v", err)
+	}
+
+	checkRoutes := func(link *TLink, routes ...string) {
+		t.Helper()
+		if len(link.sent) != len(routes) {
+			t.Fatalf("expected %d notifications, got %d", len(routes), len(link.sent))
+		}
+		for i, msg := range link.sent {
+			if msg.Route != routes[i] {
+				t.Fatalf("wrong route for notification %d. wanted %s, got %s", i, routes[i], msg.Route)
+			}
+		}
+	}
+	checkRoutes(candleSub, msgjson.SpotUpdateRoute, msgjson.EpochSummaryRoute, msgjson.CandleUpdateRoute)
+	checkRoutes(spotSub, msgjson.SpotUpdateRoute, msgjson.EpochSummaryRoute)
+
+	summary := new(msgjson.EpochSummary)
+	if err := spotSub.sent[1].Unmarshal(summary); err != nil {
+		t.Fatalf("error decoding epoch summary: %v", err)
+	}
+	if summary.MarketID != "dcr_btc" || summary.Epoch != epoch-epochsPerDay-1 || summary.MatchVolume != 123 || summary.BookBuys != 5 {
+		t.Fatalf("wrong epoch summary %+v", summary)
+	}
+	candleUpdate := new(msgjson.CandleUpdate)
+	if err := candleSub.sent[2].Unmarshal(candleUpdate); err != nil {
+		t.Fatalf("error decoding candle update: %v", err)
+	}
+	if candleUpdate.BinSize != "5m" || candleUpdate.MatchVolume != 123 {
+		t.Fatalf("wrong candle update %+v", candleUpdate)
+	}
+
+	// The current epoch doesn't complete any candles. A subscriber that can't
+	// be sent to is removed.
+	candleSub.sent, spotSub.sent = nil, nil
+	spotSub.sendErr = dummyErr
+	if _, err := rig.api.ReportEpoch(42, 0, epoch, stats); err != nil {
+		t.Fatalf("ReportEpoch error: %v", err)
+	}
+	checkRoutes(candleSub, msgjson.SpotUpdateRoute, msgjson.EpochSummaryRoute)
+	if msgErr := unsubscribe(spotSub); msgErr == nil || msgErr.Code != msgjson.NotSubscribedError {
+		t.Fatalf("wrong error for removed subscriber: %v", msgErr)
+	}
+
+	// Unsubscribe.
+	candleSub.sent = nil
+	if msgErr := unsubscribe(candleSub); msgErr != nil {
+		t.Fatalf("unsubscribe error: %v", msgErr)
+	}
+	if msgErr := unsubscribe(candleSub); msgErr == nil || msgErr.Code != msgjson.NotSubscribedError {
+		t.Fatalf("wrong error for second unsubscribe: %v", msgErr)
+	}
+	if _, err := rig.api.ReportEpoch(42, 0, epoch+1, stats); err != nil {
+		t.Fatalf("ReportEpoch error: %v", err)
+	}
+	if len(candleSub.sent) != 1 { // just the unsubscribe response
+		t.Fatalf("notifications sent after unsubscribe")
+	}
+}
@@ -520,6 +520,36 @@ func (btc *Backend) Contract(coinID []byte, redeemScript []byte) (*asset.Contrac
 	return btc.auditContract(output)
 }
 
+// PrivateSwapCoin is part of the asset.PrivateSwapper interface. A private
+// swap output is a taproot output that commits to the swap parties' keys and
+// the lock time, so the expected pkScript is rebuilt from the contract.
+func (btc *Backend) PrivateSwapCoin(coinID []byte, contract *asset.PrivateContract) (asset.Coin, error) {
+	txHash, vout, err := decodeCoinID(coinID)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding coin ID %x: %w", coinID, err)
+	}
+	redeemPubKey, _, err := dexbtc.DecodePrivateSwapPubKey(contract.RedeemKey)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding redeem key: %w", err)
+	}
+	refundPubKey, _, err := dexbtc.DecodePrivateSwapPubKey(contract.RefundKey)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding refund key: %w", err)
+	}
+	tree, err := dexbtc.NewPrivateSwapTree(redeemPubKey, refundPubKey, contract.LockTime.Unix())
+	if err != nil {
+		return nil, err
+	}
+	output, err := btc.privateOutput(txHash, vout)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(output.pkScript, tree.PkScript) {
+		return nil, fmt.Errorf("private swap output %s pays to the wrong script", output)
+	}
+	return output, nil
+}
+
 // ValidateSecret checks that the secret satisfies the contract.
 func (btc *Backend) ValidateSecret(secret, contract []byte) bool {
 	_, _, _, secretHash, err := dexbtc.ExtractSwapDetails(contract, btc.segwit, btc.chainParams)
@@ -850,6 +880,15 @@ func (btc *Backend) CheckSwapAddress(addr string) bool {
 	return true
 }
 
+// SendRawTransaction broadcasts a raw transaction, returning a coin ID.
+func (btc *Backend) SendRawTransaction(rawTx []byte) (coinID []byte, err error) {
+	txHash, err := btc.node.SendRawTransaction(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	return toCoinID(txHash, 0), nil
+}
+
 // TxData is the raw transaction bytes. SPV clients rebroadcast the transaction
 // bytes to get around not having a mempool to check.
 func (btc *Backend) TxData(coinID []byte) ([]byte, error) {
@@ -1062,6 +1101,29 @@ func (btc *Backend) output(txHash *chainhash.Hash, vout uint32, redeemScript []b
 	}, nil
 }
 
+// privateOutput gets a private swap output. Unlike output, the pkScript is
+// not required to be a standard script type.
+func (btc *Backend) privateOutput(txHash *chainhash.Hash, vout uint32) (*Output, error) {
+	txio, confs, err := btc.newTXIO(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if int(vout) >= len(txio.tx.outs) {
+		return nil, fmt.Errorf("tx %v has %d outputs (no vout %d)", txHash, len(txio.tx.outs), vout)
+	}
+	if confs < int64(txio.maturity) {
+		return nil, immatureTransactionError
+	}
+	txOut := txio.tx.outs[vout]
+	return &Output{
+		TXIO:     *txio,
+		vout:     vout,
+		value:    txOut.value,
+		pkScript: txOut.pkScript,
+		private:  true,
+	}, nil
+}
+
 // Get the value of the previous outpoint.
 func (btc *Backend) prevOutputValue(txid string, vout int) (uint64, error) {
 	txHash, err := chainhash.NewHashFromStr(txid)
@@ -395,6 +395,14 @@ func (t *testNode) RawRequest(_ context.Context, method string, params []json.Ra
 			Blocks:  2,
 			FeeRate: &optimalRate,
 		})
+	case methodSendRawTx:
+		var txHex string
+		mustUnmarshal(params[0], &txHex)
+		rawTx, err := hex.DecodeString(txHex)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(chainhash.DoubleHashH(rawTx).String())
 	case methodGetBlockchainInfo:
 		if t.rawErr != nil {
 			return nil, t.rawErr
@@ -1412,6 +1420,21 @@ func TestAuxiliary(t *testing.T) {
 	}
 }
 
+func TestSendRawTransaction(t *testing.T) {
+	btc, shutdown := testBackend(false)
+	defer shutdown()
+
+	rawTx := randomBytes(100)
+	coinID, err := btc.SendRawTransaction(rawTx)
+	if err != nil {
+		t.Fatalf("SendRawTransaction error: %v", err)
+	}
+	txHash := chainhash.DoubleHashH(rawTx)
+	if !bytes.Equal(coinID, toCoinID(&txHash, 0)) {
+		t.Fatalf("wrong coin ID %x", coinID)
+	}
+}
+
 // TestCheckSwapAddress checks that addresses are parsing or not parsing as
 // expected.
 func TestCheckSwapAddress(t *testing.T) {
@@ -30,6 +30,7 @@ const (
 	methodGetBlockHeader    = "getblockheader"
 	methodGetBlockStats     = "getblockstats"
 	methodGetBlockHash      = "getblockhash"
+	methodSendRawTx         = "sendrawtransaction"
 
 	errNoCompetition = dex.ErrorKind("no competition")
 	errNoFeeRate     = dex.ErrorKind("fee rate could not be estimated")
@@ -211,6 +212,12 @@ func (rc *RPCClient) GetRawTransaction(txHash *chainhash.Hash) ([]byte, error) {
 	return txB, nil
 }
 
+// SendRawTransaction broadcasts a serialized transaction, returning its
+// hash.
+func (rc *RPCClient) SendRawTransaction(rawTx []byte) (*chainhash.Hash, error) {
+	return rc.callHashGetter(methodSendRawTx, anylist{dex.Bytes(rawTx).String()})
+}
+
 // GetRawTransactionVerbose retrieves the verbose tx information.
 func (rc *RPCClient) GetRawTransactionVerbose(txHash *chainhash.Hash) (*VerboseTxExtended, error) {
 	args := anylist{txHash.String(), true}
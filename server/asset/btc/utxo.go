@@ -182,6 +182,8 @@ type Output struct {
 	// spendSize stores the best estimate of the size (bytes) of the serialized
 	// transaction input that spends this Output.
 	spendSize uint32
+	// private is true for a private swap output, whose script is not parsed.
+	private bool
 }
 
 // Confirmations returns the number of confirmations on this output's
@@ -189,7 +191,12 @@ type Output struct {
 func (output *Output) Confirmations(context.Context) (int64, error) {
 	confs, err := output.confirmations()
 	if errors.Is(err, ErrReorgDetected) {
-		newOut, err := output.btc.output(&output.tx.hash, output.vout, output.redeemScript)
+		var newOut *Output
+		if output.private {
+			newOut, err = output.btc.privateOutput(&output.tx.hash, output.vout)
+		} else {
+			newOut, err = output.btc.output(&output.tx.hash, output.vout, output.redeemScript)
+		}
 		if err != nil {
 			return -1, fmt.Errorf("output block is not mainchain")
 		}
@@ -109,6 +109,27 @@ type TokenBacker interface {
 	TokenBackend(assetID uint32, configPath string) (Backend, error)
 }
 
+// PrivateSwapper is implemented by backends for blockchains that support
+// adaptor signature swaps. The swap output of a private swap does not reveal a
+// swap contract, so its script is rebuilt from the keys exchanged by the swap
+// parties and compared. Redemptions of private swaps are located with
+// Backend.Redemption.
+type PrivateSwapper interface {
+	// PrivateSwapCoin locates a private swap output and checks that it pays
+	// to the private contract.
+	PrivateSwapCoin(coinID []byte, contract *PrivateContract) (Coin, error)
+}
+
+// PrivateContract is the data committed to by a private swap output.
+type PrivateContract struct {
+	// RedeemKey is the counterparty's redeem key.
+	RedeemKey []byte
+	// RefundKey is the swapper's refund key.
+	RefundKey []byte
+	// LockTime is the time after which the swap can be refunded.
+	LockTime time.Time
+}
+
 // Coin represents a transaction input or output.
 type Coin interface {
 	// Confirmations returns the number of confirmations for a Coin's
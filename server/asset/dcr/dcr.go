@@ -485,6 +485,29 @@ func (dcr *Backend) Contract(coinID []byte, redeemScript []byte) (*asset.Contrac
 	return auditContract(op)
 }
 
+// PrivateSwapCoin is part of the asset.PrivateSwapper interface. The output's
+// P2SH script hash commits to the swap parties' keys and the lock time, so the
+// expected pkScript is rebuilt from the contract.
+func (dcr *Backend) PrivateSwapCoin(coinID []byte, contract *asset.PrivateContract) (asset.Coin, error) {
+	txHash, vout, err := decodeCoinID(coinID)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding coin ID %x: %w", coinID, err)
+	}
+	_, pkScript, err := dexdcr.PrivateContractPkScript(contract.RedeemKey, contract.RefundKey,
+		contract.LockTime.Unix(), chainParams)
+	if err != nil {
+		return nil, err
+	}
+	output, err := dcr.privateOutput(txHash, vout)
+	if err != nil {
+		return nil, err
+	}
+	if output.scriptVersion != 0 || !bytes.Equal(output.pkScript, pkScript) {
+		return nil, fmt.Errorf("private swap output %s pays to the wrong script", output)
+	}
+	return output, nil
+}
+
 // ValidateSecret checks that the secret satisfies the contract.
 func (dcr *Backend) ValidateSecret(secret, contract []byte) bool {
 	_, _, _, secretHash, err := dexdcr.ExtractSwapDetails(contract, chainParams)
@@ -1204,6 +1227,35 @@ func (dcr *Backend) output(txHash *chainhash.Hash, vout uint32, redeemScript []b
 	}, nil
 }
 
+// privateOutput gets a private swap output. Unlike output, no redeem script is
+// required for the P2SH pkScript.
+func (dcr *Backend) privateOutput(txHash *chainhash.Hash, vout uint32) (*Output, error) {
+	txio, confs, err := dcr.newTXIO(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if int(vout) >= len(txio.tx.outs) {
+		return nil, fmt.Errorf("tx %v has %d outputs (no vout %d)", txHash, len(txio.tx.outs), vout)
+	}
+	var maturity int64
+	if txio.tx.isCoinbase {
+		maturity = int64(chainParams.CoinbaseMaturity)
+	}
+	if confs < maturity {
+		return nil, immatureTransactionError
+	}
+	txio.maturity = int32(maturity)
+	txOut := txio.tx.outs[vout]
+	return &Output{
+		TXIO:          *txio,
+		vout:          vout,
+		value:         txOut.value,
+		scriptVersion: txOut.version,
+		pkScript:      txOut.pkScript,
+		private:       true,
+	}, nil
+}
+
 // MsgTxFromHex creates a wire.MsgTx by deserializing the hex transaction.
 func msgTxFromHex(txhex string) (*wire.MsgTx, error) {
 	msgTx := wire.NewMsgTx()
@@ -193,6 +193,8 @@ type Output struct {
 	// spendSize stores the best estimate of the size (bytes) of the serialized
 	// transaction input that spends this Output.
 	spendSize uint32
+	// private is true for a private swap output, whose script is not parsed.
+	private bool
 }
 
 // Confirmations returns the number of confirmations for a transaction output.
@@ -205,7 +207,12 @@ type Output struct {
 func (output *Output) Confirmations(ctx context.Context) (int64, error) {
 	confs, err := output.confirmations(ctx, false)
 	if errors.Is(err, ErrReorgDetected) {
-		newOut, err := output.dcr.output(&output.tx.hash, output.vout, output.redeemScript)
+		var newOut *Output
+		if output.private {
+			newOut, err = output.dcr.privateOutput(&output.tx.hash, output.vout)
+		} else {
+			newOut, err = output.dcr.output(&output.tx.hash, output.vout, output.redeemScript)
+		}
 		if err != nil {
 			if !errors.Is(err, asset.ErrRequestTimeout) {
 				err = fmt.Errorf("output block is not mainchain")
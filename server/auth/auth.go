@@ -131,6 +131,7 @@ type clientInfo struct {
 	tier         int64
 	score        int32
 	bonds        []*db.Bond // only confirmed and active, not pending
+	online       bool       // counted in the connected user metrics
 }
 
 // not thread-safe
@@ -817,8 +818,7 @@ func (auth *AuthManager) computeUserReputation(user account.AccountID, score int
 	wasScore := client.score
 	bondTier := client.bondTier()
 	r = auth.userReputation(bondTier, score)
-	client.tier = r.EffectiveTier()
-	client.score = score
+	client.setReputation(r.EffectiveTier(), score)
 	scoreChanged = wasScore != score
 	tierChanged = wasTier != client.tier
 
@@ -1193,8 +1193,7 @@ func (auth *AuthManager) checkBonds() {
 		score := auth.userScore(client.acct.ID)
 		auth.violationMtx.Unlock()
 
-		client.tier = auth.tier(bondTier, score)
-		client.score = score
+		client.setReputation(auth.tier(bondTier, score), score)
 
 		return pruned, auth.userReputation(bondTier, score)
 	}
@@ -1253,8 +1252,7 @@ func (auth *AuthManager) addBond(user account.AccountID, bond *db.Bond) *account
 
 	bondTier := client.addBond(bond)
 	rep := auth.userReputation(bondTier, score)
-	client.tier = rep.EffectiveTier()
-	client.score = score
+	client.setReputation(rep.EffectiveTier(), score)
 
 	return rep
 }
@@ -1278,6 +1276,15 @@ func (auth *AuthManager) addClient(client *clientInfo) {
 	connID := client.conn.ID()
 	auth.conns[connID] = client
 
+	if oldClient != nil {
+		oldClient.mtx.Lock()
+		oldClient.setOnline(false)
+		oldClient.mtx.Unlock()
+	}
+	client.mtx.Lock()
+	client.setOnline(true)
+	client.mtx.Unlock()
+
 	// Now that the new conn ID is registered, disconnect any existing old link
 	// unless it is the same.
 	if oldClient != nil {
@@ -1317,6 +1324,9 @@ func (auth *AuthManager) removeClient(client *clientInfo) {
 	user := client.acct.ID
 	delete(auth.users, user)
 	delete(auth.conns, connID)
+	client.mtx.Lock()
+	client.setOnline(false)
+	client.mtx.Unlock()
 	client.conn.Disconnect() // in case not triggered by disconnect
 	auth.unbookers[user] = time.AfterFunc(auth.miaUserTimeout, func() { auth.unbookUserOrders(user) })
 
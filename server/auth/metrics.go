@@ -0,0 +1,61 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package auth
+
+import (
+	"strconv"
+
+	"decred.org/dcrdex/server/metrics"
+)
+
+// scoreBucketSize is the width of the score ranges used to label
+// connectedByScore.
+const scoreBucketSize = 10
+
+var (
+	connectedByTier = metrics.NewGaugeVec("dcrdex_auth_connected_users_by_tier",
+		"Number of connected users, by effective tier.", "tier")
+	connectedByScore = metrics.NewGaugeVec("dcrdex_auth_connected_users_by_score",
+		"Number of connected users, by conduct score range. The label is the "+
+			"lower bound of a range of width 10.", "score")
+)
+
+// scoreBucket returns the connectedByScore label for the score.
+func scoreBucket(score int32) string {
+	b := score / scoreBucketSize
+	if score < 0 && score%scoreBucketSize != 0 {
+		b-- // round toward negative infinity
+	}
+	return strconv.Itoa(int(b * scoreBucketSize))
+}
+
+func recordReputation(tier int64, score int32, delta float64) {
+	connectedByTier.With(strconv.FormatInt(tier, 10)).Add(delta)
+	connectedByScore.With(scoreBucket(score)).Add(delta)
+}
+
+// setOnline marks the client as connected or not, updating the reputation
+// gauges. The clientInfo.mtx must be held.
+func (client *clientInfo) setOnline(online bool) {
+	if client.online == online {
+		return
+	}
+	client.online = online
+	if online {
+		recordReputation(client.tier, client.score, 1)
+	} else {
+		recordReputation(client.tier, client.score, -1)
+	}
+}
+
+// setReputation sets the client's tier and score, updating the reputation
+// gauges if the client is connected. The clientInfo.mtx must be held.
+func (client *clientInfo) setReputation(tier int64, score int32) {
+	if client.online {
+		recordReputation(client.tier, client.score, -1)
+		recordReputation(tier, score, 1)
+	}
+	client.tier = tier
+	client.score = score
+}
@@ -56,9 +56,20 @@ func (b *Book) Clear() (removedBuys, removedSells []*order.LimitOrder) {
 
 // LotSize returns the Book's configured lot size in atoms of the base asset.
 func (b *Book) LotSize() uint64 {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
 	return b.lotSize
 }
 
+// SetLotSize changes the Book's lot size. Orders already on the book are not
+// checked against the new lot size, so the caller should first remove any
+// orders with incompatible quantities.
+func (b *Book) SetLotSize(lotSize uint64) {
+	b.mtx.Lock()
+	b.lotSize = lotSize
+	b.mtx.Unlock()
+}
+
 // BuyCount returns the number of buy orders.
 func (b *Book) BuyCount() int {
 	return b.buys.Count()
@@ -69,6 +80,18 @@ func (b *Book) SellCount() int {
 	return b.sells.Count()
 }
 
+// BuyQuantity returns the total remaining quantity of the buy orders, in units
+// of the base asset.
+func (b *Book) BuyQuantity() uint64 {
+	return b.buys.RemainingQuantity()
+}
+
+// SellQuantity returns the total remaining quantity of the sell orders, in
+// units of the base asset.
+func (b *Book) SellQuantity() uint64 {
+	return b.sells.RemainingQuantity()
+}
+
 // BestSell returns a pointer to the best sell order in the order book. The
 // order is NOT removed from the book.
 func (b *Book) BestSell() *order.LimitOrder {
@@ -95,12 +118,12 @@ func (b *Book) Best() (bestBuy, bestSell *order.LimitOrder) {
 // boolean indicating if the insertion was successful. If the order is not an
 // integer multiple of the Book's lot size, the order will not be inserted.
 func (b *Book) Insert(o *order.LimitOrder) bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
 	if o.Quantity%b.lotSize != 0 {
 		log.Warnf("(*Book).Insert: Refusing to insert an order with a quantity that is not a multiple of lot size.")
 		return false
 	}
-	b.mtx.Lock()
-	defer b.mtx.Unlock()
 	if o.Sell {
 		if b.sells.Insert(o) {
 			b.acctTracker.add(o)
@@ -193,6 +216,18 @@ func (b *Book) BuyOrdersN(N int) []*order.LimitOrder {
 	return b.buys.OrdersN(N)
 }
 
+// IterateSells calls f for each sell order in the book, best first, until f
+// returns false.
+func (b *Book) IterateSells(f func(*order.LimitOrder) bool) {
+	b.sells.IterateBest(f)
+}
+
+// IterateBuys calls f for each buy order in the book, best first, until f
+// returns false.
+func (b *Book) IterateBuys(f func(*order.LimitOrder) bool) {
+	b.buys.IterateBest(f)
+}
+
 // UnfilledUserBuys retrieves all buy orders belonging to a given user that are
 // completely unfilled.
 func (b *Book) UnfilledUserBuys(user account.AccountID) []*order.LimitOrder {
@@ -140,6 +140,20 @@ func TestBook(t *testing.T) {
 			b.BestSell().ID(), bestSellOrder.ID())
 	}
 
+	var buyQty, sellQty uint64
+	for _, lo := range bookBuyOrders {
+		buyQty += lo.Remaining()
+	}
+	for _, lo := range bookSellOrders {
+		sellQty += lo.Remaining()
+	}
+	if b.BuyQuantity() != buyQty {
+		t.Errorf("Incorrect buy quantity. Got %d, expected %d", b.BuyQuantity(), buyQty)
+	}
+	if b.SellQuantity() != sellQty {
+		t.Errorf("Incorrect sell quantity. Got %d, expected %d", b.SellQuantity(), sellQty)
+	}
+
 	sells := b.SellOrders()
 	if len(sells) != b.SellCount() {
 		t.Errorf("Incorrect number of sell orders. Got %d, expected %d",
@@ -332,3 +346,29 @@ func TestAccountTracking(t *testing.T) {
 		t.Fatalf("quote asset not cleared")
 	}
 }
+
+func TestSetLotSize(t *testing.T) {
+	b := New(LotSize, AccountTrackingBase)
+
+	lo := newLimitOrder(false, 2500000, 3, order.StandingTiF, 0)
+	if !b.Insert(lo) {
+		t.Fatalf("Failed to insert order %v", lo)
+	}
+
+	b.SetLotSize(2 * LotSize)
+	if b.LotSize() != 2*LotSize {
+		t.Fatalf("wrong lot size %d", b.LotSize())
+	}
+
+	// An order of 3 old lots is not a multiple of the new lot size.
+	if b.Insert(newLimitOrder(false, 2600000, 3, order.StandingTiF, 0)) {
+		t.Fatalf("inserted an order with an incompatible quantity")
+	}
+	if !b.Insert(newLimitOrder(false, 2600000, 4, order.StandingTiF, 0)) {
+		t.Fatalf("failed to insert an order with a compatible quantity")
+	}
+	// Orders already on the book are left alone.
+	if !b.HaveOrder(lo.ID()) {
+		t.Fatalf("existing order removed")
+	}
+}
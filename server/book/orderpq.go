@@ -149,6 +149,53 @@ func (pq *OrderPQ) OrdersN(count int) []*order.LimitOrder {
 	return tmp.ExtractN(count)
 }
 
+// IterateBest calls f for each order in priority order, starting with the best,
+// until f returns false. The OrderPQ is unmodified. Only the visited orders and
+// their heap children are examined, so a walk that stops early does not pay
+// for sorting the whole queue.
+func (pq *OrderPQ) IterateBest(f func(*order.LimitOrder) bool) {
+	pq.mtx.RLock()
+	defer pq.mtx.RUnlock()
+	if len(pq.oh) == 0 {
+		return
+	}
+	// The next best order is always the root or a child of an order already
+	// visited, so a frontier of heap indexes ordered by lessFn yields the
+	// orders best-first.
+	frontier := &heapIdxFrontier{pq: pq, idxs: []int{0}}
+	for frontier.Len() > 0 {
+		i := heap.Pop(frontier).(int)
+		if !f(pq.oh[i].order) {
+			return
+		}
+		for _, c := range []int{2*i + 1, 2*i + 2} {
+			if c < len(pq.oh) {
+				heap.Push(frontier, c)
+			}
+		}
+	}
+}
+
+// heapIdxFrontier is a heap.Interface of indexes into an OrderPQ's heap, used
+// by IterateBest. The OrderPQ must be locked while it is in use.
+type heapIdxFrontier struct {
+	pq   *OrderPQ
+	idxs []int
+}
+
+func (h *heapIdxFrontier) Len() int { return len(h.idxs) }
+func (h *heapIdxFrontier) Less(i, j int) bool {
+	return h.pq.lessFn(h.pq.oh[h.idxs[i]].order, h.pq.oh[h.idxs[j]].order)
+}
+func (h *heapIdxFrontier) Swap(i, j int) { h.idxs[i], h.idxs[j] = h.idxs[j], h.idxs[i] }
+func (h *heapIdxFrontier) Push(x any)    { h.idxs = append(h.idxs, x.(int)) }
+func (h *heapIdxFrontier) Pop() any {
+	n := len(h.idxs)
+	i := h.idxs[n-1]
+	h.idxs = h.idxs[:n-1]
+	return i
+}
+
 // ExtractN extracts the N best orders, sorted with the lessFn. ExtractBest is
 // called until the requested number of entries are extracted. Thus, the OrderPQ
 // is reduced in length by count, or the length of the heap, whichever is
@@ -505,6 +552,16 @@ func (pq *OrderPQ) UserOrderTotals(user account.AccountID) (amt, count uint64) {
 	return
 }
 
+// RemainingQuantity returns the total remaining quantity of the orders.
+func (pq *OrderPQ) RemainingQuantity() (qty uint64) {
+	pq.mtx.RLock()
+	defer pq.mtx.RUnlock()
+	for _, oe := range pq.oh {
+		qty += oe.order.Remaining()
+	}
+	return
+}
+
 // removeOrder removes the specified orderEntry from the queue. This function is
 // NOT thread-safe.
 func (pq *OrderPQ) removeOrder(o *orderEntry) (*order.LimitOrder, bool) {
@@ -311,6 +311,45 @@ func TestLargeOrderMaxPriorityQueue_Orders(t *testing.T) {
 	}
 }
 
+func TestLargeOrderMinPriorityQueue_IterateBest(t *testing.T) {
+	// Min oriented queue (buy book)
+	pq := NewMinOrderPQ(uint32(len(bigList)))
+	for _, o := range bigList {
+		if !pq.Insert(o) {
+			t.Fatalf("Failed to insert order %v", o)
+		}
+	}
+	ordersSorted := pq.Orders()
+
+	// A complete walk visits every order in sorted order.
+	var i int
+	pq.IterateBest(func(lo *order.LimitOrder) bool {
+		if lo.ID() != ordersSorted[i].ID() {
+			t.Fatalf("Order %d incorrect. Got %s, expected %s", i, lo.UID(), ordersSorted[i].UID())
+		}
+		i++
+		return true
+	})
+	if i != len(ordersSorted) {
+		t.Fatalf("visited %d orders, expected %d", i, len(ordersSorted))
+	}
+
+	// Stopping early visits only the best orders.
+	var visited int
+	pq.IterateBest(func(*order.LimitOrder) bool {
+		visited++
+		return visited < 6
+	})
+	if visited != 6 {
+		t.Fatalf("visited %d orders, expected 6", visited)
+	}
+
+	// The queue is unmodified.
+	if pq.Len() != len(bigList) || pq.PeekBest().ID() != ordersSorted[0].ID() {
+		t.Errorf("IterateBest modified the queue")
+	}
+}
+
 func TestLargeOrderMaxPriorityQueue_realloc(t *testing.T) {
 	// Max oriented queue (sell book)
 	pq := NewMaxOrderPQ(uint32(len(bigList))) // no realloc for initial inserts
@@ -145,8 +145,9 @@ func mainCore(ctx context.Context) error {
 			DisableDataAPI:    cfg.DisableDataAPI,
 			HiddenServiceAddr: cfg.HiddenService,
 		},
-		NoResumeSwaps: cfg.NoResumeSwaps,
-		NodeRelayAddr: cfg.NodeRelayAddr,
+		NoResumeSwaps:   cfg.NoResumeSwaps,
+		NodeRelayAddr:   cfg.NodeRelayAddr,
+		MarketsConfPath: cfg.MarketsConfPath,
 	}
 	dexMan, err := dexsrv.NewDEX(ctx, dexConf) // ctx cancel just aborts setup; Stop does normal shutdown
 	if err != nil {
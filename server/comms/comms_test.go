@@ -40,6 +40,7 @@ func newServer() *Server {
 		dataEnabled: 1,
 		rpcRoutes:   make(map[string]MsgHandler),
 		httpRoutes:  make(map[string]HTTPHandler),
+		dataRoutes:  make(map[string]bool),
 	}
 	for _, route := range []string{msgjson.ConfigRoute, msgjson.SpotsRoute, msgjson.CandlesRoute, msgjson.OrderBookRoute} {
 		s.RegisterHTTP(route, func(any) (any, error) { return nil, nil })
@@ -378,6 +379,12 @@ func TestClientRequests(t *testing.T) {
 		srvChan <- nil
 		return struct{}{}, nil
 	})
+	var dataSeen uint32
+	server.RouteData("dataroute", func(Link, *msgjson.Message) *msgjson.Error {
+		atomic.StoreUint32(&dataSeen, 1)
+		srvChan <- nil
+		return nil
+	})
 
 	// A helper function to reconnect to the server (new comm) and grab the
 	// server's link (new client).
@@ -490,6 +497,28 @@ func TestClientRequests(t *testing.T) {
 	}
 	conn.wait(t, "http route success")
 
+	// A websocket data route passes too.
+	sendToServer("dataroute", "{}")
+	readChannel(t, "dataroute", srvChan)
+	if !atomic.CompareAndSwapUint32(&dataSeen, 1, 0) {
+		t.Fatalf("data route not hit")
+	}
+
+	// As a notification too.
+	sendNtfnToServer := func(route string) {
+		t.Helper()
+		encMsg, err := json.Marshal(makeNtfn(route, "{}"))
+		if err != nil {
+			t.Fatalf("error encoding %s notification: %v", route, err)
+		}
+		conn.msg <- encMsg
+	}
+	sendNtfnToServer("dataroute")
+	readChannel(t, "dataroute notification", srvChan)
+	if !atomic.CompareAndSwapUint32(&dataSeen, 1, 0) {
+		t.Fatalf("data route not hit by notification")
+	}
+
 	// Disable HTTP non-critical HTTP routes and try again.
 	server.EnableDataAPI(false)
 	sendToServer("httproute", "{}")
@@ -501,6 +530,28 @@ func TestClientRequests(t *testing.T) {
 		t.Fatalf("disabled HTTP route hit")
 	}
 
+	// The data route is disabled with the data API.
+	sendToServer("dataroute", "{}")
+	resp = decodeResponse(t, <-conn.recv)
+	if resp.Error == nil || resp.Error.Code != msgjson.TooManyRequestsError {
+		t.Fatalf("no or incorrect error for disabled data route: %v", resp.Error)
+	}
+	if atomic.CompareAndSwapUint32(&dataSeen, 1, 0) {
+		t.Fatalf("disabled data route hit")
+	}
+
+	// Or as a notification. There is no response to a notification, but the
+	// attempt to send the error is received after the message is handled.
+	sendNtfnToServer("dataroute")
+	select {
+	case <-conn.recv:
+	case <-time.After(time.Second):
+		t.Fatalf("disabled data route notification not handled")
+	}
+	if atomic.CompareAndSwapUint32(&dataSeen, 1, 0) {
+		t.Fatalf("disabled data route hit by notification")
+	}
+
 	// Make the route a critical route
 	criticalRoutes["httproute"] = true
 	sendToServer("httproute", "{}")
@@ -927,6 +978,42 @@ func TestHTTPRateLimiter(t *testing.T) {
 	}
 }
 
+func TestRateLimitOffenders(t *testing.T) {
+	offendersMtx.Lock()
+	offenders = make(map[dex.IPKey]*RateLimitOffender)
+	offendersMtx.Unlock()
+
+	ipA, ipB := dex.NewIPKey("10.0.0.1"), dex.NewIPKey("10.0.0.2")
+	recordRejection(ipA, limiterIPHTTP)
+	recordRejection(ipA, limiterWSRoute)
+	recordRejection(ipB, limiterIPHTTP)
+
+	var s Server
+	top := s.RateLimitOffenders(1)
+	if len(top) != 1 {
+		t.Fatalf("expected 1 offender, got %d", len(top))
+	}
+	if top[0].IP != ipA.String() || top[0].Rejections != 2 || top[0].ByLimiter[limiterWSRoute] != 1 {
+		t.Fatalf("wrong top offender: %+v", top[0])
+	}
+
+	// The IP with the fewest rejections is dropped when the tracker is full.
+	for i := 0; len(s.RateLimitOffenders(-1)) < maxTrackedOffenders; i++ {
+		recordRejection(dex.NewIPKey(fmt.Sprintf("10.1.%d.%d", i/256, i%256)), limiterIPHTTP)
+		recordRejection(dex.NewIPKey(fmt.Sprintf("10.1.%d.%d", i/256, i%256)), limiterIPHTTP)
+	}
+	recordRejection(dex.NewIPKey("10.2.0.1"), limiterIPHTTP)
+	all := s.RateLimitOffenders(-1)
+	if len(all) != maxTrackedOffenders {
+		t.Fatalf("expected %d offenders, got %d", maxTrackedOffenders, len(all))
+	}
+	for _, o := range all {
+		if o.IP == ipB.String() {
+			t.Fatalf("offender with the fewest rejections not dropped")
+		}
+	}
+}
+
 func TestWSRateLimiter(t *testing.T) {
 	server := newServer()
 	var wg sync.WaitGroup
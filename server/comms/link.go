@@ -9,6 +9,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/msgjson"
 	"decred.org/dcrdex/dex/ws"
 )
@@ -83,18 +84,21 @@ type wsLink struct {
 	dataMeter func() (int, error)
 	// wsLimiter is a route-based rate limiter. This applies to rpcRoutes.
 	wsLimiter *routeLimiter
+	// ip is the client's IP address, used to label rate limit metrics.
+	ip dex.IPKey
 }
 
 // newWSLink is a constructor for a new wsLink.
-func (s *Server) newWSLink(addr string, conn ws.Connection, wsLimiter *routeLimiter, limitData func() (int, error)) *wsLink {
+func (s *Server) newWSLink(ip dex.IPKey, conn ws.Connection, wsLimiter *routeLimiter, limitData func() (int, error)) *wsLink {
 	var c *wsLink
 	c = &wsLink{
-		WSLink: ws.NewWSLink(addr, conn, pingPeriod, func(msg *msgjson.Message) *msgjson.Error {
+		WSLink: ws.NewWSLink(ip.String(), conn, pingPeriod, func(msg *msgjson.Message) *msgjson.Error {
 			return s.handleMessage(c, msg)
 		}, log.SubLogger("WS")),
 		respHandlers: make(map[uint64]*responseHandler),
 		dataMeter:    limitData,
 		wsLimiter:    wsLimiter,
+		ip:           ip,
 	}
 	return c
 }
@@ -147,8 +151,14 @@ func (s *Server) handleMessage(c *wsLink, msg *msgjson.Message) *msgjson.Error {
 		handler := s.rpcRoutes[msg.Route]
 		if handler != nil {
 			if !c.wsLimiter.allow(msg.Route) {
+				recordRejection(c.ip, limiterWSRoute)
 				return msgjson.NewError(msgjson.TooManyRequestsError, "too many requests to %s", msg.Route)
 			}
+			if s.dataRoutes[msg.Route] {
+				if _, err := c.dataMeter(); err != nil {
+					return msgjson.NewError(msgjson.TooManyRequestsError, "metered: %v", err)
+				}
+			}
 			// Handle the request.
 			return handler(c, msg)
 		}
@@ -206,8 +216,16 @@ func (s *Server) handleMessage(c *wsLink, msg *msgjson.Message) *msgjson.Error {
 		handler := s.rpcRoutes[msg.Route]
 		if handler != nil {
 			if !c.wsLimiter.allow(msg.Route) {
+				recordRejection(c.ip, limiterWSRoute)
 				return msgjson.NewError(msgjson.TooManyRequestsError, "too many requests to %s", msg.Route)
 			}
+			// Data routes are metered and disabled with the data API however
+			// they are called.
+			if s.dataRoutes[msg.Route] {
+				if _, err := c.dataMeter(); err != nil {
+					return msgjson.NewError(msgjson.TooManyRequestsError, "metered: %v", err)
+				}
+			}
 			// Handle the request.
 			return handler(c, msg)
 		}
@@ -0,0 +1,107 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package comms
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/metrics"
+)
+
+// Rate limiter labels for rateLimitRejections.
+const (
+	limiterGlobalHTTP = "global_http"
+	limiterIPHTTP     = "ip_http"
+	limiterWSRoute    = "ws_route"
+	limiterWSConns    = "ws_conns"
+)
+
+// maxTrackedOffenders is the number of client IPs for which rate limiter
+// rejections are counted. When full, the IP with the fewest rejections is
+// dropped to make room for a new one.
+const maxTrackedOffenders = 1000
+
+var (
+	wsConnections = metrics.NewGauge("dcrdex_comms_ws_connections",
+		"Number of connected websocket clients.")
+	wsConnectionsTotal = metrics.NewCounter("dcrdex_comms_ws_connections_total",
+		"Number of websocket client connections accepted.")
+	// Rejections are not labeled by source IP, since there would be a series
+	// for every client that was ever rate limited. The per-IP counts are
+	// available from Server.RateLimitOffenders instead.
+	rateLimitRejections = metrics.NewCounterVec("dcrdex_comms_rate_limit_rejections_total",
+		"Number of requests or connections rejected by a rate limiter, by limiter.",
+		"limiter")
+
+	offendersMtx sync.Mutex
+	offenders    = make(map[dex.IPKey]*RateLimitOffender)
+)
+
+// RateLimitOffender is the number of requests or connections from a client IP
+// that were rejected by the rate limiters.
+type RateLimitOffender struct {
+	IP            string            `json:"ip"`
+	Rejections    uint64            `json:"rejections"`
+	ByLimiter     map[string]uint64 `json:"byLimiter"`
+	LastRejection time.Time         `json:"lastRejection"`
+}
+
+// recordRejection increments the rejection count for the limiter and the
+// rejected IP.
+func recordRejection(ip dex.IPKey, limiter string) {
+	rateLimitRejections.With(limiter).Inc()
+
+	offendersMtx.Lock()
+	defer offendersMtx.Unlock()
+	o, found := offenders[ip]
+	if !found {
+		if len(offenders) >= maxTrackedOffenders {
+			var leastIP dex.IPKey
+			var least *RateLimitOffender
+			for ip, o := range offenders {
+				if least == nil || o.Rejections < least.Rejections ||
+					(o.Rejections == least.Rejections && o.LastRejection.Before(least.LastRejection)) {
+					leastIP, least = ip, o
+				}
+			}
+			delete(offenders, leastIP)
+		}
+		o = &RateLimitOffender{IP: ip.String(), ByLimiter: make(map[string]uint64, 1)}
+		offenders[ip] = o
+	}
+	o.Rejections++
+	o.ByLimiter[limiter]++
+	o.LastRejection = time.Now()
+}
+
+// RateLimitOffenders returns the n client IPs with the most rate limiter
+// rejections, most rejections first. Only the maxTrackedOffenders IPs with the
+// most rejections are counted.
+func (s *Server) RateLimitOffenders(n int) []*RateLimitOffender {
+	offendersMtx.Lock()
+	top := make([]*RateLimitOffender, 0, len(offenders))
+	for _, o := range offenders {
+		oCopy := *o
+		oCopy.ByLimiter = make(map[string]uint64, len(o.ByLimiter))
+		for limiter, count := range o.ByLimiter {
+			oCopy.ByLimiter[limiter] = count
+		}
+		top = append(top, &oCopy)
+	}
+	offendersMtx.Unlock()
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Rejections != top[j].Rejections {
+			return top[i].Rejections > top[j].Rejections
+		}
+		return top[i].LastRejection.After(top[j].LastRejection)
+	})
+	if n >= 0 && len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
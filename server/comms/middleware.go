@@ -41,10 +41,12 @@ func (s *Server) meterIP(ip dex.IPKey) (int, error) {
 		return http.StatusServiceUnavailable, fmt.Errorf("data API is disabled")
 	}
 	if !globalHTTPRateLimiter.Allow() {
+		recordRejection(ip, limiterGlobalHTTP)
 		return http.StatusTooManyRequests, fmt.Errorf("too many global requests")
 	}
 	ipLimiter := getIPLimiter(ip)
 	if !ipLimiter.Allow() {
+		recordRejection(ip, limiterIPHTTP)
 		return http.StatusTooManyRequests, fmt.Errorf("too many requests")
 	}
 	return 0, nil
@@ -137,6 +137,16 @@ func (s *Server) Route(route string, handler MsgHandler) {
 	s.rpcRoutes[route] = handler
 }
 
+// RouteData registers a handler for a websocket-only data API route, such as a
+// market data subscription. In addition to the route limits applied to all
+// websocket routes, requests to a data route are metered by the data API
+// limiters and are refused when the data API is disabled. Like Route, all
+// calls to RouteData should be done before the Server is started.
+func (s *Server) RouteData(route string, handler MsgHandler) {
+	s.Route(route, handler)
+	s.dataRoutes[route] = true
+}
+
 func (s *Server) RegisterHTTP(route string, handler HTTPHandler) {
 	if route == "" {
 		panic("RegisterHTTP: route is empty string")
@@ -225,6 +235,9 @@ func newRouteLimiter() *routeLimiter {
 			// Order book and price feed subscriptions
 			msgjson.OrderBookRoute: marketSubsLimiter,
 			msgjson.PriceFeedRoute: marketSubsLimiter,
+			// Data API market data subscriptions
+			msgjson.MarketDataSubscribeRoute:   marketSubsLimiter,
+			msgjson.MarketDataUnsubscribeRoute: marketSubsLimiter,
 			// Config, fee rate, spot prices, and candles
 			msgjson.FeeRateRoute: infoLimiter,
 			msgjson.ConfigRoute:  infoLimiter,
@@ -272,6 +285,9 @@ type Server struct {
 	rpcRoutes map[string]MsgHandler
 	// httpRoutes maps HTTP routes to the handlers.
 	httpRoutes map[string]HTTPHandler
+	// dataRoutes are the rpcRoutes that are part of the data API, and are
+	// subject to the data API meter as well as the websocket route limiter.
+	dataRoutes map[string]bool
 }
 
 // NewServer constructs a Server that should be started with Run. The server is
@@ -383,6 +399,7 @@ func NewServer(cfg *RPCConfig) (*Server, error) {
 		dataEnabled: dataEnabled,
 		rpcRoutes:   make(map[string]MsgHandler),
 		httpRoutes:  make(map[string]HTTPHandler),
+		dataRoutes:  make(map[string]bool),
 	}, nil
 }
 
@@ -644,10 +661,11 @@ func (s *Server) websocketHandler(ctx context.Context, conn ws.Connection, ip de
 	wsLimiter := s.wsLimiter(ip)
 	if wsLimiter == nil { // too many active ws conns from this IP
 		log.Warnf("Too many websocket connections from %v", ip)
+		recordRejection(ip, limiterWSConns)
 		return
 	}
 	defer s.wsLimiterDone(ip)
-	client := s.newWSLink(addr, conn, wsLimiter, dataRoutesMeter)
+	client := s.newWSLink(ip, conn, wsLimiter, dataRoutesMeter)
 
 	cm, err := s.addClient(ctx, client)
 	if err != nil {
@@ -724,6 +742,8 @@ func (s *Server) addClient(ctx context.Context, client *wsLink) (*dex.Connection
 	client.id = s.counter
 	s.counter++
 	s.clients[client.id] = client
+	wsConnections.Set(float64(len(s.clients)))
+	wsConnectionsTotal.Inc()
 	return cm, nil
 }
 
@@ -731,6 +751,7 @@ func (s *Server) addClient(ctx context.Context, client *wsLink) (*dex.Connection
 func (s *Server) removeClient(id uint64) {
 	s.clientMtx.Lock()
 	delete(s.clients, id)
+	wsConnections.Set(float64(len(s.clients)))
 	s.clientMtx.Unlock()
 }
 
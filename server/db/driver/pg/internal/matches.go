@@ -56,7 +56,10 @@ const (
 
 		-- participant/B (taker) REDEEM data
 		bRedeemCoinID BYTEA,
-		bRedeemTime INT8          -- server time stamp
+		bRedeemTime INT8,         -- server time stamp
+
+		-- private swap keys and adaptor signatures, NULL for HTLC swaps
+		privateData BYTEA
 	)`
 
 	RetrieveMatchStatsByEpoch = `SELECT quantity, rate, takerSell FROM %s
@@ -66,7 +69,7 @@ const (
 		aContractCoinID, aContract, aContractTime, bSigAckOfAContract,
 		bContractCoinID, bContract, bContractTime, aSigAckOfBContract,
 		aRedeemCoinID, aRedeemSecret, aRedeemTime, bSigAckOfARedeem,
-		bRedeemCoinID, bRedeemTime
+		bRedeemCoinID, bRedeemTime, privateData
 	FROM %s WHERE matchid = $1;`
 
 	InsertMatch = `INSERT INTO %s (matchid, takerSell,
@@ -147,7 +150,7 @@ const (
 		aContractCoinID, aContract, aContractTime, bSigAckOfAContract,
 		bContractCoinID, bContract, bContractTime, aSigAckOfBContract,
 		aRedeemCoinID, aRedeemSecret, aRedeemTime, bSigAckOfARedeem,
-		bRedeemCoinID, bRedeemTime
+		bRedeemCoinID, bRedeemTime, privateData
 	FROM %s
 	WHERE takerSell IS NOT NULL -- not a cancel order
 		AND active
@@ -232,6 +235,8 @@ const (
 		SET bSigAckOfARedeem = $2
 		WHERE matchid = $1;`
 
+	SetPrivateSwapData = `UPDATE %s SET privateData = $2 WHERE matchid = $1;`
+
 	SetSwapDone = `UPDATE %s SET active = FALSE  -- leave forgiven NULL
 		WHERE matchid = $1;`
 
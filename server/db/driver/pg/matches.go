@@ -31,7 +31,7 @@ func (a *Archiver) matchTableName(match *order.Match) (string, error) {
 // can actually be forgiven (inactive, not already forgiven, and not in
 // MatchComplete status).
 func (a *Archiver) ForgiveMatchFail(mid order.MatchID) (bool, error) {
-	for schema := range a.markets {
+	for schema := range a.marketMap() {
 		stmt := fmt.Sprintf(internal.ForgiveMatchFail, fullMatchesTableName(a.dbName, schema))
 		N, err := sqlExec(a.db, stmt, mid)
 		if err != nil { // not just no rows updated
@@ -49,7 +49,7 @@ func (a *Archiver) ForgiveMatchFail(mid order.MatchID) (bool, error) {
 func (a *Archiver) ActiveSwaps() ([]*db.SwapDataFull, error) {
 	var sd []*db.SwapDataFull
 
-	for schema, mkt := range a.markets {
+	for schema, mkt := range a.marketMap() {
 		matchesTableName := fullMatchesTableName(a.dbName, schema)
 		ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
 		matches, swapData, err := activeSwaps(ctx, a.db, matchesTableName)
@@ -101,7 +101,8 @@ func activeSwaps(ctx context.Context, dbe *sql.DB, tableName string) (matches []
 			&sd.ContractBAckSig,
 			&sd.RedeemACoinID, &sd.RedeemASecret, &redeemATime,
 			&sd.RedeemAAckSig,
-			&sd.RedeemBCoinID, &redeemBTime)
+			&sd.RedeemBCoinID, &redeemBTime,
+			&sd.PrivateData)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -140,7 +141,7 @@ func activeSwaps(ctx context.Context, dbe *sql.DB, tableName string) (matches []
 func (a *Archiver) CompletedAndAtFaultMatchStats(aid account.AccountID, lastN int) ([]*db.MatchOutcome, error) {
 	var outcomes []*db.MatchOutcome
 
-	for schema, mkt := range a.markets {
+	for schema, mkt := range a.marketMap() {
 		matchesTableName := fullMatchesTableName(a.dbName, schema)
 		ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
 		matchOutcomes, err := completedAndAtFaultMatches(ctx, a.db, matchesTableName, aid, lastN, mkt.Base, mkt.Quote)
@@ -166,7 +167,7 @@ func (a *Archiver) CompletedAndAtFaultMatchStats(aid account.AccountID, lastN in
 func (a *Archiver) UserMatchFails(aid account.AccountID, lastN int) ([]*db.MatchFail, error) {
 	var fails []*db.MatchFail
 
-	for schema := range a.markets {
+	for schema := range a.marketMap() {
 		matchesTableName := fullMatchesTableName(a.dbName, schema)
 		ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
 		marketFails, err := atFaultMatches(ctx, a.db, matchesTableName, aid, lastN)
@@ -462,7 +463,7 @@ func (a *Archiver) AllActiveUserMatches(aid account.AccountID) ([]*db.MatchData,
 	defer cancel()
 
 	var matches []*db.MatchData
-	for schema := range a.markets {
+	for schema := range a.marketMap() {
 		matchesTableName := fullMatchesTableName(a.dbName, schema)
 		mdM, err := userMatches(ctx, a.db, matchesTableName, aid, false)
 		if err != nil {
@@ -653,7 +654,8 @@ func (a *Archiver) SwapData(mid db.MarketMatchID) (order.MatchStatus, *db.SwapDa
 			&sd.ContractBAckSig,
 			&sd.RedeemACoinID, &sd.RedeemASecret, &redeemATime,
 			&sd.RedeemAAckSig,
-			&sd.RedeemBCoinID, &redeemBTime)
+			&sd.RedeemBCoinID, &redeemBTime,
+			&sd.PrivateData)
 	if err != nil {
 		return 0, nil, err
 	}
@@ -764,6 +766,13 @@ func (a *Archiver) SaveRedeemB(mid db.MarketMatchID, coinID []byte, timestamp in
 		mid.MatchID, uint8(order.MatchComplete), coinID, timestamp)
 }
 
+// SavePrivateData records the encoded state of a private swap, e.g. the
+// parties' keys and adaptor signatures, replacing any previously stored state.
+func (a *Archiver) SavePrivateData(mid db.MarketMatchID, data []byte) error {
+	return a.updateMatchStmt(mid, internal.SetPrivateSwapData,
+		mid.MatchID, data)
+}
+
 // SetMatchInactive flags the match as done/inactive. This is not necessary if
 // SaveRedeemAckSigB is run for the match since it will flag the match as done.
 func (a *Archiver) SetMatchInactive(mid db.MarketMatchID, forgive bool) error {
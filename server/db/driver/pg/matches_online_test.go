@@ -1121,6 +1121,26 @@ func TestActiveSwaps(t *testing.T) {
 	if wantMid != matchBack.ID() {
 		t.Fatalf("Failed to reconstruct Match %v, computed ID %v instead", matchBack.ID(), wantMid)
 	}
+
+	// Private swap data is loaded with the active swap.
+	if swapDetails.SwapData.PrivateData != nil {
+		t.Fatalf("unexpected private data for an HTLC swap")
+	}
+	privateData := randomBytes(120)
+	if err = archie.SavePrivateData(db.MatchID(match.match), privateData); err != nil {
+		t.Fatalf("SavePrivateData failed: %v", err)
+	}
+	swapsDetails, err = archie.ActiveSwaps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(swapsDetails) != 1 {
+		t.Fatalf("got details for %d swaps, expected 1", len(swapsDetails))
+	}
+	if !bytes.Equal(swapsDetails[0].SwapData.PrivateData, privateData) {
+		t.Fatalf("PrivateData incorrect. got %x, expected %x",
+			swapsDetails[0].SwapData.PrivateData, privateData)
+	}
 }
 
 func TestMatchStatuses(t *testing.T) {
@@ -69,9 +69,11 @@ type Archiver struct {
 	queryTimeout time.Duration
 	db           *sql.DB
 	dbName       string
-	markets      map[string]*dex.MarketInfo
 	tables       archiverTables
 
+	marketsMtx sync.RWMutex
+	markets    map[string]*dex.MarketInfo // keyed by market schema
+
 	queries struct {
 		selectPoints            *sql.Stmt // internal.SelectPoints
 		insertPoints            *sql.Stmt // internal.InsertPoints
@@ -193,7 +195,7 @@ func NewArchiver(ctx context.Context, cfg *Config) (*Archiver, error) {
 		return nil, err
 	}
 	for _, staleMarket := range purgeMarkets {
-		mkt := archiver.markets[staleMarket]
+		mkt := archiver.market(staleMarket)
 		if mkt == nil { // shouldn't happen
 			return nil, fmt.Errorf("unrecognized market %v", staleMarket)
 		}
@@ -222,8 +224,7 @@ func (a *Archiver) marketSchema(base, quote uint32) (string, error) {
 		return "", err
 	}
 	schema := marketSchema(marketName)
-	_, found := a.markets[schema]
-	if !found {
+	if a.market(schema) == nil {
 		return "", db.ArchiveError{
 			Code:   db.ErrUnsupportedMarket,
 			Detail: fmt.Sprintf(`archiver does not support the market "%s"`, schema),
@@ -232,6 +233,82 @@ func (a *Archiver) marketSchema(base, quote uint32) (string, error) {
 	return schema, nil
 }
 
+// market retrieves the MarketInfo for the market schema, or nil if the market
+// is not supported.
+func (a *Archiver) market(schema string) *dex.MarketInfo {
+	a.marketsMtx.RLock()
+	defer a.marketsMtx.RUnlock()
+	return a.markets[schema]
+}
+
+// marketMap returns a copy of the supported markets map, keyed by market
+// schema.
+func (a *Archiver) marketMap() map[string]*dex.MarketInfo {
+	a.marketsMtx.RLock()
+	defer a.marketsMtx.RUnlock()
+	mkts := make(map[string]*dex.MarketInfo, len(a.markets))
+	for schema, mkt := range a.markets {
+		mkts[schema] = mkt
+	}
+	return mkts
+}
+
+// AddMarket prepares the tables for a market that was not in the market
+// configuration provided to the constructor. As on startup, if the market
+// previously existed with a different lot size, its book is flushed. Adding a
+// market that is already supported updates its MarketInfo.
+func (a *Archiver) AddMarket(mkt *dex.MarketInfo) error {
+	schema := marketSchema(mkt.Name)
+	purgeMarkets, err := prepareMarkets(a.db, []*dex.MarketInfo{mkt})
+	if err != nil {
+		return err
+	}
+	a.marketsMtx.Lock()
+	a.markets[schema] = mkt
+	a.marketsMtx.Unlock()
+	if len(purgeMarkets) > 0 {
+		unbookedSells, unbookedBuys, err := a.FlushBook(mkt.Base, mkt.Quote)
+		if err != nil {
+			return fmt.Errorf("failed to flush book for market %v: %w", mkt.Name, err)
+		}
+		log.Infof("Flushed %d sell orders and %d buy orders from market %v with a changed lot size.",
+			len(unbookedSells), len(unbookedBuys), mkt.Name)
+	}
+	return nil
+}
+
+// UpdateMarket updates the stored configuration of an existing market. Unlike
+// AddMarket, the market's book is not flushed if the lot size is changed, so
+// the caller is responsible for revoking booked orders that are incompatible
+// with the new lot size.
+func (a *Archiver) UpdateMarket(mkt *dex.MarketInfo) error {
+	schema := marketSchema(mkt.Name)
+	if a.market(schema) == nil {
+		return fmt.Errorf("unknown market %s", mkt.Name)
+	}
+	if err := updateLotSize(a.db, publicSchema, mkt.Name, mkt.LotSize); err != nil {
+		return fmt.Errorf("unable to update lot size for %s: %w", mkt.Name, err)
+	}
+	a.marketsMtx.Lock()
+	a.markets[schema] = mkt
+	a.marketsMtx.Unlock()
+	return nil
+}
+
+// RemoveMarket drops a market from the supported markets. The market's tables
+// and its row in the markets table are retained, so that it can be added again
+// later.
+func (a *Archiver) RemoveMarket(name string) error {
+	schema := marketSchema(name)
+	a.marketsMtx.Lock()
+	defer a.marketsMtx.Unlock()
+	if a.markets[schema] == nil {
+		return fmt.Errorf("unknown market %s", name)
+	}
+	delete(a.markets, schema)
+	return nil
+}
+
 func (a *Archiver) prepareQueries() (err error) {
 	a.queries.selectPoints, err = a.db.Prepare(fmt.Sprintf(internal.SelectPoints, a.tables.points))
 	if err != nil {
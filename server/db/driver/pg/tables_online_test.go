@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/order"
 )
 
 func TestCheckCurrentTimeZone(t *testing.T) {
@@ -112,3 +113,96 @@ func TestUpdateLotSize(t *testing.T) {
 		t.Error("lot size is not 1337 after updating")
 	}
 }
+
+func TestArchiverAddMarket(t *testing.T) {
+	if err := cleanTables(archie.db); err != nil {
+		t.Fatal(err)
+	}
+
+	mkt, err := dex.NewMarketInfoFromSymbols("dcr", "ltc", LotSize, RateStep, EpochDuration, 1, MarketBuyBuffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := marketSchema(mkt.Name)
+	defer func() {
+		archie.marketsMtx.Lock()
+		delete(archie.markets, schema)
+		archie.marketsMtx.Unlock()
+	}()
+
+	if _, err := archie.BookOrders(mkt.Base, mkt.Quote); err == nil {
+		t.Fatalf("no error for unsupported market")
+	}
+
+	if err := archie.AddMarket(mkt); err != nil {
+		t.Fatalf("AddMarket error: %v", err)
+	}
+	if _, err := archie.BookOrders(mkt.Base, mkt.Quote); err != nil {
+		t.Fatalf("BookOrders error for added market: %v", err)
+	}
+
+	// Adding it again with a new lot size updates the markets table.
+	mkt.LotSize *= 10
+	if err := archie.AddMarket(mkt); err != nil {
+		t.Fatalf("AddMarket error for existing market: %v", err)
+	}
+	mkts, err := loadMarkets(archie.db, marketsTableName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, m := range mkts {
+		if m.Name == mkt.Name {
+			found = true
+			if m.LotSize != mkt.LotSize {
+				t.Fatalf("wrong lot size %d, expected %d", m.LotSize, mkt.LotSize)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("market %s not in markets table", mkt.Name)
+	}
+
+	// UpdateMarket keeps the booked orders.
+	lo := newLimitOrder(false, 4800000, 1, order.StandingTiF, 0)
+	lo.BaseAsset, lo.QuoteAsset = mkt.Base, mkt.Quote
+	lo.Quantity = mkt.LotSize * 2
+	if err := archie.StoreOrder(lo, 13245678, 6000, order.OrderStatusBooked); err != nil {
+		t.Fatalf("StoreOrder failed: %v", err)
+	}
+	updatedMkt := *mkt
+	updatedMkt.LotSize *= 2
+	if err := archie.UpdateMarket(&updatedMkt); err != nil {
+		t.Fatalf("UpdateMarket error: %v", err)
+	}
+	bookOrders, err := archie.BookOrders(mkt.Base, mkt.Quote)
+	if err != nil {
+		t.Fatalf("BookOrders error: %v", err)
+	}
+	if len(bookOrders) != 1 {
+		t.Fatalf("expected 1 book order after UpdateMarket, got %d", len(bookOrders))
+	}
+
+	// A restart with the reconfigured lot size in the markets config does not
+	// flush the book.
+	purgeMarkets, err := prepareMarkets(archie.db, []*dex.MarketInfo{&updatedMkt})
+	if err != nil {
+		t.Fatalf("prepareMarkets error: %v", err)
+	}
+	if len(purgeMarkets) != 0 {
+		t.Fatalf("book of reconfigured market %s flagged for purge on restart", mkt.Name)
+	}
+	bookOrders, err = archie.BookOrders(mkt.Base, mkt.Quote)
+	if err != nil {
+		t.Fatalf("BookOrders error: %v", err)
+	}
+	if len(bookOrders) != 1 {
+		t.Fatalf("expected 1 book order after restart, got %d", len(bookOrders))
+	}
+
+	unknownMkt := *mkt
+	unknownMkt.Name = "dcr_doge"
+	if err := archie.UpdateMarket(&unknownMkt); err == nil {
+		t.Fatalf("no error updating an unknown market")
+	}
+}
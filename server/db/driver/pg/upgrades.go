@@ -17,7 +17,7 @@ import (
 	"decred.org/dcrdex/server/db/driver/pg/internal"
 )
 
-const dbVersion = 7
+const dbVersion = 8
 
 // The number of upgrades defined MUST be equal to dbVersion.
 var upgrades = []func(db *sql.Tx) error{
@@ -53,6 +53,10 @@ var upgrades = []func(db *sql.Tx) error{
 	// facilitates a rolling upgrade of reputation tracking to address an issue
 	// with the DB design.
 	v7Upgrade,
+
+	// v8 upgrade adds a privateData column to the matches tables so that the
+	// state of a private swap survives a restart.
+	v8Upgrade,
 }
 
 // v1Upgrade adds the schema_version column and removes the state_hash column
@@ -375,6 +379,25 @@ func v7Upgrade(tx *sql.Tx) error {
 	return nil
 }
 
+// v8Upgrade adds the privateData column to the matches table of each market.
+func v8Upgrade(tx *sql.Tx) error {
+	mkts, err := loadMarkets(tx, marketsTableName)
+	if err != nil {
+		return fmt.Errorf("failed to read markets table: %w", err)
+	}
+
+	log.Infof("Adding privateData column to matches tables for %d markets", len(mkts))
+
+	for _, mkt := range mkts {
+		tableName := mkt.Name + "." + matchesTableName
+		_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS privateData BYTEA;", tableName))
+		if err != nil {
+			return fmt.Errorf("error adding privateData column to %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
 // DBVersion retrieves the database version from the meta table.
 func DBVersion(db *sql.DB) (ver uint32, err error) {
 	err = db.QueryRow(internal.SelectDBVersion).Scan(&ver)
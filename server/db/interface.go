@@ -88,6 +88,19 @@ type DEXArchivist interface {
 	LastCandleEndStamp(base, quote uint32, candleDur uint64) (uint64, error)
 	InsertCandles(base, quote uint32, dur uint64, cs []*candles.Candle) error
 
+	// AddMarket prepares storage for a market that was not in the market
+	// configuration when the archivist was created.
+	AddMarket(mkt *dex.MarketInfo) error
+
+	// UpdateMarket records a runtime change to an existing market's
+	// configuration, such as a new lot size, without flushing the book.
+	UpdateMarket(mkt *dex.MarketInfo) error
+
+	// RemoveMarket drops a market added with AddMarket from the supported
+	// markets. The market's tables are retained, as they are for a market
+	// that is removed from the market configuration.
+	RemoveMarket(name string) error
+
 	OrderArchiver
 	AccountArchiver
 	KeyIndexer
@@ -335,6 +348,10 @@ type SwapData struct {
 	RedeemAAckSig    []byte // B's signature of redeem A data
 	RedeemBCoinID    []byte
 	RedeemBTime      int64
+	// PrivateData is the encoded state of a private swap that is not part of
+	// the HTLC sequence, e.g. the parties' keys and adaptor signatures. It is
+	// nil for an HTLC swap.
+	PrivateData []byte
 }
 
 // SwapDataFull combines a MatchData, SwapData, and the Base/Quote asset IDs.
@@ -470,6 +487,14 @@ type SwapArchiver interface {
 	// also flag the match as inactive.
 	SaveRedeemB(mid MarketMatchID, coinID []byte, timestamp int64) error
 
+	// Private swaps.
+
+	// SavePrivateData records the encoded state of a private swap, replacing
+	// any previously stored state. It is stored when the match is inserted so
+	// that the match is known to be a private swap, and again each time a
+	// party provides keys, adaptor signatures, or an unsigned redemption.
+	SavePrivateData(mid MarketMatchID, data []byte) error
+
 	// SetMatchInactive sets the swap as done/inactive. This can be because of a
 	// failed or successfully completed swap, but in practice this will be used
 	// for failed swaps since SaveRedeemB flags the swap as done/inactive. If
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -79,6 +80,8 @@ type Market struct {
 	Duration   uint64  `json:"epochDuration"`
 	MBBuffer   float64 `json:"marketBuyBuffer"`
 	Disabled   bool    `json:"disabled"`
+	// PrivateSwaps enables adaptor signature private swaps for the market.
+	PrivateSwaps bool `json:"privateSwaps,omitempty"`
 }
 
 // Config is a market and asset configuration file.
@@ -244,6 +247,7 @@ func loadMarketConf(net dex.Network, src io.Reader) ([]*dex.MarketInfo, []*Asset
 		if err != nil {
 			return nil, nil, err
 		}
+		mkt.PrivateSwaps = mktConf.PrivateSwaps
 		markets = append(markets, mkt)
 	}
 
@@ -258,6 +262,80 @@ func loadMarketConf(net dex.Network, src io.Reader) ([]*dex.MarketInfo, []*Asset
 	return markets, assets, nil
 }
 
+// updateMarketConf writes the lot size and rate step of a market to the
+// markets config file so that they match the stored market parameters on the
+// next startup. All other content of the file is preserved.
+func updateMarketConf(filePath string, net dex.Network, mkt *dex.MarketInfo) error {
+	settings, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	var conf Config
+	if err = json.Unmarshal(settings, &conf); err != nil {
+		return err
+	}
+	// Edit the raw JSON so that fields that are not part of Config survive.
+	var rawConf map[string]json.RawMessage
+	if err = json.Unmarshal(settings, &rawConf); err != nil {
+		return err
+	}
+	var rawMkts []map[string]json.RawMessage
+	if err = json.Unmarshal(rawConf["markets"], &rawMkts); err != nil {
+		return err
+	}
+
+	idx := -1
+	for i, mktConf := range conf.Markets {
+		if mktConf.Disabled {
+			continue
+		}
+		baseConf, quoteConf := conf.Assets[mktConf.Base], conf.Assets[mktConf.Quote]
+		if baseConf == nil || quoteConf == nil {
+			continue
+		}
+		if mktNet, err := dex.NetFromString(baseConf.Network); err != nil || mktNet != net {
+			continue
+		}
+		baseID, found := dex.BipSymbolID(baseConf.Symbol)
+		if !found {
+			continue
+		}
+		quoteID, found := dex.BipSymbolID(quoteConf.Symbol)
+		if !found {
+			continue
+		}
+		if name, err := dex.MarketName(baseID, quoteID); err == nil && name == mkt.Name {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return fmt.Errorf("market %s not found in %s", mkt.Name, filePath)
+	}
+
+	rawMkts[idx]["lotSize"], _ = json.Marshal(mkt.LotSize)
+	rawMkts[idx]["rateStep"], _ = json.Marshal(mkt.RateStep)
+	if rawConf["markets"], err = json.Marshal(rawMkts); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(rawConf, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	fi, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	// Replace the file atomically so an interrupted write cannot leave a
+	// truncated config.
+	tmpPath := filePath + ".tmp"
+	if err = os.WriteFile(tmpPath, append(b, '\n'), fi.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filePath)
+}
+
 // DBConf groups the database configuration parameters.
 type DBConf struct {
 	DBName       string
@@ -423,6 +501,9 @@ type DexConf struct {
 	CommsCfg         *RPCConfig
 	NoResumeSwaps    bool
 	NodeRelayAddr    string
+	// MarketsConfPath is the markets config file that Markets were loaded
+	// from. If set, market reconfigurations are written back to it.
+	MarketsConfPath string
 }
 
 type signer struct {
@@ -453,16 +534,32 @@ func (ss *subsystem) stop() {
 // DEX is the DEX manager, which creates and controls the lifetime of all
 // components of the DEX.
 type DEX struct {
-	network     dex.Network
-	markets     map[string]*market.Market
-	assets      map[uint32]*swap.SwapperAsset
-	storage     db.DEXArchivist
-	authMgr     *auth.AuthManager
-	swapper     *swap.Swapper
-	orderRouter *market.OrderRouter
-	bookRouter  *market.BookRouter
-	subsystems  []subsystem
-	server      *comms.Server
+	network         dex.Network
+	marketsConfPath string
+	assets          map[uint32]*swap.SwapperAsset
+	storage         db.DEXArchivist
+	authMgr         *auth.AuthManager
+	swapper         *swap.Swapper
+	orderRouter     *market.OrderRouter
+	bookRouter      *market.BookRouter
+	server          *comms.Server
+
+	// The following are needed to create markets added with AddMarket.
+	coinLocker  *coinlock.DEXCoinLocker
+	feeMgr      *FeeManager
+	dataAPI     *apidata.DataAPI
+	dexBalancer *market.DEXBalancer
+
+	// adminMtx serializes the addition, removal, and reconfiguration of
+	// markets, and guards stopped and retiring.
+	adminMtx sync.Mutex
+	stopped  bool
+	retiring map[string]bool
+	// marketsMtx guards markets and subsystems, which change when markets are
+	// added or retired.
+	marketsMtx sync.RWMutex
+	markets    map[string]*market.Market
+	subsystems []subsystem
 
 	configRespMtx sync.RWMutex
 	configResp    *configResponse
@@ -535,6 +632,34 @@ func (cr *configResponse) setMktResume(name string, startEpoch uint64) (epochLen
 	return 0
 }
 
+func (cr *configResponse) addMarket(mkt *msgjson.Market) {
+	cr.configMsg.Markets = append(cr.configMsg.Markets, mkt)
+	cr.remarshal()
+}
+
+func (cr *configResponse) removeMarket(name string) {
+	mkts := make([]*msgjson.Market, 0, len(cr.configMsg.Markets))
+	for _, mkt := range cr.configMsg.Markets {
+		if mkt.Name != name {
+			mkts = append(mkts, mkt)
+		}
+	}
+	cr.configMsg.Markets = mkts
+	cr.remarshal()
+}
+
+func (cr *configResponse) setMktConfig(name string, lotSize, rateStep uint64) {
+	for _, mkt := range cr.configMsg.Markets {
+		if mkt.Name == name {
+			mkt.LotSize = lotSize
+			mkt.RateStep = rateStep
+			cr.remarshal()
+			return
+		}
+	}
+	log.Errorf("Failed to update config for market %q", name)
+}
+
 func (cr *configResponse) remarshal() {
 	encResult, err := json.Marshal(cr.configMsg)
 	if err != nil {
@@ -548,7 +673,14 @@ func (cr *configResponse) remarshal() {
 // completed their shutdown.
 func (dm *DEX) Stop() {
 	log.Infof("Stopping all DEX subsystems.")
-	for _, ss := range dm.subsystems {
+	// Prevent scheduled market reconfigurations from resuming a market.
+	dm.adminMtx.Lock()
+	dm.stopped = true
+	dm.adminMtx.Unlock()
+	dm.marketsMtx.RLock()
+	subsystems := dm.subsystems
+	dm.marketsMtx.RUnlock()
+	for _, ss := range subsystems {
 		log.Infof("Stopping %s...", ss.name)
 		ss.stop()
 		log.Infof("%s is now shut down.", ss.name)
@@ -885,10 +1017,23 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		return nil, err
 	}
 
-	// Create the user order unbook dispatcher for the AuthManager.
+	// The DEX manager's markets map is populated below, and is used by the
+	// dispatchers for the AuthManager and Swapper.
 	markets := make(map[string]*market.Market, len(cfg.Markets))
+	dexMgr := &DEX{
+		network:         cfg.Network,
+		marketsConfPath: cfg.MarketsConfPath,
+		markets:         markets,
+		assets:          lockableAssets,
+		storage:         storage,
+		coinLocker:      dexCoinLocker,
+		feeMgr:          feeMgr,
+		retiring:        make(map[string]bool),
+	}
+
+	// Create the user order unbook dispatcher for the AuthManager.
 	userUnbookFun := func(user account.AccountID) {
-		for _, mkt := range markets {
+		for _, mkt := range dexMgr.marketList() {
 			mkt.UnbookUserOrders(user)
 		}
 	}
@@ -924,7 +1069,9 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		return nil, fmt.Errorf("NewServer failed: %w", err)
 	}
 
-	dataAPI := apidata.NewDataAPI(storage, server.RegisterHTTP)
+	dataAPI := apidata.NewDataAPI(storage, server.RegisterHTTP, server.RouteData)
+	dexMgr.dataAPI = dataAPI
+	dexMgr.server = server
 
 	authCfg := auth.Config{
 		Storage:          storage,
@@ -943,6 +1090,7 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 	}
 
 	authMgr := auth.NewAuthManager(&authCfg)
+	dexMgr.authMgr = authMgr
 	log.Infof("Cancellation rate threshold %f, new user grace period %d cancels",
 		cfg.CancelThreshold, authMgr.GraceLimit())
 	log.Infof("MIA user order unbook timeout %v", cfg.BroadcastTimeout)
@@ -958,7 +1106,33 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 			log.Errorf("bad market for order %v: %v", ord.ID(), err)
 			return
 		}
-		markets[name].SwapDone(ord, match, fail)
+		mkt := dexMgr.market(name)
+		if mkt == nil {
+			// The market was retired with swaps still in progress.
+			log.Debugf("Swap done for order %v on retired market %s", ord.ID(), name)
+			return
+		}
+		mkt.SwapDone(ord, match, fail)
+	}
+
+	// Private swaps require that both of the market's asset backends can
+	// locate private swap outputs.
+	privateMarkets := make(map[[2]uint32]bool)
+	for _, mktInf := range cfg.Markets {
+		if !mktInf.PrivateSwaps {
+			continue
+		}
+		for _, assetID := range []uint32{mktInf.Base, mktInf.Quote} {
+			ba := backedAssets[assetID]
+			if ba == nil {
+				return nil, fmt.Errorf("no backend for asset %d of market %s", assetID, mktInf.Name)
+			}
+			if _, ok := ba.Backend.(asset.PrivateSwapper); !ok {
+				return nil, fmt.Errorf("market %s has private swaps enabled, but %s does not support private swaps",
+					mktInf.Name, ba.Symbol)
+			}
+		}
+		privateMarkets[[2]uint32{mktInf.Base, mktInf.Quote}] = true
 	}
 
 	// Create the swapper.
@@ -972,6 +1146,7 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		LockTimeMaker:    dex.LockTimeMaker(cfg.Network),
 		SwapDone:         swapDone,
 		NoResume:         cfg.NoResumeSwaps,
+		PrivateMarkets:   privateMarkets,
 		// TODO: set the AllowPartialRestore bool to allow startup with a
 		// missing asset backend if necessary in an emergency.
 	}
@@ -980,13 +1155,14 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 	if err != nil {
 		return nil, fmt.Errorf("NewSwapper: %w", err)
 	}
+	dexMgr.swapper = swapper
 
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	// Because the dexBalancer relies on the marketTunnels map, and NewMarket
-	// checks necessary balances for account-based assets using the dexBalancer,
+	// Because each market is added to the dexBalancer as it is created, and
+	// NewMarket checks necessary balances for account-based assets using the dexBalancer,
 	// that means that each market can only query orders for the markets that
 	// were initialized before it was, which is fine, but notable. The
 	// resulting behavior is that a user could have orders involving an
@@ -998,59 +1174,26 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 	// account balance is too low to support them all, though an algorithm could
 	// be developed to do reject only some orders, based on available funding.
 	//
-	// This pattern is only safe because the markets are not Run until after
-	// they are all instantiated, so we are synchronous in our use of the
-	// marketTunnels map.
+	// Markets added at runtime with AddMarket are created and added to the
+	// dexBalancer in the same way.
 	marketTunnels := make(map[string]market.MarketTunnel, len(cfg.Markets))
-	pendingAccounters := make(map[string]market.PendingAccounter, len(cfg.Markets))
 
-	dexBalancer, err := market.NewDEXBalancer(pendingAccounters, backedAssets, swapper)
+	dexBalancer, err := market.NewDEXBalancer(nil, backedAssets, swapper)
 	if err != nil {
 		return nil, fmt.Errorf("NewDEXBalancer error: %w", err)
 	}
+	dexMgr.dexBalancer = dexBalancer
 
 	// Markets
-	var orderRouter *market.OrderRouter
 	usersWithOrders := make(map[account.AccountID]struct{})
 	for _, mktInf := range cfg.Markets {
-		// nilness of the coin locker signals account-based asset.
-		var baseCoinLocker, quoteCoinLocker coinlock.CoinLocker
-		b, q := backedAssets[mktInf.Base], backedAssets[mktInf.Quote]
-		if _, ok := b.Backend.(asset.OutputTracker); ok {
-			baseCoinLocker = dexCoinLocker.AssetLocker(mktInf.Base).Book()
-		}
-		if _, ok := q.Backend.(asset.OutputTracker); ok {
-			quoteCoinLocker = dexCoinLocker.AssetLocker(mktInf.Quote).Book()
-		}
-
-		// Calculate a minimum market rate that avoids dust.
-		// quote_dust = base_lot * min_rate / rate_encoding_factor
-		// => min_rate = quote_dust * rate_encoding_factor * base_lot
-		quoteMinLotSize, _, _ := asset.Minimums(mktInf.Quote, q.Asset.MaxFeeRate)
-		minRate := calc.MinimumMarketRate(mktInf.LotSize, quoteMinLotSize)
-
-		mkt, err := market.NewMarket(&market.Config{
-			MarketInfo:      mktInf,
-			Storage:         storage,
-			Swapper:         swapper,
-			AuthManager:     authMgr,
-			FeeFetcherBase:  feeMgr.FeeFetcher(mktInf.Base),
-			CoinLockerBase:  baseCoinLocker,
-			FeeFetcherQuote: feeMgr.FeeFetcher(mktInf.Quote),
-			CoinLockerQuote: quoteCoinLocker,
-			DataCollector:   dataAPI,
-			Balancer:        dexBalancer,
-			CheckParcelLimit: func(user account.AccountID, calcParcels market.MarketParcelCalculator) bool {
-				return orderRouter.CheckParcelLimit(user, mktInf.Name, calcParcels)
-			},
-			MinimumRate: minRate,
-		})
+		mkt, err := dexMgr.newMarket(mktInf)
 		if err != nil {
-			return nil, fmt.Errorf("NewMarket failed: %w", err)
+			return nil, err
 		}
 		markets[mktInf.Name] = mkt
 		marketTunnels[mktInf.Name] = mkt
-		pendingAccounters[mktInf.Name] = mkt
+		dexBalancer.AddMarket(mkt)
 		log.Infof("Preparing historical market data API for market %v...", mktInf.Name)
 		err = dataAPI.AddMarketSource(mkt)
 		if err != nil {
@@ -1086,19 +1229,7 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		startEpochIdx := 1 + now/int64(mkt.EpochDuration())
 		mkt.SetStartEpochIdx(startEpochIdx)
 		bookSources[name] = mkt
-		cfgMarkets = append(cfgMarkets, &msgjson.Market{
-			Name:            name,
-			Base:            mkt.Base(),
-			Quote:           mkt.Quote(),
-			LotSize:         mkt.LotSize(),
-			RateStep:        mkt.RateStep(),
-			EpochLen:        mkt.EpochDuration(),
-			MarketBuyBuffer: mkt.MarketBuyBuffer(),
-			ParcelSize:      mkt.ParcelSize(),
-			MarketStatus: msgjson.MarketStatus{
-				StartEpoch: uint64(startEpochIdx),
-			},
-		})
+		cfgMarkets = append(cfgMarkets, marketConfig(name, mkt, startEpochIdx))
 	}
 
 	// Book router
@@ -1114,7 +1245,7 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 	}
 
 	// Order router
-	orderRouter = market.NewOrderRouter(&market.OrderRouterConfig{
+	orderRouter := market.NewOrderRouter(&market.OrderRouterConfig{
 		Assets:       backedAssets,
 		AuthManager:  authMgr,
 		Markets:      marketTunnels,
@@ -1133,19 +1264,10 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		return nil, err
 	}
 
-	dexMgr := &DEX{
-		network:     cfg.Network,
-		markets:     markets,
-		assets:      lockableAssets,
-		swapper:     swapper,
-		authMgr:     authMgr,
-		storage:     storage,
-		orderRouter: orderRouter,
-		bookRouter:  bookRouter,
-		subsystems:  subsystems,
-		server:      server,
-		configResp:  cfgResp,
-	}
+	dexMgr.orderRouter = orderRouter
+	dexMgr.bookRouter = bookRouter
+	dexMgr.subsystems = subsystems
+	dexMgr.configResp = cfgResp
 
 	server.RegisterHTTP(msgjson.ConfigRoute, dexMgr.handleDEXConfig)
 	server.RegisterHTTP(msgjson.HealthRoute, dexMgr.handleHealthFlag)
@@ -1173,6 +1295,85 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 	return dexMgr, nil
 }
 
+// newMarket creates a Market for the MarketInfo. The market's assets must have
+// running backends.
+func (dm *DEX) newMarket(mktInf *dex.MarketInfo) (*market.Market, error) {
+	// nilness of the coin locker signals account-based asset.
+	var baseCoinLocker, quoteCoinLocker coinlock.CoinLocker
+	b, q := dm.assets[mktInf.Base], dm.assets[mktInf.Quote]
+	if _, ok := b.Backend.(asset.OutputTracker); ok {
+		baseCoinLocker = dm.coinLocker.AssetLocker(mktInf.Base).Book()
+	}
+	if _, ok := q.Backend.(asset.OutputTracker); ok {
+		quoteCoinLocker = dm.coinLocker.AssetLocker(mktInf.Quote).Book()
+	}
+
+	mkt, err := market.NewMarket(&market.Config{
+		MarketInfo:      mktInf,
+		Storage:         dm.storage,
+		Swapper:         dm.swapper,
+		AuthManager:     dm.authMgr,
+		FeeFetcherBase:  dm.feeMgr.FeeFetcher(mktInf.Base),
+		CoinLockerBase:  baseCoinLocker,
+		FeeFetcherQuote: dm.feeMgr.FeeFetcher(mktInf.Quote),
+		CoinLockerQuote: quoteCoinLocker,
+		DataCollector:   dm.dataAPI,
+		Balancer:        dm.dexBalancer,
+		CheckParcelLimit: func(user account.AccountID, calcParcels market.MarketParcelCalculator) bool {
+			return dm.orderRouter.CheckParcelLimit(user, mktInf.Name, calcParcels)
+		},
+		MinimumRate: dm.minimumRate(mktInf.Quote, mktInf.LotSize),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("NewMarket failed: %w", err)
+	}
+	return mkt, nil
+}
+
+// minimumRate calculates a minimum market rate that avoids dust.
+func (dm *DEX) minimumRate(quoteID uint32, lotSize uint64) uint64 {
+	// quote_dust = base_lot * min_rate / rate_encoding_factor
+	// => min_rate = quote_dust * rate_encoding_factor * base_lot
+	quoteMinLotSize, _, _ := asset.Minimums(quoteID, dm.assets[quoteID].Asset.MaxFeeRate)
+	return calc.MinimumMarketRate(lotSize, quoteMinLotSize)
+}
+
+// marketConfig creates the config response entry for a market.
+func marketConfig(name string, mkt *market.Market, startEpochIdx int64) *msgjson.Market {
+	return &msgjson.Market{
+		Name:            name,
+		Base:            mkt.Base(),
+		Quote:           mkt.Quote(),
+		LotSize:         mkt.LotSize(),
+		RateStep:        mkt.RateStep(),
+		EpochLen:        mkt.EpochDuration(),
+		MarketBuyBuffer: mkt.MarketBuyBuffer(),
+		ParcelSize:      mkt.ParcelSize(),
+		PrivateSwaps:    mkt.PrivateSwaps(),
+		MarketStatus: msgjson.MarketStatus{
+			StartEpoch: uint64(startEpochIdx),
+		},
+	}
+}
+
+// market retrieves the named market, or nil if the market is unknown.
+func (dm *DEX) market(name string) *market.Market {
+	dm.marketsMtx.RLock()
+	defer dm.marketsMtx.RUnlock()
+	return dm.markets[name]
+}
+
+// marketList returns a slice of all known markets.
+func (dm *DEX) marketList() []*market.Market {
+	dm.marketsMtx.RLock()
+	defer dm.marketsMtx.RUnlock()
+	mkts := make([]*market.Market, 0, len(dm.markets))
+	for _, mkt := range dm.markets {
+		mkts = append(mkts, mkt)
+	}
+	return mkts
+}
+
 // Asset retrieves an asset backend by its ID.
 func (dm *DEX) Asset(id uint32) (*asset.BackedAsset, error) {
 	asset, found := dm.assets[id]
@@ -1186,7 +1387,7 @@ func (dm *DEX) Asset(id uint32) (*asset.BackedAsset, error) {
 // the optimal fee rates for new swaps for for the specified asset. That is,
 // values above 1 increase the fee rate, while values below 1 decrease it.
 func (dm *DEX) SetFeeRateScale(assetID uint32, scale float64) {
-	for _, mkt := range dm.markets {
+	for _, mkt := range dm.marketList() {
 		if mkt.Base() == assetID || mkt.Quote() == assetID {
 			mkt.SetFeeRateScale(assetID, scale)
 		}
@@ -1197,7 +1398,7 @@ func (dm *DEX) SetFeeRateScale(assetID uint32, scale float64) {
 // rate scale factor, which is 1.0 by default.
 func (dm *DEX) ScaleFeeRate(assetID uint32, rate uint64) uint64 {
 	// Any market will have the rate. Just find the first one.
-	for _, mkt := range dm.markets {
+	for _, mkt := range dm.marketList() {
 		if mkt.Base() == assetID || mkt.Quote() == assetID {
 			return mkt.ScaleFeeRate(assetID, rate)
 		}
@@ -1215,7 +1416,7 @@ func (dm *DEX) ConfigMsg() json.RawMessage {
 // TODO: for just market running status, the DEX manager should use its
 // knowledge of Market subsystem state.
 func (dm *DEX) MarketRunning(mktName string) (found, running bool) {
-	mkt := dm.markets[mktName]
+	mkt := dm.market(mktName)
 	if mkt == nil {
 		return
 	}
@@ -1225,7 +1426,7 @@ func (dm *DEX) MarketRunning(mktName string) (found, running bool) {
 // MarketStatus returns the market.Status for the named market. If the market is
 // unknown to the DEX, nil is returned.
 func (dm *DEX) MarketStatus(mktName string) *market.Status {
-	mkt := dm.markets[mktName]
+	mkt := dm.market(mktName)
 	if mkt == nil {
 		return nil
 	}
@@ -1235,6 +1436,8 @@ func (dm *DEX) MarketStatus(mktName string) *market.Status {
 // MarketStatuses returns a map of market names to market.Status for all known
 // markets.
 func (dm *DEX) MarketStatuses() map[string]*market.Status {
+	dm.marketsMtx.RLock()
+	defer dm.marketsMtx.RUnlock()
 	statuses := make(map[string]*market.Status, len(dm.markets))
 	for name, mkt := range dm.markets {
 		statuses[name] = mkt.Status()
@@ -1251,12 +1454,12 @@ func (dm *DEX) SuspendMarket(name string, tSusp time.Time, persistBooks bool) (s
 	name = strings.ToLower(name)
 
 	// Locate the (running) subsystem for this market.
-	i := dm.findSubsys(marketSubSysName(name))
-	if i == -1 {
+	ssw := dm.marketSubsys(name)
+	if ssw == nil {
 		err = fmt.Errorf("market subsystem %s not found", name)
 		return
 	}
-	if !dm.subsystems[i].ssw.On() {
+	if !ssw.On() {
 		err = fmt.Errorf("market subsystem %s is not running", name)
 		return
 	}
@@ -1290,6 +1493,8 @@ func (dm *DEX) SuspendMarket(name string, tSusp time.Time, persistBooks bool) (s
 	return
 }
 
+// findSubsys finds the index of the named subsystem, or -1 if not found. The
+// marketsMtx must be held.
 func (dm *DEX) findSubsys(name string) int {
 	for i := range dm.subsystems {
 		if dm.subsystems[i].name == name {
@@ -1299,12 +1504,24 @@ func (dm *DEX) findSubsys(name string) int {
 	return -1
 }
 
+// marketSubsys retrieves the StartStopWaiter for the named market's subsystem,
+// or nil if not found.
+func (dm *DEX) marketSubsys(name string) *dex.StartStopWaiter {
+	dm.marketsMtx.RLock()
+	defer dm.marketsMtx.RUnlock()
+	i := dm.findSubsys(marketSubSysName(name))
+	if i == -1 {
+		return nil
+	}
+	return dm.subsystems[i].ssw
+}
+
 // ResumeMarket launches a stopped market subsystem as early as the given time.
 // The actual time the market will resume depends on the configure epoch
 // duration, as the market only starts at the beginning of an epoch.
 func (dm *DEX) ResumeMarket(name string, asSoonAs time.Time) (startEpoch int64, startTime time.Time, err error) {
 	name = strings.ToLower(name)
-	mkt := dm.markets[name]
+	mkt := dm.market(name)
 	if mkt == nil {
 		err = fmt.Errorf("unknown market %s", name)
 		return
@@ -1319,12 +1536,10 @@ func (dm *DEX) ResumeMarket(name string, asSoonAs time.Time) (startEpoch int64,
 	}
 
 	// Locate the (stopped) subsystem for this market.
-	i := dm.findSubsys(marketSubSysName(name))
-	if i == -1 {
+	if ssw := dm.marketSubsys(name); ssw == nil {
 		err = fmt.Errorf("market subsystem %s not found", name)
 		return
-	}
-	if dm.subsystems[i].ssw.On() {
+	} else if ssw.On() {
 		err = fmt.Errorf("market subsystem %s not stopped", name)
 		return
 	}
@@ -1344,7 +1559,11 @@ func (dm *DEX) ResumeMarket(name string, asSoonAs time.Time) (startEpoch int64,
 
 	// Relaunch the market.
 	ssw := dex.NewStartStopWaiter(mkt)
-	dm.subsystems[i].ssw = ssw
+	dm.marketsMtx.Lock()
+	if i := dm.findSubsys(marketSubSysName(name)); i != -1 {
+		dm.subsystems[i].ssw = ssw
+	}
+	dm.marketsMtx.Unlock()
 	ssw.Start(context.Background())
 
 	// Broadcast a TradeResumption notification to all connected clients.
@@ -1363,6 +1582,352 @@ func (dm *DEX) ResumeMarket(name string, asSoonAs time.Time) (startEpoch int64,
 	return
 }
 
+// AddMarket creates and launches a new market without restarting the DEX. The
+// market's base and quote assets must already be configured, since asset
+// backends are only created on startup. The market starts trading at the next
+// epoch, and the updated config is broadcasted to all connected clients. The
+// market is not written to the markets config file, so it must also be added
+// there to persist across restarts.
+func (dm *DEX) AddMarket(mktInf *dex.MarketInfo) (startEpoch int64, startTime time.Time, err error) {
+	mktInf.Name = strings.ToLower(mktInf.Name)
+	name := mktInf.Name
+
+	switch {
+	case mktInf.LotSize == 0:
+		err = fmt.Errorf("market %s has no lot size", name)
+	case mktInf.RateStep == 0:
+		err = fmt.Errorf("market %s has no rate step", name)
+	case mktInf.ParcelSize == 0:
+		err = fmt.Errorf("market %s has no parcel size", name)
+	case mktInf.EpochDuration == 0:
+		err = fmt.Errorf("market %s has no epoch duration", name)
+	}
+	if err != nil {
+		return
+	}
+
+	for _, assetID := range []uint32{mktInf.Base, mktInf.Quote} {
+		if dm.assets[assetID] == nil {
+			err = fmt.Errorf("asset %s is not configured. New assets require a restart",
+				dex.BipIDSymbol(assetID))
+			return
+		}
+	}
+	if mktInf.PrivateSwaps {
+		for _, assetID := range []uint32{mktInf.Base, mktInf.Quote} {
+			if _, ok := dm.assets[assetID].Backend.(asset.PrivateSwapper); !ok {
+				err = fmt.Errorf("market %s has private swaps enabled, but %s does not support private swaps",
+					name, dex.BipIDSymbol(assetID))
+				return
+			}
+		}
+	}
+	b := dm.assets[mktInf.Base]
+	if minLotSize, _, found := asset.Minimums(mktInf.Base, b.Asset.MaxFeeRate); found && mktInf.LotSize < minLotSize {
+		err = fmt.Errorf("lot size %d for market %s is below the minimum %d", mktInf.LotSize, name, minLotSize)
+		return
+	}
+
+	dm.adminMtx.Lock()
+	defer dm.adminMtx.Unlock()
+
+	if dm.market(name) != nil {
+		err = fmt.Errorf("market %s already exists", name)
+		return
+	}
+
+	if err = dm.storage.AddMarket(mktInf); err != nil {
+		err = fmt.Errorf("error preparing storage for market %s: %w", name, err)
+		return
+	}
+	// Undo the completed steps if any of the following fail.
+	var undo []func()
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}()
+	undo = append(undo, func() {
+		if err := dm.storage.RemoveMarket(name); err != nil {
+			log.Errorf("Failed to remove market %s from storage: %v", name, err)
+		}
+	})
+
+	// Matches are settled with private swaps from the market's first epoch.
+	dm.swapper.SetPrivateMarket(mktInf.Base, mktInf.Quote, mktInf.PrivateSwaps)
+	undo = append(undo, func() {
+		dm.swapper.SetPrivateMarket(mktInf.Base, mktInf.Quote, false)
+	})
+
+	mkt, err := dm.newMarket(mktInf)
+	if err != nil {
+		return
+	}
+	// Route orders to the market. The market rejects them until it starts
+	// running.
+	if err = dm.orderRouter.AddMarket(name, mkt); err != nil {
+		err = fmt.Errorf("error adding market %s to the order router: %w", name, err)
+		return
+	}
+	undo = append(undo, func() {
+		if err := dm.orderRouter.RemoveMarket(name); err != nil {
+			log.Errorf("Failed to remove market %s from the order router: %v", name, err)
+		}
+	})
+	if err = dm.dataAPI.AddMarketSource(mkt); err != nil {
+		err = fmt.Errorf("DataSource.AddMarketSource: %w", err)
+		return
+	}
+	undo = append(undo, func() {
+		if err := dm.dataAPI.RemoveMarketSource(mkt); err != nil {
+			log.Errorf("Failed to remove market %s from the data API: %v", name, err)
+		}
+	})
+	if err = dm.bookRouter.AddBook(name, mkt); err != nil {
+		err = fmt.Errorf("error adding market %s to the book router: %w", name, err)
+		return
+	}
+	dm.dexBalancer.AddMarket(mkt)
+
+	epochLen := int64(mkt.EpochDuration())
+	startEpoch = 1 + time.Now().UnixMilli()/epochLen
+	startTime = time.UnixMilli(startEpoch * epochLen)
+	mkt.SetStartEpochIdx(startEpoch)
+
+	// Launch the market, and stop it before the BookRouter on shutdown like
+	// the other markets.
+	subsys := subsystem{
+		name: marketSubSysName(name),
+		ssw:  dex.NewStartStopWaiter(mkt),
+	}
+	subsys.ssw.Start(context.Background()) // stopped with Stop
+	dm.marketsMtx.Lock()
+	dm.markets[name] = mkt
+	i := dm.findSubsys("BookRouter")
+	if i == -1 {
+		i = 0
+	}
+	// Copy the subsystems, since Stop may be iterating the current slice.
+	dm.subsystems = slices.Insert(slices.Clone(dm.subsystems), i, subsys)
+	dm.marketsMtx.Unlock()
+
+	dm.configRespMtx.Lock()
+	dm.configResp.addMarket(marketConfig(name, mkt, startEpoch))
+	configEnc := dm.configResp.configEnc
+	dm.configRespMtx.Unlock()
+	dm.broadcastConfig(configEnc)
+
+	log.Infof("Added market %s, starting at epoch %d (%v).", name, startEpoch, startTime)
+	return startEpoch, startTime, nil
+}
+
+// RetireMarket suspends the market as soon as possible, purging its book, and
+// removes the market after it stops. Swaps already in progress continue to
+// settle. The updated config is broadcasted to all connected clients when the
+// market is removed. If the market is already suspended, any persisted book is
+// purged, the market is removed immediately, and the returned SuspendEpoch is
+// nil.
+func (dm *DEX) RetireMarket(name string) (*market.SuspendEpoch, error) {
+	name = strings.ToLower(name)
+
+	dm.adminMtx.Lock()
+	defer dm.adminMtx.Unlock()
+
+	if dm.retiring[name] {
+		return nil, fmt.Errorf("market %s is already being retired", name)
+	}
+	mkt := dm.market(name)
+	if mkt == nil {
+		return nil, fmt.Errorf("unknown market %s", name)
+	}
+	ssw := dm.marketSubsys(name)
+	if ssw == nil {
+		return nil, fmt.Errorf("market subsystem %s not found", name)
+	}
+
+	var suspEpoch *market.SuspendEpoch
+	if ssw.On() {
+		var err error
+		suspEpoch, err = dm.SuspendMarket(name, time.Time{}, false)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		mkt.PurgeBook()
+	}
+
+	dm.retiring[name] = true
+	go func() {
+		ssw.WaitForShutdown()
+		dm.removeMarket(name, mkt)
+	}()
+
+	return suspEpoch, nil
+}
+
+// removeMarket removes a stopped market from the DEX and broadcasts the updated
+// config.
+func (dm *DEX) removeMarket(name string, mkt *market.Market) {
+	dm.adminMtx.Lock()
+	defer dm.adminMtx.Unlock()
+	defer delete(dm.retiring, name)
+
+	if err := dm.orderRouter.RemoveMarket(name); err != nil {
+		log.Errorf("Failed to remove market %s from the order router: %v", name, err)
+	}
+	if err := dm.bookRouter.RemoveBook(name); err != nil {
+		log.Errorf("Failed to remove market %s from the book router: %v", name, err)
+	}
+	dm.dexBalancer.RemoveMarket(mkt)
+	// Swaps in progress keep settling as they started.
+	dm.swapper.SetPrivateMarket(mkt.Base(), mkt.Quote(), false)
+	if err := dm.dataAPI.RemoveMarketSource(mkt); err != nil {
+		log.Errorf("Failed to remove market %s from the data API: %v", name, err)
+	}
+
+	dm.marketsMtx.Lock()
+	delete(dm.markets, name)
+	if i := dm.findSubsys(marketSubSysName(name)); i != -1 {
+		dm.subsystems = slices.Delete(slices.Clone(dm.subsystems), i, i+1)
+	}
+	dm.marketsMtx.Unlock()
+
+	dm.configRespMtx.Lock()
+	dm.configResp.removeMarket(name)
+	configEnc := dm.configResp.configEnc
+	dm.configRespMtx.Unlock()
+	dm.broadcastConfig(configEnc)
+
+	log.Infof("Retired market %s.", name)
+}
+
+// ReconfigureMarket schedules a change to the lot size and rate step of a
+// market at the end of the epoch that includes asSoonAs, or the active epoch if
+// asSoonAs is the zero time. A zero lotSize or rateStep leaves that parameter
+// unchanged. The market is suspended with its book persisted, and booked orders
+// that are incompatible with the new parameters are revoked without penalty.
+// The updated config is then broadcasted to all connected clients, and the
+// market is resumed at the next epoch with a resumption notification that
+// signals the config change. If the market is already suspended, the change is
+// applied immediately and the returned SuspendEpoch is nil, leaving the market
+// to be resumed with ResumeMarket. The new parameters are written to the markets
+// config file, if one was configured, so that the stored and configured lot
+// sizes agree on restart and the book is not flushed.
+func (dm *DEX) ReconfigureMarket(name string, lotSize, rateStep uint64, asSoonAs time.Time) (*market.SuspendEpoch, error) {
+	name = strings.ToLower(name)
+	mkt := dm.market(name)
+	if mkt == nil {
+		return nil, fmt.Errorf("unknown market %s", name)
+	}
+	if lotSize == 0 {
+		lotSize = mkt.LotSize()
+	}
+	if rateStep == 0 {
+		rateStep = mkt.RateStep()
+	}
+	if lotSize == mkt.LotSize() && rateStep == mkt.RateStep() {
+		return nil, fmt.Errorf("no change to the lot size or rate step of market %s", name)
+	}
+	b := dm.assets[mkt.Base()]
+	if minLotSize, _, found := asset.Minimums(mkt.Base(), b.Asset.MaxFeeRate); found && lotSize < minLotSize {
+		return nil, fmt.Errorf("lot size %d for market %s is below the minimum %d", lotSize, name, minLotSize)
+	}
+	ssw := dm.marketSubsys(name)
+	if ssw == nil {
+		return nil, fmt.Errorf("market subsystem %s not found", name)
+	}
+
+	if !ssw.On() {
+		dm.adminMtx.Lock()
+		defer dm.adminMtx.Unlock()
+		return nil, dm.reconfigureMarket(name, mkt, lotSize, rateStep)
+	}
+
+	suspEpoch, err := dm.SuspendMarket(name, asSoonAs, true)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		ssw.WaitForShutdown()
+
+		dm.adminMtx.Lock()
+		defer dm.adminMtx.Unlock()
+		if dm.stopped {
+			return
+		}
+		// The market may have been resumed or retired by the operator.
+		if dm.marketSubsys(name) != ssw {
+			log.Warnf("Market %s was resumed or retired before its scheduled reconfiguration.", name)
+			return
+		}
+		if err := dm.reconfigureMarket(name, mkt, lotSize, rateStep); err != nil {
+			log.Errorf("Failed to reconfigure market %s: %v", name, err)
+		}
+		if _, _, err := dm.ResumeMarket(name, time.Now()); err != nil {
+			log.Errorf("Failed to resume market %s after reconfiguration: %v", name, err)
+		}
+	}()
+
+	return suspEpoch, nil
+}
+
+// reconfigurableMarket is the part of a *market.Market that is used to change
+// its lot size and rate step.
+type reconfigurableMarket interface {
+	Info() *dex.MarketInfo
+	Quote() uint32
+	Reconfigure(lotSize, rateStep, minimumRate uint64) (revoked int, err error)
+}
+
+// reconfigureMarket applies a new lot size and rate step to a stopped market
+// and broadcasts the updated config. The new parameters are only stored once
+// the market has accepted them. The adminMtx must be held.
+func (dm *DEX) reconfigureMarket(name string, mkt reconfigurableMarket, lotSize, rateStep uint64) error {
+	revoked, err := mkt.Reconfigure(lotSize, rateStep, dm.minimumRate(mkt.Quote(), lotSize))
+	if err != nil {
+		return err
+	}
+	mktInf := mkt.Info()
+	if err := dm.storage.UpdateMarket(mktInf); err != nil {
+		// The market has the new parameters, but the stored ones are used
+		// after a restart.
+		return fmt.Errorf("market %s reconfigured, but error updating storage: %w", name, err)
+	}
+	if dm.marketsConfPath != "" {
+		if err := updateMarketConf(dm.marketsConfPath, dm.network, mktInf); err != nil {
+			// The book would be flushed after a restart with the old config.
+			log.Errorf("Failed to write the new parameters of market %s to %s. "+
+				"Update the markets config before restarting to keep the book: %v",
+				name, dm.marketsConfPath, err)
+		}
+	}
+
+	dm.configRespMtx.Lock()
+	dm.configResp.setMktConfig(name, lotSize, rateStep)
+	configEnc := dm.configResp.configEnc
+	dm.configRespMtx.Unlock()
+	dm.broadcastConfig(configEnc)
+
+	log.Infof("Reconfigured market %s with lot size %d and rate step %d. %d booked orders revoked.",
+		name, lotSize, rateStep, revoked)
+	return nil
+}
+
+// broadcastConfig sends a ConfigChange notification with the encoded config to
+// all connected clients.
+func (dm *DEX) broadcastConfig(configEnc json.RawMessage) {
+	note, err := msgjson.NewNotification(msgjson.ConfigChangeRoute, configEnc)
+	if err != nil {
+		log.Errorf("Failed to create config change notification: %v", err)
+		return
+	}
+	dm.server.Broadcast(note)
+}
+
 // AccountInfo returns data for an account.
 func (dm *DEX) AccountInfo(aid account.AccountID) (*db.Account, error) {
 	// TODO: consider asking the auth manager for account info, including tier.
@@ -1515,12 +2080,23 @@ func (dm *DEX) MarketMatches(base, quote uint32) ([]*MatchData, error) {
 	return matchDatas, nil
 }
 
+// ActiveSwaps returns a snapshot of the matches that are being settled.
+func (dm *DEX) ActiveSwaps(ctx context.Context) []*swap.ActiveSwap {
+	return dm.swapper.ActiveSwaps(ctx)
+}
+
 // EnableDataAPI can be called via admin API to enable or disable the HTTP data
 // API endpoints.
 func (dm *DEX) EnableDataAPI(yes bool) {
 	dm.server.EnableDataAPI(yes)
 }
 
+// RateLimitOffenders returns the n client IPs with the most rate limiter
+// rejections.
+func (dm *DEX) RateLimitOffenders(n int) []*comms.RateLimitOffender {
+	return dm.server.RateLimitOffenders(n)
+}
+
 func (dm *DEX) ForgiveUser(user account.AccountID) error {
 	return dm.authMgr.ForgiveUser(user)
 }
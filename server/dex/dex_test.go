@@ -0,0 +1,164 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package dex
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/server/asset"
+	"decred.org/dcrdex/server/comms"
+	"decred.org/dcrdex/server/db"
+	"decred.org/dcrdex/server/swap"
+)
+
+type tStorage struct {
+	db.DEXArchivist
+	updated []*dex.MarketInfo
+}
+
+func (s *tStorage) UpdateMarket(mkt *dex.MarketInfo) error {
+	s.updated = append(s.updated, mkt)
+	return nil
+}
+
+type tMarket struct {
+	info           *dex.MarketInfo
+	reconfigureErr error
+}
+
+func (m *tMarket) Info() *dex.MarketInfo {
+	mktInfo := *m.info
+	return &mktInfo
+}
+
+func (m *tMarket) Quote() uint32 {
+	return m.info.Quote
+}
+
+func (m *tMarket) Reconfigure(lotSize, rateStep, minimumRate uint64) (int, error) {
+	if m.reconfigureErr != nil {
+		return 0, m.reconfigureErr
+	}
+	m.info.LotSize, m.info.RateStep = lotSize, rateStep
+	return 0, nil
+}
+
+func TestReconfigureMarketFailure(t *testing.T) {
+	const base, quote = 42, 0
+	storage := new(tStorage)
+	dm := &DEX{
+		storage: storage,
+		assets: map[uint32]*swap.SwapperAsset{
+			quote: {BackedAsset: &asset.BackedAsset{Asset: dex.Asset{ID: quote, MaxFeeRate: 100}}},
+		},
+	}
+	mkt := &tMarket{
+		info: &dex.MarketInfo{
+			Name:     "dcr_btc",
+			Base:     base,
+			Quote:    quote,
+			LotSize:  1e8,
+			RateStep: 1e3,
+		},
+		reconfigureErr: errors.New("market dcr_btc is running"),
+	}
+
+	// The market refuses the new parameters, so they are not stored.
+	if err := dm.reconfigureMarket("dcr_btc", mkt, 2e8, 1e4); err == nil {
+		t.Fatalf("no error for failed reconfiguration")
+	}
+	if len(storage.updated) != 0 {
+		t.Fatalf("market updated in storage after failed reconfiguration")
+	}
+	if mkt.info.LotSize != 1e8 || mkt.info.RateStep != 1e3 {
+		t.Fatalf("market parameters changed after failed reconfiguration")
+	}
+}
+
+func TestReconfigureMarketRestart(t *testing.T) {
+	const marketsConf = `{
+    "markets": [
+        {
+            "base": "DCR_simnet",
+            "quote": "BTC_simnet",
+            "lotSize": 100000000,
+            "rateStep": 1000,
+            "epochDuration": 6000,
+            "marketBuyBuffer": 1.25,
+            "parcelSize": 4
+        }
+    ],
+    "assets": {
+        "DCR_simnet": {
+            "bip44symbol": "dcr",
+            "network": "simnet",
+            "maxFeeRate": 10,
+            "swapConf": 1,
+            "configPath": "/home/dcrd/.dcrd/dcrd.conf"
+        },
+        "BTC_simnet": {
+            "bip44symbol": "btc",
+            "network": "simnet",
+            "maxFeeRate": 100,
+            "swapConf": 1
+        }
+    }
+}`
+	confPath := filepath.Join(t.TempDir(), "markets.json")
+	if err := os.WriteFile(confPath, []byte(marketsConf), 0600); err != nil {
+		t.Fatal(err)
+	}
+	markets, _, err := LoadConfig(dex.Simnet, confPath)
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+
+	comms.UseLogger(dex.StdOutLogger("COMMS_TEST", dex.LevelInfo))
+	storage := new(tStorage)
+	dm := &DEX{
+		network:         dex.Simnet,
+		marketsConfPath: confPath,
+		storage:         storage,
+		assets: map[uint32]*swap.SwapperAsset{
+			0: {BackedAsset: &asset.BackedAsset{Asset: dex.Asset{ID: 0, MaxFeeRate: 100}}},
+		},
+		configResp: &configResponse{configMsg: &msgjson.ConfigResult{
+			Markets: []*msgjson.Market{{Name: markets[0].Name}},
+		}},
+		server: new(comms.Server),
+	}
+	mkt := &tMarket{info: markets[0]}
+	if err := dm.reconfigureMarket(markets[0].Name, mkt, 2e8, 1e4); err != nil {
+		t.Fatalf("reconfigureMarket error: %v", err)
+	}
+	if len(storage.updated) != 1 {
+		t.Fatalf("market not updated in storage")
+	}
+	stored := storage.updated[0]
+
+	// On restart, the DB driver flushes the book of any market with a
+	// configured lot size that differs from the stored one.
+	markets, assets, err := LoadConfig(dex.Simnet, confPath)
+	if err != nil {
+		t.Fatalf("LoadConfig error after reconfiguration: %v", err)
+	}
+	if len(markets) != 1 {
+		t.Fatalf("expected 1 market after restart, got %d", len(markets))
+	}
+	if markets[0].LotSize != stored.LotSize {
+		t.Fatalf("configured lot size %d differs from stored lot size %d, so the book would be flushed",
+			markets[0].LotSize, stored.LotSize)
+	}
+	if markets[0].RateStep != 1e4 || markets[0].EpochDuration != 6000 || markets[0].ParcelSize != 4 {
+		t.Fatalf("wrong market config after restart: %+v", markets[0])
+	}
+	if len(assets) != 2 || assets[1].ConfigPath != "/home/dcrd/.dcrd/dcrd.conf" {
+		t.Fatalf("asset config not preserved")
+	}
+}
@@ -5,6 +5,7 @@ package market
 
 import (
 	"fmt"
+	"sync"
 
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/calc"
@@ -41,6 +42,10 @@ type MatchNegotiator interface {
 type DEXBalancer struct {
 	assets          map[uint32]*backedBalancer
 	matchNegotiator MatchNegotiator
+
+	// marketsMtx guards the markets slices of the backedBalancers, which are
+	// modified when markets are added or removed at runtime.
+	marketsMtx sync.RWMutex
 }
 
 // NewDEXBalancer is a constructor for a DEXBalancer. Provided assets will
@@ -211,9 +216,13 @@ func (b *DEXBalancer) CheckBalance(acctAddr string, assetID, redeemAssetID uint3
 
 		isToken := ba.feeBalancer != nil
 
+		b.marketsMtx.RLock()
+		markets := ba.markets
+		b.marketsMtx.RUnlock()
+
 		var l uint64
 		var r int
-		for _, mt := range ba.markets {
+		for _, mt := range markets {
 			newQty, newLots, newRedeems := mt.AccountPending(acctAddr, assetID)
 			l += newLots
 			q += newQty
@@ -274,6 +283,38 @@ func (b *DEXBalancer) CheckBalance(acctAddr string, assetID, redeemAssetID uint3
 
 // backedBalancer is similar to a BackedAsset, but with the Backends already
 // cast to AccountBalancer.
+// AddMarket adds a market to the balance checks of any of its account-based
+// assets.
+func (b *DEXBalancer) AddMarket(mkt PendingAccounter) {
+	b.marketsMtx.Lock()
+	defer b.marketsMtx.Unlock()
+	for _, assetID := range []uint32{mkt.Base(), mkt.Quote()} {
+		if bb, found := b.assets[assetID]; found {
+			bb.markets = append(bb.markets, mkt)
+		}
+	}
+}
+
+// RemoveMarket removes a market added with AddMarket or provided to the
+// constructor.
+func (b *DEXBalancer) RemoveMarket(mkt PendingAccounter) {
+	b.marketsMtx.Lock()
+	defer b.marketsMtx.Unlock()
+	for _, assetID := range []uint32{mkt.Base(), mkt.Quote()} {
+		bb, found := b.assets[assetID]
+		if !found {
+			continue
+		}
+		markets := make([]PendingAccounter, 0, len(bb.markets))
+		for _, m := range bb.markets {
+			if m != mkt {
+				markets = append(markets, m)
+			}
+		}
+		bb.markets = markets
+	}
+}
+
 type backedBalancer struct {
 	balancer    asset.AccountBalancer
 	assetInfo   *dex.Asset
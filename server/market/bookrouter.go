@@ -124,8 +124,8 @@ type sigDataSuspend struct {
 }
 
 type sigDataResume struct {
-	epochIdx int64
-	// TODO: indicate config change if applicable
+	epochIdx     int64
+	configChange bool
 }
 
 type sigDataMatchProof struct {
@@ -203,6 +203,9 @@ type msgBook struct {
 	source        BookSource
 	baseID        uint32
 	quoteID       uint32
+	// stop cancels the book's monitoring goroutine. It is set when the book
+	// is started by the BookRouter.
+	stop context.CancelFunc
 }
 
 func (book *msgBook) setEpoch(idx int64) {
@@ -283,7 +286,14 @@ func (book *msgBook) addBulkOrders(epoch int64, orderSets ...[]*order.LimitOrder
 // of subscribers, and maintaining an intermediate copy of the orderbook in
 // message payload format for quick, full-book syncing.
 type BookRouter struct {
-	books     map[string]*msgBook
+	booksMtx sync.RWMutex
+	books    map[string]*msgBook
+	// runCtx is the Context passed to Run. It is used to start monitoring the
+	// books added with AddBook while the router is running, and is nil when
+	// the router is not running.
+	runCtx context.Context
+	wg     sync.WaitGroup
+
 	feeSource FeeSource
 
 	priceFeeders *subscribers
@@ -305,18 +315,7 @@ func NewBookRouter(sources map[string]BookSource, feeSource FeeSource, route fun
 		spots: make(map[string]*msgjson.Spot),
 	}
 	for mkt, src := range sources {
-		subs := &subscribers{
-			conns: make(map[uint64]comms.Link),
-		}
-		book := &msgBook{
-			name:    mkt,
-			orders:  make(map[order.OrderID]*msgjson.BookOrderNote),
-			subs:    subs,
-			source:  src,
-			baseID:  src.Base(),
-			quoteID: src.Quote(),
-		}
-		router.books[mkt] = book
+		router.books[mkt] = newMsgBook(mkt, src)
 	}
 	route(msgjson.OrderBookRoute, router.handleOrderBook)
 	route(msgjson.UnsubOrderBookRoute, router.handleUnsubOrderBook)
@@ -326,17 +325,92 @@ func NewBookRouter(sources map[string]BookSource, feeSource FeeSource, route fun
 	return router
 }
 
+func newMsgBook(name string, src BookSource) *msgBook {
+	return &msgBook{
+		name:   name,
+		orders: make(map[order.OrderID]*msgjson.BookOrderNote),
+		subs: &subscribers{
+			conns: make(map[uint64]comms.Link),
+		},
+		source:  src,
+		baseID:  src.Base(),
+		quoteID: src.Quote(),
+	}
+}
+
 // Run implements dex.Runner, and is blocking.
 func (r *BookRouter) Run(ctx context.Context) {
-	var wg sync.WaitGroup
+	r.booksMtx.Lock()
+	r.runCtx = ctx
 	for _, b := range r.books {
-		wg.Add(1)
-		go func(b *msgBook) {
-			r.runBook(ctx, b)
-			wg.Done()
-		}(b)
+		r.startBook(ctx, b)
+	}
+	r.booksMtx.Unlock()
+
+	<-ctx.Done()
+
+	r.booksMtx.Lock()
+	r.runCtx = nil
+	r.booksMtx.Unlock()
+	r.wg.Wait()
+}
+
+// startBook launches the monitoring goroutine for the book. The booksMtx must
+// be held.
+func (r *BookRouter) startBook(ctx context.Context, book *msgBook) {
+	ctx, book.stop = context.WithCancel(ctx)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.runBook(ctx, book)
+	}()
+}
+
+// AddBook adds a new BookSource to the router. If the router is running, the
+// book is monitored immediately. The book should be added before the Market is
+// started so that no updates are missed.
+func (r *BookRouter) AddBook(mktName string, src BookSource) error {
+	r.booksMtx.Lock()
+	defer r.booksMtx.Unlock()
+	if _, found := r.books[mktName]; found {
+		return fmt.Errorf("book for market %s already exists", mktName)
+	}
+	book := newMsgBook(mktName, src)
+	r.books[mktName] = book
+	if r.runCtx != nil {
+		r.startBook(r.runCtx, book)
 	}
-	wg.Wait()
+	return nil
+}
+
+// RemoveBook stops monitoring the named market's book and removes it from the
+// router. Subscribers are not notified, so the market should be suspended
+// first.
+func (r *BookRouter) RemoveBook(mktName string) error {
+	r.booksMtx.Lock()
+	book, found := r.books[mktName]
+	if !found {
+		r.booksMtx.Unlock()
+		return fmt.Errorf("market %s unknown", mktName)
+	}
+	delete(r.books, mktName)
+	r.booksMtx.Unlock()
+
+	if book.stop != nil {
+		book.stop()
+	}
+
+	r.spotsMtx.Lock()
+	delete(r.spots, mktName)
+	r.spotsMtx.Unlock()
+	return nil
+}
+
+// book retrieves the named market's book, or nil if the market is unknown.
+func (r *BookRouter) book(mktName string) *msgBook {
+	r.booksMtx.RLock()
+	defer r.booksMtx.RUnlock()
+	return r.books[mktName]
 }
 
 // runBook is a monitoring loop for an order book.
@@ -526,7 +600,8 @@ out:
 				note = &msgjson.TradeResumption{
 					MarketID: book.name,
 					// ResumeTime of 0 means now.
-					StartEpoch: uint64(sigData.epochIdx),
+					StartEpoch:   uint64(sigData.epochIdx),
+					ConfigChange: sigData.configChange,
 				} // no Seq for the resume since it doesn't modify the book
 
 				log.Infof("Market %q resumed at epoch %d", book.name, sigData.epochIdx)
@@ -549,7 +624,7 @@ out:
 
 // Book creates a copy of the book as a *msgjson.OrderBook.
 func (r *BookRouter) Book(mktName string) (*msgjson.OrderBook, error) {
-	book := r.books[mktName]
+	book := r.book(mktName)
 	if book == nil {
 		return nil, fmt.Errorf("market %s unknown", mktName)
 	}
@@ -627,8 +702,8 @@ func (r *BookRouter) handleOrderBook(conn comms.Link, msg *msgjson.Message) *msg
 			Message: "market name error: " + err.Error(),
 		}
 	}
-	book, found := r.books[mkt]
-	if !found {
+	book := r.book(mkt)
+	if book == nil {
 		return &msgjson.Error{
 			Code:    msgjson.UnknownMarket,
 			Message: "unknown market",
@@ -651,7 +726,7 @@ func (r *BookRouter) handleUnsubOrderBook(conn comms.Link, msg *msgjson.Message)
 			Message: "error parsing unsub_orderbook request",
 		}
 	}
-	book := r.books[unsub.MarketID]
+	book := r.book(unsub.MarketID)
 	if book == nil {
 		return &msgjson.Error{
 			Code:    msgjson.UnknownMarket,
@@ -781,9 +856,16 @@ func limitOrderToMsgOrder(o *order.LimitOrder, mkt string) *msgjson.BookOrderNot
 	if o.Sell {
 		oSide = msgjson.SellOrderNum
 	}
-	tif := uint8(msgjson.StandingOrderNum)
-	if o.Force == order.ImmediateTiF {
+	var tif uint8
+	switch o.Force {
+	case order.ImmediateTiF:
 		tif = msgjson.ImmediateOrderNum
+	case order.FillOrKillTiF:
+		tif = msgjson.FillOrKillOrderNum
+	case order.PostOnlyTiF:
+		tif = msgjson.PostOnlyOrderNum
+	default:
+		tif = msgjson.StandingOrderNum
 	}
 	return &msgjson.BookOrderNote{
 		OrderNote: msgjson.OrderNote{
@@ -121,6 +121,7 @@ type Config struct {
 //  6. Cycle the epochs.
 //  7. Record all events with the archivist.
 type Market struct {
+	cfgMtx     sync.RWMutex // guards marketInfo.LotSize, marketInfo.RateStep, and minimumRate
 	marketInfo *dex.MarketInfo
 
 	tasks sync.WaitGroup // for lazy asynchronous tasks e.g. revoke ntfns
@@ -145,6 +146,7 @@ type Market struct {
 	activeEpochIdx   int64
 	suspendEpochIdx  int64
 	persistBook      bool
+	configChanged    bool // Reconfigure was called while stopped
 	epochCommitments map[order.Commitment]order.OrderID
 	epochOrders      map[order.OrderID]order.Order
 
@@ -173,7 +175,7 @@ type Market struct {
 
 	checkParcelLimit func(user account.AccountID, calcParcels MarketParcelCalculator) bool
 
-	minimumRate uint64
+	minimumRate uint64 // guarded by cfgMtx
 }
 
 // Storage is the DB interface required by Market.
@@ -486,9 +488,13 @@ ordersLoop:
 		return nil, fmt.Errorf("failed to load last epoch end rate: %w", err)
 	}
 
-	return &Market{
+	// The Market keeps its own copy of the MarketInfo since the lot size and
+	// rate step may be changed with Reconfigure.
+	mktInfoCopy := *mktInfo
+
+	mkt := &Market{
 		running:          make(chan struct{}), // closed on market start
-		marketInfo:       mktInfo,
+		marketInfo:       &mktInfoCopy,
 		book:             Book,
 		settling:         settling,
 		matcher:          matcher.New(),
@@ -506,7 +512,10 @@ ordersLoop:
 		lastRate:         lastEpochEndRate,
 		checkParcelLimit: cfg.CheckParcelLimit,
 		minimumRate:      cfg.MinimumRate,
-	}, nil
+	}
+	mkt.recordBookDepth()
+
+	return mkt, nil
 }
 
 // SuspendASAP suspends requests the market to gracefully suspend epoch cycling
@@ -667,14 +676,26 @@ func (m *Market) MarketBuyBuffer() float64 {
 
 // LotSize returns the market's lot size in units of the base asset.
 func (m *Market) LotSize() uint64 {
+	m.cfgMtx.RLock()
+	defer m.cfgMtx.RUnlock()
 	return m.marketInfo.LotSize
 }
 
 // RateStep returns the market's rate step in units of the quote asset.
 func (m *Market) RateStep() uint64 {
+	m.cfgMtx.RLock()
+	defer m.cfgMtx.RUnlock()
 	return m.marketInfo.RateStep
 }
 
+// Info returns a copy of the market's current configuration.
+func (m *Market) Info() *dex.MarketInfo {
+	m.cfgMtx.RLock()
+	defer m.cfgMtx.RUnlock()
+	mktInfo := *m.marketInfo
+	return &mktInfo
+}
+
 // Base is the base asset ID.
 func (m *Market) Base() uint32 {
 	return m.marketInfo.Base
@@ -768,6 +789,7 @@ func (m *Market) processCancelOrderWhileSuspended(rec *orderRecord, errChan chan
 	m.bookMtx.Lock()
 	delete(m.settling, co.TargetOrderID)
 	lo, ok := m.book.Remove(co.TargetOrderID)
+	m.recordBookDepth()
 	m.bookMtx.Unlock()
 	if !ok {
 		errChan <- ErrTargetNotCancelable
@@ -995,7 +1017,7 @@ func (m *Market) Cancelable(oid order.OrderID) bool {
 	m.epochMtx.RUnlock()
 
 	if lo, ok := ord.(*order.LimitOrder); ok {
-		return lo.Force == order.StandingTiF
+		return lo.Force.Standing()
 	}
 	return false
 }
@@ -1026,7 +1048,7 @@ func (m *Market) CancelableBy(oid order.OrderID, aid account.AccountID) (bool, t
 	if !ok {
 		return false, time.Time{}, ErrTargetNotCancelable
 	}
-	if lo.Force != order.StandingTiF {
+	if !lo.Force.Standing() {
 		return false, time.Time{}, ErrTargetNotCancelable
 	}
 	if lo.AccountID != aid {
@@ -1109,6 +1131,7 @@ func (m *Market) SwapDone(ord order.Order, match *order.Match, fail bool) {
 		if limit {
 			// Try to unbook and revoke failed limit orders.
 			_, removed := m.book.Remove(oid)
+			m.recordBookDepth()
 			m.unlockOrderCoins(lo)
 			if removed {
 				// Lazily update DB and auth, and notify orderbook subscribers.
@@ -1122,7 +1145,7 @@ func (m *Market) SwapDone(ord order.Order, match *order.Match, fail bool) {
 	// matches can be made). We check Book.HaveOrder instead of Remaining since
 	// the provided Order instance may not belong to Market and may thus be out
 	// of sync with respect to filled amount.
-	if settling > 0 || (limit && lo.Force == order.StandingTiF && m.book.HaveOrder(oid)) {
+	if settling > 0 || (limit && lo.Force.Standing() && m.book.HaveOrder(oid)) {
 		m.settling[oid] = settling
 		return
 	}
@@ -1185,7 +1208,7 @@ func (m *Market) AccountPending(acctAddr string, assetID uint32) (qty, lots uint
 		midGap = m.RateStep()
 	}
 
-	lotSize := m.marketInfo.LotSize
+	lotSize := m.LotSize()
 	switch assetID {
 	case base:
 		m.iterateBaseAccount(acctAddr, func(trade *order.Trade, rate uint64) {
@@ -1298,6 +1321,7 @@ func (m *Market) purgeBook() (removed map[order.OrderID]account.AccountID) {
 
 	// Clear the in-memory order book to match the DB.
 	buysRemoved, sellsRemoved := m.book.Clear()
+	m.recordBookDepth()
 
 	log.Infof("Flushed %d sell orders and %d buy orders from market %q book",
 		len(sellsRemoved), len(buysRemoved), m.marketInfo.Name)
@@ -1340,6 +1364,73 @@ func (m *Market) purgeBook() (removed map[order.OrderID]account.AccountID) {
 	return
 }
 
+// Reconfigure changes the lot size, rate step, and minimum rate of a stopped
+// Market, such as after a suspend with persistBook set. Booked orders that are
+// incompatible with the new parameters are removed from the book and revoked
+// without counting against their owners, who are sent revoke_order
+// notifications. Subscribers of the market's order book are sent unbook
+// notifications. The number of revoked orders is returned.
+func (m *Market) Reconfigure(lotSize, rateStep, minimumRate uint64) (revoked int, err error) {
+	if lotSize == 0 || rateStep == 0 {
+		return 0, fmt.Errorf("invalid lot size %d or rate step %d", lotSize, rateStep)
+	}
+	// Do not change the parameters while epochs are being processed.
+	if atomic.LoadUint32(&m.up) == 1 {
+		return 0, fmt.Errorf("market %s is running", m.marketInfo.Name)
+	}
+
+	incompatible := func(lo *order.LimitOrder) bool {
+		return lo.Quantity%lotSize != 0 || lo.FillAmt%lotSize != 0 ||
+			lo.Rate%rateStep != 0 || lo.Rate < minimumRate
+	}
+
+	m.bookMtx.Lock()
+	var removed []*order.LimitOrder
+	for _, lo := range append(m.book.BuyOrders(), m.book.SellOrders()...) {
+		if !incompatible(lo) {
+			continue
+		}
+		if _, ok := m.book.Remove(lo.ID()); ok {
+			delete(m.settling, lo.ID())
+			removed = append(removed, lo)
+		}
+	}
+	m.book.SetLotSize(lotSize)
+	m.recordBookDepth()
+
+	m.cfgMtx.Lock()
+	m.marketInfo.LotSize = lotSize
+	m.marketInfo.RateStep = rateStep
+	m.minimumRate = minimumRate
+	m.cfgMtx.Unlock()
+	m.bookMtx.Unlock()
+
+	m.epochMtx.Lock()
+	m.configChanged = true
+	m.epochMtx.Unlock()
+
+	for _, lo := range removed {
+		m.unlockOrderCoins(lo)
+		// The user is not at fault, so the revocation is not counted.
+		if _, _, err := m.storage.RevokeOrderUncounted(lo); err != nil {
+			log.Errorf("Failed to revoke order %v: %v", lo, err)
+		}
+		m.sendRevokeOrderNote(lo.ID(), lo.User())
+		m.sendToFeeds(&updateSignal{
+			action: unbookAction,
+			data: sigDataUnbookedOrder{
+				order:    lo,
+				epochIdx: -1, // NOTE: no epoch
+			},
+		})
+	}
+
+	log.Infof("Market %s reconfigured with lot size %d and rate step %d. Revoked %d incompatible book orders.",
+		m.marketInfo.Name, lotSize, rateStep, len(removed))
+
+	return len(removed), nil
+}
+
 func (m *Market) lazy(do func()) {
 	m.tasks.Add(1)
 	go func() {
@@ -1540,11 +1631,12 @@ func (m *Market) Run(ctx context.Context) {
 					notifyChan <- &updateSignal{
 						action: resumeAction,
 						data: sigDataResume{
-							epochIdx: currentEpoch.Epoch,
-							// TODO: signal config or new config
+							epochIdx:     currentEpoch.Epoch,
+							configChange: m.configChanged,
 						},
 					}
 				}
+				m.configChanged = false
 			}
 		}
 
@@ -1698,9 +1790,12 @@ func (m *Market) analysisHelpers() (
 	bestBuy, midGap, bestSell := m.rates()
 	likelyTaker = func(ord order.Order) bool {
 		lo, ok := ord.(*order.LimitOrder)
-		if !ok || lo.Force == order.ImmediateTiF {
+		if !ok || !lo.Force.Standing() {
 			return true
 		}
+		if lo.Force == order.PostOnlyTiF {
+			return false // never matched as a taker
+		}
 		// Must cross the spread to be a taker (not so conservative).
 		switch {
 		case midGap == 0:
@@ -1719,7 +1814,7 @@ func (m *Market) analysisHelpers() (
 		if ord.Type() == order.MarketOrderType && !ord.Trade().Sell {
 			// Market buy qty is in quote asset. Convert to base.
 			if midGap == 0 {
-				qty = m.marketInfo.LotSize // no orders on the book; call it 1 lot
+				qty = m.LotSize() // no orders on the book; call it 1 lot
 			} else {
 				qty = calc.QuoteToBase(midGap, qty)
 			}
@@ -1734,6 +1829,12 @@ func (m *Market) ParcelSize() uint32 {
 	return m.marketInfo.ParcelSize
 }
 
+// PrivateSwaps is true if the market's matches are settled with adaptor
+// signature private swaps.
+func (m *Market) PrivateSwaps() bool {
+	return m.marketInfo.PrivateSwaps
+}
+
 // Parcels calculates the total parcels for the market with the specified
 // settling quantity. Parcels is used as part of order validation for global
 // parcel limits. Parcels is not called for the market for which the order is
@@ -1762,7 +1863,7 @@ func (m *Market) parcels(user account.AccountID, addParcelWeight uint64) float64
 
 	bookedBuyAmt, bookedSellAmt, _, _ := m.book.UserOrderTotals(user)
 	makerQty += bookedBuyAmt + bookedSellAmt
-	return calc.Parcels(makerQty+addParcelWeight, takerQty, m.marketInfo.LotSize, m.marketInfo.ParcelSize)
+	return calc.Parcels(makerQty+addParcelWeight, takerQty, m.LotSize(), m.marketInfo.ParcelSize)
 }
 
 // processOrder performs the following actions:
@@ -2210,6 +2311,7 @@ func (m *Market) prepEpoch(orders []order.Order, epochEnd time.Time) (cSum []byt
 func (m *Market) UnbookUserOrders(user account.AccountID) {
 	m.bookMtx.Lock()
 	removedBuys, removedSells := m.book.RemoveUserOrders(user)
+	m.recordBookDepth()
 	// No order completion credit in SwapDone for revoked orders:
 	for _, lo := range removedSells {
 		delete(m.settling, lo.ID())
@@ -2259,6 +2361,7 @@ func (m *Market) Unbook(lo *order.LimitOrder) bool {
 	// Ensure we do not unbook during matching.
 	m.bookMtx.Lock()
 	_, removed := m.book.Remove(lo.ID())
+	m.recordBookDepth()
 	delete(m.settling, lo.ID()) // no order completion credit in SwapDone for revoked orders
 	m.bookMtx.Unlock()
 
@@ -2375,6 +2478,7 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 	m.bookEpochIdx = epoch.Epoch + 1
 	epochDur := int64(m.EpochDuration())
 	var canceled []order.OrderID
+	var tradeMatches int
 	for _, ms := range matches {
 		// Set the epoch ID.
 		ms.Epoch.Idx = uint64(epoch.Epoch)
@@ -2392,6 +2496,7 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 				})
 				continue
 			}
+			tradeMatches++
 			m.settling[match.Taker.ID()] += match.Quantity
 			m.settling[match.Maker.ID()] += match.Quantity
 		}
@@ -2401,8 +2506,12 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 		// there is no completion credit on a canceled order.
 		delete(m.settling, oid)
 	}
+	m.recordBookDepth()
 	m.bookMtx.Unlock()
 
+	epochsProcessed.With(m.marketInfo.Name).Inc()
+	epochMatches.With(m.marketInfo.Name).Add(uint64(tradeMatches))
+
 	if len(ordersRevealed) > 0 {
 		log.Infof("Matching complete for market %v epoch %d:"+
 			" %d matches (%d partial fills), %d completed OK (not booked),"+
@@ -2608,11 +2717,19 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 		m.auth.RecordCancel(co.User(), co.ID(), co.TargetOrderID, epochGap, matchTime)
 	}
 
-	// Send "nomatch" notifications.
+	// Send "nomatch" notifications. Post-only orders that failed would have
+	// matched, and are flagged so the client knows they were not booked.
+	rejected := make(map[order.OrderID]bool)
+	for _, ord := range updates.TradesFailed {
+		if lo, ok := ord.(*order.LimitOrder); ok && lo.Force == order.PostOnlyTiF {
+			rejected[lo.ID()] = true
+		}
+	}
 	for _, ord := range nomatched {
 		oid := ord.Order.ID()
 		msg, err := msgjson.NewNotification(msgjson.NoMatchRoute, &msgjson.NoMatch{
-			OrderID: oid[:],
+			OrderID:  oid[:],
+			Rejected: rejected[oid],
 		})
 		if err != nil {
 			// This is probably impossible in practice, but we'll log it anyway.
@@ -2682,11 +2799,15 @@ func (m *Market) validateOrder(ord order.Order) error {
 		return ErrInvalidCommitment
 	}
 
-	if !db.ValidateOrder(ord, order.OrderStatusEpoch, m.marketInfo) {
+	m.cfgMtx.RLock()
+	mktInfo, minRate := *m.marketInfo, m.minimumRate
+	m.cfgMtx.RUnlock()
+
+	if !db.ValidateOrder(ord, order.OrderStatusEpoch, &mktInfo) {
 		return ErrInvalidOrder // non-specific
 	}
 
-	if lo, is := ord.(*order.LimitOrder); is && lo.Rate < m.minimumRate {
+	if lo, is := ord.(*order.LimitOrder); is && lo.Rate < minRate {
 		return ErrInvalidRate
 	}
 
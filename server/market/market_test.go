@@ -132,6 +132,9 @@ func (ta *TArchivist) InsertEpoch(ed *db.EpochResults) error {
 func (ta *TArchivist) LastEpochRate(base, quote uint32) (rate uint64, err error) {
 	return 1, nil
 }
+func (ta *TArchivist) AddMarket(mkt *dex.MarketInfo) error    { return nil }
+func (ta *TArchivist) UpdateMarket(mkt *dex.MarketInfo) error { return nil }
+func (ta *TArchivist) RemoveMarket(name string) error         { return nil }
 func (ta *TArchivist) BookOrder(lo *order.LimitOrder) error {
 	ta.mtx.Lock()
 	defer ta.mtx.Unlock()
@@ -207,6 +210,7 @@ func (ta *TArchivist) SaveRedeemAckSigB(mid db.MarketMatchID, sig []byte) error
 func (ta *TArchivist) SaveRedeemB(mid db.MarketMatchID, coinID []byte, timestamp int64) error {
 	return nil
 }
+func (ta *TArchivist) SavePrivateData(mid db.MarketMatchID, data []byte) error   { return nil }
 func (ta *TArchivist) SetMatchInactive(mid db.MarketMatchID, forgive bool) error { return nil }
 func (ta *TArchivist) LoadEpochStats(uint32, uint32, []*candles.Cache) error     { return nil }
 
@@ -439,6 +443,51 @@ func TestMarket_NewMarket_BookOrders(t *testing.T) {
 
 }
 
+func TestMarket_Reconfigure(t *testing.T) {
+	mkt, _, _, cleanup, err := newTestMarket()
+	if err != nil {
+		t.Fatalf("newTestMarket failure: %v", err)
+	}
+	defer cleanup()
+
+	lotSize, rateStep := mkt.LotSize(), mkt.RateStep()
+	newLotSize, newRateStep := lotSize*2, rateStep*10
+
+	// Compatible with the new lot size and rate step.
+	loKeep := makeLO(buyer3, newRateStep*1000, 4, order.StandingTiF)
+	// 3 lots is not a multiple of the new lot size.
+	loBadQty := makeLO(seller3, newRateStep*2000, 3, order.StandingTiF)
+	// Rate is not a multiple of the new rate step.
+	loBadRate := makeLO(buyer3, newRateStep*1000+rateStep, 2, order.StandingTiF)
+	for _, lo := range []*order.LimitOrder{loKeep, loBadQty, loBadRate} {
+		if !mkt.book.Insert(lo) {
+			t.Fatalf("failed to insert order %v", lo)
+		}
+	}
+
+	if _, err := mkt.Reconfigure(0, newRateStep, 0); err == nil {
+		t.Fatalf("no error for zero lot size")
+	}
+
+	revoked, err := mkt.Reconfigure(newLotSize, newRateStep, 0)
+	if err != nil {
+		t.Fatalf("Reconfigure error: %v", err)
+	}
+	if revoked != 2 {
+		t.Fatalf("expected 2 revoked orders, got %d", revoked)
+	}
+	_, buys, sells := mkt.Book()
+	if len(buys) != 1 || len(sells) != 0 || buys[0].ID() != loKeep.ID() {
+		t.Fatalf("wrong book after reconfigure. %d buys, %d sells", len(buys), len(sells))
+	}
+	if mkt.LotSize() != newLotSize || mkt.RateStep() != newRateStep || mkt.book.LotSize() != newLotSize {
+		t.Fatalf("lot size and rate step not updated")
+	}
+	if !mkt.configChanged {
+		t.Fatalf("config change not flagged for resume")
+	}
+}
+
 func TestMarket_Book(t *testing.T) {
 	mkt, storage, auth, cleanup, err := newTestMarket()
 	if err != nil {
@@ -0,0 +1,29 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package market
+
+import (
+	"decred.org/dcrdex/server/metrics"
+)
+
+var (
+	epochsProcessed = metrics.NewCounterVec("dcrdex_market_epochs_total",
+		"Number of epochs processed, by market.", "market")
+	epochMatches = metrics.NewCounterVec("dcrdex_market_matches_total",
+		"Number of trade matches made, by market.", "market")
+	bookOrders = metrics.NewGaugeVec("dcrdex_market_book_orders",
+		"Number of orders on the book, by market and side.", "market", "side")
+	bookQuantity = metrics.NewGaugeVec("dcrdex_market_book_quantity",
+		"Remaining quantity of the orders on the book in atoms of the base asset, by market and side.", "market", "side")
+)
+
+// recordBookDepth updates the book order and quantity gauges for the market.
+// The bookMtx should be held.
+func (m *Market) recordBookDepth() {
+	name := m.marketInfo.Name
+	bookOrders.With(name, "buy").Set(float64(m.book.BuyCount()))
+	bookOrders.With(name, "sell").Set(float64(m.book.SellCount()))
+	bookQuantity.With(name, "buy").Set(float64(m.book.BuyQuantity()))
+	bookQuantity.With(name, "sell").Set(float64(m.book.SellQuantity()))
+}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"decred.org/dcrdex/dex"
@@ -149,6 +150,7 @@ type MatchSwapper interface {
 type OrderRouter struct {
 	auth        AuthManager
 	assets      map[uint32]*asset.BackedAsset
+	tunnelsMtx  sync.RWMutex
 	tunnels     map[string]MarketTunnel
 	latencyQ    *wait.TickerQueue
 	feeSource   FeeSource
@@ -187,6 +189,49 @@ func (r *OrderRouter) Run(ctx context.Context) {
 	r.latencyQ.Run(ctx)
 }
 
+// AddMarket adds a MarketTunnel for a market that was created after the
+// OrderRouter.
+func (r *OrderRouter) AddMarket(mktName string, tunnel MarketTunnel) error {
+	r.tunnelsMtx.Lock()
+	defer r.tunnelsMtx.Unlock()
+	if _, found := r.tunnels[mktName]; found {
+		return fmt.Errorf("market %s already exists", mktName)
+	}
+	r.tunnels[mktName] = tunnel
+	return nil
+}
+
+// RemoveMarket removes the named market's MarketTunnel. New orders for the
+// market are rejected as for an unknown market.
+func (r *OrderRouter) RemoveMarket(mktName string) error {
+	r.tunnelsMtx.Lock()
+	defer r.tunnelsMtx.Unlock()
+	if _, found := r.tunnels[mktName]; !found {
+		return fmt.Errorf("market %s unknown", mktName)
+	}
+	delete(r.tunnels, mktName)
+	return nil
+}
+
+// tunnel retrieves the named market's MarketTunnel.
+func (r *OrderRouter) tunnel(mktName string) (MarketTunnel, bool) {
+	r.tunnelsMtx.RLock()
+	defer r.tunnelsMtx.RUnlock()
+	tunnel, found := r.tunnels[mktName]
+	return tunnel, found
+}
+
+// marketTunnels returns a copy of the MarketTunnels map.
+func (r *OrderRouter) marketTunnels() map[string]MarketTunnel {
+	r.tunnelsMtx.RLock()
+	defer r.tunnelsMtx.RUnlock()
+	tunnels := make(map[string]MarketTunnel, len(r.tunnels))
+	for name, tunnel := range r.tunnels {
+		tunnels[name] = tunnel
+	}
+	return tunnels
+}
+
 func (r *OrderRouter) respondError(reqID uint64, user account.AccountID, msgErr *msgjson.Error) {
 	log.Debugf("Error going to user %v: %s", user, msgErr)
 	msg, err := msgjson.NewResponse(reqID, nil, msgErr)
@@ -268,6 +313,10 @@ func (r *OrderRouter) handleLimit(user account.AccountID, msg *msgjson.Message)
 		force = order.StandingTiF
 	case msgjson.ImmediateOrderNum:
 		force = order.ImmediateTiF
+	case msgjson.FillOrKillOrderNum:
+		force = order.FillOrKillTiF
+	case msgjson.PostOnlyOrderNum:
+		force = order.PostOnlyTiF
 	default:
 		return msgjson.NewError(msgjson.OrderParameterError, "unknown time-in-force")
 	}
@@ -494,7 +543,7 @@ func (r *OrderRouter) processTrade(oRecord *orderRecord, tunnel MarketTunnel, as
 
 	// Use this as a chance to check user's existing market orders.
 	// TODO: check all markets?
-	for mktName, tunnel := range r.tunnels {
+	for mktName, tunnel := range r.marketTunnels() {
 		unbookedUnfunded := tunnel.CheckUnfilled(assets.funding.ID, oRecord.order.User())
 		for _, badLo := range unbookedUnfunded {
 			log.Infof("Unbooked unfunded order %v from market %s for user %v", badLo, mktName, oRecord.order.User())
@@ -725,7 +774,7 @@ func (r *OrderRouter) CheckParcelLimit(user account.AccountID, targetMarketName
 
 	var otherMarketParcels float64
 	var settlingQty uint64
-	for mktName, mkt := range r.tunnels {
+	for mktName, mkt := range r.marketTunnels() {
 		if mktName == targetMarketName {
 			settlingQty = settlingQuantities[mktName]
 			continue
@@ -881,7 +930,7 @@ func (r *OrderRouter) extractMarket(prefix *msgjson.Prefix) (MarketTunnel, *msgj
 	if err != nil {
 		return nil, msgjson.NewError(msgjson.UnknownMarketError, "asset lookup error: %v", err.Error())
 	}
-	tunnel, found := r.tunnels[mktName]
+	tunnel, found := r.tunnel(mktName)
 	if !found {
 		return nil, msgjson.NewError(msgjson.UnknownMarketError, "unknown market %s", mktName)
 	}
@@ -904,7 +953,7 @@ type SuspendEpoch struct {
 // blocking order submission according to the schedule rather than just checking
 // Market.Running prior to submitting incoming orders to the Market.
 func (r *OrderRouter) SuspendMarket(mktName string, asSoonAs time.Time, persistBooks bool) *SuspendEpoch {
-	mkt, found := r.tunnels[mktName]
+	mkt, found := r.tunnel(mktName)
 	if !found {
 		return nil
 	}
@@ -919,8 +968,9 @@ func (r *OrderRouter) SuspendMarket(mktName string, asSoonAs time.Time, persistB
 // Suspend is like SuspendMarket, but for all known markets.
 func (r *OrderRouter) Suspend(asSoonAs time.Time, persistBooks bool) map[string]*SuspendEpoch {
 
-	suspendTimes := make(map[string]*SuspendEpoch, len(r.tunnels))
-	for name, mkt := range r.tunnels {
+	tunnels := r.marketTunnels()
+	suspendTimes := make(map[string]*SuspendEpoch, len(tunnels))
+	for name, mkt := range tunnels {
 		idx, ts := mkt.Suspend(asSoonAs, persistBooks)
 		suspendTimes[name] = &SuspendEpoch{Idx: idx, End: ts}
 	}
@@ -879,6 +879,21 @@ func TestLimit(t *testing.T) {
 		t.Errorf("Got force %v, expected %v (immediate)", epochOrder.Force, order.ImmediateTiF)
 	}
 
+	// Fill-or-kill and post-only TiF.
+	limit.TiF = msgjson.FillOrKillOrderNum
+	ensureSuccess("valid fill-or-kill order")
+	epochOrder = oRecord.order.(*order.LimitOrder)
+	if epochOrder.Force != order.FillOrKillTiF {
+		t.Errorf("Got force %v, expected %v (fill-or-kill)", epochOrder.Force, order.FillOrKillTiF)
+	}
+	limit.TiF = msgjson.PostOnlyOrderNum
+	ensureSuccess("valid post-only order")
+	epochOrder = oRecord.order.(*order.LimitOrder)
+	if epochOrder.Force != order.PostOnlyTiF {
+		t.Errorf("Got force %v, expected %v (post-only)", epochOrder.Force, order.PostOnlyTiF)
+	}
+	limit.TiF = msgjson.ImmediateOrderNum
+
 	// Test an invalid payload.
 	msg := new(msgjson.Message)
 	msg.Payload = []byte(`?`)
@@ -915,6 +930,8 @@ func TestLimit(t *testing.T) {
 	// Time-in-force incorrectly marked
 	limit.TiF = 0 // not msgjson.StandingOrderNum (1) or msgjson.ImmediateOrderNum (2)
 	ensureErr("bad tif", sendLimit(), msgjson.OrderParameterError)
+	limit.TiF = msgjson.PostOnlyOrderNum + 1
+	ensureErr("unknown tif", sendLimit(), msgjson.OrderParameterError)
 	limit.TiF = msgjson.StandingOrderNum
 
 	// Now switch it to a buy order, and ensure it passes
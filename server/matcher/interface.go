@@ -16,6 +16,8 @@ type Booker interface {
 	Remove(order.OrderID) (*order.LimitOrder, bool)
 	BuyOrders() []*order.LimitOrder
 	SellOrders() []*order.LimitOrder
+	IterateBuys(func(*order.LimitOrder) bool)
+	IterateSells(func(*order.LimitOrder) bool)
 }
 
 // MatchCycleStats is data about the results of a match cycle.
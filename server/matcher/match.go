@@ -101,8 +101,9 @@ type OrdersUpdated struct {
 	CancelsFailed []*order.CancelOrder
 
 	// TradesFailed are unmatched and unbooked (i.e. unmatched market or limit
-	// with immediate time-in-force), or orders with bad lot size. These orders
-	// will be in no other slice.
+	// with immediate time-in-force), fill-or-kill limit orders that could not
+	// be completely filled, post-only limit orders that would have matched, or
+	// orders with bad lot size. These orders will be in no other slice.
 	TradesFailed []order.Order
 
 	// TradesBooked are limit orders from the epoch queue that were put on the
@@ -223,6 +224,17 @@ func (m *Matcher) Match(book Booker, queue []*OrderRevealed) (seed []byte, match
 			updates.TradesCanceled = append(updates.TradesCanceled, removed)
 
 		case *order.LimitOrder:
+			// A post-only order that would take liquidity from the book, or a
+			// fill-or-kill order that cannot be completely filled, fails
+			// without matching.
+			if (o.Force == order.PostOnlyTiF && limitOrderCrosses(book, o)) ||
+				(o.Force == order.FillOrKillTiF && matchableQty(book, o) < o.Remaining()) {
+				nomatched = append(nomatched, q)
+				failed = append(failed, q)
+				updates.TradesFailed = append(updates.TradesFailed, o)
+				break
+			}
+
 			// limit-limit order matching
 			var makers []*order.LimitOrder
 			matchSet := matchLimitOrder(book, o)
@@ -231,7 +243,7 @@ func (m *Matcher) Match(book Booker, queue []*OrderRevealed) (seed []byte, match
 				appendTradeSet(matchSet)
 				makers = matchSet.Makers
 			} else {
-				if o.Force == order.ImmediateTiF {
+				if !o.Force.Standing() {
 					nomatched = append(nomatched, q)
 					// There was no match and TiF is Immediate. Fail.
 					failed = append(failed, q)
@@ -252,8 +264,8 @@ func (m *Matcher) Match(book Booker, queue []*OrderRevealed) (seed []byte, match
 				if o.Filled() > 0 {
 					partial = append(partial, q)
 				}
-				if o.Force == order.StandingTiF {
-					// Standing TiF orders go on the book.
+				if o.Force.Standing() {
+					// Standing and post-only TiF orders go on the book.
 					book.Insert(o)
 					booked = append(booked, q)
 					updates.TradesBooked = append(updates.TradesBooked, o)
@@ -330,6 +342,37 @@ func (m *Matcher) Match(book Booker, queue []*OrderRevealed) (seed []byte, match
 	return
 }
 
+// limitOrderCrosses checks if the limit order would match the best order on
+// the opposite side of the book.
+func limitOrderCrosses(book Booker, ord *order.LimitOrder) bool {
+	if ord.Sell {
+		best := book.BestBuy()
+		return best != nil && ord.Rate <= best.Rate
+	}
+	best := book.BestSell()
+	return best != nil && best.Rate <= ord.Rate
+}
+
+// matchableQty is the total remaining quantity of the book orders that the
+// limit order could match, capped at the order's remaining quantity. The
+// opposite side of the book is walked best-first, stopping at the first order
+// whose rate does not cross or once the order would be completely filled.
+func matchableQty(book Booker, ord *order.LimitOrder) (qty uint64) {
+	want := ord.Remaining()
+	iterate, crosses := book.IterateSells, func(lo *order.LimitOrder) bool { return lo.Rate <= ord.Rate }
+	if ord.Sell {
+		iterate, crosses = book.IterateBuys, func(lo *order.LimitOrder) bool { return ord.Rate <= lo.Rate }
+	}
+	iterate(func(lo *order.LimitOrder) bool {
+		if !crosses(lo) {
+			return false
+		}
+		qty += lo.Remaining()
+		return qty < want
+	})
+	return min(qty, want)
+}
+
 // limit-limit order matching
 func matchLimitOrder(book Booker, ord *order.LimitOrder) (matchSet *order.MatchSet) {
 	amtRemaining := ord.Remaining() // i.e. ord.Quantity - ord.FillAmt
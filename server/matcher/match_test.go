@@ -252,6 +252,22 @@ func (b *BookStub) Remove(orderID order.OrderID) (*order.LimitOrder, bool) {
 func (b *BookStub) BuyOrders() []*order.LimitOrder  { return b.buyOrders }
 func (b *BookStub) SellOrders() []*order.LimitOrder { return b.sellOrders }
 
+func (b *BookStub) IterateBuys(f func(*order.LimitOrder) bool) {
+	for i := len(b.buyOrders) - 1; i >= 0; i-- {
+		if !f(b.buyOrders[i]) {
+			return
+		}
+	}
+}
+
+func (b *BookStub) IterateSells(f func(*order.LimitOrder) bool) {
+	for i := len(b.sellOrders) - 1; i >= 0; i-- {
+		if !f(b.sellOrders[i]) {
+			return
+		}
+	}
+}
+
 var _ Booker = (*BookStub)(nil)
 
 func newLimitOrder(sell bool, rate, quantityLots uint64, force order.TimeInForce, timeOffset int64) *order.LimitOrder {
@@ -999,6 +1015,126 @@ func TestMatch_limitsOnly(t *testing.T) {
 	}
 }
 
+func TestMatch_fillOrKillPostOnly(t *testing.T) {
+	startLogger()
+	me := New()
+
+	tests := []struct {
+		name       string
+		taker      *OrderRevealed
+		wantFilled uint64
+		wantBooked bool
+		wantFailed bool
+	}{
+		{
+			name:       "fill-or-kill buy completely filled",
+			taker:      newLimit(false, 4600000, 3, order.FillOrKillTiF, 0),
+			wantFilled: 3 * LotSize,
+		},
+		{
+			name:       "fill-or-kill buy insufficient liquidity",
+			taker:      newLimit(false, 4600000, 4, order.FillOrKillTiF, 0),
+			wantFailed: true,
+		},
+		{
+			name:       "fill-or-kill sell completely filled",
+			taker:      newLimit(true, 4300000, 7, order.FillOrKillTiF, 0),
+			wantFilled: 7 * LotSize,
+		},
+		{
+			name:       "post-only buy would take",
+			taker:      newLimit(false, 4550000, 1, order.PostOnlyTiF, 0),
+			wantFailed: true,
+		},
+		{
+			name:       "post-only buy booked",
+			taker:      newLimit(false, 4540000, 1, order.PostOnlyTiF, 0),
+			wantBooked: true,
+		},
+		{
+			name:       "post-only sell would take",
+			taker:      newLimit(true, 4500000, 1, order.PostOnlyTiF, 0),
+			wantFailed: true,
+		},
+		{
+			name:       "post-only sell booked",
+			taker:      newLimit(true, 4510000, 1, order.PostOnlyTiF, 0),
+			wantBooked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := newBooker()
+			_, matches, _, failed, _, _, booked, nomatched, _, updates, _ := me.Match(book, []*OrderRevealed{tt.taker})
+			lo := tt.taker.Order.(*order.LimitOrder)
+			if lo.Filled() != tt.wantFilled {
+				t.Fatalf("filled %d, expected %d", lo.Filled(), tt.wantFilled)
+			}
+			if tt.wantFailed {
+				if len(failed) != 1 || len(nomatched) != 1 || len(updates.TradesFailed) != 1 || len(matches) != 0 {
+					t.Fatalf("order not failed without matches")
+				}
+				return
+			}
+			if len(failed) != 0 {
+				t.Fatalf("order failed")
+			}
+			if (len(booked) == 1) != tt.wantBooked {
+				t.Fatalf("booked = %v, expected %v", len(booked) == 1, tt.wantBooked)
+			}
+			if tt.wantBooked && len(matches) != 0 {
+				t.Fatalf("booked order was matched")
+			}
+		})
+	}
+}
+
+func Test_matchableQty(t *testing.T) {
+	var visited int
+	counting := &countingBooker{BookStub: newBooker().(*BookStub), visited: &visited}
+
+	// A buy for 1 lot is covered by the best sell, so the walk stops there.
+	lo := newLimitOrder(false, 4600000, 1, order.FillOrKillTiF, 0)
+	if qty := matchableQty(counting, lo); qty != LotSize {
+		t.Fatalf("matchable qty %d, expected %d", qty, LotSize)
+	}
+	if visited != 1 {
+		t.Fatalf("visited %d book orders, expected 1", visited)
+	}
+
+	// A buy that crosses nothing stops at the best sell.
+	visited = 0
+	lo = newLimitOrder(false, 1, 100, order.FillOrKillTiF, 0)
+	if qty := matchableQty(counting, lo); qty != 0 {
+		t.Fatalf("matchable qty %d, expected 0", qty)
+	}
+	if visited != 1 {
+		t.Fatalf("visited %d book orders, expected 1", visited)
+	}
+}
+
+// countingBooker counts the book orders visited by IterateBuys and
+// IterateSells.
+type countingBooker struct {
+	*BookStub
+	visited *int
+}
+
+func (b *countingBooker) IterateBuys(f func(*order.LimitOrder) bool) {
+	b.BookStub.IterateBuys(func(lo *order.LimitOrder) bool {
+		*b.visited++
+		return f(lo)
+	})
+}
+
+func (b *countingBooker) IterateSells(f func(*order.LimitOrder) bool) {
+	b.BookStub.IterateSells(func(lo *order.LimitOrder) bool {
+		*b.visited++
+		return f(lo)
+	})
+}
+
 func TestMatch_marketSellsOnly(t *testing.T) {
 	// Setup the match package's logger.
 	startLogger()
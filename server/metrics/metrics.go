@@ -0,0 +1,345 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+// Package metrics provides counters and gauges for the DEX server that are
+// exported in the Prometheus text exposition format. Metrics are registered
+// with a Registry when they are created, typically as package-level variables
+// in the package that updates them, and are written out by the admin server's
+// /metrics route.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ContentType is the content type of the text exposition format written by
+// (*Registry).WriteTo.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type metricType string
+
+const (
+	counterType metricType = "counter"
+	gaugeType   metricType = "gauge"
+)
+
+// family is a named metric with zero or more label names, and one sample per
+// combination of label values.
+type family struct {
+	name       string
+	help       string
+	typ        metricType
+	labelNames []string
+
+	mtx     sync.RWMutex
+	samples map[string]*sample // keyed by joined label values
+}
+
+type sample struct {
+	labelValues []string
+	bits        atomic.Uint64 // math.Float64bits
+}
+
+func (s *sample) add(v float64) {
+	for {
+		oldBits := s.bits.Load()
+		newBits := math.Float64bits(math.Float64frombits(oldBits) + v)
+		if s.bits.CompareAndSwap(oldBits, newBits) {
+			return
+		}
+	}
+}
+
+func (s *sample) set(v float64) {
+	s.bits.Store(math.Float64bits(v))
+}
+
+func (s *sample) value() float64 {
+	return math.Float64frombits(s.bits.Load())
+}
+
+func (f *family) sample(labelValues []string) *sample {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metric %s has %d labels, got %d values", f.name, len(f.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	f.mtx.RLock()
+	s := f.samples[key]
+	f.mtx.RUnlock()
+	if s != nil {
+		return s
+	}
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if s = f.samples[key]; s == nil {
+		s = &sample{labelValues: append([]string(nil), labelValues...)}
+		f.samples[key] = s
+	}
+	return s
+}
+
+func (f *family) delete(labelValues []string) {
+	f.mtx.Lock()
+	delete(f.samples, strings.Join(labelValues, "\xff"))
+	f.mtx.Unlock()
+}
+
+func (f *family) write(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.typ)
+
+	f.mtx.RLock()
+	samples := make([]*sample, 0, len(f.samples))
+	for _, s := range f.samples {
+		samples = append(samples, s)
+	}
+	f.mtx.RUnlock()
+	sort.Slice(samples, func(i, j int) bool {
+		a, b := samples[i].labelValues, samples[j].labelValues
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+
+	for _, s := range samples {
+		w.WriteString(f.name)
+		if len(f.labelNames) > 0 {
+			w.WriteByte('{')
+			for i, name := range f.labelNames {
+				if i > 0 {
+					w.WriteByte(',')
+				}
+				fmt.Fprintf(w, "%s=\"%s\"", name, escapeLabelValue(s.labelValues[i]))
+			}
+			w.WriteByte('}')
+		}
+		w.WriteByte(' ')
+		w.WriteString(formatValue(s.value()))
+		w.WriteByte('\n')
+	}
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
+
+// Registry is a set of metric families.
+type Registry struct {
+	mtx      sync.RWMutex
+	families map[string]*family
+}
+
+// NewRegistry is the constructor for a Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		families: make(map[string]*family),
+	}
+}
+
+// DefaultRegistry is the Registry used by the package-level constructors.
+var DefaultRegistry = NewRegistry()
+
+func (r *Registry) register(name, help string, typ metricType, labelNames []string) *family {
+	f := &family{
+		name:       name,
+		help:       help,
+		typ:        typ,
+		labelNames: labelNames,
+		samples:    make(map[string]*sample),
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.families[name] != nil {
+		panic(fmt.Sprintf("metric %s already registered", name))
+	}
+	r.families[name] = f
+	return f
+}
+
+// WriteTo writes all of the registered metrics to w in the Prometheus text
+// exposition format, sorted by name. WriteTo satisfies io.WriterTo.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mtx.RLock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mtx.RUnlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, f := range families {
+		f.write(bw)
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(b []byte) (int, error) {
+	n, err := cw.w.Write(b)
+	cw.n += int64(n)
+	return n, err
+}
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	s *sample
+}
+
+// Inc increments the counter by 1.
+func (c *Counter) Inc() {
+	c.s.add(1)
+}
+
+// Add increments the counter by n.
+func (c *Counter) Add(n uint64) {
+	c.s.add(float64(n))
+}
+
+// Value returns the counter's current value.
+func (c *Counter) Value() float64 {
+	return c.s.value()
+}
+
+// NewCounter creates a Counter registered with the Registry.
+func (r *Registry) NewCounter(name, help string) *Counter {
+	f := r.register(name, help, counterType, nil)
+	return &Counter{f.sample(nil)}
+}
+
+// NewCounter creates a Counter registered with the DefaultRegistry.
+func NewCounter(name, help string) *Counter {
+	return DefaultRegistry.NewCounter(name, help)
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	s *sample
+}
+
+// Set sets the gauge's value.
+func (g *Gauge) Set(v float64) {
+	g.s.set(v)
+}
+
+// Add adds v, which may be negative, to the gauge's value.
+func (g *Gauge) Add(v float64) {
+	g.s.add(v)
+}
+
+// Inc increments the gauge by 1.
+func (g *Gauge) Inc() {
+	g.s.add(1)
+}
+
+// Dec decrements the gauge by 1.
+func (g *Gauge) Dec() {
+	g.s.add(-1)
+}
+
+// Value returns the gauge's current value.
+func (g *Gauge) Value() float64 {
+	return g.s.value()
+}
+
+// NewGauge creates a Gauge registered with the Registry.
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	f := r.register(name, help, gaugeType, nil)
+	return &Gauge{f.sample(nil)}
+}
+
+// NewGauge creates a Gauge registered with the DefaultRegistry.
+func NewGauge(name, help string) *Gauge {
+	return DefaultRegistry.NewGauge(name, help)
+}
+
+// CounterVec is a set of Counters with the same name, partitioned by label
+// values.
+type CounterVec struct {
+	f *family
+}
+
+// With returns the Counter for the label values, which must be provided in the
+// order of the label names given to NewCounterVec.
+func (v *CounterVec) With(labelValues ...string) *Counter {
+	return &Counter{v.f.sample(labelValues)}
+}
+
+// Delete removes the Counter for the label values.
+func (v *CounterVec) Delete(labelValues ...string) {
+	v.f.delete(labelValues)
+}
+
+// NewCounterVec creates a CounterVec registered with the Registry.
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{r.register(name, help, counterType, labelNames)}
+}
+
+// NewCounterVec creates a CounterVec registered with the DefaultRegistry.
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return DefaultRegistry.NewCounterVec(name, help, labelNames...)
+}
+
+// GaugeVec is a set of Gauges with the same name, partitioned by label values.
+type GaugeVec struct {
+	f *family
+}
+
+// With returns the Gauge for the label values, which must be provided in the
+// order of the label names given to NewGaugeVec.
+func (v *GaugeVec) With(labelValues ...string) *Gauge {
+	return &Gauge{v.f.sample(labelValues)}
+}
+
+// Delete removes the Gauge for the label values.
+func (v *GaugeVec) Delete(labelValues ...string) {
+	v.f.delete(labelValues)
+}
+
+// NewGaugeVec creates a GaugeVec registered with the Registry.
+func (r *Registry) NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{r.register(name, help, gaugeType, labelNames)}
+}
+
+// NewGaugeVec creates a GaugeVec registered with the DefaultRegistry.
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	return DefaultRegistry.NewGaugeVec(name, help, labelNames...)
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	c := r.NewCounter("test_events_total", "Number of events.")
+	c.Inc()
+	c.Add(4)
+
+	g := r.NewGauge("test_depth", "Current depth.\nSecond line.")
+	g.Set(10)
+	g.Dec()
+	g.Add(-0.5)
+
+	cv := r.NewCounterVec("test_rejections_total", "Rejections by IP.", "ip", "limiter")
+	cv.With("127.0.0.1", "http").Inc()
+	cv.With("127.0.0.1", "http").Inc()
+	cv.With("10.0.0.1", "ws").Inc()
+	cv.With(`weird"ip\`, "ws").Inc()
+
+	gv := r.NewGaugeVec("test_orders", "Orders by side.", "market", "side")
+	gv.With("dcr_btc", "sell").Set(3)
+	gv.With("dcr_btc", "buy").Set(2)
+	gv.With("ltc_btc", "buy").Set(1)
+	gv.Delete("ltc_btc", "buy")
+
+	var sb strings.Builder
+	n, err := r.WriteTo(&sb)
+	if err != nil {
+		t.Fatalf("WriteTo error: %v", err)
+	}
+	if int(n) != sb.Len() {
+		t.Fatalf("WriteTo reported %d bytes, wrote %d", n, sb.Len())
+	}
+
+	exp := `# HELP test_depth Current depth.\nSecond line.
+# TYPE test_depth gauge
+test_depth 8.5
+# HELP test_events_total Number of events.
+# TYPE test_events_total counter
+test_events_total 5
+# HELP test_orders Orders by side.
+# TYPE test_orders gauge
+test_orders{market="dcr_btc",side="buy"} 2
+test_orders{market="dcr_btc",side="sell"} 3
+# HELP test_rejections_total Rejections by IP.
+# TYPE test_rejections_total counter
+test_rejections_total{ip="10.0.0.1",limiter="ws"} 1
+test_rejections_total{ip="127.0.0.1",limiter="http"} 2
+test_rejections_total{ip="weird\"ip\\",limiter="ws"} 1
+`
+	if sb.String() != exp {
+		t.Fatalf("wrong output. expected\n%s\ngot\n%s", exp, sb.String())
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("test_total", "")
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("no panic for duplicate metric")
+		}
+	}()
+	r.NewGauge("test_total", "")
+}
+
+func TestWrongLabelCount(t *testing.T) {
+	r := NewRegistry()
+	v := r.NewCounterVec("test_total", "", "a", "b")
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("no panic for wrong label count")
+		}
+	}()
+	v.With("a")
+}
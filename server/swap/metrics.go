@@ -0,0 +1,19 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package swap
+
+import (
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/metrics"
+)
+
+var activeMatches = metrics.NewGaugeVec("dcrdex_swap_active_matches",
+	"Number of matches being tracked by the swapper, by match status.", "status")
+
+// recordStatusChange moves a match from the old status to the new status in
+// the active match gauges.
+func recordStatusChange(oldStatus, newStatus order.MatchStatus) {
+	activeMatches.With(oldStatus.String()).Dec()
+	activeMatches.With(newStatus.String()).Inc()
+}
@@ -0,0 +1,953 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package swap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/dex/wait"
+	"decred.org/dcrdex/server/account"
+	"decred.org/dcrdex/server/asset"
+	"decred.org/dcrdex/server/comms"
+	"decred.org/dcrdex/server/db"
+)
+
+// Private swaps settle a match with adaptor signatures instead of a hashed
+// timelock contract, so no secret hash is visible on either chain. The match
+// status follows the HTLC sequence, with a key exchange before the maker's
+// swap, and an adaptor signature exchange before the maker's redeem:
+//
+//  1. NewlyMatched: Both parties send their public keys (private_keys). Once
+//     both are received, each is relayed to the counterparty
+//     (counterparty_keys).
+//  2. NewlyMatched -> MakerSwapCast: The maker broadcasts their swap
+//     (private_init), which is relayed to the taker (private_audit).
+//  3. MakerSwapCast -> TakerSwapCast: After the maker's swap confirms, the
+//     taker broadcasts their swap, and includes their unsigned redemption of
+//     the maker's swap.
+//  4. TakerSwapCast: After the taker's swap confirms, the maker generates an
+//     adaptor secret and sends their adaptor signatures (adaptor_sigs), which
+//     are relayed to the taker (counterparty_adaptor_sigs). The taker responds
+//     with a public key tweaked adaptor signature for the maker's redemption.
+//  5. TakerSwapCast -> MakerRedeemed: The maker redeems (private_redeem),
+//     revealing the adaptor secret to the taker (private_redemption).
+//  6. MakerRedeemed -> MatchComplete: The taker recovers the secret from the
+//     maker's redemption and redeems.
+//
+// The keys, adaptor signatures and the taker's unsigned redemption are stored
+// with the match, so private swaps are restored on startup like HTLC swaps.
+
+// SetPrivateMarket sets whether the matches of the market with the base and
+// quote assets are settled with private swaps. Matches that are already being
+// settled are not affected.
+func (s *Swapper) SetPrivateMarket(base, quote uint32, private bool) {
+	s.privateMtx.Lock()
+	defer s.privateMtx.Unlock()
+	if private {
+		s.privateMarkets[[2]uint32{base, quote}] = true
+	} else {
+		delete(s.privateMarkets, [2]uint32{base, quote})
+	}
+}
+
+// isPrivateMarket is true if the market's matches are settled with private
+// swaps.
+func (s *Swapper) isPrivateMarket(base, quote uint32) bool {
+	s.privateMtx.RLock()
+	defer s.privateMtx.RUnlock()
+	return s.privateMarkets[[2]uint32{base, quote}]
+}
+
+// privateMatch is the state of the steps of a private swap that are not a part
+// of the HTLC sequence. The fields are protected by the matchTracker's mtx.
+type privateMatch struct {
+	makerKeys *msgjson.PrivateKeys
+	takerKeys *msgjson.PrivateKeys
+	// keysTime is when both parties' keys were received.
+	keysTime time.Time
+
+	// takerRedeem is the taker's unsigned redemption of the maker's swap,
+	// received with the taker's swap.
+	takerRedeem msgjson.Bytes
+
+	makerSigs     *msgjson.AdaptorSigs
+	makerSigsTime time.Time
+	takerSigs     *msgjson.AdaptorSigs
+	takerSigsTime time.Time
+}
+
+// privateSwapData is the stored form of a privateMatch. Times are in
+// milliseconds, with zero for a step that is not done.
+type privateSwapData struct {
+	MakerKeys     *msgjson.PrivateKeys `json:"makerKeys,omitempty"`
+	TakerKeys     *msgjson.PrivateKeys `json:"takerKeys,omitempty"`
+	KeysTime      int64                `json:"keysTime,omitempty"`
+	TakerRedeem   msgjson.Bytes        `json:"takerRedeem,omitempty"`
+	MakerSigs     *msgjson.AdaptorSigs `json:"makerSigs,omitempty"`
+	MakerSigsTime int64                `json:"makerSigsTime,omitempty"`
+	TakerSigs     *msgjson.AdaptorSigs `json:"takerSigs,omitempty"`
+	TakerSigsTime int64                `json:"takerSigsTime,omitempty"`
+}
+
+// unixMs is the time in milliseconds, or zero for the zero time.
+func unixMs(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// msTime is the inverse of unixMs.
+func msTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+// encode serializes the privateMatch for storage. The encoding is never empty,
+// so stored data also flags the match as a private swap.
+func (pm *privateMatch) encode() ([]byte, error) {
+	return json.Marshal(&privateSwapData{
+		MakerKeys:     pm.makerKeys,
+		TakerKeys:     pm.takerKeys,
+		KeysTime:      unixMs(pm.keysTime),
+		TakerRedeem:   pm.takerRedeem,
+		MakerSigs:     pm.makerSigs,
+		MakerSigsTime: unixMs(pm.makerSigsTime),
+		TakerSigs:     pm.takerSigs,
+		TakerSigsTime: unixMs(pm.takerSigsTime),
+	})
+}
+
+// decodePrivateMatch deserializes a privateMatch encoded with encode.
+func decodePrivateMatch(b []byte) (*privateMatch, error) {
+	var psd privateSwapData
+	if err := json.Unmarshal(b, &psd); err != nil {
+		return nil, err
+	}
+	return &privateMatch{
+		makerKeys:     psd.MakerKeys,
+		takerKeys:     psd.TakerKeys,
+		keysTime:      msTime(psd.KeysTime),
+		takerRedeem:   psd.TakerRedeem,
+		makerSigs:     psd.MakerSigs,
+		makerSigsTime: msTime(psd.MakerSigsTime),
+		takerSigs:     psd.TakerSigs,
+		takerSigsTime: msTime(psd.TakerSigsTime),
+	}, nil
+}
+
+// contracts are the private contracts that the maker's and taker's swaps pay
+// to. Each swap pays to the counterparty's redeem key and the swapper's refund
+// key. The keys must have been exchanged. The matchTracker's mtx must be held
+// for reads.
+func (pm *privateMatch) contracts(matchTime time.Time, lockTimeMaker, lockTimeTaker time.Duration) (maker, taker *asset.PrivateContract, err error) {
+	if pm.makerKeys == nil || pm.takerKeys == nil {
+		return nil, nil, errors.New("keys not exchanged")
+	}
+	maker = &asset.PrivateContract{
+		RedeemKey: pm.takerKeys.RedeemKey,
+		RefundKey: pm.makerKeys.RefundKey,
+		LockTime:  encode.DropMilliseconds(matchTime.Add(lockTimeMaker)),
+	}
+	taker = &asset.PrivateContract{
+		RedeemKey: pm.makerKeys.RedeemKey,
+		RefundKey: pm.takerKeys.RefundKey,
+		LockTime:  encode.DropMilliseconds(matchTime.Add(lockTimeTaker)),
+	}
+	return maker, taker, nil
+}
+
+// privateContract locates a private swap output that pays to the private
+// contract.
+func privateContract(backend asset.Backend, coinID []byte, pc *asset.PrivateContract) (*asset.Contract, error) {
+	chain, ok := backend.(asset.PrivateSwapper)
+	if !ok {
+		return nil, errors.New("asset does not support private swaps")
+	}
+	coin, err := chain.PrivateSwapCoin(coinID, pc)
+	if err != nil {
+		return nil, err
+	}
+	return &asset.Contract{
+		Coin:     coin,
+		LockTime: pc.LockTime,
+	}, nil
+}
+
+// savePrivate stores the state of the private swap. The matchTracker's mtx
+// must be held.
+func (s *Swapper) savePrivate(mt *matchTracker) error {
+	b, err := mt.private.encode()
+	if err != nil {
+		return err
+	}
+	return s.storage.SavePrivateData(db.MatchID(mt.Match), b)
+}
+
+// swapStartTime is the reference time for the maker's swap deadline. For a
+// private swap, the maker's broadcast timeout starts when the keys are
+// exchanged. The matchTracker's mtx must be held for reads.
+func (mt *matchTracker) swapStartTime() time.Time {
+	if mt.private != nil && !mt.private.keysTime.IsZero() {
+		return mt.private.keysTime
+	}
+	return mt.time
+}
+
+// privateStepTime is the reference time for the deadline of the adaptor
+// signature exchange of a private swap in TakerSwapCast. The zero time is
+// returned if the maker has not sent their adaptor signatures, in which case
+// the maker's deadline is relative to the taker's swap confirmation. The
+// matchTracker's mtx must be held for reads.
+func (mt *matchTracker) privateStepTime() time.Time {
+	if mt.private == nil || mt.Status != order.TakerSwapCast {
+		return time.Time{}
+	}
+	if mt.private.takerSigs != nil {
+		return mt.private.takerSigsTime
+	}
+	return mt.private.makerSigsTime
+}
+
+// privateTakerActs is true if a private swap is waiting on the taker at a step
+// where the maker would act in the HTLC sequence.
+func (mt *matchTracker) privateTakerActs() bool {
+	if mt.private == nil {
+		return false
+	}
+	switch mt.Status {
+	case order.NewlyMatched:
+		return mt.private.makerKeys != nil && mt.private.takerKeys == nil
+	case order.TakerSwapCast:
+		return mt.private.makerSigs != nil && mt.private.takerSigs == nil
+	}
+	return false
+}
+
+// privateTakerFault checks if the taker is at fault for a private swap that
+// failed at a step where the maker would be at fault in the HTLC sequence. The
+// outcome and a reference time are returned.
+func (mt *matchTracker) privateTakerFault() (takerFault bool, outcome db.Outcome, refTime time.Time) {
+	if !mt.privateTakerActs() {
+		return false, db.OutcomeInvalid, time.Time{}
+	}
+	if mt.Status == order.NewlyMatched {
+		return true, db.OutcomeNoSwapAsTaker, mt.Epoch.End()
+	}
+	// The taker's refund adaptor signature is required for the maker's
+	// redeem, which the taker also needs to redeem.
+	return true, db.OutcomeNoRedeemAsTaker, mt.takerStatus.swapTime
+}
+
+// privateOrder finds the private swap match, and checks that the order is part
+// of the match and belongs to the user.
+func (s *Swapper) privateOrder(user account.AccountID, oidB, midB []byte) (mt *matchTracker, isMaker bool, rpcErr *msgjson.Error) {
+	if len(midB) != order.MatchIDSize || len(oidB) != order.OrderIDSize {
+		return nil, false, &msgjson.Error{
+			Code:    msgjson.RPCParseError,
+			Message: "invalid order or match ID",
+		}
+	}
+	var mid order.MatchID
+	copy(mid[:], midB)
+	s.matchMtx.RLock()
+	mt, found := s.matches[mid]
+	s.matchMtx.RUnlock()
+	if !found {
+		return nil, false, &msgjson.Error{
+			Code:    msgjson.RPCUnknownMatch,
+			Message: "unknown match ID",
+		}
+	}
+	if mt.private == nil {
+		return nil, false, &msgjson.Error{
+			Code:    msgjson.SettlementSequenceError,
+			Message: "not a private swap",
+		}
+	}
+	var oid order.OrderID
+	copy(oid[:], oidB)
+	switch {
+	case oid == mt.Maker.ID() && user == mt.Maker.User():
+		isMaker = true
+	case oid == mt.Taker.ID() && user == mt.Taker.User():
+	default:
+		return nil, false, &msgjson.Error{
+			Code:    msgjson.OrderParameterError,
+			Message: "order is not part of the match",
+		}
+	}
+	return mt, isMaker, nil
+}
+
+// relay sends a DEX-originating request to a party in a private swap. The
+// acknowledgement is processed with processAck.
+func (s *Swapper) relay(route string, ack *messageAcker, timeout time.Duration) {
+	s.authMgr.Sign(ack.params)
+	req, err := msgjson.NewRequest(comms.NextID(), route, ack.params)
+	if err != nil {
+		log.Errorf("error creating %s request: %v", route, err)
+		return
+	}
+	matchID := ack.match.ID()
+	log.Debugf("Sending '%s' request to user %v (%s) for match %v", route, ack.user,
+		makerTaker(ack.isMaker), matchID)
+	err = s.authMgr.RequestWithTimeout(ack.user, req, func(_ comms.Link, resp *msgjson.Message) {
+		s.processAck(resp, ack)
+	}, timeout, func() {
+		log.Infof("Timeout waiting for '%s' acknowledgement from user %v (%s) for match %v",
+			route, ack.user, makerTaker(ack.isMaker), matchID)
+	})
+	if err != nil {
+		log.Debugf("Couldn't send '%s' request to user %v (%s) for match %v", route, ack.user,
+			makerTaker(ack.isMaker), matchID)
+	}
+}
+
+// handlePrivateKeys handles the 'private_keys' request from a user. Once both
+// parties' keys are received, they are relayed to the counterparties.
+func (s *Swapper) handlePrivateKeys(user account.AccountID, msg *msgjson.Message) *msgjson.Error {
+	params := new(msgjson.PrivateKeys)
+	err := msg.Unmarshal(&params)
+	if err != nil || params == nil {
+		return &msgjson.Error{
+			Code:    msgjson.RPCParseError,
+			Message: "Error decoding 'private_keys' method params",
+		}
+	}
+	if rpcErr := s.authUser(user, params); rpcErr != nil {
+		return rpcErr
+	}
+	if len(params.RedeemKey) == 0 || len(params.RefundKey) == 0 {
+		return &msgjson.Error{
+			Code:    msgjson.RPCParseError,
+			Message: "missing keys",
+		}
+	}
+	mt, isMaker, rpcErr := s.privateOrder(user, params.OrderID, params.MatchID)
+	if rpcErr != nil {
+		return rpcErr
+	}
+
+	mt.mtx.Lock()
+	if mt.Status != order.NewlyMatched {
+		mt.mtx.Unlock()
+		return &msgjson.Error{
+			Code:    msgjson.SettlementSequenceError,
+			Message: "keys already exchanged",
+		}
+	}
+	keys := &mt.private.takerKeys
+	if isMaker {
+		keys = &mt.private.makerKeys
+	}
+	if *keys != nil {
+		mt.mtx.Unlock()
+		return &msgjson.Error{
+			Code:    msgjson.DuplicateRequestError,
+			Message: "keys already received",
+		}
+	}
+	*keys = params
+	makerKeys, takerKeys := mt.private.makerKeys, mt.private.takerKeys
+	exchanged := makerKeys != nil && takerKeys != nil
+	if exchanged {
+		mt.private.keysTime = unixMsNow()
+	}
+	if err := s.savePrivate(mt); err != nil {
+		*keys, mt.private.keysTime = nil, time.Time{}
+		mt.mtx.Unlock()
+		log.Errorf("saving private keys (match id=%v, maker=%v) failed: %v", mt.ID(), isMaker, err)
+		return &msgjson.Error{
+			Code:    msgjson.RPCInternalError,
+			Message: "internal server error",
+		}
+	}
+	mt.mtx.Unlock()
+
+	log.Debugf("handlePrivateKeys: keys received from user %v (%s) for match %v",
+		user, makerTaker(isMaker), mt.ID())
+
+	matchID := mt.ID()
+	s.authMgr.Sign(params)
+	s.respondSuccess(msg.ID, user, &msgjson.Acknowledgement{
+		MatchID: matchID[:],
+		Sig:     params.Sig,
+	})
+
+	if !exchanged {
+		return nil
+	}
+	relayKeys := func(to order.Order, keys *msgjson.PrivateKeys, toMaker bool) {
+		s.relay(msgjson.CounterpartyKeysRoute, &messageAcker{
+			user:  to.User(),
+			match: mt,
+			params: &msgjson.PrivateKeys{
+				OrderID:   idToBytes(to.ID()),
+				MatchID:   matchID[:],
+				RedeemKey: keys.RedeemKey,
+				RefundKey: keys.RefundKey,
+			},
+			isMaker: toMaker,
+		}, s.bTimeout)
+	}
+	relayKeys(mt.Maker, takerKeys, true)
+	relayKeys(mt.Taker, makerKeys, false)
+	return nil
+}
+
+// handlePrivateInit handles the 'private_init' request from a user, which
+// informs the DEX of a newly broadcast private swap transaction. The swap
+// output is located and checked by processPrivateInit.
+func (s *Swapper) handlePrivateInit(user account.AccountID, msg *msgjson.Message) *msgjson.Error {
+	s.handlerMtx.RLock()
+	defer s.handlerMtx.RUnlock() // block shutdown until registered with latencyQ
+	if s.stop {
+		return &msgjson.Error{
+			Code:    msgjson.TryAgainLaterError,
+			Message: "The swapper is stopping. Try again later.",
+		}
+	}
+
+	params := new(msgjson.PrivateInit)
+	err := msg.Unmarshal(&params)
+	if err != nil || params == nil {
+		return &msgjson.Error{
+			Code:    msgjson.RPCParseError,
+			Message: "Error decoding 'private_init' method params",
+		}
+	}
+	if rpcErr := s.authUser(user, params); rpcErr != nil {
+		return rpcErr
+	}
+	if len(params.MatchID) != order.MatchIDSize {
+		return &msgjson.Error{
+			Code:    msgjson.RPCParseError,
+			Message: "Invalid 'matchid' in 'private_init' message",
+		}
+	}
+	var matchID order.MatchID
+	copy(matchID[:], params.MatchID)
+	stepInfo, rpcErr := s.step(user, matchID)
+	if rpcErr != nil {
+		return rpcErr
+	}
+	mt := stepInfo.match
+	if mt.private == nil {
+		return &msgjson.Error{
+			Code:    msgjson.SettlementSequenceError,
+			Message: "not a private swap",
+		}
+	}
+
+	switch stepInfo.step {
+	case order.NewlyMatched:
+		mt.mtx.RLock()
+		exchanged := !mt.private.keysTime.IsZero()
+		mt.mtx.RUnlock()
+		if !exchanged {
+			return &msgjson.Error{
+				Code:    msgjson.SettlementSequenceError,
+				Message: "keys not exchanged",
+			}
+		}
+	case order.MakerSwapCast:
+		if len(params.UnsignedRedeem) == 0 {
+			return &msgjson.Error{
+				Code:    msgjson.RPCParseError,
+				Message: "missing unsigned redeem",
+			}
+		}
+	default:
+		return &msgjson.Error{
+			Code:    msgjson.SettlementSequenceError,
+			Message: "swap already provided",
+		}
+	}
+	if !stepInfo.actor.status.startSwapSearch() {
+		return &msgjson.Error{
+			Code:    msgjson.DuplicateRequestError,
+			Message: "already received a swap, search in progress",
+		}
+	}
+
+	coinStr, err := stepInfo.asset.Backend.ValidateCoinID(params.CoinID)
+	if err != nil {
+		stepInfo.actor.status.endSwapSearch()
+		return &msgjson.Error{
+			Code:    msgjson.ContractError,
+			Message: "invalid swap coin ID",
+		}
+	}
+
+	expireTime := time.Now().Add(s.txWaitExpiration).UTC()
+	log.Debugf("Allowing until %v (%v) to locate private swap from %v (%v), match %v, tx %s (%s)",
+		expireTime, time.Until(expireTime), makerTaker(stepInfo.actor.isMaker),
+		stepInfo.step, matchID, coinStr, stepInfo.asset.Symbol)
+
+	s.latencyQ.Wait(&wait.Waiter{
+		Expiration: expireTime,
+		TryFunc: func() wait.TryDirective {
+			return s.processPrivateInit(msg, params, stepInfo)
+		},
+		ExpireFunc: func() {
+			stepInfo.actor.status.endSwapSearch() // allow init retries
+			s.respondError(msg.ID, user, msgjson.TransactionUndiscovered,
+				fmt.Sprintf("failed to find swap coin %v", coinStr))
+		},
+	})
+	return nil
+}
+
+// processPrivateInit locates and checks a private swap output. The output's
+// script commits to the parties' keys and the required lock time, so the
+// expected script is rebuilt from the exchanged keys and compared. The Swapper
+// also checks the value and fee rate, and tracks the swap's confirmations.
+// This method is run as a coin waiter.
+func (s *Swapper) processPrivateInit(msg *msgjson.Message, params *msgjson.PrivateInit, stepInfo *stepInformation) wait.TryDirective {
+	actor, counterParty := stepInfo.actor, stepInfo.counterParty
+	fail := func(code int, errMsg string) wait.TryDirective {
+		actor.status.endSwapSearch() // allow client retry even before notifying him
+		s.respondError(msg.ID, actor.user, code, errMsg)
+		return wait.DontTryAgain
+	}
+
+	chain, ok := stepInfo.asset.Backend.(asset.PrivateSwapper)
+	if !ok { // checked on market creation
+		return fail(msgjson.ContractError, "asset does not support private swaps")
+	}
+
+	mt := stepInfo.match
+	mt.mtx.RLock()
+	makerContract, takerContract, err := mt.private.contracts(mt.matchTime, s.lockTimeMaker, s.lockTimeTaker)
+	mt.mtx.RUnlock()
+	if err != nil { // checked by handlePrivateInit
+		return fail(msgjson.SettlementSequenceError, err.Error())
+	}
+	pc := takerContract
+	if actor.isMaker {
+		pc = makerContract
+	}
+	lockTime := pc.LockTime
+	coin, err := chain.PrivateSwapCoin(params.CoinID, pc)
+	if err != nil {
+		if errors.Is(err, asset.CoinNotFoundError) {
+			return wait.TryAgain
+		}
+		log.Warnf("Private swap error encountered for match %s, actor %s using coin ID %v: %v",
+			stepInfo.match.ID(), actor.user, params.CoinID, err)
+		return fail(msgjson.ContractError, fmt.Sprintf("swap error encountered: %v", err))
+	}
+	if coin.Value() != stepInfo.checkVal {
+		return fail(msgjson.ContractError,
+			fmt.Sprintf("expected swap value to be %d, got %d", stepInfo.checkVal, coin.Value()))
+	}
+	reqFeeRate := stepInfo.match.FeeRateQuote
+	if stepInfo.isBaseAsset {
+		reqFeeRate = stepInfo.match.FeeRateBase
+	}
+	if !stepInfo.asset.Backend.ValidateFeeRate(coin, reqFeeRate) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		confs, err := coin.Confirmations(ctx)
+		cancel()
+		if err != nil || confs < 1 {
+			return fail(msgjson.ContractError, "low tx fee")
+		}
+		log.Infof("Private swap txn %v (%s) with low fee rate (%v required), accepted with %d confirmations.",
+			coin, stepInfo.asset.Symbol, reqFeeRate, confs)
+	}
+
+	if remain := time.Until(lockTime); remain < 0 {
+		return fail(msgjson.ContractError, fmt.Sprintf("lock time passed %s ago", remain))
+	}
+	contract := &asset.Contract{
+		Coin:     coin,
+		LockTime: lockTime,
+		TxData:   params.TxData,
+	}
+
+	swapTime := unixMsNow()
+	matchID := stepInfo.match.ID()
+	storFn := s.storage.SaveContractB
+	if actor.isMaker {
+		storFn = s.storage.SaveContractA
+	}
+	swapTimeMs := swapTime.UnixMilli()
+	if !actor.isMaker {
+		// The maker needs the taker's unsigned redeem to create their adaptor
+		// signatures, so it is stored before the swap.
+		mt.mtx.Lock()
+		mt.private.takerRedeem = params.UnsignedRedeem
+		err = s.savePrivate(mt)
+		mt.mtx.Unlock()
+		if err != nil {
+			log.Errorf("saving unsigned redeem (match id=%v) failed: %v", matchID, err)
+			s.respondError(msg.ID, actor.user, msgjson.RPCInternalError, "internal server error")
+			return wait.TryAgain
+		}
+	}
+	if err = storFn(db.MatchID(stepInfo.match.Match), nil, params.CoinID, swapTimeMs); err != nil {
+		log.Errorf("saving private swap (match id=%v, maker=%v) failed: %v", matchID, actor.isMaker, err)
+		s.respondError(msg.ID, actor.user, msgjson.RPCInternalError, "internal server error")
+		return wait.TryAgain
+	}
+
+	s.matchMtx.RLock()
+	if _, found := s.matches[matchID]; !found {
+		s.matchMtx.RUnlock()
+		log.Errorf("Private swap txn located after match was revoked (match id=%v, maker=%v)",
+			matchID, actor.isMaker)
+		return fail(msgjson.ContractError, "match already revoked due to inaction")
+	}
+
+	actor.status.mtx.Lock()
+	actor.status.swap = contract
+	actor.status.swapTime = swapTime
+	actor.status.mtx.Unlock()
+
+	stepInfo.match.mtx.Lock()
+	recordStatusChange(stepInfo.match.Status, stepInfo.nextStep)
+	stepInfo.match.Status = stepInfo.nextStep
+	stepInfo.match.mtx.Unlock()
+	s.matchMtx.RUnlock()
+
+	actor.status.endSwapSearch()
+
+	log.Debugf("processPrivateInit: valid private swap %v (%s) received at %v from user %v (%s) for match %v, "+
+		"swapStatus %v => %v", coin, stepInfo.asset.Symbol, swapTime, actor.user,
+		makerTaker(actor.isMaker), matchID, stepInfo.step, stepInfo.nextStep)
+
+	s.authMgr.Sign(params)
+	s.respondSuccess(msg.ID, actor.user, &msgjson.Acknowledgement{
+		MatchID: matchID[:],
+		Sig:     params.Sig,
+	})
+
+	// The counterparty may wait up to the broadcast timeout to locate the swap
+	// before responding.
+	s.relay(msgjson.PrivateAuditRoute, &messageAcker{
+		user:  counterParty.user,
+		match: stepInfo.match,
+		params: &msgjson.PrivateAudit{
+			PrivateInit: msgjson.PrivateInit{
+				OrderID:        idToBytes(counterParty.order.ID()),
+				MatchID:        matchID[:],
+				CoinID:         params.CoinID,
+				TxData:         params.TxData,
+				UnsignedRedeem: params.UnsignedRedeem,
+			},
+			Time: uint64(swapTimeMs),
+		},
+		isMaker: counterParty.isMaker,
+		isAudit: true,
+	}, s.bTimeout)
+
+	return wait.DontTryAgain
+}
+
+// handleAdaptorSigs handles the 'adaptor_sigs' request from a user. The maker
+// sends their adaptor signatures first, and then the taker. Each is relayed to
+// the counterparty.
+func (s *Swapper) handleAdaptorSigs(user account.AccountID, msg *msgjson.Message) *msgjson.Error {
+	params := new(msgjson.AdaptorSigs)
+	err := msg.Unmarshal(&params)
+	if err != nil || params == nil {
+		return &msgjson.Error{
+			Code:    msgjson.RPCParseError,
+			Message: "Error decoding 'adaptor_sigs' method params",
+		}
+	}
+	if rpcErr := s.authUser(user, params); rpcErr != nil {
+		return rpcErr
+	}
+	mt, isMaker, rpcErr := s.privateOrder(user, params.OrderID, params.MatchID)
+	if rpcErr != nil {
+		return rpcErr
+	}
+	if len(params.RefundSig) == 0 || (isMaker && (len(params.AdaptorPub) == 0 ||
+		len(params.RedeemSig) == 0 || len(params.UnsignedRedeem) == 0)) {
+		return &msgjson.Error{
+			Code:    msgjson.RPCParseError,
+			Message: "missing adaptor signature data",
+		}
+	}
+
+	mt.mtx.Lock()
+	if mt.Status != order.TakerSwapCast {
+		mt.mtx.Unlock()
+		return &msgjson.Error{
+			Code:    msgjson.SettlementSequenceError,
+			Message: "adaptor signatures are exchanged after the taker's swap",
+		}
+	}
+	pm := mt.private
+	switch {
+	case isMaker && pm.makerSigs == nil:
+		pm.makerSigs, pm.makerSigsTime = params, unixMsNow()
+	case !isMaker && pm.makerSigs != nil && pm.takerSigs == nil:
+		pm.takerSigs, pm.takerSigsTime = params, unixMsNow()
+	case !isMaker && pm.makerSigs == nil:
+		mt.mtx.Unlock()
+		return &msgjson.Error{
+			Code:    msgjson.SettlementSequenceError,
+			Message: "expected maker's adaptor signatures first",
+		}
+	default:
+		mt.mtx.Unlock()
+		return &msgjson.Error{
+			Code:    msgjson.DuplicateRequestError,
+			Message: "adaptor signatures already received",
+		}
+	}
+	if err := s.savePrivate(mt); err != nil {
+		if isMaker {
+			pm.makerSigs, pm.makerSigsTime = nil, time.Time{}
+		} else {
+			pm.takerSigs, pm.takerSigsTime = nil, time.Time{}
+		}
+		mt.mtx.Unlock()
+		log.Errorf("saving adaptor signatures (match id=%v, maker=%v) failed: %v", mt.ID(), isMaker, err)
+		return &msgjson.Error{
+			Code:    msgjson.RPCInternalError,
+			Message: "internal server error",
+		}
+	}
+	mt.mtx.Unlock()
+
+	log.Debugf("handleAdaptorSigs: adaptor signatures received from user %v (%s) for match %v",
+		user, makerTaker(isMaker), mt.ID())
+
+	matchID := mt.ID()
+	relay := &msgjson.AdaptorSigs{
+		MatchID:        matchID[:],
+		AdaptorPub:     params.AdaptorPub,
+		RedeemSig:      params.RedeemSig,
+		RefundSig:      params.RefundSig,
+		UnsignedRedeem: params.UnsignedRedeem,
+	}
+	s.authMgr.Sign(params)
+	s.respondSuccess(msg.ID, user, &msgjson.Acknowledgement{
+		MatchID: matchID[:],
+		Sig:     params.Sig,
+	})
+
+	to := order.Order(mt.Taker)
+	if !isMaker {
+		to = mt.Maker
+	}
+	relay.OrderID = idToBytes(to.ID())
+	s.relay(msgjson.CounterpartyAdaptorSigsRoute, &messageAcker{
+		user:    to.User(),
+		match:   mt,
+		params:  relay,
+		isMaker: !isMaker,
+	}, s.bTimeout)
+	return nil
+}
+
+// handlePrivateRedeem handles the 'private_redeem' request from a user. The
+// redemption is located and checked by processPrivateRedeem.
+func (s *Swapper) handlePrivateRedeem(user account.AccountID, msg *msgjson.Message) *msgjson.Error {
+	s.handlerMtx.RLock()
+	defer s.handlerMtx.RUnlock() // block shutdown until registered with latencyQ
+	if s.stop {
+		return &msgjson.Error{
+			Code:    msgjson.TryAgainLaterError,
+			Message: "The swapper is stopping. Try again later.",
+		}
+	}
+
+	params := new(msgjson.PrivateRedeem)
+	err := msg.Unmarshal(&params)
+	if err != nil || params == nil {
+		return &msgjson.Error{
+			Code:    msgjson.RPCParseError,
+			Message: "Error decoding 'private_redeem' request payload",
+		}
+	}
+	if rpcErr := s.authUser(user, params); rpcErr != nil {
+		return rpcErr
+	}
+	if len(params.MatchID) != order.MatchIDSize {
+		return &msgjson.Error{
+			Code:    msgjson.RPCParseError,
+			Message: "Invalid 'matchid' in 'private_redeem' message",
+		}
+	}
+	var matchID order.MatchID
+	copy(matchID[:], params.MatchID)
+	stepInfo, rpcErr := s.step(user, matchID)
+	if rpcErr != nil {
+		return rpcErr
+	}
+	mt := stepInfo.match
+	if mt.private == nil {
+		return &msgjson.Error{
+			Code:    msgjson.SettlementSequenceError,
+			Message: "not a private swap",
+		}
+	}
+
+	switch stepInfo.step {
+	case order.TakerSwapCast:
+		mt.mtx.RLock()
+		exchanged := mt.private.takerSigs != nil
+		mt.mtx.RUnlock()
+		if !exchanged {
+			return &msgjson.Error{
+				Code:    msgjson.SettlementSequenceError,
+				Message: "adaptor signatures not exchanged",
+			}
+		}
+	case order.MakerRedeemed:
+	default:
+		return &msgjson.Error{
+			Code:    msgjson.SettlementSequenceError,
+			Message: "redemption already provided or contracts not yet broadcast",
+		}
+	}
+	if !stepInfo.actor.status.startRedeemSearch() {
+		return &msgjson.Error{
+			Code:    msgjson.DuplicateRequestError,
+			Message: "already received a redeem transaction, search in progress",
+		}
+	}
+
+	coinStr, err := stepInfo.asset.Backend.ValidateCoinID(params.CoinID)
+	if err != nil {
+		stepInfo.actor.status.endRedeemSearch()
+		return &msgjson.Error{
+			Code:    msgjson.InvalidRequestError,
+			Message: "invalid 'coinid'",
+		}
+	}
+
+	expireTime := time.Now().Add(s.txWaitExpiration).UTC()
+	log.Debugf("Allowing until %v (%v) to locate private redeem from %v (%v), match %v, tx %s (%s)",
+		expireTime, time.Until(expireTime), makerTaker(stepInfo.actor.isMaker),
+		stepInfo.step, matchID, coinStr, stepInfo.asset.Symbol)
+
+	s.latencyQ.Wait(&wait.Waiter{
+		Expiration: expireTime,
+		TryFunc: func() wait.TryDirective {
+			return s.processPrivateRedeem(msg, params, stepInfo)
+		},
+		ExpireFunc: func() {
+			stepInfo.actor.status.endRedeemSearch() // allow redeem retries
+			s.respondError(msg.ID, user, msgjson.TransactionUndiscovered,
+				fmt.Sprintf("failed to find redeemed coin %v", coinStr))
+		},
+	})
+	return nil
+}
+
+// processPrivateRedeem locates a redemption of the counterparty's private swap.
+// There is no secret to validate. This method is run as a coin waiter.
+func (s *Swapper) processPrivateRedeem(msg *msgjson.Message, params *msgjson.PrivateRedeem, stepInfo *stepInformation) wait.TryDirective {
+	actor, counterParty := stepInfo.actor, stepInfo.counterParty
+	counterParty.status.mtx.RLock()
+	cpSwapCoin := counterParty.status.swap.ID()
+	cpSwapStr := counterParty.status.swap.String()
+	counterParty.status.mtx.RUnlock()
+
+	match := stepInfo.match
+	matchID := match.ID()
+	redemption, err := stepInfo.asset.Backend.Redemption(params.CoinID, cpSwapCoin, nil)
+	if err != nil {
+		if errors.Is(err, asset.CoinNotFoundError) {
+			return wait.TryAgain
+		}
+		log.Warnf("Private redemption error encountered for match %s, actor %s, using coin ID %v to redeem %x: %v",
+			matchID, actor.user, params.CoinID, cpSwapCoin, err)
+		actor.status.endRedeemSearch() // allow client retry even before notifying him
+		s.respondError(msg.ID, actor.user, msgjson.RedemptionError,
+			fmt.Sprintf("redemption error encountered: %v", err))
+		return wait.DontTryAgain
+	}
+
+	newStatus := stepInfo.nextStep
+	s.matchMtx.RLock()
+	if _, found := s.matches[matchID]; !found {
+		s.matchMtx.RUnlock()
+		log.Errorf("Private redeem txn found after match was revoked (match id=%v, maker=%v)",
+			matchID, actor.isMaker)
+		actor.status.endRedeemSearch()
+		s.respondError(msg.ID, actor.user, msgjson.RedemptionError, "match already revoked due to inaction")
+		return wait.DontTryAgain
+	}
+
+	actor.status.mtx.Lock()
+	redeemTime := unixMsNow()
+	actor.status.redemption = redemption
+	actor.status.redeemTime = redeemTime
+	actor.status.mtx.Unlock()
+
+	match.mtx.Lock()
+	recordStatusChange(match.Status, newStatus)
+	match.Status = newStatus
+	match.mtx.Unlock()
+	s.matchMtx.RUnlock()
+
+	actor.status.endRedeemSearch()
+
+	log.Debugf("processPrivateRedeem: valid redemption %v (%s) spending swap %s received at %v from %v (%s) for match %v, "+
+		"swapStatus %v => %v", redemption, stepInfo.asset.Symbol, cpSwapStr, redeemTime, actor.user,
+		makerTaker(actor.isMaker), matchID, stepInfo.step, newStatus)
+
+	storFn := s.storage.SaveRedeemB
+	if actor.isMaker {
+		storFn = func(mid db.MarketMatchID, coinID []byte, timestamp int64) error {
+			return s.storage.SaveRedeemA(mid, coinID, nil, timestamp)
+		}
+	}
+	redeemTimeMs := redeemTime.UnixMilli()
+	if err = storFn(db.MatchID(match.Match), params.CoinID, redeemTimeMs); err != nil {
+		log.Errorf("saving private redeem transaction (match id=%v, maker=%v) failed: %v",
+			matchID, actor.isMaker, err)
+		// Neither party's fault. Continue.
+	}
+
+	if actor.user != counterParty.user {
+		s.authMgr.SwapSuccess(actor.user, db.MatchID(match.Match), match.Quantity, redeemTime)
+	}
+
+	s.authMgr.Sign(params)
+	s.respondSuccess(msg.ID, actor.user, &msgjson.Acknowledgement{
+		MatchID: matchID[:],
+		Sig:     params.Sig,
+	})
+
+	ord := match.Taker
+	if actor.isMaker {
+		ord = match.Maker
+	}
+	s.swapDone(ord, match.Match, false)
+
+	// The taker needs the maker's redemption to recover the adaptor secret.
+	s.relay(msgjson.PrivateRedemptionRoute, &messageAcker{
+		user:  counterParty.user,
+		match: match,
+		params: &msgjson.PrivateRedemption{
+			PrivateRedeem: msgjson.PrivateRedeem{
+				OrderID: idToBytes(counterParty.order.ID()),
+				MatchID: matchID[:],
+				CoinID:  params.CoinID,
+				TxData:  params.TxData,
+			},
+			Time: uint64(redeemTimeMs),
+		},
+		isMaker: counterParty.isMaker,
+	}, time.Until(redeemTime.Add(s.bTimeout)))
+
+	return wait.DontTryAgain
+}
@@ -0,0 +1,208 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package swap
+
+import (
+	"context"
+	"time"
+
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/account"
+	"decred.org/dcrdex/server/asset"
+)
+
+// SwapProgress is one party's progress in an active match.
+type SwapProgress struct {
+	// SwapAsset is the asset of the party's swap contract.
+	SwapAsset uint32
+	// Swap is the party's swap coin, or an empty string if the swap has not
+	// been seen.
+	Swap string
+	// SwapTime is when the Swapper saw the swap.
+	SwapTime time.Time
+	// Confs is the swap's current confirmation count. Confs is -1 if there
+	// is no swap or the count could not be retrieved.
+	Confs int64
+	// RequiredConfs is the swap asset's SwapConf.
+	RequiredConfs uint32
+	// ConfirmTime is when the swap reached RequiredConfs.
+	ConfirmTime time.Time
+	// LockTime is the swap contract's lock time.
+	LockTime time.Time
+	// Redeem is the party's redeem coin, or an empty string if the
+	// redemption has not been seen.
+	Redeem string
+	// RedeemTime is when the Swapper saw the redemption.
+	RedeemTime time.Time
+}
+
+// ActiveSwap is a snapshot of a match that is being settled.
+type ActiveSwap struct {
+	MatchID    order.MatchID
+	Base       uint32
+	Quote      uint32
+	Status     order.MatchStatus
+	MakerOrder order.OrderID
+	TakerOrder order.OrderID
+	MakerAcct  account.AccountID
+	TakerAcct  account.AccountID
+	Quantity   uint64
+	Rate       uint64
+	// Private is true for an adaptor signature private swap.
+	Private bool
+	// MatchTime is the epoch close time.
+	MatchTime time.Time
+	// StepStart is when the match entered its current status.
+	StepStart time.Time
+	// MakerActs is true if the maker is expected to act next. There is no
+	// expected action once the match is complete.
+	MakerActs bool
+	// Deadline is the time at which the match will be revoked if the expected
+	// party does not act. Deadline is zero if the revocation clock has not
+	// started, e.g. while a swap awaits its required confirmations.
+	Deadline time.Time
+	Maker    *SwapProgress
+	Taker    *SwapProgress
+}
+
+// Actor is the account expected to act next. The zero value is returned for a
+// complete match.
+func (as *ActiveSwap) Actor() account.AccountID {
+	if as.Status == order.MatchComplete {
+		return account.AccountID{}
+	}
+	if as.MakerActs {
+		return as.MakerAcct
+	}
+	return as.TakerAcct
+}
+
+// earliest returns the earliest non-zero time.
+func earliest(ts ...time.Time) (t time.Time) {
+	for _, ti := range ts {
+		if !ti.IsZero() && (t.IsZero() || ti.Before(t)) {
+			t = ti
+		}
+	}
+	return
+}
+
+// swapProgress copies the swapStatus. The swap's confirmations are not set.
+func (s *Swapper) swapProgress(ss *swapStatus) (*SwapProgress, *asset.Contract) {
+	ss.mtx.RLock()
+	defer ss.mtx.RUnlock()
+	p := &SwapProgress{
+		SwapAsset:   ss.swapAsset,
+		SwapTime:    ss.swapTime,
+		Confs:       -1,
+		ConfirmTime: ss.swapConfirmed,
+		RedeemTime:  ss.redeemTime,
+	}
+	if a := s.coins[ss.swapAsset]; a != nil {
+		p.RequiredConfs = a.SwapConf
+	}
+	if ss.swap != nil {
+		p.Swap = ss.swap.String()
+		p.LockTime = ss.swap.LockTime
+	}
+	if ss.redemption != nil {
+		p.Redeem = ss.redemption.String()
+	}
+	return p, ss.swap
+}
+
+// activeSwap creates a snapshot of the match. The matchTracker's mtx must be
+// held for reads.
+func (s *Swapper) activeSwap(mt *matchTracker) (*ActiveSwap, *asset.Contract, *asset.Contract) {
+	maker, makerSwap := s.swapProgress(mt.makerStatus)
+	taker, takerSwap := s.swapProgress(mt.takerStatus)
+	as := &ActiveSwap{
+		MatchID:    mt.ID(),
+		Base:       mt.Maker.BaseAsset,
+		Quote:      mt.Maker.QuoteAsset,
+		Status:     mt.Status,
+		MakerOrder: mt.Maker.ID(),
+		TakerOrder: mt.Taker.ID(),
+		MakerAcct:  mt.Maker.AccountID,
+		TakerAcct:  mt.Taker.User(),
+		Quantity:   mt.Quantity,
+		Rate:       mt.Rate,
+		Private:    mt.private != nil,
+		MatchTime:  mt.matchTime,
+		Maker:      maker,
+		Taker:      taker,
+	}
+
+	// The deadlines mirror the checks in checkInactionEventBased and
+	// checkInactionBlockBased. A private swap may be waiting on the taker
+	// where an HTLC swap would wait on the maker.
+	swapDeadline := func(confTime time.Time) time.Time {
+		if confTime.IsZero() {
+			return time.Time{}
+		}
+		return confTime.Add(s.bTimeout)
+	}
+	switch mt.Status {
+	case order.NewlyMatched:
+		as.StepStart = mt.time
+		as.MakerActs = !mt.privateTakerActs()
+		as.Deadline = mt.swapStartTime().Add(s.bTimeout)
+	case order.MakerSwapCast:
+		as.StepStart = maker.SwapTime
+		as.Deadline = earliest(mt.matchTime.Add(s.lockTimeTaker), maker.LockTime,
+			swapDeadline(maker.ConfirmTime))
+	case order.TakerSwapCast:
+		as.StepStart = taker.SwapTime
+		as.MakerActs = !mt.privateTakerActs()
+		as.Deadline = earliest(maker.LockTime, taker.LockTime, swapDeadline(taker.ConfirmTime))
+		if stepTime := mt.privateStepTime(); !stepTime.IsZero() {
+			as.StepStart = stepTime
+			as.Deadline = earliest(maker.LockTime, taker.LockTime, stepTime.Add(s.bTimeout))
+		}
+	case order.MakerRedeemed:
+		as.StepStart = maker.RedeemTime
+		as.Deadline = maker.RedeemTime.Add(s.bTimeout)
+	case order.MatchComplete:
+		as.StepStart = taker.RedeemTime
+	}
+	return as, makerSwap, takerSwap
+}
+
+// ActiveSwaps returns a snapshot of the matches being settled, including the
+// current confirmation count of each swap.
+func (s *Swapper) ActiveSwaps(ctx context.Context) []*ActiveSwap {
+	type swapCoins struct {
+		as                   *ActiveSwap
+		makerSwap, takerSwap *asset.Contract
+	}
+	s.matchMtx.RLock()
+	matches := make([]*swapCoins, 0, len(s.matches))
+	for _, mt := range s.matches {
+		mt.mtx.RLock()
+		as, makerSwap, takerSwap := s.activeSwap(mt)
+		mt.mtx.RUnlock()
+		matches = append(matches, &swapCoins{as, makerSwap, takerSwap})
+	}
+	s.matchMtx.RUnlock()
+
+	// Request confirmations without holding the matchMtx.
+	confs := func(swap *asset.Contract) int64 {
+		if swap == nil {
+			return -1
+		}
+		n, err := swap.Confirmations(ctx)
+		if err != nil {
+			log.Debugf("Error getting confirmations for swap %v: %v", swap, err)
+			return -1
+		}
+		return n
+	}
+	swaps := make([]*ActiveSwap, 0, len(matches))
+	for _, m := range matches {
+		m.as.Maker.Confs = confs(m.makerSwap)
+		m.as.Taker.Confs = confs(m.takerSwap)
+		swaps = append(swaps, m.as)
+	}
+	return swaps
+}
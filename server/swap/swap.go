@@ -147,6 +147,8 @@ type matchTracker struct {
 	matchTime   time.Time // epoch close time
 	makerStatus *swapStatus
 	takerStatus *swapStatus
+	// private is set for a match on a market with private swaps.
+	private *privateMatch
 }
 
 // expiredBy returns true if the lock time of either party's *known* swap is
@@ -242,6 +244,12 @@ type Swapper struct {
 	// Expected locktimes for maker and taker swaps.
 	lockTimeTaker time.Duration
 	lockTimeMaker time.Duration
+	// privateMtx guards privateMarkets, which can change when markets are
+	// added or removed at runtime.
+	privateMtx sync.RWMutex
+	// privateMarkets are the markets, by base and quote asset IDs, whose
+	// matches are settled with private swaps.
+	privateMarkets map[[2]uint32]bool
 	// latencyQ is a queue for coin waiters to deal with network latency.
 	latencyQ *wait.TaperingTickerQueue
 
@@ -284,6 +292,9 @@ type Config struct {
 	// SwapDone registers a match with the DEX manager (or other consumer) for a
 	// given order as being finished.
 	SwapDone func(oid order.Order, match *order.Match, fail bool)
+	// PrivateMarkets are the markets, by base and quote asset IDs, whose
+	// matches are settled with adaptor signature private swaps.
+	PrivateMarkets map[[2]uint32]bool
 }
 
 // NewSwapper is a constructor for a Swapper.
@@ -315,6 +326,10 @@ func NewSwapper(cfg *Config) (*Swapper, error) {
 		txWaitExpiration: cfg.TxWaitExpiration,
 		lockTimeTaker:    cfg.LockTimeTaker,
 		lockTimeMaker:    cfg.LockTimeMaker,
+		privateMarkets:   make(map[[2]uint32]bool, len(cfg.PrivateMarkets)),
+	}
+	for mkt, private := range cfg.PrivateMarkets {
+		swapper.privateMarkets[mkt] = private
 	}
 
 	// Ensure txWaitExpiration is not greater than broadcast timeout setting.
@@ -330,9 +345,13 @@ func NewSwapper(cfg *Config) (*Swapper, error) {
 	}
 
 	// The swapper is only concerned with two types of client-originating
-	// method requests.
+	// method requests, plus the private swap requests.
 	authMgr.Route(msgjson.InitRoute, swapper.handleInit)
 	authMgr.Route(msgjson.RedeemRoute, swapper.handleRedeem)
+	authMgr.Route(msgjson.PrivateKeysRoute, swapper.handlePrivateKeys)
+	authMgr.Route(msgjson.PrivateInitRoute, swapper.handlePrivateInit)
+	authMgr.Route(msgjson.AdaptorSigsRoute, swapper.handleAdaptorSigs)
+	authMgr.Route(msgjson.PrivateRedeemRoute, swapper.handlePrivateRedeem)
 
 	return swapper, nil
 }
@@ -341,6 +360,7 @@ func NewSwapper(cfg *Config) (*Swapper, error) {
 func (s *Swapper) addMatch(mt *matchTracker) {
 	mid := mt.ID()
 	s.matches[mid] = mt
+	activeMatches.With(mt.Status.String()).Inc()
 
 	// Add the match to both maker's and taker's match maps.
 	maker, taker := mt.Maker.User(), mt.Taker.User()
@@ -382,6 +402,11 @@ func (s *Swapper) addMatch(mt *matchTracker) {
 // deleteMatch unregisters a match. The matchMtx must be locked.
 func (s *Swapper) deleteMatch(mt *matchTracker) {
 	mid := mt.ID()
+	if _, found := s.matches[mid]; found {
+		// Status changes require the matchMtx, so there is no need to lock
+		// the matchTracker, which may already be locked by the caller.
+		activeMatches.With(mt.Status.String()).Dec()
+	}
 	delete(s.matches, mid)
 
 	// Unlock the maker and taker order coins. May be redundant if processBlock
@@ -587,14 +612,22 @@ func (s *Swapper) restoreActiveSwaps(allowPartial bool) error {
 		// contract has reached SwapConf.
 	}
 
-	translateSwapStatus := func(ss *swapStatus, ssd *swapStatusData, cpSwapCoin []byte) error {
+	// The private contract is non-nil for a private swap. Its swap output
+	// commits to the parties' keys instead of a contract script.
+	translateSwapStatus := func(ss *swapStatus, ssd *swapStatusData, cpSwapCoin []byte, privContract *asset.PrivateContract) error {
 		ss.swapAsset, ss.redeemAsset = ssd.SwapAsset, ssd.RedeemAsset
 
 		swapCoin := ssd.ContractCoinOut
 		if len(swapCoin) > 0 {
 			assetID := ssd.SwapAsset
 			swapAsset := s.coins[assetID]
-			swap, err := swapAsset.Backend.Contract(swapCoin, ssd.ContractScript)
+			var swap *asset.Contract
+			var err error
+			if privContract != nil {
+				swap, err = privateContract(swapAsset.Backend, swapCoin, privContract)
+			} else {
+				swap, err = swapAsset.Backend.Contract(swapCoin, ssd.ContractScript)
+			}
 			if err != nil {
 				return fmt.Errorf("unable to find swap out coin %x for asset %d: %w", swapCoin, assetID, err)
 			}
@@ -729,11 +762,26 @@ func (s *Swapper) restoreActiveSwaps(allowPartial bool) error {
 			RedeemCoinIn:    sd.SwapData.RedeemBCoinID,
 		}
 
-		if err := translateSwapStatus(mt.makerStatus, makerStatus, takerStatus.ContractCoinOut); err != nil {
+		var makerContract, takerContract *asset.PrivateContract
+		if len(sd.SwapData.PrivateData) > 0 {
+			pm, err := decodePrivateMatch(sd.SwapData.PrivateData)
+			if err != nil {
+				log.Errorf("Loading private swap data for match %v failed: %v", mid, err)
+				continue
+			}
+			mt.private = pm
+			makerContract, takerContract, err = pm.contracts(mt.matchTime, s.lockTimeMaker, s.lockTimeTaker)
+			if err != nil && (len(sd.SwapData.ContractACoinID) > 0 || len(sd.SwapData.ContractBCoinID) > 0) {
+				log.Errorf("Loading private swap %v failed: %v", mid, err)
+				continue
+			}
+		}
+
+		if err := translateSwapStatus(mt.makerStatus, makerStatus, takerStatus.ContractCoinOut, makerContract); err != nil {
 			log.Errorf("Loading match %v failed: %v", mid, err)
 			continue
 		}
-		if err := translateSwapStatus(mt.takerStatus, takerStatus, makerStatus.ContractCoinOut); err != nil {
+		if err := translateSwapStatus(mt.takerStatus, takerStatus, makerStatus.ContractCoinOut, takerContract); err != nil {
 			log.Errorf("Loading match %v failed: %v", mid, err)
 			continue
 		}
@@ -1053,6 +1101,9 @@ func (s *Swapper) failMatch(match *matchTracker, userFault bool) {
 		log.Errorf("Invalid failMatch status %v for match %v", match.Status, match.ID())
 		return
 	}
+	if takerFault, takerOutcome, takerRefTime := match.privateTakerFault(); takerFault {
+		outcome, refTime, makerFault = takerOutcome, takerRefTime, false
+	}
 
 	orderAtFault, otherOrder := match.Taker, order.Order(match.Maker) // an order.Order
 	if makerFault {
@@ -1125,8 +1176,9 @@ func (s *Swapper) checkInactionEventBased() {
 		switch match.Status {
 		case order.NewlyMatched:
 			// Maker has not broadcast their swap. They have until match time
-			// plus bTimeout.
-			if tooOld(match.time) {
+			// plus bTimeout. For a private swap, the keys must be exchanged
+			// by then, and the maker has bTimeout after the exchange.
+			if tooOld(match.swapStartTime()) {
 				deleteMatch(true)
 			}
 		case order.MakerSwapCast:
@@ -1151,6 +1203,10 @@ func (s *Swapper) checkInactionEventBased() {
 				log.Infof("Revoking match %v at %v because at least one published contract has expired.",
 					match.ID(), match.Status)
 				deleteMatch(false)
+			} else if stepTime := match.privateStepTime(); !stepTime.IsZero() && tooOld(stepTime) {
+				// The adaptor signature exchange of a private swap has
+				// stalled.
+				deleteMatch(true)
 			}
 		case order.MakerRedeemed:
 			// If the maker has redeemed, the taker can redeem immediately, so
@@ -1231,7 +1287,9 @@ func (s *Swapper) checkInactionBlockBased(assetID uint32) {
 				deleteMatch()
 			}
 		case order.TakerSwapCast:
-			if tooOld(match.takerStatus.swapConfTime()) {
+			// Once a private swap's adaptor signature exchange starts,
+			// checkInactionEventBased tracks the deadline.
+			if match.privateStepTime().IsZero() && tooOld(match.takerStatus.swapConfTime()) {
 				deleteMatch()
 			}
 		}
@@ -1454,7 +1512,11 @@ func (s *Swapper) processAck(msg *msgjson.Message, acker *messageAcker) {
 	}
 
 	switch acker.params.(type) {
-	case *msgjson.Audit, *msgjson.Redemption:
+	case *msgjson.Audit, *msgjson.Redemption, *msgjson.PrivateAudit, *msgjson.PrivateRedemption:
+	case *msgjson.PrivateKeys, *msgjson.AdaptorSigs:
+		// Acknowledgements of relayed keys and adaptor signatures are not
+		// recorded.
+		return
 	default:
 		log.Warnf("unrecognized ack type %T", acker.params)
 		return
@@ -1655,6 +1717,7 @@ func (s *Swapper) processInit(msg *msgjson.Message, params *msgjson.Init, stepIn
 	actor.status.mtx.Unlock()
 
 	stepInfo.match.mtx.Lock()
+	recordStatusChange(stepInfo.match.Status, stepInfo.nextStep)
 	stepInfo.match.Status = stepInfo.nextStep // handleInit (gate mechanism) won't allow backward progress
 	stepInfo.match.mtx.Unlock()
 
@@ -1794,6 +1857,7 @@ func (s *Swapper) processRedeem(msg *msgjson.Message, params *msgjson.Redeem, st
 	actor.status.mtx.Unlock()
 
 	match.mtx.Lock()
+	recordStatusChange(match.Status, newStatus)
 	match.Status = newStatus // handleRedeem (gate mechanism) won't allow backward progress
 	match.mtx.Unlock()
 
@@ -1942,6 +2006,12 @@ func (s *Swapper) handleInit(user account.AccountID, msg *msgjson.Message) *msgj
 	if rpcErr != nil {
 		return rpcErr
 	}
+	if stepInfo.match.private != nil {
+		return &msgjson.Error{
+			Code:    msgjson.SettlementSequenceError,
+			Message: "private swaps use 'private_init'",
+		}
+	}
 
 	// init requests should only be sent when contracts are still required, in
 	// the correct sequence, and by the correct party.
@@ -2058,6 +2128,12 @@ func (s *Swapper) handleRedeem(user account.AccountID, msg *msgjson.Message) *ms
 	if rpcErr != nil {
 		return rpcErr
 	}
+	if stepInfo.match.private != nil {
+		return &msgjson.Error{
+			Code:    msgjson.SettlementSequenceError,
+			Message: "private swaps use 'private_redeem'",
+		}
+	}
 
 	// redeem requests should only be sent when all contracts have been
 	// received, in the correct sequence, and by the correct party.
@@ -2465,6 +2541,16 @@ func (s *Swapper) Negotiate(matchSets []*order.MatchSet) {
 			// abortAll()
 			return
 		}
+		// The private swap state is stored with the match so that it is
+		// restored as a private swap, even if the market setting changes.
+		if match.Taker.Type() != order.CancelOrderType &&
+			s.isPrivateMarket(match.Maker.BaseAsset, match.Maker.QuoteAsset) {
+			match.private = new(privateMatch)
+			if err := s.savePrivate(match); err != nil {
+				log.Errorf("SavePrivateData (match id=%v) failed: %v", match.ID(), err)
+				return
+			}
+		}
 	}
 
 	userMatches := make(map[account.AccountID][]*messageAcker)
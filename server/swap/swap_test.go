@@ -298,6 +298,15 @@ type TStorage struct {
 	fatalMtx sync.RWMutex
 	fatal    chan struct{}
 	fatalErr error
+
+	// For restoring swaps.
+	orders      map[order.OrderID]order.Order
+	activeSwaps []*db.SwapDataFull
+	inactiveMtx sync.Mutex
+	inactive    []db.MarketMatchID
+
+	privateMtx  sync.Mutex
+	privateData map[order.MatchID][]byte
 }
 
 func (ts *TStorage) LastErr() error {
@@ -323,10 +332,13 @@ func (ts *TStorage) fatalBackendErr(err error) {
 }
 
 func (ts *TStorage) Order(oid order.OrderID, base, quote uint32) (order.Order, order.OrderStatus, error) {
+	if ord, found := ts.orders[oid]; found {
+		return ord, order.OrderStatusExecuted, nil
+	}
 	return nil, order.OrderStatusUnknown, nil // not loading swaps
 }
 func (ts *TStorage) CancelOrder(*order.LimitOrder) error      { return nil }
-func (ts *TStorage) ActiveSwaps() ([]*db.SwapDataFull, error) { return nil, nil }
+func (ts *TStorage) ActiveSwaps() ([]*db.SwapDataFull, error) { return ts.activeSwaps, nil }
 func (ts *TStorage) InsertMatch(match *order.Match) error     { return nil }
 func (ts *TStorage) SwapData(mid db.MarketMatchID) (order.MatchStatus, *db.SwapData, error) {
 	return 0, nil, nil
@@ -354,7 +366,27 @@ func (ts *TStorage) SaveRedeemAckSigB(mid db.MarketMatchID, sig []byte) error {
 func (ts *TStorage) SaveRedeemB(mid db.MarketMatchID, coinID []byte, timestamp int64) error {
 	return nil
 }
-func (ts *TStorage) SetMatchInactive(mid db.MarketMatchID, forgive bool) error { return nil }
+func (ts *TStorage) SavePrivateData(mid db.MarketMatchID, data []byte) error {
+	ts.privateMtx.Lock()
+	defer ts.privateMtx.Unlock()
+	if ts.privateData == nil {
+		ts.privateData = make(map[order.MatchID][]byte)
+	}
+	ts.privateData[mid.MatchID] = data
+	return nil
+}
+
+func (ts *TStorage) storedPrivateData(mid order.MatchID) []byte {
+	ts.privateMtx.Lock()
+	defer ts.privateMtx.Unlock()
+	return ts.privateData[mid]
+}
+func (ts *TStorage) SetMatchInactive(mid db.MarketMatchID, forgive bool) error {
+	ts.inactiveMtx.Lock()
+	ts.inactive = append(ts.inactive, mid)
+	ts.inactiveMtx.Unlock()
+	return nil
+}
 
 type redeemKey struct {
 	redemptionCoin       string
@@ -469,7 +501,9 @@ func (a *TBackend) ValidateFeeRate(asset.Coin, uint64) bool {
 
 type TUTXOBackend struct {
 	TBackend
-	funds asset.FundingCoin
+	funds           asset.FundingCoin
+	privateContract *asset.PrivateContract
+	privateSwapErr  error
 }
 
 func (a *TUTXOBackend) FundingCoin(_ context.Context, coinID, redeemScript []byte) (asset.FundingCoin, error) {
@@ -480,6 +514,25 @@ func (a *TUTXOBackend) FundingCoin(_ context.Context, coinID, redeemScript []byt
 
 func (a *TUTXOBackend) VerifyUnspentCoin(_ context.Context, coinID []byte) error { return nil }
 
+var _ asset.PrivateSwapper = (*TUTXOBackend)(nil)
+
+// PrivateSwapCoin records the private contract and returns the coin of a
+// contract set with setContract.
+func (a *TUTXOBackend) PrivateSwapCoin(coinID []byte, privateContract *asset.PrivateContract) (asset.Coin, error) {
+	a.mtx.Lock()
+	a.privateContract = privateContract
+	err := a.privateSwapErr
+	a.mtx.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	contract, err := a.Contract(coinID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return contract.Coin, nil
+}
+
 type TAccountBackend struct {
 	TBackend
 }
@@ -2346,3 +2399,505 @@ func TestAccountTracking(t *testing.T) {
 
 // TODO: TestSwapper_restoreActiveSwaps? It would be almost entirely driven by
 // stubbed out asset backend and storage.
+
+func TestActiveSwaps(t *testing.T) {
+	set := tPerfectLimitLimit(uint64(1e8), uint64(1e8), true)
+	matchInfo := set.matchInfos[0]
+	rig, cleanup := tNewTestRig(matchInfo)
+	defer cleanup()
+
+	rig.auth.auditReq = make(chan struct{}, 1)
+	rig.auth.swapReceived = make(chan struct{}, 1)
+
+	activeSwap := func() *ActiveSwap {
+		t.Helper()
+		swaps := rig.swapper.ActiveSwaps(testCtx)
+		if len(swaps) != 1 {
+			t.Fatalf("expected 1 active swap, got %d", len(swaps))
+		}
+		as := swaps[0]
+		if as.MatchID != matchInfo.matchID {
+			t.Fatalf("wrong match ID %s", as.MatchID)
+		}
+		return as
+	}
+
+	rig.swapper.Negotiate([]*order.MatchSet{set.matchSet})
+	if err := rig.ackMatch_maker(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := rig.ackMatch_taker(true); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := rig.getTracker()
+	as := activeSwap()
+	if as.Status != order.NewlyMatched {
+		t.Fatalf("wrong status %s", as.Status)
+	}
+	if !as.MakerActs || as.Actor() != matchInfo.maker.acct {
+		t.Fatalf("maker should be acting")
+	}
+	if !as.StepStart.Equal(tracker.time) {
+		t.Fatalf("wrong step start %s, wanted %s", as.StepStart, tracker.time)
+	}
+	if !as.Deadline.Equal(tracker.time.Add(tBcastTimeout)) {
+		t.Fatalf("wrong deadline %s", as.Deadline)
+	}
+	if as.Maker.Swap != "" || as.Maker.Confs != -1 || as.Taker.Confs != -1 {
+		t.Fatalf("unexpected swap progress before swaps")
+	}
+
+	if err := rig.sendSwap_maker(true); err != nil {
+		t.Fatal(err)
+	}
+	makerSwap := matchInfo.db.makerSwap.coin
+	makerSwap.Coin.(*TCoin).setConfs(1)
+	tracker.makerStatus.mtx.Lock()
+	tracker.makerStatus.swapConfirmed = time.Time{}
+	tracker.makerStatus.mtx.Unlock()
+
+	as = activeSwap()
+	if as.Status != order.MakerSwapCast {
+		t.Fatalf("wrong status %s", as.Status)
+	}
+	if as.MakerActs || as.Actor() != matchInfo.taker.acct {
+		t.Fatalf("taker should be acting")
+	}
+	if as.Maker.Swap != makerSwap.String() || as.Maker.Confs != 1 {
+		t.Fatalf("wrong maker swap progress %+v", as.Maker)
+	}
+	if as.Maker.RequiredConfs != rig.abc.SwapConf {
+		t.Fatalf("wrong required confs %d", as.Maker.RequiredConfs)
+	}
+	// Without the swap confirmed, the taker's expected lock time is the
+	// deadline.
+	if !as.Deadline.Equal(tracker.matchTime.Add(dex.LockTimeTaker(dex.Testnet))) {
+		t.Fatalf("wrong unconfirmed deadline %s", as.Deadline)
+	}
+
+	confTime := time.Now()
+	tracker.makerStatus.mtx.Lock()
+	tracker.makerStatus.swapConfirmed = confTime
+	tracker.makerStatus.mtx.Unlock()
+	as = activeSwap()
+	if !as.Deadline.Equal(confTime.Add(tBcastTimeout)) {
+		t.Fatalf("wrong confirmed deadline %s", as.Deadline)
+	}
+}
+
+func TestPrivateSwaps(t *testing.T) {
+	set := tPerfectLimitLimit(uint64(1e8), uint64(1e8), true)
+	matchInfo := set.matchInfos[0]
+	rig, cleanup := tNewTestRig(matchInfo)
+	defer cleanup()
+	rig.swapper.SetPrivateMarket(ABCID, XYZID, true)
+	maker, taker := matchInfo.maker, matchInfo.taker
+	mid := matchInfo.matchID
+
+	rig.swapper.Negotiate([]*order.MatchSet{set.matchSet})
+	if err := rig.ackMatch_maker(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := rig.ackMatch_taker(true); err != nil {
+		t.Fatal(err)
+	}
+	tracker := rig.getTracker()
+	if tracker.private == nil {
+		t.Fatalf("match not private")
+	}
+	// storedPrivate decodes the private swap state saved for the match.
+	storedPrivate := func() *privateMatch {
+		t.Helper()
+		b := rig.storage.storedPrivateData(mid)
+		if len(b) == 0 {
+			t.Fatalf("no private swap data stored")
+		}
+		pm, err := decodePrivateMatch(b)
+		if err != nil {
+			t.Fatalf("error decoding stored private swap data: %v", err)
+		}
+		return pm
+	}
+	storedPrivate()
+
+	// waitReq waits for a request from the DEX, and acknowledges it.
+	waitReq := func(user *tUser, route string) *msgjson.Message {
+		t.Helper()
+		timeout := time.After(time.Second)
+		for {
+			if req := rig.auth.popReq(user.acct); req != nil {
+				if req.req.Route != route {
+					t.Fatalf("expected %s request for %s, got %s", route, user.lbl, req.req.Route)
+				}
+				req.respFunc(nil, tNewResponse(req.req.ID, tAck(user, mid)))
+				return req.req
+			}
+			select {
+			case <-timeout:
+				t.Fatalf("no %s request for %s", route, user.lbl)
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+	// waitResp waits for the response to a request from a user.
+	waitResp := func(user *tUser) *msgjson.ResponsePayload {
+		t.Helper()
+		timeout := time.After(time.Second)
+		for {
+			if msg, resp := rig.auth.popResp(user.acct); msg != nil {
+				return resp
+			}
+			select {
+			case <-timeout:
+				t.Fatalf("no response for %s", user.lbl)
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+	ensureSuccess := func(user *tUser) {
+		t.Helper()
+		if resp := waitResp(user); resp.Error != nil {
+			t.Fatalf("%s request error: %s", user.lbl, resp.Error.Message)
+		}
+	}
+	ensureFail := func(user *tUser, code int) {
+		t.Helper()
+		if resp := waitResp(user); resp.Error == nil || resp.Error.Code != code {
+			t.Fatalf("expected error code %d for %s, got %+v", code, user.lbl, resp.Error)
+		}
+	}
+	handle := func(user *tUser, route string, payload any, handler func(account.AccountID, *msgjson.Message) *msgjson.Error) *msgjson.Error {
+		t.Helper()
+		req, _ := msgjson.NewRequest(nextID(), route, payload)
+		return handler(user.acct, req)
+	}
+	checkStatus := func(status order.MatchStatus) {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			tracker.mtx.RLock()
+			s := tracker.Status
+			tracker.mtx.RUnlock()
+			if s == status {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("wrong status %s, wanted %s", tracker.Status, status)
+	}
+	actor := func() account.AccountID {
+		t.Helper()
+		swaps := rig.swapper.ActiveSwaps(testCtx)
+		if len(swaps) != 1 || !swaps[0].Private {
+			t.Fatalf("expected 1 private active swap")
+		}
+		return swaps[0].Actor()
+	}
+	newSwap := func(user *tUser, node *TUTXOBackend) *asset.Contract {
+		swap := tNewSwap(matchInfo, matchInfo.makerOID, "", user)
+		node.setContract(swap.coin, false)
+		return swap.coin
+	}
+
+	// The HTLC route is rejected.
+	swap := tNewSwap(matchInfo, matchInfo.makerOID, taker.addr, maker)
+	if rpcErr := rig.swapper.handleInit(maker.acct, swap.req); rpcErr == nil ||
+		rpcErr.Code != msgjson.SettlementSequenceError {
+		t.Fatalf("expected a sequence error for an HTLC init, got %v", rpcErr)
+	}
+	redeemReq, _ := msgjson.NewRequest(nextID(), msgjson.RedeemRoute, &msgjson.Redeem{
+		OrderID: matchInfo.makerOID[:],
+		MatchID: mid[:],
+		CoinID:  randBytes(36),
+	})
+	if rpcErr := rig.swapper.handleRedeem(maker.acct, redeemReq); rpcErr == nil ||
+		rpcErr.Code != msgjson.SettlementSequenceError || !strings.Contains(rpcErr.Message, "private_redeem") {
+		t.Fatalf("expected a sequence error for an HTLC redeem, got %v", rpcErr)
+	}
+
+	// Keys.
+	makerInit := &msgjson.PrivateInit{OrderID: matchInfo.makerOID[:], MatchID: mid[:]}
+	if rpcErr := handle(maker, msgjson.PrivateInitRoute, makerInit, rig.swapper.handlePrivateInit); rpcErr == nil {
+		t.Fatalf("no error for a swap before the key exchange")
+	}
+	keys := func(oid order.OrderID) *msgjson.PrivateKeys {
+		return &msgjson.PrivateKeys{OrderID: oid[:], MatchID: mid[:], RedeemKey: randBytes(33), RefundKey: randBytes(33)}
+	}
+	makerKeys, takerKeys := keys(matchInfo.makerOID), keys(matchInfo.takerOID)
+	if rpcErr := handle(maker, msgjson.PrivateKeysRoute, makerKeys, rig.swapper.handlePrivateKeys); rpcErr != nil {
+		t.Fatalf("maker keys error: %v", rpcErr)
+	}
+	ensureSuccess(maker)
+	if actor() != taker.acct {
+		t.Fatalf("taker should be acting after the maker's keys")
+	}
+	if rpcErr := handle(maker, msgjson.PrivateKeysRoute, keys(matchInfo.makerOID), rig.swapper.handlePrivateKeys); rpcErr == nil ||
+		rpcErr.Code != msgjson.DuplicateRequestError {
+		t.Fatalf("expected a duplicate error for the maker's keys, got %v", rpcErr)
+	}
+	if rpcErr := handle(taker, msgjson.PrivateKeysRoute, takerKeys, rig.swapper.handlePrivateKeys); rpcErr != nil {
+		t.Fatalf("taker keys error: %v", rpcErr)
+	}
+	ensureSuccess(taker)
+	waitReq(maker, msgjson.CounterpartyKeysRoute)
+	waitReq(taker, msgjson.CounterpartyKeysRoute)
+	if pm := storedPrivate(); pm.makerKeys == nil || pm.takerKeys == nil || pm.keysTime.IsZero() ||
+		!bytes.Equal(pm.takerKeys.RefundKey, takerKeys.RefundKey) {
+		t.Fatalf("exchanged keys not stored")
+	}
+	if actor() != maker.acct {
+		t.Fatalf("maker should be acting after the key exchange")
+	}
+
+	// checkPrivateContract checks that the swap output was checked against
+	// the counterparty's redeem key, the swapper's refund key and lock time.
+	checkPrivateContract := func(node *TUTXOBackend, redeemKeys, refundKeys *msgjson.PrivateKeys, lockTime time.Duration) {
+		t.Helper()
+		node.mtx.RLock()
+		c := node.privateContract
+		node.mtx.RUnlock()
+		if c == nil {
+			t.Fatalf("no private contract")
+		}
+		if !bytes.Equal(c.RedeemKey, redeemKeys.RedeemKey) || !bytes.Equal(c.RefundKey, refundKeys.RefundKey) {
+			t.Fatalf("wrong private contract keys")
+		}
+		if expLockTime := encode.DropMilliseconds(tracker.matchTime.Add(lockTime)); !c.LockTime.Equal(expLockTime) {
+			t.Fatalf("wrong private contract lock time %v, wanted %v", c.LockTime, expLockTime)
+		}
+	}
+
+	// Maker's swap. The maker sells the base asset. An output that does not
+	// pay to the contract is rejected.
+	makerSwap := newSwap(maker, rig.abcNode)
+	makerInit.CoinID = makerSwap.ID()
+	rig.abcNode.privateSwapErr = errors.New("wrong script")
+	if rpcErr := handle(maker, msgjson.PrivateInitRoute, makerInit, rig.swapper.handlePrivateInit); rpcErr != nil {
+		t.Fatalf("maker init error: %v", rpcErr)
+	}
+	ensureFail(maker, msgjson.ContractError)
+	rig.abcNode.mtx.Lock()
+	rig.abcNode.privateSwapErr = nil
+	rig.abcNode.mtx.Unlock()
+	if rpcErr := handle(maker, msgjson.PrivateInitRoute, makerInit, rig.swapper.handlePrivateInit); rpcErr != nil {
+		t.Fatalf("maker init error: %v", rpcErr)
+	}
+	ensureSuccess(maker)
+	checkStatus(order.MakerSwapCast)
+	checkPrivateContract(rig.abcNode, takerKeys, makerKeys, rig.swapper.lockTimeMaker)
+	waitReq(taker, msgjson.PrivateAuditRoute)
+
+	// Taker's swap requires the unsigned redeem.
+	takerSwap := newSwap(taker, rig.xyzNode)
+	takerInit := &msgjson.PrivateInit{OrderID: matchInfo.takerOID[:], MatchID: mid[:], CoinID: takerSwap.ID()}
+	if rpcErr := handle(taker, msgjson.PrivateInitRoute, takerInit, rig.swapper.handlePrivateInit); rpcErr == nil {
+		t.Fatalf("no error for a taker swap without an unsigned redeem")
+	}
+	takerInit.UnsignedRedeem = randBytes(100)
+	if rpcErr := handle(taker, msgjson.PrivateInitRoute, takerInit, rig.swapper.handlePrivateInit); rpcErr != nil {
+		t.Fatalf("taker init error: %v", rpcErr)
+	}
+	ensureSuccess(taker)
+	checkStatus(order.TakerSwapCast)
+	checkPrivateContract(rig.xyzNode, makerKeys, takerKeys, rig.swapper.lockTimeTaker)
+	audit := waitReq(maker, msgjson.PrivateAuditRoute)
+	var auditParams msgjson.PrivateAudit
+	if err := audit.Unmarshal(&auditParams); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(auditParams.UnsignedRedeem, takerInit.UnsignedRedeem) {
+		t.Fatalf("unsigned redeem not relayed")
+	}
+	if !bytes.Equal(storedPrivate().takerRedeem, takerInit.UnsignedRedeem) {
+		t.Fatalf("unsigned redeem not stored")
+	}
+
+	// Adaptor signatures. The maker goes first.
+	takerSigs := &msgjson.AdaptorSigs{OrderID: matchInfo.takerOID[:], MatchID: mid[:], RefundSig: randBytes(64)}
+	if rpcErr := handle(taker, msgjson.AdaptorSigsRoute, takerSigs, rig.swapper.handleAdaptorSigs); rpcErr == nil ||
+		rpcErr.Code != msgjson.SettlementSequenceError {
+		t.Fatalf("expected a sequence error for the taker's sigs, got %v", rpcErr)
+	}
+	makerRedeem := &msgjson.PrivateRedeem{OrderID: matchInfo.makerOID[:], MatchID: mid[:], CoinID: randBytes(36)}
+	if rpcErr := handle(maker, msgjson.PrivateRedeemRoute, makerRedeem, rig.swapper.handlePrivateRedeem); rpcErr == nil {
+		t.Fatalf("no error for a redeem before the adaptor signature exchange")
+	}
+	makerSigs := &msgjson.AdaptorSigs{
+		OrderID:        matchInfo.makerOID[:],
+		MatchID:        mid[:],
+		AdaptorPub:     randBytes(33),
+		RedeemSig:      randBytes(64),
+		RefundSig:      randBytes(64),
+		UnsignedRedeem: randBytes(100),
+	}
+	if rpcErr := handle(maker, msgjson.AdaptorSigsRoute, makerSigs, rig.swapper.handleAdaptorSigs); rpcErr != nil {
+		t.Fatalf("maker sigs error: %v", rpcErr)
+	}
+	ensureSuccess(maker)
+	waitReq(taker, msgjson.CounterpartyAdaptorSigsRoute)
+	if actor() != taker.acct {
+		t.Fatalf("taker should be acting after the maker's adaptor signatures")
+	}
+	if fault, outcome, _ := tracker.privateTakerFault(); !fault || outcome != db.OutcomeNoRedeemAsTaker {
+		t.Fatalf("taker should be at fault, outcome %v", outcome)
+	}
+	if rpcErr := handle(taker, msgjson.AdaptorSigsRoute, takerSigs, rig.swapper.handleAdaptorSigs); rpcErr != nil {
+		t.Fatalf("taker sigs error: %v", rpcErr)
+	}
+	ensureSuccess(taker)
+	waitReq(maker, msgjson.CounterpartyAdaptorSigsRoute)
+	if actor() != maker.acct {
+		t.Fatalf("maker should be acting after the adaptor signature exchange")
+	}
+	if pm := storedPrivate(); pm.makerSigs == nil || pm.takerSigs == nil ||
+		!bytes.Equal(pm.makerSigs.AdaptorPub, makerSigs.AdaptorPub) || pm.takerSigsTime.IsZero() {
+		t.Fatalf("adaptor signatures not stored")
+	}
+
+	// Redeems. A redemption that cannot be found is an error.
+	rig.xyzNode.setRedemptionErr(errors.New("bad redeem"))
+	if rpcErr := handle(maker, msgjson.PrivateRedeemRoute, makerRedeem, rig.swapper.handlePrivateRedeem); rpcErr != nil {
+		t.Fatalf("maker redeem error: %v", rpcErr)
+	}
+	ensureFail(maker, msgjson.RedemptionError)
+	rig.xyzNode.setRedemption(&TCoin{id: makerRedeem.CoinID}, takerSwap.Coin, true)
+	if rpcErr := handle(maker, msgjson.PrivateRedeemRoute, makerRedeem, rig.swapper.handlePrivateRedeem); rpcErr != nil {
+		t.Fatalf("maker redeem error: %v", rpcErr)
+	}
+	ensureSuccess(maker)
+	checkStatus(order.MakerRedeemed)
+	waitReq(taker, msgjson.PrivateRedemptionRoute)
+
+	takerRedeem := &msgjson.PrivateRedeem{OrderID: matchInfo.takerOID[:], MatchID: mid[:], CoinID: randBytes(36)}
+	rig.abcNode.setRedemption(&TCoin{id: takerRedeem.CoinID}, makerSwap.Coin, true)
+	if rpcErr := handle(taker, msgjson.PrivateRedeemRoute, takerRedeem, rig.swapper.handlePrivateRedeem); rpcErr != nil {
+		t.Fatalf("taker redeem error: %v", rpcErr)
+	}
+	ensureSuccess(taker)
+	checkStatus(order.MatchComplete)
+	waitReq(maker, msgjson.PrivateRedemptionRoute)
+}
+
+func TestRestorePrivateSwaps(t *testing.T) {
+	set := tPerfectLimitLimit(uint64(1e8), uint64(1e8), true)
+	matchInfo := set.matchInfos[0]
+	match := matchInfo.match
+	match.Status = order.MakerSwapCast
+
+	newSwapper := func(swapData *db.SwapData, abcNode *TUTXOBackend) (*Swapper, *TStorage) {
+		t.Helper()
+		storage := &TStorage{
+			orders: map[order.OrderID]order.Order{
+				matchInfo.makerOID: match.Maker,
+				matchInfo.takerOID: match.Taker,
+			},
+			activeSwaps: []*db.SwapDataFull{{
+				Base:  ABCID,
+				Quote: XYZID,
+				MatchData: &db.MatchData{
+					ID:        matchInfo.matchID,
+					Taker:     matchInfo.takerOID,
+					TakerAcct: match.Taker.User(),
+					TakerSell: match.Taker.Trade().Sell,
+					Maker:     matchInfo.makerOID,
+					MakerAcct: match.Maker.User(),
+					Epoch:     match.Epoch,
+					Quantity:  match.Quantity,
+					Rate:      match.Rate,
+					BaseRate:  match.FeeRateBase,
+					QuoteRate: match.FeeRateQuote,
+					Active:    true,
+					Status:    match.Status,
+				},
+				SwapData: swapData,
+			}},
+		}
+		// The market setting does not matter for stored matches.
+		swapper, err := NewSwapper(&Config{
+			Assets: map[uint32]*SwapperAsset{
+				ABCID: {TNewAsset(abcNode, ABCID), coinlock.NewAssetCoinLocker()},
+				XYZID: {TNewAsset(newUTXOBackend("xyz"), XYZID), coinlock.NewAssetCoinLocker()},
+			},
+			Storage:          storage,
+			AuthManager:      newTAuthManager(),
+			BroadcastTimeout: tBcastTimeout,
+			TxWaitExpiration: txWaitExpiration,
+			LockTimeTaker:    dex.LockTimeTaker(dex.Testnet),
+			LockTimeMaker:    dex.LockTimeMaker(dex.Testnet),
+			SwapDone:         func(ord order.Order, match *order.Match, fail bool) {},
+		})
+		if err != nil {
+			t.Fatalf("NewSwapper error: %v", err)
+		}
+		return swapper, storage
+	}
+
+	// An HTLC match is resumed.
+	swapper, storage := newSwapper(&db.SwapData{}, newUTXOBackend("abc"))
+	mt := swapper.matches[matchInfo.matchID]
+	if mt == nil {
+		t.Fatalf("match not restored")
+	}
+	if mt.private != nil {
+		t.Fatalf("HTLC match restored as a private swap")
+	}
+	if len(storage.inactive) != 0 {
+		t.Fatalf("restored match was set inactive")
+	}
+
+	// A private swap is resumed with the stored keys and adaptor signatures,
+	// and the maker's swap is located with the private contract.
+	mid := matchInfo.matchID
+	keys := func(oid order.OrderID) *msgjson.PrivateKeys {
+		return &msgjson.PrivateKeys{OrderID: oid[:], MatchID: mid[:], RedeemKey: randBytes(33), RefundKey: randBytes(33)}
+	}
+	keysTime := time.UnixMilli(time.Now().UnixMilli())
+	pm := &privateMatch{
+		makerKeys: keys(matchInfo.makerOID),
+		takerKeys: keys(matchInfo.takerOID),
+		keysTime:  keysTime,
+	}
+	privateData, err := pm.encode()
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	abcNode := newUTXOBackend("abc")
+	makerSwap := tNewSwap(matchInfo, matchInfo.makerOID, "", matchInfo.maker).coin
+	abcNode.setContract(makerSwap, false)
+	swapData := &db.SwapData{
+		ContractACoinID: makerSwap.ID(),
+		ContractATime:   keysTime.UnixMilli(),
+		PrivateData:     privateData,
+	}
+	swapper, storage = newSwapper(swapData, abcNode)
+	mt = swapper.matches[mid]
+	if mt == nil {
+		t.Fatalf("private match not restored")
+	}
+	if len(storage.inactive) != 0 {
+		t.Fatalf("restored private match was set inactive")
+	}
+	if mt.private == nil || !mt.private.keysTime.Equal(keysTime) ||
+		!bytes.Equal(mt.private.makerKeys.RedeemKey, pm.makerKeys.RedeemKey) ||
+		!bytes.Equal(mt.private.takerKeys.RefundKey, pm.takerKeys.RefundKey) {
+		t.Fatalf("private swap state not restored")
+	}
+	if mt.makerStatus.swap == nil {
+		t.Fatalf("maker's private swap not restored")
+	}
+	c := abcNode.privateContract
+	if c == nil || !bytes.Equal(c.RedeemKey, pm.takerKeys.RedeemKey) || !bytes.Equal(c.RefundKey, pm.makerKeys.RefundKey) {
+		t.Fatalf("maker's swap not located with the private contract")
+	}
+	if expLockTime := encode.DropMilliseconds(mt.matchTime.Add(swapper.lockTimeMaker)); !c.LockTime.Equal(expLockTime) {
+		t.Fatalf("wrong private contract lock time %v, wanted %v", c.LockTime, expLockTime)
+	}
+
+	// A private swap with a swap but no stored keys cannot be resumed.
+	noKeys, _ := new(privateMatch).encode()
+	swapData.PrivateData = noKeys
+	swapper, _ = newSwapper(swapData, abcNode)
+	if swapper.matches[mid] != nil {
+		t.Fatalf("private match without keys restored")
+	}
+}